@@ -0,0 +1,321 @@
+package embeddingx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bang-go/micro/store/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultBatchSize   = 10
+	defaultCachePrefix = "embeddingx:"
+)
+
+type Config struct {
+	Provider    Provider
+	Model       string
+	RerankModel string
+	// Dimensions requests a reduced vector size from models that support it.
+	Dimensions int
+	// BatchSize caps texts per provider request; defaults to 10, the DashScope
+	// text-embedding-v3 limit.
+	BatchSize int
+	// Rate limits provider requests per second; 0 means unlimited.
+	Rate  float64
+	Burst int
+
+	// Cache stores embeddings keyed by model, dimensions, input type and text
+	// hash, e.g. cache.NewRedis(redisClient). Nil disables caching.
+	Cache       cache.Store
+	CachePrefix string
+	// CacheTTL of zero keeps entries until evicted by the store.
+	CacheTTL time.Duration
+
+	DisableMetrics    bool
+	MetricsRegisterer prometheus.Registerer
+}
+
+type Client interface {
+	// Embed returns one vector per text, in order, embedded as documents.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// EmbedQuery embeds a single search query.
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+	// Rerank scores documents against query and returns them ordered by
+	// descending relevance; topN <= 0 returns all documents.
+	Rerank(ctx context.Context, query string, documents []string, topN int) ([]RerankResult, error)
+	Provider() Provider
+}
+
+type client struct {
+	config   *Config
+	provider string
+	limiter  *rate.Limiter
+	metrics  *metrics
+}
+
+func New(conf *Config) (Client, error) {
+	config, err := prepareConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{config: config, provider: config.Provider.Name()}
+	if config.Rate > 0 {
+		c.limiter = rate.NewLimiter(rate.Limit(config.Rate), config.Burst)
+	}
+	if !config.DisableMetrics {
+		c.metrics = defaultEmbeddingMetrics()
+		if config.MetricsRegisterer != nil {
+			c.metrics = newEmbeddingMetrics(config.MetricsRegisterer)
+		}
+	}
+	return c, nil
+}
+
+func (c *client) Provider() Provider {
+	return c.config.Provider
+}
+
+func (c *client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return c.embed(ctx, texts, InputTypeDocument)
+}
+
+func (c *client) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := c.embed(ctx, []string{text}, InputTypeQuery)
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (c *client) embed(ctx context.Context, texts []string, inputType InputType) ([][]float32, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	for _, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			return nil, ErrEmptyText
+		}
+	}
+
+	result := make([][]float32, len(texts))
+	// Identical texts within one call are embedded once.
+	positions := make(map[string][]int, len(texts))
+	var pending []string
+	for i, text := range texts {
+		if _, ok := positions[text]; !ok {
+			if embedding := c.cacheGet(ctx, text, inputType); embedding != nil {
+				result[i] = embedding
+				continue
+			}
+			pending = append(pending, text)
+		}
+		positions[text] = append(positions[text], i)
+	}
+
+	for start := 0; start < len(pending); start += c.config.BatchSize {
+		batch := pending[start:min(start+c.config.BatchSize, len(pending))]
+		embeddings, err := c.embedBatch(ctx, batch, inputType)
+		if err != nil {
+			return nil, err
+		}
+		for i, text := range batch {
+			for _, pos := range positions[text] {
+				result[pos] = embeddings[i]
+			}
+			c.cacheSet(ctx, text, inputType, embeddings[i])
+		}
+	}
+	return result, nil
+}
+
+func (c *client) embedBatch(ctx context.Context, texts []string, inputType InputType) ([][]float32, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := c.config.Provider.Embed(ctx, &EmbedRequest{
+		Model:      c.config.Model,
+		Texts:      texts,
+		Dimensions: c.config.Dimensions,
+		InputType:  inputType,
+	})
+	if err == nil && len(resp.Embeddings) != len(texts) {
+		err = ErrEmbeddingMismatch
+	}
+	c.observe("embed", start, err)
+	if err != nil {
+		return nil, err
+	}
+	c.addTokens("embed", resp.TotalTokens)
+	return resp.Embeddings, nil
+}
+
+func (c *client) Rerank(ctx context.Context, query string, documents []string, topN int) ([]RerankResult, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, ErrQueryRequired
+	}
+	if c.config.RerankModel == "" {
+		return nil, ErrModelRequired
+	}
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	if topN <= 0 || topN > len(documents) {
+		topN = len(documents)
+	}
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := c.config.Provider.Rerank(ctx, &RerankRequest{
+		Model:     c.config.RerankModel,
+		Query:     query,
+		Documents: documents,
+		TopN:      topN,
+	})
+	if err == nil {
+		for _, item := range resp.Results {
+			if item.Index < 0 || item.Index >= len(documents) {
+				err = ErrRerankIndexInvalid
+				break
+			}
+		}
+	}
+	c.observe("rerank", start, err)
+	if err != nil {
+		return nil, err
+	}
+	c.addTokens("rerank", resp.TotalTokens)
+
+	results := append([]RerankResult(nil), resp.Results...)
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > topN {
+		results = results[:topN]
+	}
+	return results, nil
+}
+
+func (c *client) wait(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+	return c.limiter.Wait(ctx)
+}
+
+func (c *client) cacheKey(text string, inputType InputType) string {
+	sum := sha256.Sum256([]byte(text))
+	return c.config.CachePrefix + c.config.Model + ":" + strconv.Itoa(c.config.Dimensions) + ":" + string(inputType) + ":" + hex.EncodeToString(sum[:])
+}
+
+func (c *client) cacheGet(ctx context.Context, text string, inputType InputType) []float32 {
+	if c.config.Cache == nil {
+		return nil
+	}
+	data, err := c.config.Cache.Get(ctx, c.cacheKey(text, inputType))
+	var embedding []float32
+	result := "hit"
+	switch {
+	case errors.Is(err, cache.ErrNotFound):
+		result = "miss"
+	case err != nil:
+		result = "error"
+	default:
+		if embedding = decodeVector(data); embedding == nil {
+			result = "miss"
+		}
+	}
+	if c.metrics != nil {
+		c.metrics.cacheLookups.WithLabelValues(c.provider, result).Inc()
+	}
+	return embedding
+}
+
+func (c *client) cacheSet(ctx context.Context, text string, inputType InputType, embedding []float32) {
+	if c.config.Cache == nil {
+		return
+	}
+	// A failed write only costs a future provider call.
+	_ = c.config.Cache.Set(ctx, c.cacheKey(text, inputType), encodeVector(embedding), c.config.CacheTTL)
+}
+
+func (c *client) observe(op string, start time.Time, err error) {
+	if c.metrics == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	c.metrics.requestsTotal.WithLabelValues(c.provider, op, result).Inc()
+	c.metrics.requestDuration.WithLabelValues(c.provider, op).Observe(time.Since(start).Seconds())
+}
+
+func (c *client) addTokens(op string, tokens int) {
+	if c.metrics != nil && tokens > 0 {
+		c.metrics.tokensTotal.WithLabelValues(c.provider, op).Add(float64(tokens))
+	}
+}
+
+// encodeVector stores float32 values little-endian, a quarter of the size of
+// the JSON form.
+func encodeVector(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+	}
+	return data
+}
+
+func decodeVector(data []byte) []float32 {
+	if len(data) == 0 || len(data)%4 != 0 {
+		return nil
+	}
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector
+}
+
+func prepareConfig(conf *Config) (*Config, error) {
+	if conf == nil {
+		return nil, ErrNilConfig
+	}
+
+	config := *conf
+	config.Model = strings.TrimSpace(config.Model)
+	config.RerankModel = strings.TrimSpace(config.RerankModel)
+	if config.Provider == nil {
+		return nil, ErrProviderRequired
+	}
+	if config.Model == "" && config.RerankModel == "" {
+		return nil, ErrModelRequired
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.Rate > 0 && config.Burst <= 0 {
+		config.Burst = 1
+	}
+	if config.CachePrefix == "" {
+		config.CachePrefix = defaultCachePrefix
+	}
+	return &config, nil
+}
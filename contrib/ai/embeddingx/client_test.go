@@ -0,0 +1,190 @@
+package embeddingx_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bang-go/micro/contrib/ai/embeddingx"
+	"github.com/bang-go/micro/store/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestDashScopeEmbedBatchesAndCaches(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/api/v1/services/embeddings/text-embedding/text-embedding" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var body struct {
+			Model string `json:"model"`
+			Input struct {
+				Texts []string `json:"texts"`
+			} `json:"input"`
+			Parameters struct {
+				Dimension int    `json:"dimension"`
+				TextType  string `json:"text_type"`
+			} `json:"parameters"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		if body.Model != "text-embedding-v3" || body.Parameters.Dimension != 2 {
+			t.Errorf("unexpected request %+v", body)
+		}
+		if len(body.Input.Texts) > 2 {
+			t.Errorf("batch size = %d, want <= 2", len(body.Input.Texts))
+		}
+
+		type item struct {
+			TextIndex int       `json:"text_index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var items []item
+		// Reply out of order to check text_index mapping.
+		for i := len(body.Input.Texts) - 1; i >= 0; i-- {
+			items = append(items, item{TextIndex: i, Embedding: []float32{float32(len(body.Input.Texts[i])), 1}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"output": map[string]any{"embeddings": items},
+			"usage":  map[string]any{"total_tokens": 7},
+		})
+	}))
+	defer server.Close()
+
+	provider, err := embeddingx.NewDashScope(&embeddingx.DashScopeConfig{APIKey: " sk-test ", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewDashScope() error = %v", err)
+	}
+	client, err := embeddingx.New(&embeddingx.Config{
+		Provider:          provider,
+		Model:             "text-embedding-v3",
+		Dimensions:        2,
+		BatchSize:         2,
+		Cache:             cache.NewMemory(nil),
+		MetricsRegisterer: prometheus.NewRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	texts := []string{"a", "bb", "a", "ccc"}
+	got, err := client.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	for i, text := range texts {
+		if got[i][0] != float32(len(text)) {
+			t.Fatalf("embedding[%d] = %v, want first value %d", i, got[i], len(text))
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("provider calls = %d, want 2 (3 unique texts, batch size 2)", calls.Load())
+	}
+
+	if _, err := client.Embed(context.Background(), []string{"ccc", "bb"}); err != nil {
+		t.Fatalf("cached Embed() error = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("provider calls = %d, want cached embeddings reused", calls.Load())
+	}
+
+	// Queries are cached separately from documents.
+	if _, err := client.EmbedQuery(context.Background(), "a"); err != nil {
+		t.Fatalf("EmbedQuery() error = %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("provider calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestOpenAIRerankAndAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/rerank":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"results": []map[string]any{
+					{"index": 0, "relevance_score": 0.1},
+					{"index": 2, "relevance_score": 0.9},
+					{"index": 1, "relevance_score": 0.5},
+				},
+			})
+		case "/v1/embeddings":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"slow down","type":"rate_limit_exceeded"}}`))
+		}
+	}))
+	defer server.Close()
+
+	provider, err := embeddingx.NewOpenAI(&embeddingx.OpenAIConfig{BaseURL: server.URL + "/v1/"})
+	if err != nil {
+		t.Fatalf("NewOpenAI() error = %v", err)
+	}
+	client, err := embeddingx.New(&embeddingx.Config{
+		Provider:       provider,
+		Model:          "bge-m3",
+		RerankModel:    "bge-reranker-v2-m3",
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	type hit struct{ title string }
+	hits := []hit{{"red shoes"}, {"blue shirt"}, {"red sneakers"}}
+	ranked, err := embeddingx.RerankItems(context.Background(), client, "red running shoes", hits, func(h hit) string { return h.title }, 2)
+	if err != nil {
+		t.Fatalf("RerankItems() error = %v", err)
+	}
+	if len(ranked) != 2 || ranked[0].Item.title != "red sneakers" || ranked[1].Item.title != "blue shirt" {
+		t.Fatalf("ranked = %+v", ranked)
+	}
+
+	_, err = client.Embed(context.Background(), []string{"x"})
+	var apiErr *embeddingx.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Code != "rate_limit_exceeded" {
+		t.Fatalf("Embed() error = %v, want APIError", err)
+	}
+}
+
+func TestClientValidation(t *testing.T) {
+	if _, err := embeddingx.New(nil); !errors.Is(err, embeddingx.ErrNilConfig) {
+		t.Fatalf("New(nil) error = %v", err)
+	}
+	if _, err := embeddingx.NewDashScope(&embeddingx.DashScopeConfig{}); !errors.Is(err, embeddingx.ErrAPIKeyRequired) {
+		t.Fatalf("NewDashScope() error = %v", err)
+	}
+	provider, _ := embeddingx.NewOpenAI(&embeddingx.OpenAIConfig{BaseURL: "http://127.0.0.1:0", DisableRerank: true})
+	client, err := embeddingx.New(&embeddingx.Config{Provider: provider, Model: "m", RerankModel: "r", DisableMetrics: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := client.Embed(context.Background(), []string{" "}); !errors.Is(err, embeddingx.ErrEmptyText) {
+		t.Fatalf("Embed() error = %v, want ErrEmptyText", err)
+	}
+	if _, err := client.Rerank(context.Background(), "q", []string{"d"}, 1); !errors.Is(err, embeddingx.ErrRerankUnsupported) {
+		t.Fatalf("Rerank() error = %v, want ErrRerankUnsupported", err)
+	}
+}
+
+func TestSearchHelpers(t *testing.T) {
+	body := embeddingx.KNNQuery("embedding", []float32{1, 0}, 5)
+	knn := body["query"].(map[string]any)["knn"].(map[string]any)["embedding"].(map[string]any)
+	if knn["k"] != 5 || body["size"] != 5 {
+		t.Fatalf("KNNQuery() = %v", body)
+	}
+	if clause := embeddingx.ElasticsearchKNN("embedding", []float32{1}, 5, 0); clause["num_candidates"] != 50 {
+		t.Fatalf("ElasticsearchKNN() = %v", clause)
+	}
+	if got := embeddingx.CosineSimilarity([]float32{1, 0}, []float32{1, 0}); got != 1 {
+		t.Fatalf("CosineSimilarity() = %v, want 1", got)
+	}
+}
@@ -0,0 +1,166 @@
+package embeddingx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const DefaultDashScopeBaseURL = "https://dashscope.aliyuncs.com"
+
+type DashScopeConfig struct {
+	APIKey string
+	// BaseURL defaults to DefaultDashScopeBaseURL; use
+	// https://dashscope-intl.aliyuncs.com for the international region.
+	BaseURL    string
+	Header     http.Header
+	HTTPClient *http.Client
+}
+
+type dashScope struct {
+	baseURL   string
+	transport *httpTransport
+}
+
+// NewDashScope returns a provider for the Aliyun DashScope native embedding
+// and text-rerank APIs.
+func NewDashScope(conf *DashScopeConfig) (Provider, error) {
+	if conf == nil {
+		return nil, ErrNilConfig
+	}
+	apiKey := strings.TrimSpace(conf.APIKey)
+	if apiKey == "" {
+		return nil, ErrAPIKeyRequired
+	}
+	baseURL := strings.TrimSpace(conf.BaseURL)
+	if baseURL == "" {
+		baseURL = DefaultDashScopeBaseURL
+	}
+
+	header := cloneHeader(conf.Header)
+	header.Set("Authorization", "Bearer "+apiKey)
+	return &dashScope{
+		baseURL: baseURL,
+		transport: &httpTransport{
+			provider:   "dashscope",
+			httpClient: newHTTPClient(conf.HTTPClient),
+			header:     header,
+			decodeErr:  decodeDashScopeError,
+		},
+	}, nil
+}
+
+func (p *dashScope) Name() string {
+	return "dashscope"
+}
+
+type dashScopeEmbedRequest struct {
+	Model string `json:"model"`
+	Input struct {
+		Texts []string `json:"texts"`
+	} `json:"input"`
+	Parameters struct {
+		Dimension int    `json:"dimension,omitempty"`
+		TextType  string `json:"text_type,omitempty"`
+	} `json:"parameters"`
+}
+
+type dashScopeEmbedResponse struct {
+	Output struct {
+		Embeddings []struct {
+			TextIndex int       `json:"text_index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"embeddings"`
+	} `json:"output"`
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+func (p *dashScope) Embed(ctx context.Context, request *EmbedRequest) (*EmbedResponse, error) {
+	var payload dashScopeEmbedRequest
+	payload.Model = request.Model
+	payload.Input.Texts = request.Texts
+	payload.Parameters.Dimension = request.Dimensions
+	payload.Parameters.TextType = string(request.InputType)
+
+	var result dashScopeEmbedResponse
+	if err := p.transport.post(ctx, joinURL(p.baseURL, "/api/v1/services/embeddings/text-embedding/text-embedding"), payload, &result); err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float32, len(request.Texts))
+	for _, item := range result.Output.Embeddings {
+		if item.TextIndex < 0 || item.TextIndex >= len(embeddings) {
+			return nil, ErrEmbeddingMismatch
+		}
+		embeddings[item.TextIndex] = item.Embedding
+	}
+	if err := checkEmbeddings(embeddings); err != nil {
+		return nil, err
+	}
+	return &EmbedResponse{Embeddings: embeddings, TotalTokens: result.Usage.TotalTokens}, nil
+}
+
+type dashScopeRerankRequest struct {
+	Model string `json:"model"`
+	Input struct {
+		Query     string   `json:"query"`
+		Documents []string `json:"documents"`
+	} `json:"input"`
+	Parameters struct {
+		TopN            int  `json:"top_n,omitempty"`
+		ReturnDocuments bool `json:"return_documents"`
+	} `json:"parameters"`
+}
+
+type dashScopeRerankResponse struct {
+	Output struct {
+		Results []struct {
+			Index          int     `json:"index"`
+			RelevanceScore float64 `json:"relevance_score"`
+		} `json:"results"`
+	} `json:"output"`
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+func (p *dashScope) Rerank(ctx context.Context, request *RerankRequest) (*RerankResponse, error) {
+	var payload dashScopeRerankRequest
+	payload.Model = request.Model
+	payload.Input.Query = request.Query
+	payload.Input.Documents = request.Documents
+	payload.Parameters.TopN = request.TopN
+
+	var result dashScopeRerankResponse
+	if err := p.transport.post(ctx, joinURL(p.baseURL, "/api/v1/services/rerank/text-rerank/text-rerank"), payload, &result); err != nil {
+		return nil, err
+	}
+
+	results := make([]RerankResult, 0, len(result.Output.Results))
+	for _, item := range result.Output.Results {
+		results = append(results, RerankResult{Index: item.Index, Score: item.RelevanceScore})
+	}
+	return &RerankResponse{Results: results, TotalTokens: result.Usage.TotalTokens}, nil
+}
+
+func decodeDashScopeError(body []byte) *APIError {
+	var payload struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		RequestID string `json:"request_id"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	return &APIError{Code: payload.Code, Message: payload.Message, RequestID: payload.RequestID}
+}
+
+func checkEmbeddings(embeddings [][]float32) error {
+	for _, embedding := range embeddings {
+		if embedding == nil {
+			return ErrEmbeddingMismatch
+		}
+	}
+	return nil
+}
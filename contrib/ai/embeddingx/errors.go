@@ -0,0 +1,47 @@
+package embeddingx
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNilConfig          = errors.New("embeddingx: config is required")
+	ErrContextRequired    = errors.New("embeddingx: context is required")
+	ErrProviderRequired   = errors.New("embeddingx: provider is required")
+	ErrModelRequired      = errors.New("embeddingx: model is required")
+	ErrAPIKeyRequired     = errors.New("embeddingx: api key is required")
+	ErrBaseURLRequired    = errors.New("embeddingx: base url is required")
+	ErrEmptyText          = errors.New("embeddingx: text is required")
+	ErrQueryRequired      = errors.New("embeddingx: rerank query is required")
+	ErrRerankUnsupported  = errors.New("embeddingx: provider does not support rerank")
+	ErrEmbeddingMismatch  = errors.New("embeddingx: provider returned an unexpected number of embeddings")
+	ErrRerankIndexInvalid = errors.New("embeddingx: provider returned an invalid rerank index")
+)
+
+// APIError is returned when a provider responds with a non-2xx status or an
+// error payload.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	if e == nil {
+		return ""
+	}
+	base := fmt.Sprintf("embeddingx: %s request failed status=%d", e.Provider, e.StatusCode)
+	if e.Code != "" {
+		base += " code=" + e.Code
+	}
+	if e.Message != "" {
+		base += " message=" + e.Message
+	}
+	if e.RequestID != "" {
+		base += " request_id=" + e.RequestID
+	}
+	return base
+}
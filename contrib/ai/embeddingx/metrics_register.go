@@ -0,0 +1,82 @@
+package embeddingx
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	tokensTotal     *prometheus.CounterVec
+	cacheLookups    *prometheus.CounterVec
+}
+
+var (
+	defaultMetricsOnce sync.Once
+	defaultMetrics     *metrics
+)
+
+func defaultEmbeddingMetrics() *metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = newEmbeddingMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetrics
+}
+
+func newEmbeddingMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		requestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "embeddingx_requests_total",
+				Help: "Total number of embedding and rerank provider requests.",
+			},
+			[]string{"provider", "op", "result"},
+		),
+		requestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "embeddingx_request_duration_seconds",
+				Help:    "Embedding and rerank provider request duration in seconds.",
+				Buckets: []float64{0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			},
+			[]string{"provider", "op"},
+		),
+		tokensTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "embeddingx_tokens_total",
+				Help: "Total number of tokens billed by embedding and rerank providers.",
+			},
+			[]string{"provider", "op"},
+		),
+		cacheLookups: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "embeddingx_cache_lookups_total",
+				Help: "Total number of embedding cache lookups.",
+			},
+			[]string{"provider", "result"},
+		),
+	}
+
+	mustRegisterCollector(registerer, &m.requestsTotal, m.requestsTotal)
+	mustRegisterCollector(registerer, &m.requestDuration, m.requestDuration)
+	mustRegisterCollector(registerer, &m.tokensTotal, m.tokensTotal)
+	mustRegisterCollector(registerer, &m.cacheLookups, m.cacheLookups)
+
+	return m
+}
+
+func mustRegisterCollector[T prometheus.Collector](registerer prometheus.Registerer, dst *T, collector T) {
+	if registerer == nil {
+		return
+	}
+	if err := registerer.Register(collector); err != nil {
+		if alreadyRegistered, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if registered, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				*dst = registered
+				return
+			}
+		}
+		panic(err)
+	}
+}
@@ -0,0 +1,165 @@
+package embeddingx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type OpenAIConfig struct {
+	// BaseURL is the API root including the version, e.g.
+	// https://api.openai.com/v1 or
+	// https://dashscope.aliyuncs.com/compatible-mode/v1.
+	BaseURL string
+	// APIKey is optional for self-hosted endpoints.
+	APIKey     string
+	Header     http.Header
+	HTTPClient *http.Client
+	// DisableRerank reports ErrRerankUnsupported instead of calling
+	// {BaseURL}/rerank, for endpoints that only serve embeddings.
+	DisableRerank bool
+}
+
+type openAI struct {
+	baseURL       string
+	disableRerank bool
+	transport     *httpTransport
+}
+
+// NewOpenAI returns a provider for OpenAI-compatible endpoints. Rerank uses
+// the {BaseURL}/rerank convention shared by vLLM, Xinference, Jina and TEI.
+func NewOpenAI(conf *OpenAIConfig) (Provider, error) {
+	if conf == nil {
+		return nil, ErrNilConfig
+	}
+	baseURL := strings.TrimSpace(conf.BaseURL)
+	if baseURL == "" {
+		return nil, ErrBaseURLRequired
+	}
+
+	header := cloneHeader(conf.Header)
+	if apiKey := strings.TrimSpace(conf.APIKey); apiKey != "" {
+		header.Set("Authorization", "Bearer "+apiKey)
+	}
+	return &openAI{
+		baseURL:       baseURL,
+		disableRerank: conf.DisableRerank,
+		transport: &httpTransport{
+			provider:   "openai",
+			httpClient: newHTTPClient(conf.HTTPClient),
+			header:     header,
+			decodeErr:  decodeOpenAIError,
+		},
+	}, nil
+}
+
+func (p *openAI) Name() string {
+	return "openai"
+}
+
+type openAIEmbedRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format"`
+}
+
+type openAIEmbedResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+func (p *openAI) Embed(ctx context.Context, request *EmbedRequest) (*EmbedResponse, error) {
+	payload := openAIEmbedRequest{
+		Model:          request.Model,
+		Input:          request.Texts,
+		Dimensions:     request.Dimensions,
+		EncodingFormat: "float",
+	}
+
+	var result openAIEmbedResponse
+	if err := p.transport.post(ctx, joinURL(p.baseURL, "/embeddings"), payload, &result); err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float32, len(request.Texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(embeddings) {
+			return nil, ErrEmbeddingMismatch
+		}
+		embeddings[item.Index] = item.Embedding
+	}
+	if err := checkEmbeddings(embeddings); err != nil {
+		return nil, err
+	}
+	return &EmbedResponse{Embeddings: embeddings, TotalTokens: result.Usage.TotalTokens}, nil
+}
+
+type openAIRerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n,omitempty"`
+}
+
+type openAIRerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+func (p *openAI) Rerank(ctx context.Context, request *RerankRequest) (*RerankResponse, error) {
+	if p.disableRerank {
+		return nil, ErrRerankUnsupported
+	}
+	payload := openAIRerankRequest{
+		Model:     request.Model,
+		Query:     request.Query,
+		Documents: request.Documents,
+		TopN:      request.TopN,
+	}
+
+	var result openAIRerankResponse
+	if err := p.transport.post(ctx, joinURL(p.baseURL, "/rerank"), payload, &result); err != nil {
+		return nil, err
+	}
+
+	results := make([]RerankResult, 0, len(result.Results))
+	for _, item := range result.Results {
+		results = append(results, RerankResult{Index: item.Index, Score: item.RelevanceScore})
+	}
+	return &RerankResponse{Results: results, TotalTokens: result.Usage.TotalTokens}, nil
+}
+
+func decodeOpenAIError(body []byte) *APIError {
+	var payload struct {
+		Error struct {
+			Code    any    `json:"code"`
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &payload)
+
+	apiErr := &APIError{Message: payload.Error.Message, Code: payload.Error.Type}
+	switch code := payload.Error.Code.(type) {
+	case string:
+		if code != "" {
+			apiErr.Code = code
+		}
+	case float64:
+		apiErr.Code = strconv.FormatFloat(code, 'f', -1, 64)
+	}
+	return apiErr
+}
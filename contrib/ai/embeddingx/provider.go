@@ -0,0 +1,130 @@
+package embeddingx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// InputType hints whether texts are search queries or indexed documents.
+// Providers that do not distinguish the two ignore it.
+type InputType string
+
+const (
+	InputTypeQuery    InputType = "query"
+	InputTypeDocument InputType = "document"
+)
+
+type EmbedRequest struct {
+	Model      string
+	Texts      []string
+	Dimensions int
+	InputType  InputType
+}
+
+type EmbedResponse struct {
+	// Embeddings are in the same order as EmbedRequest.Texts.
+	Embeddings  [][]float32
+	TotalTokens int
+}
+
+type RerankRequest struct {
+	Model     string
+	Query     string
+	Documents []string
+	// TopN limits the returned results; zero returns all documents.
+	TopN int
+}
+
+type RerankResponse struct {
+	// Results are ordered by descending Score.
+	Results     []RerankResult
+	TotalTokens int
+}
+
+type RerankResult struct {
+	// Index points into RerankRequest.Documents.
+	Index int
+	Score float64
+}
+
+// Provider is a single embedding/rerank backend. Implementations send one
+// HTTP request per call; batching, rate limiting and caching live in Client.
+type Provider interface {
+	Name() string
+	Embed(context.Context, *EmbedRequest) (*EmbedResponse, error)
+	// Rerank returns ErrRerankUnsupported when the backend has no rerank API.
+	Rerank(context.Context, *RerankRequest) (*RerankResponse, error)
+}
+
+type httpTransport struct {
+	provider   string
+	httpClient *http.Client
+	header     http.Header
+	decodeErr  func(body []byte) *APIError
+}
+
+func newHTTPClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+func (t *httpTransport) post(ctx context.Context, url string, payload any, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("embeddingx: marshal request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("embeddingx: build request failed: %w", err)
+	}
+	for key, values := range t.header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("embeddingx: %s request failed: %w", t.provider, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("embeddingx: read response failed: %w", err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		apiErr := t.decodeErr(respBody)
+		apiErr.Provider = t.provider
+		apiErr.StatusCode = resp.StatusCode
+		if apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(respBody))
+		}
+		return apiErr
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("embeddingx: decode response failed: %w", err)
+	}
+	return nil
+}
+
+func joinURL(base, path string) string {
+	return strings.TrimRight(base, "/") + path
+}
+
+func cloneHeader(header http.Header) http.Header {
+	if header == nil {
+		return http.Header{}
+	}
+	return header.Clone()
+}
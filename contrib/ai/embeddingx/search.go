@@ -0,0 +1,79 @@
+package embeddingx
+
+import (
+	"context"
+	"math"
+)
+
+// KNNQuery builds an OpenSearch k-NN search body, usable with
+// polarsearchx.Client.Search or opensearch-compatible raw requests.
+func KNNQuery(field string, vector []float32, k int) map[string]any {
+	return map[string]any{
+		"size": k,
+		"query": map[string]any{
+			"knn": map[string]any{
+				field: map[string]any{
+					"vector": vector,
+					"k":      k,
+				},
+			},
+		},
+	}
+}
+
+// ElasticsearchKNN builds an Elasticsearch 8+ top-level "knn" clause. Merge it
+// into a search body, or unmarshal it into types.KnnSearch for the typed
+// client. numCandidates defaults to 10*k.
+func ElasticsearchKNN(field string, vector []float32, k, numCandidates int) map[string]any {
+	if numCandidates <= 0 {
+		numCandidates = 10 * k
+	}
+	return map[string]any{
+		"field":          field,
+		"query_vector":   vector,
+		"k":              k,
+		"num_candidates": numCandidates,
+	}
+}
+
+type Ranked[T any] struct {
+	Item  T
+	Score float64
+}
+
+// RerankItems reranks arbitrary search hits by the text extracted from each
+// item, returning at most topN items ordered by descending relevance.
+func RerankItems[T any](ctx context.Context, client Client, query string, items []T, text func(T) string, topN int) ([]Ranked[T], error) {
+	documents := make([]string, len(items))
+	for i, item := range items {
+		documents[i] = text(item)
+	}
+
+	results, err := client.Rerank(ctx, query, documents, topN)
+	if err != nil {
+		return nil, err
+	}
+	ranked := make([]Ranked[T], len(results))
+	for i, result := range results {
+		ranked[i] = Ranked[T]{Item: items[result.Index], Score: result.Score}
+	}
+	return ranked, nil
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0 when
+// the lengths differ or either vector is zero.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
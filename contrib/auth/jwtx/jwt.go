@@ -1,6 +1,9 @@
 package jwtx
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
@@ -19,6 +22,11 @@ var (
 	ErrInvalidTokenLifetime = errors.New("jwtx: invalid token lifetime")
 	ErrTokenExpired         = errors.New("jwtx: token expired")
 	ErrTokenInvalid         = errors.New("jwtx: token invalid")
+	ErrTokenUsed            = errors.New("jwtx: token already used")
+	ErrPurposeMismatch      = errors.New("jwtx: token purpose mismatch")
+	ErrPurposeRequired      = errors.New("jwtx: purpose is required")
+	ErrStoreRequired        = errors.New("jwtx: store is required for one-time tokens")
+	ErrCacheStoreRequired   = errors.New("jwtx: cache store is required")
 )
 
 type Config struct {
@@ -29,6 +37,9 @@ type Config struct {
 	Leeway    time.Duration
 	Method    jwt.SigningMethod
 	TimeFunc  func() time.Time
+	// Store records the IDs of consumed one-time tokens. It is required to
+	// issue or parse tokens made with WithOneTime.
+	Store Store
 }
 
 type JWT[T any] struct {
@@ -36,13 +47,20 @@ type JWT[T any] struct {
 	issuer   string
 	audience []string
 	expire   time.Duration
+	leeway   time.Duration
 	method   jwt.SigningMethod
 	timeFunc func() time.Time
 	parser   *jwt.Parser
+	store    Store
 }
 
 type Claims[T any] struct {
 	Payload T `json:"payload"`
+	// Purpose scopes an action token; only ParseAction with the same purpose
+	// accepts it.
+	Purpose string `json:"purpose,omitempty"`
+	// OneTime tokens are consumed in the Store on their first successful parse.
+	OneTime bool `json:"one_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -52,6 +70,8 @@ type issueOptions struct {
 	subject   string
 	audience  []string
 	id        string
+	purpose   string
+	oneTime   bool
 	notBefore *time.Time
 	issuedAt  *time.Time
 	expiresAt *time.Time
@@ -102,9 +122,11 @@ func New[T any](conf *Config) (*JWT[T], error) {
 		issuer:   issuer,
 		audience: audience,
 		expire:   expire,
+		leeway:   conf.Leeway,
 		method:   method,
 		timeFunc: timeFunc,
 		parser:   jwt.NewParser(parserOptions...),
+		store:    conf.Store,
 	}, nil
 }
 
@@ -128,7 +150,20 @@ func (j *JWT[T]) Generate(payload T, options ...IssueOption) (string, error) {
 	}
 	opts.subject = strings.TrimSpace(opts.subject)
 	opts.id = strings.TrimSpace(opts.id)
+	opts.purpose = strings.TrimSpace(opts.purpose)
 	opts.audience = normalizeAudience(opts.audience)
+	if opts.oneTime {
+		if j.store == nil {
+			return "", ErrStoreRequired
+		}
+		if opts.id == "" {
+			id, err := newTokenID()
+			if err != nil {
+				return "", err
+			}
+			opts.id = id
+		}
+	}
 
 	issuedAt := now
 	if opts.issuedAt != nil {
@@ -150,6 +185,8 @@ func (j *JWT[T]) Generate(payload T, options ...IssueOption) (string, error) {
 
 	token := jwt.NewWithClaims(j.method, Claims[T]{
 		Payload: payload,
+		Purpose: opts.purpose,
+		OneTime: opts.oneTime,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    j.issuer,
 			Subject:   opts.subject,
@@ -163,7 +200,44 @@ func (j *JWT[T]) Generate(payload T, options ...IssueOption) (string, error) {
 	return token.SignedString(j.secret)
 }
 
+// GenerateAction issues a short-lived token for a single action such as a
+// password reset or a download link. It expires ttl from now and is accepted
+// only by ParseAction with the same purpose; add WithOneTime to make it
+// single-use.
+func (j *JWT[T]) GenerateAction(purpose string, payload T, ttl time.Duration, options ...IssueOption) (string, error) {
+	purpose = strings.TrimSpace(purpose)
+	if purpose == "" {
+		return "", ErrPurposeRequired
+	}
+	if ttl <= 0 {
+		return "", ErrInvalidTokenLifetime
+	}
+	now := j.timeFunc().UTC()
+	options = append(options, WithIssuedAt(now), WithExpiresAt(now.Add(ttl)), WithPurpose(purpose))
+	return j.Generate(payload, options...)
+}
+
+// Parse validates a token that carries no purpose. One-time tokens are
+// consumed with a background context; use ParseContext to pass one through.
 func (j *JWT[T]) Parse(tokenString string) (*Claims[T], error) {
+	return j.ParseContext(context.Background(), tokenString)
+}
+
+// ParseContext is Parse with ctx passed to the Store.
+func (j *JWT[T]) ParseContext(ctx context.Context, tokenString string) (*Claims[T], error) {
+	return j.parse(ctx, tokenString, "")
+}
+
+// ParseAction validates a token issued by GenerateAction for purpose.
+func (j *JWT[T]) ParseAction(ctx context.Context, purpose, tokenString string) (*Claims[T], error) {
+	purpose = strings.TrimSpace(purpose)
+	if purpose == "" {
+		return nil, ErrPurposeRequired
+	}
+	return j.parse(ctx, tokenString, purpose)
+}
+
+func (j *JWT[T]) parse(ctx context.Context, tokenString, purpose string) (*Claims[T], error) {
 	claims := &Claims[T]{}
 	token, err := j.parser.ParseWithClaims(tokenString, claims, j.keyFunc)
 	if err != nil {
@@ -172,9 +246,38 @@ func (j *JWT[T]) Parse(tokenString string) (*Claims[T], error) {
 	if !token.Valid {
 		return nil, ErrTokenInvalid
 	}
+	// An action token must never pass as a session token, nor as a token for
+	// another action.
+	if claims.Purpose != purpose {
+		return nil, fmt.Errorf("%w: got %q, want %q", ErrPurposeMismatch, claims.Purpose, purpose)
+	}
+	if claims.OneTime {
+		if err := j.consume(ctx, claims); err != nil {
+			return nil, err
+		}
+	}
 	return claims, nil
 }
 
+// consume runs last, so a token that fails any other check is not used up.
+func (j *JWT[T]) consume(ctx context.Context, claims *Claims[T]) error {
+	if j.store == nil {
+		return ErrStoreRequired
+	}
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return fmt.Errorf("%w: one-time token without jti or exp", ErrTokenInvalid)
+	}
+	// Remember the ID for as long as the parser could still accept the token.
+	first, err := j.store.Consume(ctx, claims.ID, claims.ExpiresAt.Add(j.leeway))
+	if err != nil {
+		return fmt.Errorf("jwtx: consume token: %w", err)
+	}
+	if !first {
+		return ErrTokenUsed
+	}
+	return nil
+}
+
 func (j *JWT[T]) ParsePayload(tokenString string) (T, error) {
 	claims, err := j.Parse(tokenString)
 	if err != nil {
@@ -202,6 +305,21 @@ func WithJWTID(id string) IssueOption {
 	}
 }
 
+// WithPurpose scopes the token to an action; see GenerateAction.
+func WithPurpose(purpose string) IssueOption {
+	return func(opts *issueOptions) {
+		opts.purpose = purpose
+	}
+}
+
+// WithOneTime makes the token single-use. A random jti is assigned unless
+// WithJWTID sets one.
+func WithOneTime() IssueOption {
+	return func(opts *issueOptions) {
+		opts.oneTime = true
+	}
+}
+
 func WithNotBefore(at time.Time) IssueOption {
 	return func(opts *issueOptions) {
 		opts.notBefore = util.Ptr(at)
@@ -242,6 +360,14 @@ func mapTokenError(err error) error {
 	}
 }
 
+func newTokenID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("jwtx: generate token id: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
 func normalizeMethod(method jwt.SigningMethod) (jwt.SigningMethod, error) {
 	if method == nil {
 		return jwt.SigningMethodHS256, nil
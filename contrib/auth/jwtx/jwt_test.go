@@ -1,10 +1,12 @@
 package jwtx
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/bang-go/micro/store/cache"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -262,3 +264,189 @@ func TestParseRejectsWrongAudience(t *testing.T) {
 		t.Fatalf("Parse() error = %v, want %v", err, ErrTokenInvalid)
 	}
 }
+
+func TestOneTimeToken(t *testing.T) {
+	client, err := New[userPayload](&Config{SecretKey: "secret", Store: NewMemoryStore()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	token, err := client.Generate(userPayload{UserID: "u-6"}, WithOneTime())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	claims, err := client.Parse(token)
+	if err != nil {
+		t.Fatalf("first Parse() error = %v", err)
+	}
+	if !claims.OneTime || claims.ID == "" {
+		t.Fatalf("claims = %+v, want one-time token with jti", claims.RegisteredClaims)
+	}
+
+	_, err = client.Parse(token)
+	if !errors.Is(err, ErrTokenUsed) {
+		t.Fatalf("second Parse() error = %v, want %v", err, ErrTokenUsed)
+	}
+
+	other, err := client.Generate(userPayload{UserID: "u-6"}, WithOneTime())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, err := client.Parse(other); err != nil {
+		t.Fatalf("Parse(other) error = %v", err)
+	}
+}
+
+func TestOneTimeTokenRequiresStore(t *testing.T) {
+	client, err := New[userPayload](&Config{SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = client.Generate(userPayload{UserID: "u-7"}, WithOneTime())
+	if !errors.Is(err, ErrStoreRequired) {
+		t.Fatalf("Generate() error = %v, want %v", err, ErrStoreRequired)
+	}
+
+	signer, err := New[userPayload](&Config{SecretKey: "secret", Store: NewMemoryStore()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	token, err := signer.Generate(userPayload{UserID: "u-7"}, WithOneTime())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	_, err = client.Parse(token)
+	if !errors.Is(err, ErrStoreRequired) {
+		t.Fatalf("Parse() without store error = %v, want %v", err, ErrStoreRequired)
+	}
+}
+
+func TestActionToken(t *testing.T) {
+	now := time.Date(2026, 3, 23, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	client, err := New[userPayload](&Config{
+		SecretKey: "secret",
+		Store:     store,
+		TimeFunc:  func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	token, err := client.GenerateAction(" password-reset ", userPayload{UserID: "u-8"}, 15*time.Minute, WithOneTime())
+	if err != nil {
+		t.Fatalf("GenerateAction() error = %v", err)
+	}
+
+	_, err = client.Parse(token)
+	if !errors.Is(err, ErrPurposeMismatch) {
+		t.Fatalf("Parse(action token) error = %v, want %v", err, ErrPurposeMismatch)
+	}
+	_, err = client.ParseAction(ctx, "download", token)
+	if !errors.Is(err, ErrPurposeMismatch) {
+		t.Fatalf("ParseAction(wrong purpose) error = %v, want %v", err, ErrPurposeMismatch)
+	}
+
+	// Rejected attempts must not have used the token up.
+	claims, err := client.ParseAction(ctx, "password-reset", token)
+	if err != nil {
+		t.Fatalf("ParseAction() error = %v", err)
+	}
+	if got, want := claims.ExpiresAt.Time, now.Add(15*time.Minute); !got.Equal(want) {
+		t.Fatalf("claims.ExpiresAt = %v, want %v", got, want)
+	}
+	if got, want := claims.Payload.UserID, "u-8"; got != want {
+		t.Fatalf("claims.Payload.UserID = %q, want %q", got, want)
+	}
+
+	_, err = client.ParseAction(ctx, "password-reset", token)
+	if !errors.Is(err, ErrTokenUsed) {
+		t.Fatalf("second ParseAction() error = %v, want %v", err, ErrTokenUsed)
+	}
+
+	session, err := client.Generate(userPayload{UserID: "u-8"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	_, err = client.ParseAction(ctx, "password-reset", session)
+	if !errors.Is(err, ErrPurposeMismatch) {
+		t.Fatalf("ParseAction(session token) error = %v, want %v", err, ErrPurposeMismatch)
+	}
+}
+
+func TestGenerateActionValidation(t *testing.T) {
+	client, err := New[userPayload](&Config{SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = client.GenerateAction(" ", userPayload{}, time.Minute)
+	if !errors.Is(err, ErrPurposeRequired) {
+		t.Fatalf("GenerateAction(blank purpose) error = %v, want %v", err, ErrPurposeRequired)
+	}
+	_, err = client.GenerateAction("download", userPayload{}, 0)
+	if !errors.Is(err, ErrInvalidTokenLifetime) {
+		t.Fatalf("GenerateAction(zero ttl) error = %v, want %v", err, ErrInvalidTokenLifetime)
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 23, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if ok, _ := store.Consume(ctx, "id", now.Add(time.Minute)); !ok {
+		t.Fatal("first Consume() = false, want true")
+	}
+	if ok, _ := store.Consume(ctx, "id", now.Add(time.Minute)); ok {
+		t.Fatal("second Consume() = true, want false")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if ok, _ := store.Consume(ctx, "other", now.Add(time.Minute)); !ok {
+		t.Fatal("Consume(other) = false, want true")
+	}
+	if _, ok := store.used["id"]; ok {
+		t.Fatal("expired id was not swept")
+	}
+}
+
+func TestCacheStore(t *testing.T) {
+	if _, err := NewCacheStore(nil); !errors.Is(err, ErrCacheStoreRequired) {
+		t.Fatalf("NewCacheStore(nil) error = %v, want %v", err, ErrCacheStoreRequired)
+	}
+
+	backend := cache.NewMemory(nil)
+	first, err := NewCacheStore(backend)
+	if err != nil {
+		t.Fatalf("NewCacheStore() error = %v", err)
+	}
+	second, _ := NewCacheStore(backend)
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	if ok, err := first.Consume(ctx, "id", expiresAt); err != nil || !ok {
+		t.Fatalf("first Consume() = %v, %v, want true", ok, err)
+	}
+	// Instances sharing the backend see each other's consumed IDs.
+	if ok, err := second.Consume(ctx, "id", expiresAt); err != nil || ok {
+		t.Fatalf("second Consume() = %v, %v, want false", ok, err)
+	}
+	if _, err := backend.Get(ctx, "jwtx:jti:id"); err != nil {
+		t.Fatalf("marker not stored under jwtx:jti: prefix: %v", err)
+	}
+
+	if ok, err := first.Consume(ctx, "stale", time.Now().Add(-time.Minute)); err != nil || !ok {
+		t.Fatalf("Consume(past expiry) = %v, %v, want true", ok, err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if ok, _ := first.Consume(ctx, "stale", time.Now().Add(time.Minute)); !ok {
+		t.Fatal("marker with past expiry was kept")
+	}
+}
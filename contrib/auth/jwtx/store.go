@@ -0,0 +1,79 @@
+package jwtx
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bang-go/micro/store/cache"
+)
+
+const cacheStorePrefix = "jwtx:jti:"
+
+// Store records consumed one-time token IDs. Implementations shared by
+// several instances, e.g. Redis SET NX with an expiry, make a token single-use
+// across the whole deployment.
+type Store interface {
+	// Consume marks id as used until expiresAt and reports whether this call
+	// was the first to do so.
+	Consume(ctx context.Context, id string, expiresAt time.Time) (bool, error)
+}
+
+// MemoryStore is a Store for a single process.
+type MemoryStore struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	used      map[string]time.Time
+	nextSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{now: time.Now, used: make(map[string]time.Time)}
+}
+
+func (s *MemoryStore) Consume(_ context.Context, id string, expiresAt time.Time) (bool, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	if until, ok := s.used[id]; ok && now.Before(until) {
+		return false, nil
+	}
+	s.used[id] = expiresAt
+	return true, nil
+}
+
+// sweep drops expired IDs at most once a minute, so Consume stays cheap.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	for id, until := range s.used {
+		if !now.Before(until) {
+			delete(s.used, id)
+		}
+	}
+	s.nextSweep = now.Add(time.Minute)
+}
+
+// CacheStore is a Store on a store/cache Store. Backed by cache.NewRedis it
+// claims IDs with SET NX PX, so a token is single-use across instances.
+type CacheStore struct {
+	store cache.Store
+	now   func() time.Time
+}
+
+func NewCacheStore(store cache.Store) (*CacheStore, error) {
+	if store == nil {
+		return nil, ErrCacheStoreRequired
+	}
+	return &CacheStore{store: store, now: time.Now}, nil
+}
+
+func (s *CacheStore) Consume(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+	// A zero ttl means no expiry to the cache; keep the marker briefly instead.
+	ttl := max(expiresAt.Sub(s.now()), time.Millisecond)
+	return s.store.SetNX(ctx, cacheStorePrefix+id, []byte{1}, ttl)
+}
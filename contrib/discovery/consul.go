@@ -0,0 +1,276 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bang-go/micro/pkg/registry"
+)
+
+const (
+	defaultConsulAddr            = "http://127.0.0.1:8500"
+	defaultConsulTTL             = 15 * time.Second
+	defaultConsulDeregisterAfter = time.Minute
+	defaultConsulWait            = 5 * time.Minute
+	discoveryRetryDelay          = time.Second
+)
+
+type ConsulConfig struct {
+	// Addr of the Consul agent; defaults to http://127.0.0.1:8500.
+	Addr  string
+	Token string
+	// TTL of the health check; the registry passes it every TTL/3.
+	// Defaults to 15s.
+	TTL time.Duration
+	// DeregisterAfter removes instances whose check stayed critical this
+	// long, e.g. after a crash. Defaults to 1 minute.
+	DeregisterAfter time.Duration
+	HTTPClient      *http.Client
+}
+
+type consulRegistry struct {
+	config     ConsulConfig
+	httpClient *http.Client
+
+	mu         sync.Mutex
+	heartbeats map[string]context.CancelFunc
+}
+
+// NewConsulRegistry returns a registry backed by the Consul agent HTTP API.
+// Instances get a TTL check kept passing by a heartbeat goroutine, and
+// watchers use blocking health queries so only passing instances are served.
+func NewConsulRegistry(conf *ConsulConfig) (registry.Registry, error) {
+	var config ConsulConfig
+	if conf != nil {
+		config = *conf
+	}
+	config.Addr = strings.TrimRight(strings.TrimSpace(config.Addr), "/")
+	if config.Addr == "" {
+		config.Addr = defaultConsulAddr
+	}
+	if !strings.Contains(config.Addr, "://") {
+		config.Addr = "http://" + config.Addr
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaultConsulTTL
+	}
+	if config.DeregisterAfter <= 0 {
+		config.DeregisterAfter = defaultConsulDeregisterAfter
+	}
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &consulRegistry{config: config, httpClient: httpClient, heartbeats: make(map[string]context.CancelFunc)}, nil
+}
+
+type consulService struct {
+	ID      string            `json:"ID"`
+	Service string            `json:"Service,omitempty"`
+	Name    string            `json:"Name,omitempty"`
+	Address string            `json:"Address"`
+	Port    int               `json:"Port"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+	Check   *consulCheck      `json:"Check,omitempty"`
+}
+
+type consulCheck struct {
+	CheckID                        string `json:"CheckID"`
+	TTL                            string `json:"TTL"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter"`
+}
+
+type consulServiceEntry struct {
+	Node struct {
+		Address string `json:"Address"`
+	} `json:"Node"`
+	Service consulService `json:"Service"`
+}
+
+func (r *consulRegistry) Register(ctx context.Context, ins *registry.Instance) error {
+	ins, err := registry.Normalize(ins)
+	if err != nil {
+		return err
+	}
+	host, port, err := splitHostPort(ins.Addr)
+	if err != nil {
+		return err
+	}
+	id := consulServiceID(ins)
+	checkID := "service:" + id
+	if err := r.do(ctx, http.MethodPut, "/v1/agent/service/register", nil, consulService{
+		ID:      id,
+		Name:    ins.Name,
+		Address: host,
+		Port:    int(port),
+		Meta:    ins.Metadata,
+		Check: &consulCheck{
+			CheckID:                        checkID,
+			TTL:                            r.config.TTL.String(),
+			DeregisterCriticalServiceAfter: r.config.DeregisterAfter.String(),
+		},
+	}, nil, nil); err != nil {
+		return err
+	}
+	if err := r.passCheck(ctx, checkID); err != nil {
+		return err
+	}
+
+	heartbeatCtx, cancel := context.WithCancel(context.Background())
+	r.mu.Lock()
+	if previous, ok := r.heartbeats[id]; ok {
+		previous()
+	}
+	r.heartbeats[id] = cancel
+	r.mu.Unlock()
+	go r.heartbeat(heartbeatCtx, checkID)
+	return nil
+}
+
+func (r *consulRegistry) heartbeat(ctx context.Context, checkID string) {
+	ticker := time.NewTicker(r.config.TTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			passCtx, cancel := context.WithTimeout(ctx, r.config.TTL/3)
+			_ = r.passCheck(passCtx, checkID)
+			cancel()
+		}
+	}
+}
+
+func (r *consulRegistry) passCheck(ctx context.Context, checkID string) error {
+	return r.do(ctx, http.MethodPut, "/v1/agent/check/pass/"+url.PathEscape(checkID), nil, nil, nil, nil)
+}
+
+func (r *consulRegistry) Deregister(ctx context.Context, ins *registry.Instance) error {
+	ins, err := registry.Normalize(ins)
+	if err != nil {
+		return err
+	}
+	id := consulServiceID(ins)
+	r.mu.Lock()
+	if cancel, ok := r.heartbeats[id]; ok {
+		cancel()
+		delete(r.heartbeats, id)
+	}
+	r.mu.Unlock()
+	return r.do(ctx, http.MethodPut, "/v1/agent/service/deregister/"+url.PathEscape(id), nil, nil, nil, nil)
+}
+
+func (r *consulRegistry) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	if name == "" {
+		return nil, registry.ErrNameRequired
+	}
+	watcher := newSnapshotWatcher(ctx, nil)
+	go r.watch(watcher, name)
+	return watcher, nil
+}
+
+func (r *consulRegistry) watch(watcher *snapshotWatcher, name string) {
+	ctx := watcher.ctx
+	index := "0"
+	for ctx.Err() == nil {
+		query := url.Values{"passing": {"true"}, "index": {index}, "wait": {defaultConsulWait.String()}}
+		var entries []consulServiceEntry
+		header := http.Header{}
+		if err := r.do(ctx, http.MethodGet, "/v1/health/service/"+url.PathEscape(name), query, nil, &entries, header); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			watcher.fail(err)
+			select {
+			case <-time.After(discoveryRetryDelay):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		next := header.Get("X-Consul-Index")
+		// Consul asks clients to reset when the index goes backwards.
+		if n, err := strconv.ParseUint(next, 10, 64); err != nil || n == 0 {
+			next = "0"
+		} else if current, _ := strconv.ParseUint(index, 10, 64); n < current {
+			next = "0"
+		}
+		index = next
+
+		instances := make([]*registry.Instance, 0, len(entries))
+		for _, entry := range entries {
+			host := entry.Service.Address
+			if host == "" {
+				host = entry.Node.Address
+			}
+			instances = append(instances, &registry.Instance{
+				ID:       entry.Service.ID,
+				Name:     name,
+				Addr:     net.JoinHostPort(host, strconv.Itoa(entry.Service.Port)),
+				Metadata: entry.Service.Meta,
+			})
+		}
+		watcher.push(instances)
+	}
+}
+
+// do sends a request to the agent, decoding the response into out and copying
+// response headers into header when they are non-nil.
+func (r *consulRegistry) do(ctx context.Context, method, path string, query url.Values, in, out any, header http.Header) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	target := r.config.Addr + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	if r.config.Token != "" {
+		req.Header.Set("X-Consul-Token", r.config.Token)
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("discovery: consul %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	if header != nil {
+		for key, values := range resp.Header {
+			header[key] = values
+		}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func consulServiceID(ins *registry.Instance) string {
+	if ins.ID != ins.Addr {
+		return ins.ID
+	}
+	return ins.Name + "-" + ins.Addr
+}
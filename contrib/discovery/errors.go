@@ -5,4 +5,6 @@ import "errors"
 var (
 	ErrNilConfig        = errors.New("discovery: config is required")
 	ErrServerConfigMiss = errors.New("discovery: server configs or client endpoint is required")
+
+	ErrNamingClientRequired = errors.New("discovery: nacos naming client is required")
 )
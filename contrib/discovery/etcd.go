@@ -0,0 +1,325 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bang-go/micro/pkg/registry"
+)
+
+const (
+	defaultEtcdEndpoint = "http://127.0.0.1:2379"
+	defaultEtcdPrefix   = "/services/"
+	defaultEtcdTTL      = 10 * time.Second
+)
+
+type EtcdConfig struct {
+	// Endpoint of the etcd v3 JSON gateway; defaults to http://127.0.0.1:2379.
+	Endpoint string
+	// Prefix of instance keys, stored as <Prefix><name>/<id>. Defaults to
+	// "/services/".
+	Prefix string
+	// TTL of the lease attached to registered keys; the registry renews it
+	// every TTL/3. Defaults to 10s.
+	TTL        time.Duration
+	HTTPClient *http.Client
+}
+
+type etcdRegistry struct {
+	config     EtcdConfig
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	leases map[string]*etcdLease
+}
+
+type etcdLease struct {
+	id     string
+	cancel context.CancelFunc
+}
+
+// NewEtcdRegistry returns a registry backed by the etcd v3 JSON gateway.
+// Each instance is a key bound to a lease kept alive by a heartbeat goroutine,
+// so a crashed process disappears once its lease expires.
+func NewEtcdRegistry(conf *EtcdConfig) (registry.Registry, error) {
+	var config EtcdConfig
+	if conf != nil {
+		config = *conf
+	}
+	config.Endpoint = strings.TrimRight(strings.TrimSpace(config.Endpoint), "/")
+	if config.Endpoint == "" {
+		config.Endpoint = defaultEtcdEndpoint
+	}
+	if !strings.Contains(config.Endpoint, "://") {
+		config.Endpoint = "http://" + config.Endpoint
+	}
+	if config.Prefix == "" {
+		config.Prefix = defaultEtcdPrefix
+	}
+	if !strings.HasSuffix(config.Prefix, "/") {
+		config.Prefix += "/"
+	}
+	if config.TTL < time.Second {
+		config.TTL = defaultEtcdTTL
+	}
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &etcdRegistry{config: config, httpClient: httpClient, leases: make(map[string]*etcdLease)}, nil
+}
+
+type etcdKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type etcdRangeResponse struct {
+	Header struct {
+		Revision string `json:"revision"`
+	} `json:"header"`
+	Kvs []etcdKeyValue `json:"kvs"`
+}
+
+func (r *etcdRegistry) Register(ctx context.Context, ins *registry.Instance) error {
+	ins, err := registry.Normalize(ins)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(ins)
+	if err != nil {
+		return err
+	}
+
+	var grant struct {
+		ID    string `json:"ID"`
+		Error string `json:"error"`
+	}
+	if err := r.post(ctx, "/v3/lease/grant", map[string]any{"TTL": int64(r.config.TTL / time.Second)}, &grant); err != nil {
+		return err
+	}
+	if grant.ID == "" {
+		return fmt.Errorf("discovery: etcd lease grant failed: %s", grant.Error)
+	}
+	key := r.instanceKey(ins)
+	if err := r.post(ctx, "/v3/kv/put", map[string]any{
+		"key":   encodeKey(key),
+		"value": base64.StdEncoding.EncodeToString(value),
+		"lease": grant.ID,
+	}, nil); err != nil {
+		return err
+	}
+
+	heartbeatCtx, cancel := context.WithCancel(context.Background())
+	r.mu.Lock()
+	if previous, ok := r.leases[key]; ok {
+		previous.cancel()
+	}
+	r.leases[key] = &etcdLease{id: grant.ID, cancel: cancel}
+	r.mu.Unlock()
+	go r.keepAlive(heartbeatCtx, grant.ID)
+	return nil
+}
+
+func (r *etcdRegistry) keepAlive(ctx context.Context, leaseID string) {
+	ticker := time.NewTicker(r.config.TTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			keepAliveCtx, cancel := context.WithTimeout(ctx, r.config.TTL/3)
+			_ = r.post(keepAliveCtx, "/v3/lease/keepalive", map[string]any{"ID": leaseID}, nil)
+			cancel()
+		}
+	}
+}
+
+func (r *etcdRegistry) Deregister(ctx context.Context, ins *registry.Instance) error {
+	ins, err := registry.Normalize(ins)
+	if err != nil {
+		return err
+	}
+	key := r.instanceKey(ins)
+	r.mu.Lock()
+	lease, ok := r.leases[key]
+	delete(r.leases, key)
+	r.mu.Unlock()
+	if !ok {
+		return r.post(ctx, "/v3/kv/deleterange", map[string]any{"key": encodeKey(key)}, nil)
+	}
+	lease.cancel()
+	// Revoking the lease deletes the key with it.
+	return r.post(ctx, "/v3/lease/revoke", map[string]any{"ID": lease.id}, nil)
+}
+
+func (r *etcdRegistry) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	if name == "" {
+		return nil, registry.ErrNameRequired
+	}
+	watcher := newSnapshotWatcher(ctx, nil)
+	go r.watch(watcher, name)
+	return watcher, nil
+}
+
+func (r *etcdRegistry) watch(watcher *snapshotWatcher, name string) {
+	ctx := watcher.ctx
+	prefix := r.config.Prefix + name + "/"
+	for ctx.Err() == nil {
+		revision, err := r.load(ctx, watcher, prefix)
+		if err == nil {
+			// The stream returns after every change so the next load reads
+			// a consistent snapshot.
+			err = r.waitForChange(ctx, prefix, revision+1)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			watcher.fail(err)
+			select {
+			case <-time.After(discoveryRetryDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (r *etcdRegistry) load(ctx context.Context, watcher *snapshotWatcher, prefix string) (int64, error) {
+	var resp etcdRangeResponse
+	if err := r.post(ctx, "/v3/kv/range", map[string]any{
+		"key":       encodeKey(prefix),
+		"range_end": encodeKey(prefixEnd(prefix)),
+	}, &resp); err != nil {
+		return 0, err
+	}
+	instances := make([]*registry.Instance, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		data, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			continue
+		}
+		var ins registry.Instance
+		if json.Unmarshal(data, &ins) != nil || ins.Addr == "" {
+			continue
+		}
+		instances = append(instances, &ins)
+	}
+	watcher.push(instances)
+	revision, _ := strconv.ParseInt(resp.Header.Revision, 10, 64)
+	return revision, nil
+}
+
+// waitForChange opens a watch stream from revision and returns once it
+// reports an event under prefix.
+func (r *etcdRegistry) waitForChange(ctx context.Context, prefix string, revision int64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]any{"create_request": map[string]any{
+		"key":            encodeKey(prefix),
+		"range_end":      encodeKey(prefixEnd(prefix)),
+		"start_revision": strconv.FormatInt(revision, 10),
+	}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.config.Endpoint+"/v3/watch", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("discovery: etcd watch: %s", resp.Status)
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(resp.Body))
+	for {
+		var message struct {
+			Result struct {
+				Events   []json.RawMessage `json:"events"`
+				Canceled bool              `json:"canceled"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := decoder.Decode(&message); err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("discovery: etcd watch stream closed")
+			}
+			return err
+		}
+		if message.Error != nil {
+			return fmt.Errorf("discovery: etcd watch: %s", message.Error.Message)
+		}
+		if message.Result.Canceled {
+			return fmt.Errorf("discovery: etcd watch canceled")
+		}
+		if len(message.Result.Events) > 0 {
+			return nil
+		}
+	}
+}
+
+func (r *etcdRegistry) post(ctx context.Context, path string, in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.config.Endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("discovery: etcd %s: %s: %s", path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (r *etcdRegistry) instanceKey(ins *registry.Instance) string {
+	return r.config.Prefix + ins.Name + "/" + ins.ID
+}
+
+func encodeKey(key string) string {
+	return base64.StdEncoding.EncodeToString([]byte(key))
+}
+
+// prefixEnd returns the smallest key greater than every key with prefix.
+func prefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	return "\x00"
+}
@@ -0,0 +1,133 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/bang-go/micro/pkg/registry"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+type NacosRegistryConfig struct {
+	// GroupName defaults to DEFAULT_GROUP on the server.
+	GroupName   string
+	ClusterName string
+	// Weight of registered instances; defaults to 1.
+	Weight float64
+}
+
+type nacosRegistry struct {
+	client naming_client.INamingClient
+	config NacosRegistryConfig
+}
+
+// NewNacosRegistry adapts a naming client to registry.Registry. Instances are
+// registered as ephemeral, so the Nacos client keeps them alive with its own
+// heartbeats and the server drops them when the process dies.
+func NewNacosRegistry(client naming_client.INamingClient, conf *NacosRegistryConfig) (registry.Registry, error) {
+	if client == nil {
+		return nil, ErrNamingClientRequired
+	}
+	var config NacosRegistryConfig
+	if conf != nil {
+		config = *conf
+	}
+	if config.Weight <= 0 {
+		config.Weight = 1
+	}
+	return &nacosRegistry{client: client, config: config}, nil
+}
+
+func (r *nacosRegistry) Register(_ context.Context, ins *registry.Instance) error {
+	ins, err := registry.Normalize(ins)
+	if err != nil {
+		return err
+	}
+	host, port, err := splitHostPort(ins.Addr)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip:          host,
+		Port:        port,
+		Weight:      r.config.Weight,
+		Enable:      true,
+		Healthy:     true,
+		Metadata:    ins.Metadata,
+		ClusterName: r.config.ClusterName,
+		ServiceName: ins.Name,
+		GroupName:   r.config.GroupName,
+		Ephemeral:   true,
+	})
+	return err
+}
+
+func (r *nacosRegistry) Deregister(_ context.Context, ins *registry.Instance) error {
+	ins, err := registry.Normalize(ins)
+	if err != nil {
+		return err
+	}
+	host, port, err := splitHostPort(ins.Addr)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.DeregisterInstance(vo.DeregisterInstanceParam{
+		Ip:          host,
+		Port:        port,
+		Cluster:     r.config.ClusterName,
+		ServiceName: ins.Name,
+		GroupName:   r.config.GroupName,
+		Ephemeral:   true,
+	})
+	return err
+}
+
+func (r *nacosRegistry) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	if name == "" {
+		return nil, registry.ErrNameRequired
+	}
+	var clusters []string
+	if r.config.ClusterName != "" {
+		clusters = []string{r.config.ClusterName}
+	}
+
+	param := &vo.SubscribeParam{ServiceName: name, GroupName: r.config.GroupName, Clusters: clusters}
+	watcher := newSnapshotWatcher(ctx, func() {
+		_ = r.client.Unsubscribe(param)
+	})
+	param.SubscribeCallback = func(instances []model.Instance, err error) {
+		if err != nil {
+			watcher.fail(err)
+			return
+		}
+		watcher.push(nacosInstances(name, instances))
+	}
+
+	current, err := r.client.SelectAllInstances(vo.SelectAllInstancesParam{ServiceName: name, GroupName: r.config.GroupName, Clusters: clusters})
+	if err != nil {
+		// Nacos reports an unknown or empty service as an error; the
+		// subscription still delivers instances once they appear.
+		current = nil
+	}
+	watcher.push(nacosInstances(name, current))
+	if err := r.client.Subscribe(param); err != nil {
+		_ = watcher.Stop()
+		return nil, err
+	}
+	return watcher, nil
+}
+
+func nacosInstances(name string, instances []model.Instance) []*registry.Instance {
+	out := make([]*registry.Instance, 0, len(instances))
+	for _, ins := range instances {
+		if !ins.Healthy || !ins.Enable || ins.Weight <= 0 {
+			continue
+		}
+		addr := net.JoinHostPort(ins.Ip, strconv.FormatUint(ins.Port, 10))
+		out = append(out, &registry.Instance{ID: addr, Name: name, Addr: addr, Metadata: ins.Metadata})
+	}
+	return out
+}
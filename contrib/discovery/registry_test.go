@@ -0,0 +1,305 @@
+package discovery
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/pkg/registry"
+)
+
+// changeLog is a revision counter whose waiters wake on every change.
+type changeLog struct {
+	mu       sync.Mutex
+	revision int
+	changed  chan struct{}
+}
+
+func newChangeLog() *changeLog {
+	return &changeLog{revision: 1, changed: make(chan struct{})}
+}
+
+// bump must be called with mu held.
+func (l *changeLog) bump() {
+	l.revision++
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// waitAfter blocks until the revision exceeds after or ctx is done.
+func (l *changeLog) waitAfter(ctx context.Context, after int) {
+	for {
+		l.mu.Lock()
+		if l.revision > after {
+			l.mu.Unlock()
+			return
+		}
+		changed := l.changed
+		l.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func newFakeConsul(t *testing.T) (*httptest.Server, func() int) {
+	t.Helper()
+
+	log := newChangeLog()
+	services := map[string]consulService{}
+	passes := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /v1/agent/service/register", func(w http.ResponseWriter, r *http.Request) {
+		var service consulService
+		if err := json.NewDecoder(r.Body).Decode(&service); err != nil || service.Check == nil {
+			http.Error(w, "bad service", http.StatusBadRequest)
+			return
+		}
+		log.mu.Lock()
+		services[service.ID] = service
+		log.bump()
+		log.mu.Unlock()
+	})
+	mux.HandleFunc("PUT /v1/agent/service/deregister/{id}", func(w http.ResponseWriter, r *http.Request) {
+		log.mu.Lock()
+		delete(services, r.PathValue("id"))
+		log.bump()
+		log.mu.Unlock()
+	})
+	mux.HandleFunc("PUT /v1/agent/check/pass/{id}", func(w http.ResponseWriter, r *http.Request) {
+		log.mu.Lock()
+		passes++
+		log.mu.Unlock()
+	})
+	mux.HandleFunc("GET /v1/health/service/{name}", func(w http.ResponseWriter, r *http.Request) {
+		index, _ := strconv.Atoi(r.URL.Query().Get("index"))
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+		log.waitAfter(ctx, index)
+
+		log.mu.Lock()
+		var entries []consulServiceEntry
+		for _, service := range services {
+			if service.Name != r.PathValue("name") {
+				continue
+			}
+			var entry consulServiceEntry
+			entry.Service = service
+			entries = append(entries, entry)
+		}
+		w.Header().Set("X-Consul-Index", strconv.Itoa(log.revision))
+		log.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(entries)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, func() int {
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		return passes
+	}
+}
+
+type fakeEtcd struct {
+	log    *changeLog
+	kvs    map[string]string
+	leases map[string][]string
+	grants int
+}
+
+func newFakeEtcd(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	etcd := &fakeEtcd{log: newChangeLog(), kvs: map[string]string{}, leases: map[string][]string{}}
+	decode := func(value string) string {
+		data, _ := base64.StdEncoding.DecodeString(value)
+		return string(data)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/lease/grant", func(w http.ResponseWriter, r *http.Request) {
+		etcd.log.mu.Lock()
+		etcd.grants++
+		id := strconv.Itoa(etcd.grants)
+		etcd.log.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]string{"ID": id, "TTL": "10"})
+	})
+	mux.HandleFunc("POST /v3/lease/keepalive", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{}}`))
+	})
+	mux.HandleFunc("POST /v3/lease/revoke", func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ ID string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		etcd.log.mu.Lock()
+		for _, key := range etcd.leases[req.ID] {
+			delete(etcd.kvs, key)
+		}
+		delete(etcd.leases, req.ID)
+		etcd.log.bump()
+		etcd.log.mu.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("POST /v3/kv/put", func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Key, Value, Lease string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		etcd.log.mu.Lock()
+		key := decode(req.Key)
+		etcd.kvs[key] = req.Value
+		etcd.leases[req.Lease] = append(etcd.leases[req.Lease], key)
+		etcd.log.bump()
+		etcd.log.mu.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("POST /v3/kv/range", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Key      string `json:"key"`
+			RangeEnd string `json:"range_end"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		from, to := decode(req.Key), decode(req.RangeEnd)
+		etcd.log.mu.Lock()
+		var resp etcdRangeResponse
+		resp.Header.Revision = strconv.Itoa(etcd.log.revision)
+		for key, value := range etcd.kvs {
+			if key >= from && key < to {
+				resp.Kvs = append(resp.Kvs, etcdKeyValue{Key: base64.StdEncoding.EncodeToString([]byte(key)), Value: value})
+			}
+		}
+		etcd.log.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("POST /v3/watch", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			CreateRequest struct {
+				StartRevision string `json:"start_revision"`
+			} `json:"create_request"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		start, _ := strconv.Atoi(req.CreateRequest.StartRevision)
+		_, _ = w.Write([]byte(`{"result":{"created":true}}` + "\n"))
+		w.(http.Flusher).Flush()
+		etcd.log.waitAfter(r.Context(), start-1)
+		if r.Context().Err() != nil {
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"events":[{"type":"PUT"}]}}` + "\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// nextAddrs reads the next snapshot and returns its sorted addresses.
+func nextAddrs(t *testing.T, watcher registry.Watcher) string {
+	t.Helper()
+
+	type result struct {
+		instances []*registry.Instance
+		err       error
+	}
+	done := make(chan result, 1)
+	go func() {
+		instances, err := watcher.Next()
+		done <- result{instances, err}
+	}()
+	select {
+	case got := <-done:
+		if got.err != nil {
+			t.Fatalf("Next() error = %v", got.err)
+		}
+		addrs := make([]string, 0, len(got.instances))
+		for _, ins := range got.instances {
+			addrs = append(addrs, ins.Addr)
+		}
+		return strings.Join(addrs, ",")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next() did not return")
+		return ""
+	}
+}
+
+func exerciseRegistry(t *testing.T, reg registry.Registry) {
+	t.Helper()
+
+	ctx := context.Background()
+	watcher, err := reg.Watch(ctx, "orders")
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer watcher.Stop()
+	if got := nextAddrs(t, watcher); got != "" {
+		t.Fatalf("initial snapshot = %q, want empty", got)
+	}
+
+	first := &registry.Instance{Name: "orders", Addr: "10.0.0.1:9000", Metadata: map[string]string{"zone": "a"}}
+	second := &registry.Instance{Name: "orders", Addr: "10.0.0.2:9000"}
+	for _, ins := range []*registry.Instance{first, second} {
+		if err := reg.Register(ctx, ins); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+	if err := reg.Register(ctx, &registry.Instance{Name: "billing", Addr: "10.0.0.3:9000"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for got := nextAddrs(t, watcher); got != "10.0.0.1:9000,10.0.0.2:9000"; got = nextAddrs(t, watcher) {
+		if time.Now().After(deadline) {
+			t.Fatalf("snapshot = %q", got)
+		}
+	}
+
+	if err := reg.Deregister(ctx, first); err != nil {
+		t.Fatalf("Deregister() error = %v", err)
+	}
+	for got := nextAddrs(t, watcher); got != "10.0.0.2:9000"; got = nextAddrs(t, watcher) {
+		if time.Now().After(deadline) {
+			t.Fatalf("snapshot after deregister = %q", got)
+		}
+	}
+
+	if err := watcher.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if _, err := watcher.Next(); err != registry.ErrWatcherStopped {
+		t.Fatalf("Next() after Stop error = %v", err)
+	}
+}
+
+func TestConsulRegistry(t *testing.T) {
+	server, passes := newFakeConsul(t)
+	reg, err := NewConsulRegistry(&ConsulConfig{Addr: server.URL, TTL: 150 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewConsulRegistry() error = %v", err)
+	}
+	exerciseRegistry(t, reg)
+
+	// The remaining instances keep passing their TTL check.
+	time.Sleep(200 * time.Millisecond)
+	if got := passes(); got <= 3 {
+		t.Fatalf("check passes = %d, want heartbeats", got)
+	}
+}
+
+func TestEtcdRegistry(t *testing.T) {
+	server := newFakeEtcd(t)
+	reg, err := NewEtcdRegistry(&EtcdConfig{Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("NewEtcdRegistry() error = %v", err)
+	}
+	exerciseRegistry(t, reg)
+
+	if got := prefixEnd("/services/orders/"); got != "/services/orders0" {
+		t.Fatalf("prefixEnd() = %q", got)
+	}
+}
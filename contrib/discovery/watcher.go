@@ -0,0 +1,107 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/bang-go/micro/pkg/registry"
+)
+
+// snapshotWatcher hands the latest instance set to Next, coalescing updates
+// that arrive faster than the caller reads them.
+type snapshotWatcher struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	onStop  func()
+	stopped sync.Once
+
+	mu      sync.Mutex
+	latest  []*registry.Instance
+	seen    bool
+	err     error
+	pending bool
+	ready   chan struct{}
+}
+
+func newSnapshotWatcher(ctx context.Context, onStop func()) *snapshotWatcher {
+	ctx, cancel := context.WithCancel(ctx)
+	return &snapshotWatcher{ctx: ctx, cancel: cancel, onStop: onStop, ready: make(chan struct{}, 1)}
+}
+
+// push records a snapshot; identical consecutive snapshots are dropped.
+func (w *snapshotWatcher) push(instances []*registry.Instance) {
+	registry.Sort(instances)
+	w.mu.Lock()
+	if !w.seen || !registry.Equal(instances, w.latest) {
+		w.latest = instances
+		w.seen = true
+		w.err = nil
+		w.pending = true
+	}
+	w.mu.Unlock()
+	w.signal()
+}
+
+// fail surfaces a backend error to the next Next call; the watcher keeps
+// retrying in the background.
+func (w *snapshotWatcher) fail(err error) {
+	w.mu.Lock()
+	w.err = err
+	w.mu.Unlock()
+	w.signal()
+}
+
+func (w *snapshotWatcher) signal() {
+	select {
+	case w.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (w *snapshotWatcher) Next() ([]*registry.Instance, error) {
+	for {
+		w.mu.Lock()
+		if w.pending {
+			w.pending = false
+			instances := w.latest
+			w.mu.Unlock()
+			return instances, nil
+		}
+		if err := w.err; err != nil {
+			w.err = nil
+			w.mu.Unlock()
+			return nil, err
+		}
+		w.mu.Unlock()
+
+		select {
+		case <-w.ready:
+		case <-w.ctx.Done():
+			return nil, registry.ErrWatcherStopped
+		}
+	}
+}
+
+func (w *snapshotWatcher) Stop() error {
+	w.stopped.Do(func() {
+		w.cancel()
+		if w.onStop != nil {
+			w.onStop()
+		}
+	})
+	return nil
+}
+
+func splitHostPort(addr string) (string, uint64, error) {
+	host, portText, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.ParseUint(portText, 10, 16)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, port, nil
+}
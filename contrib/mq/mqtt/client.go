@@ -46,6 +46,10 @@ type Config struct {
 	AutoReconnect   bool
 	CleanSession    bool
 	OrderMatters    bool
+	// AutoAckDisabled acks a message only after its handler returns, so the
+	// broker redelivers messages still queued in a SubscribeShared pool when
+	// the session resumes.
+	AutoAckDisabled bool
 
 	DefaultPublishHandler pahomqtt.MessageHandler
 	OnConnect             pahomqtt.OnConnectHandler
@@ -66,6 +70,7 @@ type Client interface {
 	SubscribeMultiple(ctx context.Context, filters map[string]byte, callback MessageHandler) error
 	Unsubscribe(ctx context.Context, topics ...string) error
 	AddRoute(topic string, callback MessageHandler) error
+	SubscribeShared(ctx context.Context, group, topic string, qos byte, callback MessageHandler, opts *SharedOptions) (*SharedSubscription, error)
 }
 
 type clientEntity struct {
@@ -217,6 +222,7 @@ func prepareConfig(conf *Config) (*Config, *pahomqtt.ClientOptions, error) {
 	options.SetAutoReconnect(cloned.AutoReconnect)
 	options.SetCleanSession(cloned.CleanSession)
 	options.SetOrderMatters(cloned.OrderMatters)
+	options.SetAutoAckDisabled(cloned.AutoAckDisabled)
 	options.SetConnectTimeout(cloned.ConnectTimeout)
 
 	if cloned.KeepAlive > 0 {
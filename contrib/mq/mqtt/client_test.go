@@ -400,6 +400,7 @@ type fakeMQTTClient struct {
 
 	lastPublishedTopic    string
 	lastSubscribeTopic    string
+	lastSubscribeCallback pahomqtt.MessageHandler
 	lastAddRouteTopic     string
 	lastSubscribeMany     map[string]byte
 	lastUnsubscribeTopics []string
@@ -429,6 +430,7 @@ func (f *fakeMQTTClient) Publish(topic string, qos byte, retained bool, payload
 
 func (f *fakeMQTTClient) Subscribe(topic string, qos byte, callback pahomqtt.MessageHandler) pahomqtt.Token {
 	f.lastSubscribeTopic = topic
+	f.lastSubscribeCallback = callback
 	return f.subscribeToken
 }
 
@@ -15,4 +15,9 @@ var (
 	ErrInvalidAliyunAuthMode  = errors.New("mqtt: invalid aliyun auth mode")
 	ErrDuplicateFilterTopic   = errors.New("mqtt: duplicate filter topic after normalization")
 	ErrOperationTokenRequired = errors.New("mqtt: operation token is required")
+	ErrHandlerRequired        = errors.New("mqtt: message handler is required")
+	ErrGroupRequired          = errors.New("mqtt: share group is required")
+	ErrInvalidShareGroup      = errors.New("mqtt: share group must not contain '/', '+' or '#'")
+	ErrTopicAlreadyShared     = errors.New("mqtt: topic already has a $share prefix")
+	ErrHandlerPanic           = errors.New("mqtt: message handler panicked")
 )
@@ -0,0 +1,188 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	sharePrefix            = "$share/"
+	defaultSharedWorkers   = 4
+	defaultSharedQueueSize = 256
+	defaultRedeliveries    = 3
+)
+
+type SharedOptions struct {
+	// Workers handle messages concurrently, default 4. Use 1 when a group
+	// member must see messages in order.
+	Workers   int
+	QueueSize int
+	// MaxRedeliveries is how many times a message whose handler panicked is
+	// queued again locally, default 3; negative disables retries.
+	MaxRedeliveries int
+	// OnDrop is called with ErrHandlerPanic when a message gives up.
+	OnDrop func(pahomqtt.Message, error)
+}
+
+// SharedTopic returns the MQTT 5 shared subscription filter
+// $share/group/topic.
+func SharedTopic(group, topic string) string {
+	return sharePrefix + group + "/" + topic
+}
+
+// SharedSubscription dispatches messages of a shared subscription to a
+// worker pool. The broker balances the group across instances; within one
+// instance the pool balances across workers.
+type SharedSubscription struct {
+	client   pahomqtt.Client
+	filter   string
+	callback MessageHandler
+	options  SharedOptions
+	wait     func(context.Context, pahomqtt.Token) error
+
+	queue     chan sharedDelivery
+	done      chan struct{}
+	closeOnce sync.Once
+	workers   sync.WaitGroup
+}
+
+type sharedDelivery struct {
+	msg      pahomqtt.Message
+	attempts int
+}
+
+func (c *clientEntity) SubscribeShared(ctx context.Context, group, topic string, qos byte, callback MessageHandler, opts *SharedOptions) (*SharedSubscription, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	if callback == nil {
+		return nil, ErrHandlerRequired
+	}
+	group = strings.TrimSpace(group)
+	if group == "" {
+		return nil, ErrGroupRequired
+	}
+	if strings.ContainsAny(group, "/+#") {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidShareGroup, group)
+	}
+	topic = normalizeTopic(topic)
+	if topic == "" {
+		return nil, ErrTopicRequired
+	}
+	if strings.HasPrefix(topic, sharePrefix) {
+		return nil, fmt.Errorf("%w: %s", ErrTopicAlreadyShared, topic)
+	}
+
+	options := SharedOptions{}
+	if opts != nil {
+		options = *opts
+	}
+	if options.Workers <= 0 {
+		options.Workers = defaultSharedWorkers
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaultSharedQueueSize
+	}
+	if options.MaxRedeliveries == 0 {
+		options.MaxRedeliveries = defaultRedeliveries
+	}
+
+	sub := &SharedSubscription{
+		client:   c.client,
+		filter:   SharedTopic(group, topic),
+		callback: callback,
+		options:  options,
+		wait: func(ctx context.Context, token pahomqtt.Token) error {
+			return waitToken(ctx, c.operationWait, token)
+		},
+		queue: make(chan sharedDelivery, options.QueueSize),
+		done:  make(chan struct{}),
+	}
+	for range options.Workers {
+		sub.workers.Add(1)
+		go sub.work()
+	}
+	if err := sub.wait(ctx, c.client.Subscribe(sub.filter, qos, sub.enqueue)); err != nil {
+		sub.stop()
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Topic returns the $share filter subscribed on the broker.
+func (s *SharedSubscription) Topic() string {
+	return s.filter
+}
+
+// Close unsubscribes and waits for running handlers. Queued messages are not
+// handled; with Config.AutoAckDisabled they stay unacknowledged, so the
+// broker can redeliver them.
+func (s *SharedSubscription) Close(ctx context.Context) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	// Release a blocked enqueue first: with OrderMatters paho routes messages
+	// on the goroutine that would also read the UNSUBACK.
+	s.stop()
+	return s.wait(ctx, s.client.Unsubscribe(s.filter))
+}
+
+func (s *SharedSubscription) stop() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	s.workers.Wait()
+}
+
+// enqueue blocks while the queue is full, pushing back on the broker
+// instead of dropping messages.
+func (s *SharedSubscription) enqueue(_ pahomqtt.Client, msg pahomqtt.Message) {
+	select {
+	case s.queue <- sharedDelivery{msg: msg}:
+	case <-s.done:
+	}
+}
+
+func (s *SharedSubscription) work() {
+	defer s.workers.Done()
+	for {
+		select {
+		case d := <-s.queue:
+			s.handle(d)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *SharedSubscription) handle(d sharedDelivery) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.requeue(d, fmt.Errorf("%w: %v", ErrHandlerPanic, r))
+		}
+	}()
+	s.callback(s.client, d.msg)
+	d.msg.Ack()
+}
+
+func (s *SharedSubscription) requeue(d sharedDelivery, err error) {
+	if d.attempts >= s.options.MaxRedeliveries {
+		if s.options.OnDrop != nil {
+			s.options.OnDrop(d.msg, err)
+		}
+		d.msg.Ack()
+		return
+	}
+	d.attempts++
+	// Workers are the only readers, so requeueing must not block one of them.
+	go func() {
+		select {
+		case s.queue <- d:
+		case <-s.done:
+		}
+	}()
+}
@@ -0,0 +1,174 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+func openSharedTestClient(t *testing.T) (*fakeMQTTClient, Client) {
+	t.Helper()
+	fake := &fakeMQTTClient{
+		connectToken:     newFakeToken(nil),
+		subscribeToken:   newFakeToken(nil),
+		unsubscribeToken: newFakeToken(nil),
+	}
+	client, err := Open(context.Background(), &Config{
+		Brokers:  []string{"tcp://localhost:1883"},
+		ClientID: "client",
+		Username: "user",
+		Password: "pass",
+		newClient: func(*pahomqtt.ClientOptions) pahomqtt.Client {
+			return fake
+		},
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return fake, client
+}
+
+func TestSubscribeSharedDispatchesToWorkers(t *testing.T) {
+	fake, client := openSharedTestClient(t)
+
+	var (
+		running atomic.Int32
+		peak    atomic.Int32
+		handled sync.WaitGroup
+	)
+	sub, err := client.SubscribeShared(context.Background(), "billing", " devices/+/telemetry ", 1, func(_ pahomqtt.Client, _ pahomqtt.Message) {
+		defer handled.Done()
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+	}, &SharedOptions{Workers: 3})
+	if err != nil {
+		t.Fatalf("SubscribeShared() error = %v", err)
+	}
+	if got, want := fake.lastSubscribeTopic, "$share/billing/devices/+/telemetry"; got != want {
+		t.Fatalf("subscribed topic = %q, want %q", got, want)
+	}
+	if sub.Topic() != fake.lastSubscribeTopic {
+		t.Fatalf("Topic() = %q", sub.Topic())
+	}
+
+	msgs := make([]*fakeMessage, 6)
+	handled.Add(len(msgs))
+	for i := range msgs {
+		msgs[i] = &fakeMessage{}
+		fake.lastSubscribeCallback(fake, msgs[i])
+	}
+	handled.Wait()
+
+	if got := peak.Load(); got != 3 {
+		t.Fatalf("peak concurrency = %d, want 3", got)
+	}
+	for i, msg := range msgs {
+		if msg.acks.Load() != 1 {
+			t.Fatalf("message %d acked %d times", i, msg.acks.Load())
+		}
+	}
+	if err := sub.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got, want := fake.lastUnsubscribeTopics, []string{"$share/billing/devices/+/telemetry"}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("unsubscribed topics = %v, want %v", got, want)
+	}
+}
+
+func TestSubscribeSharedRequeuesAfterPanic(t *testing.T) {
+	fake, client := openSharedTestClient(t)
+
+	var attempts atomic.Int32
+	dropped := make(chan error, 1)
+	sub, err := client.SubscribeShared(context.Background(), "billing", "orders", 1, func(_ pahomqtt.Client, msg pahomqtt.Message) {
+		if attempts.Add(1) < 3 || string(msg.Payload()) == "poison" {
+			panic("boom")
+		}
+	}, &SharedOptions{Workers: 1, MaxRedeliveries: 2, OnDrop: func(_ pahomqtt.Message, err error) {
+		dropped <- err
+	}})
+	if err != nil {
+		t.Fatalf("SubscribeShared() error = %v", err)
+	}
+	defer sub.Close(context.Background())
+
+	flaky := &fakeMessage{}
+	fake.lastSubscribeCallback(fake, flaky)
+	waitFor(t, func() bool { return flaky.acks.Load() == 1 })
+	if got := attempts.Load(); got != 3 {
+		t.Fatalf("handler attempts = %d, want 3", got)
+	}
+
+	poison := &fakeMessage{payload: []byte("poison")}
+	fake.lastSubscribeCallback(fake, poison)
+	select {
+	case err := <-dropped:
+		if !errors.Is(err, ErrHandlerPanic) {
+			t.Fatalf("OnDrop error = %v, want ErrHandlerPanic", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("poison message was not dropped")
+	}
+	if got := attempts.Load(); got != 6 {
+		t.Fatalf("handler attempts = %d, want 6", got)
+	}
+	waitFor(t, func() bool { return poison.acks.Load() == 1 })
+}
+
+func TestSubscribeSharedValidation(t *testing.T) {
+	_, client := openSharedTestClient(t)
+	noop := func(pahomqtt.Client, pahomqtt.Message) {}
+
+	tests := []struct {
+		group, topic string
+		callback     MessageHandler
+		want         error
+	}{
+		{"g", "t", nil, ErrHandlerRequired},
+		{" ", "t", noop, ErrGroupRequired},
+		{"a/b", "t", noop, ErrInvalidShareGroup},
+		{"g", " ", noop, ErrTopicRequired},
+		{"g", "$share/other/t", noop, ErrTopicAlreadyShared},
+	}
+	for _, tt := range tests {
+		if _, err := client.SubscribeShared(context.Background(), tt.group, tt.topic, 0, tt.callback, nil); !errors.Is(err, tt.want) {
+			t.Fatalf("SubscribeShared(%q, %q) error = %v, want %v", tt.group, tt.topic, err, tt.want)
+		}
+	}
+	if _, err := client.SubscribeShared(nil, "g", "t", 0, noop, nil); !errors.Is(err, ErrContextRequired) {
+		t.Fatalf("SubscribeShared(nil ctx) error = %v", err)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type fakeMessage struct {
+	pahomqtt.Message
+	payload []byte
+	acks    atomic.Int32
+}
+
+func (m *fakeMessage) Payload() []byte { return m.payload }
+
+func (m *fakeMessage) Ack() { m.acks.Add(1) }
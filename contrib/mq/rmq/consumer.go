@@ -3,6 +3,7 @@ package rmq
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/apache/rocketmq-clients/golang/v5/credentials"
 	v2 "github.com/apache/rocketmq-clients/golang/v5/protocol/v2"
 	"github.com/bang-go/micro/telemetry/logger"
+	microtrace "github.com/bang-go/micro/telemetry/trace"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -39,13 +41,17 @@ type ConsumerConfig struct {
 	MaxMessageNum           int32
 	InvisibleDuration       time.Duration
 	StartTimeout            time.Duration
+	// VerifyOnStart checks the subscribed topics and Group with
+	// VerifyResources before starting, so missing resources fail Start.
+	VerifyOnStart bool
 
 	Logger            *logger.Logger
 	EnableLogger      bool
 	DisableMetrics    bool
 	MetricsRegisterer prometheus.Registerer
 
-	newConsumer consumerFactory
+	newConsumer    consumerFactory
+	newResourceAPI resourceAPIFactory
 }
 
 type Consumer interface {
@@ -62,6 +68,7 @@ type consumerEntity struct {
 	maxMessages       int32
 	invisibleDuration time.Duration
 	startTimeout      time.Duration
+	verify            *VerifyConfig
 	logger            *logger.Logger
 	enableLogger      bool
 	metrics           *metrics
@@ -101,6 +108,7 @@ func NewSimpleConsumer(conf *ConsumerConfig) (Consumer, error) {
 		maxMessages:       boundedMaxMessages(config.MaxMessageNum),
 		invisibleDuration: invisibleDurationOrDefault(config.InvisibleDuration),
 		startTimeout:      config.StartTimeout,
+		verify:            consumerVerifyConfig(config),
 		logger:            config.Logger,
 		enableLogger:      config.EnableLogger,
 		metrics:           metrics,
@@ -116,6 +124,12 @@ func (c *consumerEntity) Start(ctx context.Context) error {
 	startCtx, cancel := timeoutContext(ctx, c.startTimeout)
 	defer cancel()
 
+	if c.verify != nil {
+		if err := VerifyResources(startCtx, c.verify); err != nil {
+			return fmt.Errorf("rmq: start consumer failed: %w", err)
+		}
+	}
+
 	done := make(chan error, 1)
 	go func() {
 		done <- c.consumer.Start()
@@ -145,6 +159,12 @@ func (c *consumerEntity) Receive(ctx context.Context) ([]*MessageView, error) {
 	status := receiveStatus(err)
 	duration := time.Since(startedAt)
 
+	for _, message := range messages {
+		if message != nil {
+			microtrace.QueueDequeue(ctx, traceMessagingSystem, message.GetTopic(), message.GetMessageId())
+		}
+	}
+
 	if c.metrics != nil {
 		c.metrics.consumerRequestsTotal.WithLabelValues(c.name, "receive", status).Inc()
 		c.metrics.consumerDuration.WithLabelValues(c.name, "receive", status).Observe(duration.Seconds())
@@ -272,6 +292,26 @@ func prepareConsumerConfig(conf *ConsumerConfig) (*ConsumerConfig, *rmqClient.Co
 	return &cloned, sdkConfig, options, nil
 }
 
+func consumerVerifyConfig(config *ConsumerConfig) *VerifyConfig {
+	if !config.VerifyOnStart {
+		return nil
+	}
+	topics := make([]string, 0, len(config.SubscriptionExpressions))
+	for topic := range config.SubscriptionExpressions {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return &VerifyConfig{
+		Endpoint:  config.Endpoint,
+		Namespace: config.Namespace,
+		AccessKey: config.AccessKey,
+		SecretKey: config.SecretKey,
+		Topics:    topics,
+		Group:     config.Group,
+		newAPI:    config.newResourceAPI,
+	}
+}
+
 func boundedMaxMessages(value int32) int32 {
 	if value <= 0 {
 		return defaultReceiveMaxMessages
@@ -14,4 +14,11 @@ var (
 	ErrTopicRequired            = errors.New("rmq: topic is required")
 	ErrMessageGroupEmpty        = errors.New("rmq: message group is required")
 	ErrMessageViewNil           = errors.New("rmq: message view is required")
+	ErrNilReplayConfig          = errors.New("rmq: replay config is required")
+	ErrReplaySourceRequired     = errors.New("rmq: replay source consumer is required")
+	ErrReplayProducerRequired   = errors.New("rmq: replay producer is required")
+	ErrReplayInvalidRange       = errors.New("rmq: replay time range end is before start")
+	ErrNilVerifyConfig          = errors.New("rmq: verify config is required")
+	ErrTopicNotFound            = errors.New("rmq: topic not found")
+	ErrConsumerGroupNotFound    = errors.New("rmq: consumer group not found")
 )
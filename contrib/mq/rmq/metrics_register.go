@@ -12,6 +12,7 @@ type metrics struct {
 	consumerRequestsTotal *prometheus.CounterVec
 	consumerDuration      *prometheus.HistogramVec
 	consumerMessagesTotal *prometheus.CounterVec
+	replayMessagesTotal   *prometheus.CounterVec
 }
 
 var (
@@ -65,6 +66,13 @@ func newRMQMetrics(registerer prometheus.Registerer) *metrics {
 			},
 			[]string{"name", "status"},
 		),
+		replayMessagesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rmq_replay_messages_total",
+				Help: "Total number of RocketMQ replay messages by result.",
+			},
+			[]string{"name", "result"},
+		),
 	}
 
 	mustRegisterCollector(registerer, &m.producerRequestsTotal, m.producerRequestsTotal)
@@ -72,6 +80,7 @@ func newRMQMetrics(registerer prometheus.Registerer) *metrics {
 	mustRegisterCollector(registerer, &m.consumerRequestsTotal, m.consumerRequestsTotal)
 	mustRegisterCollector(registerer, &m.consumerDuration, m.consumerDuration)
 	mustRegisterCollector(registerer, &m.consumerMessagesTotal, m.consumerMessagesTotal)
+	mustRegisterCollector(registerer, &m.replayMessagesTotal, m.replayMessagesTotal)
 
 	return m
 }
@@ -9,6 +9,7 @@ import (
 	rmqClient "github.com/apache/rocketmq-clients/golang/v5"
 	"github.com/apache/rocketmq-clients/golang/v5/credentials"
 	"github.com/bang-go/micro/telemetry/logger"
+	microtrace "github.com/bang-go/micro/telemetry/trace"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -31,13 +32,20 @@ type ProducerConfig struct {
 	StartTimeout time.Duration
 	DialTimeout  time.Duration
 	MaxAttempts  int32
+	// Topics are the topics the producer sends to. Their routes are loaded at
+	// Start, which fails when one is missing.
+	Topics []string
+	// VerifyOnStart checks Topics with VerifyResources before starting, for
+	// errors that name every missing topic.
+	VerifyOnStart bool
 
 	Logger            *logger.Logger
 	EnableLogger      bool
 	DisableMetrics    bool
 	MetricsRegisterer prometheus.Registerer
 
-	newProducer producerFactory
+	newProducer    producerFactory
+	newResourceAPI resourceAPIFactory
 }
 
 type Producer interface {
@@ -55,6 +63,7 @@ type producerEntity struct {
 	logger       *logger.Logger
 	enableLogger bool
 	startTimeout time.Duration
+	verify       *VerifyConfig
 	metrics      *metrics
 	producer     producerAPI
 }
@@ -91,6 +100,7 @@ func NewProducer(conf *ProducerConfig) (Producer, error) {
 		logger:       config.Logger,
 		enableLogger: config.EnableLogger,
 		startTimeout: config.StartTimeout,
+		verify:       producerVerifyConfig(config),
 		metrics:      metrics,
 		producer:     producer,
 	}, nil
@@ -104,6 +114,12 @@ func (p *producerEntity) Start(ctx context.Context) error {
 	startCtx, cancel := timeoutContext(ctx, p.startTimeout)
 	defer cancel()
 
+	if p.verify != nil {
+		if err := VerifyResources(startCtx, p.verify); err != nil {
+			return fmt.Errorf("rmq: start producer failed: %w", err)
+		}
+	}
+
 	done := make(chan error, 1)
 	go func() {
 		done <- p.producer.Start()
@@ -139,7 +155,7 @@ func (p *producerEntity) Send(ctx context.Context, message *Message) ([]*SendRec
 
 	start := time.Now()
 	receipts, err := p.producer.Send(ctx, prepared)
-	p.observeSend(ctx, "send", start, len(receipts), err)
+	p.observeSend(ctx, "send", prepared.Topic, start, receipts, err)
 	return receipts, err
 }
 
@@ -161,7 +177,7 @@ func (p *producerEntity) SendAsync(ctx context.Context, message *Message, handle
 
 	start := time.Now()
 	p.producer.SendAsync(ctx, prepared, func(ctx context.Context, receipts []*SendReceipt, err error) {
-		p.observeSend(ctx, "send_async", start, len(receipts), err)
+		p.observeSend(ctx, "send_async", prepared.Topic, start, receipts, err)
 		if handler != nil {
 			handler(ctx, receipts, err)
 		}
@@ -185,7 +201,7 @@ func (p *producerEntity) SendFIFO(ctx context.Context, message *Message, group s
 
 	start := time.Now()
 	receipts, err := p.producer.Send(ctx, prepared)
-	p.observeSend(ctx, "send_fifo", start, len(receipts), err)
+	p.observeSend(ctx, "send_fifo", prepared.Topic, start, receipts, err)
 	return receipts, err
 }
 
@@ -202,17 +218,23 @@ func (p *producerEntity) SendDelay(ctx context.Context, message *Message, delive
 
 	start := time.Now()
 	receipts, err := p.producer.Send(ctx, prepared)
-	p.observeSend(ctx, "send_delay", start, len(receipts), err)
+	p.observeSend(ctx, "send_delay", prepared.Topic, start, receipts, err)
 	return receipts, err
 }
 
-func (p *producerEntity) observeSend(ctx context.Context, operation string, startedAt time.Time, receiptCount int, err error) {
+func (p *producerEntity) observeSend(ctx context.Context, operation, topic string, startedAt time.Time, receipts []*SendReceipt, err error) {
 	status := "success"
 	if err != nil {
 		status = "error"
 	}
 	duration := time.Since(startedAt)
 
+	for _, receipt := range receipts {
+		if receipt != nil {
+			microtrace.QueueEnqueue(ctx, traceMessagingSystem, topic, receipt.MessageID)
+		}
+	}
+
 	if p.metrics != nil {
 		p.metrics.producerRequestsTotal.WithLabelValues(p.name, operation, status).Inc()
 		p.metrics.producerDuration.WithLabelValues(p.name, operation, status).Observe(duration.Seconds())
@@ -225,7 +247,7 @@ func (p *producerEntity) observeSend(ctx context.Context, operation string, star
 		"name", p.name,
 		"endpoint", p.endpoint,
 		"operation", operation,
-		"receipt_count", receiptCount,
+		"receipt_count", len(receipts),
 		"duration", duration,
 	}
 	if err != nil {
@@ -284,9 +306,28 @@ func prepareProducerConfig(conf *ProducerConfig) (*ProducerConfig, *rmqClient.Co
 	if cloned.MaxAttempts > 0 {
 		options = append(options, rmqClient.WithMaxAttempts(cloned.MaxAttempts))
 	}
+	cloned.Topics = normalizeTopics(cloned.Topics)
+	if len(cloned.Topics) > 0 {
+		options = append(options, rmqClient.WithTopics(cloned.Topics...))
+	}
 	return &cloned, sdkConfig, options, nil
 }
 
+func producerVerifyConfig(config *ProducerConfig) *VerifyConfig {
+	if !config.VerifyOnStart || len(config.Topics) == 0 {
+		return nil
+	}
+	return &VerifyConfig{
+		Endpoint:  config.Endpoint,
+		Namespace: config.Namespace,
+		AccessKey: config.AccessKey,
+		SecretKey: config.SecretKey,
+		Topics:    config.Topics,
+		Timeout:   min(config.DialTimeout, defaultQueryRouteTimeout),
+		newAPI:    config.newResourceAPI,
+	}
+}
+
 func prepareMessage(message *Message) (*Message, error) {
 	switch {
 	case message == nil:
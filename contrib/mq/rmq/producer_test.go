@@ -8,7 +8,10 @@ import (
 	"time"
 
 	rmqClient "github.com/apache/rocketmq-clients/golang/v5"
+	microtrace "github.com/bang-go/micro/telemetry/trace"
 	"github.com/prometheus/client_golang/prometheus"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestNewProducerValidation(t *testing.T) {
@@ -190,6 +193,41 @@ func TestProducerLifecycleAndMessageIsolation(t *testing.T) {
 	}
 }
 
+func TestProducerRecordsEnqueueEvents(t *testing.T) {
+	producer, err := NewProducer(&ProducerConfig{
+		Endpoint:       "127.0.0.1:8081",
+		DisableMetrics: true,
+		newProducer: func(cfg *rmqClient.Config, opts ...rmqClient.ProducerOption) (producerAPI, error) {
+			return &fakeProducer{}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewProducer() error = %v", err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "checkout")
+
+	if _, err := producer.Send(ctx, &Message{Topic: "orders", Body: []byte("ok")}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	span.End()
+
+	events := recorder.Ended()[0].Events()
+	if len(events) != 1 || events[0].Name != microtrace.EventQueueEnqueue {
+		t.Fatalf("span events = %+v, want one %s", events, microtrace.EventQueueEnqueue)
+	}
+	attrs := make(map[string]string)
+	for _, kv := range events[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsString()
+	}
+	if attrs[string(microtrace.AttrMessagingSystem)] != "rocketmq" ||
+		attrs[string(microtrace.AttrMessagingDestination)] != "orders" ||
+		attrs[string(microtrace.AttrMessageID)] != "msg-1" {
+		t.Fatalf("enqueue attributes = %v", attrs)
+	}
+}
+
 func TestProducerContextValidationAndTimeout(t *testing.T) {
 	fake := &fakeProducer{startDelay: 50 * time.Millisecond}
 	producer, err := NewProducer(&ProducerConfig{
@@ -0,0 +1,332 @@
+package rmq
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultReplayIdleRounds     = 3
+	defaultOriginTopicProperty  = "RETRY_TOPIC"
+	ReplaySourceMessageProperty = "REPLAY_SOURCE_MSG_ID"
+)
+
+// ReplayMessage is a detached copy of a received message used for filtering
+// and republishing.
+type ReplayMessage struct {
+	ID           string
+	Topic        string
+	Body         []byte
+	Tag          string
+	Keys         []string
+	Properties   map[string]string
+	MessageGroup string
+	BornAt       time.Time
+	View         *MessageView
+}
+
+type ReplayProgress struct {
+	Received      int64
+	Replayed      int64
+	Skipped       int64
+	Failed        int64
+	LastMessageID string
+	StartedAt     time.Time
+	Elapsed       time.Duration
+}
+
+type ReplayConfig struct {
+	Name string
+	// Source consumes the backup or DLQ topic; broker-side tag/SQL filtering is
+	// configured through its SubscriptionExpressions. Replay does not start or close it.
+	Source Consumer
+	// Producer republishes messages. Replay does not start or close it.
+	Producer Producer
+	// Target overrides the destination topic. When empty the topic is read from
+	// OriginTopicProperty (default RETRY_TOPIC, set by the broker on DLQ messages).
+	Target              string
+	OriginTopicProperty string
+	// From and To bound the message born time; zero means unbounded.
+	From time.Time
+	To   time.Time
+	// Filter is evaluated client-side after the time range; false skips the message.
+	Filter func(*ReplayMessage) bool
+	// Rate limits republished messages per second; 0 means unlimited.
+	Rate  float64
+	Burst int
+	// MaxMessages stops the replay after this many republished messages.
+	MaxMessages int64
+	// IdleRounds stops the replay after this many consecutive empty receives.
+	IdleRounds int
+	OnProgress func(ReplayProgress)
+
+	Logger            *logger.Logger
+	EnableLogger      bool
+	DisableMetrics    bool
+	MetricsRegisterer prometheus.Registerer
+
+	toMessage func(*MessageView) *ReplayMessage
+}
+
+type Replay interface {
+	Run(context.Context) (ReplayProgress, error)
+}
+
+type replayEntity struct {
+	config  *ReplayConfig
+	limiter *rate.Limiter
+	metrics *metrics
+}
+
+func NewReplay(conf *ReplayConfig) (Replay, error) {
+	config, err := prepareReplayConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics *metrics
+	if !config.DisableMetrics {
+		metrics = defaultRMQMetrics()
+		if config.MetricsRegisterer != nil {
+			metrics = newRMQMetrics(config.MetricsRegisterer)
+		}
+	}
+
+	var limiter *rate.Limiter
+	if config.Rate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(config.Rate), config.Burst)
+	}
+
+	return &replayEntity{config: config, limiter: limiter, metrics: metrics}, nil
+}
+
+// Run replays until the source stays empty for IdleRounds receives, MaxMessages
+// is reached, or ctx ends. Failed sends are left unacked so the broker redelivers
+// them to a later run; skipped messages are acked.
+func (r *replayEntity) Run(ctx context.Context) (ReplayProgress, error) {
+	progress := ReplayProgress{StartedAt: time.Now()}
+	if ctx == nil {
+		return progress, ErrContextRequired
+	}
+
+	idle := 0
+	for idle < r.config.IdleRounds {
+		if err := ctx.Err(); err != nil {
+			return r.finish(ctx, progress, err)
+		}
+
+		views, err := r.config.Source.Receive(ctx)
+		if err != nil && receiveStatus(err) != "empty" {
+			return r.finish(ctx, progress, fmt.Errorf("rmq: replay receive failed: %w", err))
+		}
+		if len(views) == 0 {
+			idle++
+			continue
+		}
+		idle = 0
+
+		for _, view := range views {
+			if view == nil {
+				continue
+			}
+			progress.Received++
+			if err := r.replayOne(ctx, view, &progress); err != nil {
+				return r.finish(ctx, progress, err)
+			}
+			if r.config.MaxMessages > 0 && progress.Replayed >= r.config.MaxMessages {
+				return r.finish(ctx, progress, nil)
+			}
+		}
+		r.report(progress)
+	}
+	return r.finish(ctx, progress, nil)
+}
+
+func (r *replayEntity) replayOne(ctx context.Context, view *MessageView, progress *ReplayProgress) error {
+	message := r.config.toMessage(view)
+	progress.LastMessageID = message.ID
+
+	if !r.inRange(message) || (r.config.Filter != nil && !r.config.Filter(message)) {
+		progress.Skipped++
+		r.observe("skipped")
+		return r.config.Source.Ack(ctx, view)
+	}
+
+	target := r.config.Target
+	if target == "" {
+		target = strings.TrimSpace(message.Properties[r.config.OriginTopicProperty])
+	}
+	if target == "" {
+		progress.Failed++
+		r.observe("failed")
+		r.warn(ctx, "rmq replay target topic missing", "message_id", message.ID)
+		return nil
+	}
+
+	if r.limiter != nil {
+		if err := r.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := r.send(ctx, target, message); err != nil {
+		progress.Failed++
+		r.observe("failed")
+		r.warn(ctx, "rmq replay send failed", "message_id", message.ID, "target", target, "error", err)
+		return nil
+	}
+	progress.Replayed++
+	r.observe("replayed")
+
+	if err := r.config.Source.Ack(ctx, view); err != nil {
+		r.warn(ctx, "rmq replay ack failed", "message_id", message.ID, "error", err)
+	}
+	return nil
+}
+
+func (r *replayEntity) send(ctx context.Context, target string, message *ReplayMessage) error {
+	out := &Message{Topic: target, Body: message.Body}
+	if message.Tag != "" {
+		out.SetTag(message.Tag)
+	}
+	if len(message.Keys) > 0 {
+		out.SetKeys(message.Keys...)
+	}
+	for key, value := range message.Properties {
+		if key == r.config.OriginTopicProperty {
+			continue
+		}
+		out.AddProperty(key, value)
+	}
+	if message.ID != "" {
+		out.AddProperty(ReplaySourceMessageProperty, message.ID)
+	}
+
+	var err error
+	if message.MessageGroup != "" {
+		_, err = r.config.Producer.SendFIFO(ctx, out, message.MessageGroup)
+	} else {
+		_, err = r.config.Producer.Send(ctx, out)
+	}
+	return err
+}
+
+func (r *replayEntity) inRange(message *ReplayMessage) bool {
+	if r.config.From.IsZero() && r.config.To.IsZero() {
+		return true
+	}
+	if message.BornAt.IsZero() {
+		return false
+	}
+	if !r.config.From.IsZero() && message.BornAt.Before(r.config.From) {
+		return false
+	}
+	if !r.config.To.IsZero() && message.BornAt.After(r.config.To) {
+		return false
+	}
+	return true
+}
+
+func (r *replayEntity) finish(ctx context.Context, progress ReplayProgress, err error) (ReplayProgress, error) {
+	progress.Elapsed = time.Since(progress.StartedAt)
+	r.report(progress)
+	if r.config.EnableLogger {
+		r.config.Logger.Info(ctx, "rmq replay finished",
+			"name", r.config.Name,
+			"received", progress.Received,
+			"replayed", progress.Replayed,
+			"skipped", progress.Skipped,
+			"failed", progress.Failed,
+			"duration", progress.Elapsed,
+			"error", err,
+		)
+	}
+	return progress, err
+}
+
+func (r *replayEntity) report(progress ReplayProgress) {
+	if r.config.OnProgress == nil {
+		return
+	}
+	if progress.Elapsed == 0 {
+		progress.Elapsed = time.Since(progress.StartedAt)
+	}
+	r.config.OnProgress(progress)
+}
+
+func (r *replayEntity) observe(result string) {
+	if r.metrics != nil {
+		r.metrics.replayMessagesTotal.WithLabelValues(r.config.Name, result).Inc()
+	}
+}
+
+func (r *replayEntity) warn(ctx context.Context, msg string, args ...any) {
+	if r.config.EnableLogger {
+		r.config.Logger.Warn(ctx, msg, append([]any{"name", r.config.Name}, args...)...)
+	}
+}
+
+func prepareReplayConfig(conf *ReplayConfig) (*ReplayConfig, error) {
+	if conf == nil {
+		return nil, ErrNilReplayConfig
+	}
+
+	cloned := *conf
+	cloned.Name = strings.TrimSpace(cloned.Name)
+	cloned.Target = strings.TrimSpace(cloned.Target)
+	cloned.OriginTopicProperty = strings.TrimSpace(cloned.OriginTopicProperty)
+	cloned.Logger = defaultLogger(cloned.Logger)
+	if cloned.Source == nil {
+		return nil, ErrReplaySourceRequired
+	}
+	if cloned.Producer == nil {
+		return nil, ErrReplayProducerRequired
+	}
+	if !cloned.From.IsZero() && !cloned.To.IsZero() && cloned.To.Before(cloned.From) {
+		return nil, ErrReplayInvalidRange
+	}
+	if cloned.Name == "" {
+		cloned.Name = "replay"
+	}
+	if cloned.OriginTopicProperty == "" {
+		cloned.OriginTopicProperty = defaultOriginTopicProperty
+	}
+	if cloned.IdleRounds <= 0 {
+		cloned.IdleRounds = defaultReplayIdleRounds
+	}
+	if cloned.Rate > 0 && cloned.Burst <= 0 {
+		cloned.Burst = 1
+	}
+	if cloned.toMessage == nil {
+		cloned.toMessage = replayMessageFromView
+	}
+	return &cloned, nil
+}
+
+func replayMessageFromView(view *MessageView) *ReplayMessage {
+	message := &ReplayMessage{
+		ID:         view.GetMessageId(),
+		Topic:      view.GetTopic(),
+		Body:       append([]byte(nil), view.GetBody()...),
+		Keys:       append([]string(nil), view.GetKeys()...),
+		Properties: maps.Clone(view.GetProperties()),
+		View:       view,
+	}
+	if tag := view.GetTag(); tag != nil {
+		message.Tag = *tag
+	}
+	if group := view.GetMessageGroup(); group != nil {
+		message.MessageGroup = *group
+	}
+	if bornAt := view.GetBornTimestamp(); bornAt != nil {
+		message.BornAt = *bornAt
+	}
+	return message
+}
@@ -0,0 +1,232 @@
+package rmq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type replaySource struct {
+	mu      sync.Mutex
+	batches [][]*MessageView
+	acked   []*MessageView
+	err     error
+}
+
+func (s *replaySource) Start(context.Context) error { return nil }
+func (s *replaySource) Close() error                { return nil }
+
+func (s *replaySource) Receive(context.Context) ([]*MessageView, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(s.batches) == 0 {
+		return nil, nil
+	}
+	batch := s.batches[0]
+	s.batches = s.batches[1:]
+	return batch, nil
+}
+
+func (s *replaySource) Ack(_ context.Context, view *MessageView) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acked = append(s.acked, view)
+	return nil
+}
+
+type replayProducer struct {
+	mu     sync.Mutex
+	sent   []*Message
+	groups []string
+	fail   map[string]bool
+}
+
+func (p *replayProducer) Start(context.Context) error { return nil }
+func (p *replayProducer) Close() error                { return nil }
+func (p *replayProducer) SendAsync(context.Context, *Message, AsyncSendHandler) {
+}
+func (p *replayProducer) SendDelay(context.Context, *Message, time.Time) ([]*SendReceipt, error) {
+	return nil, nil
+}
+
+func (p *replayProducer) Send(_ context.Context, message *Message) ([]*SendReceipt, error) {
+	return p.SendFIFO(context.Background(), message, "")
+}
+
+func (p *replayProducer) SendFIFO(_ context.Context, message *Message, group string) ([]*SendReceipt, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail[string(message.Body)] {
+		return nil, errors.New("broker unavailable")
+	}
+	p.sent = append(p.sent, message)
+	p.groups = append(p.groups, group)
+	return []*SendReceipt{{}}, nil
+}
+
+func replayFixture(messages map[*MessageView]*ReplayMessage) func(*MessageView) *ReplayMessage {
+	return func(view *MessageView) *ReplayMessage {
+		return messages[view]
+	}
+}
+
+func TestReplayRepublishesMatchingMessages(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	views := []*MessageView{{}, {}, {}, {}, {}}
+	messages := map[*MessageView]*ReplayMessage{
+		views[0]: {ID: "m0", Body: []byte("ok-1"), Tag: "paid", Keys: []string{"order-1"}, BornAt: base, Properties: map[string]string{"RETRY_TOPIC": "orders", "trace": "t1"}},
+		views[1]: {ID: "m1", Body: []byte("too-old"), BornAt: base.Add(-2 * time.Hour), Properties: map[string]string{"RETRY_TOPIC": "orders"}},
+		views[2]: {ID: "m2", Body: []byte("filtered"), BornAt: base, Properties: map[string]string{"RETRY_TOPIC": "orders"}},
+		views[3]: {ID: "m3", Body: []byte("fifo"), MessageGroup: "user-7", BornAt: base, Properties: map[string]string{"RETRY_TOPIC": "orders"}},
+		views[4]: {ID: "m4", Body: []byte("broken"), BornAt: base, Properties: map[string]string{"RETRY_TOPIC": "orders"}},
+	}
+	source := &replaySource{batches: [][]*MessageView{views[:2], views[2:]}}
+	producer := &replayProducer{fail: map[string]bool{"broken": true}}
+	reg := prometheus.NewRegistry()
+
+	var reports []ReplayProgress
+	replay, err := NewReplay(&ReplayConfig{
+		Name:       "orders-dlq",
+		Source:     source,
+		Producer:   producer,
+		From:       base.Add(-time.Hour),
+		To:         base.Add(time.Hour),
+		Filter:     func(m *ReplayMessage) bool { return string(m.Body) != "filtered" },
+		IdleRounds: 1,
+		OnProgress: func(p ReplayProgress) {
+			reports = append(reports, p)
+		},
+		MetricsRegisterer: reg,
+		toMessage:         replayFixture(messages),
+	})
+	if err != nil {
+		t.Fatalf("NewReplay() error = %v", err)
+	}
+
+	progress, err := replay.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if progress.Received != 5 || progress.Replayed != 2 || progress.Skipped != 2 || progress.Failed != 1 {
+		t.Fatalf("progress = %+v, want 5 received, 2 replayed, 2 skipped, 1 failed", progress)
+	}
+	if len(reports) < 3 || reports[len(reports)-1].Replayed != 2 {
+		t.Fatalf("progress reports = %+v", reports)
+	}
+
+	if len(producer.sent) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(producer.sent))
+	}
+	first := producer.sent[0]
+	if first.Topic != "orders" || string(first.Body) != "ok-1" || first.GetTag() == nil || *first.GetTag() != "paid" {
+		t.Fatalf("first republished message = %+v", first)
+	}
+	props := first.GetProperties()
+	if props[ReplaySourceMessageProperty] != "m0" || props["trace"] != "t1" {
+		t.Fatalf("properties = %v, want source id and copied user properties", props)
+	}
+	if _, ok := props["RETRY_TOPIC"]; ok {
+		t.Fatal("origin topic property should not be republished")
+	}
+	if producer.groups[1] != "user-7" {
+		t.Fatalf("fifo group = %q, want user-7", producer.groups[1])
+	}
+
+	for _, view := range source.acked {
+		if view == views[4] {
+			t.Fatal("failed message must stay unacked for redelivery")
+		}
+	}
+	if len(source.acked) != 4 {
+		t.Fatalf("acked %d messages, want 4", len(source.acked))
+	}
+	if got := testutil.ToFloat64(newRMQMetrics(reg).replayMessagesTotal.WithLabelValues("orders-dlq", "replayed")); got != 2 {
+		t.Fatalf("rmq_replay_messages_total{result=replayed} = %v, want 2", got)
+	}
+}
+
+func TestReplayHonorsTargetRateAndMaxMessages(t *testing.T) {
+	views := []*MessageView{{}, {}, {}}
+	messages := map[*MessageView]*ReplayMessage{
+		views[0]: {ID: "a", Body: []byte("a")},
+		views[1]: {ID: "b", Body: []byte("b")},
+		views[2]: {ID: "c", Body: []byte("c")},
+	}
+	producer := &replayProducer{}
+	replay, err := NewReplay(&ReplayConfig{
+		Source:         &replaySource{batches: [][]*MessageView{views}},
+		Producer:       producer,
+		Target:         "orders.replay",
+		Rate:           20,
+		MaxMessages:    2,
+		DisableMetrics: true,
+		toMessage:      replayFixture(messages),
+	})
+	if err != nil {
+		t.Fatalf("NewReplay() error = %v", err)
+	}
+
+	start := time.Now()
+	progress, err := replay.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if progress.Replayed != 2 || len(producer.sent) != 2 {
+		t.Fatalf("progress = %+v, sent = %d, want 2", progress, len(producer.sent))
+	}
+	if producer.sent[0].Topic != "orders.replay" {
+		t.Fatalf("topic = %q, want orders.replay", producer.sent[0].Topic)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("elapsed = %v, want rate limiting to space sends", elapsed)
+	}
+}
+
+func TestReplayStopsOnReceiveErrorAndContext(t *testing.T) {
+	replay, err := NewReplay(&ReplayConfig{
+		Source:         &replaySource{err: errors.New("connection refused")},
+		Producer:       &replayProducer{},
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("NewReplay() error = %v", err)
+	}
+	if _, err := replay.Run(context.Background()); err == nil {
+		t.Fatal("Run() error = nil, want receive error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := replay.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run(canceled) error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := replay.Run(nil); !errors.Is(err, ErrContextRequired) {
+		t.Fatalf("Run(nil) error = %v, want %v", err, ErrContextRequired)
+	}
+}
+
+func TestNewReplayValidation(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		conf *ReplayConfig
+		want error
+	}{
+		{conf: nil, want: ErrNilReplayConfig},
+		{conf: &ReplayConfig{}, want: ErrReplaySourceRequired},
+		{conf: &ReplayConfig{Source: &replaySource{}}, want: ErrReplayProducerRequired},
+		{conf: &ReplayConfig{Source: &replaySource{}, Producer: &replayProducer{}, From: now, To: now.Add(-time.Second)}, want: ErrReplayInvalidRange},
+	}
+	for _, tt := range tests {
+		if _, err := NewReplay(tt.conf); !errors.Is(err, tt.want) {
+			t.Fatalf("NewReplay() error = %v, want %v", err, tt.want)
+		}
+	}
+}
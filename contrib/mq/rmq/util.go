@@ -2,6 +2,7 @@ package rmq
 
 import (
 	"context"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,6 +20,7 @@ const (
 	defaultInvisibleDuration          = 20 * time.Second
 	defaultReceiveMaxMessages   int32 = 16
 	maxReceiveMessages          int32 = 32
+	traceMessagingSystem              = "rocketmq"
 )
 
 type Message = rmqClient.Message
@@ -77,6 +79,16 @@ func cloneSubscriptions(src map[string]*FilterExpression) (map[string]*FilterExp
 	return cloned, nil
 }
 
+func normalizeTopics(topics []string) []string {
+	normalized := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		if topic = strings.TrimSpace(topic); topic != "" && !slices.Contains(normalized, topic) {
+			normalized = append(normalized, topic)
+		}
+	}
+	return normalized
+}
+
 func subscriptionsName(subscriptions map[string]*FilterExpression) string {
 	if len(subscriptions) == 0 {
 		return ""
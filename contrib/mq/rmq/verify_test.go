@@ -0,0 +1,120 @@
+package rmq
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	rmqClient "github.com/apache/rocketmq-clients/golang/v5"
+	v2 "github.com/apache/rocketmq-clients/golang/v5/protocol/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type fakeResourceAPI struct {
+	topics    map[string]bool
+	groups    map[string]bool
+	target    string
+	namespace string
+	auth      string
+	closed    bool
+}
+
+func (f *fakeResourceAPI) factory(target string) (resourceAPI, func() error, error) {
+	f.target = target
+	return f, func() error { f.closed = true; return nil }, nil
+}
+
+func (f *fakeResourceAPI) QueryRoute(ctx context.Context, req *v2.QueryRouteRequest, _ ...grpc.CallOption) (*v2.QueryRouteResponse, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 {
+		f.auth = values[0]
+	}
+	f.namespace = req.GetTopic().GetResourceNamespace()
+	code := v2.Code_OK
+	if !f.topics[req.GetTopic().GetName()] {
+		code = v2.Code_TOPIC_NOT_FOUND
+	}
+	return &v2.QueryRouteResponse{Status: &v2.Status{Code: code}}, nil
+}
+
+func (f *fakeResourceAPI) QueryAssignment(_ context.Context, req *v2.QueryAssignmentRequest, _ ...grpc.CallOption) (*v2.QueryAssignmentResponse, error) {
+	code := v2.Code_OK
+	if !f.groups[req.GetGroup().GetName()] {
+		code = v2.Code_CONSUMER_GROUP_NOT_FOUND
+	}
+	return &v2.QueryAssignmentResponse{Status: &v2.Status{Code: code}}, nil
+}
+
+func TestVerifyResources(t *testing.T) {
+	api := &fakeResourceAPI{topics: map[string]bool{"orders": true}, groups: map[string]bool{"GID_orders": true}}
+	conf := &VerifyConfig{
+		Endpoint:  "127.0.0.1:8081",
+		Namespace: "prod",
+		AccessKey: "ak",
+		SecretKey: "sk",
+		Topics:    []string{"orders"},
+		Group:     "GID_orders",
+		newAPI:    api.factory,
+	}
+	if err := VerifyResources(context.Background(), conf); err != nil {
+		t.Fatalf("VerifyResources() error = %v", err)
+	}
+	if api.target != "127.0.0.1:8081" || !api.closed {
+		t.Fatalf("target = %q, closed = %v", api.target, api.closed)
+	}
+	if api.namespace != "prod" {
+		t.Fatalf("namespace = %q, want prod", api.namespace)
+	}
+	if !strings.HasPrefix(api.auth, "MQv2-HMAC-SHA1 Credential=ak/") {
+		t.Fatalf("authorization = %q", api.auth)
+	}
+
+	conf.Topics = []string{"orders", "payments", "refunds"}
+	conf.Group = "GID_missing"
+	err := VerifyResources(context.Background(), conf)
+	if !errors.Is(err, ErrTopicNotFound) || !errors.Is(err, ErrConsumerGroupNotFound) {
+		t.Fatalf("VerifyResources() error = %v, want topic and group not found", err)
+	}
+	for _, want := range []string{`"payments"`, `"refunds"`, `"GID_missing" in namespace "prod"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestConsumerStartVerifiesResources(t *testing.T) {
+	api := &fakeResourceAPI{topics: map[string]bool{"orders": true}}
+	fake := &startTrackingConsumer{}
+	consumer, err := NewSimpleConsumer(&ConsumerConfig{
+		Group:          "GID_orders",
+		Endpoint:       "127.0.0.1:8081",
+		Topic:          "orders",
+		VerifyOnStart:  true,
+		DisableMetrics: true,
+		newConsumer: func(*rmqClient.Config, ...rmqClient.SimpleConsumerOption) (consumerAPI, error) {
+			return fake, nil
+		},
+		newResourceAPI: api.factory,
+	})
+	if err != nil {
+		t.Fatalf("NewSimpleConsumer() error = %v", err)
+	}
+	if err := consumer.Start(context.Background()); !errors.Is(err, ErrConsumerGroupNotFound) {
+		t.Fatalf("Start() error = %v, want %v", err, ErrConsumerGroupNotFound)
+	}
+	if fake.started {
+		t.Fatal("consumer started although its group is missing")
+	}
+}
+
+type startTrackingConsumer struct {
+	fakeConsumer
+	started bool
+}
+
+func (f *startTrackingConsumer) Start() error {
+	f.started = true
+	return nil
+}
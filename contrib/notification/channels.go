@@ -0,0 +1,114 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bang-go/micro/contrib/push/umeng"
+	"github.com/bang-go/micro/contrib/sms"
+)
+
+const (
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+	ChannelWS    = "ws"
+)
+
+// ChannelFunc adapts a function to Channel, e.g. for an email provider.
+func ChannelFunc(name string, send func(context.Context, *Message) (*Receipt, error)) Channel {
+	return &funcChannel{name: name, send: send}
+}
+
+type funcChannel struct {
+	name string
+	send func(context.Context, *Message) (*Receipt, error)
+}
+
+func (c *funcChannel) Name() string { return c.name }
+
+func (c *funcChannel) Send(ctx context.Context, message *Message) (*Receipt, error) {
+	return c.send(ctx, message)
+}
+
+// SMSSender is implemented by *sms.Router.
+type SMSSender interface {
+	Send(context.Context, *sms.SendRequest) (*sms.SendResult, error)
+}
+
+// NewSMSChannel sends the template by the same name through sender, with
+// data as the JSON template parameters and the delivery id as OutID. The
+// user's contact is the phone number.
+func NewSMSChannel(sender SMSSender) Channel {
+	return ChannelFunc(ChannelSMS, func(ctx context.Context, message *Message) (*Receipt, error) {
+		if message.Contact == "" {
+			return nil, ErrContactRequired
+		}
+		param := ""
+		if len(message.Data) > 0 {
+			payload, err := json.Marshal(message.Data)
+			if err != nil {
+				return nil, fmt.Errorf("notification: encode sms params: %w", err)
+			}
+			param = string(payload)
+		}
+		result, err := sender.Send(ctx, &sms.SendRequest{
+			PhoneNumber:   message.Contact,
+			Template:      message.Template,
+			TemplateParam: param,
+			OutID:         message.DeliveryID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &Receipt{MessageID: result.MessageID}, nil
+	})
+}
+
+// PushBuilder renders a message as an umeng request; it decides the
+// platform, target and text.
+type PushBuilder func(*Message) (*umeng.SendRequest, error)
+
+func NewPushChannel(client umeng.Client, build PushBuilder) Channel {
+	return ChannelFunc(ChannelPush, func(ctx context.Context, message *Message) (*Receipt, error) {
+		request, err := build(message)
+		if err != nil {
+			return nil, err
+		}
+		response, err := client.Send(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		return &Receipt{MessageID: response.Data.MsgID}, nil
+	})
+}
+
+// WSSender is implemented by wsx.Hub.
+type WSSender interface {
+	SendJSONTo(ctx context.Context, userID string, v interface{}) error
+}
+
+// WSPayload is the JSON frame NewWSChannel sends.
+type WSPayload struct {
+	ID       string         `json:"id"`
+	Template string         `json:"template"`
+	Data     map[string]any `json:"data,omitempty"`
+}
+
+// NewWSChannel pushes the message to the user's open connections. The hub
+// does not report whether anyone was connected, so use it with All or
+// before a channel that reaches offline users.
+func NewWSChannel(hub WSSender) Channel {
+	return ChannelFunc(ChannelWS, func(ctx context.Context, message *Message) (*Receipt, error) {
+		err := hub.SendJSONTo(ctx, message.UserID, WSPayload{
+			ID:       message.DeliveryID,
+			Template: message.Template,
+			Data:     message.Data,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &Receipt{MessageID: message.DeliveryID}, nil
+	})
+}
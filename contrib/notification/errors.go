@@ -0,0 +1,21 @@
+package notification
+
+import "errors"
+
+var (
+	ErrContextRequired     = errors.New("notification: context is required")
+	ErrConfigRequired      = errors.New("notification: config is required")
+	ErrPreferencesRequired = errors.New("notification: preferences are required")
+	ErrChannelRequired     = errors.New("notification: channel is required")
+	ErrChannelNameRequired = errors.New("notification: channel name is required")
+	ErrDuplicateChannel    = errors.New("notification: duplicate channel")
+	ErrUserIDRequired      = errors.New("notification: user id is required")
+	ErrTemplateRequired    = errors.New("notification: template is required")
+	// ErrContactRequired is returned by channels when the user has no
+	// address for them; the channel is skipped without hurting its health.
+	ErrContactRequired  = errors.New("notification: contact is required")
+	ErrNoChannel        = errors.New("notification: no channel available")
+	ErrDeliveryFailed   = errors.New("notification: every channel failed")
+	ErrRateLimited      = errors.New("notification: rate limited")
+	ErrDeliveryNotFound = errors.New("notification: delivery not found")
+)
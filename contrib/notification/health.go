@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailureThreshold = 3
+	defaultCooldown         = 30 * time.Second
+)
+
+// HealthConfig takes a channel out of rotation after FailureThreshold
+// consecutive failures for Cooldown; the first send after the cooldown
+// probes it, and one more failure takes it out again.
+type HealthConfig struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+type channelHealth struct {
+	failures  int
+	downUntil time.Time
+}
+
+type health struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	channels map[string]*channelHealth
+}
+
+func newHealth(conf *HealthConfig, now func() time.Time) *health {
+	h := &health{
+		threshold: defaultFailureThreshold,
+		cooldown:  defaultCooldown,
+		now:       now,
+		channels:  make(map[string]*channelHealth),
+	}
+	if conf != nil {
+		if conf.FailureThreshold > 0 {
+			h.threshold = conf.FailureThreshold
+		}
+		if conf.Cooldown > 0 {
+			h.cooldown = conf.Cooldown
+		}
+	}
+	return h
+}
+
+func (h *health) available(name string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	state, ok := h.channels[name]
+	return !ok || !h.now().Before(state.downUntil)
+}
+
+func (h *health) success(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.channels, name)
+}
+
+func (h *health) failure(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	state, ok := h.channels[name]
+	if !ok {
+		state = &channelHealth{}
+		h.channels[name] = state
+	}
+	state.failures++
+	if state.failures >= h.threshold {
+		state.downUntil = h.now().Add(h.cooldown)
+	}
+}
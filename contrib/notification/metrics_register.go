@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	attempts   *prometheus.CounterVec
+	deliveries *prometheus.CounterVec
+}
+
+var (
+	defaultMetricsOnce sync.Once
+	defaultMetrics     *metrics
+)
+
+func defaultNotificationMetrics() *metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = newNotificationMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetrics
+}
+
+func newNotificationMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		attempts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "notification_attempts_total",
+				Help: "Total number of notification channel attempts.",
+			},
+			[]string{"channel", "result"},
+		),
+		deliveries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "notification_deliveries_total",
+				Help: "Total number of notifications by final status.",
+			},
+			[]string{"template", "status"},
+		),
+	}
+
+	mustRegisterCollector(registerer, &m.attempts, m.attempts)
+	mustRegisterCollector(registerer, &m.deliveries, m.deliveries)
+
+	return m
+}
+
+func mustRegisterCollector[T prometheus.Collector](registerer prometheus.Registerer, dst *T, collector T) {
+	if registerer == nil {
+		return
+	}
+	if err := registerer.Register(collector); err != nil {
+		if alreadyRegistered, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if registered, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				*dst = registered
+				return
+			}
+		}
+		panic(err)
+	}
+}
@@ -0,0 +1,374 @@
+package notification
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bang-go/micro/store/cache"
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultPrefix       = "notification:"
+	defaultDedupeWindow = 10 * time.Minute
+
+	StatusDelivered    = "delivered"
+	StatusFailed       = "failed"
+	StatusDeduplicated = "deduplicated"
+	StatusRateLimited  = "rate_limited"
+
+	AttemptSuccess     = "success"
+	AttemptError       = "error"
+	AttemptSkipped     = "skipped"
+	AttemptUnhealthy   = "unhealthy"
+	AttemptRateLimited = "rate_limited"
+)
+
+// Message is what a channel delivers.
+type Message struct {
+	DeliveryID string
+	UserID     string
+	Template   string
+	Data       map[string]any
+	// Contact is the user's address on the channel, e.g. a phone number or
+	// device token; empty for channels addressed by user id.
+	Contact string
+}
+
+type Receipt struct {
+	// MessageID is the provider's id, when it returns one.
+	MessageID string
+}
+
+type Channel interface {
+	Name() string
+	Send(context.Context, *Message) (*Receipt, error)
+}
+
+// Preference is how a user wants a template delivered.
+type Preference struct {
+	// Channels in fallback order; the first healthy channel that succeeds
+	// ends the delivery unless All is set.
+	Channels []string
+	// Contacts maps channel name to the user's address on it.
+	Contacts map[string]string
+	// All delivers on every listed channel instead of stopping at the first
+	// success, e.g. for security alerts.
+	All bool
+}
+
+type Preferences interface {
+	Lookup(ctx context.Context, userID, template string) (*Preference, error)
+}
+
+// PreferencesFunc adapts a function to Preferences.
+type PreferencesFunc func(ctx context.Context, userID, template string) (*Preference, error)
+
+func (f PreferencesFunc) Lookup(ctx context.Context, userID, template string) (*Preference, error) {
+	return f(ctx, userID, template)
+}
+
+// RateCap allows Limit deliveries per Window.
+type RateCap struct {
+	Limit  int
+	Window time.Duration
+}
+
+type Config struct {
+	Channels    []Channel
+	Preferences Preferences
+	// Store keeps dedupe markers and rate counters; defaults to an
+	// in-process cache.NewMemory. Use a shared store across replicas.
+	Store  cache.Store
+	Prefix string
+	// DedupeWindow drops repeats of the same user, template and data;
+	// defaults to 10 minutes, negative disables.
+	DedupeWindow time.Duration
+	// UserRateCap caps deliveries per user across channels; ChannelRateCaps
+	// cap deliveries per user on one channel, skipping to the next.
+	UserRateCap     *RateCap
+	ChannelRateCaps map[string]RateCap
+	Health          *HealthConfig
+	// Tracker records every delivery; nil keeps no history.
+	Tracker Tracker
+
+	Logger            *logger.Logger
+	DisableMetrics    bool
+	MetricsRegisterer prometheus.Registerer
+
+	now func() time.Time
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, userID, template string, data map[string]any) (*Delivery, error)
+}
+
+type notifier struct {
+	config   Config
+	channels map[string]Channel
+	health   *health
+	metrics  *metrics
+}
+
+func New(conf *Config) (Notifier, error) {
+	if conf == nil {
+		return nil, ErrConfigRequired
+	}
+	config := *conf
+	if config.Preferences == nil {
+		return nil, ErrPreferencesRequired
+	}
+	channels := make(map[string]Channel, len(config.Channels))
+	for _, channel := range config.Channels {
+		if channel == nil {
+			return nil, ErrChannelRequired
+		}
+		name := strings.TrimSpace(channel.Name())
+		if name == "" {
+			return nil, ErrChannelNameRequired
+		}
+		if _, ok := channels[name]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateChannel, name)
+		}
+		channels[name] = channel
+	}
+	if config.Store == nil {
+		config.Store = cache.NewMemory(nil)
+	}
+	if config.Prefix == "" {
+		config.Prefix = defaultPrefix
+	}
+	if config.DedupeWindow == 0 {
+		config.DedupeWindow = defaultDedupeWindow
+	}
+	if config.Logger == nil {
+		config.Logger = logger.New(logger.WithLevel("info"))
+	}
+	if config.now == nil {
+		config.now = time.Now
+	}
+
+	var m *metrics
+	if !config.DisableMetrics {
+		m = defaultNotificationMetrics()
+		if config.MetricsRegisterer != nil {
+			m = newNotificationMetrics(config.MetricsRegisterer)
+		}
+	}
+	return &notifier{
+		config:   config,
+		channels: channels,
+		health:   newHealth(config.Health, config.now),
+		metrics:  m,
+	}, nil
+}
+
+// Notify delivers template to userID on the channels of the user's
+// preference. The returned delivery records every attempt; the error is
+// non-nil only when nothing was delivered for a reason other than dedupe.
+func (n *notifier) Notify(ctx context.Context, userID, template string, data map[string]any) (*Delivery, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	userID = strings.TrimSpace(userID)
+	template = strings.TrimSpace(template)
+	if userID == "" {
+		return nil, ErrUserIDRequired
+	}
+	if template == "" {
+		return nil, ErrTemplateRequired
+	}
+
+	delivery := &Delivery{
+		ID:        newDeliveryID(),
+		UserID:    userID,
+		Template:  template,
+		CreatedAt: n.config.now(),
+	}
+	err := n.deliver(ctx, delivery, data)
+	n.finish(ctx, delivery, err)
+	return delivery, err
+}
+
+func (n *notifier) deliver(ctx context.Context, delivery *Delivery, data map[string]any) (err error) {
+	if n.config.DedupeWindow > 0 {
+		key, err := n.dedupeKey(delivery.UserID, delivery.Template, data)
+		if err != nil {
+			return err
+		}
+		// SetNX claims the marker atomically, so of concurrent duplicates
+		// only one sends; a failed delivery clears it to allow a retry.
+		claimed, err := n.config.Store.SetNX(ctx, key, []byte(delivery.ID), n.config.DedupeWindow)
+		if err != nil {
+			return fmt.Errorf("notification: claim dedupe marker: %w", err)
+		}
+		if !claimed {
+			delivery.Status = StatusDeduplicated
+			return nil
+		}
+		defer func() {
+			if delivery.Status == StatusDelivered {
+				return
+			}
+			if deleteErr := n.config.Store.Delete(context.WithoutCancel(ctx), key); deleteErr != nil {
+				err = errors.Join(err, fmt.Errorf("notification: release dedupe marker: %w", deleteErr))
+			}
+		}()
+	}
+
+	if n.config.UserRateCap != nil {
+		if !n.allow(ctx, "user:"+delivery.UserID, *n.config.UserRateCap) {
+			delivery.Status = StatusRateLimited
+			return ErrRateLimited
+		}
+	}
+
+	preference, err := n.config.Preferences.Lookup(ctx, delivery.UserID, delivery.Template)
+	if err != nil {
+		delivery.Status = StatusFailed
+		return fmt.Errorf("notification: lookup preferences: %w", err)
+	}
+	if preference == nil || len(preference.Channels) == 0 {
+		delivery.Status = StatusFailed
+		return ErrNoChannel
+	}
+
+	message := &Message{DeliveryID: delivery.ID, UserID: delivery.UserID, Template: delivery.Template, Data: data}
+	delivered := false
+	var errs []error
+	for _, name := range preference.Channels {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		channel, ok := n.channels[name]
+		if !ok {
+			delivery.attempt(name, AttemptSkipped, "", fmt.Errorf("notification: unknown channel %q", name), n.config.now())
+			continue
+		}
+		if !n.health.available(name) {
+			delivery.attempt(name, AttemptUnhealthy, "", nil, n.config.now())
+			n.observeAttempt(name, AttemptUnhealthy)
+			continue
+		}
+		if limit, ok := n.config.ChannelRateCaps[name]; ok && !n.allow(ctx, "channel:"+name+":"+delivery.UserID, limit) {
+			delivery.attempt(name, AttemptRateLimited, "", nil, n.config.now())
+			n.observeAttempt(name, AttemptRateLimited)
+			continue
+		}
+
+		channelMessage := *message
+		channelMessage.Contact = strings.TrimSpace(preference.Contacts[name])
+		receipt, err := channel.Send(ctx, &channelMessage)
+		switch {
+		case err == nil:
+			messageID := ""
+			if receipt != nil {
+				messageID = receipt.MessageID
+			}
+			n.health.success(name)
+			delivery.attempt(name, AttemptSuccess, messageID, nil, n.config.now())
+			n.observeAttempt(name, AttemptSuccess)
+			delivered = true
+		case errors.Is(err, ErrContactRequired):
+			delivery.attempt(name, AttemptSkipped, "", err, n.config.now())
+			n.observeAttempt(name, AttemptSkipped)
+		default:
+			if ctx.Err() == nil {
+				n.health.failure(name)
+			}
+			delivery.attempt(name, AttemptError, "", err, n.config.now())
+			n.observeAttempt(name, AttemptError)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			n.config.Logger.Warn(ctx, "notification channel failed",
+				"channel", name,
+				"template", delivery.Template,
+				"delivery_id", delivery.ID,
+				"error", err,
+			)
+		}
+		if delivered && !preference.All {
+			break
+		}
+	}
+
+	if delivered {
+		delivery.Status = StatusDelivered
+		return nil
+	}
+	delivery.Status = StatusFailed
+	if len(errs) == 0 {
+		return ErrNoChannel
+	}
+	return errors.Join(append([]error{ErrDeliveryFailed}, errs...)...)
+}
+
+func (n *notifier) finish(ctx context.Context, delivery *Delivery, err error) {
+	if delivery.Status == "" {
+		delivery.Status = StatusFailed
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	if n.metrics != nil {
+		n.metrics.deliveries.WithLabelValues(delivery.Template, delivery.Status).Inc()
+	}
+	if n.config.Tracker == nil {
+		return
+	}
+	if trackErr := n.config.Tracker.Save(context.WithoutCancel(ctx), delivery); trackErr != nil {
+		n.config.Logger.Warn(ctx, "notification tracking failed", "delivery_id", delivery.ID, "error", trackErr)
+	}
+}
+
+func (n *notifier) observeAttempt(channel, result string) {
+	if n.metrics != nil {
+		n.metrics.attempts.WithLabelValues(channel, result).Inc()
+	}
+}
+
+// allow counts one delivery in the fixed window containing now. The count
+// is read and written separately, so concurrent senders may briefly exceed
+// the cap.
+func (n *notifier) allow(ctx context.Context, key string, limit RateCap) bool {
+	if limit.Limit <= 0 || limit.Window <= 0 {
+		return true
+	}
+	now := n.config.now()
+	window := now.Truncate(limit.Window)
+	key = fmt.Sprintf("%srate:%s:%d", n.config.Prefix, key, window.Unix())
+
+	count := 0
+	if raw, err := n.config.Store.Get(ctx, key); err == nil {
+		fmt.Sscan(string(raw), &count)
+	}
+	if count >= limit.Limit {
+		return false
+	}
+	ttl := window.Add(limit.Window).Sub(now)
+	_ = n.config.Store.Set(ctx, key, []byte(fmt.Sprint(count+1)), ttl)
+	return true
+}
+
+func (n *notifier) dedupeKey(userID, template string, data map[string]any) (string, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("notification: encode data: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(userID+"\x00"+template+"\x00"), payload...))
+	return n.config.Prefix + "dedupe:" + hex.EncodeToString(sum[:16]), nil
+}
+
+func newDeliveryID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
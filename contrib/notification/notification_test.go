@@ -0,0 +1,315 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/contrib/sms"
+	"github.com/bang-go/micro/store/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeChannel struct {
+	name string
+
+	mu   sync.Mutex
+	err  error
+	sent []*Message
+}
+
+func (c *fakeChannel) Name() string { return c.name }
+
+func (c *fakeChannel) Send(_ context.Context, message *Message) (*Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, message)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &Receipt{MessageID: c.name + "-id"}, nil
+}
+
+func (c *fakeChannel) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sent)
+}
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func staticPreferences(preference *Preference) Preferences {
+	return PreferencesFunc(func(context.Context, string, string) (*Preference, error) {
+		return preference, nil
+	})
+}
+
+func newTestNotifier(t *testing.T, conf *Config) (*notifier, *testClock) {
+	t.Helper()
+	clock := &testClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	conf.now = clock.Now
+	if conf.MetricsRegisterer == nil {
+		conf.DisableMetrics = true
+	}
+	n, err := New(conf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return n.(*notifier), clock
+}
+
+func TestNotifyFallsBackAndTracks(t *testing.T) {
+	push := &fakeChannel{name: ChannelPush, err: errors.New("push down")}
+	sms := &fakeChannel{name: ChannelSMS}
+	tracker := NewStoreTracker(nil, "", 0)
+	registry := prometheus.NewRegistry()
+	n, _ := newTestNotifier(t, &Config{
+		Channels: []Channel{push, sms},
+		Preferences: staticPreferences(&Preference{
+			Channels: []string{ChannelPush, ChannelSMS},
+			Contacts: map[string]string{ChannelSMS: "+8613800000000"},
+		}),
+		Tracker:           tracker,
+		MetricsRegisterer: registry,
+	})
+
+	delivery, err := n.Notify(context.Background(), "u1", "order_shipped", map[string]any{"order": "42"})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if delivery.Status != StatusDelivered || len(delivery.Attempts) != 2 {
+		t.Fatalf("delivery = %+v", delivery)
+	}
+	if delivery.Attempts[0].Result != AttemptError || delivery.Attempts[1].MessageID != "sms-id" {
+		t.Fatalf("attempts = %+v", delivery.Attempts)
+	}
+	if got := sms.sent[0]; got.Contact != "+8613800000000" || got.DeliveryID != delivery.ID || got.Data["order"] != "42" {
+		t.Fatalf("sms message = %+v", got)
+	}
+
+	saved, err := tracker.Get(context.Background(), delivery.ID)
+	if err != nil || saved.Status != StatusDelivered || len(saved.Attempts) != 2 {
+		t.Fatalf("tracked delivery = %+v, err = %v", saved, err)
+	}
+	if _, err := tracker.Get(context.Background(), "missing"); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Fatalf("Get(missing) error = %v", err)
+	}
+
+	if got := testutil.ToFloat64(n.metrics.attempts.WithLabelValues(ChannelPush, AttemptError)); got != 1 {
+		t.Fatalf("push error attempts = %v", got)
+	}
+	if got := testutil.ToFloat64(n.metrics.deliveries.WithLabelValues("order_shipped", StatusDelivered)); got != 1 {
+		t.Fatalf("delivered = %v", got)
+	}
+}
+
+func TestNotifySkipsUnhealthyChannel(t *testing.T) {
+	push := &fakeChannel{name: ChannelPush, err: errors.New("push down")}
+	ws := &fakeChannel{name: ChannelWS}
+	n, clock := newTestNotifier(t, &Config{
+		Channels:     []Channel{push, ws},
+		Preferences:  staticPreferences(&Preference{Channels: []string{ChannelPush, ChannelWS}}),
+		DedupeWindow: -1,
+		Health:       &HealthConfig{FailureThreshold: 2, Cooldown: time.Minute},
+	})
+
+	for range 3 {
+		if _, err := n.Notify(context.Background(), "u1", "hello", nil); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+	if push.count() != 2 {
+		t.Fatalf("push attempts = %d, want 2 before the channel is taken out", push.count())
+	}
+
+	clock.Advance(time.Minute)
+	push.err = nil
+	delivery, err := n.Notify(context.Background(), "u1", "hello", nil)
+	if err != nil || delivery.Attempts[0].Channel != ChannelPush || delivery.Attempts[0].Result != AttemptSuccess {
+		t.Fatalf("probe delivery = %+v, err = %v", delivery, err)
+	}
+}
+
+func TestNotifyDedupes(t *testing.T) {
+	ws := &fakeChannel{name: ChannelWS}
+	n, _ := newTestNotifier(t, &Config{
+		Channels:     []Channel{ws},
+		Preferences:  staticPreferences(&Preference{Channels: []string{ChannelWS}}),
+		DedupeWindow: time.Minute,
+	})
+	ctx := context.Background()
+
+	if _, err := n.Notify(ctx, "u1", "hello", map[string]any{"n": 1}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	delivery, err := n.Notify(ctx, "u1", "hello", map[string]any{"n": 1})
+	if err != nil || delivery.Status != StatusDeduplicated {
+		t.Fatalf("duplicate delivery = %+v, err = %v", delivery, err)
+	}
+	if _, err := n.Notify(ctx, "u1", "hello", map[string]any{"n": 2}); err != nil {
+		t.Fatalf("Notify(other data) error = %v", err)
+	}
+	if ws.count() != 2 {
+		t.Fatalf("sent = %d, want 2", ws.count())
+	}
+
+	// Failed deliveries release the marker so callers can retry.
+	ws.err = errors.New("offline")
+	if _, err := n.Notify(ctx, "u2", "hello", nil); !errors.Is(err, ErrDeliveryFailed) {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	ws.err = nil
+	if delivery, err := n.Notify(ctx, "u2", "hello", nil); err != nil || delivery.Status != StatusDelivered {
+		t.Fatalf("retry delivery = %+v, err = %v", delivery, err)
+	}
+}
+
+type failingStore struct {
+	cache.Store
+	err error
+}
+
+func (s *failingStore) SetNX(context.Context, string, []byte, time.Duration) (bool, error) {
+	return false, s.err
+}
+
+func TestNotifyDedupesConcurrentAndSurfacesStoreErrors(t *testing.T) {
+	ws := &fakeChannel{name: ChannelWS}
+	n, _ := newTestNotifier(t, &Config{
+		Channels:     []Channel{ws},
+		Preferences:  staticPreferences(&Preference{Channels: []string{ChannelWS}}),
+		DedupeWindow: time.Minute,
+	})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			if _, err := n.Notify(ctx, "u1", "hello", nil); err != nil {
+				t.Errorf("Notify() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if ws.count() != 1 {
+		t.Fatalf("sent = %d, want concurrent duplicates dropped", ws.count())
+	}
+
+	storeErr := errors.New("redis down")
+	n, _ = newTestNotifier(t, &Config{
+		Channels:     []Channel{ws},
+		Preferences:  staticPreferences(&Preference{Channels: []string{ChannelWS}}),
+		Store:        &failingStore{Store: cache.NewMemory(nil), err: storeErr},
+		DedupeWindow: time.Minute,
+	})
+	delivery, err := n.Notify(ctx, "u1", "hello", nil)
+	if !errors.Is(err, storeErr) || delivery.Status != StatusFailed || ws.count() != 1 {
+		t.Fatalf("delivery = %+v, err = %v, sent = %d", delivery, err, ws.count())
+	}
+}
+
+func TestNotifyRateCaps(t *testing.T) {
+	push := &fakeChannel{name: ChannelPush}
+	sms := &fakeChannel{name: ChannelSMS}
+	n, clock := newTestNotifier(t, &Config{
+		Channels: []Channel{push, sms},
+		Preferences: staticPreferences(&Preference{
+			Channels: []string{ChannelPush, ChannelSMS},
+			Contacts: map[string]string{ChannelSMS: "+8613800000000"},
+		}),
+		DedupeWindow:    -1,
+		UserRateCap:     &RateCap{Limit: 3, Window: time.Hour},
+		ChannelRateCaps: map[string]RateCap{ChannelPush: {Limit: 1, Window: time.Hour}},
+	})
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := n.Notify(ctx, "u1", "hello", nil); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+	if push.count() != 1 || sms.count() != 2 {
+		t.Fatalf("push = %d, sms = %d", push.count(), sms.count())
+	}
+	delivery, err := n.Notify(ctx, "u1", "hello", nil)
+	if !errors.Is(err, ErrRateLimited) || delivery.Status != StatusRateLimited {
+		t.Fatalf("delivery = %+v, err = %v", delivery, err)
+	}
+
+	clock.Advance(time.Hour)
+	if _, err := n.Notify(ctx, "u1", "hello", nil); err != nil || push.count() != 2 {
+		t.Fatalf("Notify() after window error = %v, push = %d", err, push.count())
+	}
+}
+
+func TestNotifyAllAndMissingContact(t *testing.T) {
+	ws := &fakeChannel{name: ChannelWS}
+	router := &fakeSMSSender{}
+	n, _ := newTestNotifier(t, &Config{
+		Channels:     []Channel{ws, NewSMSChannel(router)},
+		Preferences:  staticPreferences(&Preference{Channels: []string{ChannelSMS, ChannelWS}, All: true}),
+		DedupeWindow: -1,
+	})
+
+	delivery, err := n.Notify(context.Background(), "u1", "login_alert", nil)
+	if err != nil || delivery.Status != StatusDelivered {
+		t.Fatalf("delivery = %+v, err = %v", delivery, err)
+	}
+	if delivery.Attempts[0].Result != AttemptSkipped || router.requests != 0 || ws.count() != 1 {
+		t.Fatalf("attempts = %+v", delivery.Attempts)
+	}
+	if n.health.channels[ChannelSMS] != nil {
+		t.Fatal("missing contact counted as a channel failure")
+	}
+}
+
+type fakeSMSSender struct {
+	requests int
+}
+
+func (s *fakeSMSSender) Send(context.Context, *sms.SendRequest) (*sms.SendResult, error) {
+	s.requests++
+	return &sms.SendResult{MessageID: "biz-1"}, nil
+}
+
+func TestNewValidation(t *testing.T) {
+	if _, err := New(nil); !errors.Is(err, ErrConfigRequired) {
+		t.Fatalf("New(nil) error = %v", err)
+	}
+	if _, err := New(&Config{}); !errors.Is(err, ErrPreferencesRequired) {
+		t.Fatalf("New() error = %v", err)
+	}
+	_, err := New(&Config{
+		Channels:    []Channel{&fakeChannel{name: "sms"}, &fakeChannel{name: "sms"}},
+		Preferences: staticPreferences(nil),
+	})
+	if !errors.Is(err, ErrDuplicateChannel) {
+		t.Fatalf("New(duplicate) error = %v", err)
+	}
+
+	n, _ := newTestNotifier(t, &Config{Preferences: staticPreferences(nil)})
+	if _, err := n.Notify(context.Background(), "", "t", nil); !errors.Is(err, ErrUserIDRequired) {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if delivery, err := n.Notify(context.Background(), "u1", "t", nil); !errors.Is(err, ErrNoChannel) || delivery.Status != StatusFailed {
+		t.Fatalf("Notify() delivery = %+v, err = %v", delivery, err)
+	}
+}
@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bang-go/micro/store/cache"
+)
+
+const defaultTrackTTL = 7 * 24 * time.Hour
+
+// Delivery is the outcome of one Notify call.
+type Delivery struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Template  string    `json:"template"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Attempts  []Attempt `json:"attempts,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Attempt is one channel tried for a delivery, including channels skipped
+// because they were unhealthy, rate limited or missing a contact.
+type Attempt struct {
+	Channel   string    `json:"channel"`
+	Result    string    `json:"result"`
+	MessageID string    `json:"message_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+func (d *Delivery) attempt(channel, result, messageID string, err error, at time.Time) {
+	attempt := Attempt{Channel: channel, Result: result, MessageID: messageID, At: at}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	d.Attempts = append(d.Attempts, attempt)
+}
+
+type Tracker interface {
+	Save(ctx context.Context, delivery *Delivery) error
+	Get(ctx context.Context, id string) (*Delivery, error)
+}
+
+// NewStoreTracker keeps deliveries in store for ttl, 7 days by default.
+func NewStoreTracker(store cache.Store, prefix string, ttl time.Duration) Tracker {
+	if store == nil {
+		store = cache.NewMemory(nil)
+	}
+	if prefix == "" {
+		prefix = defaultPrefix + "delivery:"
+	}
+	if ttl <= 0 {
+		ttl = defaultTrackTTL
+	}
+	return &storeTracker{store: store, prefix: prefix, ttl: ttl}
+}
+
+type storeTracker struct {
+	store  cache.Store
+	prefix string
+	ttl    time.Duration
+}
+
+func (t *storeTracker) Save(ctx context.Context, delivery *Delivery) error {
+	payload, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("notification: encode delivery: %w", err)
+	}
+	return t.store.Set(ctx, t.prefix+delivery.ID, payload, t.ttl)
+}
+
+func (t *storeTracker) Get(ctx context.Context, id string) (*Delivery, error) {
+	payload, err := t.store.Get(ctx, t.prefix+id)
+	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+	var delivery Delivery
+	if err := json.Unmarshal(payload, &delivery); err != nil {
+		return nil, fmt.Errorf("notification: decode delivery: %w", err)
+	}
+	return &delivery, nil
+}
@@ -0,0 +1,58 @@
+package alipaytest_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/bang-go/micro/contrib/pay/alipay"
+	"github.com/bang-go/micro/contrib/pay/alipay/alipaytest"
+)
+
+func newClient(t *testing.T, signer *alipaytest.Signer) alipay.Client {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	client, err := alipay.New(&alipay.Config{
+		AppID:           "2021000000000000",
+		PrivateKey:      base64.StdEncoding.EncodeToString(x509.MarshalPKCS1PrivateKey(key)),
+		AlipayPublicKey: signer.PublicKey(),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return client
+}
+
+func TestNotifyRequestVerifies(t *testing.T) {
+	signer, err := alipaytest.NewSigner()
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	client := newClient(t, signer)
+
+	req, err := signer.NotifyRequest("/pay/alipay/notify", alipaytest.TradeNotify("2021000000000000", "order-1", "9.90"))
+	if err != nil {
+		t.Fatalf("NotifyRequest() error = %v", err)
+	}
+	notify, err := client.ParseNotify(req)
+	if err != nil {
+		t.Fatalf("ParseNotify() error = %v", err)
+	}
+	if notify.GetString("out_trade_no") != "order-1" || notify.GetString("trade_status") != "TRADE_SUCCESS" {
+		t.Fatalf("notify = %v", notify)
+	}
+
+	params := alipaytest.TradeNotify("2021000000000000", "order-1", "9.90")
+	other, _ := alipaytest.NewSigner()
+	forged, _ := other.NotifyRequest("/pay/alipay/notify", params)
+	if _, err := client.ParseNotify(forged); err == nil || errors.Is(err, alipay.ErrVerifyConfigRequired) {
+		t.Fatalf("ParseNotify() of a forged notify error = %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+// Package paytest records payment notify callbacks and replays them against
+// a local handler, so notify handling can be debugged without new payments.
+package paytest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRequestRequired = errors.New("paytest: request is required")
+	ErrHandlerRequired = errors.New("paytest: handler is required")
+)
+
+// Callback is a stored notify request.
+type Callback struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	ReceivedAt time.Time   `json:"received_at"`
+}
+
+// Capture copies req into a Callback and restores its body so the request
+// can still be handled.
+func Capture(req *http.Request) (Callback, error) {
+	if req == nil {
+		return Callback{}, ErrRequestRequired
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return Callback{}, fmt.Errorf("paytest: read body failed: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return Callback{
+		Method:     req.Method,
+		URL:        req.URL.RequestURI(),
+		Header:     req.Header.Clone(),
+		Body:       body,
+		ReceivedAt: time.Now(),
+	}, nil
+}
+
+// Request rebuilds the callback as a server-side request.
+func (c Callback) Request(ctx context.Context) *http.Request {
+	method := c.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	target := c.URL
+	if target == "" {
+		target = "/"
+	}
+	req := httptest.NewRequest(method, target, bytes.NewReader(c.Body)).WithContext(ctx)
+	req.Header = c.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	return req
+}
+
+// Recorder wraps the notify handler and stores every request it receives.
+type Recorder struct {
+	mu        sync.Mutex
+	callbacks []Callback
+	w         io.Writer
+}
+
+// NewRecorder returns a Recorder that also appends each callback to w as a
+// JSON line when w is not nil.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+func (r *Recorder) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if callback, err := Capture(req); err == nil {
+			r.mu.Lock()
+			r.callbacks = append(r.callbacks, callback)
+			if r.w != nil {
+				_ = Save(r.w, callback)
+			}
+			r.mu.Unlock()
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (r *Recorder) Callbacks() []Callback {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Callback(nil), r.callbacks...)
+}
+
+// Save writes callbacks as JSON lines.
+func Save(w io.Writer, callbacks ...Callback) error {
+	encoder := json.NewEncoder(w)
+	for _, callback := range callbacks {
+		if err := encoder.Encode(callback); err != nil {
+			return fmt.Errorf("paytest: encode callback failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// Load reads callbacks written by Save, skipping blank lines.
+func Load(r io.Reader) ([]Callback, error) {
+	var callbacks []Callback
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var callback Callback
+		if err := json.Unmarshal([]byte(text), &callback); err != nil {
+			return nil, fmt.Errorf("paytest: line %d: %w", line, err)
+		}
+		callbacks = append(callbacks, callback)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("paytest: read callbacks failed: %w", err)
+	}
+	return callbacks, nil
+}
+
+// Result is the response of one replayed callback.
+type Result struct {
+	Callback   Callback
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+type ReplayOption func(*replayOptions)
+
+type replayOptions struct {
+	rewrite func(*http.Request) error
+}
+
+// WithRewrite edits each request before it is sent, e.g. to re-sign a
+// stored WeChat Pay callback whose timestamp has expired.
+func WithRewrite(rewrite func(*http.Request) error) ReplayOption {
+	return func(o *replayOptions) {
+		o.rewrite = rewrite
+	}
+}
+
+// Replay sends callbacks to handler in order and returns its responses.
+func Replay(ctx context.Context, handler http.Handler, callbacks []Callback, opts ...ReplayOption) ([]Result, error) {
+	if handler == nil {
+		return nil, ErrHandlerRequired
+	}
+	return replay(ctx, callbacks, opts, func(req *http.Request) (int, http.Header, []byte, error) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code, recorder.Header(), recorder.Body.Bytes(), nil
+	})
+}
+
+// ReplayURL sends callbacks to a running server at baseURL, keeping each
+// callback's path and query.
+func ReplayURL(ctx context.Context, client *http.Client, baseURL string, callbacks []Callback, opts ...ReplayOption) ([]Result, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	return replay(ctx, callbacks, opts, func(req *http.Request) (int, http.Header, []byte, error) {
+		out, err := http.NewRequestWithContext(req.Context(), req.Method, baseURL+req.URL.RequestURI(), req.Body)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		out.Header = req.Header
+		resp, err := client.Do(out)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return resp.StatusCode, resp.Header, body, err
+	})
+}
+
+func replay(ctx context.Context, callbacks []Callback, opts []ReplayOption, send func(*http.Request) (int, http.Header, []byte, error)) ([]Result, error) {
+	settings := replayOptions{}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	results := make([]Result, 0, len(callbacks))
+	for i, callback := range callbacks {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		req := callback.Request(ctx)
+		if settings.rewrite != nil {
+			if err := settings.rewrite(req); err != nil {
+				return results, fmt.Errorf("paytest: rewrite callback %d failed: %w", i, err)
+			}
+		}
+		status, header, body, err := send(req)
+		if err != nil {
+			return results, fmt.Errorf("paytest: replay callback %d failed: %w", i, err)
+		}
+		results = append(results, Result{Callback: callback, StatusCode: status, Header: header, Body: body})
+	}
+	return results, nil
+}
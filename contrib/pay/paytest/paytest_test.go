@@ -0,0 +1,82 @@
+package paytest_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bang-go/micro/contrib/pay/paytest"
+)
+
+func echoHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Path", r.URL.Path)
+		_, _ = w.Write([]byte(r.Header.Get("X-Tag") + ":" + string(body)))
+	})
+}
+
+func TestRecordSaveLoadReplay(t *testing.T) {
+	var stored bytes.Buffer
+	recorder := paytest.NewRecorder(&stored)
+	handler := recorder.Wrap(echoHandler())
+
+	for _, body := range []string{"first", "second"} {
+		req := httptest.NewRequest(http.MethodPost, "/notify?provider=alipay", strings.NewReader(body))
+		req.Header.Set("X-Tag", "live")
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		if got := resp.Body.String(); got != "live:"+body {
+			t.Fatalf("wrapped handler saw %q", got)
+		}
+	}
+	if got := len(recorder.Callbacks()); got != 2 {
+		t.Fatalf("Callbacks() = %d", got)
+	}
+
+	callbacks, err := paytest.Load(&stored)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(callbacks) != 2 || callbacks[1].URL != "/notify?provider=alipay" || string(callbacks[1].Body) != "second" {
+		t.Fatalf("callbacks = %+v", callbacks)
+	}
+
+	results, err := paytest.Replay(context.Background(), echoHandler(), callbacks, paytest.WithRewrite(func(r *http.Request) error {
+		r.Header.Set("X-Tag", "replay")
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if len(results) != 2 || string(results[0].Body) != "replay:first" || results[0].Header.Get("X-Path") != "/notify" {
+		t.Fatalf("results = %+v", results)
+	}
+}
+
+func TestReplayURL(t *testing.T) {
+	server := httptest.NewServer(echoHandler())
+	defer server.Close()
+
+	callbacks := []paytest.Callback{{
+		Method: http.MethodPost,
+		URL:    "/pay/wechat/notify",
+		Header: http.Header{"X-Tag": {"stored"}},
+		Body:   []byte(`{"id":"1"}`),
+	}}
+	results, err := paytest.ReplayURL(context.Background(), server.Client(), server.URL+"/", callbacks)
+	if err != nil {
+		t.Fatalf("ReplayURL() error = %v", err)
+	}
+	if results[0].StatusCode != http.StatusOK || string(results[0].Body) != `stored:{"id":"1"}` || results[0].Header.Get("X-Path") != "/pay/wechat/notify" {
+		t.Fatalf("result = %+v", results[0])
+	}
+
+	if _, err := paytest.ReplayURL(context.Background(), nil, "http://127.0.0.1:1", callbacks); err == nil {
+		t.Fatal("ReplayURL() to a closed port succeeded")
+	}
+}
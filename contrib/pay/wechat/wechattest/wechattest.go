@@ -0,0 +1,187 @@
+// Package wechattest builds WeChat Pay v3 notify requests signed and
+// encrypted with local keys, for exercising notify handling without real
+// payments.
+package wechattest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/bang-go/util"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/auth"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/auth/verifiers"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/notify"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments"
+	"github.com/wechatpay-apiv3/wechatpay-go/utils"
+)
+
+const (
+	DefaultKeyID = "PUB_KEY_ID_TEST"
+
+	signatureType  = "WECHATPAY2-SHA256-RSA2048"
+	aeadAlgorithm  = "AEAD_AES_256_GCM"
+	associatedData = "transaction"
+)
+
+var ErrInvalidAPIv3Key = errors.New("wechattest: api v3 key must be 32 bytes")
+
+// Platform plays the WeChat Pay side of a notify: it signs requests with a
+// local platform key and encrypts resources with the merchant APIv3 key.
+type Platform struct {
+	KeyID    string
+	apiV3Key string
+	key      *rsa.PrivateKey
+}
+
+// NewPlatform generates a platform key; apiV3Key must be the 32-byte key the
+// handler under test decrypts with.
+func NewPlatform(apiV3Key string) (*Platform, error) {
+	if len(apiV3Key) != 32 {
+		return nil, ErrInvalidAPIv3Key
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("wechattest: generate key failed: %w", err)
+	}
+	return &Platform{KeyID: DefaultKeyID, apiV3Key: apiV3Key, key: key}, nil
+}
+
+// Verifier trusts the platform key, in place of the downloaded certificates.
+func (p *Platform) Verifier() auth.Verifier {
+	return verifiers.NewSHA256WithRSAPubkeyVerifier(p.KeyID, p.key.PublicKey)
+}
+
+// NotifyHandler parses notifies the same way wechat.Client.ParseNotify does.
+func (p *Platform) NotifyHandler() (*notify.Handler, error) {
+	return notify.NewRSANotifyHandler(p.apiV3Key, p.Verifier())
+}
+
+// NotifyRequest returns a signed POST to target whose resource is content
+// encrypted with the APIv3 key, e.g. eventType "TRANSACTION.SUCCESS".
+func (p *Platform) NotifyRequest(target, eventType string, content any) (*http.Request, error) {
+	plaintext, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("wechattest: marshal content failed: %w", err)
+	}
+	nonce, err := utils.GenerateNonce()
+	if err != nil {
+		return nil, err
+	}
+	nonce = nonce[:12]
+	ciphertext, err := p.encrypt(nonce, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := utils.GenerateNonce()
+	now := time.Now()
+	body, err := json.Marshal(notify.Request{
+		ID:           id,
+		CreateTime:   util.Ptr(now),
+		EventType:    eventType,
+		ResourceType: "encrypt-resource",
+		Summary:      "test notify",
+		Resource: &notify.EncryptedResource{
+			Algorithm:      aeadAlgorithm,
+			Ciphertext:     ciphertext,
+			AssociatedData: associatedData,
+			Nonce:          nonce,
+			OriginalType:   associatedData,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wechattest: marshal notify failed: %w", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if err := p.Resign(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Resign replaces the signature headers of req with a fresh timestamp signed
+// by the platform key. Stored callbacks fail verification after five
+// minutes; use Resign with paytest.WithRewrite to replay them.
+func (p *Platform) Resign(req *http.Request) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("wechattest: read body failed: %w", err)
+		}
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	nonce, err := utils.GenerateNonce()
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	hashed := sha256.Sum256([]byte(timestamp + "\n" + nonce + "\n" + string(body) + "\n"))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return fmt.Errorf("wechattest: sign failed: %w", err)
+	}
+
+	req.Header.Set("Wechatpay-Serial", p.KeyID)
+	req.Header.Set("Wechatpay-Timestamp", timestamp)
+	req.Header.Set("Wechatpay-Nonce", nonce)
+	req.Header.Set("Wechatpay-Signature", base64.StdEncoding.EncodeToString(signature))
+	req.Header.Set("Wechatpay-Signature-Type", signatureType)
+	return nil
+}
+
+func (p *Platform) encrypt(nonce string, plaintext []byte) (string, error) {
+	block, err := aes.NewCipher([]byte(p.apiV3Key))
+	if err != nil {
+		return "", err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nil, []byte(nonce), plaintext, []byte(associatedData))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Transaction returns a paid JSAPI transaction for a TRANSACTION.SUCCESS
+// notify; adjust fields for other trade states.
+func Transaction(appID, mchID, outTradeNo string, total int64) *payments.Transaction {
+	now := time.Now().Format(time.RFC3339)
+	return &payments.Transaction{
+		Appid:          util.Ptr(appID),
+		Mchid:          util.Ptr(mchID),
+		OutTradeNo:     util.Ptr(outTradeNo),
+		TransactionId:  util.Ptr("4200" + outTradeNo),
+		TradeType:      util.Ptr("JSAPI"),
+		TradeState:     util.Ptr("SUCCESS"),
+		TradeStateDesc: util.Ptr("支付成功"),
+		BankType:       util.Ptr("OTHERS"),
+		SuccessTime:    util.Ptr(now),
+		Payer:          &payments.TransactionPayer{Openid: util.Ptr("test-openid")},
+		Amount: &payments.TransactionAmount{
+			Total:         util.Ptr(total),
+			PayerTotal:    util.Ptr(total),
+			Currency:      util.Ptr("CNY"),
+			PayerCurrency: util.Ptr("CNY"),
+		},
+	}
+}
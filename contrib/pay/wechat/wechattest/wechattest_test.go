@@ -0,0 +1,73 @@
+package wechattest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bang-go/micro/contrib/pay/wechat/wechattest"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments"
+)
+
+const apiV3Key = "01234567890123456789012345678901"
+
+func TestNotifyRequestParses(t *testing.T) {
+	platform, err := wechattest.NewPlatform(apiV3Key)
+	if err != nil {
+		t.Fatalf("NewPlatform() error = %v", err)
+	}
+	handler, err := platform.NotifyHandler()
+	if err != nil {
+		t.Fatalf("NotifyHandler() error = %v", err)
+	}
+
+	req, err := platform.NotifyRequest("/pay/wechat/notify", "TRANSACTION.SUCCESS", wechattest.Transaction("wx1", "1900", "order-1", 100))
+	if err != nil {
+		t.Fatalf("NotifyRequest() error = %v", err)
+	}
+	var transaction payments.Transaction
+	notifyReq, err := handler.ParseNotifyRequest(context.Background(), req, &transaction)
+	if err != nil {
+		t.Fatalf("ParseNotifyRequest() error = %v", err)
+	}
+	if notifyReq.EventType != "TRANSACTION.SUCCESS" || *transaction.OutTradeNo != "order-1" || *transaction.Amount.Total != 100 {
+		t.Fatalf("notify = %+v, transaction = %+v", notifyReq, transaction)
+	}
+
+	other, _ := wechattest.NewPlatform(apiV3Key)
+	req, _ = other.NotifyRequest("/pay/wechat/notify", "TRANSACTION.SUCCESS", wechattest.Transaction("wx1", "1900", "order-1", 100))
+	if _, err := handler.ParseNotifyRequest(context.Background(), req, &transaction); err == nil {
+		t.Fatal("ParseNotifyRequest() accepted a notify signed by another platform key")
+	}
+}
+
+func TestResignRefreshesExpiredTimestamp(t *testing.T) {
+	platform, _ := wechattest.NewPlatform(apiV3Key)
+	handler, _ := platform.NotifyHandler()
+
+	req, err := platform.NotifyRequest("/notify", "TRANSACTION.SUCCESS", map[string]string{"out_trade_no": "order-2"})
+	if err != nil {
+		t.Fatalf("NotifyRequest() error = %v", err)
+	}
+	req.Header.Set("Wechatpay-Timestamp", "1700000000")
+	if _, err := handler.ParseNotifyRequest(context.Background(), req, &map[string]any{}); err == nil {
+		t.Fatal("ParseNotifyRequest() accepted a stale timestamp")
+	}
+
+	if err := platform.Resign(req); err != nil {
+		t.Fatalf("Resign() error = %v", err)
+	}
+	content := map[string]any{}
+	if _, err := handler.ParseNotifyRequest(context.Background(), req, &content); err != nil {
+		t.Fatalf("ParseNotifyRequest() after Resign error = %v", err)
+	}
+	if content["out_trade_no"] != "order-2" {
+		t.Fatalf("content = %v", content)
+	}
+}
+
+func TestNewPlatformRejectsShortKey(t *testing.T) {
+	if _, err := wechattest.NewPlatform("short"); !errors.Is(err, wechattest.ErrInvalidAPIv3Key) {
+		t.Fatalf("NewPlatform() error = %v", err)
+	}
+}
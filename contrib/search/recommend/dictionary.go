@@ -0,0 +1,226 @@
+package recommend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	aliyunoss "github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
+	"github.com/bang-go/util"
+)
+
+type Source interface {
+	Load(context.Context) ([]byte, error)
+}
+
+type SourceFunc func(context.Context) ([]byte, error)
+
+func (f SourceFunc) Load(ctx context.Context) ([]byte, error) {
+	return f(ctx)
+}
+
+type ossObjectGetter interface {
+	GetObject(context.Context, *aliyunoss.GetObjectRequest, ...func(*aliyunoss.Options)) (*aliyunoss.GetObjectResult, error)
+}
+
+type Dictionary struct {
+	source  Source
+	entries atomic.Pointer[map[string][]string]
+}
+
+func NewDictionary(source Source) *Dictionary {
+	d := &Dictionary{source: source}
+	empty := map[string][]string{}
+	d.entries.Store(&empty)
+	return d
+}
+
+func LoadDictionary(ctx context.Context, source Source) (*Dictionary, error) {
+	d := NewDictionary(source)
+	if err := d.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func StaticSource(content string) Source {
+	return SourceFunc(func(context.Context) ([]byte, error) {
+		return []byte(content), nil
+	})
+}
+
+func FileSource(path string) Source {
+	path = strings.TrimSpace(path)
+	return SourceFunc(func(ctx context.Context) ([]byte, error) {
+		if path == "" {
+			return nil, ErrSourcePathRequired
+		}
+		return os.ReadFile(path)
+	})
+}
+
+func OSSSource(client ossObjectGetter, bucket string, key string) Source {
+	bucket = strings.TrimSpace(bucket)
+	key = strings.TrimSpace(key)
+	return SourceFunc(func(ctx context.Context) ([]byte, error) {
+		if client == nil {
+			return nil, ErrOSSClientRequired
+		}
+		if bucket == "" || key == "" {
+			return nil, ErrSourcePathRequired
+		}
+		result, err := client.GetObject(ctx, &aliyunoss.GetObjectRequest{
+			Bucket: util.Ptr(bucket),
+			Key:    util.Ptr(key),
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer result.Body.Close()
+		return io.ReadAll(result.Body)
+	})
+}
+
+func (d *Dictionary) Reload(ctx context.Context) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	if d.source == nil {
+		return ErrSourceRequired
+	}
+
+	data, err := d.source.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("recommend: load dictionary failed: %w", err)
+	}
+	entries, err := parseDictionary(data)
+	if err != nil {
+		return err
+	}
+	d.entries.Store(&entries)
+	return nil
+}
+
+func (d *Dictionary) Watch(ctx context.Context, interval time.Duration, onError func(error)) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	if interval <= 0 {
+		return ErrInvalidReloadInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := d.Reload(ctx); err != nil && onError != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func (d *Dictionary) Lookup(term string) ([]string, bool) {
+	if d == nil {
+		return nil, false
+	}
+	values, ok := (*d.entries.Load())[normalizeTerm(term)]
+	if !ok {
+		return nil, false
+	}
+	return append([]string(nil), values...), true
+}
+
+func (d *Dictionary) Contains(term string) bool {
+	_, ok := d.Lookup(term)
+	return ok
+}
+
+func (d *Dictionary) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(*d.entries.Load())
+}
+
+func parseDictionary(data []byte) (map[string][]string, error) {
+	entries := make(map[string][]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if left, right, found := strings.Cut(line, "=>"); found {
+			key := normalizeTerm(left)
+			values := splitTerms(right)
+			if key == "" || len(values) == 0 {
+				return nil, fmt.Errorf("%w: line %d", ErrInvalidDictionary, lineNo)
+			}
+			entries[key] = appendUnique(entries[key], values...)
+			continue
+		}
+
+		group := splitTerms(line)
+		if len(group) == 1 {
+			if _, ok := entries[group[0]]; !ok {
+				entries[group[0]] = nil
+			}
+			continue
+		}
+		for _, term := range group {
+			for _, other := range group {
+				if other != term {
+					entries[term] = appendUnique(entries[term], other)
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func splitTerms(value string) []string {
+	parts := strings.Split(value, ",")
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if term := normalizeTerm(part); term != "" {
+			out = appendUnique(out, term)
+		}
+	}
+	return out
+}
+
+func appendUnique(values []string, items ...string) []string {
+	for _, item := range items {
+		exists := false
+		for _, value := range values {
+			if value == item {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+func normalizeTerm(term string) string {
+	return strings.ToLower(strings.TrimSpace(term))
+}
@@ -0,0 +1,144 @@
+package recommend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	aliyunoss "github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
+	"github.com/bang-go/util"
+)
+
+func TestParseDictionary(t *testing.T) {
+	entries, err := parseDictionary([]byte("A, b ,c\nfoo => Bar, baz\nfoo => qux\nstop\n\n# ignored"))
+	if err != nil {
+		t.Fatalf("parseDictionary() error = %v", err)
+	}
+	if !reflect.DeepEqual(entries["a"], []string{"b", "c"}) || !reflect.DeepEqual(entries["c"], []string{"a", "b"}) {
+		t.Fatalf("unexpected equivalent group: %#v", entries)
+	}
+	if !reflect.DeepEqual(entries["foo"], []string{"bar", "baz", "qux"}) {
+		t.Fatalf("unexpected explicit mapping: %#v", entries["foo"])
+	}
+	if _, ok := entries["stop"]; !ok {
+		t.Fatalf("expected single term entry: %#v", entries)
+	}
+	if _, ok := entries["bar"]; ok {
+		t.Fatalf("explicit mapping should be one-way: %#v", entries)
+	}
+
+	if _, err := parseDictionary([]byte(" => x")); !errors.Is(err, ErrInvalidDictionary) {
+		t.Fatalf("expected ErrInvalidDictionary, got %v", err)
+	}
+}
+
+func TestDictionaryReloadKeepsPreviousOnFailure(t *testing.T) {
+	var content atomic.Value
+	content.Store("a => b")
+	dict := NewDictionary(SourceFunc(func(context.Context) ([]byte, error) {
+		value := content.Load().(string)
+		if value == "" {
+			return nil, errors.New("boom")
+		}
+		return []byte(value), nil
+	}))
+
+	if dict.Contains("a") {
+		t.Fatal("expected empty dictionary before first reload")
+	}
+	if err := dict.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if values, ok := dict.Lookup(" A "); !ok || !reflect.DeepEqual(values, []string{"b"}) {
+		t.Fatalf("Lookup() = %#v, %v", values, ok)
+	}
+
+	content.Store("")
+	if err := dict.Reload(context.Background()); err == nil {
+		t.Fatal("expected reload error")
+	}
+	if !dict.Contains("a") || dict.Len() != 1 {
+		t.Fatal("expected previous entries to survive failed reload")
+	}
+
+	if err := dict.Reload(nil); !errors.Is(err, ErrContextRequired) {
+		t.Fatalf("expected ErrContextRequired, got %v", err)
+	}
+	if err := NewDictionary(nil).Reload(context.Background()); !errors.Is(err, ErrSourceRequired) {
+		t.Fatalf("expected ErrSourceRequired, got %v", err)
+	}
+}
+
+func TestDictionaryWatch(t *testing.T) {
+	var calls atomic.Int32
+	dict := NewDictionary(SourceFunc(func(context.Context) ([]byte, error) {
+		if calls.Add(1) > 1 {
+			return []byte("x => y"), nil
+		}
+		return []byte("a => b"), nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- dict.Watch(ctx, 5*time.Millisecond, nil) }()
+
+	deadline := time.Now().Add(time.Second)
+	for !dict.Contains("x") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if !dict.Contains("x") {
+		t.Fatal("expected watch to reload dictionary")
+	}
+
+	if err := dict.Watch(context.Background(), 0, nil); !errors.Is(err, ErrInvalidReloadInterval) {
+		t.Fatalf("expected ErrInvalidReloadInterval, got %v", err)
+	}
+}
+
+func TestFileAndOSSSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synonyms.txt")
+	if err := os.WriteFile(path, []byte("a, b"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	dict, err := LoadDictionary(context.Background(), FileSource(path))
+	if err != nil || !dict.Contains("b") {
+		t.Fatalf("LoadDictionary(file) = %v, %v", dict, err)
+	}
+
+	getter := &fakeOSSGetter{body: "c => d"}
+	dict, err = LoadDictionary(context.Background(), OSSSource(getter, " dicts ", " synonyms.txt "))
+	if err != nil || !dict.Contains("c") {
+		t.Fatalf("LoadDictionary(oss) = %v, %v", dict, err)
+	}
+	if util.DerefZero(getter.request.Bucket) != "dicts" || util.DerefZero(getter.request.Key) != "synonyms.txt" {
+		t.Fatalf("unexpected oss request: %+v", getter.request)
+	}
+
+	if _, err := LoadDictionary(context.Background(), OSSSource(nil, "b", "k")); !errors.Is(err, ErrOSSClientRequired) {
+		t.Fatalf("expected ErrOSSClientRequired, got %v", err)
+	}
+	if _, err := LoadDictionary(context.Background(), FileSource(" ")); !errors.Is(err, ErrSourcePathRequired) {
+		t.Fatalf("expected ErrSourcePathRequired, got %v", err)
+	}
+}
+
+type fakeOSSGetter struct {
+	body    string
+	request *aliyunoss.GetObjectRequest
+}
+
+func (f *fakeOSSGetter) GetObject(_ context.Context, request *aliyunoss.GetObjectRequest, _ ...func(*aliyunoss.Options)) (*aliyunoss.GetObjectResult, error) {
+	f.request = request
+	return &aliyunoss.GetObjectResult{Body: io.NopCloser(bytes.NewBufferString(f.body))}, nil
+}
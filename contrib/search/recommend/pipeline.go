@@ -0,0 +1,233 @@
+package recommend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrContextRequired       = errors.New("recommend: context is required")
+	ErrStageRequired         = errors.New("recommend: stage is required")
+	ErrDictionaryRequired    = errors.New("recommend: dictionary is required")
+	ErrSourceRequired        = errors.New("recommend: dictionary source is required")
+	ErrSourcePathRequired    = errors.New("recommend: dictionary source path is required")
+	ErrOSSClientRequired     = errors.New("recommend: oss client is required")
+	ErrInvalidDictionary     = errors.New("recommend: invalid dictionary entry")
+	ErrInvalidReloadInterval = errors.New("recommend: reload interval must be positive")
+	ErrFieldRequired         = errors.New("recommend: field is required")
+)
+
+type Tokenizer func(string) []string
+
+type Stage interface {
+	Name() string
+	Apply(context.Context, *Query) error
+}
+
+type Config struct {
+	Tokenizer Tokenizer
+	Stages    []Stage
+}
+
+type Query struct {
+	Raw   string
+	Terms []Term
+}
+
+type Term struct {
+	Text     string
+	Original string
+	Synonyms []string
+}
+
+type Pipeline struct {
+	tokenizer Tokenizer
+	stages    []Stage
+}
+
+func New(conf *Config) (*Pipeline, error) {
+	if conf == nil {
+		conf = &Config{}
+	}
+
+	stages := make([]Stage, 0, len(conf.Stages))
+	for _, stage := range conf.Stages {
+		if stage == nil {
+			return nil, ErrStageRequired
+		}
+		if fn, ok := stage.(*stageFunc); ok && fn.apply == nil {
+			return nil, fmt.Errorf("%w: stage %s has no apply func", ErrStageRequired, fn.name)
+		}
+		stages = append(stages, stage)
+	}
+
+	tokenizer := conf.Tokenizer
+	if tokenizer == nil {
+		tokenizer = strings.Fields
+	}
+
+	return &Pipeline{
+		tokenizer: tokenizer,
+		stages:    stages,
+	}, nil
+}
+
+func (p *Pipeline) Rewrite(ctx context.Context, raw string) (*Query, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+
+	query := &Query{Raw: raw}
+	for _, token := range p.tokenizer(raw) {
+		if text := normalizeTerm(token); text != "" {
+			query.Terms = append(query.Terms, Term{Text: text, Original: token})
+		}
+	}
+
+	for _, stage := range p.stages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := stage.Apply(ctx, query); err != nil {
+			return nil, fmt.Errorf("recommend: stage %s failed: %w", stage.Name(), err)
+		}
+	}
+	return query, nil
+}
+
+func (q *Query) Text() string {
+	if q == nil {
+		return ""
+	}
+	texts := make([]string, 0, len(q.Terms))
+	for _, term := range q.Terms {
+		texts = append(texts, term.Text)
+	}
+	return strings.Join(texts, " ")
+}
+
+func (q *Query) Groups() [][]string {
+	if q == nil {
+		return nil
+	}
+	groups := make([][]string, 0, len(q.Terms))
+	for _, term := range q.Terms {
+		groups = append(groups, appendUnique([]string{term.Text}, term.Synonyms...))
+	}
+	return groups
+}
+
+func (q *Query) OpenSearchClause(index string) (string, error) {
+	index = strings.TrimSpace(index)
+	if index == "" {
+		return "", ErrFieldRequired
+	}
+
+	clauses := make([]string, 0, len(q.Terms))
+	for _, group := range q.Groups() {
+		parts := make([]string, 0, len(group))
+		for _, value := range group {
+			parts = append(parts, fmt.Sprintf("%s:'%s'", index, strings.ReplaceAll(value, "'", `\'`)))
+		}
+		if len(parts) == 1 {
+			clauses = append(clauses, parts[0])
+			continue
+		}
+		clauses = append(clauses, "("+strings.Join(parts, " OR ")+")")
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+func (q *Query) ElasticsearchQuery(field string) (map[string]any, error) {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return nil, ErrFieldRequired
+	}
+
+	must := make([]any, 0, len(q.Terms))
+	for _, group := range q.Groups() {
+		should := make([]any, 0, len(group))
+		for _, value := range group {
+			should = append(should, map[string]any{
+				"match_phrase": map[string]any{field: value},
+			})
+		}
+		must = append(must, map[string]any{
+			"bool": map[string]any{
+				"should":               should,
+				"minimum_should_match": 1,
+			},
+		})
+	}
+	return map[string]any{
+		"bool": map[string]any{"must": must},
+	}, nil
+}
+
+type stageFunc struct {
+	name  string
+	apply func(context.Context, *Query) error
+}
+
+func (s *stageFunc) Name() string {
+	return s.name
+}
+
+func (s *stageFunc) Apply(ctx context.Context, query *Query) error {
+	if s.apply == nil {
+		return ErrStageRequired
+	}
+	return s.apply(ctx, query)
+}
+
+// StageFunc adapts apply into a Stage. New rejects a nil apply with
+// ErrStageRequired.
+func StageFunc(name string, apply func(context.Context, *Query) error) Stage {
+	return &stageFunc{name: strings.TrimSpace(name), apply: apply}
+}
+
+func StopWords(dict *Dictionary) Stage {
+	return StageFunc("stopwords", func(_ context.Context, query *Query) error {
+		if dict == nil {
+			return ErrDictionaryRequired
+		}
+		kept := query.Terms[:0]
+		for _, term := range query.Terms {
+			if !dict.Contains(term.Text) {
+				kept = append(kept, term)
+			}
+		}
+		query.Terms = kept
+		return nil
+	})
+}
+
+func Corrections(dict *Dictionary) Stage {
+	return StageFunc("corrections", func(_ context.Context, query *Query) error {
+		if dict == nil {
+			return ErrDictionaryRequired
+		}
+		for i := range query.Terms {
+			if values, ok := dict.Lookup(query.Terms[i].Text); ok && len(values) > 0 {
+				query.Terms[i].Text = values[0]
+			}
+		}
+		return nil
+	})
+}
+
+func Synonyms(dict *Dictionary) Stage {
+	return StageFunc("synonyms", func(_ context.Context, query *Query) error {
+		if dict == nil {
+			return ErrDictionaryRequired
+		}
+		for i := range query.Terms {
+			if values, ok := dict.Lookup(query.Terms[i].Text); ok {
+				query.Terms[i].Synonyms = appendUnique(query.Terms[i].Synonyms, values...)
+			}
+		}
+		return nil
+	})
+}
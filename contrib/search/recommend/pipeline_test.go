@@ -0,0 +1,115 @@
+package recommend
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPipelineRewrite(t *testing.T) {
+	ctx := context.Background()
+	stop := mustLoadDictionary(t, "的\n# comment\n了")
+	corrections := mustLoadDictionary(t, "pingguo => 苹果\niphnoe => iphone")
+	synonyms := mustLoadDictionary(t, "苹果, apple\niphone => 苹果手机")
+
+	pipeline, err := New(&Config{
+		Stages: []Stage{Corrections(corrections), StopWords(stop), Synonyms(synonyms)},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	query, err := pipeline.Rewrite(ctx, " PingGuo 的  iphnoe ")
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if query.Text() != "苹果 iphone" {
+		t.Fatalf("Text() = %q", query.Text())
+	}
+	want := [][]string{{"苹果", "apple"}, {"iphone", "苹果手机"}}
+	if got := query.Groups(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Groups() = %#v, want %#v", got, want)
+	}
+	if query.Terms[0].Original != "PingGuo" {
+		t.Fatalf("expected original token to be kept, got %q", query.Terms[0].Original)
+	}
+
+	clause, err := query.OpenSearchClause("default")
+	if err != nil {
+		t.Fatalf("OpenSearchClause() error = %v", err)
+	}
+	if clause != "(default:'苹果' OR default:'apple') AND (default:'iphone' OR default:'苹果手机')" {
+		t.Fatalf("OpenSearchClause() = %q", clause)
+	}
+
+	es, err := query.ElasticsearchQuery("title")
+	if err != nil {
+		t.Fatalf("ElasticsearchQuery() error = %v", err)
+	}
+	must := es["bool"].(map[string]any)["must"].([]any)
+	if len(must) != 2 {
+		t.Fatalf("expected one must clause per term, got %#v", es)
+	}
+}
+
+func TestPipelineValidation(t *testing.T) {
+	if _, err := New(&Config{Stages: []Stage{nil}}); !errors.Is(err, ErrStageRequired) {
+		t.Fatalf("expected ErrStageRequired, got %v", err)
+	}
+	if _, err := New(&Config{Stages: []Stage{StageFunc("noop", nil)}}); !errors.Is(err, ErrStageRequired) {
+		t.Fatalf("expected ErrStageRequired for nil apply, got %v", err)
+	}
+	if err := StageFunc("noop", nil).Apply(context.Background(), &Query{}); !errors.Is(err, ErrStageRequired) {
+		t.Fatalf("Apply() with nil func error = %v", err)
+	}
+
+	pipeline, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) error = %v", err)
+	}
+	if _, err := pipeline.Rewrite(nil, "q"); !errors.Is(err, ErrContextRequired) {
+		t.Fatalf("expected ErrContextRequired, got %v", err)
+	}
+
+	failing, _ := New(&Config{Stages: []Stage{Synonyms(nil)}})
+	if _, err := failing.Rewrite(context.Background(), "q"); !errors.Is(err, ErrDictionaryRequired) {
+		t.Fatalf("expected ErrDictionaryRequired, got %v", err)
+	}
+
+	query, _ := pipeline.Rewrite(context.Background(), "q")
+	if _, err := query.OpenSearchClause(" "); !errors.Is(err, ErrFieldRequired) {
+		t.Fatalf("expected ErrFieldRequired, got %v", err)
+	}
+}
+
+func TestPipelineCustomTokenizer(t *testing.T) {
+	pipeline, err := New(&Config{
+		Tokenizer: func(raw string) []string {
+			out := make([]string, 0, len(raw))
+			for _, r := range raw {
+				out = append(out, string(r))
+			}
+			return out
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	query, err := pipeline.Rewrite(context.Background(), "手机")
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if query.Text() != "手 机" {
+		t.Fatalf("Text() = %q", query.Text())
+	}
+}
+
+func mustLoadDictionary(t *testing.T, content string) *Dictionary {
+	t.Helper()
+	dict, err := LoadDictionary(context.Background(), StaticSource(content))
+	if err != nil {
+		t.Fatalf("LoadDictionary() error = %v", err)
+	}
+	return dict
+}
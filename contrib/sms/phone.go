@@ -0,0 +1,133 @@
+package sms
+
+import (
+	"errors"
+	"strings"
+)
+
+const DefaultCountryCode = "86"
+
+var (
+	ErrInvalidPhoneNumber = errors.New("sms: invalid phone number")
+	ErrUnknownCountryCode = errors.New("sms: unknown country calling code")
+)
+
+// callingCodes lists ITU-T E.164 country calling codes. They are prefix-free,
+// so at most one of the 1-3 digit prefixes of a number can match.
+var callingCodes = func() map[string]struct{} {
+	const codes = `1 7
+20 27 30 31 32 33 34 36 39 40 41 43 44 45 46 47 48 49 51 52 53 54 55 56 57 58
+60 61 62 63 64 65 66 81 82 84 86 90 91 92 93 94 95 98
+211 212 213 216 218 220 221 222 223 224 225 226 227 228 229 230 231 232 233 234
+235 236 237 238 239 240 241 242 243 244 245 246 248 249 250 251 252 253 254 255
+256 257 258 260 261 262 263 264 265 266 267 268 269 290 291 297 298 299 350 351
+352 353 354 355 356 357 358 359 370 371 372 373 374 375 376 377 378 379 380 381
+382 383 385 386 387 389 420 421 423 500 501 502 503 504 505 506 507 508 509 590
+591 592 593 594 595 596 597 598 599 670 672 673 674 675 676 677 678 679 680 681
+682 683 685 686 687 688 689 690 691 692 850 852 853 855 856 880 886 960 961 962
+963 964 965 966 967 968 970 971 972 973 974 975 976 977 992 993 994 995 996 998`
+
+	fields := strings.Fields(codes)
+	set := make(map[string]struct{}, len(fields))
+	for _, code := range fields {
+		set[code] = struct{}{}
+	}
+	return set
+}()
+
+type PhoneNumber struct {
+	CountryCode string
+	National    string
+}
+
+// ParsePhoneNumber accepts "+<cc><number>", "00<cc><number>" or a national
+// number, which is attributed to defaultCountryCode (DefaultCountryCode when empty).
+// Spaces, dashes, dots and parentheses are ignored.
+func ParsePhoneNumber(raw string, defaultCountryCode string) (PhoneNumber, error) {
+	var b strings.Builder
+	trimmed := strings.TrimSpace(raw)
+	for i, r := range trimmed {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return PhoneNumber{}, ErrInvalidPhoneNumber
+		}
+	}
+	digits := b.String()
+	if digits == "" {
+		return PhoneNumber{}, ErrInvalidPhoneNumber
+	}
+
+	var number PhoneNumber
+	switch {
+	case strings.HasPrefix(trimmed, "+"):
+		number.CountryCode, number.National = splitCountryCode(digits)
+	case strings.HasPrefix(digits, "00"):
+		number.CountryCode, number.National = splitCountryCode(digits[2:])
+	default:
+		number.CountryCode = strings.TrimPrefix(strings.TrimSpace(defaultCountryCode), "+")
+		if number.CountryCode == "" {
+			number.CountryCode = DefaultCountryCode
+		}
+		if _, ok := callingCodes[number.CountryCode]; !ok {
+			return PhoneNumber{}, ErrUnknownCountryCode
+		}
+		number.National = strings.TrimPrefix(digits, "0")
+	}
+	if number.CountryCode == "" {
+		return PhoneNumber{}, ErrUnknownCountryCode
+	}
+	if err := number.Validate(); err != nil {
+		return PhoneNumber{}, err
+	}
+	return number, nil
+}
+
+func splitCountryCode(digits string) (string, string) {
+	for size := 1; size <= 3 && size < len(digits); size++ {
+		if _, ok := callingCodes[digits[:size]]; ok {
+			return digits[:size], digits[size:]
+		}
+	}
+	return "", ""
+}
+
+func (n PhoneNumber) Validate() error {
+	if _, ok := callingCodes[n.CountryCode]; !ok {
+		return ErrUnknownCountryCode
+	}
+	if len(n.National) < 4 || len(n.CountryCode)+len(n.National) > 15 {
+		return ErrInvalidPhoneNumber
+	}
+	for _, r := range n.National {
+		if r < '0' || r > '9' {
+			return ErrInvalidPhoneNumber
+		}
+	}
+	// Mainland China only accepts 11-digit mobile numbers for SMS.
+	if n.CountryCode == "86" && (len(n.National) != 11 || n.National[0] != '1') {
+		return ErrInvalidPhoneNumber
+	}
+	return nil
+}
+
+// E164 returns the number as "+<cc><national>".
+func (n PhoneNumber) E164() string {
+	return "+" + n.CountryCode + n.National
+}
+
+// Aliyun returns the number in the format expected by dysmsapi: national
+// digits for mainland China, "<cc><national>" for everything else.
+func (n PhoneNumber) Aliyun() string {
+	if n.CountryCode == DefaultCountryCode {
+		return n.National
+	}
+	return n.CountryCode + n.National
+}
+
+func (n PhoneNumber) String() string {
+	return n.E164()
+}
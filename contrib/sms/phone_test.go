@@ -0,0 +1,50 @@
+package sms
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePhoneNumber(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		defaultCode string
+		wantE164    string
+		wantAliyun  string
+		wantErr     error
+	}{
+		{name: "domestic bare", raw: " 138-0000-0000 ", wantE164: "+8613800000000", wantAliyun: "13800000000"},
+		{name: "domestic e164", raw: "+86 138 0000 0000", wantE164: "+8613800000000", wantAliyun: "13800000000"},
+		{name: "hong kong", raw: "+852 6123 4567", wantE164: "+85261234567", wantAliyun: "85261234567"},
+		{name: "international prefix", raw: "001 (415) 555-2671", wantE164: "+14155552671", wantAliyun: "14155552671"},
+		{name: "default country trunk prefix", raw: "07911 123456", defaultCode: "+44", wantE164: "+447911123456", wantAliyun: "447911123456"},
+		{name: "invalid domestic", raw: "+86 2345", wantErr: ErrInvalidPhoneNumber},
+		{name: "unknown country", raw: "+999 1234567", wantErr: ErrUnknownCountryCode},
+		{name: "unknown default country", raw: "1234567", defaultCode: "999", wantErr: ErrUnknownCountryCode},
+		{name: "letters", raw: "+1 415 CALL NOW", wantErr: ErrInvalidPhoneNumber},
+		{name: "too long", raw: "+1 4155552671 12345", wantErr: ErrInvalidPhoneNumber},
+		{name: "empty", raw: " ", wantErr: ErrInvalidPhoneNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number, err := ParsePhoneNumber(tt.raw, tt.defaultCode)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParsePhoneNumber() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePhoneNumber() error = %v", err)
+			}
+			if got := number.E164(); got != tt.wantE164 {
+				t.Fatalf("E164() = %q, want %q", got, tt.wantE164)
+			}
+			if got := number.Aliyun(); got != tt.wantAliyun {
+				t.Fatalf("Aliyun() = %q, want %q", got, tt.wantAliyun)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bang-go/util"
+)
+
+var (
+	ErrRouterConfigRequired = errors.New("sms: router config is required")
+	ErrProviderRequired     = errors.New("sms: provider is required")
+	ErrProviderNotFound     = errors.New("sms: no provider for country")
+	ErrMessageRequired      = errors.New("sms: message is required")
+	ErrTemplateNotFound     = errors.New("sms: template not found")
+)
+
+// Message is a provider-neutral SMS with a resolved destination and template.
+type Message struct {
+	PhoneNumber   PhoneNumber
+	SignName      string
+	TemplateCode  string
+	TemplateParam string
+	OutID         string
+}
+
+type SendResult struct {
+	Provider  string
+	MessageID string
+}
+
+type Provider interface {
+	Name() string
+	Send(context.Context, *Message) (*SendResult, error)
+}
+
+type TemplateRoute struct {
+	SignName     string
+	TemplateCode string
+}
+
+// Template maps a logical template to provider templates. Countries takes
+// precedence, then Domestic for the router's domestic country code, then International.
+type Template struct {
+	Domestic      TemplateRoute
+	International TemplateRoute
+	Countries     map[string]TemplateRoute
+}
+
+type RouterConfig struct {
+	// DomesticCountryCode defaults to DefaultCountryCode and is also used for numbers without a country code.
+	DomesticCountryCode string
+	Domestic            Provider
+	International       Provider
+	// Providers overrides the gateway for specific country calling codes, e.g. "852".
+	Providers map[string]Provider
+	Templates map[string]Template
+}
+
+type SendRequest struct {
+	PhoneNumber   string
+	Template      string
+	TemplateParam string
+	OutID         string
+}
+
+type Router struct {
+	config *RouterConfig
+}
+
+func NewRouter(conf *RouterConfig) (*Router, error) {
+	if conf == nil {
+		return nil, ErrRouterConfigRequired
+	}
+
+	cloned := *conf
+	cloned.DomesticCountryCode = strings.TrimPrefix(strings.TrimSpace(cloned.DomesticCountryCode), "+")
+	if cloned.DomesticCountryCode == "" {
+		cloned.DomesticCountryCode = DefaultCountryCode
+	}
+	if _, ok := callingCodes[cloned.DomesticCountryCode]; !ok {
+		return nil, ErrUnknownCountryCode
+	}
+	if cloned.Domestic == nil && cloned.International == nil && len(cloned.Providers) == 0 {
+		return nil, ErrProviderRequired
+	}
+
+	cloned.Providers = make(map[string]Provider, len(conf.Providers))
+	for code, provider := range conf.Providers {
+		code = strings.TrimPrefix(strings.TrimSpace(code), "+")
+		if _, ok := callingCodes[code]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownCountryCode, code)
+		}
+		if provider == nil {
+			return nil, fmt.Errorf("%w: country %s", ErrProviderRequired, code)
+		}
+		cloned.Providers[code] = provider
+	}
+
+	cloned.Templates = make(map[string]Template, len(conf.Templates))
+	for name, template := range conf.Templates {
+		countries := make(map[string]TemplateRoute, len(template.Countries))
+		for code, route := range template.Countries {
+			countries[strings.TrimPrefix(strings.TrimSpace(code), "+")] = route
+		}
+		template.Countries = countries
+		cloned.Templates[strings.TrimSpace(name)] = template
+	}
+
+	return &Router{config: &cloned}, nil
+}
+
+// Provider returns the gateway that serves the given country calling code.
+func (r *Router) Provider(countryCode string) (Provider, error) {
+	if provider, ok := r.config.Providers[countryCode]; ok {
+		return provider, nil
+	}
+	provider := r.config.International
+	if countryCode == r.config.DomesticCountryCode {
+		provider = r.config.Domestic
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("%w: +%s", ErrProviderNotFound, countryCode)
+	}
+	return provider, nil
+}
+
+// Resolve parses the destination and picks the template route without sending.
+func (r *Router) Resolve(request *SendRequest) (*Message, Provider, error) {
+	if request == nil {
+		return nil, nil, ErrMessageRequired
+	}
+
+	phone, err := ParsePhoneNumber(request.PhoneNumber, r.config.DomesticCountryCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := strings.TrimSpace(request.Template)
+	template, ok := r.config.Templates[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %q", ErrTemplateNotFound, name)
+	}
+	route, ok := template.Countries[phone.CountryCode]
+	if !ok {
+		route = template.International
+		if phone.CountryCode == r.config.DomesticCountryCode {
+			route = template.Domestic
+		}
+	}
+	route.SignName = strings.TrimSpace(route.SignName)
+	route.TemplateCode = strings.TrimSpace(route.TemplateCode)
+	if route.TemplateCode == "" {
+		return nil, nil, fmt.Errorf("%w: %q for +%s", ErrTemplateNotFound, name, phone.CountryCode)
+	}
+
+	provider, err := r.Provider(phone.CountryCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Message{
+		PhoneNumber:   phone,
+		SignName:      route.SignName,
+		TemplateCode:  route.TemplateCode,
+		TemplateParam: strings.TrimSpace(request.TemplateParam),
+		OutID:         strings.TrimSpace(request.OutID),
+	}, provider, nil
+}
+
+func (r *Router) Send(ctx context.Context, request *SendRequest) (*SendResult, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+
+	message, provider, err := r.Resolve(request)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Send(ctx, message)
+}
+
+type aliyunProvider struct {
+	name   string
+	client Client
+}
+
+// NewAliyunProvider adapts a Client to Provider. Numbers are sent in the
+// dysmsapi format, so the same client can serve domestic and international routes.
+func NewAliyunProvider(name string, client Client) (Provider, error) {
+	if client == nil {
+		return nil, ErrProviderRequired
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "aliyun"
+	}
+	return &aliyunProvider{name: name, client: client}, nil
+}
+
+func (p *aliyunProvider) Name() string {
+	return p.name
+}
+
+func (p *aliyunProvider) Send(ctx context.Context, message *Message) (*SendResult, error) {
+	if message == nil {
+		return nil, ErrMessageRequired
+	}
+
+	request := &SendSmsRequest{
+		PhoneNumbers: util.Ptr(message.PhoneNumber.Aliyun()),
+		SignName:     util.Ptr(message.SignName),
+		TemplateCode: util.Ptr(message.TemplateCode),
+	}
+	if message.TemplateParam != "" {
+		request.TemplateParam = util.Ptr(message.TemplateParam)
+	}
+	if message.OutID != "" {
+		request.OutId = util.Ptr(message.OutID)
+	}
+
+	resp, err := p.client.SendSms(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SendResult{Provider: p.name}
+	if resp != nil && resp.Body != nil {
+		if code := util.DerefZero(resp.Body.Code); code != "" && !strings.EqualFold(code, "OK") {
+			return nil, fmt.Errorf("sms: %s send failed: %s %s", p.name, code, util.DerefZero(resp.Body.Message))
+		}
+		result.MessageID = util.DerefZero(resp.Body.BizId)
+	}
+	return result, nil
+}
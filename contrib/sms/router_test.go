@@ -0,0 +1,139 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/models"
+)
+
+type fakeProvider struct {
+	name     string
+	messages []*Message
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) Send(_ context.Context, message *Message) (*SendResult, error) {
+	p.messages = append(p.messages, message)
+	return &SendResult{Provider: p.name, MessageID: message.PhoneNumber.E164()}, nil
+}
+
+func TestRouterRoutesByCountry(t *testing.T) {
+	domestic := &fakeProvider{name: "domestic"}
+	international := &fakeProvider{name: "international"}
+	hongKong := &fakeProvider{name: "hk"}
+
+	router, err := NewRouter(&RouterConfig{
+		Domestic:      domestic,
+		International: international,
+		Providers:     map[string]Provider{"+852": hongKong},
+		Templates: map[string]Template{
+			"login": {
+				Domestic:      TemplateRoute{SignName: "Bang", TemplateCode: "SMS_CN"},
+				International: TemplateRoute{SignName: "Bang", TemplateCode: "SMS_INTL"},
+				Countries:     map[string]TemplateRoute{"852": {SignName: "Bang HK", TemplateCode: "SMS_HK"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	tests := []struct {
+		phone        string
+		wantProvider string
+		wantTemplate string
+	}{
+		{phone: "13800000000", wantProvider: "domestic", wantTemplate: "SMS_CN"},
+		{phone: "+14155552671", wantProvider: "international", wantTemplate: "SMS_INTL"},
+		{phone: "+85261234567", wantProvider: "hk", wantTemplate: "SMS_HK"},
+	}
+	for _, tt := range tests {
+		result, err := router.Send(context.Background(), &SendRequest{PhoneNumber: tt.phone, Template: "login", TemplateParam: `{"code":"1234"}`})
+		if err != nil {
+			t.Fatalf("Send(%s) error = %v", tt.phone, err)
+		}
+		if result.Provider != tt.wantProvider {
+			t.Fatalf("Send(%s) provider = %q, want %q", tt.phone, result.Provider, tt.wantProvider)
+		}
+	}
+
+	if got := hongKong.messages[0]; got.TemplateCode != "SMS_HK" || got.SignName != "Bang HK" {
+		t.Fatalf("unexpected hk message: %+v", got)
+	}
+	if got := international.messages[0]; got.TemplateCode != "SMS_INTL" || got.TemplateParam != `{"code":"1234"}` {
+		t.Fatalf("unexpected international message: %+v", got)
+	}
+}
+
+func TestRouterErrors(t *testing.T) {
+	if _, err := NewRouter(nil); !errors.Is(err, ErrRouterConfigRequired) {
+		t.Fatalf("NewRouter(nil) error = %v", err)
+	}
+	if _, err := NewRouter(&RouterConfig{}); !errors.Is(err, ErrProviderRequired) {
+		t.Fatalf("NewRouter(empty) error = %v", err)
+	}
+	if _, err := NewRouter(&RouterConfig{Providers: map[string]Provider{"999": &fakeProvider{}}}); !errors.Is(err, ErrUnknownCountryCode) {
+		t.Fatalf("NewRouter(bad code) error = %v", err)
+	}
+
+	router, err := NewRouter(&RouterConfig{
+		Domestic: &fakeProvider{name: "domestic"},
+		Templates: map[string]Template{
+			"login": {Domestic: TemplateRoute{SignName: "Bang", TemplateCode: "SMS_CN"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	var nilCtx context.Context
+	if _, err := router.Send(nilCtx, &SendRequest{}); !errors.Is(err, ErrContextRequired) {
+		t.Fatalf("Send(nil ctx) error = %v", err)
+	}
+	if _, err := router.Send(context.Background(), &SendRequest{PhoneNumber: "13800000000", Template: "missing"}); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("Send(missing template) error = %v", err)
+	}
+	if _, err := router.Send(context.Background(), &SendRequest{PhoneNumber: "+14155552671", Template: "login"}); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("Send(no international template) error = %v", err)
+	}
+
+	router.config.Templates["login"] = Template{International: TemplateRoute{TemplateCode: "SMS_INTL"}, Domestic: TemplateRoute{TemplateCode: "SMS_CN"}}
+	if _, err := router.Send(context.Background(), &SendRequest{PhoneNumber: "+14155552671", Template: "login"}); !errors.Is(err, ErrProviderNotFound) {
+		t.Fatalf("Send(no international provider) error = %v", err)
+	}
+}
+
+func TestAliyunProviderFormatsNumbers(t *testing.T) {
+	fakeAPI := &fakeSMSAPI{}
+	client, err := New(&Config{
+		AccessKeyID:     "ak",
+		AccessKeySecret: "sk",
+		newClient: func(*openapi.Config) (smsAPI, error) {
+			return fakeAPI, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	provider, err := NewAliyunProvider("", client)
+	if err != nil {
+		t.Fatalf("NewAliyunProvider() error = %v", err)
+	}
+	if provider.Name() != "aliyun" {
+		t.Fatalf("Name() = %q", provider.Name())
+	}
+
+	phone, _ := ParsePhoneNumber("+85261234567", "")
+	if _, err := provider.Send(context.Background(), &Message{PhoneNumber: phone, SignName: "Bang", TemplateCode: "SMS_HK"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if fakeAPI.sendSMS.phone != "85261234567" || fakeAPI.sendSMS.template != "SMS_HK" {
+		t.Fatalf("unexpected request: %+v", fakeAPI.sendSMS)
+	}
+}
@@ -0,0 +1,273 @@
+// Package breaker is the circuit breaker state machine behind the httpx
+// client and grpcx client breakers, which key circuits by host and by method.
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultWindow           = 10 * time.Second
+	defaultBuckets          = 10
+	defaultMinRequests      = 20
+	defaultErrorRate        = 0.5
+	defaultOpenTimeout      = 5 * time.Second
+	defaultHalfOpenRequests = 1
+)
+
+type State int
+
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half_open"
+	case Open:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// Config trips a circuit when its error rate over the rolling Window reaches
+// ErrorRate with at least MinRequests results, or after ConsecutiveFailures
+// failures in a row when positive. After OpenTimeout the circuit lets
+// HalfOpenRequests probes through; it closes when they all succeed and opens
+// again on the first failure. Zero values take the defaults.
+type Config struct {
+	Window              time.Duration
+	Buckets             int
+	MinRequests         int
+	ErrorRate           float64
+	ConsecutiveFailures int
+	OpenTimeout         time.Duration
+	HalfOpenRequests    int
+}
+
+// Group keeps one circuit per key. OnCreate and OnChange are called outside
+// the lock, e.g. to update metrics.
+type Group struct {
+	conf     Config
+	onCreate func(key string)
+	onChange func(key string, from, to State)
+	now      func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+func NewGroup(conf Config, onCreate func(key string), onChange func(key string, from, to State)) *Group {
+	if conf.Window <= 0 {
+		conf.Window = defaultWindow
+	}
+	if conf.Buckets <= 0 {
+		conf.Buckets = defaultBuckets
+	}
+	if conf.MinRequests <= 0 {
+		conf.MinRequests = defaultMinRequests
+	}
+	if conf.ErrorRate <= 0 || conf.ErrorRate > 1 {
+		conf.ErrorRate = defaultErrorRate
+	}
+	if conf.OpenTimeout <= 0 {
+		conf.OpenTimeout = defaultOpenTimeout
+	}
+	if conf.HalfOpenRequests <= 0 {
+		conf.HalfOpenRequests = defaultHalfOpenRequests
+	}
+	return &Group{conf: conf, onCreate: onCreate, onChange: onChange, now: time.Now, circuits: make(map[string]*circuit)}
+}
+
+// State reports the current state of key's circuit.
+func (g *Group) State(key string) State {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, ok := g.circuits[key]
+	if !ok {
+		return Closed
+	}
+	state, _ := c.current(g.now())
+	return state
+}
+
+// Allow admits a call and returns the circuit generation to report its result
+// against, or false when the call is rejected.
+func (g *Group) Allow(key string) (uint64, bool) {
+	g.mu.Lock()
+	c, created := g.circuits[key]
+	created = !created
+	if created {
+		c = newCircuit(g.conf)
+		g.circuits[key] = c
+	}
+	from := c.state
+	generation, allowed := c.allow(g.now())
+	to := c.state
+	g.mu.Unlock()
+
+	if created && g.onCreate != nil {
+		g.onCreate(key)
+	}
+	g.transition(key, from, to)
+	return generation, allowed
+}
+
+// Done reports the result of a call admitted by Allow.
+func (g *Group) Done(key string, generation uint64, failed bool) {
+	g.mu.Lock()
+	c := g.circuits[key]
+	from := c.state
+	c.record(g.now(), generation, failed)
+	to := c.state
+	g.mu.Unlock()
+
+	g.transition(key, from, to)
+}
+
+// Release frees the probe slot of a call that reports no result.
+func (g *Group) Release(key string, generation uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c := g.circuits[key]; c.state == HalfOpen && c.generation == generation {
+		c.probes--
+	}
+}
+
+func (g *Group) transition(key string, from, to State) {
+	if from != to && g.onChange != nil {
+		g.onChange(key, from, to)
+	}
+}
+
+type bucket struct {
+	start    time.Time
+	total    int
+	failures int
+}
+
+type circuit struct {
+	conf Config
+
+	state       State
+	generation  uint64
+	openedAt    time.Time
+	buckets     []bucket
+	consecutive int
+	probes      int
+	successes   int
+}
+
+func newCircuit(conf Config) *circuit {
+	return &circuit{conf: conf, buckets: make([]bucket, conf.Buckets)}
+}
+
+// current moves an open circuit to half-open once OpenTimeout has passed, and
+// frees the probe slots of a half-open circuit whose probes never reported.
+func (c *circuit) current(now time.Time) (State, bool) {
+	switch c.state {
+	case Open:
+		if now.Sub(c.openedAt) < c.conf.OpenTimeout {
+			return c.state, false
+		}
+		c.setState(HalfOpen, now)
+	case HalfOpen:
+		if now.Sub(c.openedAt) >= c.conf.OpenTimeout {
+			c.setState(HalfOpen, now)
+		}
+	}
+	return c.state, true
+}
+
+func (c *circuit) allow(now time.Time) (uint64, bool) {
+	state, ok := c.current(now)
+	if !ok {
+		return 0, false
+	}
+	if state == HalfOpen {
+		if c.probes >= c.conf.HalfOpenRequests {
+			return 0, false
+		}
+		c.probes++
+	}
+	return c.generation, true
+}
+
+func (c *circuit) record(now time.Time, generation uint64, failed bool) {
+	// Results of calls admitted before the last transition are stale.
+	if generation != c.generation {
+		return
+	}
+	switch c.state {
+	case HalfOpen:
+		if failed {
+			c.setState(Open, now)
+			return
+		}
+		c.successes++
+		if c.successes >= c.conf.HalfOpenRequests {
+			c.setState(Closed, now)
+		}
+	case Closed:
+		bucket := c.bucket(now)
+		bucket.total++
+		if !failed {
+			c.consecutive = 0
+			return
+		}
+		bucket.failures++
+		c.consecutive++
+		if c.conf.ConsecutiveFailures > 0 && c.consecutive >= c.conf.ConsecutiveFailures {
+			c.setState(Open, now)
+			return
+		}
+		total, failures := c.totals(now)
+		if total >= c.conf.MinRequests && float64(failures)/float64(total) >= c.conf.ErrorRate {
+			c.setState(Open, now)
+		}
+	}
+}
+
+func (c *circuit) setState(state State, now time.Time) {
+	c.state = state
+	c.generation++
+	c.openedAt = now
+	c.probes = 0
+	c.successes = 0
+	c.consecutive = 0
+	if state == Closed {
+		clear(c.buckets)
+	}
+}
+
+func (c *circuit) bucketWidth() time.Duration {
+	return c.conf.Window / time.Duration(len(c.buckets))
+}
+
+func (c *circuit) bucket(now time.Time) *bucket {
+	width := c.bucketWidth()
+	start := now.Truncate(width)
+	b := &c.buckets[int(start.UnixNano()/int64(width))%len(c.buckets)]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	return b
+}
+
+func (c *circuit) totals(now time.Time) (total, failures int) {
+	oldest := now.Truncate(c.bucketWidth()).Add(-c.conf.Window)
+	for _, b := range c.buckets {
+		if b.start.After(oldest) {
+			total += b.total
+			failures += b.failures
+		}
+	}
+	return total, failures
+}
@@ -0,0 +1,226 @@
+// Package tlsutil loads certificates and CA pools from files or PEM and
+// reloads them as the files change, for the transport packages' TLS configs.
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server mirrors the fields of the transports' ServerTLSConfig, which
+// convert to it directly.
+type Server struct {
+	CertFile       string
+	KeyFile        string
+	CertPEM        []byte
+	KeyPEM         []byte
+	Certificates   []tls.Certificate
+	ClientCAFile   string
+	ClientCAPEM    []byte
+	ClientCAs      *x509.CertPool
+	ClientAuth     tls.ClientAuthType
+	MinVersion     uint16
+	ReloadInterval time.Duration
+}
+
+// Loader reports failures with the calling package's prefix and sentinel
+// errors, so each transport keeps its own error values.
+type Loader struct {
+	Prefix                 string
+	ErrCertificateRequired error
+	ErrKeyPairIncomplete   error
+	ErrInvalidCA           error
+}
+
+// Server returns a tls.Config for c offering nextProtos through ALPN; with
+// ReloadInterval set it serves the latest credentials through
+// GetConfigForClient.
+func (l Loader) Server(c *Server, nextProtos []string) (*tls.Config, error) {
+	if c == nil {
+		return nil, l.ErrCertificateRequired
+	}
+	build := func() (*tls.Config, error) {
+		certificates := append([]tls.Certificate(nil), c.Certificates...)
+		certificate, ok, err := l.KeyPair(c.CertFile, c.KeyFile, c.CertPEM, c.KeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			certificates = append(certificates, certificate)
+		}
+		if len(certificates) == 0 {
+			return nil, l.ErrCertificateRequired
+		}
+		clientCAs, err := l.CertPool(c.ClientCAs, c.ClientCAFile, c.ClientCAPEM)
+		if err != nil {
+			return nil, err
+		}
+		clientAuth := c.ClientAuth
+		if clientAuth == tls.NoClientCert && clientCAs != nil {
+			clientAuth = tls.RequireAndVerifyClientCert
+		}
+		return &tls.Config{
+			Certificates: certificates,
+			ClientCAs:    clientCAs,
+			ClientAuth:   clientAuth,
+			MinVersion:   MinVersion(c.MinVersion),
+			// Configs returned from GetConfigForClient get no ALPN from the
+			// server they are used by, so it is always set here.
+			NextProtos: append([]string(nil), nextProtos...),
+		}, nil
+	}
+
+	config, err := build()
+	if err != nil {
+		return nil, err
+	}
+	if c.ReloadInterval <= 0 {
+		return config, nil
+	}
+	r := NewReloader(c.ReloadInterval, build, config, c.CertFile, c.KeyFile, c.ClientCAFile)
+	return &tls.Config{
+		MinVersion: config.MinVersion,
+		NextProtos: config.NextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.Get(), nil
+		},
+	}, nil
+}
+
+// KeyPair loads a certificate from files or PEM, reporting false when
+// neither is set.
+func (l Loader) KeyPair(certFile, keyFile string, certPEM, keyPEM []byte) (tls.Certificate, bool, error) {
+	certFile, keyFile = strings.TrimSpace(certFile), strings.TrimSpace(keyFile)
+	switch {
+	case certFile != "" || keyFile != "":
+		if certFile == "" || keyFile == "" {
+			return tls.Certificate{}, false, l.ErrKeyPairIncomplete
+		}
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return tls.Certificate{}, false, fmt.Errorf("%s: load tls key pair: %w", l.Prefix, err)
+		}
+		return certificate, true, nil
+	case len(certPEM) > 0 || len(keyPEM) > 0:
+		if len(certPEM) == 0 || len(keyPEM) == 0 {
+			return tls.Certificate{}, false, l.ErrKeyPairIncomplete
+		}
+		certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return tls.Certificate{}, false, fmt.Errorf("%s: parse tls key pair: %w", l.Prefix, err)
+		}
+		return certificate, true, nil
+	}
+	return tls.Certificate{}, false, nil
+}
+
+// CertPool adds the CA file and PEM to a copy of pool; it returns pool
+// unchanged, possibly nil, when neither is set.
+func (l Loader) CertPool(pool *x509.CertPool, file string, pem []byte) (*x509.CertPool, error) {
+	var data [][]byte
+	if file = strings.TrimSpace(file); file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: read tls ca: %w", l.Prefix, err)
+		}
+		data = append(data, content)
+	}
+	if len(pem) > 0 {
+		data = append(data, pem)
+	}
+	if len(data) == 0 {
+		return pool, nil
+	}
+	if pool == nil {
+		pool = x509.NewCertPool()
+	} else {
+		pool = pool.Clone()
+	}
+	for _, content := range data {
+		if !pool.AppendCertsFromPEM(content) {
+			return nil, l.ErrInvalidCA
+		}
+	}
+	return pool, nil
+}
+
+// MinVersion defaults version to TLS 1.2.
+func MinVersion(version uint16) uint16 {
+	if version == 0 {
+		return tls.VersionTLS12
+	}
+	return version
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// Reloader rebuilds a tls.Config when any of its files change, polling
+// lazily from handshakes so it needs no goroutine or Close.
+type Reloader struct {
+	interval time.Duration
+	build    func() (*tls.Config, error)
+	files    []string
+
+	mu      sync.Mutex
+	current *tls.Config
+	stamps  []fileStamp
+	checked time.Time
+}
+
+func NewReloader(interval time.Duration, build func() (*tls.Config, error), current *tls.Config, files ...string) *Reloader {
+	r := &Reloader{interval: interval, build: build, current: current, checked: time.Now()}
+	for _, file := range files {
+		if file = strings.TrimSpace(file); file != "" {
+			r.files = append(r.files, file)
+		}
+	}
+	r.stamps = r.stat()
+	return r
+}
+
+// Get returns the current config, rebuilding it first when the interval has
+// passed and a file changed. A failed rebuild keeps the previous config.
+func (r *Reloader) Get() *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.files) == 0 || time.Since(r.checked) < r.interval {
+		return r.current
+	}
+	r.checked = time.Now()
+	stamps := r.stat()
+	changed := false
+	for i := range stamps {
+		if stamps[i].size != r.stamps[i].size || !stamps[i].modTime.Equal(r.stamps[i].modTime) {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return r.current
+	}
+	// A cert rewritten before its key fails to load; the stamps are kept so
+	// the next check retries.
+	if config, err := r.build(); err == nil {
+		r.current = config
+		r.stamps = stamps
+	}
+	return r.current
+}
+
+func (r *Reloader) stat() []fileStamp {
+	stamps := make([]fileStamp, len(r.files))
+	for i, file := range r.files {
+		if info, err := os.Stat(file); err == nil {
+			stamps[i] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		}
+	}
+	return stamps
+}
@@ -0,0 +1,95 @@
+// Package budget caps downstream call timeouts to a share of the caller's
+// remaining deadline, so a call made while serving a request never outlives
+// the request.
+package budget
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultFraction = 0.8
+	DefaultMin      = 10 * time.Millisecond
+)
+
+// Policy is attached to a request context by the server, typically the grpcx
+// budget middleware, and read by httpx, gormx and redisx for each outbound
+// call.
+type Policy struct {
+	// Fraction of the remaining time one downstream call may use, default
+	// 0.8. The rest is left for the caller to handle the result or a failure.
+	Fraction float64
+	// Min floors the capped timeout, default 10ms, so a nearly spent budget
+	// still allows one quick attempt. The call still ends at the caller's
+	// deadline.
+	Min time.Duration
+}
+
+type policyKey struct{}
+
+// WithPolicy attaches p to ctx. Zero fields take their defaults.
+func WithPolicy(ctx context.Context, p Policy) context.Context {
+	if p.Fraction <= 0 || p.Fraction > 1 {
+		p.Fraction = DefaultFraction
+	}
+	if p.Min <= 0 {
+		p.Min = DefaultMin
+	}
+	return context.WithValue(ctx, policyKey{}, p)
+}
+
+// FromContext returns the policy attached to ctx.
+func FromContext(ctx context.Context) (Policy, bool) {
+	if ctx == nil {
+		return Policy{}, false
+	}
+	p, ok := ctx.Value(policyKey{}).(Policy)
+	return p, ok
+}
+
+// Remaining returns the time left before ctx's deadline, or false when ctx
+// has none.
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// Timeout returns the timeout for a downstream call configured with timeout
+// (0 meaning none). With a policy and a deadline on ctx it is the smaller of
+// timeout and the policy's share of the remaining time; otherwise timeout is
+// returned unchanged.
+func Timeout(ctx context.Context, timeout time.Duration) time.Duration {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return timeout
+	}
+	remaining, ok := Remaining(ctx)
+	if !ok {
+		return timeout
+	}
+	capped := max(time.Duration(float64(remaining)*p.Fraction), p.Min)
+	if timeout > 0 && timeout < capped {
+		return timeout
+	}
+	return capped
+}
+
+// Context derives the context for a downstream call. It returns ctx and a
+// no-op cancel when there is no budget to apply, so callers can use it on
+// every call.
+func Context(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := FromContext(ctx); !ok {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, Timeout(ctx, timeout))
+}
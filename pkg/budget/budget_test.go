@@ -0,0 +1,74 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeoutWithoutPolicyOrDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if got := Timeout(ctx, 5*time.Second); got != 5*time.Second {
+		t.Fatalf("Timeout(no policy) = %v, want configured 5s", got)
+	}
+	if got := Timeout(WithPolicy(context.Background(), Policy{}), 5*time.Second); got != 5*time.Second {
+		t.Fatalf("Timeout(no deadline) = %v, want configured 5s", got)
+	}
+
+	derived, cancelDerived := Context(ctx, 5*time.Second)
+	defer cancelDerived()
+	if derived != ctx {
+		t.Fatal("Context(no policy) should return ctx unchanged")
+	}
+}
+
+func TestTimeoutCapsToRemainingBudget(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ctx := WithPolicy(parent, Policy{Fraction: 0.5, Min: 50 * time.Millisecond})
+
+	got := Timeout(ctx, 5*time.Second)
+	if got > 500*time.Millisecond || got < 450*time.Millisecond {
+		t.Fatalf("Timeout() = %v, want about half of 1s", got)
+	}
+	if got := Timeout(ctx, 100*time.Millisecond); got != 100*time.Millisecond {
+		t.Fatalf("Timeout(shorter configured) = %v, want 100ms", got)
+	}
+	if got := Timeout(ctx, 0); got > 500*time.Millisecond || got < 450*time.Millisecond {
+		t.Fatalf("Timeout(unconfigured) = %v, want about half of 1s", got)
+	}
+
+	derived, cancelDerived := Context(ctx, 0)
+	defer cancelDerived()
+	deadline, ok := derived.Deadline()
+	if !ok || time.Until(deadline) > 500*time.Millisecond {
+		t.Fatalf("Context() deadline in %v, want within the 500ms share", time.Until(deadline))
+	}
+}
+
+func TestTimeoutFloorStaysWithinCallerDeadline(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	ctx := WithPolicy(parent, Policy{Fraction: 0.1, Min: time.Second})
+
+	if got := Timeout(ctx, 0); got != time.Second {
+		t.Fatalf("Timeout() = %v, want the 1s floor", got)
+	}
+	derived, cancelDerived := Context(ctx, 0)
+	defer cancelDerived()
+	parentDeadline, _ := parent.Deadline()
+	if deadline, _ := derived.Deadline(); deadline.After(parentDeadline) {
+		t.Fatalf("derived deadline %v outlives caller deadline %v", deadline, parentDeadline)
+	}
+}
+
+func TestWithPolicyDefaults(t *testing.T) {
+	p, ok := FromContext(WithPolicy(context.Background(), Policy{Fraction: 2}))
+	if !ok || p.Fraction != DefaultFraction || p.Min != DefaultMin {
+		t.Fatalf("FromContext() = %+v, %v, want defaults", p, ok)
+	}
+	if _, ok := FromContext(nil); ok {
+		t.Fatal("FromContext(nil) should report no policy")
+	}
+}
@@ -0,0 +1,112 @@
+// Package bytesx provides a size-classed buffer pool and zero-copy
+// conversions for hot transport paths.
+package bytesx
+
+import (
+	"math/bits"
+	"sync"
+	"unsafe"
+)
+
+const (
+	defaultMinSize = 64
+	defaultMaxSize = 64 << 10
+)
+
+var defaultPool = NewPool(defaultMinSize, defaultMaxSize)
+
+// Pool hands out byte slices from power-of-two size classes, so buffers of
+// different sizes do not evict each other the way one sync.Pool would.
+type Pool struct {
+	minShift int
+	maxSize  int
+	classes  []sync.Pool
+}
+
+// NewPool returns a Pool with classes from minSize up to maxSize, both
+// rounded up to a power of two. Requests above maxSize are allocated and not
+// pooled.
+func NewPool(minSize, maxSize int) *Pool {
+	if minSize <= 0 {
+		minSize = defaultMinSize
+	}
+	if maxSize < minSize {
+		maxSize = minSize
+	}
+	minShift := bits.Len(uint(minSize - 1))
+	maxShift := bits.Len(uint(maxSize - 1))
+	return &Pool{
+		minShift: minShift,
+		maxSize:  1 << maxShift,
+		classes:  make([]sync.Pool, maxShift-minShift+1),
+	}
+}
+
+// Get returns a slice of length n. Its capacity may be larger; Put it back
+// once nothing references it anymore.
+func (p *Pool) Get(n int) *[]byte {
+	if n < 0 {
+		n = 0
+	}
+	if n > p.maxSize {
+		buffer := make([]byte, n)
+		return &buffer
+	}
+	class := p.class(n)
+	if buffer, ok := p.classes[class].Get().(*[]byte); ok {
+		*buffer = (*buffer)[:n]
+		return buffer
+	}
+	buffer := make([]byte, n, 1<<(class+p.minShift))
+	return &buffer
+}
+
+// Put returns a buffer obtained from Get. Buffers whose capacity is not one
+// of the pool's classes are dropped, so callers may Put any slice.
+func (p *Pool) Put(buffer *[]byte) {
+	if buffer == nil {
+		return
+	}
+	size := cap(*buffer)
+	if size < 1<<p.minShift || size > p.maxSize || size&(size-1) != 0 {
+		return
+	}
+	*buffer = (*buffer)[:0]
+	p.classes[bits.Len(uint(size-1))-p.minShift].Put(buffer)
+}
+
+func (p *Pool) class(n int) int {
+	if n <= 1<<p.minShift {
+		return 0
+	}
+	return bits.Len(uint(n-1)) - p.minShift
+}
+
+// Get returns a buffer of length n from the shared pool, which has classes
+// from 64 B to 64 KiB.
+func Get(n int) *[]byte {
+	return defaultPool.Get(n)
+}
+
+// Put returns a buffer to the shared pool.
+func Put(buffer *[]byte) {
+	defaultPool.Put(buffer)
+}
+
+// String returns b as a string without copying. b must not be modified while
+// the string is in use.
+func String(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// Bytes returns the bytes of s without copying. The result must not be
+// modified.
+func Bytes(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
@@ -0,0 +1,74 @@
+package bytesx
+
+import (
+	"strconv"
+	"testing"
+)
+
+var (
+	sink       []byte
+	sinkString string
+)
+
+// The transports copy each message into a fresh slice; the pool variants
+// show the allocations saved at high message rates.
+func BenchmarkCopyMessage(b *testing.B) {
+	for _, size := range []int{512, 1400, 16 << 10} {
+		message := make([]byte, size)
+		b.Run("make/"+sizeName(size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(size))
+			for b.Loop() {
+				buffer := make([]byte, len(message))
+				copy(buffer, message)
+				sink = buffer
+			}
+		})
+		b.Run("pool/"+sizeName(size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(size))
+			for b.Loop() {
+				buffer := Get(len(message))
+				copy(*buffer, message)
+				sink = *buffer
+				Put(buffer)
+			}
+		})
+	}
+}
+
+func BenchmarkCopyMessageParallel(b *testing.B) {
+	message := make([]byte, 1400)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(message)))
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			buffer := Get(len(message))
+			copy(*buffer, message)
+			Put(buffer)
+		}
+	})
+}
+
+func BenchmarkString(b *testing.B) {
+	payload := []byte("user:42:session")
+	b.Run("copy", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			sinkString = string(payload)
+		}
+	})
+	b.Run("zero-copy", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			sinkString = String(payload)
+		}
+	})
+}
+
+func sizeName(size int) string {
+	if size >= 1<<10 && size%(1<<10) == 0 {
+		return strconv.Itoa(size>>10) + "KiB"
+	}
+	return strconv.Itoa(size) + "B"
+}
@@ -0,0 +1,68 @@
+package bytesx
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestPoolSizeClasses(t *testing.T) {
+	p := NewPool(100, 5000)
+
+	tests := []struct {
+		n       int
+		wantCap int
+	}{
+		{0, 128},
+		{1, 128},
+		{128, 128},
+		{129, 256},
+		{4096, 4096},
+		{8192, 8192},
+		{9000, 9000},
+	}
+	for _, tt := range tests {
+		buffer := p.Get(tt.n)
+		if len(*buffer) != tt.n || cap(*buffer) != tt.wantCap {
+			t.Fatalf("Get(%d) len=%d cap=%d, want len=%d cap=%d", tt.n, len(*buffer), cap(*buffer), tt.n, tt.wantCap)
+		}
+		p.Put(buffer)
+	}
+}
+
+func TestPoolReusesBuffers(t *testing.T) {
+	p := NewPool(64, 1024)
+	allocs := testing.AllocsPerRun(100, func() {
+		buffer := p.Get(700)
+		(*buffer)[0] = 1
+		p.Put(buffer)
+	})
+	if allocs > 0 {
+		t.Fatalf("Get/Put allocs = %v, want 0", allocs)
+	}
+}
+
+func TestPoolDropsForeignBuffers(t *testing.T) {
+	p := NewPool(64, 1024)
+	foreign := make([]byte, 100)
+	p.Put(&foreign)
+	p.Put(nil)
+
+	buffer := p.Get(100)
+	if cap(*buffer) != 128 {
+		t.Fatalf("Get(100) cap = %d, want 128", cap(*buffer))
+	}
+}
+
+func TestZeroCopyConversions(t *testing.T) {
+	b := []byte("payload")
+	if s := String(b); s != "payload" || unsafe.StringData(s) != unsafe.SliceData(b) {
+		t.Fatalf("String() = %q, want a view of b", s)
+	}
+	s := "frame"
+	if got := Bytes(s); string(got) != s || unsafe.SliceData(got) != unsafe.StringData(s) {
+		t.Fatalf("Bytes() = %q, want a view of s", got)
+	}
+	if String(nil) != "" || Bytes("") != nil {
+		t.Fatal("empty conversions should be empty")
+	}
+}
@@ -0,0 +1,209 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+)
+
+// Code is the transport-neutral error category. The values follow the gRPC
+// canonical codes so both gRPC statuses and HTTP responses map onto them.
+type Code string
+
+const (
+	CodeUnknown            Code = "UNKNOWN"
+	CodeCanceled           Code = "CANCELED"
+	CodeInvalidArgument    Code = "INVALID_ARGUMENT"
+	CodeDeadlineExceeded   Code = "DEADLINE_EXCEEDED"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeAlreadyExists      Code = "ALREADY_EXISTS"
+	CodePermissionDenied   Code = "PERMISSION_DENIED"
+	CodeResourceExhausted  Code = "RESOURCE_EXHAUSTED"
+	CodeFailedPrecondition Code = "FAILED_PRECONDITION"
+	CodeAborted            Code = "ABORTED"
+	CodeOutOfRange         Code = "OUT_OF_RANGE"
+	CodeUnimplemented      Code = "UNIMPLEMENTED"
+	CodeInternal           Code = "INTERNAL"
+	CodeUnavailable        Code = "UNAVAILABLE"
+	CodeDataLoss           Code = "DATA_LOSS"
+	CodeUnauthenticated    Code = "UNAUTHENTICATED"
+)
+
+var httpStatuses = map[Code]int{
+	CodeUnknown:            http.StatusInternalServerError,
+	CodeCanceled:           499,
+	CodeInvalidArgument:    http.StatusBadRequest,
+	CodeDeadlineExceeded:   http.StatusGatewayTimeout,
+	CodeNotFound:           http.StatusNotFound,
+	CodeAlreadyExists:      http.StatusConflict,
+	CodePermissionDenied:   http.StatusForbidden,
+	CodeResourceExhausted:  http.StatusTooManyRequests,
+	CodeFailedPrecondition: http.StatusBadRequest,
+	CodeAborted:            http.StatusConflict,
+	CodeOutOfRange:         http.StatusBadRequest,
+	CodeUnimplemented:      http.StatusNotImplemented,
+	CodeInternal:           http.StatusInternalServerError,
+	CodeUnavailable:        http.StatusServiceUnavailable,
+	CodeDataLoss:           http.StatusInternalServerError,
+	CodeUnauthenticated:    http.StatusUnauthorized,
+}
+
+// HTTPStatus returns the HTTP status conventionally used for c.
+func (c Code) HTTPStatus() int {
+	if status, ok := httpStatuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeFromHTTPStatus maps an HTTP response status onto a Code; 2xx statuses
+// map to "".
+func CodeFromHTTPStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodePermissionDenied
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeAborted
+	case http.StatusTooManyRequests:
+		return CodeResourceExhausted
+	case 499:
+		return CodeCanceled
+	case http.StatusNotImplemented:
+		return CodeUnimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeDeadlineExceeded
+	}
+	switch {
+	case status >= 200 && status < 300:
+		return ""
+	case status >= 400 && status < 500:
+		return CodeFailedPrecondition
+	case status >= 500:
+		return CodeInternal
+	}
+	return CodeUnknown
+}
+
+// FieldViolation describes one invalid request field.
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Error is a coded error shared by HTTP and gRPC clients and servers.
+type Error struct {
+	Code    Code
+	Message string
+	// Reason is a stable, machine readable identifier such as "USER_BANNED".
+	// Together with Domain it identifies the error more precisely than Code.
+	Reason   string
+	Domain   string
+	Metadata map[string]string
+	// Fields lists invalid request fields for CodeInvalidArgument errors.
+	Fields []FieldViolation
+
+	cause error
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func Newf(code Code, format string, args ...any) *Error {
+	return New(code, fmt.Sprintf(format, args...))
+}
+
+// Wrap returns a coded error keeping cause reachable through errors.Is / As.
+func Wrap(cause error, code Code, message string) *Error {
+	return &Error{Code: code, Message: message, cause: cause}
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(string(e.Code))
+	if e.Reason != "" {
+		b.WriteString(" (")
+		b.WriteString(e.Reason)
+		b.WriteString(")")
+	}
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+	if e.cause != nil {
+		b.WriteString(": ")
+		b.WriteString(e.cause.Error())
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches another *Error by Code and, when set on target, by Reason, so
+// sentinel values like New(CodeNotFound, "") can be used with errors.Is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code && (t.Reason == "" || e.Reason == t.Reason)
+}
+
+// WithReason returns a copy of e carrying reason, domain and metadata.
+func (e *Error) WithReason(reason, domain string, metadata map[string]string) *Error {
+	cloned := *e
+	cloned.Reason = reason
+	cloned.Domain = domain
+	cloned.Metadata = maps.Clone(metadata)
+	return &cloned
+}
+
+// WithFields returns a copy of e with field violations appended.
+func (e *Error) WithFields(fields ...FieldViolation) *Error {
+	cloned := *e
+	cloned.Fields = append(append([]FieldViolation(nil), e.Fields...), fields...)
+	return &cloned
+}
+
+// FromError returns the *Error in err's chain, or wraps err as CodeUnknown.
+// It returns nil for a nil err.
+func FromError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var coded *Error
+	if stderrors.As(err, &coded) {
+		return coded
+	}
+	return Wrap(err, CodeUnknown, "")
+}
+
+// CodeOf returns the code of err, "" for nil and CodeUnknown for errors
+// without one.
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	return FromError(err).Code
+}
+
+// ReasonOf returns the reason of the *Error in err's chain, if any.
+func ReasonOf(err error) string {
+	var coded *Error
+	if stderrors.As(err, &coded) {
+		return coded.Reason
+	}
+	return ""
+}
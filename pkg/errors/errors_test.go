@@ -0,0 +1,63 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bang-go/micro/pkg/errors"
+)
+
+var errUserBanned = errors.New(errors.CodePermissionDenied, "").WithReason("USER_BANNED", "", nil)
+
+func TestErrorMatchingAndUnwrap(t *testing.T) {
+	cause := stderrors.New("db down")
+	err := fmt.Errorf("load user: %w", errors.Wrap(cause, errors.CodeUnavailable, "user store unavailable"))
+
+	if !stderrors.Is(err, cause) {
+		t.Fatal("cause is not reachable")
+	}
+	if !stderrors.Is(err, errors.New(errors.CodeUnavailable, "")) {
+		t.Fatal("errors.Is by code failed")
+	}
+	if got := errors.CodeOf(err); got != errors.CodeUnavailable {
+		t.Fatalf("CodeOf() = %q", got)
+	}
+	if got := err.Error(); got != "load user: UNAVAILABLE: user store unavailable: db down" {
+		t.Fatalf("Error() = %q", got)
+	}
+
+	banned := errors.New(errors.CodePermissionDenied, "banned").WithReason("USER_BANNED", "user.example.com", map[string]string{"uid": "1"})
+	if !stderrors.Is(banned, errUserBanned) || stderrors.Is(errors.New(errors.CodePermissionDenied, ""), errUserBanned) {
+		t.Fatal("errors.Is by reason mismatch")
+	}
+	if errors.ReasonOf(banned) != "USER_BANNED" {
+		t.Fatalf("ReasonOf() = %q", errors.ReasonOf(banned))
+	}
+
+	if errors.CodeOf(nil) != "" || errors.FromError(nil) != nil || errors.CodeOf(cause) != errors.CodeUnknown {
+		t.Fatal("nil or plain error handling mismatch")
+	}
+}
+
+func TestHTTPStatusMapping(t *testing.T) {
+	for code, status := range map[errors.Code]int{
+		errors.CodeInvalidArgument:   http.StatusBadRequest,
+		errors.CodeUnauthenticated:   http.StatusUnauthorized,
+		errors.CodeNotFound:          http.StatusNotFound,
+		errors.CodeResourceExhausted: http.StatusTooManyRequests,
+		errors.CodeUnavailable:       http.StatusServiceUnavailable,
+		errors.CodeDeadlineExceeded:  http.StatusGatewayTimeout,
+	} {
+		if got := code.HTTPStatus(); got != status {
+			t.Fatalf("%s.HTTPStatus() = %d, want %d", code, got, status)
+		}
+		if got := errors.CodeFromHTTPStatus(status); got != code {
+			t.Fatalf("CodeFromHTTPStatus(%d) = %q, want %q", status, got, code)
+		}
+	}
+	if errors.CodeFromHTTPStatus(http.StatusOK) != "" || errors.CodeFromHTTPStatus(418) != errors.CodeFailedPrecondition || errors.CodeFromHTTPStatus(507) != errors.CodeInternal {
+		t.Fatal("fallback mapping mismatch")
+	}
+}
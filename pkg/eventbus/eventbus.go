@@ -0,0 +1,308 @@
+// Package eventbus is an in-process, topic-based pub/sub for fanning events
+// out to many live connections, e.g. gRPC server streams or WebSocket
+// sessions. Publishing never blocks: each subscription has a bounded queue
+// and a DropPolicy for when it is full.
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	defaultShards    = 16
+	defaultQueueSize = 64
+	unnamedBus       = "default"
+)
+
+var (
+	ErrBusClosed       = errors.New("eventbus: closed")
+	ErrTopicRequired   = errors.New("eventbus: topic is required")
+	ErrSlowSubscriber  = errors.New("eventbus: subscriber queue overflowed")
+	ErrContextRequired = errors.New("eventbus: context is required")
+)
+
+type Bus[T any] struct {
+	options *options
+	metrics *metrics
+	seed    maphash.Seed
+	shards  []*shard[T]
+	closed  atomic.Bool
+}
+
+type shard[T any] struct {
+	mu     sync.RWMutex
+	topics map[string]map[*Subscription[T]]struct{}
+}
+
+func New[T any](opts ...Option) *Bus[T] {
+	config := &options{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.shards <= 0 {
+		config.shards = defaultShards
+	}
+	if config.queueSize <= 0 {
+		config.queueSize = defaultQueueSize
+	}
+	if config.name == "" {
+		config.name = unnamedBus
+	}
+
+	b := &Bus[T]{
+		options: config,
+		seed:    maphash.MakeSeed(),
+		shards:  make([]*shard[T], config.shards),
+	}
+	for i := range b.shards {
+		b.shards[i] = &shard[T]{topics: make(map[string]map[*Subscription[T]]struct{})}
+	}
+	if config.metrics {
+		b.metrics = defaultBusMetrics()
+		if config.metricsRegisterer != nil {
+			b.metrics = newBusMetrics(config.metricsRegisterer)
+		}
+	}
+	return b
+}
+
+// Subscribe receives events published to any of topics until ctx is done or
+// Unsubscribe is called; either closes C.
+func (b *Bus[T]) Subscribe(ctx context.Context, topics []string, opts ...SubscribeOption) (*Subscription[T], error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	if len(topics) == 0 {
+		return nil, ErrTopicRequired
+	}
+	for _, topic := range topics {
+		if topic == "" {
+			return nil, ErrTopicRequired
+		}
+	}
+	settings := subscribeOptions{queueSize: b.options.queueSize, policy: b.options.policy}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	if settings.queueSize <= 0 {
+		settings.queueSize = b.options.queueSize
+	}
+
+	sub := &Subscription[T]{
+		bus:    b,
+		topics: append([]string(nil), topics...),
+		policy: settings.policy,
+		ch:     make(chan T, settings.queueSize),
+		done:   make(chan struct{}),
+	}
+	for _, topic := range sub.topics {
+		s := b.shard(topic)
+		s.mu.Lock()
+		if b.closed.Load() {
+			s.mu.Unlock()
+			b.remove(sub)
+			return nil, ErrBusClosed
+		}
+		subs, ok := s.topics[topic]
+		if !ok {
+			subs = make(map[*Subscription[T]]struct{})
+			s.topics[topic] = subs
+		}
+		subs[sub] = struct{}{}
+		s.mu.Unlock()
+	}
+	if b.metrics != nil {
+		b.metrics.subscribers.WithLabelValues(b.options.name).Inc()
+	}
+
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				sub.close(ctx.Err())
+			case <-sub.done:
+			}
+		}()
+	}
+	return sub, nil
+}
+
+// Publish queues payload for every subscriber of topic and reports how many
+// received it. It never blocks on slow subscribers.
+func (b *Bus[T]) Publish(topic string, payload T) int {
+	if b.closed.Load() {
+		return 0
+	}
+	if b.metrics != nil {
+		b.metrics.published.WithLabelValues(b.options.name).Inc()
+	}
+
+	var slow []*Subscription[T]
+	delivered := 0
+	s := b.shard(topic)
+	s.mu.RLock()
+	for sub := range s.topics[topic] {
+		ok, overflow := sub.deliver(payload)
+		if ok {
+			delivered++
+		}
+		if overflow {
+			slow = append(slow, sub)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, sub := range slow {
+		sub.close(ErrSlowSubscriber)
+	}
+	if b.metrics != nil && delivered > 0 {
+		b.metrics.delivered.WithLabelValues(b.options.name).Add(float64(delivered))
+	}
+	return delivered
+}
+
+// Subscribers returns the number of subscriptions to topic.
+func (b *Bus[T]) Subscribers(topic string) int {
+	s := b.shard(topic)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.topics[topic])
+}
+
+// Close ends every subscription with ErrBusClosed; later Publish calls are
+// no-ops.
+func (b *Bus[T]) Close() {
+	if !b.closed.CompareAndSwap(false, true) {
+		return
+	}
+	var subs []*Subscription[T]
+	for _, s := range b.shards {
+		s.mu.RLock()
+		for _, topicSubs := range s.topics {
+			for sub := range topicSubs {
+				subs = append(subs, sub)
+			}
+		}
+		s.mu.RUnlock()
+	}
+	for _, sub := range subs {
+		sub.close(ErrBusClosed)
+	}
+}
+
+func (b *Bus[T]) shard(topic string) *shard[T] {
+	return b.shards[maphash.String(b.seed, topic)%uint64(len(b.shards))]
+}
+
+func (b *Bus[T]) remove(sub *Subscription[T]) {
+	for _, topic := range sub.topics {
+		s := b.shard(topic)
+		s.mu.Lock()
+		if subs, ok := s.topics[topic]; ok {
+			delete(subs, sub)
+			if len(subs) == 0 {
+				delete(s.topics, topic)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (b *Bus[T]) dropped(policy DropPolicy) {
+	if b.metrics != nil {
+		b.metrics.dropped.WithLabelValues(b.options.name, policy.String()).Inc()
+	}
+}
+
+type Subscription[T any] struct {
+	bus    *Bus[T]
+	topics []string
+	policy DropPolicy
+	ch     chan T
+
+	mu      sync.Mutex
+	closed  bool
+	err     error
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+// C delivers events in publish order per topic. It is closed when the
+// subscription ends; Err tells why.
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+func (s *Subscription[T]) Topics() []string {
+	return append([]string(nil), s.topics...)
+}
+
+// Dropped returns the number of events lost to a full queue.
+func (s *Subscription[T]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Err returns nil while active, then the reason the subscription ended:
+// context.Canceled for Unsubscribe, the context error, ErrSlowSubscriber or
+// ErrBusClosed.
+func (s *Subscription[T]) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Subscription[T]) Unsubscribe() {
+	s.close(context.Canceled)
+}
+
+func (s *Subscription[T]) deliver(payload T) (delivered, overflow bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false, false
+	}
+	select {
+	case s.ch <- payload:
+		return true, false
+	default:
+	}
+
+	s.dropped.Add(1)
+	s.bus.dropped(s.policy)
+	switch s.policy {
+	case DropNewest:
+		return false, false
+	case CloseSlow:
+		return false, true
+	}
+	// Publishers hold s.mu, so after taking one event out the send below
+	// cannot block even if the reader drained the queue meanwhile.
+	select {
+	case <-s.ch:
+	default:
+	}
+	s.ch <- payload
+	return true, false
+}
+
+func (s *Subscription[T]) close(err error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.err = err
+	close(s.done)
+	close(s.ch)
+	s.mu.Unlock()
+
+	s.bus.remove(s)
+	if s.bus.metrics != nil {
+		s.bus.metrics.subscribers.WithLabelValues(s.bus.options.name).Dec()
+	}
+}
@@ -0,0 +1,229 @@
+package eventbus_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/pkg/eventbus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPublishFansOutToTopicSubscribers(t *testing.T) {
+	bus := eventbus.New[string]()
+	defer bus.Close()
+
+	a, err := bus.Subscribe(context.Background(), []string{"orders"})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	b, err := bus.Subscribe(context.Background(), []string{"orders", "users"})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	if n := bus.Publish("orders", "o1"); n != 2 {
+		t.Fatalf("Publish(orders) = %d, want 2", n)
+	}
+	if n := bus.Publish("users", "u1"); n != 1 {
+		t.Fatalf("Publish(users) = %d, want 1", n)
+	}
+	if n := bus.Publish("none", "x"); n != 0 {
+		t.Fatalf("Publish(none) = %d, want 0", n)
+	}
+
+	if got := <-a.C(); got != "o1" {
+		t.Fatalf("a got %q, want o1", got)
+	}
+	if got := <-b.C(); got != "o1" {
+		t.Fatalf("b got %q, want o1", got)
+	}
+	if got := <-b.C(); got != "u1" {
+		t.Fatalf("b got %q, want u1", got)
+	}
+}
+
+func TestSubscribeValidatesTopics(t *testing.T) {
+	bus := eventbus.New[int]()
+	if _, err := bus.Subscribe(context.Background(), nil); !errors.Is(err, eventbus.ErrTopicRequired) {
+		t.Fatalf("Subscribe(nil) error = %v, want ErrTopicRequired", err)
+	}
+	if _, err := bus.Subscribe(context.Background(), []string{""}); !errors.Is(err, eventbus.ErrTopicRequired) {
+		t.Fatalf("Subscribe(\"\") error = %v, want ErrTopicRequired", err)
+	}
+	bus.Close()
+	if _, err := bus.Subscribe(context.Background(), []string{"t"}); !errors.Is(err, eventbus.ErrBusClosed) {
+		t.Fatalf("Subscribe() after Close error = %v, want ErrBusClosed", err)
+	}
+}
+
+func TestDropPolicies(t *testing.T) {
+	bus := eventbus.New[int](eventbus.WithQueueSize(2))
+	defer bus.Close()
+
+	oldest, _ := bus.Subscribe(context.Background(), []string{"t"})
+	newest, _ := bus.Subscribe(context.Background(), []string{"t"}, eventbus.WithSubscriberPolicy(eventbus.DropNewest))
+	slow, _ := bus.Subscribe(context.Background(), []string{"t"}, eventbus.WithSubscriberPolicy(eventbus.CloseSlow))
+
+	for i := 1; i <= 3; i++ {
+		bus.Publish("t", i)
+	}
+
+	if got := drain(oldest); !slices.Equal(got, []int{2, 3}) {
+		t.Fatalf("DropOldest received %v, want [2 3]", got)
+	}
+	if oldest.Dropped() != 1 {
+		t.Fatalf("DropOldest Dropped() = %d, want 1", oldest.Dropped())
+	}
+	if got := drain(newest); !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("DropNewest received %v, want [1 2]", got)
+	}
+
+	var got []int
+	for v := range slow.C() {
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("CloseSlow received %v, want [1 2]", got)
+	}
+	if !errors.Is(slow.Err(), eventbus.ErrSlowSubscriber) {
+		t.Fatalf("CloseSlow Err() = %v, want ErrSlowSubscriber", slow.Err())
+	}
+	if n := bus.Subscribers("t"); n != 2 {
+		t.Fatalf("Subscribers() = %d, want 2", n)
+	}
+}
+
+func TestSubscriptionEndsWithContext(t *testing.T) {
+	bus := eventbus.New[int]()
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, _ := bus.Subscribe(ctx, []string{"t"})
+	cancel()
+
+	select {
+	case _, ok := <-sub.C():
+		if ok {
+			t.Fatal("unexpected event")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed after context cancel")
+	}
+	if !errors.Is(sub.Err(), context.Canceled) {
+		t.Fatalf("Err() = %v, want context.Canceled", sub.Err())
+	}
+	if n := bus.Subscribers("t"); n != 0 {
+		t.Fatalf("Subscribers() = %d, want 0", n)
+	}
+}
+
+func TestUnsubscribeAndClose(t *testing.T) {
+	bus := eventbus.New[int]()
+	sub, _ := bus.Subscribe(context.Background(), []string{"t"})
+	other, _ := bus.Subscribe(context.Background(), []string{"t"})
+
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	if n := bus.Publish("t", 1); n != 1 {
+		t.Fatalf("Publish() = %d, want 1", n)
+	}
+
+	bus.Close()
+	if !errors.Is(other.Err(), eventbus.ErrBusClosed) {
+		t.Fatalf("Err() = %v, want ErrBusClosed", other.Err())
+	}
+	if n := bus.Publish("t", 2); n != 0 {
+		t.Fatalf("Publish() after Close = %d, want 0", n)
+	}
+}
+
+func TestConcurrentPublishAndUnsubscribe(t *testing.T) {
+	bus := eventbus.New[int](eventbus.WithShards(4), eventbus.WithQueueSize(1))
+	defer bus.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		sub, _ := bus.Subscribe(context.Background(), []string{"t"})
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				bus.Publish("t", j)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				select {
+				case <-sub.C():
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+			sub.Unsubscribe()
+		}()
+	}
+	wg.Wait()
+	if n := bus.Subscribers("t"); n != 0 {
+		t.Fatalf("Subscribers() = %d, want 0", n)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	bus := eventbus.New[int](eventbus.WithName("push"), eventbus.WithQueueSize(1), eventbus.WithMetrics(registry))
+	defer bus.Close()
+
+	sub, _ := bus.Subscribe(context.Background(), []string{"t"}, eventbus.WithSubscriberPolicy(eventbus.DropNewest))
+	bus.Publish("t", 1)
+	bus.Publish("t", 2)
+
+	if got := metricValue(t, registry, "eventbus_published_total"); got != 2 {
+		t.Fatalf("published = %v, want 2", got)
+	}
+	if got := metricValue(t, registry, "eventbus_dropped_total"); got != 1 {
+		t.Fatalf("dropped = %v, want 1", got)
+	}
+	if got := metricValue(t, registry, "eventbus_subscribers"); got != 1 {
+		t.Fatalf("subscribers = %v, want 1", got)
+	}
+	sub.Unsubscribe()
+	if got := metricValue(t, registry, "eventbus_subscribers"); got != 0 {
+		t.Fatalf("subscribers after Unsubscribe = %v, want 0", got)
+	}
+}
+
+func metricValue(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		metric := family.GetMetric()[0]
+		if metric.GetCounter() != nil {
+			return metric.GetCounter().GetValue()
+		}
+		return metric.GetGauge().GetValue()
+	}
+	t.Fatalf("metric %s not found", name)
+	return 0
+}
+
+func drain(sub *eventbus.Subscription[int]) []int {
+	var got []int
+	for {
+		select {
+		case v := <-sub.C():
+			got = append(got, v)
+		default:
+			return got
+		}
+	}
+}
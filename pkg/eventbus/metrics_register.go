@@ -0,0 +1,81 @@
+package eventbus
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	published   *prometheus.CounterVec
+	delivered   *prometheus.CounterVec
+	dropped     *prometheus.CounterVec
+	subscribers *prometheus.GaugeVec
+}
+
+var (
+	defaultMetricsOnce sync.Once
+	defaultMetrics     *metrics
+)
+
+func defaultBusMetrics() *metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = newBusMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetrics
+}
+
+func newBusMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		published: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "eventbus_published_total",
+				Help: "Total number of events published.",
+			},
+			[]string{"bus"},
+		),
+		delivered: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "eventbus_delivered_total",
+				Help: "Total number of events queued to subscribers.",
+			},
+			[]string{"bus"},
+		),
+		dropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "eventbus_dropped_total",
+				Help: "Total number of events dropped for full subscriber queues.",
+			},
+			[]string{"bus", "policy"},
+		),
+		subscribers: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "eventbus_subscribers",
+				Help: "Current number of subscriptions.",
+			},
+			[]string{"bus"},
+		),
+	}
+
+	mustRegisterCollector(registerer, &m.published, m.published)
+	mustRegisterCollector(registerer, &m.delivered, m.delivered)
+	mustRegisterCollector(registerer, &m.dropped, m.dropped)
+	mustRegisterCollector(registerer, &m.subscribers, m.subscribers)
+
+	return m
+}
+
+func mustRegisterCollector[T prometheus.Collector](registerer prometheus.Registerer, dst *T, collector T) {
+	if registerer == nil {
+		return
+	}
+	if err := registerer.Register(collector); err != nil {
+		if alreadyRegistered, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if registered, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				*dst = registered
+				return
+			}
+		}
+		panic(err)
+	}
+}
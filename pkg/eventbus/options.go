@@ -0,0 +1,98 @@
+package eventbus
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// DropPolicy decides what happens when a subscriber's queue is full.
+type DropPolicy int
+
+const (
+	// DropOldest discards the oldest queued event to make room, so slow
+	// subscribers still see the latest state.
+	DropOldest DropPolicy = iota
+	// DropNewest discards the event being published.
+	DropNewest
+	// CloseSlow closes the subscription with ErrSlowSubscriber, e.g. to end a
+	// stream whose client must resync after reconnecting.
+	CloseSlow
+)
+
+func (p DropPolicy) String() string {
+	switch p {
+	case DropNewest:
+		return "drop_newest"
+	case CloseSlow:
+		return "close_slow"
+	default:
+		return "drop_oldest"
+	}
+}
+
+type Option func(*options)
+
+type options struct {
+	shards    int
+	queueSize int
+	policy    DropPolicy
+	name      string
+
+	metrics           bool
+	metricsRegisterer prometheus.Registerer
+}
+
+// WithShards sets the number of topic shards, each with its own lock.
+// Defaults to 16.
+func WithShards(n int) Option {
+	return func(o *options) {
+		o.shards = n
+	}
+}
+
+// WithQueueSize sets the default per-subscriber queue length. Defaults to 64.
+func WithQueueSize(size int) Option {
+	return func(o *options) {
+		o.queueSize = size
+	}
+}
+
+// WithDropPolicy sets the default policy for full subscriber queues.
+func WithDropPolicy(policy DropPolicy) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+// WithName sets the bus label used in metrics.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithMetrics records eventbus_published_total, eventbus_delivered_total,
+// eventbus_dropped_total and eventbus_subscribers, labeled by bus name.
+func WithMetrics(registerer prometheus.Registerer) Option {
+	return func(o *options) {
+		o.metrics = true
+		o.metricsRegisterer = registerer
+	}
+}
+
+// SubscribeOption overrides bus defaults for one subscription.
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	queueSize int
+	policy    DropPolicy
+}
+
+func WithSubscriberQueue(size int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.queueSize = size
+	}
+}
+
+func WithSubscriberPolicy(policy DropPolicy) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.policy = policy
+	}
+}
@@ -0,0 +1,35 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Handler serves Readiness as JSON, answering 503 when the report is down.
+// Use it as the HealthHandler of httpx, ginx or wsx.
+func (c *Checker) Handler() http.Handler {
+	return reportHandler(c.Readiness)
+}
+
+// LivenessHandler serves Liveness the same way as Handler.
+func (c *Checker) LivenessHandler() http.Handler {
+	return reportHandler(c.Liveness)
+}
+
+func reportHandler(evaluate func(context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := evaluate(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status == StatusDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		if r.Method == http.MethodHead {
+			return
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
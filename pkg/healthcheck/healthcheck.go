@@ -0,0 +1,311 @@
+// Package healthcheck aggregates named component checks into readiness and
+// liveness reports for HTTP probes and the gRPC health service.
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultName    = "default"
+	defaultTimeout = time.Second
+	defaultTTL     = 2 * time.Second
+)
+
+var (
+	ErrNameRequired    = errors.New("healthcheck: check name is required")
+	ErrCheckRequired   = errors.New("healthcheck: check func is required")
+	ErrDuplicateCheck  = errors.New("healthcheck: check already registered")
+	ErrContextRequired = errors.New("healthcheck: context is required")
+	ErrUnhealthy       = errors.New("healthcheck: unhealthy")
+)
+
+// Status is the outcome of a check or a whole report.
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// CheckFunc reports a component as healthy by returning nil. It should
+// honor ctx; a check that ignores it is reported as timed out and left to
+// finish in the background.
+type CheckFunc func(context.Context) error
+
+// Result is the outcome of one check.
+type Result struct {
+	Name      string        `json:"name"`
+	Severity  Severity      `json:"severity"`
+	Status    Status        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"-"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Cached    bool          `json:"cached,omitempty"`
+}
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	type result Result
+	return json.Marshal(struct {
+		result
+		Duration string `json:"duration"`
+	}{result(r), r.Duration.String()})
+}
+
+// Report aggregates check results. It is down when a critical check fails,
+// degraded when only soft checks fail, and up otherwise.
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Err returns nil unless the report is down, naming the failed critical
+// checks.
+func (r Report) Err() error {
+	if r.Status != StatusDown {
+		return nil
+	}
+	var failed []string
+	for _, result := range r.Checks {
+		if result.Status == StatusDown && result.Severity == Critical {
+			failed = append(failed, result.Name)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnhealthy, strings.Join(failed, ", "))
+}
+
+type Checker struct {
+	options options
+	metrics *metrics
+
+	mu     sync.RWMutex
+	checks map[string]*check
+}
+
+type check struct {
+	name     string
+	fn       CheckFunc
+	severity Severity
+	timeout  time.Duration
+	ttl      time.Duration
+	liveness bool
+
+	// mu serializes runs, so concurrent probes share one result.
+	mu     sync.Mutex
+	last   Result
+	cached bool
+}
+
+func New(opts ...Option) *Checker {
+	config := options{
+		name:    defaultName,
+		timeout: defaultTimeout,
+		ttl:     defaultTTL,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&config)
+		}
+	}
+	if config.timeout <= 0 {
+		config.timeout = defaultTimeout
+	}
+
+	c := &Checker{
+		options: config,
+		checks:  make(map[string]*check),
+	}
+	if config.metrics {
+		c.metrics = defaultCheckerMetrics()
+		if config.metricsRegisterer != nil {
+			c.metrics = newCheckerMetrics(config.metricsRegisterer)
+		}
+	}
+	return c
+}
+
+// Register adds a named check. Checks are critical, readiness-only and use
+// the checker timeout and TTL unless overridden.
+func (c *Checker) Register(name string, fn CheckFunc, opts ...CheckOption) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrNameRequired
+	}
+	if fn == nil {
+		return ErrCheckRequired
+	}
+
+	chk := &check{
+		name:     name,
+		fn:       fn,
+		severity: Critical,
+		timeout:  c.options.timeout,
+		ttl:      c.options.ttl,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(chk)
+		}
+	}
+	if chk.timeout <= 0 {
+		chk.timeout = c.options.timeout
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.checks[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateCheck, name)
+	}
+	c.checks[name] = chk
+	return nil
+}
+
+// MustRegister is Register that panics on error, for wiring at startup.
+func (c *Checker) MustRegister(name string, fn CheckFunc, opts ...CheckOption) {
+	if err := c.Register(name, fn, opts...); err != nil {
+		panic(err)
+	}
+}
+
+func (c *Checker) Unregister(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.checks, name)
+}
+
+// Readiness runs every check concurrently and aggregates the results.
+func (c *Checker) Readiness(ctx context.Context) Report {
+	return c.evaluate(ctx, false)
+}
+
+// Liveness runs only the checks registered with WithLiveness. With none it
+// reports up, since a process that can answer is alive.
+func (c *Checker) Liveness(ctx context.Context) Report {
+	return c.evaluate(ctx, true)
+}
+
+// Ready returns Readiness(ctx).Err(), matching callbacks such as
+// tcpx.HealthConfig.Check.
+func (c *Checker) Ready(ctx context.Context) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	return c.Readiness(ctx).Err()
+}
+
+func (c *Checker) evaluate(ctx context.Context, liveness bool) Report {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	c.mu.RLock()
+	checks := make([]*check, 0, len(c.checks))
+	for _, chk := range c.checks {
+		if !liveness || chk.liveness {
+			checks = append(checks, chk)
+		}
+	}
+	c.mu.RUnlock()
+	slices.SortFunc(checks, func(a, b *check) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, chk := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, chk)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusDown {
+			continue
+		}
+		if result.Severity == Critical {
+			report.Status = StatusDown
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, chk *check) Result {
+	chk.mu.Lock()
+	defer chk.mu.Unlock()
+
+	if chk.cached && time.Since(chk.last.CheckedAt) < chk.ttl {
+		result := chk.last
+		result.Cached = true
+		return result
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, chk.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- call(checkCtx, chk.fn)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-checkCtx.Done():
+		err = checkCtx.Err()
+	}
+
+	result := Result{
+		Name:      chk.name,
+		Severity:  chk.severity,
+		Status:    StatusUp,
+		Duration:  time.Since(start),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	// A probe that gave up says nothing about the component; don't let it
+	// poison the cache for the next caller.
+	if ctx.Err() == nil {
+		chk.last = result
+		chk.cached = true
+	}
+	c.observe(result)
+	return result
+}
+
+func call(ctx context.Context, fn CheckFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("healthcheck: check panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+func (c *Checker) observe(result Result) {
+	if c.metrics == nil {
+		return
+	}
+	up := 0.0
+	if result.Status == StatusUp {
+		up = 1
+	}
+	c.metrics.up.WithLabelValues(c.options.name, result.Name).Set(up)
+	c.metrics.duration.WithLabelValues(c.options.name, result.Name).Observe(result.Duration.Seconds())
+}
@@ -0,0 +1,117 @@
+package healthcheck_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/pkg/healthcheck"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestReadinessSeverity(t *testing.T) {
+	checker := healthcheck.New()
+	var dbDown, cacheDown atomic.Bool
+	probe := func(down *atomic.Bool) healthcheck.CheckFunc {
+		return func(context.Context) error {
+			if down.Load() {
+				return errors.New("down")
+			}
+			return nil
+		}
+	}
+	checker.MustRegister("db", probe(&dbDown), healthcheck.WithCheckTTL(-1))
+	checker.MustRegister("cache", probe(&cacheDown), healthcheck.WithSeverity(healthcheck.Soft), healthcheck.WithCheckTTL(-1))
+
+	ctx := context.Background()
+	if report := checker.Readiness(ctx); report.Status != healthcheck.StatusUp || len(report.Checks) != 2 {
+		t.Fatalf("Readiness() = %+v", report)
+	}
+
+	cacheDown.Store(true)
+	report := checker.Readiness(ctx)
+	if report.Status != healthcheck.StatusDegraded || report.Err() != nil {
+		t.Fatalf("Readiness() with soft failure = %+v", report)
+	}
+
+	dbDown.Store(true)
+	report = checker.Readiness(ctx)
+	if report.Status != healthcheck.StatusDown {
+		t.Fatalf("Readiness() with critical failure = %+v", report)
+	}
+	if err := checker.Ready(ctx); !errors.Is(err, healthcheck.ErrUnhealthy) || err.Error() != "healthcheck: unhealthy: db" {
+		t.Fatalf("Ready() error = %v", err)
+	}
+}
+
+func TestChecksAreCachedAndTimedOut(t *testing.T) {
+	checker := healthcheck.New(healthcheck.WithTTL(time.Hour), healthcheck.WithTimeout(20*time.Millisecond))
+	var runs atomic.Int32
+	checker.MustRegister("counted", func(context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	checker.MustRegister("stuck", func(context.Context) error {
+		select {}
+	})
+
+	ctx := context.Background()
+	first := checker.Readiness(ctx)
+	second := checker.Readiness(ctx)
+	if runs.Load() != 1 {
+		t.Fatalf("check ran %d times within TTL", runs.Load())
+	}
+	if first.Checks[0].Cached || !second.Checks[0].Cached {
+		t.Fatalf("Cached flags = %v, %v", first.Checks[0].Cached, second.Checks[0].Cached)
+	}
+	if stuck := first.Checks[1]; stuck.Status != healthcheck.StatusDown || stuck.Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("stuck check = %+v", stuck)
+	}
+
+	if err := checker.Register("counted", func(context.Context) error { return nil }); !errors.Is(err, healthcheck.ErrDuplicateCheck) {
+		t.Fatalf("Register() duplicate error = %v", err)
+	}
+	if err := checker.Register(" ", func(context.Context) error { return nil }); !errors.Is(err, healthcheck.ErrNameRequired) {
+		t.Fatalf("Register() empty name error = %v", err)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	checker := healthcheck.New(healthcheck.WithName("api"), healthcheck.WithMetrics(prometheus.NewRegistry()))
+	checker.MustRegister("worker", func(context.Context) error { return nil }, healthcheck.WithLiveness())
+	checker.MustRegister("db", func(context.Context) error { panic("boom") })
+
+	rec := httptest.NewRecorder()
+	checker.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readiness status = %d", rec.Code)
+	}
+	var report struct {
+		Status string `json:"status"`
+		Checks []struct {
+			Name     string `json:"name"`
+			Severity string `json:"severity"`
+			Status   string `json:"status"`
+			Error    string `json:"error"`
+			Duration string `json:"duration"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.Status != "down" || len(report.Checks) != 2 || report.Checks[0].Name != "db" ||
+		report.Checks[0].Severity != "critical" || report.Checks[0].Error == "" || report.Checks[0].Duration == "" {
+		t.Fatalf("report = %+v", report)
+	}
+
+	rec = httptest.NewRecorder()
+	checker.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("liveness status = %d, body = %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,64 @@
+package healthcheck
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	up       *prometheus.GaugeVec
+	duration *prometheus.HistogramVec
+}
+
+var (
+	defaultMetricsOnce sync.Once
+	defaultMetrics     *metrics
+)
+
+func defaultCheckerMetrics() *metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = newCheckerMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetrics
+}
+
+func newCheckerMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		up: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "healthcheck_check_up",
+				Help: "Whether the last run of a health check passed (1) or failed (0).",
+			},
+			[]string{"checker", "check"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "healthcheck_check_duration_seconds",
+				Help:    "Duration of health check runs.",
+				Buckets: []float64{.001, .005, .01, .05, .1, .25, .5, 1, 2.5, 5},
+			},
+			[]string{"checker", "check"},
+		),
+	}
+
+	mustRegisterCollector(registerer, &m.up, m.up)
+	mustRegisterCollector(registerer, &m.duration, m.duration)
+
+	return m
+}
+
+func mustRegisterCollector[T prometheus.Collector](registerer prometheus.Registerer, dst *T, collector T) {
+	if registerer == nil {
+		return
+	}
+	if err := registerer.Register(collector); err != nil {
+		if alreadyRegistered, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if registered, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				*dst = registered
+				return
+			}
+		}
+		panic(err)
+	}
+}
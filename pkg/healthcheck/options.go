@@ -0,0 +1,100 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Severity decides how a failing check affects the report.
+type Severity int
+
+const (
+	// Critical failures mark the report down and fail readiness.
+	Critical Severity = iota
+	// Soft failures mark the report degraded but keep the instance in
+	// rotation, e.g. for an optional cache or a slow downstream.
+	Soft
+)
+
+func (s Severity) String() string {
+	if s == Soft {
+		return "soft"
+	}
+	return "critical"
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+type Option func(*options)
+
+type options struct {
+	name    string
+	timeout time.Duration
+	ttl     time.Duration
+
+	metrics           bool
+	metricsRegisterer prometheus.Registerer
+}
+
+// WithName sets the checker label used in metrics.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithTimeout sets the default per-check timeout. Defaults to 1s.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithTTL sets how long a result is reused before the check runs again.
+// Defaults to 2s; a negative value disables caching.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithMetrics records healthcheck_check_up and
+// healthcheck_check_duration_seconds, labeled by checker and check name.
+func WithMetrics(registerer prometheus.Registerer) Option {
+	return func(o *options) {
+		o.metrics = true
+		o.metricsRegisterer = registerer
+	}
+}
+
+// CheckOption overrides checker defaults for one check.
+type CheckOption func(*check)
+
+func WithSeverity(severity Severity) CheckOption {
+	return func(c *check) {
+		c.severity = severity
+	}
+}
+
+func WithCheckTimeout(timeout time.Duration) CheckOption {
+	return func(c *check) {
+		c.timeout = timeout
+	}
+}
+
+func WithCheckTTL(ttl time.Duration) CheckOption {
+	return func(c *check) {
+		c.ttl = ttl
+	}
+}
+
+// WithLiveness also runs the check for Liveness. Only add checks whose
+// failure means the process must be restarted, such as a deadlocked worker.
+func WithLiveness() CheckOption {
+	return func(c *check) {
+		c.liveness = true
+	}
+}
@@ -0,0 +1,265 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+var (
+	ErrInvalidLanguage = errors.New("i18n: invalid language")
+	ErrEmptyMessage    = errors.New("i18n: message has no Other form")
+)
+
+// Message is a translation with CLDR plural forms. Other is required and
+// used when the form chosen for a count is empty; Zero, when set, is used
+// for a count of 0 even in languages without a zero category.
+type Message struct {
+	Zero  string
+	One   string
+	Two   string
+	Few   string
+	Many  string
+	Other string
+}
+
+// Bundle holds messages per language. Lookups walk the matched language,
+// its parents (zh-Hant-TW, zh-Hant, zh) and finally the default language.
+type Bundle struct {
+	defaultTag language.Tag
+
+	mu       sync.RWMutex
+	messages map[language.Tag]map[string]Message
+	tags     []language.Tag
+	matcher  language.Matcher
+}
+
+func NewBundle(defaultLanguage string) (*Bundle, error) {
+	tag, err := parseTag(defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+	return &Bundle{
+		defaultTag: tag,
+		messages:   map[language.Tag]map[string]Message{tag: {}},
+		tags:       []language.Tag{tag},
+	}, nil
+}
+
+// AddMessages adds single-form messages for lang, replacing existing keys.
+func (b *Bundle) AddMessages(lang string, messages map[string]string) error {
+	plural := make(map[string]Message, len(messages))
+	for key, text := range messages {
+		plural[key] = Message{Other: text}
+	}
+	return b.AddPluralMessages(lang, plural)
+}
+
+// AddPluralMessages adds messages with plural forms for lang.
+func (b *Bundle) AddPluralMessages(lang string, messages map[string]Message) error {
+	tag, err := parseTag(lang)
+	if err != nil {
+		return err
+	}
+	for key, message := range messages {
+		if message.Other == "" {
+			return fmt.Errorf("%w: %s", ErrEmptyMessage, key)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	existing, ok := b.messages[tag]
+	if !ok {
+		existing = make(map[string]Message, len(messages))
+		b.messages[tag] = existing
+		b.tags = append(b.tags, tag)
+		b.matcher = nil
+	}
+	for key, message := range messages {
+		existing[key] = message
+	}
+	return nil
+}
+
+// Languages returns the languages with messages, the default first.
+func (b *Bundle) Languages() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	languages := make([]string, len(b.tags))
+	for i, tag := range b.tags {
+		languages[i] = tag.String()
+	}
+	return languages
+}
+
+// Localizer returns a localizer for the best supported match of preferred,
+// each either a language tag or an Accept-Language header value. Unparsable
+// entries are ignored; without a match the default language is used.
+func (b *Bundle) Localizer(preferred ...string) *Localizer {
+	var desired []language.Tag
+	for _, value := range preferred {
+		tags, _, err := language.ParseAcceptLanguage(value)
+		if err == nil {
+			desired = append(desired, tags...)
+		}
+	}
+
+	b.mu.Lock()
+	if b.matcher == nil {
+		b.matcher = language.NewMatcher(b.tags)
+	}
+	matcher, tags := b.matcher, b.tags
+	b.mu.Unlock()
+
+	tag := b.defaultTag
+	if len(desired) > 0 {
+		if _, index, confidence := matcher.Match(desired...); confidence != language.No {
+			tag = tags[index]
+		}
+	}
+	return &Localizer{bundle: b, tag: tag, chain: b.chain(tag)}
+}
+
+func (b *Bundle) chain(tag language.Tag) []language.Tag {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var chain []language.Tag
+	for t := tag; ; t = t.Parent() {
+		if _, ok := b.messages[t]; ok && t != b.defaultTag {
+			chain = append(chain, t)
+		}
+		if t == language.Und {
+			break
+		}
+	}
+	return append(chain, b.defaultTag)
+}
+
+func (b *Bundle) lookup(chain []language.Tag, key string) (Message, language.Tag, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, tag := range chain {
+		if message, ok := b.messages[tag][key]; ok {
+			return message, tag, true
+		}
+	}
+	return Message{}, language.Und, false
+}
+
+// Localizer translates messages into one negotiated language. A nil
+// Localizer returns keys unchanged, so callers need no nil checks.
+type Localizer struct {
+	bundle *Bundle
+	tag    language.Tag
+	chain  []language.Tag
+}
+
+// Language returns the negotiated language, e.g. for Content-Language.
+func (l *Localizer) Language() string {
+	if l == nil {
+		return ""
+	}
+	return l.tag.String()
+}
+
+// Lookup translates key, replacing {name} placeholders with args. It
+// reports false when no language in the fallback chain has key.
+func (l *Localizer) Lookup(key string, args map[string]any) (string, bool) {
+	if l == nil {
+		return key, false
+	}
+	message, _, ok := l.bundle.lookup(l.chain, key)
+	if !ok {
+		return key, false
+	}
+	return format(message.Other, args), true
+}
+
+// T translates key, returning key itself when it has no translation.
+func (l *Localizer) T(key string, args map[string]any) string {
+	text, _ := l.Lookup(key, args)
+	return text
+}
+
+// Plural translates key choosing the plural form for count in the language
+// the message was found in; {count} is filled in automatically.
+func (l *Localizer) Plural(key string, count int, args map[string]any) string {
+	if l == nil {
+		return key
+	}
+	message, tag, ok := l.bundle.lookup(l.chain, key)
+	if !ok {
+		return key
+	}
+	values := make(map[string]any, len(args)+1)
+	for name, value := range args {
+		values[name] = value
+	}
+	values["count"] = count
+	return format(message.form(tag, count), values)
+}
+
+func (m Message) form(tag language.Tag, count int) string {
+	if count == 0 && m.Zero != "" {
+		return m.Zero
+	}
+	n := count
+	if n < 0 {
+		n = -n
+	}
+	var text string
+	switch plural.Cardinal.MatchPlural(tag, n, 0, 0, 0, 0) {
+	case plural.Zero:
+		text = m.Zero
+	case plural.One:
+		text = m.One
+	case plural.Two:
+		text = m.Two
+	case plural.Few:
+		text = m.Few
+	case plural.Many:
+		text = m.Many
+	}
+	if text == "" {
+		return m.Other
+	}
+	return text
+}
+
+func format(text string, args map[string]any) string {
+	if len(args) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	pairs := make([]string, 0, len(args)*2)
+	for name, value := range args {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+func parseTag(lang string) (language.Tag, error) {
+	tag, err := language.Parse(strings.TrimSpace(lang))
+	if err != nil || tag == language.Und {
+		return language.Und, fmt.Errorf("%w: %q", ErrInvalidLanguage, lang)
+	}
+	return tag, nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l.
+func NewContext(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the Localizer stored in ctx, or nil.
+func FromContext(ctx context.Context) *Localizer {
+	l, _ := ctx.Value(contextKey{}).(*Localizer)
+	return l
+}
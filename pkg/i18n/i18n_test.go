@@ -0,0 +1,118 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func newTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+
+	bundle, err := NewBundle("en")
+	if err != nil {
+		t.Fatalf("NewBundle() error = %v", err)
+	}
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("add messages: %v", err)
+		}
+	}
+	must(bundle.AddMessages("en", map[string]string{
+		"greeting": "Hello, {name}",
+		"farewell": "Goodbye",
+	}))
+	must(bundle.AddPluralMessages("en", map[string]Message{
+		"items": {Zero: "No items", One: "{count} item", Other: "{count} items"},
+	}))
+	must(bundle.AddMessages("zh", map[string]string{"greeting": "你好，{name}"}))
+	must(bundle.AddMessages("zh-Hant", map[string]string{"greeting": "您好，{name}"}))
+	must(bundle.AddPluralMessages("ru", map[string]Message{
+		"items": {One: "{count} предмет", Few: "{count} предмета", Many: "{count} предметов", Other: "{count} предмета"},
+	}))
+	return bundle
+}
+
+func TestLocalizerNegotiatesAndFallsBack(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	tests := []struct {
+		accept   string
+		language string
+		greeting string
+		farewell string
+	}{
+		{accept: "zh-CN,zh;q=0.9,en;q=0.8", language: "zh", greeting: "你好，Ann", farewell: "Goodbye"},
+		{accept: "zh-TW", language: "zh-Hant", greeting: "您好，Ann", farewell: "Goodbye"},
+		{accept: "fr-FR, de;q=0.5", language: "en", greeting: "Hello, Ann", farewell: "Goodbye"},
+		{accept: "not a language", language: "en", greeting: "Hello, Ann", farewell: "Goodbye"},
+	}
+	for _, tt := range tests {
+		l := bundle.Localizer(tt.accept)
+		if got := l.Language(); got != tt.language {
+			t.Fatalf("Localizer(%q).Language() = %q, want %q", tt.accept, got, tt.language)
+		}
+		if got := l.T("greeting", map[string]any{"name": "Ann"}); got != tt.greeting {
+			t.Fatalf("Localizer(%q) greeting = %q, want %q", tt.accept, got, tt.greeting)
+		}
+		if got := l.T("farewell", nil); got != tt.farewell {
+			t.Fatalf("Localizer(%q) farewell = %q, want %q", tt.accept, got, tt.farewell)
+		}
+	}
+
+	if got, ok := bundle.Localizer("zh").Lookup("missing", nil); ok || got != "missing" {
+		t.Fatalf("Lookup(missing) = %q, %v", got, ok)
+	}
+}
+
+func TestLocalizerPlural(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	en := bundle.Localizer("en")
+	for count, want := range map[int]string{0: "No items", 1: "1 item", 5: "5 items"} {
+		if got := en.Plural("items", count, nil); got != want {
+			t.Fatalf("en Plural(%d) = %q, want %q", count, got, want)
+		}
+	}
+	ru := bundle.Localizer("ru")
+	for count, want := range map[int]string{1: "1 предмет", 3: "3 предмета", 5: "5 предметов", 21: "21 предмет"} {
+		if got := ru.Plural("items", count, nil); got != want {
+			t.Fatalf("ru Plural(%d) = %q, want %q", count, got, want)
+		}
+	}
+	// Falling back to English also switches to English plural rules.
+	if got := bundle.Localizer("zh").Plural("items", 1, nil); got != "1 item" {
+		t.Fatalf("zh Plural(1) = %q", got)
+	}
+}
+
+func TestLocalizerContextAndNil(t *testing.T) {
+	var l *Localizer
+	if got := l.T("greeting", nil); got != "greeting" {
+		t.Fatalf("nil T() = %q", got)
+	}
+	if FromContext(context.Background()) != nil {
+		t.Fatal("FromContext() on empty context returned a localizer")
+	}
+	l = newTestBundle(t).Localizer("zh")
+	if FromContext(NewContext(context.Background(), l)) != l {
+		t.Fatal("FromContext() did not return the stored localizer")
+	}
+}
+
+func TestBundleValidation(t *testing.T) {
+	if _, err := NewBundle(""); !errors.Is(err, ErrInvalidLanguage) {
+		t.Fatalf("NewBundle(\"\") error = %v", err)
+	}
+	bundle := newTestBundle(t)
+	if err := bundle.AddMessages("??", map[string]string{"k": "v"}); !errors.Is(err, ErrInvalidLanguage) {
+		t.Fatalf("AddMessages(??) error = %v", err)
+	}
+	if err := bundle.AddPluralMessages("en", map[string]Message{"k": {One: "x"}}); !errors.Is(err, ErrEmptyMessage) {
+		t.Fatalf("AddPluralMessages() error = %v", err)
+	}
+	if got := bundle.Languages(); len(got) != 4 || got[0] != "en" {
+		t.Fatalf("Languages() = %v", got)
+	}
+}
@@ -0,0 +1,171 @@
+package pagination
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FieldType int
+
+const (
+	TypeString FieldType = iota
+	TypeInt
+	TypeFloat
+	TypeBool
+	// TypeTime values are RFC 3339 timestamps.
+	TypeTime
+)
+
+type Operator string
+
+const (
+	OpEq  Operator = "="
+	OpNe  Operator = "!="
+	OpLt  Operator = "<"
+	OpLte Operator = "<="
+	OpGt  Operator = ">"
+	OpGte Operator = ">="
+	// OpContains matches strings containing the value.
+	OpContains Operator = "~"
+)
+
+// Condition is one "field op value" term. Value is a string, int64, float64,
+// bool or time.Time according to the field's type.
+type Condition struct {
+	Field string
+	Op    Operator
+	Value any
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOp
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// parseFilter reads terms joined by AND, e.g.
+// `status = "active" AND age >= 18 AND name ~ bob`. String values may be bare
+// words or double quoted with Go escapes.
+func (p *Paginator) parseFilter(expr string) ([]Condition, error) {
+	if len(p.conf.FilterFields) == 0 {
+		return nil, fmt.Errorf("%w: filtering is not supported", ErrInvalidFilter)
+	}
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []Condition
+	for i := 0; i < len(tokens); {
+		if len(conditions) > 0 {
+			if tokens[i].kind != tokenWord || !strings.EqualFold(tokens[i].value, "AND") {
+				return nil, fmt.Errorf("%w: expected AND before %q", ErrInvalidFilter, tokens[i].value)
+			}
+			i++
+		}
+		if len(tokens)-i < 3 {
+			return nil, fmt.Errorf("%w: incomplete condition", ErrInvalidFilter)
+		}
+		field, op, value := tokens[i], tokens[i+1], tokens[i+2]
+		i += 3
+		if field.kind != tokenWord || op.kind != tokenOp || value.kind == tokenOp {
+			return nil, fmt.Errorf("%w: expected field, operator and value near %q", ErrInvalidFilter, field.value)
+		}
+		condition, err := p.condition(field.value, Operator(op.value), value.value)
+		if err != nil {
+			return nil, err
+		}
+		if len(conditions) == p.conf.MaxConditions {
+			return nil, fmt.Errorf("%w: more than %d conditions", ErrInvalidFilter, p.conf.MaxConditions)
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+func (p *Paginator) condition(field string, op Operator, raw string) (Condition, error) {
+	typ, ok := p.conf.FilterFields[field]
+	if !ok {
+		return Condition{}, fmt.Errorf("%w: field %q is not filterable", ErrInvalidFilter, field)
+	}
+	switch {
+	case op == OpContains && typ != TypeString,
+		typ == TypeBool && op != OpEq && op != OpNe:
+		return Condition{}, fmt.Errorf("%w: operator %s does not apply to field %q", ErrInvalidFilter, op, field)
+	}
+
+	var (
+		value any
+		err   error
+	)
+	switch typ {
+	case TypeString:
+		value = raw
+	case TypeInt:
+		value, err = strconv.ParseInt(raw, 10, 64)
+	case TypeFloat:
+		value, err = strconv.ParseFloat(raw, 64)
+	case TypeBool:
+		value, err = strconv.ParseBool(raw)
+	case TypeTime:
+		value, err = time.Parse(time.RFC3339Nano, raw)
+	}
+	if err != nil {
+		return Condition{}, fmt.Errorf("%w: invalid value %q for field %q", ErrInvalidFilter, raw, field)
+	}
+	return Condition{Field: field, Op: op, Value: value}, nil
+}
+
+func tokenizeFilter(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			quoted, err := strconv.QuotedPrefix(expr[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w: unterminated string at offset %d", ErrInvalidFilter, i)
+			}
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid string at offset %d", ErrInvalidFilter, i)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value})
+			i += len(quoted)
+		case isOperatorByte(c):
+			op := string(c)
+			if i+1 < len(expr) && expr[i+1] == '=' && c != '=' && c != '~' {
+				op += "="
+			}
+			switch Operator(op) {
+			case OpEq, OpNe, OpLt, OpLte, OpGt, OpGte, OpContains:
+			default:
+				return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, op)
+			}
+			tokens = append(tokens, token{kind: tokenOp, value: op})
+			i += len(op)
+		default:
+			start := i
+			for i < len(expr) && !isOperatorByte(expr[i]) && !strings.ContainsRune(" \t\n\r\"", rune(expr[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: expr[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+func isOperatorByte(c byte) bool {
+	return c == '=' || c == '!' || c == '<' || c == '>' || c == '~'
+}
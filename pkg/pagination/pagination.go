@@ -0,0 +1,210 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	microerrors "github.com/bang-go/micro/pkg/errors"
+)
+
+const (
+	defaultPageSize      = 20
+	defaultMaxPageSize   = 100
+	defaultMaxConditions = 16
+	defaultMaxPage       = 10000
+)
+
+// Parameter names shared by the gin and protobuf binders.
+const (
+	FieldPage      = "page"
+	FieldPageSize  = "page_size"
+	FieldPageToken = "page_token"
+	FieldOrderBy   = "order_by"
+	FieldFilter    = "filter"
+)
+
+var (
+	ErrNilConfig        = errors.New("pagination: config is required")
+	ErrInvalidConfig    = errors.New("pagination: invalid config")
+	ErrInvalidPage      = errors.New("pagination: invalid page")
+	ErrInvalidPageSize  = errors.New("pagination: invalid page size")
+	ErrInvalidPageToken = errors.New("pagination: invalid page token")
+	ErrInvalidSort      = errors.New("pagination: invalid sort")
+	ErrInvalidFilter    = errors.New("pagination: invalid filter")
+	ErrCursorDisabled   = errors.New("pagination: cursor secret is not configured")
+)
+
+type Config struct {
+	// DefaultSize applies when the request has no size; defaults to 20.
+	DefaultSize int
+	// MaxSize caps the page size; larger sizes are clamped. Defaults to 100.
+	MaxSize int
+	// MaxPage rejects deep offset pages; defaults to 10000.
+	MaxPage int
+	// SortFields is the allowlist for order_by. Empty disables sorting.
+	SortFields []string
+	// DefaultSort is used when order_by is empty, e.g. "-created_at,id".
+	DefaultSort string
+	// FilterFields maps the filterable fields to their value types. Empty
+	// disables filtering.
+	FilterFields map[string]FieldType
+	// MaxConditions caps the conditions in one filter; defaults to 16.
+	MaxConditions int
+	// CursorSecret signs page tokens. Empty disables cursor paging.
+	CursorSecret []byte
+}
+
+// Query is the raw input of a list request.
+type Query struct {
+	Page      int
+	PageSize  int
+	PageToken string
+	OrderBy   string
+	Filter    string
+}
+
+// Request is a validated list request. With a Cursor the caller pages by
+// keyset and ignores Page.
+type Request struct {
+	Page   int
+	Size   int
+	Cursor *Cursor
+	Sort   []SortField
+	Filter []Condition
+
+	orderBy string
+	filter  string
+}
+
+// Offset is the number of rows to skip, zero for cursor requests.
+func (r *Request) Offset() int {
+	if r.Cursor != nil {
+		return 0
+	}
+	return (r.Page - 1) * r.Size
+}
+
+// Limit is the page size.
+func (r *Request) Limit() int {
+	return r.Size
+}
+
+// OrderBy renders Sort as an SQL ORDER BY list such as "created_at DESC, id
+// ASC". Field names come from the allowlist, so they are safe to inline as
+// long as they are column names.
+func (r *Request) OrderBy() string {
+	parts := make([]string, 0, len(r.Sort))
+	for _, field := range r.Sort {
+		parts = append(parts, field.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Paginator parses list requests against one Config, so every endpoint of a
+// resource accepts the same parameters.
+type Paginator struct {
+	conf *Config
+	sort []SortField
+}
+
+func New(conf *Config) (*Paginator, error) {
+	if conf == nil {
+		return nil, ErrNilConfig
+	}
+	cloned := *conf
+	cloned.SortFields = slices.Clone(conf.SortFields)
+	cloned.CursorSecret = slices.Clone(conf.CursorSecret)
+	if conf.FilterFields != nil {
+		cloned.FilterFields = make(map[string]FieldType, len(conf.FilterFields))
+		for field, typ := range conf.FilterFields {
+			if typ < TypeString || typ > TypeTime {
+				return nil, fmt.Errorf("%w: unknown type for filter field %q", ErrInvalidConfig, field)
+			}
+			cloned.FilterFields[field] = typ
+		}
+	}
+	if cloned.DefaultSize <= 0 {
+		cloned.DefaultSize = defaultPageSize
+	}
+	if cloned.MaxSize <= 0 {
+		cloned.MaxSize = defaultMaxPageSize
+	}
+	if cloned.DefaultSize > cloned.MaxSize {
+		return nil, fmt.Errorf("%w: default size %d exceeds max size %d", ErrInvalidConfig, cloned.DefaultSize, cloned.MaxSize)
+	}
+	if cloned.MaxPage <= 0 {
+		cloned.MaxPage = defaultMaxPage
+	}
+	if cloned.MaxConditions <= 0 {
+		cloned.MaxConditions = defaultMaxConditions
+	}
+
+	p := &Paginator{conf: &cloned}
+	sort, err := p.parseSort(cloned.DefaultSort)
+	if err != nil {
+		return nil, fmt.Errorf("%w: default sort: %w", ErrInvalidConfig, err)
+	}
+	p.sort = sort
+	return p, nil
+}
+
+// Parse validates q. Failures are CodeInvalidArgument errors naming the
+// offending parameter and wrapping one of the ErrInvalid* values.
+func (p *Paginator) Parse(q Query) (*Request, error) {
+	req := &Request{Page: q.Page, Size: q.PageSize}
+	switch {
+	case req.Page < 0:
+		return nil, invalid(FieldPage, fmt.Errorf("%w: must not be negative", ErrInvalidPage))
+	case req.Page == 0:
+		req.Page = 1
+	case req.Page > p.conf.MaxPage:
+		return nil, invalid(FieldPage, fmt.Errorf("%w: must not exceed %d", ErrInvalidPage, p.conf.MaxPage))
+	}
+	switch {
+	case req.Size < 0:
+		return nil, invalid(FieldPageSize, fmt.Errorf("%w: must not be negative", ErrInvalidPageSize))
+	case req.Size == 0:
+		req.Size = p.conf.DefaultSize
+	default:
+		req.Size = min(req.Size, p.conf.MaxSize)
+	}
+
+	req.orderBy = strings.TrimSpace(q.OrderBy)
+	if req.orderBy == "" {
+		req.Sort = slices.Clone(p.sort)
+	} else {
+		sort, err := p.parseSort(req.orderBy)
+		if err != nil {
+			return nil, invalid(FieldOrderBy, err)
+		}
+		req.Sort = sort
+	}
+
+	req.filter = strings.TrimSpace(q.Filter)
+	if req.filter != "" {
+		conditions, err := p.parseFilter(req.filter)
+		if err != nil {
+			return nil, invalid(FieldFilter, err)
+		}
+		req.Filter = conditions
+	}
+
+	if token := strings.TrimSpace(q.PageToken); token != "" {
+		if q.Page > 1 {
+			return nil, invalid(FieldPageToken, fmt.Errorf("%w: cannot be combined with page", ErrInvalidPageToken))
+		}
+		cursor, err := p.decodeCursor(token, req)
+		if err != nil {
+			return nil, invalid(FieldPageToken, err)
+		}
+		req.Cursor = cursor
+	}
+	return req, nil
+}
+
+func invalid(field string, err error) *microerrors.Error {
+	return microerrors.Wrap(err, microerrors.CodeInvalidArgument, "").
+		WithFields(microerrors.FieldViolation{Field: field, Description: err.Error()})
+}
@@ -0,0 +1,227 @@
+package pagination
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	microerrors "github.com/bang-go/micro/pkg/errors"
+)
+
+func newTestPaginator(t *testing.T) *Paginator {
+	t.Helper()
+	p, err := New(&Config{
+		DefaultSize: 10,
+		MaxSize:     50,
+		SortFields:  []string{"created_at", "name", "id"},
+		DefaultSort: "-created_at,id",
+		FilterFields: map[string]FieldType{
+			"status":     TypeString,
+			"age":        TypeInt,
+			"score":      TypeFloat,
+			"vip":        TypeBool,
+			"created_at": TypeTime,
+		},
+		CursorSecret: []byte("secret"),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return p
+}
+
+func TestParseDefaultsAndClamps(t *testing.T) {
+	p := newTestPaginator(t)
+
+	req, err := p.Parse(Query{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if req.Page != 1 || req.Size != 10 || req.Offset() != 0 {
+		t.Fatalf("defaults = page %d size %d offset %d", req.Page, req.Size, req.Offset())
+	}
+	if got := req.OrderBy(); got != "created_at DESC, id ASC" {
+		t.Fatalf("OrderBy() = %q", got)
+	}
+
+	req, err = p.Parse(Query{Page: 3, PageSize: 500, OrderBy: "name desc, +id"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if req.Size != 50 || req.Offset() != 100 {
+		t.Fatalf("size %d offset %d, want 50 and 100", req.Size, req.Offset())
+	}
+	if want := []SortField{{Field: "name", Desc: true}, {Field: "id"}}; !slices.Equal(req.Sort, want) {
+		t.Fatalf("Sort = %+v, want %+v", req.Sort, want)
+	}
+}
+
+func TestParseRejectsInvalidInput(t *testing.T) {
+	p := newTestPaginator(t)
+	tests := []struct {
+		name  string
+		query Query
+		field string
+		want  error
+	}{
+		{"negative page", Query{Page: -1}, FieldPage, ErrInvalidPage},
+		{"deep page", Query{Page: defaultMaxPage + 1}, FieldPage, ErrInvalidPage},
+		{"negative size", Query{PageSize: -1}, FieldPageSize, ErrInvalidPageSize},
+		{"unknown sort field", Query{OrderBy: "password"}, FieldOrderBy, ErrInvalidSort},
+		{"duplicate sort field", Query{OrderBy: "name,-name"}, FieldOrderBy, ErrInvalidSort},
+		{"bad direction", Query{OrderBy: "name sideways"}, FieldOrderBy, ErrInvalidSort},
+		{"unknown filter field", Query{Filter: `password = "x"`}, FieldFilter, ErrInvalidFilter},
+		{"bad int", Query{Filter: "age > old"}, FieldFilter, ErrInvalidFilter},
+		{"contains on int", Query{Filter: "age ~ 1"}, FieldFilter, ErrInvalidFilter},
+		{"ordering on bool", Query{Filter: "vip > true"}, FieldFilter, ErrInvalidFilter},
+		{"missing AND", Query{Filter: "age > 1 vip = true"}, FieldFilter, ErrInvalidFilter},
+		{"incomplete", Query{Filter: "age >"}, FieldFilter, ErrInvalidFilter},
+		{"unterminated string", Query{Filter: `status = "act`}, FieldFilter, ErrInvalidFilter},
+		{"unknown operator", Query{Filter: "age == 1"}, FieldFilter, ErrInvalidFilter},
+		{"garbage token", Query{PageToken: "abc"}, FieldPageToken, ErrInvalidPageToken},
+		{"token with page", Query{Page: 2, PageToken: "abc"}, FieldPageToken, ErrInvalidPageToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.Parse(tt.query)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.want)
+			}
+			coded := microerrors.FromError(err)
+			if coded.Code != microerrors.CodeInvalidArgument || len(coded.Fields) != 1 || coded.Fields[0].Field != tt.field {
+				t.Fatalf("Parse() error = %#v, want INVALID_ARGUMENT on %s", coded, tt.field)
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	p := newTestPaginator(t)
+	req, err := p.Parse(Query{Filter: `status = "on hold" and age>=18 AND score < -1.5 AND vip != false AND created_at > 2024-01-02T03:04:05+08:00 AND status ~ hold`})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Condition{
+		{Field: "status", Op: OpEq, Value: "on hold"},
+		{Field: "age", Op: OpGte, Value: int64(18)},
+		{Field: "score", Op: OpLt, Value: -1.5},
+		{Field: "vip", Op: OpNe, Value: false},
+		{Field: "created_at", Op: OpGt, Value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 8*3600))},
+		{Field: "status", Op: OpContains, Value: "hold"},
+	}
+	if len(req.Filter) != len(want) {
+		t.Fatalf("Filter = %+v", req.Filter)
+	}
+	for i, condition := range req.Filter {
+		got, expected := condition, want[i]
+		if gotTime, ok := got.Value.(time.Time); ok {
+			if !gotTime.Equal(expected.Value.(time.Time)) {
+				t.Fatalf("condition %d = %+v, want %+v", i, got, expected)
+			}
+			continue
+		}
+		if got != expected {
+			t.Fatalf("condition %d = %+v, want %+v", i, got, expected)
+		}
+	}
+}
+
+func TestParseLimitsConditions(t *testing.T) {
+	p, err := New(&Config{FilterFields: map[string]FieldType{"age": TypeInt}, MaxConditions: 2})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := p.Parse(Query{Filter: "age > 1 AND age < 9"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if _, err := p.Parse(Query{Filter: "age > 1 AND age < 9 AND age != 5"}); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("Parse() error = %v, want ErrInvalidFilter", err)
+	}
+	if _, err := p.Parse(Query{OrderBy: "age"}); !errors.Is(err, ErrInvalidSort) {
+		t.Fatalf("Parse() error = %v, want ErrInvalidSort without an allowlist", err)
+	}
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	if _, err := New(nil); !errors.Is(err, ErrNilConfig) {
+		t.Fatalf("New(nil) error = %v", err)
+	}
+	if _, err := New(&Config{SortFields: []string{"id"}, DefaultSort: "name"}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("New() error = %v, want ErrInvalidConfig for a default sort outside the allowlist", err)
+	}
+	if _, err := New(&Config{DefaultSize: 200, MaxSize: 100}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("New() error = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	p := newTestPaginator(t)
+	query := Query{PageSize: 5, Filter: "age > 1"}
+	first, err := p.Parse(query)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	createdAt := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	token, err := p.NextPageToken(first, createdAt, int64(42))
+	if err != nil {
+		t.Fatalf("NextPageToken() error = %v", err)
+	}
+
+	query.PageToken = token
+	next, err := p.Parse(query)
+	if err != nil {
+		t.Fatalf("Parse(token) error = %v", err)
+	}
+	if next.Cursor == nil || next.Offset() != 0 {
+		t.Fatalf("cursor request = %+v", next)
+	}
+	var (
+		gotCreatedAt time.Time
+		gotID        int64
+	)
+	if err := next.Cursor.Scan(&gotCreatedAt, &gotID); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !gotCreatedAt.Equal(createdAt) || gotID != 42 {
+		t.Fatalf("Scan() = %v, %d", gotCreatedAt, gotID)
+	}
+
+	for name, changed := range map[string]Query{
+		"order_by": {PageToken: token, Filter: query.Filter, OrderBy: "name"},
+		"filter":   {PageToken: token, Filter: "age > 2"},
+		"tampered": {PageToken: token[:len(token)-2] + "xx", Filter: query.Filter},
+	} {
+		if _, err := p.Parse(changed); !errors.Is(err, ErrInvalidPageToken) {
+			t.Fatalf("%s: Parse() error = %v, want ErrInvalidPageToken", name, err)
+		}
+	}
+
+	other, err := New(&Config{SortFields: []string{"created_at", "id"}, DefaultSort: "-created_at,id", FilterFields: map[string]FieldType{"age": TypeInt}, CursorSecret: []byte("other")})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := other.Parse(query); !errors.Is(err, ErrInvalidPageToken) {
+		t.Fatalf("Parse() with another secret error = %v, want ErrInvalidPageToken", err)
+	}
+}
+
+func TestCursorRequiresSecret(t *testing.T) {
+	p, err := New(&Config{SortFields: []string{"id"}, DefaultSort: "id"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	req, _ := p.Parse(Query{})
+	if _, err := p.NextPageToken(req, 1); !errors.Is(err, ErrCursorDisabled) {
+		t.Fatalf("NextPageToken() error = %v, want ErrCursorDisabled", err)
+	}
+	if _, err := p.Parse(Query{PageToken: "x.y"}); !errors.Is(err, ErrCursorDisabled) {
+		t.Fatalf("Parse() error = %v, want ErrCursorDisabled", err)
+	}
+
+	p = newTestPaginator(t)
+	req, _ = p.Parse(Query{})
+	if _, err := p.NextPageToken(req, 1); !errors.Is(err, ErrInvalidPageToken) {
+		t.Fatalf("NextPageToken() with too few values error = %v", err)
+	}
+}
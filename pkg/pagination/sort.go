@@ -0,0 +1,92 @@
+package pagination
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// String renders the field as "name ASC" or "name DESC".
+func (f SortField) String() string {
+	if f.Desc {
+		return f.Field + " DESC"
+	}
+	return f.Field + " ASC"
+}
+
+// parseSort accepts comma separated fields, each either prefixed with "-"
+// (descending) or "+", or followed by "asc" / "desc":
+// "-created_at,name" and "created_at desc, name" are equivalent.
+func (p *Paginator) parseSort(expr string) ([]SortField, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil
+	}
+	if len(p.conf.SortFields) == 0 {
+		return nil, fmt.Errorf("%w: sorting is not supported", ErrInvalidSort)
+	}
+
+	var fields []SortField
+	for part := range strings.SplitSeq(expr, ",") {
+		field, err := parseSortField(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(p.conf.SortFields, field.Field) {
+			return nil, fmt.Errorf("%w: field %q is not sortable", ErrInvalidSort, field.Field)
+		}
+		if slices.ContainsFunc(fields, func(f SortField) bool { return f.Field == field.Field }) {
+			return nil, fmt.Errorf("%w: field %q is listed twice", ErrInvalidSort, field.Field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func parseSortField(part string) (SortField, error) {
+	var field SortField
+	words := strings.Fields(part)
+	switch len(words) {
+	case 1:
+		field.Field = words[0]
+		switch field.Field[0] {
+		case '-':
+			field.Desc = true
+			field.Field = field.Field[1:]
+		case '+':
+			field.Field = field.Field[1:]
+		}
+	case 2:
+		field.Field = words[0]
+		switch strings.ToLower(words[1]) {
+		case "asc":
+		case "desc":
+			field.Desc = true
+		default:
+			return field, fmt.Errorf("%w: unknown direction %q", ErrInvalidSort, words[1])
+		}
+	default:
+		return field, fmt.Errorf("%w: malformed field %q", ErrInvalidSort, part)
+	}
+	if field.Field == "" {
+		return field, fmt.Errorf("%w: empty field", ErrInvalidSort)
+	}
+	return field, nil
+}
+
+func formatSort(fields []SortField) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Desc {
+			parts = append(parts, "-"+field.Field)
+		} else {
+			parts = append(parts, field.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
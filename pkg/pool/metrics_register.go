@@ -0,0 +1,73 @@
+package pool
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	tasks    *prometheus.CounterVec
+	panics   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+var (
+	defaultMetricsOnce sync.Once
+	defaultMetrics     *metrics
+)
+
+func defaultPoolMetrics() *metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = newPoolMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetrics
+}
+
+func newPoolMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		tasks: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "pool_tasks_total",
+				Help: "Total number of pool tasks run.",
+			},
+			[]string{"pool", "task"},
+		),
+		panics: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "pool_panics_total",
+				Help: "Total number of pool tasks that panicked.",
+			},
+			[]string{"pool", "task"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "pool_task_duration_seconds",
+				Help:    "Pool task run duration in seconds.",
+				Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+			},
+			[]string{"pool", "task"},
+		),
+	}
+
+	mustRegisterCollector(registerer, &m.tasks, m.tasks)
+	mustRegisterCollector(registerer, &m.panics, m.panics)
+	mustRegisterCollector(registerer, &m.duration, m.duration)
+
+	return m
+}
+
+func mustRegisterCollector[T prometheus.Collector](registerer prometheus.Registerer, dst *T, collector T) {
+	if registerer == nil {
+		return
+	}
+	if err := registerer.Register(collector); err != nil {
+		if alreadyRegistered, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if registered, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				*dst = registered
+				return
+			}
+		}
+		panic(err)
+	}
+}
@@ -2,6 +2,7 @@ package pool
 
 import (
 	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Option defines a functional option for the Pool.
@@ -12,6 +13,11 @@ type options struct {
 	logger       *logger.Logger
 	nonBlocking  bool
 	queueSize    int
+	name         string
+
+	metrics           bool
+	metricsRegisterer prometheus.Registerer
+	taskNames         map[string]struct{}
 }
 
 // WithPanicHandler sets a callback for when a worker panics.
@@ -44,3 +50,33 @@ func WithQueueSize(size int) Option {
 		o.queueSize = size
 	}
 }
+
+// WithName sets the pool label used in panic logs and metrics.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithMetrics records pool_tasks_total, pool_panics_total and
+// pool_task_duration_seconds per pool and task name. A nil registerer uses
+// prometheus.DefaultRegisterer. Metrics are off by default to keep Submit
+// free of timing overhead.
+func WithMetrics(registerer prometheus.Registerer) Option {
+	return func(o *options) {
+		o.metrics = true
+		o.metricsRegisterer = registerer
+	}
+}
+
+// WithTaskNames bounds the task label of the metrics: names outside the list
+// are counted as "other". Panic logs still carry the real name. Without it
+// every SubmitNamed name becomes a label value, so names must be static.
+func WithTaskNames(names ...string) Option {
+	return func(o *options) {
+		o.taskNames = make(map[string]struct{}, len(names))
+		for _, name := range names {
+			o.taskNames[name] = struct{}{}
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package pool
 
 import (
-	"container/list"
 	"context"
 	"errors"
 	"fmt"
@@ -9,10 +8,16 @@ import (
 	"runtime/debug"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/bang-go/micro/telemetry/logger"
 )
 
+const (
+	unnamedTask = "unnamed"
+	otherTask   = "other"
+)
+
 var (
 	ErrContextRequired = errors.New("pool: context is required")
 	ErrPoolClosed      = errors.New("pool: closed")
@@ -23,6 +28,11 @@ var (
 type Pool interface {
 	Submit(task func()) error
 	SubmitContext(context.Context, func()) error
+	// SubmitNamed labels the task in panic logs and metrics, e.g.
+	// "resize_image", so workloads sharing a pool can be told apart. The
+	// name is a metric label: keep it static, or bound it with WithTaskNames.
+	SubmitNamed(ctx context.Context, name string, task func()) error
+	SubmitBatch(context.Context, []func()) error
 	Release()
 	Running() int
 	Pending() int
@@ -33,12 +43,15 @@ type Pool interface {
 type pool struct {
 	capacity int
 	options  *options
+	metrics  *metrics
 
-	mu       sync.RWMutex
-	cond     *sync.Cond
-	queue    *list.List
-	queueCap int
-	closed   bool
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	queue    []job
+	head     int
+	count    int
+	closed   atomic.Bool
 
 	wg      sync.WaitGroup
 	running int32
@@ -66,10 +79,16 @@ func New(size int, opts ...Option) (Pool, error) {
 	p := &pool{
 		capacity: size,
 		options:  config,
-		queue:    list.New(),
-		queueCap: config.queueSize,
+		queue:    make([]job, config.queueSize),
+	}
+	if config.metrics {
+		p.metrics = defaultPoolMetrics()
+		if config.metricsRegisterer != nil {
+			p.metrics = newPoolMetrics(config.metricsRegisterer)
+		}
 	}
-	p.cond = sync.NewCond(&p.mu)
+	p.notEmpty = sync.NewCond(&p.mu)
+	p.notFull = sync.NewCond(&p.mu)
 
 	p.wg.Add(size)
 	for i := 0; i < size; i++ {
@@ -84,63 +103,146 @@ func (p *pool) Submit(task func()) error {
 }
 
 func (p *pool) SubmitContext(ctx context.Context, task func()) error {
-	if task == nil {
+	return p.submit(ctx, job{fn: task})
+}
+
+func (p *pool) SubmitNamed(ctx context.Context, name string, task func()) error {
+	return p.submit(ctx, job{name: name, fn: task})
+}
+
+func (p *pool) submit(ctx context.Context, task job) error {
+	if task.fn == nil {
 		return ErrNilTask
 	}
 	if ctx == nil {
 		return ErrContextRequired
 	}
+	if p.closed.Load() {
+		return ErrPoolClosed
+	}
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 
 	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	if p.closed {
-		return ErrPoolClosed
+	// Fast path: room in the queue, no need to watch the context.
+	if p.count < len(p.queue) && !p.closed.Load() {
+		p.push(task)
+		p.mu.Unlock()
+		p.notEmpty.Signal()
+		return nil
 	}
+	p.mu.Unlock()
 
 	if p.options.nonBlocking {
-		if err := ctx.Err(); err != nil {
-			return err
+		if p.closed.Load() {
+			return ErrPoolClosed
 		}
-		if p.queue.Len() >= p.queueCap {
-			return ErrPoolFull
+		return ErrPoolFull
+	}
+	return p.submitSlow(ctx, []job{task})
+}
+
+// SubmitBatch enqueues tasks in order, taking the lock once per batch when
+// the queue has room. Nil tasks are rejected before anything is queued. In
+// non-blocking mode the whole batch must fit, otherwise ErrPoolFull is returned
+// and nothing is queued.
+func (p *pool) SubmitBatch(ctx context.Context, tasks []func()) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	for _, task := range tasks {
+		if task == nil {
+			return ErrNilTask
+		}
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	if p.closed.Load() {
+		return ErrPoolClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	if len(p.queue)-p.count >= len(tasks) && !p.closed.Load() {
+		for _, task := range tasks {
+			p.push(job{fn: task})
 		}
-		p.queue.PushBack(task)
-		p.cond.Signal()
+		p.mu.Unlock()
+		p.signalWorkers(len(tasks))
 		return nil
 	}
+	p.mu.Unlock()
+
+	if p.options.nonBlocking {
+		if p.closed.Load() {
+			return ErrPoolClosed
+		}
+		return ErrPoolFull
+	}
+	jobs := make([]job, len(tasks))
+	for i, task := range tasks {
+		jobs[i] = job{fn: task}
+	}
+	return p.submitSlow(ctx, jobs)
+}
 
+func (p *pool) submitSlow(ctx context.Context, tasks []job) error {
 	stop := context.AfterFunc(ctx, func() {
 		p.mu.Lock()
-		p.cond.Broadcast()
+		p.notFull.Broadcast()
 		p.mu.Unlock()
 	})
 	defer stop()
 
-	for !p.closed && p.queue.Len() >= p.queueCap && ctx.Err() == nil {
-		p.cond.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(tasks) > 0 {
+		for !p.closed.Load() && p.count >= len(p.queue) && ctx.Err() == nil {
+			p.notFull.Wait()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if p.closed.Load() {
+			return ErrPoolClosed
+		}
+
+		n := min(len(tasks), len(p.queue)-p.count)
+		for _, task := range tasks[:n] {
+			p.push(task)
+		}
+		tasks = tasks[n:]
+		p.signalWorkers(n)
 	}
+	return nil
+}
 
-	if err := ctx.Err(); err != nil {
-		return err
+func (p *pool) push(task job) {
+	p.queue[(p.head+p.count)%len(p.queue)] = task
+	p.count++
+}
+
+func (p *pool) signalWorkers(n int) {
+	if n >= p.capacity {
+		p.notEmpty.Broadcast()
+		return
 	}
-	if p.closed {
-		return ErrPoolClosed
+	for i := 0; i < n; i++ {
+		p.notEmpty.Signal()
 	}
-
-	p.queue.PushBack(task)
-	p.cond.Signal()
-	return nil
 }
 
 func (p *pool) Release() {
 	p.once.Do(func() {
 		p.mu.Lock()
-		p.closed = true
-		p.cond.Broadcast()
+		p.closed.Store(true)
+		p.notEmpty.Broadcast()
+		p.notFull.Broadcast()
 		p.mu.Unlock()
 		p.wg.Wait()
 	})
@@ -151,9 +253,9 @@ func (p *pool) Running() int {
 }
 
 func (p *pool) Pending() int {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-	return p.queue.Len()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.count
 }
 
 func (p *pool) Cap() int {
@@ -161,9 +263,7 @@ func (p *pool) Cap() int {
 }
 
 func (p *pool) IsClosed() bool {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-	return p.closed
+	return p.closed.Load()
 }
 
 func (p *pool) worker() {
@@ -178,41 +278,76 @@ func (p *pool) worker() {
 	}
 }
 
-func (p *pool) nextTask() (func(), bool) {
+func (p *pool) nextTask() (job, bool) {
 	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	for p.queue.Len() == 0 && !p.closed {
-		p.cond.Wait()
+	for p.count == 0 && !p.closed.Load() {
+		p.notEmpty.Wait()
 	}
-	if p.queue.Len() == 0 {
-		return nil, false
+	if p.count == 0 {
+		p.mu.Unlock()
+		return job{}, false
 	}
 
-	element := p.queue.Front()
-	task := element.Value.(func())
-	p.queue.Remove(element)
-	p.cond.Signal()
+	task := p.queue[p.head]
+	p.queue[p.head] = job{}
+	p.head = (p.head + 1) % len(p.queue)
+	p.count--
+	p.mu.Unlock()
+
+	p.notFull.Signal()
 	return task, true
 }
 
-func (p *pool) runTask(task func()) {
+func (p *pool) runTask(task job) {
 	atomic.AddInt32(&p.running, 1)
 	defer atomic.AddInt32(&p.running, -1)
 
+	name := task.name
+	if name == "" {
+		name = unnamedTask
+	}
+	var start time.Time
+	if p.metrics != nil {
+		start = time.Now()
+	}
 	defer func() {
-		if recovered := recover(); recovered != nil {
-			if p.options.panicHandler != nil {
-				p.options.panicHandler(recovered)
-				return
-			}
-			if p.options.logger != nil {
-				p.options.logger.Error(context.Background(), "pool worker panic", "panic", recovered, "stack", string(debug.Stack()))
-				return
+		recovered := recover()
+		if p.metrics != nil {
+			label := p.taskLabel(name)
+			p.metrics.tasks.WithLabelValues(p.options.name, label).Inc()
+			p.metrics.duration.WithLabelValues(p.options.name, label).Observe(time.Since(start).Seconds())
+			if recovered != nil {
+				p.metrics.panics.WithLabelValues(p.options.name, label).Inc()
 			}
-			slog.Error("pool worker panic", "panic", recovered, "stack", string(debug.Stack()))
 		}
+		if recovered == nil {
+			return
+		}
+		if p.options.panicHandler != nil {
+			p.options.panicHandler(recovered)
+			return
+		}
+		if p.options.logger != nil {
+			p.options.logger.Error(context.Background(), "pool worker panic", "pool", p.options.name, "task", name, "panic", recovered, "stack", string(debug.Stack()))
+			return
+		}
+		slog.Error("pool worker panic", "pool", p.options.name, "task", name, "panic", recovered, "stack", string(debug.Stack()))
 	}()
 
-	task()
+	task.fn()
+}
+
+func (p *pool) taskLabel(name string) string {
+	if p.options.taskNames == nil || name == unnamedTask {
+		return name
+	}
+	if _, ok := p.options.taskNames[name]; ok {
+		return name
+	}
+	return otherTask
+}
+
+type job struct {
+	name string
+	fn   func()
 }
@@ -0,0 +1,118 @@
+package pool
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func benchmarkSubmit(b *testing.B, size int, queueSize int) {
+	p, err := New(size, WithQueueSize(queueSize))
+	if err != nil {
+		b.Fatalf("New() error = %v", err)
+	}
+	defer p.Release()
+
+	var wg sync.WaitGroup
+	task := func() { wg.Done() }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	wg.Add(b.N)
+	for i := 0; i < b.N; i++ {
+		if err := p.Submit(task); err != nil {
+			b.Fatalf("Submit() error = %v", err)
+		}
+	}
+	wg.Wait()
+}
+
+func BenchmarkSubmit(b *testing.B) {
+	b.Run("workers=1", func(b *testing.B) { benchmarkSubmit(b, 1, 1024) })
+	b.Run("workers=8", func(b *testing.B) { benchmarkSubmit(b, 8, 1024) })
+	b.Run("workers=gomaxprocs", func(b *testing.B) { benchmarkSubmit(b, runtime.GOMAXPROCS(0), 1024) })
+}
+
+func BenchmarkSubmitParallel(b *testing.B) {
+	p, err := New(runtime.GOMAXPROCS(0), WithQueueSize(4096))
+	if err != nil {
+		b.Fatalf("New() error = %v", err)
+	}
+	defer p.Release()
+
+	var wg sync.WaitGroup
+	task := func() { wg.Done() }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			wg.Add(1)
+			if err := p.SubmitContext(context.Background(), task); err != nil {
+				b.Fatalf("SubmitContext() error = %v", err)
+			}
+		}
+	})
+	wg.Wait()
+}
+
+func BenchmarkSubmitNonBlocking(b *testing.B) {
+	p, err := New(runtime.GOMAXPROCS(0), WithQueueSize(4096), WithNonBlocking(true))
+	if err != nil {
+		b.Fatalf("New() error = %v", err)
+	}
+	defer p.Release()
+
+	task := func() {}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = p.Submit(task)
+	}
+}
+
+func BenchmarkSubmitClosed(b *testing.B) {
+	p, err := New(1)
+	if err != nil {
+		b.Fatalf("New() error = %v", err)
+	}
+	p.Release()
+
+	task := func() {}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = p.Submit(task)
+		}
+	})
+}
+
+func BenchmarkSubmitBatch(b *testing.B) {
+	const batchSize = 64
+
+	p, err := New(runtime.GOMAXPROCS(0), WithQueueSize(4096))
+	if err != nil {
+		b.Fatalf("New() error = %v", err)
+	}
+	defer p.Release()
+
+	var wg sync.WaitGroup
+	batch := make([]func(), batchSize)
+	for i := range batch {
+		batch[i] = func() { wg.Done() }
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i += batchSize {
+		wg.Add(batchSize)
+		if err := p.SubmitBatch(context.Background(), batch); err != nil {
+			b.Fatalf("SubmitBatch() error = %v", err)
+		}
+	}
+	wg.Wait()
+}
@@ -1,12 +1,18 @@
 package pool
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestNew(t *testing.T) {
@@ -169,6 +175,97 @@ func TestPoolPanicHandler(t *testing.T) {
 	t.Fatal("expected panic handler to be invoked")
 }
 
+func TestPoolNamedTaskMetricsAndPanicLog(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	var logs syncBuffer
+	p, err := New(2,
+		WithName("media"),
+		WithMetrics(reg),
+		WithLogger(logger.New(logger.WithOutput(&logs), logger.WithFormat("json"))),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for range 3 {
+		if err := p.SubmitNamed(context.Background(), "resize", func() {}); err != nil {
+			t.Fatalf("SubmitNamed() error = %v", err)
+		}
+	}
+	if err := p.SubmitNamed(context.Background(), "transcode", func() { panic("boom") }); err != nil {
+		t.Fatalf("SubmitNamed() error = %v", err)
+	}
+	if err := p.Submit(func() {}); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	p.Release()
+
+	m := newPoolMetrics(reg)
+	for _, tc := range []struct {
+		counter *prometheus.CounterVec
+		task    string
+		want    float64
+	}{
+		{m.tasks, "resize", 3},
+		{m.tasks, "transcode", 1},
+		{m.tasks, unnamedTask, 1},
+		{m.panics, "transcode", 1},
+		{m.panics, "resize", 0},
+	} {
+		if got := testutil.ToFloat64(tc.counter.WithLabelValues("media", tc.task)); got != tc.want {
+			t.Fatalf("%s = %v, want %v", tc.task, got, tc.want)
+		}
+	}
+	if got := testutil.CollectAndCount(m.duration); got != 3 {
+		t.Fatalf("duration series = %d, want 3", got)
+	}
+
+	if out := logs.String(); !strings.Contains(out, `"pool":"media"`) || !strings.Contains(out, `"task":"transcode"`) {
+		t.Fatalf("panic log = %s", out)
+	}
+}
+
+func TestPoolTaskNamesBoundMetricLabels(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	p, err := New(1, WithName("jobs"), WithMetrics(reg), WithTaskNames("sync"), WithPanicHandler(func(any) {}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for _, name := range []string{"sync", "order-1", "order-2", ""} {
+		if err := p.SubmitNamed(context.Background(), name, func() {}); err != nil {
+			t.Fatalf("SubmitNamed(%q) error = %v", name, err)
+		}
+	}
+	p.Release()
+
+	m := newPoolMetrics(reg)
+	for task, want := range map[string]float64{"sync": 1, otherTask: 2, unnamedTask: 1} {
+		if got := testutil.ToFloat64(m.tasks.WithLabelValues("jobs", task)); got != want {
+			t.Fatalf("%s = %v, want %v", task, got, want)
+		}
+	}
+	if got := testutil.CollectAndCount(m.tasks); got != 3 {
+		t.Fatalf("task series = %d, want 3", got)
+	}
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 func TestPoolPendingAndRunning(t *testing.T) {
 	p, err := New(1, WithQueueSize(4))
 	if err != nil {
@@ -259,3 +356,83 @@ func TestPoolReleaseUnblocksBlockingSubmit(t *testing.T) {
 		t.Fatal("Release() did not complete")
 	}
 }
+
+func TestPoolSubmitBatch(t *testing.T) {
+	p, err := New(2, WithQueueSize(2))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer p.Release()
+
+	var count int32
+	var wg sync.WaitGroup
+	tasks := make([]func(), 16)
+	for i := range tasks {
+		tasks[i] = func() {
+			atomic.AddInt32(&count, 1)
+			wg.Done()
+		}
+	}
+	wg.Add(len(tasks))
+
+	if err := p.SubmitBatch(context.Background(), tasks); err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+	wg.Wait()
+	if got := atomic.LoadInt32(&count); got != int32(len(tasks)) {
+		t.Fatalf("executed = %d, want %d", got, len(tasks))
+	}
+
+	if err := p.SubmitBatch(context.Background(), []func(){func() {}, nil}); !errors.Is(err, ErrNilTask) {
+		t.Fatalf("SubmitBatch(nil task) error = %v, want %v", err, ErrNilTask)
+	}
+	if err := p.SubmitBatch(nil, tasks); !errors.Is(err, ErrContextRequired) {
+		t.Fatalf("SubmitBatch(nil ctx) error = %v, want %v", err, ErrContextRequired)
+	}
+}
+
+func TestPoolSubmitBatchNonBlockingIsAllOrNothing(t *testing.T) {
+	p, err := New(1, WithQueueSize(2), WithNonBlocking(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer p.Release()
+
+	block := make(chan struct{})
+	defer close(block)
+	started := make(chan struct{})
+	if err := p.Submit(func() {
+		close(started)
+		<-block
+	}); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	<-started
+
+	noop := func() {}
+	if err := p.SubmitBatch(context.Background(), []func(){noop, noop, noop}); !errors.Is(err, ErrPoolFull) {
+		t.Fatalf("SubmitBatch() error = %v, want %v", err, ErrPoolFull)
+	}
+	if got := p.Pending(); got != 0 {
+		t.Fatalf("Pending() = %d, want 0 after rejected batch", got)
+	}
+	if err := p.SubmitBatch(context.Background(), []func(){noop, noop}); err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+}
+
+func TestPoolSubmitFastPathDoesNotAllocate(t *testing.T) {
+	p, err := New(1, WithQueueSize(1024))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer p.Release()
+
+	task := func() {}
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = p.Submit(task)
+	})
+	if allocs != 0 {
+		t.Fatalf("Submit allocations = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"context"
+	"sync"
+)
+
+type memoryRegistry struct {
+	mu       sync.Mutex
+	services map[string]map[string]*Instance
+	changed  map[string]chan struct{}
+}
+
+// NewMemory returns an in-process registry for tests and single-binary
+// deployments.
+func NewMemory() Registry {
+	return &memoryRegistry{
+		services: make(map[string]map[string]*Instance),
+		changed:  make(map[string]chan struct{}),
+	}
+}
+
+func (r *memoryRegistry) Register(_ context.Context, ins *Instance) error {
+	ins, err := Normalize(ins)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.services[ins.Name] == nil {
+		r.services[ins.Name] = make(map[string]*Instance)
+	}
+	r.services[ins.Name][ins.ID] = ins
+	r.notifyLocked(ins.Name)
+	return nil
+}
+
+func (r *memoryRegistry) Deregister(_ context.Context, ins *Instance) error {
+	ins, err := Normalize(ins)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.services[ins.Name][ins.ID]; ok {
+		delete(r.services[ins.Name], ins.ID)
+		r.notifyLocked(ins.Name)
+	}
+	return nil
+}
+
+func (r *memoryRegistry) Watch(ctx context.Context, name string) (Watcher, error) {
+	if name == "" {
+		return nil, ErrNameRequired
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &memoryWatcher{registry: r, name: name, ctx: ctx, cancel: cancel}, nil
+}
+
+// notifyLocked wakes every watcher of name by closing its change channel.
+func (r *memoryRegistry) notifyLocked(name string) {
+	if ch, ok := r.changed[name]; ok {
+		close(ch)
+		delete(r.changed, name)
+	}
+}
+
+func (r *memoryRegistry) snapshot(name string) ([]*Instance, <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	instances := make([]*Instance, 0, len(r.services[name]))
+	for _, ins := range r.services[name] {
+		cloned := *ins
+		instances = append(instances, &cloned)
+	}
+	ch, ok := r.changed[name]
+	if !ok {
+		ch = make(chan struct{})
+		r.changed[name] = ch
+	}
+	return Sort(instances), ch
+}
+
+type memoryWatcher struct {
+	registry *memoryRegistry
+	name     string
+	ctx      context.Context
+	cancel   context.CancelFunc
+	last     []*Instance
+	started  bool
+}
+
+func (w *memoryWatcher) Next() ([]*Instance, error) {
+	for {
+		instances, changed := w.registry.snapshot(w.name)
+		if !w.started || !Equal(instances, w.last) {
+			w.started = true
+			w.last = instances
+			return instances, nil
+		}
+		select {
+		case <-changed:
+		case <-w.ctx.Done():
+			return nil, ErrWatcherStopped
+		}
+	}
+}
+
+func (w *memoryWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInstanceRequired = errors.New("registry: instance is required")
+	ErrNameRequired     = errors.New("registry: service name is required")
+	ErrAddrRequired     = errors.New("registry: instance addr is required")
+	ErrWatcherStopped   = errors.New("registry: watcher stopped")
+)
+
+// Instance is one reachable endpoint of a service.
+type Instance struct {
+	// ID identifies the instance within its service; defaults to Addr.
+	ID   string
+	Name string
+	// Addr is the dialable host:port.
+	Addr     string
+	Metadata map[string]string
+}
+
+// Registry publishes instances and watches services. Register keeps the
+// instance alive, renewing its TTL or lease where the backend needs one, until
+// Deregister is called.
+type Registry interface {
+	Register(context.Context, *Instance) error
+	Deregister(context.Context, *Instance) error
+	Watch(ctx context.Context, name string) (Watcher, error)
+}
+
+// Watcher streams the full instance set of a service. The first Next returns
+// the current set; later calls block until it changes.
+type Watcher interface {
+	Next() ([]*Instance, error)
+	Stop() error
+}
+
+// Normalize validates an instance and returns a trimmed copy with ID defaulted.
+func Normalize(ins *Instance) (*Instance, error) {
+	if ins == nil {
+		return nil, ErrInstanceRequired
+	}
+	cloned := &Instance{
+		ID:       strings.TrimSpace(ins.ID),
+		Name:     strings.TrimSpace(ins.Name),
+		Addr:     strings.TrimSpace(ins.Addr),
+		Metadata: maps.Clone(ins.Metadata),
+	}
+	if cloned.Name == "" {
+		return nil, ErrNameRequired
+	}
+	if cloned.Addr == "" {
+		return nil, ErrAddrRequired
+	}
+	if cloned.ID == "" {
+		cloned.ID = cloned.Addr
+	}
+	return cloned, nil
+}
+
+// Sort orders instances by ID so watchers can compare snapshots cheaply.
+func Sort(instances []*Instance) []*Instance {
+	slices.SortFunc(instances, func(a, b *Instance) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return instances
+}
+
+// Equal reports whether two sorted snapshots hold the same instances.
+func Equal(a, b []*Instance) bool {
+	return slices.EqualFunc(a, b, func(x, y *Instance) bool {
+		return x.ID == y.ID && x.Name == y.Name && x.Addr == y.Addr && maps.Equal(x.Metadata, y.Metadata)
+	})
+}
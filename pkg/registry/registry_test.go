@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryRegistryWatch(t *testing.T) {
+	reg := NewMemory()
+	ctx := context.Background()
+
+	if err := reg.Register(ctx, &Instance{Name: "user", Addr: "10.0.0.1:9000"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	watcher, err := reg.Watch(ctx, "user")
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer watcher.Stop()
+
+	instances, err := watcher.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if len(instances) != 1 || instances[0].ID != "10.0.0.1:9000" {
+		t.Fatalf("initial instances = %+v", instances)
+	}
+
+	updates := make(chan []*Instance, 1)
+	go func() {
+		instances, err := watcher.Next()
+		if err == nil {
+			updates <- instances
+		}
+	}()
+	if err := reg.Register(ctx, &Instance{ID: "b", Name: "user", Addr: "10.0.0.2:9000"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	select {
+	case instances := <-updates:
+		if len(instances) != 2 || instances[0].ID != "10.0.0.1:9000" || instances[1].ID != "b" {
+			t.Fatalf("updated instances = %+v", instances)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("watcher did not observe the new instance")
+	}
+
+	if err := reg.Deregister(ctx, &Instance{Name: "user", Addr: "10.0.0.1:9000"}); err != nil {
+		t.Fatalf("Deregister() error = %v", err)
+	}
+	if instances, err := watcher.Next(); err != nil || len(instances) != 1 || instances[0].ID != "b" {
+		t.Fatalf("Next() after deregister = %+v, %v", instances, err)
+	}
+
+	stopped := make(chan error, 1)
+	go func() {
+		_, err := watcher.Next()
+		stopped <- err
+	}()
+	_ = watcher.Stop()
+	select {
+	case err := <-stopped:
+		if !errors.Is(err, ErrWatcherStopped) {
+			t.Fatalf("Next() after Stop error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not unblock Next")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if _, err := Normalize(nil); !errors.Is(err, ErrInstanceRequired) {
+		t.Fatalf("Normalize(nil) error = %v", err)
+	}
+	if _, err := Normalize(&Instance{Addr: "a:1"}); !errors.Is(err, ErrNameRequired) {
+		t.Fatalf("Normalize() without name error = %v", err)
+	}
+	if _, err := Normalize(&Instance{Name: "svc"}); !errors.Is(err, ErrAddrRequired) {
+		t.Fatalf("Normalize() without addr error = %v", err)
+	}
+
+	metadata := map[string]string{"zone": "a"}
+	ins, err := Normalize(&Instance{Name: " svc ", Addr: " a:1 ", Metadata: metadata})
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	metadata["zone"] = "b"
+	if ins.Name != "svc" || ins.ID != "a:1" || ins.Metadata["zone"] != "a" {
+		t.Fatalf("Normalize() = %+v", ins)
+	}
+}
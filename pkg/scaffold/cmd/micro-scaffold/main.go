@@ -0,0 +1,39 @@
+// Command micro-scaffold generates a new service skeleton. It is meant to be
+// run directly or from a go:generate directive:
+//
+//	//go:generate go run github.com/bang-go/micro/pkg/scaffold/cmd/micro-scaffold -name order -module github.com/acme/order
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bang-go/micro/pkg/scaffold"
+)
+
+func main() {
+	var conf scaffold.Config
+	noHTTP := flag.Bool("no-http", false, "skip the ginx HTTP server")
+	noGRPC := flag.Bool("no-grpc", false, "skip the grpcx server")
+	flag.StringVar(&conf.Name, "name", "", "service name, e.g. order-center")
+	flag.StringVar(&conf.Module, "module", "", "go module path of the new service")
+	flag.StringVar(&conf.Dir, "dir", "", "output directory (default ./<name>)")
+	flag.StringVar(&conf.GoVersion, "go", "", "go directive for go.mod")
+	flag.StringVar(&conf.HTTPAddr, "http-addr", "", "default HTTP listen address")
+	flag.StringVar(&conf.GRPCAddr, "grpc-addr", "", "default gRPC listen address")
+	flag.BoolVar(&conf.Overwrite, "force", false, "overwrite existing files")
+	flag.Parse()
+
+	conf.DisableHTTP = *noHTTP
+	conf.DisableGRPC = *noGRPC
+
+	files, err := scaffold.Generate(&conf)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, file := range files {
+		fmt.Println(file)
+	}
+}
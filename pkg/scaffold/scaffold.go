@@ -0,0 +1,223 @@
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	defaultGoVersion = "1.25"
+	defaultHTTPAddr  = ":8080"
+	defaultGRPCAddr  = ":9090"
+)
+
+var (
+	ErrNameRequired   = errors.New("scaffold: service name is required")
+	ErrInvalidName    = errors.New("scaffold: service name must match [a-z][a-z0-9-]*")
+	ErrModuleRequired = errors.New("scaffold: module path is required")
+	ErrNoServer       = errors.New("scaffold: at least one of http or grpc must be enabled")
+	ErrFileExists     = errors.New("scaffold: file already exists")
+)
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+type Config struct {
+	Name   string
+	Module string
+	// Dir is the output directory; it defaults to ./<Name>.
+	Dir         string
+	GoVersion   string
+	HTTPAddr    string
+	GRPCAddr    string
+	DisableHTTP bool
+	DisableGRPC bool
+	Overwrite   bool
+}
+
+type File struct {
+	Path    string
+	Content []byte
+}
+
+type templateData struct {
+	Name        string
+	Title       string
+	Module      string
+	GoVersion   string
+	HTTP        bool
+	GRPC        bool
+	HTTPAddr    string
+	GRPCAddr    string
+	ServerCount int
+}
+
+type fileSpec struct {
+	path     string
+	template string
+	enabled  func(templateData) bool
+}
+
+var fileSpecs = []fileSpec{
+	{path: "go.mod", template: "go.mod.tmpl"},
+	{path: "main.go", template: "main.go.tmpl"},
+	{path: "conf/application.yaml", template: "application.yaml.tmpl"},
+	{path: "internal/config/config.go", template: "config.go.tmpl"},
+	{path: "internal/server/http.go", template: "http.go.tmpl", enabled: func(d templateData) bool { return d.HTTP }},
+	{path: "internal/server/grpc.go", template: "grpc.go.tmpl", enabled: func(d templateData) bool { return d.GRPC }},
+}
+
+// Render returns the service skeleton without touching the filesystem.
+// Paths are slash-separated and relative to the service root.
+func Render(conf *Config) ([]File, error) {
+	config, err := prepareConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	data := newTemplateData(config)
+	templates, err := template.ParseFS(templateFS, "templates/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("scaffold: parse templates: %w", err)
+	}
+
+	files := make([]File, 0, len(fileSpecs))
+	for _, spec := range fileSpecs {
+		if spec.enabled != nil && !spec.enabled(data) {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := templates.ExecuteTemplate(&buf, spec.template, data); err != nil {
+			return nil, fmt.Errorf("scaffold: render %s: %w", spec.path, err)
+		}
+		content := buf.Bytes()
+		if path.Ext(spec.path) == ".go" {
+			content, err = format.Source(content)
+			if err != nil {
+				return nil, fmt.Errorf("scaffold: format %s: %w", spec.path, err)
+			}
+		}
+		files = append(files, File{Path: spec.path, Content: content})
+	}
+	return files, nil
+}
+
+// Generate renders the skeleton and writes it under Config.Dir. Existing files
+// are left untouched unless Overwrite is set; the check runs before any write.
+func Generate(conf *Config) ([]string, error) {
+	config, err := prepareConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+	files, err := Render(config)
+	if err != nil {
+		return nil, err
+	}
+
+	written := make([]string, 0, len(files))
+	for _, file := range files {
+		written = append(written, filepath.Join(config.Dir, filepath.FromSlash(file.Path)))
+	}
+	if !config.Overwrite {
+		for _, target := range written {
+			if _, err := os.Stat(target); err == nil {
+				return nil, fmt.Errorf("%w: %s", ErrFileExists, target)
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+
+	for i, file := range files {
+		if err := os.MkdirAll(filepath.Dir(written[i]), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(written[i], file.Content, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return written, nil
+}
+
+func prepareConfig(conf *Config) (*Config, error) {
+	if conf == nil {
+		return nil, ErrNameRequired
+	}
+	config := *conf
+	config.Name = strings.TrimSpace(config.Name)
+	config.Module = strings.TrimSpace(config.Module)
+	config.Dir = strings.TrimSpace(config.Dir)
+	config.GoVersion = strings.TrimSpace(config.GoVersion)
+	config.HTTPAddr = strings.TrimSpace(config.HTTPAddr)
+	config.GRPCAddr = strings.TrimSpace(config.GRPCAddr)
+
+	if config.Name == "" {
+		return nil, ErrNameRequired
+	}
+	if !namePattern.MatchString(config.Name) {
+		return nil, ErrInvalidName
+	}
+	if config.Module == "" {
+		return nil, ErrModuleRequired
+	}
+	if config.DisableHTTP && config.DisableGRPC {
+		return nil, ErrNoServer
+	}
+	if config.Dir == "" {
+		config.Dir = config.Name
+	}
+	if config.GoVersion == "" {
+		config.GoVersion = defaultGoVersion
+	}
+	if config.HTTPAddr == "" {
+		config.HTTPAddr = defaultHTTPAddr
+	}
+	if config.GRPCAddr == "" {
+		config.GRPCAddr = defaultGRPCAddr
+	}
+	return &config, nil
+}
+
+func newTemplateData(conf *Config) templateData {
+	data := templateData{
+		Name:      conf.Name,
+		Title:     titleCase(conf.Name),
+		Module:    conf.Module,
+		GoVersion: conf.GoVersion,
+		HTTP:      !conf.DisableHTTP,
+		GRPC:      !conf.DisableGRPC,
+		HTTPAddr:  conf.HTTPAddr,
+		GRPCAddr:  conf.GRPCAddr,
+	}
+	if data.HTTP {
+		data.ServerCount++
+	}
+	if data.GRPC {
+		data.ServerCount++
+	}
+	return data
+}
+
+func titleCase(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "-") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
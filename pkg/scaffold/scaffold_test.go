@@ -0,0 +1,126 @@
+package scaffold_test
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bang-go/micro/pkg/scaffold"
+)
+
+func renderedFiles(t *testing.T, conf *scaffold.Config) map[string]string {
+	t.Helper()
+
+	files, err := scaffold.Render(conf)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out := make(map[string]string, len(files))
+	for _, file := range files {
+		out[file.Path] = string(file.Content)
+		if strings.HasSuffix(file.Path, ".go") {
+			if _, err := parser.ParseFile(token.NewFileSet(), file.Path, file.Content, parser.AllErrors); err != nil {
+				t.Fatalf("%s does not parse: %v", file.Path, err)
+			}
+		}
+	}
+	return out
+}
+
+func TestRenderFullSkeleton(t *testing.T) {
+	files := renderedFiles(t, &scaffold.Config{Name: "order-center", Module: "example.com/order"})
+
+	for _, path := range []string{"go.mod", "main.go", "conf/application.yaml", "internal/config/config.go", "internal/server/http.go", "internal/server/grpc.go"} {
+		if _, ok := files[path]; !ok {
+			t.Fatalf("Render() missing %s", path)
+		}
+	}
+	if !strings.HasPrefix(files["go.mod"], "module example.com/order\n") {
+		t.Fatalf("go.mod = %q", files["go.mod"])
+	}
+	for _, want := range []string{
+		`"example.com/order/internal/server"`,
+		`"github.com/bang-go/micro/transport/ginx"`,
+		`"github.com/bang-go/micro/transport/grpcx"`,
+		"trace.InitTracer",
+		"shutdown.New(",
+		"make(chan error, 2)",
+	} {
+		if !strings.Contains(files["main.go"], want) {
+			t.Fatalf("main.go missing %q", want)
+		}
+	}
+	if !strings.Contains(files["internal/server/grpc.go"], "RegisterOrderCenterServer") {
+		t.Fatalf("grpc.go = %q, want title-cased service name", files["internal/server/grpc.go"])
+	}
+	if !strings.Contains(files["conf/application.yaml"], `addr: ":8080"`) {
+		t.Fatalf("application.yaml = %q", files["conf/application.yaml"])
+	}
+}
+
+func TestRenderGRPCOnly(t *testing.T) {
+	files := renderedFiles(t, &scaffold.Config{Name: "billing", Module: "example.com/billing", DisableHTTP: true, GRPCAddr: ":7000"})
+
+	if _, ok := files["internal/server/http.go"]; ok {
+		t.Fatal("Render() generated http.go with DisableHTTP")
+	}
+	if strings.Contains(files["main.go"], "ginx") || strings.Contains(files["internal/config/config.go"], "HTTP") {
+		t.Fatal("Render() referenced HTTP with DisableHTTP")
+	}
+	if !strings.Contains(files["internal/config/config.go"], `"grpc.addr", ":7000"`) {
+		t.Fatalf("config.go = %q, want custom grpc addr", files["internal/config/config.go"])
+	}
+}
+
+func TestRenderValidation(t *testing.T) {
+	tests := []struct {
+		conf *scaffold.Config
+		want error
+	}{
+		{conf: nil, want: scaffold.ErrNameRequired},
+		{conf: &scaffold.Config{Name: "Order", Module: "x"}, want: scaffold.ErrInvalidName},
+		{conf: &scaffold.Config{Name: "order"}, want: scaffold.ErrModuleRequired},
+		{conf: &scaffold.Config{Name: "order", Module: "x", DisableHTTP: true, DisableGRPC: true}, want: scaffold.ErrNoServer},
+	}
+	for _, tt := range tests {
+		if _, err := scaffold.Render(tt.conf); !errors.Is(err, tt.want) {
+			t.Fatalf("Render(%+v) error = %v, want %v", tt.conf, err, tt.want)
+		}
+	}
+}
+
+func TestGenerateRefusesToOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	conf := &scaffold.Config{Name: "order", Module: "example.com/order", Dir: dir}
+
+	written, err := scaffold.Generate(conf)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(written) != 6 {
+		t.Fatalf("Generate() wrote %d files, want 6", len(written))
+	}
+
+	mainPath := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(mainPath, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scaffold.Generate(conf); !errors.Is(err, scaffold.ErrFileExists) {
+		t.Fatalf("Generate() error = %v, want %v", err, scaffold.ErrFileExists)
+	}
+	if data, _ := os.ReadFile(mainPath); string(data) != "package main\n" {
+		t.Fatal("Generate() modified an existing file")
+	}
+
+	conf.Overwrite = true
+	if _, err := scaffold.Generate(conf); err != nil {
+		t.Fatalf("Generate() with Overwrite error = %v", err)
+	}
+	if data, _ := os.ReadFile(mainPath); !strings.Contains(string(data), "func run(") {
+		t.Fatal("Generate() with Overwrite did not rewrite main.go")
+	}
+}
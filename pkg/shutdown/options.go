@@ -0,0 +1,40 @@
+package shutdown
+
+import (
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+)
+
+// Option configures a Registry.
+type Option func(*options)
+
+type options struct {
+	timeout time.Duration
+	logger  *logger.Logger
+}
+
+// WithTimeout bounds a Shutdown whose context has no deadline. Defaults to
+// 30s.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithLogger logs every hook with its duration and error.
+func WithLogger(log *logger.Logger) Option {
+	return func(o *options) {
+		o.logger = log
+	}
+}
+
+// HookOption configures one hook.
+type HookOption func(*hook)
+
+// WithHookTimeout bounds one hook within the overall shutdown deadline.
+func WithHookTimeout(timeout time.Duration) HookOption {
+	return func(h *hook) {
+		h.timeout = timeout
+	}
+}
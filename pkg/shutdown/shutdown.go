@@ -0,0 +1,233 @@
+// Package shutdown runs registered cleanup hooks in dependency order when a
+// service stops.
+package shutdown
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Priority orders hooks: lower values run first, and hooks sharing a
+// priority run concurrently. The predefined stages leave room in between.
+type Priority int
+
+const (
+	// Servers stop accepting work and drain in-flight requests first.
+	Servers Priority = 100
+	// Consumers stop pulling messages and finish the ones in progress.
+	Consumers Priority = 200
+	// Stores close databases, caches and producers still used above.
+	Stores Priority = 300
+	// Telemetry flushes traces, metrics and logs last.
+	Telemetry Priority = 400
+)
+
+var (
+	ErrNameRequired    = errors.New("shutdown: hook name is required")
+	ErrHookRequired    = errors.New("shutdown: hook func is required")
+	ErrDuplicateHook   = errors.New("shutdown: hook already registered")
+	ErrContextRequired = errors.New("shutdown: context is required")
+	ErrShutdownStarted = errors.New("shutdown: already started")
+)
+
+// Func releases one resource. It should honor ctx; a hook still running when
+// its timeout ends is reported as failed and left to finish in the
+// background, so one stuck closer cannot hold up the rest.
+type Func func(context.Context) error
+
+type hook struct {
+	name     string
+	priority Priority
+	fn       Func
+	timeout  time.Duration
+	seq      int
+}
+
+// Registry collects cleanup hooks from the modules of a service.
+type Registry struct {
+	opts options
+
+	mu       sync.Mutex
+	hooks    []*hook
+	names    map[string]struct{}
+	started  bool
+	done     chan struct{}
+	err      error
+	stopOnce sync.Once
+}
+
+func New(opts ...Option) *Registry {
+	o := options{timeout: defaultTimeout}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	if o.timeout <= 0 {
+		o.timeout = defaultTimeout
+	}
+	return &Registry{opts: o, names: make(map[string]struct{}), done: make(chan struct{})}
+}
+
+// Register adds a hook. It fails once Shutdown has started, so a module
+// initialized during shutdown has to clean up by itself.
+func (r *Registry) Register(name string, priority Priority, fn Func, opts ...HookOption) error {
+	if name == "" {
+		return ErrNameRequired
+	}
+	if fn == nil {
+		return ErrHookRequired
+	}
+	h := &hook{name: name, priority: priority, fn: fn}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(h)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return fmt.Errorf("%w: cannot register %q", ErrShutdownStarted, name)
+	}
+	if _, ok := r.names[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateHook, name)
+	}
+	h.seq = len(r.hooks)
+	r.names[name] = struct{}{}
+	r.hooks = append(r.hooks, h)
+	return nil
+}
+
+func (r *Registry) MustRegister(name string, priority Priority, fn Func, opts ...HookOption) {
+	if err := r.Register(name, priority, fn, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterCloser registers closer.Close, for clients without a context-aware
+// shutdown.
+func (r *Registry) RegisterCloser(name string, priority Priority, closer io.Closer, opts ...HookOption) error {
+	if closer == nil {
+		return ErrHookRequired
+	}
+	return r.Register(name, priority, func(context.Context) error {
+		return closer.Close()
+	}, opts...)
+}
+
+// Shutdown runs the hooks by ascending priority and returns their errors
+// joined. Without a deadline on ctx the registry timeout, 30s by default,
+// bounds the whole run. Later calls wait for the first and return its result.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	r.stopOnce.Do(func() {
+		r.mu.Lock()
+		r.started = true
+		hooks := slices.Clone(r.hooks)
+		r.mu.Unlock()
+
+		r.err = r.run(ctx, hooks)
+		close(r.done)
+	})
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done is closed once Shutdown has run every hook.
+func (r *Registry) Done() <-chan struct{} {
+	return r.done
+}
+
+func (r *Registry) run(ctx context.Context, hooks []*hook) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.timeout)
+		defer cancel()
+	}
+	slices.SortFunc(hooks, func(a, b *hook) int {
+		return cmp.Or(cmp.Compare(a.priority, b.priority), cmp.Compare(a.seq, b.seq))
+	})
+
+	var errs []error
+	for stage := range chunkByPriority(hooks) {
+		stageErrs := make([]error, len(stage))
+		var wg sync.WaitGroup
+		for i, h := range stage {
+			wg.Go(func() {
+				stageErrs[i] = r.runHook(ctx, h)
+			})
+		}
+		wg.Wait()
+		errs = append(errs, stageErrs...)
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Registry) runHook(ctx context.Context, h *hook) error {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				result <- fmt.Errorf("panic: %v", recovered)
+			}
+		}()
+		result <- h.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	duration := time.Since(start)
+	if err != nil {
+		err = fmt.Errorf("shutdown: %s: %w", h.name, err)
+	}
+	if log := r.opts.logger; log != nil {
+		if err != nil {
+			log.Error(ctx, "shutdown_hook_failed", "hook", h.name, "priority", int(h.priority), "duration", duration.Seconds(), "error", err)
+		} else {
+			log.Info(ctx, "shutdown_hook_done", "hook", h.name, "priority", int(h.priority), "duration", duration.Seconds())
+		}
+	}
+	return err
+}
+
+// chunkByPriority yields runs of sorted hooks sharing a priority.
+func chunkByPriority(hooks []*hook) func(func([]*hook) bool) {
+	return func(yield func([]*hook) bool) {
+		for start := 0; start < len(hooks); {
+			end := start + 1
+			for end < len(hooks) && hooks[end].priority == hooks[start].priority {
+				end++
+			}
+			if !yield(hooks[start:end]) {
+				return
+			}
+			start = end
+		}
+	}
+}
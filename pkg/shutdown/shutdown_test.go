@@ -0,0 +1,158 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func TestShutdownRunsStagesInOrder(t *testing.T) {
+	r := New()
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(name string) Func {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, name)
+			return nil
+		}
+	}
+	r.MustRegister("trace", Telemetry, record("trace"))
+	r.MustRegister("mysql", Stores, record("mysql"))
+	r.MustRegister("http", Servers, record("http"))
+	r.MustRegister("grpc", Servers, record("grpc"))
+	r.MustRegister("kafka", Consumers, record("kafka"))
+	if err := r.RegisterCloser("redis", Stores+10, closerFunc(func() error {
+		return record("redis")(context.Background())
+	})); err != nil {
+		t.Fatalf("RegisterCloser() error = %v", err)
+	}
+
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if len(events) != 6 {
+		t.Fatalf("events = %v", events)
+	}
+	servers := events[:2]
+	if !(servers[0] == "http" && servers[1] == "grpc" || servers[0] == "grpc" && servers[1] == "http") {
+		t.Fatalf("servers stage = %v", servers)
+	}
+	if got := strings.Join(events[2:], ","); got != "kafka,mysql,redis,trace" {
+		t.Fatalf("later stages = %s", got)
+	}
+	select {
+	case <-r.Done():
+	default:
+		t.Fatal("Done() not closed after Shutdown")
+	}
+}
+
+func TestShutdownSamePriorityRunsConcurrently(t *testing.T) {
+	r := New()
+	release := make(chan struct{})
+	for _, name := range []string{"a", "b"} {
+		r.MustRegister(name, Servers, func(context.Context) error {
+			select {
+			case release <- struct{}{}:
+			case <-release:
+			}
+			return nil
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v, want hooks of one stage to run together", err)
+	}
+}
+
+func TestShutdownCollectsErrorsAndKeepsGoing(t *testing.T) {
+	r := New()
+	errBoom := errors.New("boom")
+	ran := false
+	r.MustRegister("broken", Servers, func(context.Context) error { return errBoom })
+	r.MustRegister("panics", Consumers, func(context.Context) error { panic("oops") })
+	r.MustRegister("stuck", Stores, func(context.Context) error {
+		select {}
+	}, WithHookTimeout(20*time.Millisecond))
+	r.MustRegister("flush", Telemetry, func(context.Context) error {
+		ran = true
+		return nil
+	})
+
+	err := r.Shutdown(context.Background())
+	if !ran {
+		t.Fatal("later stage skipped after failures")
+	}
+	if !errors.Is(err, errBoom) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	for _, want := range []string{"shutdown: broken:", "shutdown: panics: panic: oops", "shutdown: stuck:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("Shutdown() error = %q, missing %q", err, want)
+		}
+	}
+
+	if again := r.Shutdown(context.Background()); again != err {
+		t.Fatalf("second Shutdown() = %v, want the first result", again)
+	}
+}
+
+func TestShutdownAppliesRegistryTimeout(t *testing.T) {
+	r := New(WithTimeout(20 * time.Millisecond))
+	deadlines := make(chan time.Time, 1)
+	r.MustRegister("server", Servers, func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	start := time.Now()
+	if err := r.Shutdown(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if deadline := <-deadlines; deadline.IsZero() || time.Since(start) > time.Second {
+		t.Fatalf("hook deadline = %v, elapsed %v", deadline, time.Since(start))
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	r := New()
+	noop := func(context.Context) error { return nil }
+	if err := r.Register("", Servers, noop); !errors.Is(err, ErrNameRequired) {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := r.Register("x", Servers, nil); !errors.Is(err, ErrHookRequired) {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := r.RegisterCloser("x", Servers, nil); !errors.Is(err, ErrHookRequired) {
+		t.Fatalf("RegisterCloser() error = %v", err)
+	}
+	r.MustRegister("x", Servers, noop)
+	if err := r.Register("x", Stores, noop); !errors.Is(err, ErrDuplicateHook) {
+		t.Fatalf("Register() error = %v", err)
+	}
+	//nolint:staticcheck // nil context is the case under test
+	if err := r.Shutdown(nil); !errors.Is(err, ErrContextRequired) {
+		t.Fatalf("Shutdown(nil) error = %v", err)
+	}
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := r.Register("late", Servers, noop); !errors.Is(err, ErrShutdownStarted) {
+		t.Fatalf("Register() after Shutdown error = %v", err)
+	}
+}
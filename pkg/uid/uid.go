@@ -0,0 +1,227 @@
+package uid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxNode      = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+var DefaultSnowflakeEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	ErrInvalidNode  = errors.New("uid: snowflake node must be between 0 and 1023")
+	ErrInvalidEpoch = errors.New("uid: snowflake epoch must not be in the future")
+	ErrNotNumeric   = errors.New("uid: id is not numeric")
+)
+
+type Generator interface {
+	Generate() (ID, error)
+}
+
+type GeneratorFunc func() (ID, error)
+
+func (f GeneratorFunc) Generate() (ID, error) {
+	return f()
+}
+
+type ID struct {
+	text    string
+	bytes   []byte
+	number  int64
+	numeric bool
+}
+
+func (id ID) String() string {
+	return id.text
+}
+
+func (id ID) Bytes() []byte {
+	return append([]byte(nil), id.bytes...)
+}
+
+func (id ID) Int64() (int64, error) {
+	if !id.numeric {
+		return 0, ErrNotNumeric
+	}
+	return id.number, nil
+}
+
+func (id ID) IsZero() bool {
+	return id.text == "" && len(id.bytes) == 0 && !id.numeric
+}
+
+func UUIDv4() Generator {
+	return GeneratorFunc(func() (ID, error) {
+		value, err := uuid.NewRandom()
+		if err != nil {
+			return ID{}, err
+		}
+		return uuidID(value), nil
+	})
+}
+
+func UUIDv7() Generator {
+	return GeneratorFunc(func() (ID, error) {
+		value, err := uuid.NewV7()
+		if err != nil {
+			return ID{}, err
+		}
+		return uuidID(value), nil
+	})
+}
+
+func ULID() Generator {
+	return GeneratorFunc(func() (ID, error) {
+		return newULID(time.Now())
+	})
+}
+
+func NewUUID() string {
+	return uuid.NewString()
+}
+
+func NewULID() (string, error) {
+	id, err := newULID(time.Now())
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+type SnowflakeConfig struct {
+	Node  int64
+	Epoch time.Time
+
+	now func() time.Time
+}
+
+type Snowflake struct {
+	node  int64
+	epoch time.Time
+	now   func() time.Time
+
+	mu       sync.Mutex
+	last     int64
+	sequence int64
+}
+
+func NewSnowflake(conf *SnowflakeConfig) (*Snowflake, error) {
+	if conf == nil {
+		conf = &SnowflakeConfig{}
+	}
+	if conf.Node < 0 || conf.Node > snowflakeMaxNode {
+		return nil, ErrInvalidNode
+	}
+
+	now := conf.now
+	if now == nil {
+		now = time.Now
+	}
+	epoch := conf.Epoch
+	if epoch.IsZero() {
+		epoch = DefaultSnowflakeEpoch
+	}
+	if epoch.After(now()) {
+		return nil, ErrInvalidEpoch
+	}
+
+	return &Snowflake{
+		node:  conf.Node,
+		epoch: epoch,
+		now:   now,
+	}, nil
+}
+
+func (s *Snowflake) Next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.elapsed()
+	if current < s.last {
+		current = s.last
+	}
+	if current == s.last {
+		s.sequence = (s.sequence + 1) & snowflakeMaxSequence
+		if s.sequence == 0 {
+			for current <= s.last {
+				time.Sleep(100 * time.Microsecond)
+				current = s.elapsed()
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.last = current
+
+	return current<<(snowflakeNodeBits+snowflakeSequenceBits) | s.node<<snowflakeSequenceBits | s.sequence
+}
+
+func (s *Snowflake) Generate() (ID, error) {
+	value := s.Next()
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(value))
+	return ID{
+		text:    strconv.FormatInt(value, 10),
+		bytes:   buf,
+		number:  value,
+		numeric: true,
+	}, nil
+}
+
+func (s *Snowflake) elapsed() int64 {
+	return s.now().Sub(s.epoch).Milliseconds()
+}
+
+func uuidID(value uuid.UUID) ID {
+	return ID{
+		text:  value.String(),
+		bytes: append([]byte(nil), value[:]...),
+	}
+}
+
+func newULID(now time.Time) (ID, error) {
+	raw := make([]byte, 16)
+	ms := uint64(now.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		raw[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := rand.Read(raw[6:]); err != nil {
+		return ID{}, err
+	}
+	return ID{
+		text:  encodeCrockford(raw),
+		bytes: raw,
+	}, nil
+}
+
+func encodeCrockford(raw []byte) string {
+	out := make([]byte, 26)
+	var buffer uint64
+	bits := 2
+	index := 0
+	for _, b := range raw {
+		buffer = buffer<<8 | uint64(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[index] = crockfordAlphabet[(buffer>>uint(bits))&0x1f]
+			index++
+		}
+	}
+	return string(out)
+}
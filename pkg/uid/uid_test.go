@@ -0,0 +1,148 @@
+package uid
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestUUIDGenerators(t *testing.T) {
+	for name, generator := range map[string]Generator{"v4": UUIDv4(), "v7": UUIDv7()} {
+		id, err := generator.Generate()
+		if err != nil {
+			t.Fatalf("%s Generate() error = %v", name, err)
+		}
+		parsed, err := uuid.Parse(id.String())
+		if err != nil {
+			t.Fatalf("%s produced invalid uuid %q: %v", name, id.String(), err)
+		}
+		if string(id.Bytes()) != string(parsed[:]) {
+			t.Fatalf("%s bytes do not match text form", name)
+		}
+		if _, err := id.Int64(); !errors.Is(err, ErrNotNumeric) {
+			t.Fatalf("%s Int64() error = %v, want ErrNotNumeric", name, err)
+		}
+	}
+	if _, err := uuid.Parse(NewUUID()); err != nil {
+		t.Fatalf("NewUUID() produced invalid uuid: %v", err)
+	}
+}
+
+func TestULID(t *testing.T) {
+	at := time.UnixMilli(1700000000123)
+	first, err := newULID(at)
+	if err != nil {
+		t.Fatalf("newULID() error = %v", err)
+	}
+	second, err := newULID(at.Add(time.Millisecond))
+	if err != nil {
+		t.Fatalf("newULID() error = %v", err)
+	}
+
+	if len(first.String()) != 26 || strings.Trim(first.String(), crockfordAlphabet) != "" {
+		t.Fatalf("invalid ulid %q", first.String())
+	}
+	if first.String()[:10] >= second.String()[:10] {
+		t.Fatalf("expected ulid timestamp prefix to sort by time: %q vs %q", first.String(), second.String())
+	}
+	if len(first.Bytes()) != 16 {
+		t.Fatalf("expected 16 byte ulid, got %d", len(first.Bytes()))
+	}
+
+	if got := encodeCrockford(make([]byte, 16)); got != strings.Repeat("0", 26) {
+		t.Fatalf("encodeCrockford(zero) = %q", got)
+	}
+	max := make([]byte, 16)
+	for i := range max {
+		max[i] = 0xff
+	}
+	if got := encodeCrockford(max); got != "7"+strings.Repeat("Z", 25) {
+		t.Fatalf("encodeCrockford(max) = %q", got)
+	}
+
+	text, err := NewULID()
+	if err != nil || len(text) != 26 {
+		t.Fatalf("NewULID() = %q, %v", text, err)
+	}
+}
+
+func TestSnowflake(t *testing.T) {
+	if _, err := NewSnowflake(&SnowflakeConfig{Node: 1024}); !errors.Is(err, ErrInvalidNode) {
+		t.Fatalf("expected ErrInvalidNode, got %v", err)
+	}
+	if _, err := NewSnowflake(&SnowflakeConfig{Epoch: time.Now().Add(time.Hour)}); !errors.Is(err, ErrInvalidEpoch) {
+		t.Fatalf("expected ErrInvalidEpoch, got %v", err)
+	}
+
+	current := DefaultSnowflakeEpoch.Add(time.Second)
+	var mu sync.Mutex
+	flake, err := NewSnowflake(&SnowflakeConfig{
+		Node: 7,
+		now: func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return current
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSnowflake() error = %v", err)
+	}
+
+	first := flake.Next()
+	if node := (first >> snowflakeSequenceBits) & snowflakeMaxNode; node != 7 {
+		t.Fatalf("node bits = %d, want 7", node)
+	}
+	if ms := first >> (snowflakeNodeBits + snowflakeSequenceBits); ms != 1000 {
+		t.Fatalf("timestamp bits = %d, want 1000", ms)
+	}
+
+	mu.Lock()
+	current = current.Add(-time.Second)
+	mu.Unlock()
+	if second := flake.Next(); second <= first {
+		t.Fatalf("expected monotonic ids when clock moves backwards: %d <= %d", second, first)
+	}
+
+	id, err := flake.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	number, err := id.Int64()
+	if err != nil || id.String() == "" || len(id.Bytes()) != 8 || number <= first {
+		t.Fatalf("unexpected snowflake id %+v, %v", id, err)
+	}
+}
+
+func TestSnowflakeUniqueUnderConcurrency(t *testing.T) {
+	flake, err := NewSnowflake(nil)
+	if err != nil {
+		t.Fatalf("NewSnowflake() error = %v", err)
+	}
+
+	const workers, perWorker = 8, 2000
+	results := make(chan int64, workers*perWorker)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				results <- flake.Next()
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	seen := make(map[int64]struct{}, workers*perWorker)
+	for id := range results {
+		if _, ok := seen[id]; ok {
+			t.Fatalf("duplicate snowflake id %d", id)
+		}
+		seen[id] = struct{}{}
+	}
+}
@@ -0,0 +1,236 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+// Store is the byte-level contract implemented by cache drivers. Get returns
+// ErrNotFound on a miss; a ttl <= 0 in Set means the entry does not expire.
+// SetNX stores value only if key is absent, atomically, and reports whether
+// it did.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+}
+
+type LoadFunc func(context.Context) (any, error)
+
+type Config struct {
+	Name  string
+	Store Store
+	// Codec defaults to JSONCodec.
+	Codec Codec
+	// Prefix is prepended to every key, e.g. "order:".
+	Prefix string
+	// TTL applies when Set or GetOrLoad is called with ttl <= 0; zero means no expiry.
+	TTL time.Duration
+
+	DisableMetrics    bool
+	MetricsRegisterer prometheus.Registerer
+}
+
+type Cache interface {
+	Get(ctx context.Context, key string, dst any) error
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// GetOrLoad decodes a cached value into dst, or calls load on a miss and
+	// caches its result. Concurrent misses for the same key share one load.
+	GetOrLoad(ctx context.Context, key string, dst any, ttl time.Duration, load LoadFunc) error
+}
+
+type cacheEntity struct {
+	config  *Config
+	group   singleflight.Group
+	metrics *metrics
+}
+
+func New(conf *Config) (Cache, error) {
+	if conf == nil {
+		return nil, ErrNilConfig
+	}
+
+	config := *conf
+	config.Name = strings.TrimSpace(config.Name)
+	if config.Store == nil {
+		return nil, ErrStoreRequired
+	}
+	if config.Name == "" {
+		config.Name = "default"
+	}
+	if config.Codec == nil {
+		config.Codec = JSONCodec{}
+	}
+
+	var metrics *metrics
+	if !config.DisableMetrics {
+		metrics = defaultCacheMetrics()
+		if config.MetricsRegisterer != nil {
+			metrics = newCacheMetrics(config.MetricsRegisterer)
+		}
+	}
+
+	return &cacheEntity{config: &config, metrics: metrics}, nil
+}
+
+// Load is a typed wrapper around Cache.GetOrLoad.
+func Load[T any](ctx context.Context, c Cache, key string, ttl time.Duration, load func(context.Context) (T, error)) (T, error) {
+	var value T
+	if load == nil {
+		return value, ErrLoaderRequired
+	}
+	err := c.GetOrLoad(ctx, key, &value, ttl, func(ctx context.Context) (any, error) {
+		return load(ctx)
+	})
+	return value, err
+}
+
+func (c *cacheEntity) Get(ctx context.Context, key string, dst any) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	if key == "" {
+		return ErrKeyRequired
+	}
+
+	data, err := c.config.Store.Get(ctx, c.key(key))
+	if err != nil {
+		c.observe("get", lookupResult(err))
+		return err
+	}
+	if err := c.config.Codec.Unmarshal(data, dst); err != nil {
+		c.observe("get", "error")
+		return err
+	}
+	c.observe("get", "hit")
+	return nil
+}
+
+func (c *cacheEntity) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	if key == "" {
+		return ErrKeyRequired
+	}
+
+	data, err := c.config.Codec.Marshal(value)
+	if err != nil {
+		c.observe("set", "error")
+		return err
+	}
+	err = c.config.Store.Set(ctx, c.key(key), data, c.ttl(ttl))
+	c.observe("set", resultOf(err))
+	return err
+}
+
+func (c *cacheEntity) Delete(ctx context.Context, keys ...string) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			return ErrKeyRequired
+		}
+		prefixed = append(prefixed, c.key(key))
+	}
+	err := c.config.Store.Delete(ctx, prefixed...)
+	c.observe("delete", resultOf(err))
+	return err
+}
+
+func (c *cacheEntity) GetOrLoad(ctx context.Context, key string, dst any, ttl time.Duration, load LoadFunc) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	if key == "" {
+		return ErrKeyRequired
+	}
+	if load == nil {
+		return ErrLoaderRequired
+	}
+
+	storeKey := c.key(key)
+	data, err := c.config.Store.Get(ctx, storeKey)
+	switch {
+	case err == nil:
+		if err := c.config.Codec.Unmarshal(data, dst); err == nil {
+			c.observe("get", "hit")
+			return nil
+		}
+		// An undecodable entry is treated as a miss and overwritten.
+	case !errors.Is(err, ErrNotFound):
+		c.observe("get", "error")
+		return err
+	}
+	c.observe("get", "miss")
+
+	result, err, _ := c.group.Do(storeKey, func() (any, error) {
+		start := time.Now()
+		value, err := load(ctx)
+		c.observeLoad(time.Since(start), err)
+		if err != nil {
+			return nil, err
+		}
+		data, err := c.config.Codec.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		// The loaded value is still returned when the write fails.
+		c.observe("set", resultOf(c.config.Store.Set(ctx, storeKey, data, c.ttl(ttl))))
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+	return c.config.Codec.Unmarshal(result.([]byte), dst)
+}
+
+func (c *cacheEntity) key(key string) string {
+	return c.config.Prefix + key
+}
+
+func (c *cacheEntity) ttl(ttl time.Duration) time.Duration {
+	if ttl > 0 {
+		return ttl
+	}
+	return c.config.TTL
+}
+
+func (c *cacheEntity) observe(op, result string) {
+	if c.metrics != nil {
+		c.metrics.requestsTotal.WithLabelValues(c.config.Name, op, result).Inc()
+	}
+}
+
+func (c *cacheEntity) observeLoad(duration time.Duration, err error) {
+	if c.metrics != nil {
+		c.metrics.loadDuration.WithLabelValues(c.config.Name, resultOf(err)).Observe(duration.Seconds())
+	}
+}
+
+func lookupResult(err error) string {
+	if errors.Is(err, ErrNotFound) {
+		return "miss"
+	}
+	return "error"
+}
+
+func resultOf(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,259 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type recordingStore struct {
+	Store
+	mu   sync.Mutex
+	ttls map[string]time.Duration
+}
+
+func (s *recordingStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	s.ttls[key] = ttl
+	s.mu.Unlock()
+	return s.Store.Set(ctx, key, value, ttl)
+}
+
+func TestCacheCodecPrefixAndTTL(t *testing.T) {
+	store := &recordingStore{Store: NewMemory(nil), ttls: map[string]time.Duration{}}
+	c, err := New(&Config{Store: store, Prefix: "user:", TTL: time.Minute, DisableMetrics: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "1", user{ID: 1, Name: "alice"}, 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got := store.ttls["user:1"]; got != time.Minute {
+		t.Fatalf("stored ttl = %v, want default %v", got, time.Minute)
+	}
+
+	var got user
+	if err := c.Get(ctx, "1", &got); err != nil || got.Name != "alice" {
+		t.Fatalf("Get() = %+v, %v", got, err)
+	}
+	if raw, err := store.Get(ctx, "user:1"); err != nil || string(raw) != `{"id":1,"name":"alice"}` {
+		t.Fatalf("raw entry = %q, %v", raw, err)
+	}
+
+	if err := c.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := c.Get(ctx, "1", &got); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() after Delete error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestCacheGetOrLoadSharesConcurrentMisses(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c, err := New(&Config{Name: "users", Store: NewMemory(nil), MetricsRegisterer: reg})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (user, error) {
+		calls.Add(1)
+		<-release
+		return user{ID: 7, Name: "bob"}, nil
+	}
+
+	const callers = 8
+	var wg sync.WaitGroup
+	results := make([]user, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = Load(context.Background(), c, "7", time.Minute, load)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("loader calls = %d, want 1", got)
+	}
+	for _, result := range results {
+		if result.Name != "bob" {
+			t.Fatalf("result = %+v, want loaded user", result)
+		}
+	}
+
+	if _, err := Load(context.Background(), c, "7", time.Minute, func(context.Context) (user, error) {
+		t.Fatal("loader called on hit")
+		return user{}, nil
+	}); err != nil {
+		t.Fatalf("Load() hit error = %v", err)
+	}
+
+	m := newCacheMetrics(reg)
+	if got := testutil.ToFloat64(m.requestsTotal.WithLabelValues("users", "get", "hit")); got != 1 {
+		t.Fatalf("cache_requests_total{op=get,result=hit} = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.requestsTotal.WithLabelValues("users", "get", "miss")); got != callers {
+		t.Fatalf("cache_requests_total{op=get,result=miss} = %v, want %d", got, callers)
+	}
+}
+
+func TestCacheGetOrLoadDoesNotCacheErrors(t *testing.T) {
+	c, _ := New(&Config{Store: NewMemory(nil), DisableMetrics: true})
+	loadErr := errors.New("db down")
+
+	if _, err := Load(context.Background(), c, "k", 0, func(context.Context) (int, error) {
+		return 0, loadErr
+	}); !errors.Is(err, loadErr) {
+		t.Fatalf("Load() error = %v, want %v", err, loadErr)
+	}
+	got, err := Load(context.Background(), c, "k", 0, func(context.Context) (int, error) {
+		return 42, nil
+	})
+	if err != nil || got != 42 {
+		t.Fatalf("Load() = %d, %v, want 42", got, err)
+	}
+}
+
+func TestMemoryStoreEvictsAndExpires(t *testing.T) {
+	now := time.Unix(1000, 0)
+	store := NewMemory(&MemoryConfig{MaxEntries: 2, now: func() time.Time { return now }})
+	ctx := context.Background()
+
+	_ = store.Set(ctx, "a", []byte("1"), 0)
+	_ = store.Set(ctx, "b", []byte("2"), time.Second)
+	if _, err := store.Get(ctx, "a"); err != nil {
+		t.Fatalf("Get(a) error = %v", err)
+	}
+	_ = store.Set(ctx, "c", []byte("3"), 0)
+
+	if _, err := store.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(b) error = %v, want least recently used entry evicted", err)
+	}
+	if _, err := store.Get(ctx, "a"); err != nil {
+		t.Fatalf("Get(a) error = %v, want recently used entry kept", err)
+	}
+
+	_ = store.Set(ctx, "d", []byte("4"), time.Second)
+	now = now.Add(time.Second)
+	if _, err := store.Get(ctx, "d"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(d) error = %v, want expired", err)
+	}
+}
+
+func TestStoreSetNX(t *testing.T) {
+	now := time.Unix(1000, 0)
+	memory := NewMemory(&MemoryConfig{now: func() time.Time { return now }})
+	tiered, err := NewTiered(&TieredConfig{Local: NewMemory(nil), Remote: NewMemory(nil)})
+	if err != nil {
+		t.Fatalf("NewTiered() error = %v", err)
+	}
+	ctx := context.Background()
+
+	for name, store := range map[string]Store{"memory": memory, "tiered": tiered} {
+		if ok, err := store.SetNX(ctx, "k", []byte("1"), time.Second); err != nil || !ok {
+			t.Fatalf("%s: SetNX() = %v, %v, want stored", name, ok, err)
+		}
+		if ok, err := store.SetNX(ctx, "k", []byte("2"), time.Second); err != nil || ok {
+			t.Fatalf("%s: second SetNX() = %v, %v, want rejected", name, ok, err)
+		}
+		if data, err := store.Get(ctx, "k"); err != nil || string(data) != "1" {
+			t.Fatalf("%s: Get() = %q, %v, want first value kept", name, data, err)
+		}
+	}
+
+	now = now.Add(time.Second)
+	if ok, err := memory.SetNX(ctx, "k", []byte("3"), 0); err != nil || !ok {
+		t.Fatalf("SetNX() over an expired entry = %v, %v, want stored", ok, err)
+	}
+
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Go(func() {
+			if ok, _ := memory.SetNX(ctx, "race", []byte("x"), 0); ok {
+				wins.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+	if wins.Load() != 1 {
+		t.Fatalf("concurrent SetNX() winners = %d, want 1", wins.Load())
+	}
+}
+
+func TestTieredStoreReadsThroughAndBackfills(t *testing.T) {
+	local := &recordingStore{Store: NewMemory(nil), ttls: map[string]time.Duration{}}
+	remote := NewMemory(nil)
+	store, err := NewTiered(&TieredConfig{Local: local, Remote: remote, LocalTTL: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("NewTiered() error = %v", err)
+	}
+	ctx := context.Background()
+
+	_ = remote.Set(ctx, "k", []byte("v"), 0)
+	if data, err := store.Get(ctx, "k"); err != nil || string(data) != "v" {
+		t.Fatalf("Get() = %q, %v", data, err)
+	}
+	if local.ttls["k"] != 10*time.Second {
+		t.Fatalf("backfill ttl = %v, want LocalTTL", local.ttls["k"])
+	}
+
+	if err := store.Set(ctx, "short", []byte("x"), time.Second); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if local.ttls["short"] != time.Second {
+		t.Fatalf("local ttl = %v, want entry ttl below LocalTTL", local.ttls["short"])
+	}
+
+	if err := store.Delete(ctx, "k"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := local.Get(ctx, "k"); !errors.Is(err, ErrNotFound) {
+		t.Fatal("Delete() kept the local copy")
+	}
+	if _, err := remote.Get(ctx, "k"); !errors.Is(err, ErrNotFound) {
+		t.Fatal("Delete() kept the remote copy")
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	if _, err := New(nil); !errors.Is(err, ErrNilConfig) {
+		t.Fatalf("New(nil) error = %v", err)
+	}
+	if _, err := New(&Config{}); !errors.Is(err, ErrStoreRequired) {
+		t.Fatalf("New() error = %v, want %v", err, ErrStoreRequired)
+	}
+	if _, err := NewTiered(&TieredConfig{Local: NewMemory(nil)}); !errors.Is(err, ErrTieredRemoteRequired) {
+		t.Fatalf("NewTiered() error = %v, want %v", err, ErrTieredRemoteRequired)
+	}
+	if _, err := NewRedis(nil); !errors.Is(err, ErrRedisClientRequired) {
+		t.Fatalf("NewRedis(nil) error = %v", err)
+	}
+
+	c, _ := New(&Config{Store: NewMemory(nil), DisableMetrics: true})
+	var dst string
+	if err := c.Get(nil, "k", &dst); !errors.Is(err, ErrContextRequired) {
+		t.Fatalf("Get(nil ctx) error = %v", err)
+	}
+	if err := c.Set(context.Background(), "", "v", 0); !errors.Is(err, ErrKeyRequired) {
+		t.Fatalf("Set(empty key) error = %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Codec converts values to and from the bytes kept by a Store.
+type Codec interface {
+	Marshal(any) ([]byte, error)
+	Unmarshal([]byte, any) error
+}
+
+// JSONCodec is the default codec.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// RawCodec stores []byte and string values as-is and decodes into *[]byte or
+// *string.
+type RawCodec struct{}
+
+func (RawCodec) Marshal(v any) ([]byte, error) {
+	switch value := v.(type) {
+	case []byte:
+		return value, nil
+	case string:
+		return []byte(value), nil
+	default:
+		return nil, fmt.Errorf("cache: raw codec cannot marshal %T", v)
+	}
+}
+
+func (RawCodec) Unmarshal(data []byte, v any) error {
+	switch dst := v.(type) {
+	case *[]byte:
+		*dst = append((*dst)[:0], data...)
+		return nil
+	case *string:
+		*dst = string(data)
+		return nil
+	default:
+		return fmt.Errorf("cache: raw codec cannot unmarshal into %T", v)
+	}
+}
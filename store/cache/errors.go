@@ -0,0 +1,16 @@
+package cache
+
+import "errors"
+
+var (
+	ErrNilConfig       = errors.New("cache: config is required")
+	ErrContextRequired = errors.New("cache: context is required")
+	ErrStoreRequired   = errors.New("cache: store is required")
+	ErrKeyRequired     = errors.New("cache: key is required")
+	ErrLoaderRequired  = errors.New("cache: loader is required")
+	ErrNotFound        = errors.New("cache: key not found")
+
+	ErrRedisClientRequired  = errors.New("cache: redis client is required")
+	ErrTieredLocalRequired  = errors.New("cache: tiered local store is required")
+	ErrTieredRemoteRequired = errors.New("cache: tiered remote store is required")
+)
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultMemoryMaxEntries = 10000
+
+type MemoryConfig struct {
+	// MaxEntries bounds the number of entries; the least recently used entry is
+	// evicted first. Defaults to 10000.
+	MaxEntries int
+
+	now func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	order      *list.List
+	now        func() time.Time
+}
+
+// NewMemory returns an in-process LRU store. A nil config uses the defaults.
+func NewMemory(conf *MemoryConfig) Store {
+	var config MemoryConfig
+	if conf != nil {
+		config = *conf
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaultMemoryMaxEntries
+	}
+	if config.now == nil {
+		config.now = time.Now
+	}
+
+	return &memoryStore{
+		maxEntries: config.MaxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		now:        config.now,
+	}
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !s.now().Before(entry.expiresAt) {
+		s.remove(elem)
+		return nil, ErrNotFound
+	}
+	s.order.MoveToFront(elem)
+	return append([]byte(nil), entry.value...), nil
+}
+
+func (s *memoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = s.now().Add(ttl)
+	}
+	value = append([]byte(nil), value...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		s.order.MoveToFront(elem)
+		return nil
+	}
+
+	s.items[key] = s.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for s.order.Len() > s.maxEntries {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+func (s *memoryStore) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	now := s.now()
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = now.Add(ttl)
+	}
+	value = append([]byte(nil), value...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		if entry.expiresAt.IsZero() || now.Before(entry.expiresAt) {
+			return false, nil
+		}
+		s.remove(elem)
+	}
+
+	s.items[key] = s.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for s.order.Len() > s.maxEntries {
+		s.remove(s.order.Back())
+	}
+	return true, nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := s.items[key]; ok {
+			s.remove(elem)
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.items, elem.Value.(*memoryEntry).key)
+}
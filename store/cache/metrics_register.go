@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	requestsTotal *prometheus.CounterVec
+	loadDuration  *prometheus.HistogramVec
+}
+
+var (
+	defaultMetricsOnce sync.Once
+	defaultMetrics     *metrics
+)
+
+func defaultCacheMetrics() *metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = newCacheMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetrics
+}
+
+func newCacheMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		requestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cache_requests_total",
+				Help: "Total number of cache operations.",
+			},
+			[]string{"name", "op", "result"},
+		),
+		loadDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "cache_load_duration_seconds",
+				Help:    "Cache loader duration in seconds on misses.",
+				Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+			},
+			[]string{"name", "result"},
+		),
+	}
+
+	mustRegisterCollector(registerer, &m.requestsTotal, m.requestsTotal)
+	mustRegisterCollector(registerer, &m.loadDuration, m.loadDuration)
+
+	return m
+}
+
+func mustRegisterCollector[T prometheus.Collector](registerer prometheus.Registerer, dst *T, collector T) {
+	if registerer == nil {
+		return
+	}
+	if err := registerer.Register(collector); err != nil {
+		if alreadyRegistered, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if registered, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				*dst = registered
+				return
+			}
+		}
+		panic(err)
+	}
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/store/redisx"
+	"github.com/bang-go/util"
+)
+
+type fakeRedis struct {
+	mu       sync.Mutex
+	store    map[string]string
+	commands [][]string
+}
+
+func (s *fakeRedis) dialer(context.Context, string, string) (net.Conn, error) {
+	client, server := net.Pipe()
+	go s.serve(server)
+	return client, nil
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		cmd, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, cmd)
+		var reply string
+		switch strings.ToUpper(cmd[0]) {
+		case "PING":
+			reply = "+PONG\r\n"
+		case "SET":
+			reply = "+OK\r\n"
+			if _, ok := s.store[cmd[1]]; ok && slices.ContainsFunc(cmd[3:], func(arg string) bool { return strings.EqualFold(arg, "nx") }) {
+				reply = "$-1\r\n"
+			} else {
+				s.store[cmd[1]] = cmd[2]
+			}
+		case "GET":
+			if value, ok := s.store[cmd[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case "DEL":
+			for _, key := range cmd[1:] {
+				delete(s.store, key)
+			}
+			reply = ":1\r\n"
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		s.mu.Unlock()
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	cmd := make([]string, 0, count)
+	for range count {
+		sizeLine, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(sizeLine, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		cmd = append(cmd, string(buf[:size]))
+	}
+	return cmd, nil
+}
+
+func TestRedisStore(t *testing.T) {
+	server := &fakeRedis{store: map[string]string{}}
+	client, err := redisx.Open(context.Background(), &redisx.Config{
+		Addr:            "fake:6379",
+		Protocol:        2,
+		DisableIdentity: util.Ptr(true),
+		Dialer:          server.dialer,
+		DisableMetrics:  true,
+	})
+	if err != nil {
+		t.Fatalf("redisx.Open() error = %v", err)
+	}
+	defer client.Close()
+
+	store, err := NewRedis(client)
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(missing) error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.Set(ctx, "k", []byte("v"), 1500*time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if data, err := store.Get(ctx, "k"); err != nil || string(data) != "v" {
+		t.Fatalf("Get() = %q, %v", data, err)
+	}
+	if err := store.Delete(ctx, "k"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if ok, err := store.SetNX(ctx, "once", []byte("1"), 1500*time.Millisecond); err != nil || !ok {
+		t.Fatalf("SetNX() = %v, %v, want stored", ok, err)
+	}
+	if ok, err := store.SetNX(ctx, "once", []byte("2"), 1500*time.Millisecond); err != nil || ok {
+		t.Fatalf("second SetNX() = %v, %v, want rejected", ok, err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	var set []string
+	for _, cmd := range server.commands {
+		if strings.EqualFold(cmd[0], "set") && cmd[1] == "k" {
+			set = cmd
+		}
+	}
+	if strings.Join(set, " ") != "set k v px 1500" {
+		t.Fatalf("SET command = %q, want px ttl", set)
+	}
+	if _, ok := server.store["k"]; ok {
+		t.Fatal("Delete() did not remove the key")
+	}
+	if server.store["once"] != "1" {
+		t.Fatalf("SetNX() overwrote the key: %q", server.store["once"])
+	}
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultTieredLocalTTL = time.Minute
+
+type TieredConfig struct {
+	// Local is the near cache, usually NewMemory.
+	Local Store
+	// Remote is the shared cache, usually NewRedis.
+	Remote Store
+	// LocalTTL caps how long an entry stays in Local. Other instances only see
+	// writes after their local copy expires. Defaults to 1m.
+	LocalTTL time.Duration
+}
+
+type tieredStore struct {
+	local    Store
+	remote   Store
+	localTTL time.Duration
+}
+
+// NewTiered reads through Local to Remote and writes to both, Remote first.
+func NewTiered(conf *TieredConfig) (Store, error) {
+	if conf == nil {
+		return nil, ErrNilConfig
+	}
+	if conf.Local == nil {
+		return nil, ErrTieredLocalRequired
+	}
+	if conf.Remote == nil {
+		return nil, ErrTieredRemoteRequired
+	}
+
+	localTTL := conf.LocalTTL
+	if localTTL <= 0 {
+		localTTL = defaultTieredLocalTTL
+	}
+	return &tieredStore{local: conf.Local, remote: conf.Remote, localTTL: localTTL}, nil
+}
+
+func (s *tieredStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.local.Get(ctx, key)
+	if err == nil {
+		return data, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	data, err = s.remote.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.local.Set(ctx, key, data, s.localTTL)
+	return data, nil
+}
+
+func (s *tieredStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := s.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	localTTL := s.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	return s.local.Set(ctx, key, value, localTTL)
+}
+
+// SetNX decides on Remote, the store shared by all instances, and only then
+// caches the value locally.
+func (s *tieredStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	ok, err := s.remote.SetNX(ctx, key, value, ttl)
+	if err != nil || !ok {
+		return ok, err
+	}
+	localTTL := s.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	return true, s.local.Set(ctx, key, value, localTTL)
+}
+
+// Delete always clears Local, even when Remote fails, so this instance never
+// serves a value the caller tried to remove.
+func (s *tieredStore) Delete(ctx context.Context, keys ...string) error {
+	localErr := s.local.Delete(ctx, keys...)
+	if err := s.remote.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	return localErr
+}
@@ -56,6 +56,8 @@ type Client interface {
 	GetIndex(context.Context, string) (indicesget.Response, error)
 	ExistsIndex(context.Context, string) (bool, error)
 	DeleteIndex(context.Context, string) (*indicesdelete.Response, error)
+	DiffMapping(context.Context, string, any) (*MappingDiff, error)
+	ApplyMapping(context.Context, string, any) (*MappingMigration, error)
 
 	Index(context.Context, string, string, any) (*index.Response, error)
 	Get(context.Context, string, string) (*get.Response, error)
@@ -63,6 +65,8 @@ type Client interface {
 	Delete(context.Context, string, string) (*delete.Response, error)
 	Search(context.Context, string, *search.Request) (*search.Response, error)
 	Bulk(context.Context, []BulkOperation) (*bulk.Response, error)
+	IndexPercolator(context.Context, string, string, *PercolatorQuery) (*index.Response, error)
+	Percolate(context.Context, string, []any, *PercolateOptions) (*PercolateResult, error)
 
 	Raw() *elasticsearch.TypedClient
 	RawLowLevel() *elasticsearch.Client
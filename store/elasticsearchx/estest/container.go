@@ -0,0 +1,126 @@
+package estest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	DefaultImage          = "docker.elastic.co/elasticsearch/elasticsearch:9.2.1"
+	defaultStartupTimeout = 2 * time.Minute
+	containerHTTPPort     = "9200/tcp"
+)
+
+type ContainerConfig struct {
+	// Image defaults to DefaultImage. OpenSearch images work as long as Env
+	// disables their security plugin.
+	Image string
+	// Env is merged over the single-node, security-disabled defaults.
+	Env            map[string]string
+	StartupTimeout time.Duration
+}
+
+// StartContainer runs a single-node cluster with the docker CLI and removes
+// it when the test ends. The test is skipped when docker is unavailable.
+func StartContainer(t testing.TB, conf *ContainerConfig) *Cluster {
+	t.Helper()
+
+	if conf == nil {
+		conf = &ContainerConfig{}
+	}
+	image := strings.TrimSpace(conf.Image)
+	if image == "" {
+		image = DefaultImage
+	}
+	timeout := conf.StartupTimeout
+	if timeout <= 0 {
+		timeout = defaultStartupTimeout
+	}
+
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("estest: docker is not available")
+	}
+
+	env := map[string]string{
+		"discovery.type":         "single-node",
+		"xpack.security.enabled": "false",
+		"ES_JAVA_OPTS":           "-Xms512m -Xmx512m",
+	}
+	for key, value := range conf.Env {
+		env[key] = value
+	}
+
+	args := []string{"run", "-d", "--rm", "-p", "127.0.0.1::9200"}
+	for key, value := range env {
+		args = append(args, "-e", key+"="+value)
+	}
+	args = append(args, image)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	id, err := docker(ctx, args...)
+	if err != nil {
+		t.Fatalf("estest: start container %s: %v", image, err)
+	}
+	t.Cleanup(func() {
+		if _, err := docker(context.Background(), "rm", "-f", id); err != nil {
+			t.Logf("estest: remove container %s: %v", id, err)
+		}
+	})
+
+	hostPort, err := docker(ctx, "port", id, containerHTTPPort)
+	if err != nil {
+		t.Fatalf("estest: resolve container port: %v", err)
+	}
+	address := "http://" + strings.TrimSpace(strings.Split(hostPort, "\n")[0])
+
+	if err := waitReady(ctx, address); err != nil {
+		logs, _ := docker(context.Background(), "logs", "--tail", "50", id)
+		t.Fatalf("estest: container %s not ready: %v\n%s", image, err, logs)
+	}
+	return &Cluster{Addresses: []string{address}}
+}
+
+func waitReady(ctx context.Context, address string) error {
+	client := &http.Client{Timeout: 2 * time.Second}
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	url := address + "/_cluster/health?wait_for_status=yellow&timeout=1s"
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		if resp, err := client.Do(req); err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func docker(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,143 @@
+// Package estest provides Elasticsearch test clusters and index fixtures for
+// packages built on elasticsearchx.
+package estest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bang-go/micro/store/elasticsearchx"
+)
+
+const (
+	// EnvAddresses points Start at an existing cluster (comma separated).
+	EnvAddresses = "ESTEST_ADDRESSES"
+	EnvUsername  = "ESTEST_USERNAME"
+	EnvPassword  = "ESTEST_PASSWORD"
+	// EnvContainer makes Start launch a container when set to "1" or "true".
+	EnvContainer = "ESTEST_CONTAINER"
+	// EnvImage overrides the container image used by Start.
+	EnvImage = "ESTEST_IMAGE"
+)
+
+type Cluster struct {
+	Addresses []string
+	Username  string
+	Password  string
+
+	// Fake is set when the cluster is backed by the in-memory server.
+	Fake *Fake
+}
+
+// Start returns a cluster for the current test, in order of preference:
+// ESTEST_ADDRESSES, a container when ESTEST_CONTAINER is set, otherwise the
+// in-memory fake. Resources are released through t.Cleanup.
+func Start(t testing.TB) *Cluster {
+	t.Helper()
+
+	if raw := strings.TrimSpace(os.Getenv(EnvAddresses)); raw != "" {
+		return &Cluster{
+			Addresses: splitAddresses(raw),
+			Username:  strings.TrimSpace(os.Getenv(EnvUsername)),
+			Password:  os.Getenv(EnvPassword),
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(EnvContainer))) {
+	case "1", "true":
+		return StartContainer(t, &ContainerConfig{Image: strings.TrimSpace(os.Getenv(EnvImage))})
+	}
+	return StartFake(t)
+}
+
+// StartFake starts the in-memory server and closes it when the test ends.
+func StartFake(t testing.TB) *Cluster {
+	t.Helper()
+
+	fake := NewFake()
+	t.Cleanup(fake.Close)
+	return &Cluster{Addresses: []string{fake.URL()}, Fake: fake}
+}
+
+func (c *Cluster) Config() *elasticsearchx.Config {
+	return &elasticsearchx.Config{
+		Addresses: append([]string(nil), c.Addresses...),
+		Username:  c.Username,
+		Password:  c.Password,
+	}
+}
+
+func (c *Cluster) Client(t testing.TB) elasticsearchx.Client {
+	t.Helper()
+
+	client, err := elasticsearchx.New(c.Config())
+	if err != nil {
+		t.Fatalf("estest: create client: %v", err)
+	}
+	return client
+}
+
+// CreateIndex creates a uniquely named index with the given body (mappings,
+// settings or nil) and deletes it when the test ends.
+func CreateIndex(t testing.TB, client elasticsearchx.Client, prefix string, body any) string {
+	t.Helper()
+
+	name := IndexName(prefix)
+	if _, err := client.CreateIndex(context.Background(), name, body); err != nil {
+		t.Fatalf("estest: create index %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		if _, err := client.DeleteIndex(context.Background(), name); err != nil {
+			t.Logf("estest: delete index %s: %v", name, err)
+		}
+	})
+	return name
+}
+
+// Seed indexes documents keyed by id and makes them visible to search.
+func Seed(t testing.TB, client elasticsearchx.Client, index string, docs map[string]any) {
+	t.Helper()
+
+	ops := make([]elasticsearchx.BulkOperation, 0, len(docs))
+	for id, doc := range docs {
+		ops = append(ops, elasticsearchx.BulkOperation{Action: "index", Index: index, ID: id, Document: doc})
+	}
+	if len(ops) == 0 {
+		return
+	}
+	resp, err := client.Bulk(context.Background(), ops)
+	if err != nil {
+		t.Fatalf("estest: seed %s: %v", index, err)
+	}
+	if resp.Errors {
+		t.Fatalf("estest: seed %s: bulk response reported errors", index)
+	}
+	if _, err := client.Raw().Indices.Refresh().Index(index).Do(context.Background()); err != nil {
+		t.Fatalf("estest: refresh %s: %v", index, err)
+	}
+}
+
+// IndexName returns prefix with a random suffix; index names must be
+// lowercase so the prefix is lowered too.
+func IndexName(prefix string) string {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		prefix = "estest"
+	}
+	var suffix [6]byte
+	_, _ = rand.Read(suffix[:])
+	return prefix + "-" + hex.EncodeToString(suffix[:])
+}
+
+func splitAddresses(raw string) []string {
+	var addresses []string
+	for _, address := range strings.Split(raw, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,123 @@
+package estest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bang-go/micro/store/elasticsearchx/estest"
+	"github.com/elastic/go-elasticsearch/v9/typedapi/core/search"
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+)
+
+func TestClusterDocumentLifecycle(t *testing.T) {
+	cluster := estest.Start(t)
+	client := cluster.Client(t)
+	ctx := context.Background()
+
+	index := estest.CreateIndex(t, client, "Products", map[string]any{
+		"mappings": map[string]any{
+			"properties": map[string]any{
+				"name":  map[string]any{"type": "keyword"},
+				"stock": map[string]any{"type": "integer"},
+			},
+		},
+	})
+	if !strings.HasPrefix(index, "products-") {
+		t.Fatalf("index = %q, want lowercase products- prefix", index)
+	}
+
+	exists, err := client.ExistsIndex(ctx, index)
+	if err != nil || !exists {
+		t.Fatalf("ExistsIndex() = %v, %v, want true", exists, err)
+	}
+
+	estest.Seed(t, client, index, map[string]any{
+		"1": map[string]any{"name": "keyboard", "stock": 3},
+		"2": map[string]any{"name": "mouse", "stock": 5},
+	})
+
+	if _, err := client.Update(ctx, index, "1", map[string]any{"stock": 10}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := client.Get(ctx, index, "1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var source struct {
+		Name  string `json:"name"`
+		Stock int    `json:"stock"`
+	}
+	if err := json.Unmarshal(got.Source_, &source); err != nil {
+		t.Fatalf("decode source: %v", err)
+	}
+	if !got.Found || source.Name != "keyboard" || source.Stock != 10 {
+		t.Fatalf("Get() = found %v source %+v, want keyboard with stock 10", got.Found, source)
+	}
+
+	if _, err := client.Delete(ctx, index, "2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := client.Raw().Indices.Refresh().Index(index).Do(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	res, err := client.Search(ctx, index, &search.Request{
+		Query: &types.Query{MatchAll: &types.MatchAllQuery{}},
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if res.Hits.Total == nil || res.Hits.Total.Value != 1 {
+		t.Fatalf("Search() total = %+v, want 1", res.Hits.Total)
+	}
+
+	missing, err := client.Get(ctx, index, "2")
+	if err != nil {
+		t.Fatalf("Get(missing) error = %v", err)
+	}
+	if missing.Found {
+		t.Fatal("Get(missing) found deleted document")
+	}
+}
+
+func TestFakeStubOverridesBuiltInResponse(t *testing.T) {
+	cluster := estest.StartFake(t)
+	client := cluster.Client(t)
+
+	cluster.Fake.Stub(http.MethodPost, "/products/_search", http.StatusOK,
+		`{"took":1,"timed_out":false,"hits":{"total":{"value":42,"relation":"eq"},"hits":[]}}`)
+
+	res, err := client.Search(context.Background(), "products", &search.Request{
+		Query: &types.Query{MatchAll: &types.MatchAllQuery{}},
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if res.Hits.Total.Value != 42 {
+		t.Fatalf("Search() total = %d, want 42", res.Hits.Total.Value)
+	}
+}
+
+func TestCreateIndexCleansUpAfterTest(t *testing.T) {
+	cluster := estest.StartFake(t)
+	client := cluster.Client(t)
+
+	var index string
+	t.Run("fixture", func(t *testing.T) {
+		index = estest.CreateIndex(t, client, "orders", nil)
+	})
+
+	exists, err := client.ExistsIndex(context.Background(), index)
+	if err != nil {
+		t.Fatalf("ExistsIndex() error = %v", err)
+	}
+	if exists {
+		t.Fatalf("index %s still exists after subtest cleanup", index)
+	}
+	if docs := cluster.Fake.Documents(index); docs != nil {
+		t.Fatalf("Documents() = %v, want nil", docs)
+	}
+}
@@ -0,0 +1,496 @@
+package estest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Fake is an in-memory Elasticsearch stand-in covering the endpoints used by
+// elasticsearchx. Search returns every document in the index (honouring
+// from/size); use Stub for anything that needs a precise response.
+type Fake struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	indices map[string]*fakeIndex
+	stubs   map[string]stub
+	nextID  int
+}
+
+type fakeIndex struct {
+	body json.RawMessage
+	docs map[string]*fakeDoc
+}
+
+type fakeDoc struct {
+	source  map[string]any
+	version int
+}
+
+type stub struct {
+	status int
+	body   string
+}
+
+func NewFake() *Fake {
+	f := &Fake{
+		indices: make(map[string]*fakeIndex),
+		stubs:   make(map[string]stub),
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+func (f *Fake) URL() string {
+	return f.server.URL
+}
+
+func (f *Fake) Close() {
+	f.server.Close()
+}
+
+// Stub returns a canned JSON body for method and path, taking precedence over
+// the built-in behaviour.
+func (f *Fake) Stub(method, path string, status int, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stubs[stubKey(method, path)] = stub{status: status, body: body}
+}
+
+// Documents returns a copy of the sources stored in index, keyed by id.
+func (f *Fake) Documents(index string) map[string]map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	idx, ok := f.indices[index]
+	if !ok {
+		return nil
+	}
+	docs := make(map[string]map[string]any, len(idx.docs))
+	for id, doc := range idx.docs {
+		docs[id] = maps.Clone(doc.source)
+	}
+	return docs
+}
+
+func (f *Fake) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "parse_exception", err.Error())
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if s, ok := f.stubs[stubKey(r.Method, r.URL.Path)]; ok {
+		w.WriteHeader(s.status)
+		_, _ = io.WriteString(w, s.body)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/":
+		writeJSON(w, http.StatusOK, map[string]any{
+			"name":         "estest",
+			"cluster_name": "estest",
+			"version":      map[string]any{"number": "9.0.0"},
+			"tagline":      "You Know, for Search",
+		})
+	case parts[0] == "_bulk":
+		f.bulk(w, body)
+	case len(parts) == 1:
+		f.index(w, r.Method, parts[0], body)
+	case len(parts) == 2 && parts[1] == "_refresh":
+		if _, ok := f.indices[parts[0]]; !ok {
+			writeIndexNotFound(w, parts[0])
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"_shards": map[string]any{"total": 1, "successful": 1, "failed": 0}})
+	case len(parts) == 2 && parts[1] == "_mapping":
+		f.mapping(w, r.Method, parts[0], body)
+	case len(parts) == 2 && parts[1] == "_search":
+		f.search(w, parts[0], body)
+	case len(parts) == 2 && parts[1] == "_doc" && r.Method == http.MethodPost:
+		f.nextID++
+		f.putDoc(w, parts[0], strconv.Itoa(f.nextID), body)
+	case len(parts) == 3 && (parts[1] == "_doc" || parts[1] == "_create"):
+		f.doc(w, r.Method, parts[0], parts[2], body)
+	case len(parts) == 3 && parts[1] == "_update" && r.Method == http.MethodPost:
+		f.update(w, parts[0], parts[2], body)
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported_operation_exception", fmt.Sprintf("estest: %s %s is not supported", r.Method, r.URL.Path))
+	}
+}
+
+func (f *Fake) index(w http.ResponseWriter, method, name string, body []byte) {
+	idx, exists := f.indices[name]
+	switch method {
+	case http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPut:
+		if exists {
+			writeError(w, http.StatusBadRequest, "resource_already_exists_exception", "index ["+name+"] already exists")
+			return
+		}
+		f.indices[name] = &fakeIndex{body: append(json.RawMessage(nil), body...), docs: make(map[string]*fakeDoc)}
+		writeJSON(w, http.StatusOK, map[string]any{"acknowledged": true, "shards_acknowledged": true, "index": name})
+	case http.MethodGet:
+		if !exists {
+			writeIndexNotFound(w, name)
+			return
+		}
+		state := map[string]any{}
+		if len(idx.body) > 0 {
+			_ = json.Unmarshal(idx.body, &state)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{name: state})
+	case http.MethodDelete:
+		if !exists {
+			writeIndexNotFound(w, name)
+			return
+		}
+		delete(f.indices, name)
+		writeJSON(w, http.StatusOK, map[string]any{"acknowledged": true})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", method)
+	}
+}
+
+// mapping serves GET and PUT _mapping. PUT merges properties into the
+// stored mapping without Elasticsearch's conflict checks.
+func (f *Fake) mapping(w http.ResponseWriter, method, name string, body []byte) {
+	idx, ok := f.indices[name]
+	if !ok {
+		writeIndexNotFound(w, name)
+		return
+	}
+	state := map[string]any{}
+	if len(idx.body) > 0 {
+		_ = json.Unmarshal(idx.body, &state)
+	}
+	mappings, _ := state["mappings"].(map[string]any)
+	if mappings == nil {
+		mappings = map[string]any{}
+	}
+
+	switch method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{name: map[string]any{"mappings": mappings}})
+	case http.MethodPut, http.MethodPost:
+		var patch map[string]any
+		if err := json.Unmarshal(body, &patch); err != nil {
+			writeError(w, http.StatusBadRequest, "parse_exception", err.Error())
+			return
+		}
+		mergeMapping(mappings, patch)
+		state["mappings"] = mappings
+		idx.body, _ = json.Marshal(state)
+		writeJSON(w, http.StatusOK, map[string]any{"acknowledged": true})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", method)
+	}
+}
+
+func mergeMapping(dst, src map[string]any) {
+	for key, value := range src {
+		srcObject, ok := value.(map[string]any)
+		dstObject, ok2 := dst[key].(map[string]any)
+		if ok && ok2 {
+			mergeMapping(dstObject, srcObject)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+func (f *Fake) doc(w http.ResponseWriter, method, index, id string, body []byte) {
+	switch method {
+	case http.MethodPut, http.MethodPost:
+		f.putDoc(w, index, id, body)
+	case http.MethodGet:
+		idx, ok := f.indices[index]
+		if !ok {
+			writeIndexNotFound(w, index)
+			return
+		}
+		doc, ok := idx.docs[id]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]any{"_index": index, "_id": id, "found": false})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"_index":        index,
+			"_id":           id,
+			"_version":      doc.version,
+			"_seq_no":       doc.version - 1,
+			"_primary_term": 1,
+			"found":         true,
+			"_source":       doc.source,
+		})
+	case http.MethodDelete:
+		status, result := f.deleteDoc(index, id)
+		writeJSON(w, status, writeResult(index, id, result, 1))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", method)
+	}
+}
+
+func (f *Fake) putDoc(w http.ResponseWriter, index, id string, body []byte) {
+	var source map[string]any
+	if err := json.Unmarshal(body, &source); err != nil {
+		writeError(w, http.StatusBadRequest, "mapper_parsing_exception", err.Error())
+		return
+	}
+	result, version := f.storeDoc(index, id, source)
+	status := http.StatusOK
+	if result == "created" {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, writeResult(index, id, result, version))
+}
+
+func (f *Fake) update(w http.ResponseWriter, index, id string, body []byte) {
+	var req struct {
+		Doc map[string]any `json:"doc"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "x_content_parse_exception", err.Error())
+		return
+	}
+	status, result, version := f.updateDoc(index, id, req.Doc)
+	if status == http.StatusNotFound {
+		writeError(w, status, "document_missing_exception", "["+id+"]: document missing")
+		return
+	}
+	writeJSON(w, status, writeResult(index, id, result, version))
+}
+
+func (f *Fake) search(w http.ResponseWriter, index string, body []byte) {
+	idx, ok := f.indices[index]
+	if !ok {
+		writeIndexNotFound(w, index)
+		return
+	}
+
+	var req struct {
+		From *int `json:"from"`
+		Size *int `json:"size"`
+	}
+	if len(body) > 0 {
+		_ = json.Unmarshal(body, &req)
+	}
+
+	ids := slices.Sorted(maps.Keys(idx.docs))
+	from, size := 0, 10
+	if req.From != nil {
+		from = *req.From
+	}
+	if req.Size != nil {
+		size = *req.Size
+	}
+	from = min(max(from, 0), len(ids))
+	end := min(from+max(size, 0), len(ids))
+
+	hits := make([]map[string]any, 0, end-from)
+	for _, id := range ids[from:end] {
+		hits = append(hits, map[string]any{
+			"_index":  index,
+			"_id":     id,
+			"_score":  1.0,
+			"_source": idx.docs[id].source,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"took":      0,
+		"timed_out": false,
+		"_shards":   map[string]any{"total": 1, "successful": 1, "skipped": 0, "failed": 0},
+		"hits": map[string]any{
+			"total":     map[string]any{"value": len(ids), "relation": "eq"},
+			"max_score": 1.0,
+			"hits":      hits,
+		},
+	})
+}
+
+func (f *Fake) bulk(w http.ResponseWriter, body []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+
+	items := make([]map[string]any, 0)
+	hasErrors := false
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var action map[string]struct {
+			Index string `json:"_index"`
+			ID    string `json:"_id"`
+		}
+		if err := json.Unmarshal(line, &action); err != nil || len(action) != 1 {
+			writeError(w, http.StatusBadRequest, "illegal_argument_exception", "malformed bulk action line")
+			return
+		}
+
+		for name, meta := range action {
+			var source []byte
+			if name != "delete" {
+				if !scanner.Scan() {
+					writeError(w, http.StatusBadRequest, "illegal_argument_exception", "bulk action is missing its source line")
+					return
+				}
+				source = append([]byte(nil), scanner.Bytes()...)
+			}
+
+			id := meta.ID
+			if id == "" {
+				f.nextID++
+				id = strconv.Itoa(f.nextID)
+			}
+			status, result, version := f.bulkItem(name, meta.Index, id, source)
+			item := writeResult(meta.Index, id, result, version)
+			item["status"] = status
+			if status >= http.StatusBadRequest {
+				hasErrors = true
+				item["error"] = map[string]any{"type": result, "reason": result}
+			}
+			items = append(items, map[string]any{name: item})
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"took": 0, "errors": hasErrors, "items": items})
+}
+
+func (f *Fake) bulkItem(action, index, id string, source []byte) (int, string, int) {
+	switch action {
+	case "index", "create":
+		var doc map[string]any
+		if err := json.Unmarshal(source, &doc); err != nil {
+			return http.StatusBadRequest, "mapper_parsing_exception", 0
+		}
+		if action == "create" {
+			if idx, ok := f.indices[index]; ok {
+				if _, exists := idx.docs[id]; exists {
+					return http.StatusConflict, "version_conflict_engine_exception", 0
+				}
+			}
+		}
+		result, version := f.storeDoc(index, id, doc)
+		if result == "created" {
+			return http.StatusCreated, result, version
+		}
+		return http.StatusOK, result, version
+	case "update":
+		var req struct {
+			Doc map[string]any `json:"doc"`
+		}
+		if err := json.Unmarshal(source, &req); err != nil {
+			return http.StatusBadRequest, "x_content_parse_exception", 0
+		}
+		status, result, version := f.updateDoc(index, id, req.Doc)
+		if status == http.StatusNotFound {
+			return status, "document_missing_exception", 0
+		}
+		return status, result, version
+	case "delete":
+		status, result := f.deleteDoc(index, id)
+		return status, result, 1
+	default:
+		return http.StatusBadRequest, "illegal_argument_exception", 0
+	}
+}
+
+// storeDoc auto-creates the index like a default cluster would.
+func (f *Fake) storeDoc(index, id string, source map[string]any) (string, int) {
+	idx, ok := f.indices[index]
+	if !ok {
+		idx = &fakeIndex{docs: make(map[string]*fakeDoc)}
+		f.indices[index] = idx
+	}
+	if doc, ok := idx.docs[id]; ok {
+		doc.source = source
+		doc.version++
+		return "updated", doc.version
+	}
+	idx.docs[id] = &fakeDoc{source: source, version: 1}
+	return "created", 1
+}
+
+func (f *Fake) updateDoc(index, id string, patch map[string]any) (int, string, int) {
+	idx, ok := f.indices[index]
+	if !ok {
+		return http.StatusNotFound, "", 0
+	}
+	doc, ok := idx.docs[id]
+	if !ok {
+		return http.StatusNotFound, "", 0
+	}
+	if doc.source == nil {
+		doc.source = make(map[string]any, len(patch))
+	}
+	maps.Copy(doc.source, patch)
+	doc.version++
+	return http.StatusOK, "updated", doc.version
+}
+
+func (f *Fake) deleteDoc(index, id string) (int, string) {
+	idx, ok := f.indices[index]
+	if !ok {
+		return http.StatusNotFound, "not_found"
+	}
+	if _, ok := idx.docs[id]; !ok {
+		return http.StatusNotFound, "not_found"
+	}
+	delete(idx.docs, id)
+	return http.StatusOK, "deleted"
+}
+
+func writeResult(index, id, result string, version int) map[string]any {
+	return map[string]any{
+		"_index":        index,
+		"_id":           id,
+		"_version":      version,
+		"result":        result,
+		"_seq_no":       max(version-1, 0),
+		"_primary_term": 1,
+		"_shards":       map[string]any{"total": 1, "successful": 1, "failed": 0},
+	}
+}
+
+func writeIndexNotFound(w http.ResponseWriter, index string) {
+	writeError(w, http.StatusNotFound, "index_not_found_exception", "no such index ["+index+"]")
+}
+
+func writeError(w http.ResponseWriter, status int, errType, reason string) {
+	writeJSON(w, status, map[string]any{
+		"error":  map[string]any{"type": errType, "reason": reason},
+		"status": status,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func stubKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
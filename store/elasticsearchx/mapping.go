@@ -0,0 +1,596 @@
+package elasticsearchx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+)
+
+var (
+	ErrMappingRequired        = errors.New("elasticsearchx: mapping is required")
+	ErrInvalidMapping         = errors.New("elasticsearchx: invalid mapping")
+	ErrUnsupportedMappingType = errors.New("elasticsearchx: unsupported mapping field type")
+	ErrMultipleIndices        = errors.New("elasticsearchx: index resolves to more than one index")
+)
+
+type MappingChangeKind string
+
+const (
+	// MappingAdd adds a field, object property or multi-field.
+	MappingAdd MappingChangeKind = "add"
+	// MappingUpdate changes a parameter Elasticsearch updates in place, such
+	// as ignore_above or search_analyzer.
+	MappingUpdate MappingChangeKind = "update"
+	// MappingTypeChange changes a field's type and requires a reindex.
+	MappingTypeChange MappingChangeKind = "type_change"
+	// MappingParamChange changes a parameter fixed at creation, such as
+	// analyzer or format, and requires a reindex.
+	MappingParamChange MappingChangeKind = "param_change"
+	// MappingRemove lists a live field missing from the desired mapping.
+	// Mapped fields cannot be removed; it is reported and left alone.
+	MappingRemove MappingChangeKind = "remove"
+)
+
+// updatableParams can change on an existing field through the put mapping
+// API; any other parameter change needs a new index.
+var updatableParams = map[string]bool{
+	"ignore_above":          true,
+	"ignore_malformed":      true,
+	"search_analyzer":       true,
+	"search_quote_analyzer": true,
+	"dynamic":               true,
+	"meta":                  true,
+}
+
+// MappingChange is one difference between the live and desired mapping.
+// Path is dotted, with multi-fields addressed as "title.raw".
+type MappingChange struct {
+	Path     string
+	Kind     MappingChangeKind
+	Breaking bool
+	From     any
+	To       any
+	Detail   string
+}
+
+func (c MappingChange) String() string {
+	switch c.Kind {
+	case MappingAdd:
+		return fmt.Sprintf("%s %s", c.Kind, c.Path)
+	case MappingRemove:
+		return fmt.Sprintf("%s %s (kept by Elasticsearch)", c.Kind, c.Path)
+	}
+	text := fmt.Sprintf("%s %s: %v -> %v", c.Kind, c.Path, c.From, c.To)
+	if c.Detail != "" {
+		text += " (" + c.Detail + ")"
+	}
+	return text
+}
+
+// MappingDiff compares a live index mapping with a desired one.
+type MappingDiff struct {
+	Changes []MappingChange
+	patch   map[string]any
+}
+
+// Compatible returns the changes the put mapping API can apply in place.
+func (d *MappingDiff) Compatible() []MappingChange {
+	return d.filter(func(c MappingChange) bool { return !c.Breaking && c.Kind != MappingRemove })
+}
+
+// Breaking returns the changes that need a new index and a reindex.
+func (d *MappingDiff) Breaking() []MappingChange {
+	return d.filter(func(c MappingChange) bool { return c.Breaking })
+}
+
+func (d *MappingDiff) HasBreaking() bool {
+	return len(d.Breaking()) > 0
+}
+
+// Patch returns the put mapping body applying the compatible changes, or
+// nil when there is nothing to apply.
+func (d *MappingDiff) Patch() map[string]any {
+	if len(d.patch) == 0 {
+		return nil
+	}
+	return map[string]any{"properties": d.patch}
+}
+
+func (d *MappingDiff) filter(keep func(MappingChange) bool) []MappingChange {
+	var changes []MappingChange
+	for _, change := range d.Changes {
+		if keep(change) {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// MappingMigration reports what ApplyMapping did and what is left to do.
+type MappingMigration struct {
+	Index string
+	Diff  *MappingDiff
+	// Applied is true when compatible changes were sent with put mapping.
+	Applied bool
+	// Reindex is set when breaking changes remain.
+	Reindex *ReindexPlan
+}
+
+// ReindexPlan describes the manual migration for breaking changes: create
+// Target with Mapping, reindex Source into it, then switch readers over.
+type ReindexPlan struct {
+	Source  string
+	Target  string
+	Mapping map[string]any
+	Reasons []MappingChange
+	Steps   []string
+}
+
+// DiffMappings compares two mappings. Each may be a struct (see
+// MappingFromStruct), JSON bytes or string, or a map, given either as a
+// create-index body with "mappings" or as the mappings object itself. Only
+// properties are compared.
+func DiffMappings(live, desired any) (*MappingDiff, error) {
+	liveProps, err := mappingProperties(live)
+	if err != nil {
+		return nil, err
+	}
+	desiredProps, err := mappingProperties(desired)
+	if err != nil {
+		return nil, err
+	}
+	changes, patch := diffProperties("", liveProps, desiredProps)
+	return &MappingDiff{Changes: changes, patch: patch}, nil
+}
+
+func (c *client) DiffMapping(ctx context.Context, name string, desired any) (*MappingDiff, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrIndexRequired
+	}
+	if desired == nil {
+		return nil, ErrMappingRequired
+	}
+	live, err := c.liveMapping(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return DiffMappings(live, desired)
+}
+
+// ApplyMapping applies the compatible changes between the live mapping of
+// name and desired, and returns a reindex plan for the breaking ones, which
+// are never applied automatically.
+func (c *client) ApplyMapping(ctx context.Context, name string, desired any) (*MappingMigration, error) {
+	diff, err := c.DiffMapping(ctx, name, desired)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	migration := &MappingMigration{Index: name, Diff: diff}
+	if patch := diff.Patch(); patch != nil {
+		body, err := marshalBody(patch)
+		if err != nil {
+			return nil, fmt.Errorf("elasticsearchx: encode put mapping body failed: %w", err)
+		}
+		if _, err := c.typed.Indices.PutMapping(name).Raw(bytes.NewReader(body)).Do(ctx); err != nil {
+			return nil, fmt.Errorf("elasticsearchx: put mapping failed: %w", err)
+		}
+		migration.Applied = true
+	}
+	if diff.HasBreaking() {
+		properties, err := mappingProperties(desired)
+		if err != nil {
+			return nil, err
+		}
+		migration.Reindex = newReindexPlan(name, map[string]any{"properties": properties}, diff.Breaking())
+	}
+	return migration, nil
+}
+
+func (c *client) liveMapping(ctx context.Context, name string) (map[string]any, error) {
+	res, err := c.typed.Indices.GetMapping().Index(name).Perform(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		errorResponse := types.NewElasticsearchError()
+		if err := json.NewDecoder(res.Body).Decode(errorResponse); err != nil {
+			return nil, fmt.Errorf("elasticsearchx: get mapping failed with status %d: %w", res.StatusCode, err)
+		}
+		if errorResponse.Status == 0 {
+			errorResponse.Status = res.StatusCode
+		}
+		return nil, errorResponse
+	}
+
+	var indices map[string]struct {
+		Mappings map[string]any `json:"mappings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return nil, fmt.Errorf("elasticsearchx: decode mapping failed: %w", err)
+	}
+	if len(indices) > 1 {
+		return nil, fmt.Errorf("%w: %s", ErrMultipleIndices, strings.Join(slices.Sorted(maps.Keys(indices)), ", "))
+	}
+	for _, index := range indices {
+		if index.Mappings == nil {
+			return map[string]any{}, nil
+		}
+		return index.Mappings, nil
+	}
+	return map[string]any{}, nil
+}
+
+func diffProperties(prefix string, live, desired map[string]any) ([]MappingChange, map[string]any) {
+	var changes []MappingChange
+	patch := map[string]any{}
+	for _, name := range slices.Sorted(maps.Keys(desired)) {
+		path := prefix + name
+		desiredField := asObject(desired[name])
+		liveValue, ok := live[name]
+		if !ok {
+			changes = append(changes, MappingChange{Path: path, Kind: MappingAdd, To: fieldType(desiredField)})
+			patch[name] = desiredField
+			continue
+		}
+		fieldChanges, fieldPatch := diffField(path, asObject(liveValue), desiredField)
+		changes = append(changes, fieldChanges...)
+		if fieldPatch != nil {
+			patch[name] = fieldPatch
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(live)) {
+		if _, ok := desired[name]; !ok {
+			changes = append(changes, MappingChange{Path: prefix + name, Kind: MappingRemove, From: fieldType(asObject(live[name]))})
+		}
+	}
+	return changes, patch
+}
+
+func diffField(path string, live, desired map[string]any) ([]MappingChange, map[string]any) {
+	liveType, desiredType := fieldType(live), fieldType(desired)
+	if liveType != desiredType {
+		return []MappingChange{{
+			Path: path, Kind: MappingTypeChange, Breaking: true, From: liveType, To: desiredType,
+		}}, nil
+	}
+
+	var own []MappingChange
+	for _, param := range slices.Sorted(maps.Keys(mergeKeys(live, desired))) {
+		if param == "type" || param == "properties" || param == "fields" {
+			continue
+		}
+		from, to := live[param], desired[param]
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+		change := MappingChange{Path: path, Kind: MappingParamChange, Breaking: true, From: from, To: to, Detail: param}
+		if updatableParams[param] && to != nil {
+			change.Kind, change.Breaking = MappingUpdate, false
+		}
+		own = append(own, change)
+	}
+	// Multi-fields are part of their parent's definition, while object
+	// properties are independent fields.
+	fieldsChanges, fieldsPatch := diffProperties(path+".", asObject(live["fields"]), asObject(desired["fields"]))
+	own = append(own, fieldsChanges...)
+	propertyChanges, propertyPatch := diffProperties(path+".", asObject(live["properties"]), asObject(desired["properties"]))
+
+	if hasBreaking(own) {
+		// The field is sent whole, so one breaking change blocks the
+		// compatible ones next to it; they ride along with the reindex.
+		for i := range own {
+			if !own[i].Breaking && own[i].Kind != MappingRemove {
+				own[i].Breaking = true
+				own[i].Detail = strings.TrimSpace(own[i].Detail + " blocked by a breaking change on " + path)
+			}
+		}
+		changes := append(own, propertyChanges...)
+		if len(propertyPatch) == 0 {
+			return changes, nil
+		}
+		return changes, objectPatch(desired, nil, propertyPatch)
+	}
+
+	changes := append(own, propertyChanges...)
+	updated := map[string]any{}
+	for _, change := range own {
+		if change.Kind == MappingUpdate {
+			updated[change.Detail] = desired[change.Detail]
+		}
+	}
+	if len(updated) == 0 && len(fieldsPatch) == 0 && len(propertyPatch) == 0 {
+		return changes, nil
+	}
+	if liveType != "object" && liveType != "nested" {
+		// Leaf fields are updated by sending their full definition.
+		return changes, desired
+	}
+	return changes, objectPatch(desired, updated, propertyPatch)
+}
+
+func objectPatch(desired, updated, properties map[string]any) map[string]any {
+	patch := make(map[string]any, len(updated)+2)
+	if fieldType, ok := desired["type"]; ok {
+		patch["type"] = fieldType
+	}
+	maps.Copy(patch, updated)
+	if len(properties) > 0 {
+		patch["properties"] = properties
+	}
+	return patch
+}
+
+func hasBreaking(changes []MappingChange) bool {
+	return slices.ContainsFunc(changes, func(c MappingChange) bool { return c.Breaking })
+}
+
+func fieldType(field map[string]any) string {
+	if fieldType, ok := field["type"].(string); ok && fieldType != "" {
+		return fieldType
+	}
+	return "object"
+}
+
+func mergeKeys(a, b map[string]any) map[string]any {
+	merged := make(map[string]any, len(a)+len(b))
+	maps.Copy(merged, a)
+	maps.Copy(merged, b)
+	return merged
+}
+
+func asObject(value any) map[string]any {
+	object, _ := value.(map[string]any)
+	return object
+}
+
+// mappingProperties normalizes a mapping to its properties, round-tripping
+// through JSON so numbers and nested values compare like decoded responses.
+func mappingProperties(mapping any) (map[string]any, error) {
+	if mapping == nil {
+		return nil, ErrMappingRequired
+	}
+	var raw []byte
+	switch value := mapping.(type) {
+	case []byte:
+		raw = value
+	case json.RawMessage:
+		raw = value
+	case string:
+		raw = []byte(value)
+	default:
+		if isStruct(reflect.TypeOf(mapping)) {
+			built, err := MappingFromStruct(mapping)
+			if err != nil {
+				return nil, err
+			}
+			mapping = built
+		}
+		encoded, err := json.Marshal(mapping)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMapping, err)
+		}
+		raw = encoded
+	}
+
+	var document map[string]any
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMapping, err)
+	}
+	if mappings, ok := document["mappings"]; ok {
+		document = asObject(mappings)
+	}
+	properties := asObject(document["properties"])
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	return properties, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// MappingFromStruct builds a mappings object, {"properties": {...}}, from a
+// struct. Field names follow the json tag. The es tag sets the type and
+// parameters, e.g. `es:"text,analyzer=ik_smart,fields.raw=keyword"`, and
+// `es:"-"` skips a field; without a type it is inferred: string is keyword,
+// integers and floats map to their sized types, time.Time to date and
+// structs to object, or nested with `es:"nested"`.
+func MappingFromStruct(v any) (map[string]any, error) {
+	t := reflect.TypeOf(v)
+	if !isStruct(t) {
+		return nil, fmt.Errorf("%w: %T is not a struct", ErrInvalidMapping, v)
+	}
+	properties, err := structProperties(derefType(t))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"properties": properties}, nil
+}
+
+func structProperties(t reflect.Type) (map[string]any, error) {
+	properties := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("es")
+		if tag == "-" {
+			continue
+		}
+		name, skip := jsonFieldName(sf)
+		if skip {
+			continue
+		}
+		ft := derefType(sf.Type)
+		if sf.Anonymous && ft.Kind() == reflect.Struct && ft != timeType && name == "" && tag == "" {
+			embedded, err := structProperties(ft)
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range embedded {
+				if _, ok := properties[key]; !ok {
+					properties[key] = value
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		field, err := structField(sf.Name, ft, tag)
+		if err != nil {
+			return nil, err
+		}
+		properties[name] = field
+	}
+	return properties, nil
+}
+
+func structField(goName string, t reflect.Type, tag string) (map[string]any, error) {
+	options := strings.Split(tag, ",")
+	fieldType := strings.TrimSpace(options[0])
+	field := map[string]any{}
+
+	elem := t
+	if elem.Kind() == reflect.Slice && elem.Elem().Kind() != reflect.Uint8 || elem.Kind() == reflect.Array {
+		elem = derefType(elem.Elem())
+	}
+	if fieldType == "" {
+		fieldType = inferFieldType(elem)
+		if fieldType == "" {
+			return nil, fmt.Errorf("%w: field %s of type %s needs an es tag", ErrUnsupportedMappingType, goName, t)
+		}
+	}
+	if fieldType != "object" {
+		field["type"] = fieldType
+	}
+	if (fieldType == "object" || fieldType == "nested") && elem.Kind() == reflect.Struct && elem != timeType {
+		properties, err := structProperties(elem)
+		if err != nil {
+			return nil, err
+		}
+		field["properties"] = properties
+	}
+
+	for _, option := range options[1:] {
+		key, value, ok := strings.Cut(strings.TrimSpace(option), "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: field %s has malformed es option %q", ErrInvalidMapping, goName, option)
+		}
+		if subField, ok := strings.CutPrefix(key, "fields."); ok {
+			fields, _ := field["fields"].(map[string]any)
+			if fields == nil {
+				fields = map[string]any{}
+				field["fields"] = fields
+			}
+			fields[subField] = map[string]any{"type": value}
+			continue
+		}
+		field[key] = tagValue(value)
+	}
+	return field, nil
+}
+
+func inferFieldType(t reflect.Type) string {
+	if t == timeType {
+		return "date"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "keyword"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8:
+		return "byte"
+	case reflect.Int16, reflect.Uint8:
+		return "short"
+	case reflect.Int32, reflect.Uint16:
+		return "integer"
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "long"
+	case reflect.Uint, reflect.Uint64:
+		return "unsigned_long"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "binary"
+		}
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return ""
+}
+
+func tagValue(value string) any {
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+	return value
+}
+
+func jsonFieldName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, false
+}
+
+func isStruct(t reflect.Type) bool {
+	return t != nil && derefType(t).Kind() == reflect.Struct
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+var versionSuffix = regexp.MustCompile(`^(.*)_v(\d+)$`)
+
+func newReindexPlan(source string, mapping map[string]any, reasons []MappingChange) *ReindexPlan {
+	target := source + "_v2"
+	if match := versionSuffix.FindStringSubmatch(source); match != nil {
+		version, _ := strconv.Atoi(match[2])
+		target = fmt.Sprintf("%s_v%d", match[1], version+1)
+	}
+	return &ReindexPlan{
+		Source:  source,
+		Target:  target,
+		Mapping: mapping,
+		Reasons: reasons,
+		Steps: []string{
+			fmt.Sprintf("PUT /%s with the desired mappings and the settings of %s", target, source),
+			fmt.Sprintf(`POST /_reindex?wait_for_completion=false {"source":{"index":%q},"dest":{"index":%q}} and follow the task until it completes`, source, target),
+			fmt.Sprintf("compare document counts of %s and %s, then replay writes made during the reindex", source, target),
+			fmt.Sprintf("POST /_aliases to move the alias readers use from %s to %s in one request", source, target),
+			fmt.Sprintf("DELETE /%s once nothing reads from it", source),
+		},
+	}
+}
@@ -0,0 +1,167 @@
+package elasticsearchx_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/store/elasticsearchx"
+	"github.com/bang-go/micro/store/elasticsearchx/estest"
+)
+
+type productDoc struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title" es:"text,analyzer=standard,fields.raw=keyword"`
+	Price     float64   `json:"price"`
+	Stock     int32     `json:"stock"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	Seller    struct {
+		Name string `json:"name"`
+	} `json:"seller"`
+	Variants []struct {
+		SKU string `json:"sku"`
+	} `json:"variants" es:"nested"`
+	Internal string `json:"-"`
+	Cache    string `json:"cache" es:"-"`
+}
+
+func TestMappingFromStruct(t *testing.T) {
+	got, err := elasticsearchx.MappingFromStruct(&productDoc{})
+	if err != nil {
+		t.Fatalf("MappingFromStruct() error = %v", err)
+	}
+	want := map[string]any{"properties": map[string]any{
+		"id":         map[string]any{"type": "keyword"},
+		"title":      map[string]any{"type": "text", "analyzer": "standard", "fields": map[string]any{"raw": map[string]any{"type": "keyword"}}},
+		"price":      map[string]any{"type": "double"},
+		"stock":      map[string]any{"type": "integer"},
+		"tags":       map[string]any{"type": "keyword"},
+		"created_at": map[string]any{"type": "date"},
+		"seller":     map[string]any{"properties": map[string]any{"name": map[string]any{"type": "keyword"}}},
+		"variants":   map[string]any{"type": "nested", "properties": map[string]any{"sku": map[string]any{"type": "keyword"}}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("MappingFromStruct() = %#v", got)
+	}
+
+	if _, err := elasticsearchx.MappingFromStruct(struct{ Any any }{}); !errors.Is(err, elasticsearchx.ErrUnsupportedMappingType) {
+		t.Fatalf("MappingFromStruct(any) error = %v", err)
+	}
+	if _, err := elasticsearchx.MappingFromStruct("nope"); !errors.Is(err, elasticsearchx.ErrInvalidMapping) {
+		t.Fatalf("MappingFromStruct(string) error = %v", err)
+	}
+}
+
+func TestDiffMappingsClassifiesChanges(t *testing.T) {
+	live := `{"mappings":{"properties":{
+		"title":  {"type":"text","analyzer":"standard"},
+		"sku":    {"type":"keyword","ignore_above":64},
+		"price":  {"type":"float"},
+		"body":   {"type":"text","analyzer":"standard"},
+		"seller": {"properties":{"name":{"type":"keyword"}}},
+		"legacy": {"type":"keyword"}
+	}}}`
+	desired := map[string]any{"properties": map[string]any{
+		"title":  map[string]any{"type": "text", "analyzer": "standard", "fields": map[string]any{"raw": map[string]any{"type": "keyword"}}},
+		"sku":    map[string]any{"type": "keyword", "ignore_above": 256},
+		"price":  map[string]any{"type": "double"},
+		"body":   map[string]any{"type": "text", "analyzer": "english"},
+		"seller": map[string]any{"properties": map[string]any{"name": map[string]any{"type": "keyword"}, "city": map[string]any{"type": "keyword"}}},
+		"brand":  map[string]any{"type": "keyword"},
+	}}
+
+	diff, err := elasticsearchx.DiffMappings(live, desired)
+	if err != nil {
+		t.Fatalf("DiffMappings() error = %v", err)
+	}
+	type key struct {
+		path     string
+		kind     elasticsearchx.MappingChangeKind
+		breaking bool
+	}
+	var got []key
+	for _, change := range diff.Changes {
+		got = append(got, key{change.Path, change.Kind, change.Breaking})
+	}
+	want := []key{
+		{"body", elasticsearchx.MappingParamChange, true},
+		{"brand", elasticsearchx.MappingAdd, false},
+		{"price", elasticsearchx.MappingTypeChange, true},
+		{"seller.city", elasticsearchx.MappingAdd, false},
+		{"sku", elasticsearchx.MappingUpdate, false},
+		{"title.raw", elasticsearchx.MappingAdd, false},
+		{"legacy", elasticsearchx.MappingRemove, false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("changes = %v\nwant %v", got, want)
+	}
+	if len(diff.Compatible()) != 4 || len(diff.Breaking()) != 2 || !diff.HasBreaking() {
+		t.Fatalf("compatible = %v, breaking = %v", diff.Compatible(), diff.Breaking())
+	}
+
+	wantPatch := map[string]any{"properties": map[string]any{
+		"brand":  map[string]any{"type": "keyword"},
+		"seller": map[string]any{"properties": map[string]any{"city": map[string]any{"type": "keyword"}}},
+		"sku":    map[string]any{"type": "keyword", "ignore_above": float64(256)},
+		"title":  map[string]any{"type": "text", "analyzer": "standard", "fields": map[string]any{"raw": map[string]any{"type": "keyword"}}},
+	}}
+	if patch := diff.Patch(); !reflect.DeepEqual(patch, wantPatch) {
+		t.Fatalf("Patch() = %#v", patch)
+	}
+
+	// A multi-field added next to a breaking change cannot be applied alone.
+	diff, err = elasticsearchx.DiffMappings(
+		`{"properties":{"title":{"type":"text","analyzer":"standard"}}}`,
+		`{"properties":{"title":{"type":"text","analyzer":"english","fields":{"raw":{"type":"keyword"}}}}}`,
+	)
+	if err != nil {
+		t.Fatalf("DiffMappings() error = %v", err)
+	}
+	if len(diff.Breaking()) != 2 || diff.Patch() != nil {
+		t.Fatalf("blocked diff = %v, patch %v", diff.Changes, diff.Patch())
+	}
+}
+
+func TestApplyMappingAppliesCompatibleChanges(t *testing.T) {
+	cluster := estest.Start(t)
+	client := cluster.Client(t)
+	ctx := context.Background()
+
+	index := estest.CreateIndex(t, client, "products", map[string]any{
+		"mappings": map[string]any{"properties": map[string]any{
+			"id":    map[string]any{"type": "keyword"},
+			"title": map[string]any{"type": "text", "analyzer": "standard"},
+			"price": map[string]any{"type": "float"},
+		}},
+	})
+
+	migration, err := client.ApplyMapping(ctx, index, productDoc{})
+	if err != nil {
+		t.Fatalf("ApplyMapping() error = %v", err)
+	}
+	if !migration.Applied || migration.Reindex == nil {
+		t.Fatalf("migration = %+v", migration)
+	}
+	plan := migration.Reindex
+	if plan.Source != index || plan.Target != index+"_v2" || len(plan.Reasons) != 1 || plan.Reasons[0].Path != "price" || len(plan.Steps) == 0 {
+		t.Fatalf("reindex plan = %+v", plan)
+	}
+
+	diff, err := client.DiffMapping(ctx, index, productDoc{})
+	if err != nil {
+		t.Fatalf("DiffMapping() error = %v", err)
+	}
+	if len(diff.Compatible()) != 0 || len(diff.Breaking()) != 1 {
+		t.Fatalf("remaining changes = %v", diff.Changes)
+	}
+
+	if _, err := client.DiffMapping(ctx, index, nil); !errors.Is(err, elasticsearchx.ErrMappingRequired) {
+		t.Fatalf("DiffMapping(nil) error = %v", err)
+	}
+	if _, err := client.DiffMapping(ctx, "missing-index", productDoc{}); err == nil {
+		t.Fatal("DiffMapping() on a missing index succeeded")
+	}
+}
@@ -0,0 +1,164 @@
+package elasticsearchx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/core/index"
+	"github.com/elastic/go-elasticsearch/v9/typedapi/core/search"
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+)
+
+// DefaultPercolatorField is the percolator field used when none is given.
+const DefaultPercolatorField = "query"
+
+const percolatorSlotField = "_percolator_document_slot"
+
+var (
+	ErrPercolatorQueryRequired    = errors.New("elasticsearchx: percolator query is required")
+	ErrPercolateDocumentsRequired = errors.New("elasticsearchx: percolate documents are required")
+)
+
+// PercolatorMapping builds a create index body for storing queries: the
+// properties of document, in any form DiffMapping accepts, plus a percolator
+// field. Queries are parsed against these properties, so they must cover
+// every field a stored query references.
+func PercolatorMapping(document any, field string) (map[string]any, error) {
+	properties, err := mappingProperties(document)
+	if err != nil {
+		return nil, err
+	}
+	properties[percolatorField(field)] = map[string]any{"type": "percolator"}
+	return map[string]any{"mappings": map[string]any{"properties": properties}}, nil
+}
+
+// PercolatorQuery is a saved search stored in a percolator index.
+type PercolatorQuery struct {
+	Query *types.Query
+	// Field is the percolator field; defaults to "query".
+	Field string
+	// Metadata is stored next to the query, e.g. the owner to notify. A key
+	// equal to Field is overwritten by the query.
+	Metadata map[string]any
+}
+
+type PercolateOptions struct {
+	// Field is the percolator field; defaults to "query".
+	Field string
+	// Filter narrows the stored queries considered, e.g. to active alerts.
+	Filter *types.Query
+	From   int
+	// Size caps the matches returned; zero uses the server default of 10.
+	Size int
+}
+
+type PercolateResult struct {
+	Total   int64
+	Matches []PercolateMatch
+}
+
+// PercolateMatch is a stored query that matched. Slots are the positions in
+// the percolated documents that it matched.
+type PercolateMatch struct {
+	ID     string
+	Score  float64
+	Source json.RawMessage
+	Slots  []int
+}
+
+// IndexPercolator stores query under id so later Percolate calls match
+// documents against it.
+func (c *client) IndexPercolator(ctx context.Context, name, id string, query *PercolatorQuery) (*index.Response, error) {
+	if query == nil || query.Query == nil {
+		return nil, ErrPercolatorQueryRequired
+	}
+	document := make(map[string]any, len(query.Metadata)+1)
+	maps.Copy(document, query.Metadata)
+	document[percolatorField(query.Field)] = query.Query
+	return c.Index(ctx, name, id, document)
+}
+
+// Percolate returns the stored queries in the named index that match any of
+// documents.
+func (c *client) Percolate(ctx context.Context, name string, documents []any, opts *PercolateOptions) (*PercolateResult, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrIndexRequired
+	}
+	if len(documents) == 0 {
+		return nil, ErrPercolateDocumentsRequired
+	}
+	var options PercolateOptions
+	if opts != nil {
+		options = *opts
+	}
+
+	percolate := &types.PercolateQuery{Field: percolatorField(options.Field)}
+	for i, document := range documents {
+		if document == nil {
+			return nil, fmt.Errorf("%w: document %d is nil", ErrPercolateDocumentsRequired, i)
+		}
+		raw, err := marshalBody(document)
+		if err != nil {
+			return nil, fmt.Errorf("elasticsearchx: encode percolate document %d failed: %w", i, err)
+		}
+		percolate.Documents = append(percolate.Documents, raw)
+	}
+
+	request := &search.Request{Query: &types.Query{Percolate: percolate}}
+	if options.Filter != nil {
+		request.Query = &types.Query{Bool: &types.BoolQuery{
+			Must:   []types.Query{*request.Query},
+			Filter: []types.Query{*options.Filter},
+		}}
+	}
+	if options.From > 0 {
+		request.From = &options.From
+	}
+	if options.Size > 0 {
+		request.Size = &options.Size
+	}
+
+	resp, err := c.Search(ctx, name, request)
+	if err != nil {
+		return nil, err
+	}
+	return percolateResult(resp)
+}
+
+func percolateResult(resp *search.Response) (*PercolateResult, error) {
+	result := &PercolateResult{Matches: make([]PercolateMatch, 0, len(resp.Hits.Hits))}
+	if resp.Hits.Total != nil {
+		result.Total = resp.Hits.Total.Value
+	}
+	for _, hit := range resp.Hits.Hits {
+		match := PercolateMatch{Source: hit.Source_}
+		if hit.Id_ != nil {
+			match.ID = *hit.Id_
+		}
+		if hit.Score_ != nil {
+			match.Score = float64(*hit.Score_)
+		}
+		if raw, ok := hit.Fields[percolatorSlotField]; ok {
+			if err := json.Unmarshal(raw, &match.Slots); err != nil {
+				return nil, fmt.Errorf("elasticsearchx: decode %s of %s failed: %w", percolatorSlotField, match.ID, err)
+			}
+		}
+		result.Matches = append(result.Matches, match)
+	}
+	return result, nil
+}
+
+func percolatorField(field string) string {
+	if field = strings.TrimSpace(field); field != "" {
+		return field
+	}
+	return DefaultPercolatorField
+}
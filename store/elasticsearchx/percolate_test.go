@@ -0,0 +1,120 @@
+package elasticsearchx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+)
+
+func TestPercolatorMapping(t *testing.T) {
+	body, err := PercolatorMapping(map[string]any{"properties": map[string]any{
+		"title": map[string]any{"type": "text"},
+	}}, "")
+	if err != nil {
+		t.Fatalf("PercolatorMapping() error = %v", err)
+	}
+	properties := asObject(asObject(body["mappings"])["properties"])
+	if fieldType(asObject(properties["title"])) != "text" || fieldType(asObject(properties["query"])) != "percolator" {
+		t.Fatalf("mapping = %v", body)
+	}
+
+	if _, err := PercolatorMapping(nil, "query"); !errors.Is(err, ErrMappingRequired) {
+		t.Fatalf("expected ErrMappingRequired, got %v", err)
+	}
+}
+
+func TestIndexPercolatorAndPercolate(t *testing.T) {
+	transport := &recordingTransport{
+		handler: func(req *http.Request, body string) (*http.Response, error) {
+			switch {
+			case req.Method == http.MethodPut && req.URL.Path == "/alerts/_doc/a1":
+				if !strings.Contains(body, `"user_id":"u1"`) || !strings.Contains(body, `"rule":{"match":{"title":{"query":"bike"}}}`) {
+					t.Fatalf("unexpected percolator body: %s", body)
+				}
+				return jsonResponse(`{"_index":"alerts","_id":"a1","result":"created","_version":1}`), nil
+			case req.Method == http.MethodPost && req.URL.Path == "/alerts/_search":
+				for _, want := range []string{
+					`"percolate":{"documents":[{"title":"red bike"},{"title":"sofa"}],"field":"rule"}`,
+					`"filter":[{"term":{"active":{"value":true}}}]`,
+					`"size":50`,
+				} {
+					if !strings.Contains(body, want) {
+						t.Fatalf("expected %s in percolate body, got %s", want, body)
+					}
+				}
+				return jsonResponse(`{"took":1,"timed_out":false,"hits":{"total":{"value":1,"relation":"eq"},"hits":[
+					{"_index":"alerts","_id":"a1","_score":0.5,"_source":{"user_id":"u1"},"fields":{"_percolator_document_slot":[0]}}
+				]}}`), nil
+			default:
+				t.Fatalf("unexpected request: %s %s body=%s", req.Method, req.URL.Path, body)
+				return nil, nil
+			}
+		},
+	}
+
+	client, err := New(&Config{
+		Addresses: []string{"http://example.com"},
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.IndexPercolator(ctx, "alerts", "a1", &PercolatorQuery{
+		Field:    "rule",
+		Query:    &types.Query{Match: map[string]types.MatchQuery{"title": {Query: "bike"}}},
+		Metadata: map[string]any{"user_id": "u1"},
+	}); err != nil {
+		t.Fatalf("IndexPercolator() error = %v", err)
+	}
+
+	result, err := client.Percolate(ctx, "alerts", []any{
+		map[string]any{"title": "red bike"},
+		map[string]any{"title": "sofa"},
+	}, &PercolateOptions{
+		Field:  "rule",
+		Filter: &types.Query{Term: map[string]types.TermQuery{"active": {Value: true}}},
+		Size:   50,
+	})
+	if err != nil {
+		t.Fatalf("Percolate() error = %v", err)
+	}
+	if result.Total != 1 || len(result.Matches) != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	match := result.Matches[0]
+	if match.ID != "a1" || match.Score != 0.5 || len(match.Slots) != 1 || match.Slots[0] != 0 || string(match.Source) != `{"user_id":"u1"}` {
+		t.Fatalf("match = %+v", match)
+	}
+}
+
+func TestPercolateValidation(t *testing.T) {
+	client, err := New(&Config{Addresses: []string{"http://example.com"}, Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return jsonResponse(`{}`), nil
+	})})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.IndexPercolator(ctx, "alerts", "a1", &PercolatorQuery{}); err != ErrPercolatorQueryRequired {
+		t.Fatalf("expected ErrPercolatorQueryRequired, got %v", err)
+	}
+	if _, err := client.Percolate(nil, "alerts", []any{map[string]any{}}, nil); err != ErrContextRequired {
+		t.Fatalf("expected ErrContextRequired, got %v", err)
+	}
+	if _, err := client.Percolate(ctx, " ", []any{map[string]any{}}, nil); err != ErrIndexRequired {
+		t.Fatalf("expected ErrIndexRequired, got %v", err)
+	}
+	if _, err := client.Percolate(ctx, "alerts", nil, nil); err != ErrPercolateDocumentsRequired {
+		t.Fatalf("expected ErrPercolateDocumentsRequired, got %v", err)
+	}
+	if _, err := client.Percolate(ctx, "alerts", []any{nil}, nil); !errors.Is(err, ErrPercolateDocumentsRequired) {
+		t.Fatalf("expected ErrPercolateDocumentsRequired, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultBatchSize = 500
+	// defaultMaxPlaceholders stays under the 65535 bind parameter limit of
+	// MySQL and Postgres.
+	defaultMaxPlaceholders = 60000
+)
+
+// ConflictPolicy decides what a batch insert does with rows that hit a
+// primary or unique key. gorm renders it per driver, as ON DUPLICATE KEY
+// UPDATE on MySQL and ON CONFLICT elsewhere.
+type ConflictPolicy int
+
+const (
+	// ConflictFail leaves the conflict to the database, failing the chunk.
+	ConflictFail ConflictPolicy = iota
+	// ConflictIgnore keeps the existing rows.
+	ConflictIgnore
+	// ConflictUpdate overwrites UpdateColumns, or every column when empty.
+	ConflictUpdate
+)
+
+type BatchOptions struct {
+	// BatchSize caps the rows per INSERT; defaults to 500. Lower it for wide
+	// rows that would exceed max_allowed_packet.
+	BatchSize int
+	// MaxPlaceholders caps the bind parameters per INSERT, shrinking chunks
+	// of tables with many columns; defaults to 60000.
+	MaxPlaceholders int
+	// ContinueOnError inserts the remaining chunks after one fails.
+	ContinueOnError bool
+
+	Conflict ConflictPolicy
+	// ConflictColumns name the conflicting key for ON CONFLICT; they default
+	// to the primary key and MySQL ignores them.
+	ConflictColumns []string
+	UpdateColumns   []string
+}
+
+type BatchResult struct {
+	// RowsAffected sums the driver counts; on MySQL an updated row counts
+	// twice.
+	RowsAffected int64
+	Chunks       int
+	Failed       []*ChunkError
+}
+
+// ChunkError reports a failed INSERT covering rows[Offset:Offset+Len].
+type ChunkError struct {
+	Index  int
+	Offset int
+	Len    int
+	Err    error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("gormx: batch chunk %d (rows %d-%d): %v", e.Index, e.Offset, e.Offset+e.Len-1, e.Err)
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchInsert inserts rows in chunks sized by BatchOptions, so a large slice
+// never becomes a single oversized statement. The returned error joins the
+// ChunkErrors in BatchResult.Failed.
+func BatchInsert[T any](ctx context.Context, db *gorm.DB, rows []T, opts *BatchOptions) (BatchResult, error) {
+	return batchCreate(ctx, db, rows, opts, "insert")
+}
+
+// BatchUpsert is BatchInsert with ConflictUpdate unless opts asks for
+// ConflictIgnore.
+func BatchUpsert[T any](ctx context.Context, db *gorm.DB, rows []T, opts *BatchOptions) (BatchResult, error) {
+	var config BatchOptions
+	if opts != nil {
+		config = *opts
+	}
+	if config.Conflict == ConflictFail {
+		config.Conflict = ConflictUpdate
+	}
+	return batchCreate(ctx, db, rows, &config, "upsert")
+}
+
+func batchCreate[T any](ctx context.Context, db *gorm.DB, rows []T, opts *BatchOptions, operation string) (BatchResult, error) {
+	if ctx == nil {
+		return BatchResult{}, ErrContextRequired
+	}
+	if db == nil {
+		return BatchResult{}, ErrDBRequired
+	}
+	var config BatchOptions
+	if opts != nil {
+		config = *opts
+	}
+	if len(rows) == 0 {
+		return BatchResult{}, nil
+	}
+
+	base := db.WithContext(ctx)
+	size, err := batchChunkSize(base, rows, config)
+	if err != nil {
+		return BatchResult{}, err
+	}
+	onConflict := conflictClause(config)
+	plugin, _ := db.Config.Plugins[observabilityPluginName].(*observabilityPlugin)
+
+	var result BatchResult
+	for offset := 0; offset < len(rows); offset += size {
+		chunk := rows[offset:min(offset+size, len(rows))]
+		tx := base
+		if onConflict != nil {
+			tx = tx.Clauses(*onConflict)
+		}
+		res := tx.Create(chunk)
+		result.Chunks++
+		result.RowsAffected += res.RowsAffected
+		plugin.observeBatch(operation, len(chunk), res.Error)
+
+		if res.Error != nil {
+			result.Failed = append(result.Failed, &ChunkError{Index: result.Chunks - 1, Offset: offset, Len: len(chunk), Err: res.Error})
+			if !config.ContinueOnError || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	if len(result.Failed) == 0 {
+		return result, nil
+	}
+	errs := make([]error, len(result.Failed))
+	for i, failed := range result.Failed {
+		errs[i] = failed
+	}
+	return result, errors.Join(errs...)
+}
+
+func batchChunkSize[T any](db *gorm.DB, rows []T, config BatchOptions) (int, error) {
+	size := config.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	limit := config.MaxPlaceholders
+	if limit <= 0 {
+		limit = defaultMaxPlaceholders
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&rows); err != nil {
+		return 0, fmt.Errorf("gormx: parse batch model: %w", err)
+	}
+	if columns := len(stmt.Schema.DBNames); columns > 0 {
+		size = min(size, max(limit/columns, 1))
+	}
+	return size, nil
+}
+
+func conflictClause(config BatchOptions) *clause.OnConflict {
+	var onConflict clause.OnConflict
+	for _, column := range config.ConflictColumns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	switch config.Conflict {
+	case ConflictIgnore:
+		onConflict.DoNothing = true
+	case ConflictUpdate:
+		if len(config.UpdateColumns) > 0 {
+			onConflict.DoUpdates = clause.AssignmentColumns(config.UpdateColumns)
+		} else {
+			onConflict.UpdateAll = true
+		}
+	default:
+		return nil
+	}
+	return &onConflict
+}
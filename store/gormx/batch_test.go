@@ -0,0 +1,101 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/gorm"
+)
+
+type batchItem struct {
+	ID    uint   `gorm:"primaryKey"`
+	SKU   string `gorm:"uniqueIndex"`
+	Stock int
+	Note  string
+}
+
+func TestBatchInsertAndUpsert(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	client, err := New(&Config{
+		Name:              "local",
+		Driver:            DriverSQLite,
+		DSN:               "file::memory:",
+		MaxOpenConns:      1,
+		GormConfig:        &gorm.Config{SkipDefaultTransaction: true},
+		MetricsRegisterer: reg,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	db := client.WithContext(ctx)
+	if err := db.AutoMigrate(&batchItem{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+
+	items := make([]batchItem, 10)
+	for i := range items {
+		items[i] = batchItem{SKU: string(rune('a' + i)), Stock: i}
+	}
+	// Four columns under a limit of eight placeholders give chunks of two.
+	result, err := BatchInsert(ctx, client.DB(), items, &BatchOptions{BatchSize: 3, MaxPlaceholders: 8})
+	if err != nil {
+		t.Fatalf("BatchInsert() error = %v", err)
+	}
+	if result.Chunks != 5 || result.RowsAffected != 10 || items[9].ID == 0 {
+		t.Fatalf("BatchInsert() = %+v, last id = %d", result, items[9].ID)
+	}
+
+	dupes := []*batchItem{{SKU: "a", Stock: 100, Note: "dupe"}, {SKU: "z", Stock: 1}}
+	_, err = BatchInsert(ctx, client.DB(), dupes, &BatchOptions{BatchSize: 1, ContinueOnError: true})
+	var chunkErr *ChunkError
+	if !errors.As(err, &chunkErr) || chunkErr.Index != 0 || chunkErr.Offset != 0 || chunkErr.Len != 1 {
+		t.Fatalf("BatchInsert() duplicate error = %v", err)
+	}
+
+	ignored := []batchItem{{SKU: "b", Stock: 100}, {SKU: "y", Stock: 1}}
+	if _, err := BatchInsert(ctx, client.DB(), ignored, &BatchOptions{Conflict: ConflictIgnore}); err != nil {
+		t.Fatalf("BatchInsert() ignore error = %v", err)
+	}
+
+	updates := []batchItem{{SKU: "c", Stock: 100, Note: "kept"}, {SKU: "x", Stock: 7}}
+	if _, err := BatchUpsert(ctx, client.DB(), updates, &BatchOptions{
+		ConflictColumns: []string{"sku"},
+		UpdateColumns:   []string{"stock"},
+	}); err != nil {
+		t.Fatalf("BatchUpsert() error = %v", err)
+	}
+
+	stock := func(sku string) (item batchItem) {
+		t.Helper()
+		if err := db.Where("sku = ?", sku).First(&item).Error; err != nil {
+			t.Fatalf("load %s: %v", sku, err)
+		}
+		return item
+	}
+	if got := stock("b"); got.Stock != 1 {
+		t.Fatalf("ignored conflict overwrote stock = %d", got.Stock)
+	}
+	if got := stock("c"); got.Stock != 100 || got.Note != "" {
+		t.Fatalf("upserted row = %+v, want stock updated and note untouched", got)
+	}
+	for _, sku := range []string{"z", "y", "x"} {
+		stock(sku)
+	}
+
+	m := newGORMMetrics(reg)
+	if got := testutil.ToFloat64(m.dbBatchRows.WithLabelValues("local", "insert", "success")); got != 13 {
+		t.Fatalf("inserted rows metric = %v, want 13", got)
+	}
+	if got := testutil.ToFloat64(m.dbBatchChunks.WithLabelValues("local", "insert", "error")); got != 1 {
+		t.Fatalf("failed chunks metric = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.dbBatchChunks.WithLabelValues("local", "upsert", "success")); got != 1 {
+		t.Fatalf("upsert chunks metric = %v, want 1", got)
+	}
+}
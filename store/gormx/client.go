@@ -13,9 +13,6 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
-	"gorm.io/driver/mysql"
-	"gorm.io/driver/postgres"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	otgorm "gorm.io/plugin/opentelemetry/tracing"
 )
@@ -56,6 +53,20 @@ type Config struct {
 	SlowThreshold     time.Duration
 	DisableMetrics    bool
 	MetricsRegisterer prometheus.Registerer
+
+	// KillOnCancel cancels the statement on the server when its context ends,
+	// with KILL QUERY on MySQL and a cancel request on Postgres. Without it
+	// the driver only drops the connection and the query keeps running.
+	// Requires Driver and DSN; SQLite already interrupts on its own, and
+	// drivers added with RegisterDriver are opened unchanged.
+	KillOnCancel bool
+	// KillTimeout bounds each kill; defaults to 5s.
+	KillTimeout time.Duration
+
+	// LockWaitSampleInterval polls information_schema.innodb_trx at this
+	// interval and exports the number of lock-waiting transactions and the
+	// longest wait. MySQL only; zero disables it, as do disabled metrics.
+	LockWaitSampleInterval time.Duration
 }
 
 type Client interface {
@@ -69,8 +80,10 @@ type Client interface {
 }
 
 type clientEntity struct {
-	db    *gorm.DB
-	sqlDB *sql.DB
+	db        *gorm.DB
+	sqlDB     *sql.DB
+	closeKill func() error
+	stopLock  func()
 
 	closeOnce sync.Once
 	closeErr  error
@@ -93,29 +106,36 @@ func Open(ctx context.Context, conf *Config) (Client, error) {
 		}
 	}
 
+	closeKill := func() error { return nil }
+	if config.KillOnCancel {
+		dialector, closeKill, err = buildKillDialector(config, metrics)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
-		return nil, fmt.Errorf("gormx: open database: %w", err)
+		return nil, errors.Join(fmt.Errorf("gormx: open database: %w", err), closeKill())
 	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
-		return nil, fmt.Errorf("gormx: sql db: %w", err)
+		return nil, errors.Join(fmt.Errorf("gormx: sql db: %w", err), closeKill())
 	}
 
 	configurePool(sqlDB, config)
 
 	cleanup := func(cause error) error {
-		closeErr := sqlDB.Close()
-		if closeErr != nil {
-			return errors.Join(cause, closeErr)
-		}
-		return cause
+		return errors.Join(cause, sqlDB.Close(), closeKill())
 	}
 
 	if err := db.Use(newObservabilityPlugin(config, metrics)); err != nil {
 		return nil, cleanup(fmt.Errorf("gormx: register observability plugin: %w", err))
 	}
+	if metrics != nil {
+		observeTransactions(db, config.Name, metrics)
+	}
 
 	if config.Trace {
 		tracePlugin := buildTracePlugin(config)
@@ -125,8 +145,10 @@ func Open(ctx context.Context, conf *Config) (Client, error) {
 	}
 
 	client := &clientEntity{
-		db:    db,
-		sqlDB: sqlDB,
+		db:        db,
+		sqlDB:     sqlDB,
+		closeKill: closeKill,
+		stopLock:  func() {},
 	}
 
 	if !config.SkipPing {
@@ -137,6 +159,17 @@ func Open(ctx context.Context, conf *Config) (Client, error) {
 		}
 	}
 
+	if config.LockWaitSampleInterval > 0 && metrics != nil {
+		client.stopLock = startLockWaitSampler(&lockWaitSampler{
+			db:       sqlDB,
+			name:     config.Name,
+			interval: config.LockWaitSampleInterval,
+			query:    mysqlLockWaitQuery,
+			metrics:  metrics,
+			logger:   config.Logger,
+		})
+	}
+
 	return client, nil
 }
 
@@ -176,7 +209,8 @@ func (c *clientEntity) Use(plugin gorm.Plugin) error {
 
 func (c *clientEntity) Close() error {
 	c.closeOnce.Do(func() {
-		c.closeErr = c.sqlDB.Close()
+		c.stopLock()
+		c.closeErr = errors.Join(c.sqlDB.Close(), c.closeKill())
 	})
 	return c.closeErr
 }
@@ -198,11 +232,20 @@ func prepareConfig(conf *Config) (*Config, gorm.Dialector, *gorm.Config, error)
 	if cloned.SlowThreshold == 0 {
 		cloned.SlowThreshold = defaultSlowThreshold
 	}
+	if cloned.KillTimeout <= 0 {
+		cloned.KillTimeout = defaultKillTimeout
+	}
+	if cloned.KillOnCancel && cloned.Dialector != nil {
+		return nil, nil, nil, ErrKillOnCancelDialector
+	}
 
 	dialector, err := buildDialector(&cloned)
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	if cloned.LockWaitSampleInterval > 0 && dialector.Name() != DriverMySQL {
+		return nil, nil, nil, ErrLockWaitDriver
+	}
 
 	cloned.Name = defaultName(cloned.Name, cloned.Driver, dialector)
 
@@ -223,16 +266,11 @@ func buildDialector(conf *Config) (gorm.Dialector, error) {
 		return nil, ErrDSNRequired
 	}
 
-	switch conf.Driver {
-	case DriverMySQL:
-		return mysql.Open(conf.DSN), nil
-	case DriverPostgres:
-		return postgres.Open(conf.DSN), nil
-	case DriverSQLite:
-		return sqlite.Open(conf.DSN), nil
-	default:
+	entry, ok := lookupDriver(conf.Driver)
+	if !ok {
 		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, conf.Driver)
 	}
+	return entry.open(conf.DSN), nil
 }
 
 func configurePool(sqlDB *sql.DB, conf *Config) {
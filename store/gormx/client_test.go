@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bang-go/micro/pkg/budget"
 	"github.com/bang-go/micro/store/gormx"
 	"github.com/bang-go/micro/telemetry/logger"
 	"github.com/prometheus/client_golang/prometheus"
@@ -181,6 +182,114 @@ func TestClientUsePlugin(t *testing.T) {
 	}
 }
 
+func TestQueriesAreCappedByDeadlineBudget(t *testing.T) {
+	client, err := gormx.New(&gormx.Config{
+		Driver:         gormx.DriverSQLite,
+		DSN:            "file:budget?mode=memory&cache=shared",
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer client.Close()
+
+	db := client.DB()
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	var remaining time.Duration
+	if err := db.Callback().Query().Before("gorm:query").After("gormx:before_query").Register("test:deadline", func(db *gorm.DB) {
+		deadline, _ := db.Statement.Context.Deadline()
+		remaining = time.Until(deadline)
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ctx := budget.WithPolicy(parent, budget.Policy{Fraction: 0.5})
+	var users []testUser
+	if err := db.WithContext(ctx).Find(&users).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if remaining <= 0 || remaining > 500*time.Millisecond {
+		t.Fatalf("query deadline in %v, want within half of the 1s budget", remaining)
+	}
+}
+
+func TestReusedQueryChainUnderDeadlineBudget(t *testing.T) {
+	client, err := gormx.New(&gormx.Config{
+		Driver:         gormx.DriverSQLite,
+		DSN:            "file:budget_chain?mode=memory&cache=shared",
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer client.Close()
+
+	db := client.DB()
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.Create(&testUser{Email: "a@example.com", Name: "a"}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ctx := budget.WithPolicy(parent, budget.Policy{Fraction: 0.5})
+	query := db.WithContext(ctx).Model(&testUser{}).Where("name = ?", "a")
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	// Count's budget context is cancelled by now; Find must not inherit it.
+	var users []testUser
+	if err := query.Find(&users).Error; err != nil {
+		t.Fatalf("find after count: %v", err)
+	}
+	if total != 1 || len(users) != 1 {
+		t.Fatalf("count = %d, users = %d, want 1 and 1", total, len(users))
+	}
+}
+
+func TestRowQueriesKeepCallerContext(t *testing.T) {
+	client, err := gormx.New(&gormx.Config{
+		Driver:         gormx.DriverSQLite,
+		DSN:            "file:budget_row?mode=memory&cache=shared",
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer client.Close()
+
+	db := client.DB()
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ctx := budget.WithPolicy(parent, budget.Policy{Fraction: 0.5})
+	var statementCtx context.Context
+	if err := db.Callback().Row().Before("gorm:row").After("gormx:before_row").Register("test:row_context", func(db *gorm.DB) {
+		statementCtx = db.Statement.Context
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	rows, err := db.WithContext(ctx).Model(&testUser{}).Rows()
+	if err != nil {
+		t.Fatalf("rows: %v", err)
+	}
+	defer rows.Close()
+	// A derived budget context could never be cancelled once Rows returns.
+	if statementCtx != ctx {
+		t.Fatal("row query context was wrapped in a budget context")
+	}
+}
+
 func TestLoggerDoesNotLeakQueryValues(t *testing.T) {
 	var logs safeBuffer
 	client, err := gormx.New(&gormx.Config{
@@ -257,16 +366,23 @@ func TestTracePluginCreatesSpanWithoutQueryVariables(t *testing.T) {
 	}
 
 	var queryText string
+	var rows int64 = -1
 	for _, span := range spans {
 		for _, attr := range span.Attributes() {
-			if string(attr.Key) == "db.query.text" {
+			switch string(attr.Key) {
+			case "db.query.text":
 				queryText = attr.Value.AsString()
+			case "db.rows":
+				rows = attr.Value.AsInt64()
 			}
 		}
 	}
 	if queryText == "" {
 		t.Fatal("db.query.text attribute missing")
 	}
+	if rows != 1 {
+		t.Fatalf("db.rows = %d, want 1", rows)
+	}
 	if strings.Contains(queryText, "trace@example.com") {
 		t.Fatalf("span leaked query variable: %q", queryText)
 	}
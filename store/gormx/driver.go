@@ -0,0 +1,61 @@
+package gormx
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// OpenFunc builds a dialector from Config.DSN, e.g. sqlserver.Open.
+type OpenFunc func(dsn string) gorm.Dialector
+
+type killFunc func(conf *Config, k *killer) (gorm.Dialector, func() error, error)
+
+type driverEntry struct {
+	open OpenFunc
+	kill killFunc
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]driverEntry{}
+)
+
+// RegisterDriver makes a dialect selectable through Config.Driver, replacing
+// a driver registered under the same name. The built-in mysql, postgres and
+// sqlite drivers can be left out of a binary with the gormx_nomysql,
+// gormx_nopostgres and gormx_nosqlite build tags.
+func RegisterDriver(name string, open OpenFunc) {
+	registerDriver(name, open, nil)
+}
+
+func registerDriver(name string, open OpenFunc, kill killFunc) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || open == nil {
+		panic("gormx: driver name and open func are required")
+	}
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[name] = driverEntry{open: open, kill: kill}
+}
+
+func lookupDriver(name string) (driverEntry, bool) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	entry, ok := drivers[name]
+	return entry, ok
+}
+
+// Drivers returns the registered driver names, sorted.
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
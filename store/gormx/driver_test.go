@@ -0,0 +1,35 @@
+package gormx_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/bang-go/micro/store/gormx"
+	"gorm.io/driver/sqlite"
+)
+
+func TestRegisterDriver(t *testing.T) {
+	gormx.RegisterDriver("Custom-SQLite", sqlite.Open)
+	if !slices.Contains(gormx.Drivers(), "custom-sqlite") {
+		t.Fatalf("Drivers() = %v, want custom-sqlite", gormx.Drivers())
+	}
+
+	client, err := gormx.Open(context.Background(), &gormx.Config{
+		Driver:         "custom-sqlite",
+		DSN:            "file::memory:",
+		KillOnCancel:   true,
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer client.Close()
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	if _, err := gormx.Open(context.Background(), &gormx.Config{Driver: "oracle", DSN: "x"}); err == nil {
+		t.Fatal("Open() accepted an unregistered driver")
+	}
+}
@@ -6,7 +6,16 @@ var (
 	ErrNilConfig         = errors.New("gormx: config is required")
 	ErrContextRequired   = errors.New("gormx: context is required")
 	ErrNilPlugin         = errors.New("gormx: plugin is required")
+	ErrDBRequired        = errors.New("gormx: db is required")
 	ErrDriverRequired    = errors.New("gormx: driver or dialector is required")
 	ErrDSNRequired       = errors.New("gormx: dsn is required when dialector is not provided")
 	ErrUnsupportedDriver = errors.New("gormx: unsupported driver")
+
+	ErrKillOnCancelDialector = errors.New("gormx: kill on cancel requires driver and dsn instead of a dialector")
+	ErrLockWaitDriver        = errors.New("gormx: lock wait sampling requires mysql")
+
+	ErrIDGeneratorRequired = errors.New("gormx: id generator is required")
+	ErrIDTableRequired     = errors.New("gormx: id rule table is required")
+	ErrIDFieldNotFound     = errors.New("gormx: id field not found")
+	ErrUnsupportedIDType   = errors.New("gormx: unsupported id field type")
 )
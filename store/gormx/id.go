@@ -0,0 +1,169 @@
+package gormx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/bang-go/micro/pkg/uid"
+	"gorm.io/gorm"
+)
+
+type IDRule struct {
+	Field     string
+	Generator uid.Generator
+}
+
+type IDConfig struct {
+	Default *IDRule
+	Tables  map[string]IDRule
+}
+
+type idPlugin struct {
+	defaultRule *IDRule
+	tables      map[string]IDRule
+}
+
+func NewIDPlugin(conf *IDConfig) (gorm.Plugin, error) {
+	if conf == nil {
+		return nil, ErrNilConfig
+	}
+
+	plugin := &idPlugin{tables: make(map[string]IDRule, len(conf.Tables))}
+	if conf.Default != nil {
+		rule, err := prepareIDRule(*conf.Default)
+		if err != nil {
+			return nil, err
+		}
+		plugin.defaultRule = &rule
+	}
+	for table, rule := range conf.Tables {
+		table = strings.TrimSpace(table)
+		if table == "" {
+			return nil, ErrIDTableRequired
+		}
+		prepared, err := prepareIDRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("%w: table %s", err, table)
+		}
+		plugin.tables[table] = prepared
+	}
+	return plugin, nil
+}
+
+func (p *idPlugin) Name() string {
+	return "gormx.id"
+}
+
+func (p *idPlugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Create().Before("gorm:create").Register("gormx:fill_id", p.fill)
+}
+
+func (p *idPlugin) fill(db *gorm.DB) {
+	if db.Error != nil || db.Statement == nil || db.Statement.Schema == nil {
+		return
+	}
+
+	rule, ok := p.rule(db.Statement.Schema.Table)
+	if !ok {
+		return
+	}
+
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if rule.Field != "" {
+		field = db.Statement.Schema.LookUpField(rule.Field)
+	}
+	if field == nil {
+		_ = db.AddError(fmt.Errorf("%w: %s", ErrIDFieldNotFound, db.Statement.Schema.Table))
+		return
+	}
+
+	ctx := normalizeContext(db.Statement.Context)
+	reflectValue := reflect.Indirect(db.Statement.ReflectValue)
+	switch reflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < reflectValue.Len(); i++ {
+			item := reflect.Indirect(reflectValue.Index(i))
+			if item.Kind() != reflect.Struct {
+				continue
+			}
+			if err := fillIDField(field.ReflectValueOf(ctx, item), rule.Generator); err != nil {
+				_ = db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := fillIDField(field.ReflectValueOf(ctx, reflectValue), rule.Generator); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+}
+
+func (p *idPlugin) rule(table string) (IDRule, bool) {
+	if rule, ok := p.tables[table]; ok {
+		return rule, true
+	}
+	if p.defaultRule != nil {
+		return *p.defaultRule, true
+	}
+	return IDRule{}, false
+}
+
+func prepareIDRule(rule IDRule) (IDRule, error) {
+	rule.Field = strings.TrimSpace(rule.Field)
+	if rule.Generator == nil {
+		return IDRule{}, ErrIDGeneratorRequired
+	}
+	return rule, nil
+}
+
+func fillIDField(target reflect.Value, generator uid.Generator) error {
+	if !target.CanSet() || !target.IsZero() {
+		return nil
+	}
+
+	id, err := generator.Generate()
+	if err != nil {
+		return fmt.Errorf("gormx: generate id: %w", err)
+	}
+	return assignID(target, id)
+}
+
+func assignID(target reflect.Value, id uid.ID) error {
+	switch target.Kind() {
+	case reflect.Pointer:
+		value := reflect.New(target.Type().Elem())
+		if err := assignID(value.Elem(), id); err != nil {
+			return err
+		}
+		target.Set(value)
+	case reflect.String:
+		target.SetString(id.String())
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		number, err := id.Int64()
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrUnsupportedIDType, target.Type())
+		}
+		target.SetInt(number)
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		number, err := id.Int64()
+		if err != nil || number < 0 {
+			return fmt.Errorf("%w: %s", ErrUnsupportedIDType, target.Type())
+		}
+		target.SetUint(uint64(number))
+	case reflect.Slice:
+		if target.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("%w: %s", ErrUnsupportedIDType, target.Type())
+		}
+		target.SetBytes(id.Bytes())
+	case reflect.Array:
+		raw := id.Bytes()
+		if target.Type().Elem().Kind() != reflect.Uint8 || target.Len() != len(raw) {
+			return fmt.Errorf("%w: %s", ErrUnsupportedIDType, target.Type())
+		}
+		reflect.Copy(target, reflect.ValueOf(raw))
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedIDType, target.Type())
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package gormx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bang-go/micro/pkg/uid"
+	"github.com/bang-go/micro/store/gormx"
+	"github.com/google/uuid"
+)
+
+type uuidOrder struct {
+	ID   string `gorm:"primaryKey;size:36"`
+	Name string
+}
+
+type binaryOrder struct {
+	ID   []byte `gorm:"primaryKey;type:binary(16)"`
+	Name string
+}
+
+type snowflakeOrder struct {
+	ID   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name string
+}
+
+type arrayOrder struct {
+	ID   uuid.UUID `gorm:"primaryKey;type:varchar(36)"`
+	Name string
+}
+
+func TestIDPluginFillsConfiguredFields(t *testing.T) {
+	flake, err := uid.NewSnowflake(&uid.SnowflakeConfig{Node: 3})
+	if err != nil {
+		t.Fatalf("NewSnowflake: %v", err)
+	}
+
+	client, err := gormx.Open(context.Background(), &gormx.Config{
+		Driver:         gormx.DriverSQLite,
+		DSN:            "file:id_plugin?mode=memory&cache=shared",
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer client.Close()
+
+	plugin, err := gormx.NewIDPlugin(&gormx.IDConfig{
+		Default: &gormx.IDRule{Generator: uid.UUIDv7()},
+		Tables: map[string]gormx.IDRule{
+			"binary_orders":    {Generator: uid.UUIDv4()},
+			"snowflake_orders": {Generator: flake},
+			"array_orders":     {Generator: uid.UUIDv4()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewIDPlugin: %v", err)
+	}
+	if err := client.Use(plugin); err != nil {
+		t.Fatalf("use: %v", err)
+	}
+
+	db := client.WithContext(context.Background())
+	if err := db.AutoMigrate(&uuidOrder{}, &binaryOrder{}, &snowflakeOrder{}, &arrayOrder{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	order := &uuidOrder{Name: "a"}
+	if err := db.Create(order).Error; err != nil {
+		t.Fatalf("create uuid order: %v", err)
+	}
+	if _, err := uuid.Parse(order.ID); err != nil {
+		t.Fatalf("expected uuid id, got %q", order.ID)
+	}
+
+	preset := &uuidOrder{ID: "preset", Name: "b"}
+	if err := db.Create(preset).Error; err != nil {
+		t.Fatalf("create preset order: %v", err)
+	}
+	if preset.ID != "preset" {
+		t.Fatalf("expected preset id to be kept, got %q", preset.ID)
+	}
+
+	binaries := []binaryOrder{{Name: "x"}, {Name: "y"}}
+	if err := db.Create(&binaries).Error; err != nil {
+		t.Fatalf("create binary orders: %v", err)
+	}
+	for _, item := range binaries {
+		if len(item.ID) != 16 {
+			t.Fatalf("expected 16 byte binary id, got %v", item.ID)
+		}
+	}
+	var loaded binaryOrder
+	if err := db.Where("id = ?", binaries[0].ID).First(&loaded).Error; err != nil {
+		t.Fatalf("load binary order: %v", err)
+	}
+	if loaded.Name != "x" {
+		t.Fatalf("loaded.Name = %q, want x", loaded.Name)
+	}
+
+	flakeOrder := &snowflakeOrder{Name: "z"}
+	if err := db.Create(flakeOrder).Error; err != nil {
+		t.Fatalf("create snowflake order: %v", err)
+	}
+	if flakeOrder.ID <= 0 {
+		t.Fatalf("expected snowflake id, got %d", flakeOrder.ID)
+	}
+
+	arrayItem := &arrayOrder{Name: "w"}
+	if err := db.Create(arrayItem).Error; err != nil {
+		t.Fatalf("create array order: %v", err)
+	}
+	if arrayItem.ID == uuid.Nil || arrayItem.ID.Version() != 4 {
+		t.Fatalf("expected uuid v4 array id, got %s", arrayItem.ID)
+	}
+}
+
+func TestIDPluginValidation(t *testing.T) {
+	if _, err := gormx.NewIDPlugin(nil); !errors.Is(err, gormx.ErrNilConfig) {
+		t.Fatalf("NewIDPlugin(nil) error = %v", err)
+	}
+	if _, err := gormx.NewIDPlugin(&gormx.IDConfig{Default: &gormx.IDRule{}}); !errors.Is(err, gormx.ErrIDGeneratorRequired) {
+		t.Fatalf("expected ErrIDGeneratorRequired, got %v", err)
+	}
+	if _, err := gormx.NewIDPlugin(&gormx.IDConfig{Tables: map[string]gormx.IDRule{" ": {Generator: uid.ULID()}}}); !errors.Is(err, gormx.ErrIDTableRequired) {
+		t.Fatalf("expected ErrIDTableRequired, got %v", err)
+	}
+
+	client, err := gormx.Open(context.Background(), &gormx.Config{
+		Driver:         gormx.DriverSQLite,
+		DSN:            "file:id_plugin_errors?mode=memory&cache=shared",
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer client.Close()
+
+	plugin, err := gormx.NewIDPlugin(&gormx.IDConfig{
+		Tables: map[string]gormx.IDRule{
+			"snowflake_orders": {Generator: uid.ULID()},
+			"uuid_orders":      {Field: "Missing", Generator: uid.ULID()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewIDPlugin: %v", err)
+	}
+	if err := client.Use(plugin); err != nil {
+		t.Fatalf("use: %v", err)
+	}
+
+	db := client.WithContext(context.Background())
+	if err := db.AutoMigrate(&uuidOrder{}, &snowflakeOrder{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.Create(&snowflakeOrder{Name: "z"}).Error; !errors.Is(err, gormx.ErrUnsupportedIDType) {
+		t.Fatalf("expected ErrUnsupportedIDType, got %v", err)
+	}
+	if err := db.Create(&uuidOrder{Name: "z"}).Error; !errors.Is(err, gormx.ErrIDFieldNotFound) {
+		t.Fatalf("expected ErrIDFieldNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package gormx
+
+import (
+	"context"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"gorm.io/gorm"
+)
+
+const defaultKillTimeout = 5 * time.Second
+
+// killer reports server-side cancellations of statements whose context ended.
+type killer struct {
+	name    string
+	timeout time.Duration
+	logger  *logger.Logger
+	metrics *metrics
+}
+
+func (k *killer) record(err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+		k.logger.Warn(context.Background(), "db query kill failed", "db", k.name, "error", err)
+	}
+	if k.metrics != nil {
+		k.metrics.dbQueryKills.WithLabelValues(k.name, result).Inc()
+	}
+}
+
+// buildKillDialector opens the pool for conf.Driver with a driver hook that
+// cancels the running statement on the server when its context ends. The
+// returned closer releases resources the dialector does not own.
+func buildKillDialector(conf *Config, metrics *metrics) (gorm.Dialector, func() error, error) {
+	entry, ok := lookupDriver(conf.Driver)
+	if !ok || entry.kill == nil {
+		// SQLite interrupts the running statement on context cancellation by
+		// itself; other registered drivers are opened unchanged.
+		dialector, err := buildDialector(conf)
+		return dialector, func() error { return nil }, err
+	}
+	k := &killer{name: conf.Name, timeout: conf.KillTimeout, logger: conf.Logger, metrics: metrics}
+	return entry.kill(conf, k)
+}
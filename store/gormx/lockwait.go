@@ -0,0 +1,70 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+)
+
+const (
+	maxLockWaitSampleTimeout = 5 * time.Second
+
+	mysqlLockWaitQuery = "SELECT COUNT(*), COALESCE(MAX(TIMESTAMPDIFF(SECOND, trx_wait_started, NOW())), 0) " +
+		"FROM information_schema.innodb_trx WHERE trx_state = 'LOCK WAIT'"
+)
+
+// lockWaitSampler polls InnoDB for transactions waiting on row locks. It sees
+// every session on the server, not only this client's, which is what makes
+// contention from other services visible.
+type lockWaitSampler struct {
+	db       *sql.DB
+	name     string
+	interval time.Duration
+	query    string
+	metrics  *metrics
+	logger   *logger.Logger
+}
+
+func startLockWaitSampler(s *lockWaitSampler) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.sample(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (s *lockWaitSampler) sample(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, min(s.interval, maxLockWaitSampleTimeout))
+	defer cancel()
+
+	var (
+		waiting    int64
+		maxSeconds float64
+	)
+	if err := s.db.QueryRowContext(ctx, s.query).Scan(&waiting, &maxSeconds); err != nil {
+		if parent.Err() != nil {
+			return
+		}
+		s.metrics.dbLockWaitSampleErrors.WithLabelValues(s.name).Inc()
+		s.logger.Warn(parent, "db lock wait sample failed", "db", s.name, "error", err)
+		return
+	}
+	s.metrics.dbLockWaits.WithLabelValues(s.name).Set(float64(waiting))
+	s.metrics.dbLockWaitMaxSeconds.WithLabelValues(s.name).Set(maxSeconds)
+}
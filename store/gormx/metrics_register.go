@@ -9,6 +9,17 @@ import (
 type metrics struct {
 	dbRequestDuration *prometheus.HistogramVec
 	dbRequestsTotal   *prometheus.CounterVec
+	dbCanceledQueries *prometheus.CounterVec
+	dbQueryKills      *prometheus.CounterVec
+
+	dbTransactionOps       *prometheus.CounterVec
+	dbTransactionDuration  *prometheus.HistogramVec
+	dbLockWaits            *prometheus.GaugeVec
+	dbLockWaitMaxSeconds   *prometheus.GaugeVec
+	dbLockWaitSampleErrors *prometheus.CounterVec
+
+	dbBatchChunks *prometheus.CounterVec
+	dbBatchRows   *prometheus.CounterVec
 }
 
 var (
@@ -40,10 +51,83 @@ func newGORMMetrics(registerer prometheus.Registerer) *metrics {
 			},
 			[]string{"db", "operation", "status", "table"},
 		),
+		dbCanceledQueries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gormx_canceled_queries_total",
+				Help: "Total number of database requests whose context ended before they completed.",
+			},
+			[]string{"db", "operation", "reason"},
+		),
+		dbQueryKills: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gormx_query_kills_total",
+				Help: "Total number of server-side cancellations sent for canceled queries.",
+			},
+			[]string{"db", "result"},
+		),
+		dbTransactionOps: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gormx_transactions_total",
+				Help: "Total number of transaction begin, commit and rollback operations.",
+			},
+			[]string{"db", "operation", "status"},
+		),
+		dbTransactionDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "gormx_transaction_duration_seconds",
+				Help:    "Transaction duration from begin to commit or rollback in seconds.",
+				Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			},
+			[]string{"db", "outcome"},
+		),
+		dbLockWaits: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "gormx_lock_waits",
+				Help: "Number of InnoDB transactions currently waiting for a row lock.",
+			},
+			[]string{"db"},
+		),
+		dbLockWaitMaxSeconds: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "gormx_lock_wait_max_seconds",
+				Help: "Longest current InnoDB row lock wait in seconds.",
+			},
+			[]string{"db"},
+		),
+		dbLockWaitSampleErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gormx_lock_wait_sample_errors_total",
+				Help: "Total number of failed lock wait samples.",
+			},
+			[]string{"db"},
+		),
+		dbBatchChunks: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gormx_batch_chunks_total",
+				Help: "Total number of batch insert chunks.",
+			},
+			[]string{"db", "operation", "status"},
+		),
+		dbBatchRows: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gormx_batch_rows_total",
+				Help: "Total number of rows sent in batch insert chunks.",
+			},
+			[]string{"db", "operation", "status"},
+		),
 	}
 
 	mustRegisterCollector(registerer, &m.dbRequestDuration, m.dbRequestDuration)
 	mustRegisterCollector(registerer, &m.dbRequestsTotal, m.dbRequestsTotal)
+	mustRegisterCollector(registerer, &m.dbCanceledQueries, m.dbCanceledQueries)
+	mustRegisterCollector(registerer, &m.dbQueryKills, m.dbQueryKills)
+	mustRegisterCollector(registerer, &m.dbTransactionOps, m.dbTransactionOps)
+	mustRegisterCollector(registerer, &m.dbTransactionDuration, m.dbTransactionDuration)
+	mustRegisterCollector(registerer, &m.dbLockWaits, m.dbLockWaits)
+	mustRegisterCollector(registerer, &m.dbLockWaitMaxSeconds, m.dbLockWaitMaxSeconds)
+	mustRegisterCollector(registerer, &m.dbLockWaitSampleErrors, m.dbLockWaitSampleErrors)
+	mustRegisterCollector(registerer, &m.dbBatchChunks, m.dbBatchChunks)
+	mustRegisterCollector(registerer, &m.dbBatchRows, m.dbBatchRows)
 
 	return m
 }
@@ -0,0 +1,192 @@
+//go:build !gormx_nomysql
+
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+const killPoolSize = 2
+
+func init() {
+	registerDriver(DriverMySQL, mysql.Open, openMySQLKill)
+}
+
+func openMySQLKill(conf *Config, k *killer) (gorm.Dialector, func() error, error) {
+	dsnConfig, err := mysqldriver.ParseDSN(conf.DSN)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gormx: parse mysql dsn: %w", err)
+	}
+	connector, err := mysqldriver.NewConnector(dsnConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gormx: mysql connector: %w", err)
+	}
+	// KILL QUERY must run on a connection other than the one executing
+	// the statement, and must not wait behind a saturated main pool.
+	killDB := sql.OpenDB(connector)
+	killDB.SetMaxOpenConns(killPoolSize)
+	killDB.SetMaxIdleConns(1)
+	sqlDB := sql.OpenDB(&mysqlKillConnector{Connector: connector, killer: k, killDB: killDB})
+	dialector := mysql.New(mysql.Config{DSN: conf.DSN, DSNConfig: dsnConfig, Conn: sqlDB})
+	return dialector, killDB.Close, nil
+}
+
+type mysqlKillConnector struct {
+	driver.Connector
+	killer *killer
+	killDB *sql.DB
+}
+
+func (c *mysqlKillConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := mysqlConnectionID(ctx, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("gormx: read mysql connection id: %w", err)
+	}
+	return &killConn{Conn: conn, id: id, connector: c}, nil
+}
+
+func mysqlConnectionID(ctx context.Context, conn driver.Conn) (uint64, error) {
+	queryer, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return 0, errors.New("driver does not support QueryContext")
+	}
+	rows, err := queryer.QueryContext(ctx, "SELECT CONNECTION_ID()", nil)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		return 0, err
+	}
+	switch id := dest[0].(type) {
+	case int64:
+		return uint64(id), nil
+	case uint64:
+		return id, nil
+	case []byte:
+		var parsed uint64
+		_, err := fmt.Sscan(string(id), &parsed)
+		return parsed, err
+	default:
+		return 0, fmt.Errorf("unexpected connection id type %T", id)
+	}
+}
+
+// killConn issues KILL QUERY for its server thread when the context of a
+// running statement ends. The statement does not return until the kill has
+// finished, so the kill can never hit a later statement on a reused
+// connection.
+type killConn struct {
+	driver.Conn
+	id        uint64
+	connector *mysqlKillConnector
+}
+
+func (c *killConn) watch(ctx context.Context) func() {
+	if ctx.Done() == nil || ctx.Err() != nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(done)
+		c.kill()
+	})
+	return func() {
+		if !stop() {
+			<-done
+		}
+	}
+}
+
+func (c *killConn) kill() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.connector.killer.timeout)
+	defer cancel()
+	_, err := c.connector.killDB.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", c.id))
+	c.connector.killer.record(err)
+}
+
+func (c *killConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	defer c.watch(ctx)()
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func (c *killConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	defer c.watch(ctx)()
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+func (c *killConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &killStmt{Stmt: stmt, conn: c}, nil
+}
+
+func (c *killConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c *killConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *killConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *killConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *killConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type killStmt struct {
+	driver.Stmt
+	conn *killConn
+}
+
+func (s *killStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	defer s.conn.watch(ctx)()
+	return s.Stmt.(driver.StmtQueryContext).QueryContext(ctx, args)
+}
+
+func (s *killStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	defer s.conn.watch(ctx)()
+	return s.Stmt.(driver.StmtExecContext).ExecContext(ctx, args)
+}
+
+func (s *killStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
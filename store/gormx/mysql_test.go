@@ -0,0 +1,145 @@
+//go:build !gormx_nomysql
+
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeServer stands in for a MySQL server: statements block until their
+// context ends, and KILL QUERY is only recorded after a short delay so tests
+// can tell whether the statement waited for it.
+type fakeServer struct {
+	mu    sync.Mutex
+	kills []string
+}
+
+func (s *fakeServer) Connect(context.Context) (driver.Conn, error) { return &fakeConn{server: s}, nil }
+func (s *fakeServer) Driver() driver.Driver                        { return nil }
+
+func (s *fakeServer) killed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.kills...)
+}
+
+type fakeConn struct {
+	server *fakeServer
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if query == "SELECT CONNECTION_ID()" {
+		return &idRows{}, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if query == "SELECT 1" {
+		return driver.ResultNoRows, nil
+	}
+	if len(query) > 4 && query[:4] == "KILL" {
+		time.Sleep(50 * time.Millisecond)
+		c.server.mu.Lock()
+		c.server.kills = append(c.server.kills, query)
+		c.server.mu.Unlock()
+		return driver.ResultNoRows, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+type idRows struct {
+	done bool
+}
+
+func (r *idRows) Columns() []string { return []string{"id"} }
+func (r *idRows) Close() error      { return nil }
+
+func (r *idRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(42)
+	return nil
+}
+
+func TestKillConnKillsCanceledStatement(t *testing.T) {
+	server := &fakeServer{}
+	m := newGORMMetrics(prometheus.NewRegistry())
+	k := &killer{name: "orders", timeout: time.Second, logger: logger.New(logger.WithLevel("error")), metrics: m}
+	killDB := sql.OpenDB(server)
+	defer killDB.Close()
+	db := sql.OpenDB(&mysqlKillConnector{Connector: server, killer: k, killDB: killDB})
+	defer db.Close()
+
+	if _, err := db.ExecContext(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+	if kills := server.killed(); len(kills) != 0 {
+		t.Fatalf("kills after completed statement = %v", kills)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := db.ExecContext(ctx, "SELECT SLEEP(60)"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+	// The statement returns only once the kill has landed.
+	if kills := server.killed(); len(kills) != 1 || kills[0] != "KILL QUERY 42" {
+		t.Fatalf("kills = %v", kills)
+	}
+	if got := testutil.ToFloat64(m.dbQueryKills.WithLabelValues("orders", "success")); got != 1 {
+		t.Fatalf("kill metric = %v", got)
+	}
+}
+
+func TestCanceledQueriesAreCounted(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	client, err := New(&Config{
+		Name:              "local",
+		Driver:            DriverSQLite,
+		DSN:               "file::memory:",
+		SkipPing:          true,
+		KillOnCancel:      true,
+		MetricsRegisterer: reg,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = client.WithContext(ctx).Exec("WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT count(*) FROM c").Error
+	if err == nil {
+		t.Fatal("unbounded query finished")
+	}
+
+	m := newGORMMetrics(reg)
+	if got := testutil.ToFloat64(m.dbCanceledQueries.WithLabelValues("local", "raw", "deadline_exceeded")); got != 1 {
+		t.Fatalf("canceled metric = %v", got)
+	}
+
+	_, err = New(&Config{Dialector: client.DB().Dialector, KillOnCancel: true, SkipPing: true, DisableMetrics: true})
+	if !errors.Is(err, ErrKillOnCancelDialector) {
+		t.Fatalf("New() with dialector error = %v", err)
+	}
+}
@@ -1,13 +1,21 @@
 package gormx
 
 import (
+	"context"
 	"time"
 
+	"github.com/bang-go/micro/pkg/budget"
 	"github.com/bang-go/micro/telemetry/logger"
+	microtrace "github.com/bang-go/micro/telemetry/trace"
 	"gorm.io/gorm"
 )
 
-const callbackStartTimeKey = "gormx:start_time"
+const (
+	callbackStartTimeKey    = "gormx:start_time"
+	callbackBudgetCancelKey = "gormx:budget_cancel"
+	callbackCallerCtxKey    = "gormx:caller_ctx"
+	observabilityPluginName = "gormx.observability"
+)
 
 type observabilityPlugin struct {
 	name          string
@@ -28,11 +36,11 @@ func newObservabilityPlugin(conf *Config, metrics *metrics) gorm.Plugin {
 }
 
 func (p *observabilityPlugin) Name() string {
-	return "gormx.observability"
+	return observabilityPluginName
 }
 
 func (p *observabilityPlugin) Initialize(db *gorm.DB) error {
-	return registerCallbacks(db, p.before, map[string]func(*gorm.DB){
+	return registerCallbacks(db, p.before, p.start, p.release, map[string]func(*gorm.DB){
 		"create": p.after("create"),
 		"query":  p.after("query"),
 		"update": p.after("update"),
@@ -43,10 +51,33 @@ func (p *observabilityPlugin) Initialize(db *gorm.DB) error {
 }
 
 func (p *observabilityPlugin) before(db *gorm.DB) {
-	db.Statement.Context = normalizeContext(db.Statement.Context)
+	db.InstanceSet(callbackCallerCtxKey, db.Statement.Context)
+	ctx, cancel := budget.Context(normalizeContext(db.Statement.Context), 0)
+	db.Statement.Context = ctx
+	db.InstanceSet(callbackBudgetCancelKey, cancel)
+	p.start(db)
+}
+
+// start only records the start time. Row and Rows are scanned after the
+// callbacks return, so there is no point at which a budget context could be
+// cancelled; they keep the caller's context.
+func (p *observabilityPlugin) start(db *gorm.DB) {
 	db.InstanceSet(callbackStartTimeKey, time.Now())
 }
 
+// release runs after every other callback, so later plugins such as tracing
+// still see the statement context. It cancels the budget context and puts the
+// caller's context back: a reused query chain shares the Statement, and the
+// next call would otherwise run on the cancelled context.
+func (p *observabilityPlugin) release(db *gorm.DB) {
+	if cancel, ok := db.InstanceGet(callbackBudgetCancelKey); ok {
+		cancel.(context.CancelFunc)()
+	}
+	if caller, ok := db.InstanceGet(callbackCallerCtxKey); ok {
+		db.Statement.Context, _ = caller.(context.Context)
+	}
+}
+
 func (p *observabilityPlugin) after(operation string) func(*gorm.DB) {
 	return func(db *gorm.DB) {
 		ctx := normalizeContext(db.Statement.Context)
@@ -61,6 +92,7 @@ func (p *observabilityPlugin) after(operation string) func(*gorm.DB) {
 		}
 
 		duration := time.Since(startTime)
+		microtrace.DBRows(ctx, db.RowsAffected)
 		status := queryStatus(db.Error)
 		table := tableName(db.Statement)
 		query := normalizeSQL(db.Statement.SQL.String())
@@ -68,6 +100,9 @@ func (p *observabilityPlugin) after(operation string) func(*gorm.DB) {
 		if p.metrics != nil {
 			p.metrics.dbRequestDuration.WithLabelValues(p.name, operation, status, table).Observe(duration.Seconds())
 			p.metrics.dbRequestsTotal.WithLabelValues(p.name, operation, status, table).Inc()
+			if db.Error != nil && ctx.Err() != nil {
+				p.metrics.dbCanceledQueries.WithLabelValues(p.name, operation, cancelReason(ctx.Err())).Inc()
+			}
 		}
 
 		fields := []any{
@@ -102,7 +137,7 @@ func (p *observabilityPlugin) after(operation string) func(*gorm.DB) {
 	}
 }
 
-func registerCallbacks(db *gorm.DB, before func(*gorm.DB), afters map[string]func(*gorm.DB)) error {
+func registerCallbacks(db *gorm.DB, before, beforeRow, release func(*gorm.DB), afters map[string]func(*gorm.DB)) error {
 	callbacks := db.Callback()
 
 	if err := callbacks.Create().Before("gorm:create").Register("gormx:before_create", before); err != nil {
@@ -111,25 +146,37 @@ func registerCallbacks(db *gorm.DB, before func(*gorm.DB), afters map[string]fun
 	if err := callbacks.Create().After("gorm:create").Register("gormx:after_create", afters["create"]); err != nil {
 		return err
 	}
+	if err := callbacks.Create().After("*").Register("gormx:release_create", release); err != nil {
+		return err
+	}
 	if err := callbacks.Query().Before("gorm:query").Register("gormx:before_query", before); err != nil {
 		return err
 	}
 	if err := callbacks.Query().After("gorm:query").Register("gormx:after_query", afters["query"]); err != nil {
 		return err
 	}
+	if err := callbacks.Query().After("*").Register("gormx:release_query", release); err != nil {
+		return err
+	}
 	if err := callbacks.Update().Before("gorm:update").Register("gormx:before_update", before); err != nil {
 		return err
 	}
 	if err := callbacks.Update().After("gorm:update").Register("gormx:after_update", afters["update"]); err != nil {
 		return err
 	}
+	if err := callbacks.Update().After("*").Register("gormx:release_update", release); err != nil {
+		return err
+	}
 	if err := callbacks.Delete().Before("gorm:delete").Register("gormx:before_delete", before); err != nil {
 		return err
 	}
 	if err := callbacks.Delete().After("gorm:delete").Register("gormx:after_delete", afters["delete"]); err != nil {
 		return err
 	}
-	if err := callbacks.Row().Before("gorm:row").Register("gormx:before_row", before); err != nil {
+	if err := callbacks.Delete().After("*").Register("gormx:release_delete", release); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("gormx:before_row", beforeRow); err != nil {
 		return err
 	}
 	if err := callbacks.Row().After("gorm:row").Register("gormx:after_row", afters["row"]); err != nil {
@@ -141,5 +188,18 @@ func registerCallbacks(db *gorm.DB, before func(*gorm.DB), afters map[string]fun
 	if err := callbacks.Raw().After("gorm:raw").Register("gormx:after_raw", afters["raw"]); err != nil {
 		return err
 	}
+	if err := callbacks.Raw().After("*").Register("gormx:release_raw", release); err != nil {
+		return err
+	}
 	return nil
 }
+
+// observeBatch is safe on a nil plugin, for databases not opened by gormx.
+func (p *observabilityPlugin) observeBatch(operation string, rows int, err error) {
+	if p == nil || p.metrics == nil {
+		return
+	}
+	status := queryStatus(err)
+	p.metrics.dbBatchChunks.WithLabelValues(p.name, operation, status).Inc()
+	p.metrics.dbBatchRows.WithLabelValues(p.name, operation, status).Add(float64(rows))
+}
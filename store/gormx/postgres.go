@@ -0,0 +1,86 @@
+//go:build !gormx_nopostgres
+
+package gormx
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var pgTimeZonePattern = regexp.MustCompile(`(time_zone|TimeZone)=(.*?)($|&| )`)
+
+func init() {
+	registerDriver(DriverPostgres, postgres.Open, openPostgresKill)
+}
+
+func openPostgresKill(conf *Config, k *killer) (gorm.Dialector, func() error, error) {
+	connConfig, err := pgx.ParseConfig(conf.DSN)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gormx: parse postgres dsn: %w", err)
+	}
+	connConfig.BuildContextWatcherHandler = func(conn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgCancelHandler{conn: conn, killer: k}
+	}
+	sqlDB := stdlib.OpenDB(*connConfig, pgTimeZoneOptions(conf.DSN)...)
+	return postgres.New(postgres.Config{DSN: conf.DSN, Conn: sqlDB}), func() error { return nil }, nil
+}
+
+// pgTimeZoneOptions mirrors the TimeZone handling of gorm's postgres
+// dialector, which is skipped when the pool is passed in.
+func pgTimeZoneOptions(dsn string) []stdlib.OptionOpenDB {
+	match := pgTimeZonePattern.FindStringSubmatch(dsn)
+	if len(match) <= 2 {
+		return nil
+	}
+	return []stdlib.OptionOpenDB{stdlib.OptionAfterConnect(func(_ context.Context, conn *pgx.Conn) error {
+		loc, err := time.LoadLocation(match[2])
+		if err != nil {
+			return err
+		}
+		conn.TypeMap().RegisterType(&pgtype.Type{
+			Name:  "timestamp",
+			OID:   pgtype.TimestampOID,
+			Codec: &pgtype.TimestampCodec{ScanLocation: loc},
+		})
+		return nil
+	})}
+}
+
+// pgCancelHandler sends a cancel request for the backend when a query context
+// ends, keeping the connection usable, and falls back to a socket deadline if
+// the server does not respond within the kill timeout.
+type pgCancelHandler struct {
+	conn   *pgconn.PgConn
+	killer *killer
+	done   chan struct{}
+}
+
+func (h *pgCancelHandler) HandleCancel(context.Context) {
+	h.done = make(chan struct{})
+	deadline := time.Now().Add(h.killer.timeout)
+	_ = h.conn.Conn().SetDeadline(deadline)
+	go func() {
+		defer close(h.done)
+		ctx, cancel := context.WithDeadline(context.Background(), deadline)
+		defer cancel()
+		h.killer.record(h.conn.CancelRequest(ctx))
+		// The server applies the cancel asynchronously; give it time to land
+		// before the connection can run its next statement.
+		time.Sleep(100 * time.Millisecond)
+	}()
+}
+
+func (h *pgCancelHandler) HandleUnwatchAfterCancel() {
+	<-h.done
+	_ = h.conn.Conn().SetDeadline(time.Time{})
+}
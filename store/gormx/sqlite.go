@@ -0,0 +1,9 @@
+//go:build !gormx_nosqlite
+
+package gormx
+
+import "gorm.io/driver/sqlite"
+
+func init() {
+	registerDriver(DriverSQLite, sqlite.Open, nil)
+}
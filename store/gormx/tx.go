@@ -0,0 +1,123 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	txOutcomeCommit        = "commit"
+	txOutcomeRollback      = "rollback"
+	txOutcomeCommitError   = "commit_error"
+	txOutcomeRollbackError = "rollback_error"
+)
+
+// txObservingPool wraps the connection pool so transactions begun through
+// gorm report their begin, commit and rollback; gorm has no callbacks for
+// them.
+type txObservingPool struct {
+	gorm.ConnPool
+	name    string
+	metrics *metrics
+}
+
+func observeTransactions(db *gorm.DB, name string, metrics *metrics) {
+	pool := &txObservingPool{ConnPool: db.ConnPool, name: name, metrics: metrics}
+	db.ConnPool = pool
+	db.Statement.ConnPool = pool
+}
+
+func (p *txObservingPool) GetDBConn() (*sql.DB, error) {
+	return getDBConn(p.ConnPool)
+}
+
+func (p *txObservingPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	start := time.Now()
+	var (
+		tx  gorm.ConnPool
+		err error
+	)
+	switch beginner := p.ConnPool.(type) {
+	case gorm.TxBeginner:
+		var sqlTx *sql.Tx
+		sqlTx, err = beginner.BeginTx(ctx, opts)
+		if sqlTx != nil {
+			tx = sqlTx
+		}
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	default:
+		err = gorm.ErrInvalidTransaction
+	}
+	p.metrics.dbTransactionOps.WithLabelValues(p.name, "begin", queryStatus(err)).Inc()
+	if err != nil {
+		return nil, err
+	}
+	committer, ok := tx.(gorm.TxCommitter)
+	if !ok {
+		return nil, gorm.ErrInvalidTransaction
+	}
+	return &observedTx{ConnPool: tx, committer: committer, pool: p, start: start}, nil
+}
+
+// observedTx implements gorm.Tx so nested transactions use savepoints and
+// prepared statement sessions keep working.
+type observedTx struct {
+	gorm.ConnPool
+	committer gorm.TxCommitter
+	pool      *txObservingPool
+	start     time.Time
+	once      sync.Once
+}
+
+func (t *observedTx) Commit() error {
+	err := t.committer.Commit()
+	t.finish("commit", err, txOutcomeCommit, txOutcomeCommitError)
+	return err
+}
+
+func (t *observedTx) Rollback() error {
+	err := t.committer.Rollback()
+	t.finish("rollback", err, txOutcomeRollback, txOutcomeRollbackError)
+	return err
+}
+
+func (t *observedTx) StmtContext(ctx context.Context, stmt *sql.Stmt) *sql.Stmt {
+	if stmter, ok := t.ConnPool.(interface {
+		StmtContext(context.Context, *sql.Stmt) *sql.Stmt
+	}); ok {
+		return stmter.StmtContext(ctx, stmt)
+	}
+	return stmt
+}
+
+func (t *observedTx) GetDBConn() (*sql.DB, error) {
+	return t.pool.GetDBConn()
+}
+
+// finish records the first commit or rollback; gorm rolls back after a
+// failed commit, which would otherwise count the transaction twice.
+func (t *observedTx) finish(operation string, err error, outcome, errorOutcome string) {
+	t.once.Do(func() {
+		metrics := t.pool.metrics
+		metrics.dbTransactionOps.WithLabelValues(t.pool.name, operation, queryStatus(err)).Inc()
+		if err != nil {
+			outcome = errorOutcome
+		}
+		metrics.dbTransactionDuration.WithLabelValues(t.pool.name, outcome).Observe(time.Since(t.start).Seconds())
+	})
+}
+
+func getDBConn(pool gorm.ConnPool) (*sql.DB, error) {
+	switch pool := pool.(type) {
+	case *sql.DB:
+		return pool, nil
+	case gorm.GetDBConnector:
+		return pool.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
@@ -0,0 +1,138 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/gorm"
+)
+
+type txOrder struct {
+	ID   uint `gorm:"primaryKey"`
+	Note string
+}
+
+func TestTransactionsAreObserved(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	client, err := New(&Config{
+		Name:              "local",
+		Driver:            DriverSQLite,
+		DSN:               "file::memory:",
+		MaxOpenConns:      1,
+		GormConfig:        &gorm.Config{SkipDefaultTransaction: true},
+		MetricsRegisterer: reg,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	db := client.WithContext(context.Background())
+	if err := db.AutoMigrate(&txOrder{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	m := newGORMMetrics(reg)
+	count := func(operation, status string) float64 {
+		return testutil.ToFloat64(m.dbTransactionOps.WithLabelValues("local", operation, status))
+	}
+	begins, commits, rollbacks := count("begin", "success"), count("commit", "success"), count("rollback", "success")
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&txOrder{Note: "outer"}).Error; err != nil {
+			return err
+		}
+		// Nested transactions are savepoints and must not count as new ones.
+		return tx.Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&txOrder{Note: "inner"}).Error
+		})
+	})
+	if err != nil {
+		t.Fatalf("Transaction() error = %v", err)
+	}
+
+	errAbort := errors.New("abort")
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&txOrder{Note: "dropped"}).Error; err != nil {
+			return err
+		}
+		return errAbort
+	}); !errors.Is(err, errAbort) {
+		t.Fatalf("Transaction() error = %v", err)
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatalf("Begin() error = %v", tx.Error)
+	}
+	if err := tx.Rollback().Error; err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	var stored int64
+	if err := db.Model(&txOrder{}).Count(&stored).Error; err != nil || stored != 2 {
+		t.Fatalf("stored rows = %d, err = %v", stored, err)
+	}
+	if got := count("begin", "success") - begins; got != 3 {
+		t.Fatalf("begins = %v", got)
+	}
+	if got := count("commit", "success") - commits; got != 1 {
+		t.Fatalf("commits = %v", got)
+	}
+	if got := count("rollback", "success") - rollbacks; got != 2 {
+		t.Fatalf("rollbacks = %v", got)
+	}
+	if got := testutil.CollectAndCount(m.dbTransactionDuration); got != 2 {
+		t.Fatalf("duration series = %d, want commit and rollback", got)
+	}
+
+	sqlDB, err := client.DB().DB()
+	if err != nil || sqlDB != client.SQLDB() {
+		t.Fatalf("DB() = %p, %v; want the client pool", sqlDB, err)
+	}
+}
+
+func TestLockWaitSampler(t *testing.T) {
+	_, err := New(&Config{Driver: DriverSQLite, DSN: "file::memory:", LockWaitSampleInterval: time.Second, DisableMetrics: true})
+	if !errors.Is(err, ErrLockWaitDriver) {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	client, err := New(&Config{Driver: DriverSQLite, DSN: "file::memory:", DisableMetrics: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	m := newGORMMetrics(prometheus.NewRegistry())
+	s := &lockWaitSampler{
+		db:       client.SQLDB(),
+		name:     "orders",
+		interval: time.Second,
+		query:    "SELECT 2, 1.5",
+		metrics:  m,
+		logger:   logger.New(logger.WithLevel("error")),
+	}
+	s.sample(context.Background())
+	if got := testutil.ToFloat64(m.dbLockWaits.WithLabelValues("orders")); got != 2 {
+		t.Fatalf("lock waits = %v", got)
+	}
+	if got := testutil.ToFloat64(m.dbLockWaitMaxSeconds.WithLabelValues("orders")); got != 1.5 {
+		t.Fatalf("max wait = %v", got)
+	}
+
+	s.query = "SELECT * FROM information_schema.innodb_trx"
+	stop := startLockWaitSampler(s)
+	deadline := time.Now().Add(2 * time.Second)
+	for testutil.ToFloat64(m.dbLockWaitSampleErrors.WithLabelValues("orders")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("failed sample was not counted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	stop()
+}
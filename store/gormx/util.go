@@ -91,6 +91,13 @@ func queryStatus(err error) string {
 	}
 }
 
+func cancelReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "deadline_exceeded"
+	}
+	return "canceled"
+}
+
 func tracingAttributes(name string, attrs []attribute.KeyValue) []attribute.KeyValue {
 	all := make([]attribute.KeyValue, 0, len(attrs)+1)
 	all = append(all, attribute.String("micro.db.name", name))
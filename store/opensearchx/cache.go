@@ -0,0 +1,235 @@
+package opensearchx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bang-go/micro/store/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultCachePrefix         = "opensearchx:"
+	defaultCacheTTL            = time.Minute
+	defaultCacheStaleTTL       = 10 * time.Minute
+	defaultCacheRefreshTimeout = 10 * time.Second
+)
+
+var ErrCacheClientRequired = errors.New("opensearchx: client to cache is required")
+
+type CacheConfig struct {
+	// Store holds cached responses; use cache.NewRedis to share them across
+	// instances. Defaults to cache.NewMemory(nil).
+	Store cache.Store
+	// Prefix is prepended to every key. Defaults to "opensearchx:".
+	Prefix string
+	// TTL is how long a response is served without contacting OpenSearch.
+	// Defaults to 1 minute.
+	TTL time.Duration
+	// StaleTTL is how long past TTL a response may still be served while it
+	// is refreshed in the background. Defaults to 10 minutes; negative
+	// disables stale serving.
+	StaleTTL time.Duration
+	// RefreshTimeout bounds background refreshes. Defaults to 10 seconds.
+	RefreshTimeout time.Duration
+
+	DisableMetrics    bool
+	MetricsRegisterer prometheus.Registerer
+
+	now func() time.Time
+}
+
+type cachedClient struct {
+	Client
+	config  *CacheConfig
+	group   singleflight.Group
+	metrics *metrics
+}
+
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// NewCachedClient wraps next so Hint and HotSearch responses are cached with
+// stale-while-revalidate. Other calls go straight to next.
+func NewCachedClient(next Client, conf *CacheConfig) (Client, error) {
+	if next == nil {
+		return nil, ErrCacheClientRequired
+	}
+
+	var config CacheConfig
+	if conf != nil {
+		config = *conf
+	}
+	if config.Store == nil {
+		config.Store = cache.NewMemory(nil)
+	}
+	if config.Prefix == "" {
+		config.Prefix = defaultCachePrefix
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaultCacheTTL
+	}
+	if config.StaleTTL == 0 {
+		config.StaleTTL = defaultCacheStaleTTL
+	}
+	if config.StaleTTL < 0 {
+		config.StaleTTL = 0
+	}
+	if config.RefreshTimeout <= 0 {
+		config.RefreshTimeout = defaultCacheRefreshTimeout
+	}
+	if config.now == nil {
+		config.now = time.Now
+	}
+
+	var metrics *metrics
+	if !config.DisableMetrics {
+		metrics = defaultOpenSearchMetrics()
+		if config.MetricsRegisterer != nil {
+			metrics = newOpenSearchMetrics(config.MetricsRegisterer)
+		}
+	}
+
+	return &cachedClient{Client: next, config: &config, metrics: metrics}, nil
+}
+
+func (c *cachedClient) Hint(ctx context.Context, appName string, req *HintRequest) (*HintResponse, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	if req == nil {
+		return nil, ErrHintRequestRequired
+	}
+	return cachedCall(ctx, c, "hint", appName, req.ToQuery(), func(ctx context.Context) (*HintResponse, error) {
+		return c.Client.Hint(ctx, appName, req)
+	})
+}
+
+func (c *cachedClient) HotSearch(ctx context.Context, appName string, req *HotSearchRequest) (*HotSearchResponse, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	if req == nil {
+		return nil, ErrHotRequestRequired
+	}
+	return cachedCall(ctx, c, "hot_search", appName, req.ToQuery(), func(ctx context.Context) (*HotSearchResponse, error) {
+		return c.Client.HotSearch(ctx, appName, req)
+	})
+}
+
+func cachedCall[T any](ctx context.Context, c *cachedClient, api, appName string, query map[string]string, fetch func(context.Context) (T, error)) (T, error) {
+	var value T
+	appName = strings.TrimSpace(appName)
+	if appName == "" {
+		return value, ErrAppNameRequired
+	}
+	key := c.cacheKey(api, appName, query)
+	load := func(ctx context.Context) (any, error) {
+		return fetch(ctx)
+	}
+
+	data, err := c.config.Store.Get(ctx, key)
+	switch {
+	case err == nil:
+		var entry cacheEntry
+		if json.Unmarshal(data, &entry) == nil && json.Unmarshal(entry.Value, &value) == nil {
+			age := c.config.now().Sub(entry.FetchedAt)
+			if age < c.config.TTL {
+				c.observe(api, "hit")
+				return value, nil
+			}
+			if age < c.config.TTL+c.config.StaleTTL {
+				c.observe(api, "stale")
+				c.refresh(ctx, api, key, load)
+				return value, nil
+			}
+		}
+		// Expired or undecodable entries are reloaded and overwritten.
+	case !errors.Is(err, cache.ErrNotFound):
+		// A broken cache must not take search down with it.
+		c.observe(api, "error")
+	}
+	c.observe(api, "miss")
+
+	result, err, _ := c.group.Do(key, func() (any, error) {
+		return c.load(ctx, api, key, load)
+	})
+	if err != nil {
+		return value, err
+	}
+	// Each caller decodes its own copy, so shared loads never alias.
+	var loaded T
+	if err := json.Unmarshal(result.([]byte), &loaded); err != nil {
+		return loaded, err
+	}
+	return loaded, nil
+}
+
+// load calls OpenSearch and stores the response; the response is returned
+// even when it cannot be stored.
+func (c *cachedClient) load(ctx context.Context, api, key string, fetch func(context.Context) (any, error)) ([]byte, error) {
+	value, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := json.Marshal(cacheEntry{FetchedAt: c.config.now(), Value: data})
+	if err != nil {
+		return nil, err
+	}
+	if err := c.config.Store.Set(ctx, key, entry, c.config.TTL+c.config.StaleTTL); err != nil {
+		c.observe(api, "error")
+	}
+	return data, nil
+}
+
+// refresh reloads key in the background; concurrent stale hits share one
+// reload, which outlives the request that triggered it.
+func (c *cachedClient) refresh(ctx context.Context, api, key string, fetch func(context.Context) (any, error)) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		_, _, _ = c.group.Do(key, func() (any, error) {
+			refreshCtx, cancel := context.WithTimeout(ctx, c.config.RefreshTimeout)
+			defer cancel()
+			data, err := c.load(refreshCtx, api, key, fetch)
+			c.observeRefresh(api, err)
+			return data, err
+		})
+	}()
+}
+
+func (c *cachedClient) cacheKey(api, appName string, query map[string]string) string {
+	values := make(url.Values, len(query))
+	for key, value := range query {
+		values.Set(key, value)
+	}
+	// Encode sorts by key, so equal requests share an entry.
+	return c.config.Prefix + api + ":" + appName + "?" + values.Encode()
+}
+
+func (c *cachedClient) observe(api, result string) {
+	if c.metrics != nil {
+		c.metrics.cacheRequestsTotal.WithLabelValues(api, result).Inc()
+	}
+}
+
+func (c *cachedClient) observeRefresh(api string, err error) {
+	if c.metrics == nil {
+		return
+	}
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	c.metrics.cacheRefreshTotal.WithLabelValues(api, result).Inc()
+}
@@ -0,0 +1,162 @@
+package opensearchx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type countingClient struct {
+	Client
+	hints atomic.Int32
+	hots  atomic.Int32
+	err   error
+}
+
+func (c *countingClient) Hint(_ context.Context, _ string, _ *HintRequest) (*HintResponse, error) {
+	n := c.hints.Add(1)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &HintResponse{Body: HintBody{Result: []HintItem{{Hint: fmt.Sprintf("hint-%d", n)}}}}, nil
+}
+
+func (c *countingClient) HotSearch(_ context.Context, _ string, _ *HotSearchRequest) (*HotSearchResponse, error) {
+	c.hots.Add(1)
+	return &HotSearchResponse{Body: HotSearchBody{Result: []HotSearchItem{{Hot: "hot"}}}}, nil
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestCachedClientStaleWhileRevalidate(t *testing.T) {
+	upstream := &countingClient{}
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	reg := prometheus.NewRegistry()
+	client, err := NewCachedClient(upstream, &CacheConfig{
+		TTL:               time.Minute,
+		StaleTTL:          time.Hour,
+		MetricsRegisterer: reg,
+		now:               clock.Now,
+	})
+	if err != nil {
+		t.Fatalf("NewCachedClient() error = %v", err)
+	}
+	ctx := context.Background()
+	req := &HintRequest{Hit: 3}
+
+	hint := func() string {
+		t.Helper()
+		resp, err := client.Hint(ctx, "catalog", req)
+		if err != nil {
+			t.Fatalf("Hint() error = %v", err)
+		}
+		return resp.Body.Result[0].Hint
+	}
+
+	if got := hint(); got != "hint-1" {
+		t.Fatalf("first hint = %q", got)
+	}
+	if got := hint(); got != "hint-1" || upstream.hints.Load() != 1 {
+		t.Fatalf("cached hint = %q after %d upstream calls", got, upstream.hints.Load())
+	}
+
+	clock.Advance(2 * time.Minute)
+	if got := hint(); got != "hint-1" {
+		t.Fatalf("stale hint = %q, want the cached response", got)
+	}
+	deadline := time.Now().Add(time.Second)
+	for upstream.hints.Load() != 2 || hint() != "hint-2" {
+		if time.Now().After(deadline) {
+			t.Fatalf("stale entry was not refreshed, upstream calls = %d", upstream.hints.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if got := hint(); got != "hint-3" {
+		t.Fatalf("expired hint = %q, want a fresh load", got)
+	}
+
+	if _, err := client.HotSearch(ctx, "catalog", &HotSearchRequest{Hit: 5}); err != nil {
+		t.Fatalf("HotSearch() error = %v", err)
+	}
+	if _, err := client.HotSearch(ctx, "catalog", &HotSearchRequest{Hit: 5}); err != nil {
+		t.Fatalf("HotSearch() error = %v", err)
+	}
+	if got := upstream.hots.Load(); got != 1 {
+		t.Fatalf("hot search upstream calls = %d", got)
+	}
+
+	requests := cacheRequests(t, reg)
+	if got := testutil.ToFloat64(requests.WithLabelValues("hint", "stale")); got != 1 {
+		t.Fatalf("stale hits = %v", got)
+	}
+	if got := testutil.ToFloat64(requests.WithLabelValues("hot_search", "hit")); got != 1 {
+		t.Fatalf("hot search hits = %v", got)
+	}
+	if got := testutil.ToFloat64(requests.WithLabelValues("hint", "miss")); got != 2 {
+		t.Fatalf("hint misses = %v", got)
+	}
+}
+
+func TestCachedClientDoesNotCacheErrors(t *testing.T) {
+	upstream := &countingClient{err: errors.New("boom")}
+	client, err := NewCachedClient(upstream, &CacheConfig{DisableMetrics: true})
+	if err != nil {
+		t.Fatalf("NewCachedClient() error = %v", err)
+	}
+
+	for range 2 {
+		if _, err := client.Hint(context.Background(), "catalog", &HintRequest{}); err == nil {
+			t.Fatal("Hint() error = nil")
+		}
+	}
+	if got := upstream.hints.Load(); got != 2 {
+		t.Fatalf("upstream calls = %d, want errors to bypass the cache", got)
+	}
+
+	if _, err := NewCachedClient(nil, nil); !errors.Is(err, ErrCacheClientRequired) {
+		t.Fatalf("NewCachedClient(nil) error = %v", err)
+	}
+	if _, err := client.Hint(context.Background(), " ", &HintRequest{}); !errors.Is(err, ErrAppNameRequired) {
+		t.Fatalf("Hint() without app error = %v", err)
+	}
+}
+
+func cacheRequests(t *testing.T, reg *prometheus.Registry) *prometheus.CounterVec {
+	t.Helper()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "opensearchx_cache_requests_total",
+		Help: "Total number of cached OpenSearch lookups by result.",
+	}, []string{"api", "result"})
+	if err := reg.Register(requests); err != nil {
+		existing, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			t.Fatalf("register requests counter: %v", err)
+		}
+		requests = existing.ExistingCollector.(*prometheus.CounterVec)
+	}
+	return requests
+}
@@ -471,8 +471,8 @@ func (c *client) Search(ctx context.Context, appName string, req *SearchRequest)
 	if appName == "" {
 		return nil, ErrAppNameRequired
 	}
-	if req == nil {
-		return nil, ErrSearchRequestRequired
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
 	query := req.String()
 	if query == "" {
@@ -0,0 +1,63 @@
+package opensearchx
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	cacheRequestsTotal *prometheus.CounterVec
+	cacheRefreshTotal  *prometheus.CounterVec
+}
+
+var (
+	defaultMetricsOnce sync.Once
+	defaultMetrics     *metrics
+)
+
+func defaultOpenSearchMetrics() *metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = newOpenSearchMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetrics
+}
+
+func newOpenSearchMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		cacheRequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "opensearchx_cache_requests_total",
+				Help: "Total number of cached OpenSearch lookups by result.",
+			},
+			[]string{"api", "result"},
+		),
+		cacheRefreshTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "opensearchx_cache_refresh_total",
+				Help: "Total number of background refreshes of stale OpenSearch responses.",
+			},
+			[]string{"api", "result"},
+		),
+	}
+
+	mustRegisterCollector(registerer, &m.cacheRequestsTotal, m.cacheRequestsTotal)
+	mustRegisterCollector(registerer, &m.cacheRefreshTotal, m.cacheRefreshTotal)
+
+	return m
+}
+
+func mustRegisterCollector[T prometheus.Collector](registerer prometheus.Registerer, dst *T, collector T) {
+	if registerer == nil {
+		return
+	}
+	if err := registerer.Register(collector); err != nil {
+		if alreadyRegistered, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if registered, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				*dst = registered
+				return
+			}
+		}
+		panic(err)
+	}
+}
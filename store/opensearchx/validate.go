@@ -0,0 +1,146 @@
+package opensearchx
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	maxSearchHit    = 500
+	maxSearchWindow = 5000
+)
+
+// ErrInvalidSearchRequest wraps every problem Validate reports, so callers
+// can tell a malformed request from a server-side failure.
+var ErrInvalidSearchRequest = errors.New("opensearchx: invalid search request")
+
+var (
+	filterOperators = []string{"=", "!=", ">", "<", ">=", "<="}
+	sortOrders      = []string{"+", "-"}
+	searchFormats   = []string{"json", "fulljson", "xml", "protobuf"}
+
+	aggFunPattern = regexp.MustCompile(`^(count\(\)|(sum|max|min|distinct_count)\([^()]+\))$`)
+)
+
+// Validate reports every malformed clause at once. Search calls it before
+// sending, because OpenSearch rejects such requests with opaque errors or,
+// worse, silently drops the clause.
+func (r *SearchRequest) Validate() error {
+	if r == nil {
+		return ErrSearchRequestRequired
+	}
+	if r.Query == nil {
+		return ErrQueryRequired
+	}
+
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	add(r.Query.Validate())
+	add(r.Filter.Validate())
+	add(r.Sort.Validate())
+	add(r.Distinct.Validate())
+	add(r.Aggregate.Validate())
+
+	if r.Start < 0 {
+		add(invalid("start %d must not be negative", r.Start))
+	}
+	if r.Hit < 0 || r.Hit > maxSearchHit {
+		add(invalid("hit %d must be between 0 and %d", r.Hit, maxSearchHit))
+	}
+	hit := r.Hit
+	if hit <= 0 {
+		hit = defaultSearchHit
+	}
+	if r.Start >= 0 && r.Start+hit > maxSearchWindow {
+		add(invalid("start+hit %d exceeds %d", r.Start+hit, maxSearchWindow))
+	}
+	if format := strings.TrimSpace(r.Format); format != "" && !slices.Contains(searchFormats, format) {
+		add(invalid("format %q must be one of %s", format, strings.Join(searchFormats, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+func (q *QueryClause) Validate() error {
+	if q == nil {
+		return nil
+	}
+	if strings.TrimSpace(q.Index) == "" {
+		return invalid("query index is required")
+	}
+	if strings.TrimSpace(q.Value) == "" {
+		return ErrQueryRequired
+	}
+	return nil
+}
+
+func (f *FilterClause) Validate() error {
+	if f == nil {
+		return nil
+	}
+	if strings.TrimSpace(f.Field) == "" {
+		return invalid("filter field is required")
+	}
+	if operator := strings.TrimSpace(f.Operator); !slices.Contains(filterOperators, operator) {
+		return invalid("filter operator %q on %q must be one of %s", operator, f.Field, strings.Join(filterOperators, " "))
+	}
+	if f.Value == nil {
+		return invalid("filter value on %q is required", f.Field)
+	}
+	return nil
+}
+
+func (s *SortClause) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if strings.TrimSpace(s.Field) == "" {
+		return invalid("sort field is required")
+	}
+	if order := strings.TrimSpace(s.Order); order != "" && !slices.Contains(sortOrders, order) {
+		return invalid("sort order %q on %q must be + or -", order, s.Field)
+	}
+	return nil
+}
+
+func (d *DistinctClause) Validate() error {
+	if d == nil {
+		return nil
+	}
+	if strings.TrimSpace(d.Key) == "" {
+		return invalid("distinct key is required")
+	}
+	if d.Count < 0 || d.Times < 0 {
+		return invalid("distinct count and times on %q must not be negative", d.Key)
+	}
+	return nil
+}
+
+func (a *AggregateClause) Validate() error {
+	if a == nil {
+		return nil
+	}
+	if strings.TrimSpace(a.GroupKey) == "" {
+		return invalid("aggregate group key is required")
+	}
+	aggFun := strings.TrimSpace(a.AggFun)
+	if aggFun == "" {
+		return invalid("aggregate function on %q is required", a.GroupKey)
+	}
+	for _, fn := range strings.Split(aggFun, "#") {
+		if !aggFunPattern.MatchString(strings.TrimSpace(fn)) {
+			return invalid("aggregate function %q on %q must be count(), sum(f), max(f), min(f) or distinct_count(f)", fn, a.GroupKey)
+		}
+	}
+	return nil
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSearchRequest, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,91 @@
+package opensearchx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSearchRequestValidate(t *testing.T) {
+	valid := func() *SearchRequest {
+		return &SearchRequest{
+			Query:     &QueryClause{Index: "default", Value: "iphone"},
+			Filter:    &FilterClause{Field: "price", Operator: ">=", Value: 100},
+			Sort:      &SortClause{Field: "price", Order: "+"},
+			Distinct:  &DistinctClause{Key: "shop_id", Count: 1, Times: 2},
+			Aggregate: &AggregateClause{GroupKey: "brand", AggFun: "count()#max(price)"},
+			Start:     4980,
+			Hit:       20,
+			Format:    "json",
+		}
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*SearchRequest)
+		want   string
+	}{
+		{"missing query", func(r *SearchRequest) { r.Query = nil }, "query is required"},
+		{"query without index", func(r *SearchRequest) { r.Query.Index = " " }, "query index is required"},
+		{"filter operator", func(r *SearchRequest) { r.Filter.Operator = "==" }, `filter operator "==" on "price"`},
+		{"filter without value", func(r *SearchRequest) { r.Filter.Value = nil }, `filter value on "price"`},
+		{"sort order", func(r *SearchRequest) { r.Sort.Order = "desc" }, `sort order "desc"`},
+		{"distinct count", func(r *SearchRequest) { r.Distinct.Count = -1 }, "distinct count"},
+		{"aggregate function", func(r *SearchRequest) { r.Aggregate.AggFun = "avg(price)" }, `aggregate function "avg(price)"`},
+		{"negative start", func(r *SearchRequest) { r.Start = -1 }, "start -1"},
+		{"hit too large", func(r *SearchRequest) { r.Start, r.Hit = 0, 501 }, "hit 501"},
+		{"window", func(r *SearchRequest) { r.Hit = 21 }, "start+hit 5001"},
+		{"format", func(r *SearchRequest) { r.Format = "yaml" }, `format "yaml"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.mutate(req)
+			err := req.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	req := valid()
+	req.Filter.Operator = "~"
+	req.Sort.Order = "asc"
+	err := req.Validate()
+	if !errors.Is(err, ErrInvalidSearchRequest) {
+		t.Fatalf("Validate() error = %v, want ErrInvalidSearchRequest", err)
+	}
+	if !strings.Contains(err.Error(), "filter operator") || !strings.Contains(err.Error(), "sort order") {
+		t.Fatalf("Validate() did not report every problem: %v", err)
+	}
+}
+
+func TestSearchRejectsInvalidRequestBeforeSending(t *testing.T) {
+	fake := &fakeRequester{}
+	client, err := New(&Config{
+		Endpoint:        "endpoint",
+		AccessKeyID:     "ak",
+		AccessKeySecret: "sk",
+		newRequester: func(*Config) requester {
+			return fake
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = client.Search(context.Background(), "catalog", &SearchRequest{
+		Query:  &QueryClause{Index: "default", Value: "x"},
+		Filter: &FilterClause{Field: "status", Operator: "is", Value: 1},
+	})
+	if !errors.Is(err, ErrInvalidSearchRequest) {
+		t.Fatalf("Search() error = %v, want ErrInvalidSearchRequest", err)
+	}
+	if fake.last.Path != "" {
+		t.Fatalf("invalid request was sent: %+v", fake.last)
+	}
+}
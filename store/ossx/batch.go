@@ -0,0 +1,322 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	aliyunoss "github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
+	"github.com/bang-go/util"
+)
+
+const (
+	defaultBatchConcurrency = 8
+	// maxDeleteKeys is the per-request limit of DeleteMultipleObjects.
+	maxDeleteKeys   = 1000
+	listObjectsPage = 1000
+)
+
+var ErrObjectNotDeleted = errors.New("ossx: object not reported as deleted")
+
+// BatchOptions tunes BatchDelete, BatchCopy and SyncPrefix.
+type BatchOptions struct {
+	// Concurrency is the number of requests in flight; defaults to 8.
+	Concurrency int
+	// OnProgress is called after every request with the running totals.
+	// Calls are serialized.
+	OnProgress func(BatchProgress)
+}
+
+type BatchProgress struct {
+	Total  int64
+	Done   int64
+	Failed int64
+}
+
+// ObjectCopy copies SourceBucket/SourceKey to Bucket/Key. An empty
+// SourceBucket means Bucket.
+type ObjectCopy struct {
+	SourceBucket string
+	SourceKey    string
+	Bucket       string
+	Key          string
+}
+
+// ObjectError is the failure of one object in a batch.
+type ObjectError struct {
+	Bucket string
+	Key    string
+	Err    error
+}
+
+func (e *ObjectError) Error() string {
+	return fmt.Sprintf("ossx: %s/%s: %v", e.Bucket, e.Key, e.Err)
+}
+
+func (e *ObjectError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError lists every object that failed; the others succeeded.
+// errors.Is and errors.As see through to each object's error.
+type BatchError struct {
+	Objects []*ObjectError
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("ossx: %d objects failed, first: %v", len(e.Objects), e.Objects[0])
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Objects))
+	for i, err := range e.Objects {
+		errs[i] = err
+	}
+	return errs
+}
+
+// SyncRequest mirrors the objects under SourcePrefix to DestPrefix. An
+// object is copied when the destination is missing or differs in size or
+// ETag.
+type SyncRequest struct {
+	SourceBucket string
+	SourcePrefix string
+	DestBucket   string
+	DestPrefix   string
+	// Delete removes destination objects that have no source counterpart.
+	Delete bool
+}
+
+type SyncResult struct {
+	Copied    int64
+	Deleted   int64
+	Unchanged int64
+}
+
+// BatchDelete removes keys from bucket in DeleteMultipleObjects requests of
+// up to 1000 keys. Missing keys count as deleted.
+func (c *client) BatchDelete(ctx context.Context, bucket string, keys []string, opts *BatchOptions) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	bucket = strings.TrimSpace(bucket)
+	if bucket == "" {
+		return ErrBucketRequired
+	}
+
+	chunks := slices.Collect(slices.Chunk(keys, maxDeleteKeys))
+	return runBatch(ctx, chunks, int64(len(keys)), opts, func(ctx context.Context, chunk []string) (int, []*ObjectError) {
+		failed := c.deleteChunk(ctx, bucket, chunk)
+		return len(chunk) - len(failed), failed
+	})
+}
+
+// BatchCopy runs server-side copies. CopyObject is limited to objects of up
+// to 5 GiB.
+func (c *client) BatchCopy(ctx context.Context, copies []ObjectCopy, opts *BatchOptions) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	for _, item := range copies {
+		if strings.TrimSpace(item.Bucket) == "" {
+			return ErrBucketRequired
+		}
+		if strings.TrimSpace(item.Key) == "" || strings.TrimSpace(item.SourceKey) == "" {
+			return ErrKeyRequired
+		}
+	}
+	return runBatch(ctx, copies, int64(len(copies)), opts, func(ctx context.Context, item ObjectCopy) (int, []*ObjectError) {
+		if err := c.copyObject(ctx, item); err != nil {
+			return 0, []*ObjectError{{Bucket: item.Bucket, Key: item.Key, Err: err}}
+		}
+		return 1, nil
+	})
+}
+
+// SyncPrefix lists both prefixes, copies new and changed objects and, with
+// Delete, removes stale ones. Progress covers the copies and deletes.
+func (c *client) SyncPrefix(ctx context.Context, req *SyncRequest, opts *BatchOptions) (*SyncResult, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	if req == nil {
+		return nil, ErrRequestRequired
+	}
+	request := *req
+	request.SourceBucket = strings.TrimSpace(request.SourceBucket)
+	request.DestBucket = strings.TrimSpace(request.DestBucket)
+	if request.SourceBucket == "" || request.DestBucket == "" {
+		return nil, ErrBucketRequired
+	}
+
+	source, err := c.listObjects(ctx, request.SourceBucket, request.SourcePrefix)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := c.listObjects(ctx, request.DestBucket, request.DestPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	type syncTask struct {
+		copy   *ObjectCopy
+		delete string
+	}
+	var (
+		tasks  []syncTask
+		result SyncResult
+	)
+	for rel, src := range source {
+		if dst, ok := dest[rel]; ok && dst.Size == src.Size && aliyunoss.ToString(dst.ETag) == aliyunoss.ToString(src.ETag) {
+			result.Unchanged++
+			continue
+		}
+		tasks = append(tasks, syncTask{copy: &ObjectCopy{
+			SourceBucket: request.SourceBucket,
+			SourceKey:    request.SourcePrefix + rel,
+			Bucket:       request.DestBucket,
+			Key:          request.DestPrefix + rel,
+		}})
+	}
+	if request.Delete {
+		for rel := range dest {
+			if _, ok := source[rel]; !ok {
+				tasks = append(tasks, syncTask{delete: request.DestPrefix + rel})
+			}
+		}
+	}
+
+	var copied, deleted atomic.Int64
+	err = runBatch(ctx, tasks, int64(len(tasks)), opts, func(ctx context.Context, task syncTask) (int, []*ObjectError) {
+		if task.copy != nil {
+			if err := c.copyObject(ctx, *task.copy); err != nil {
+				return 0, []*ObjectError{{Bucket: task.copy.Bucket, Key: task.copy.Key, Err: err}}
+			}
+			copied.Add(1)
+			return 1, nil
+		}
+		if failed := c.deleteChunk(ctx, request.DestBucket, []string{task.delete}); len(failed) > 0 {
+			return 0, failed
+		}
+		deleted.Add(1)
+		return 1, nil
+	})
+	result.Copied = copied.Load()
+	result.Deleted = deleted.Load()
+	return &result, err
+}
+
+func (c *client) deleteChunk(ctx context.Context, bucket string, keys []string) []*ObjectError {
+	objects := make([]DeleteObject, len(keys))
+	for i, key := range keys {
+		objects[i] = DeleteObject{Key: util.Ptr(key)}
+	}
+	result, err := c.api.DeleteMultipleObjects(ctx, &DeleteMultipleObjectsRequest{
+		Bucket:  util.Ptr(bucket),
+		Objects: objects,
+	})
+	failed := make([]*ObjectError, 0)
+	if err != nil {
+		for _, key := range keys {
+			failed = append(failed, &ObjectError{Bucket: bucket, Key: key, Err: err})
+		}
+		return failed
+	}
+	// OSS reports only the deleted keys, so any key left out failed.
+	deleted := make(map[string]struct{}, len(result.DeletedObjects))
+	for _, info := range result.DeletedObjects {
+		deleted[aliyunoss.ToString(info.Key)] = struct{}{}
+	}
+	for _, key := range keys {
+		if _, ok := deleted[key]; !ok {
+			failed = append(failed, &ObjectError{Bucket: bucket, Key: key, Err: ErrObjectNotDeleted})
+		}
+	}
+	return failed
+}
+
+func (c *client) copyObject(ctx context.Context, item ObjectCopy) error {
+	sourceBucket := strings.TrimSpace(item.SourceBucket)
+	if sourceBucket == "" {
+		sourceBucket = strings.TrimSpace(item.Bucket)
+	}
+	_, err := c.api.CopyObject(ctx, &CopyObjectRequest{
+		Bucket:       util.Ptr(strings.TrimSpace(item.Bucket)),
+		Key:          util.Ptr(item.Key),
+		SourceBucket: util.Ptr(sourceBucket),
+		SourceKey:    util.Ptr(item.SourceKey),
+	})
+	return err
+}
+
+// listObjects returns the objects under prefix keyed by the rest of their key.
+func (c *client) listObjects(ctx context.Context, bucket, prefix string) (map[string]aliyunoss.ObjectProperties, error) {
+	objects := make(map[string]aliyunoss.ObjectProperties)
+	req := &ListObjectsV2Request{Bucket: util.Ptr(bucket), Prefix: util.Ptr(prefix), MaxKeys: listObjectsPage}
+	for {
+		result, err := c.api.ListObjectsV2(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("ossx: list %s/%s: %w", bucket, prefix, err)
+		}
+		for _, object := range result.Contents {
+			key := aliyunoss.ToString(object.Key)
+			objects[strings.TrimPrefix(key, prefix)] = object
+		}
+		if !result.IsTruncated || aliyunoss.ToString(result.NextContinuationToken) == "" {
+			return objects, nil
+		}
+		req.ContinuationToken = result.NextContinuationToken
+	}
+}
+
+// runBatch hands items to Concurrency workers and collects the objects that
+// failed. handle reports how many objects an item completed and which failed.
+func runBatch[T any](ctx context.Context, items []T, total int64, opts *BatchOptions, handle func(context.Context, T) (int, []*ObjectError)) error {
+	var options BatchOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultBatchConcurrency
+	}
+
+	var (
+		mu       sync.Mutex
+		failures []*ObjectError
+		progress = BatchProgress{Total: total}
+		wg       sync.WaitGroup
+	)
+	work := make(chan T)
+	for range min(options.Concurrency, max(len(items), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range work {
+				done, failed := handle(ctx, item)
+
+				mu.Lock()
+				failures = append(failures, failed...)
+				progress.Done += int64(done)
+				progress.Failed += int64(len(failed))
+				if options.OnProgress != nil {
+					options.OnProgress(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, item := range items {
+		work <- item
+	}
+	close(work)
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return &BatchError{Objects: failures}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	aliyunoss "github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
+)
+
+var errInjected = errors.New("injected failure")
+
+type memoryOSSAPI struct {
+	fakeOSSAPI
+
+	mu          sync.Mutex
+	objects     map[string]string // "bucket/key" -> content
+	failKeys    map[string]bool
+	deleteCalls []int
+}
+
+func newMemoryClient(t *testing.T) (*client, *memoryOSSAPI) {
+	t.Helper()
+	api := &memoryOSSAPI{objects: make(map[string]string), failKeys: make(map[string]bool)}
+	c, err := New(&Config{
+		Endpoint:        "oss-cn-hangzhou.aliyuncs.com",
+		Region:          "cn-hangzhou",
+		AccessKeyID:     "ak",
+		AccessKeySecret: "sk",
+		newClient: func(*aliyunoss.Config, ...func(*Options)) ossAPI {
+			return api
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c.(*client), api
+}
+
+func (m *memoryOSSAPI) DeleteMultipleObjects(_ context.Context, req *DeleteMultipleObjectsRequest, _ ...func(*Options)) (*DeleteMultipleObjectsResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleteCalls = append(m.deleteCalls, len(req.Objects))
+	result := &DeleteMultipleObjectsResult{}
+	for _, object := range req.Objects {
+		key := aliyunoss.ToString(object.Key)
+		if m.failKeys[key] {
+			continue
+		}
+		delete(m.objects, aliyunoss.ToString(req.Bucket)+"/"+key)
+		result.DeletedObjects = append(result.DeletedObjects, aliyunoss.DeletedInfo{Key: object.Key})
+	}
+	return result, nil
+}
+
+func (m *memoryOSSAPI) CopyObject(_ context.Context, req *CopyObjectRequest, _ ...func(*Options)) (*CopyObjectResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failKeys[aliyunoss.ToString(req.Key)] {
+		return nil, errInjected
+	}
+	content, ok := m.objects[aliyunoss.ToString(req.SourceBucket)+"/"+aliyunoss.ToString(req.SourceKey)]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	m.objects[aliyunoss.ToString(req.Bucket)+"/"+aliyunoss.ToString(req.Key)] = content
+	return &CopyObjectResult{}, nil
+}
+
+// ListObjectsV2 returns one object per page to exercise pagination.
+func (m *memoryOSSAPI) ListObjectsV2(_ context.Context, req *ListObjectsV2Request, _ ...func(*Options)) (*ListObjectsV2Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := aliyunoss.ToString(req.Bucket) + "/"
+	var keys []string
+	for name := range m.objects {
+		if key, ok := strings.CutPrefix(name, bucket); ok && strings.HasPrefix(key, aliyunoss.ToString(req.Prefix)) && key > aliyunoss.ToString(req.ContinuationToken) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	if len(keys) == 0 {
+		return &ListObjectsV2Result{}, nil
+	}
+	content := m.objects[bucket+keys[0]]
+	return &ListObjectsV2Result{
+		Contents: []aliyunoss.ObjectProperties{{
+			Key:  aliyunoss.Ptr(keys[0]),
+			Size: int64(len(content)),
+			ETag: aliyunoss.Ptr(`"` + content + `"`),
+		}},
+		IsTruncated:           len(keys) > 1,
+		NextContinuationToken: aliyunoss.Ptr(keys[0]),
+	}, nil
+}
+
+func TestBatchDelete(t *testing.T) {
+	c, api := newMemoryClient(t)
+	keys := make([]string, 2500)
+	for i := range keys {
+		keys[i] = "logs/" + strconv.Itoa(i)
+		api.objects["bucket/"+keys[i]] = "x"
+	}
+	api.failKeys["logs/7"] = true
+	api.failKeys["logs/2400"] = true
+
+	var (
+		mu   sync.Mutex
+		last BatchProgress
+	)
+	err := c.BatchDelete(context.Background(), "bucket", keys, &BatchOptions{
+		Concurrency: 2,
+		OnProgress: func(p BatchProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			last = p
+		},
+	})
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Objects) != 2 {
+		t.Fatalf("BatchDelete() error = %v, want 2 failed objects", err)
+	}
+	if !errors.Is(err, ErrObjectNotDeleted) {
+		t.Fatalf("errors.Is(err, ErrObjectNotDeleted) = false for %v", err)
+	}
+	failed := []string{batchErr.Objects[0].Key, batchErr.Objects[1].Key}
+	slices.Sort(failed)
+	if !slices.Equal(failed, []string{"logs/2400", "logs/7"}) {
+		t.Fatalf("failed keys = %v", failed)
+	}
+	if want := (BatchProgress{Total: 2500, Done: 2498, Failed: 2}); last != want {
+		t.Fatalf("last progress = %+v, want %+v", last, want)
+	}
+	calls := slices.Clone(api.deleteCalls)
+	slices.Sort(calls)
+	if !slices.Equal(calls, []int{500, 1000, 1000}) {
+		t.Fatalf("delete request sizes = %v, want [500 1000 1000]", calls)
+	}
+	if len(api.objects) != 2 {
+		t.Fatalf("remaining objects = %d, want 2", len(api.objects))
+	}
+}
+
+func TestBatchCopy(t *testing.T) {
+	c, api := newMemoryClient(t)
+	api.objects["src/a"] = "1"
+	api.objects["src/b"] = "2"
+	api.failKeys["b-copy"] = true
+
+	err := c.BatchCopy(context.Background(), []ObjectCopy{
+		{SourceBucket: "src", SourceKey: "a", Bucket: "dst", Key: "a-copy"},
+		{SourceBucket: "src", SourceKey: "b", Bucket: "dst", Key: "b-copy"},
+	}, nil)
+	if !errors.Is(err, errInjected) {
+		t.Fatalf("BatchCopy() error = %v, want %v", err, errInjected)
+	}
+	if api.objects["dst/a-copy"] != "1" {
+		t.Fatalf("dst/a-copy = %q, want 1", api.objects["dst/a-copy"])
+	}
+
+	if err := c.BatchCopy(context.Background(), []ObjectCopy{{SourceKey: "a", Key: "b"}}, nil); !errors.Is(err, ErrBucketRequired) {
+		t.Fatalf("BatchCopy(no bucket) error = %v, want %v", err, ErrBucketRequired)
+	}
+}
+
+func TestSyncPrefix(t *testing.T) {
+	c, api := newMemoryClient(t)
+	api.objects["bucket/src/same"] = "s"
+	api.objects["bucket/src/changed"] = "new"
+	api.objects["bucket/src/nested/added"] = "a"
+	api.objects["bucket/backup/same"] = "s"
+	api.objects["bucket/backup/changed"] = "old"
+	api.objects["bucket/backup/stale"] = "x"
+
+	result, err := c.SyncPrefix(context.Background(), &SyncRequest{
+		SourceBucket: "bucket",
+		SourcePrefix: "src/",
+		DestBucket:   "bucket",
+		DestPrefix:   "backup/",
+		Delete:       true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("SyncPrefix() error = %v", err)
+	}
+	if want := (SyncResult{Copied: 2, Deleted: 1, Unchanged: 1}); *result != want {
+		t.Fatalf("SyncPrefix() = %+v, want %+v", *result, want)
+	}
+	for key, want := range map[string]string{"backup/same": "s", "backup/changed": "new", "backup/nested/added": "a"} {
+		if got := api.objects["bucket/"+key]; got != want {
+			t.Fatalf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if _, ok := api.objects["bucket/backup/stale"]; ok {
+		t.Fatal("stale object was not deleted")
+	}
+}
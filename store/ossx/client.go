@@ -42,6 +42,13 @@ type PutObjectResult = aliyunoss.PutObjectResult
 type AppendObjectRequest = aliyunoss.AppendObjectRequest
 type AppendObjectResult = aliyunoss.AppendObjectResult
 type AppendOnlyFile = aliyunoss.AppendOnlyFile
+type DeleteObject = aliyunoss.DeleteObject
+type DeleteMultipleObjectsRequest = aliyunoss.DeleteMultipleObjectsRequest
+type DeleteMultipleObjectsResult = aliyunoss.DeleteMultipleObjectsResult
+type CopyObjectRequest = aliyunoss.CopyObjectRequest
+type CopyObjectResult = aliyunoss.CopyObjectResult
+type ListObjectsV2Request = aliyunoss.ListObjectsV2Request
+type ListObjectsV2Result = aliyunoss.ListObjectsV2Result
 
 type Client interface {
 	Raw() *aliyunoss.Client
@@ -49,6 +56,9 @@ type Client interface {
 	PutObjectFromFile(context.Context, *PutObjectRequest, string, ...func(*Options)) (*PutObjectResult, error)
 	AppendObject(context.Context, *AppendObjectRequest, ...func(*Options)) (*AppendObjectResult, error)
 	AppendFile(context.Context, string, string, ...func(*AppendOptions)) (*AppendOnlyFile, error)
+	BatchDelete(ctx context.Context, bucket string, keys []string, opts *BatchOptions) error
+	BatchCopy(context.Context, []ObjectCopy, *BatchOptions) error
+	SyncPrefix(context.Context, *SyncRequest, *BatchOptions) (*SyncResult, error)
 }
 
 type ossAPI interface {
@@ -56,6 +66,9 @@ type ossAPI interface {
 	PutObjectFromFile(context.Context, *PutObjectRequest, string, ...func(*Options)) (*PutObjectResult, error)
 	AppendObject(context.Context, *AppendObjectRequest, ...func(*Options)) (*AppendObjectResult, error)
 	AppendFile(context.Context, string, string, ...func(*AppendOptions)) (*AppendOnlyFile, error)
+	DeleteMultipleObjects(context.Context, *DeleteMultipleObjectsRequest, ...func(*Options)) (*DeleteMultipleObjectsResult, error)
+	CopyObject(context.Context, *CopyObjectRequest, ...func(*Options)) (*CopyObjectResult, error)
+	ListObjectsV2(context.Context, *ListObjectsV2Request, ...func(*Options)) (*ListObjectsV2Result, error)
 }
 
 type client struct {
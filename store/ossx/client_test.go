@@ -168,3 +168,15 @@ func (f *fakeOSSAPI) AppendFile(_ context.Context, bucket, key string, _ ...func
 	f.key = key
 	return &AppendOnlyFile{}, nil
 }
+
+func (f *fakeOSSAPI) DeleteMultipleObjects(context.Context, *DeleteMultipleObjectsRequest, ...func(*Options)) (*DeleteMultipleObjectsResult, error) {
+	return &DeleteMultipleObjectsResult{}, nil
+}
+
+func (f *fakeOSSAPI) CopyObject(context.Context, *CopyObjectRequest, ...func(*Options)) (*CopyObjectResult, error) {
+	return &CopyObjectResult{}, nil
+}
+
+func (f *fakeOSSAPI) ListObjectsV2(context.Context, *ListObjectsV2Request, ...func(*Options)) (*ListObjectsV2Result, error) {
+	return &ListObjectsV2Result{}, nil
+}
@@ -64,6 +64,10 @@ type Config struct {
 	PingTimeout   time.Duration
 	SlowThreshold time.Duration
 
+	// Watchdog probes the primary in the background and fails over to
+	// FallbackAddr (or opens a breaker) while it is unhealthy.
+	Watchdog *WatchdogConfig
+
 	Trace                   bool
 	TraceProvider           trace.TracerProvider
 	TraceAttributes         []attribute.KeyValue
@@ -74,6 +78,8 @@ type Config struct {
 	EnableLogger      bool
 	DisableMetrics    bool
 	MetricsRegisterer prometheus.Registerer
+	// Metrics bounds the command label of redisx_request_* metrics.
+	Metrics *MetricsConfig
 }
 
 type Client interface {
@@ -82,12 +88,18 @@ type Client interface {
 	Ping(context.Context) error
 	Stats() redis.PoolStats
 	AddHook(redis.Hook) error
+	WatchdogState() WatchdogState
+	IterateKeys(ctx context.Context, pattern string, fn func(context.Context, []string) error, opts *ScanOptions) (int64, error)
+	DeleteByPattern(ctx context.Context, pattern string, opts *ScanOptions) (int64, error)
 	Close() error
 }
 
 type clientEntity struct {
 	client    *redis.Client
 	options   *redis.Options
+	watchdog  *watchdog
+	name      string
+	metrics   *metrics
 	closeOnce sync.Once
 	closeErr  error
 }
@@ -113,11 +125,19 @@ func Open(ctx context.Context, conf *Config) (Client, error) {
 		}
 	}
 
+	var wd *watchdog
+	if config.Watchdog != nil {
+		wd = newWatchdog(config, opts, metrics)
+	}
+
 	rdb := redis.NewClient(opts)
 
 	client := &clientEntity{
-		client:  rdb,
-		options: cloneOptions(rdb.Options()),
+		client:   rdb,
+		options:  cloneOptions(rdb.Options()),
+		watchdog: wd,
+		name:     config.Name,
+		metrics:  metrics,
 	}
 
 	if !config.SkipPing {
@@ -130,6 +150,10 @@ func Open(ctx context.Context, conf *Config) (Client, error) {
 	}
 
 	rdb.AddHook(newObservabilityHook(config, opts.Addr, metrics))
+	if wd != nil {
+		rdb.AddHook(wd)
+		wd.start()
+	}
 
 	if config.Trace {
 		if err := redisotel.InstrumentTracing(rdb, buildTraceOptions(config)...); err != nil {
@@ -176,9 +200,21 @@ func (c *clientEntity) AddHook(hook redis.Hook) error {
 	return nil
 }
 
+func (c *clientEntity) WatchdogState() WatchdogState {
+	if c.watchdog == nil {
+		return WatchdogStatePrimary
+	}
+	return c.watchdog.current()
+}
+
 func (c *clientEntity) Close() error {
 	c.closeOnce.Do(func() {
-		c.closeErr = c.client.Close()
+		if c.watchdog != nil {
+			c.closeErr = c.watchdog.stop()
+		}
+		if err := c.client.Close(); err != nil {
+			c.closeErr = err
+		}
 	})
 	return c.closeErr
 }
@@ -263,6 +299,13 @@ func prepareConfig(conf *Config) (*Config, *redis.Options, error) {
 	if cloned.Name == "" {
 		cloned.Name = opts.Addr
 	}
+	if cloned.Watchdog != nil {
+		watchdog, err := prepareWatchdogConfig(cloned.Watchdog, opts.Addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		cloned.Watchdog = watchdog
+	}
 	return &cloned, opts, nil
 }
 
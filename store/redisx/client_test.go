@@ -3,11 +3,14 @@ package redisx
 import (
 	"context"
 	"errors"
+	"io"
+	"maps"
 	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/bang-go/micro/pkg/budget"
 	"github.com/bang-go/micro/telemetry/logger"
 	"github.com/bang-go/util"
 	"github.com/prometheus/client_golang/prometheus"
@@ -237,6 +240,35 @@ func TestObservabilityHookRedactsValues(t *testing.T) {
 	}
 }
 
+func TestObservabilityHookCapsCommandsToDeadlineBudget(t *testing.T) {
+	h := &observabilityHook{name: "cache", logger: logger.New(logger.WithOutput(io.Discard))}
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ctx := budget.WithPolicy(parent, budget.Policy{Fraction: 0.5})
+
+	var remaining time.Duration
+	capture := func(ctx context.Context) {
+		deadline, _ := ctx.Deadline()
+		remaining = time.Until(deadline)
+	}
+	_ = h.ProcessHook(func(ctx context.Context, _ redis.Cmder) error {
+		capture(ctx)
+		return nil
+	})(ctx, redis.NewStringCmd(ctx, "GET", "key"))
+	if remaining <= 0 || remaining > 500*time.Millisecond {
+		t.Fatalf("command deadline in %v, want within half of the 1s budget", remaining)
+	}
+
+	remaining = 0
+	_ = h.ProcessPipelineHook(func(ctx context.Context, _ []redis.Cmder) error {
+		capture(ctx)
+		return nil
+	})(ctx, []redis.Cmder{redis.NewStringCmd(ctx, "GET", "key")})
+	if remaining <= 0 || remaining > 500*time.Millisecond {
+		t.Fatalf("pipeline deadline in %v, want within half of the 1s budget", remaining)
+	}
+}
+
 func TestObservabilityHookSkipsConnectionManagementCommands(t *testing.T) {
 	var logs safeBuffer
 	h := &observabilityHook{
@@ -366,6 +398,59 @@ func TestTraceDoesNotIncludeCommandArgsByDefault(t *testing.T) {
 	}
 }
 
+func TestTraceRecordsCacheEventsOnCallerSpan(t *testing.T) {
+	server := newFakeRedisServer()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider()
+	provider.RegisterSpanProcessor(recorder)
+	defer provider.Shutdown(context.Background())
+
+	client, err := New(&Config{
+		Name:            "cache",
+		Addr:            "pipe",
+		Protocol:        2,
+		DisableIdentity: util.Ptr(true),
+		Dialer:          server.dialer,
+		SkipPing:        true,
+		Trace:           true,
+		TraceProvider:   provider,
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer client.Close()
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "handler")
+	if err := client.Redis().Set(ctx, "user:1", "alice", 0).Err(); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := client.Redis().Get(ctx, "user:1").Err(); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if err := client.Redis().Get(ctx, "user:2").Err(); !errors.Is(err, redis.Nil) {
+		t.Fatalf("get missing: %v", err)
+	}
+	span.End()
+
+	var events []string
+	for _, s := range recorder.Ended() {
+		if s.Name() != "handler" {
+			continue
+		}
+		for _, event := range s.Events() {
+			events = append(events, event.Name)
+			for _, attr := range event.Attributes {
+				if strings.Contains(attr.Value.Emit(), "user:") {
+					t.Fatalf("cache event leaked key: %+v", event.Attributes)
+				}
+			}
+		}
+	}
+	if want := []string{"cache.hit", "cache.miss"}; !slices.Equal(events, want) {
+		t.Fatalf("handler events = %v, want %v", events, want)
+	}
+}
+
 func TestMetricsRegistererAndDisableMetrics(t *testing.T) {
 	server := newFakeRedisServer()
 	reg := prometheus.NewRegistry()
@@ -431,6 +516,56 @@ func TestMetricsRegistererAndDisableMetrics(t *testing.T) {
 	}
 }
 
+func TestMetricsCommandLabels(t *testing.T) {
+	server := newFakeRedisServer()
+	reg := prometheus.NewRegistry()
+	client, err := New(&Config{
+		Addr:              "pipe",
+		Protocol:          2,
+		DisableIdentity:   util.Ptr(true),
+		Dialer:            server.dialer,
+		MetricsRegisterer: reg,
+		Metrics: &MetricsConfig{
+			NormalizeCommand: TrimSubcommand,
+			Commands:         []string{"SET", "cluster"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	rdb := client.Redis()
+	_ = rdb.Set(ctx, "k", "v", 0).Err()
+	_ = rdb.Get(ctx, "k").Err()
+	_ = rdb.Do(ctx, "cluster", "info").Err()
+	_ = rdb.Do(ctx, "cluster", "slots").Err()
+	_ = rdb.Do(ctx, "mymodule.cmd", "x").Err()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	counts := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "redisx_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "command" {
+					counts[label.GetValue()] += metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	want := map[string]float64{"set": 1, "cluster": 2, "other": 2}
+	if !maps.Equal(counts, want) {
+		t.Fatalf("command labels = %v, want %v", counts, want)
+	}
+}
+
 func TestNewCanBeCalledRepeatedly(t *testing.T) {
 	server := newFakeRedisServer()
 	first, err := New(&Config{Addr: "pipe", Protocol: 2, Dialer: server.dialer})
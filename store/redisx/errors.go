@@ -3,8 +3,14 @@ package redisx
 import "errors"
 
 var (
-	ErrNilConfig       = errors.New("redisx: config is required")
-	ErrContextRequired = errors.New("redisx: context is required")
-	ErrAddrRequired    = errors.New("redisx: addr is required")
-	ErrNilHook         = errors.New("redisx: hook is required")
+	ErrNilConfig        = errors.New("redisx: config is required")
+	ErrContextRequired  = errors.New("redisx: context is required")
+	ErrAddrRequired     = errors.New("redisx: addr is required")
+	ErrNilHook          = errors.New("redisx: hook is required")
+	ErrPatternRequired  = errors.New("redisx: key pattern is required")
+	ErrScanFuncRequired = errors.New("redisx: scan func is required")
+
+	ErrInvalidFallbackAddr = errors.New("redisx: watchdog fallback addr must differ from addr")
+	ErrCircuitOpen         = errors.New("redisx: circuit breaker is open")
+	ErrWatchdogSlowPing    = errors.New("redisx: watchdog ping exceeded latency threshold")
 )
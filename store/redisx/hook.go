@@ -4,30 +4,81 @@ import (
 	"context"
 	"errors"
 	"slices"
+	"strings"
 	"time"
 
+	"github.com/bang-go/micro/pkg/budget"
 	"github.com/bang-go/micro/telemetry/logger"
+	microtrace "github.com/bang-go/micro/telemetry/trace"
 	"github.com/redis/go-redis/v9"
 )
 
+const otherCommand = "other"
+
+// MetricsConfig keeps the command label bounded. Commands sent through Do
+// with arbitrary names, and subcommands such as "cluster <sub>", would
+// otherwise each create new series.
+type MetricsConfig struct {
+	// NormalizeCommand rewrites the lower-case command name before labeling,
+	// e.g. TrimSubcommand.
+	NormalizeCommand func(command string) string
+	// Commands lists the normalized names labeled as themselves; any other
+	// command is labeled "other". Empty keeps every command. Pipelines are
+	// always labeled "pipeline".
+	Commands []string
+}
+
+// TrimSubcommand drops the subcommand, so "cluster info" and "cluster slots"
+// are both labeled "cluster".
+func TrimSubcommand(command string) string {
+	name, _, _ := strings.Cut(command, " ")
+	return name
+}
+
 type observabilityHook struct {
 	name          string
 	addr          string
 	logger        *logger.Logger
 	enableLogger  bool
 	slowThreshold time.Duration
+	trace         bool
 	metrics       *metrics
+	normalize     func(string) string
+	allowed       map[string]struct{}
 }
 
 func newObservabilityHook(conf *Config, addr string, metrics *metrics) redis.Hook {
-	return &observabilityHook{
+	h := &observabilityHook{
 		name:          conf.Name,
 		addr:          addr,
 		logger:        conf.Logger,
 		enableLogger:  conf.EnableLogger,
 		slowThreshold: conf.SlowThreshold,
+		trace:         conf.Trace,
 		metrics:       metrics,
 	}
+	if mc := conf.Metrics; mc != nil {
+		h.normalize = mc.NormalizeCommand
+		if len(mc.Commands) > 0 {
+			h.allowed = make(map[string]struct{}, len(mc.Commands))
+			for _, command := range mc.Commands {
+				h.allowed[strings.ToLower(strings.TrimSpace(command))] = struct{}{}
+			}
+		}
+	}
+	return h
+}
+
+func (h *observabilityHook) commandLabel(command string) string {
+	if h.normalize != nil {
+		command = h.normalize(command)
+	}
+	if h.allowed != nil {
+		if _, ok := h.allowed[command]; !ok {
+			return otherCommand
+		}
+	}
+	return command
 }
 
 func (h *observabilityHook) DialHook(next redis.DialHook) redis.DialHook {
@@ -36,6 +87,9 @@ func (h *observabilityHook) DialHook(next redis.DialHook) redis.DialHook {
 
 func (h *observabilityHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 	return func(ctx context.Context, cmd redis.Cmder) error {
+		// Takes effect on the socket only with ContextTimeoutEnabled.
+		ctx, cancel := budget.Context(ctx, 0)
+		defer cancel()
 		command := commandName(cmd)
 		start := time.Now()
 		err := next(ctx, cmd)
@@ -47,17 +101,45 @@ func (h *observabilityHook) ProcessHook(next redis.ProcessHook) redis.ProcessHoo
 		status := commandStatus(err)
 
 		if h.metrics != nil {
-			h.metrics.requestDuration.WithLabelValues(h.name, command, status).Observe(duration.Seconds())
-			h.metrics.requestsTotal.WithLabelValues(h.name, command, status).Inc()
+			label := h.commandLabel(command)
+			h.metrics.requestDuration.WithLabelValues(h.name, label, status).Observe(duration.Seconds())
+			h.metrics.requestsTotal.WithLabelValues(h.name, label, status).Inc()
 		}
 
+		if h.trace {
+			h.traceCacheRead(ctx, command, err)
+		}
 		h.logCommand(ctx, command, len(cmd.Args())-1, status, duration, err)
 		return err
 	}
 }
 
+// cacheReadCommands answer with redis.Nil on a miss.
+var cacheReadCommands = map[string]struct{}{
+	"get":    {},
+	"getex":  {},
+	"getdel": {},
+	"hget":   {},
+}
+
+// traceCacheRead marks single-key reads as cache.hit or cache.miss on the
+// caller's span. The key is not recorded.
+func (h *observabilityHook) traceCacheRead(ctx context.Context, command string, err error) {
+	if _, ok := cacheReadCommands[command]; !ok {
+		return
+	}
+	switch {
+	case err == nil:
+		microtrace.CacheHit(ctx, h.name, command)
+	case errors.Is(err, redis.Nil):
+		microtrace.CacheMiss(ctx, h.name, command)
+	}
+}
+
 func (h *observabilityHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
 	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, cancel := budget.Context(ctx, 0)
+		defer cancel()
 		start := time.Now()
 		err := next(ctx, cmds)
 		if isConnectionManagementPipeline(cmds) {
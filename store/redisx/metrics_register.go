@@ -9,6 +9,14 @@ import (
 type metrics struct {
 	requestDuration *prometheus.HistogramVec
 	requestsTotal   *prometheus.CounterVec
+
+	watchdogState       *prometheus.GaugeVec
+	watchdogTransitions *prometheus.CounterVec
+	watchdogProbes      *prometheus.CounterVec
+
+	scanKeys        *prometheus.CounterVec
+	scanBatches     *prometheus.CounterVec
+	scanDeletedKeys *prometheus.CounterVec
 }
 
 var (
@@ -40,10 +48,58 @@ func newRedisMetrics(registerer prometheus.Registerer) *metrics {
 			},
 			[]string{"name", "command", "status"},
 		),
+		watchdogState: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "redisx_watchdog_state",
+				Help: "Current Redis watchdog state, 1 for the active state.",
+			},
+			[]string{"name", "state"},
+		),
+		watchdogTransitions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "redisx_watchdog_transitions_total",
+				Help: "Total number of Redis watchdog state transitions.",
+			},
+			[]string{"name", "from", "to"},
+		),
+		watchdogProbes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "redisx_watchdog_probes_total",
+				Help: "Total number of Redis watchdog health probes.",
+			},
+			[]string{"name", "result"},
+		),
+		scanKeys: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "redisx_scan_keys_total",
+				Help: "Total number of keys returned by SCAN iterations.",
+			},
+			[]string{"name", "operation"},
+		),
+		scanBatches: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "redisx_scan_batches_total",
+				Help: "Total number of SCAN batches handled.",
+			},
+			[]string{"name", "operation"},
+		),
+		scanDeletedKeys: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "redisx_scan_deleted_keys_total",
+				Help: "Total number of keys deleted by DeleteByPattern.",
+			},
+			[]string{"name"},
+		),
 	}
 
 	mustRegisterCollector(registerer, &m.requestDuration, m.requestDuration)
 	mustRegisterCollector(registerer, &m.requestsTotal, m.requestsTotal)
+	mustRegisterCollector(registerer, &m.watchdogState, m.watchdogState)
+	mustRegisterCollector(registerer, &m.watchdogTransitions, m.watchdogTransitions)
+	mustRegisterCollector(registerer, &m.watchdogProbes, m.watchdogProbes)
+	mustRegisterCollector(registerer, &m.scanKeys, m.scanKeys)
+	mustRegisterCollector(registerer, &m.scanBatches, m.scanBatches)
+	mustRegisterCollector(registerer, &m.scanDeletedKeys, m.scanDeletedKeys)
 
 	return m
 }
@@ -0,0 +1,174 @@
+package redisx
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	defaultScanCount       = 500
+	defaultScanConcurrency = 1
+
+	scanOperationIterate = "iterate"
+	scanOperationDelete  = "delete"
+)
+
+// ScanOptions tunes IterateKeys and DeleteByPattern.
+type ScanOptions struct {
+	// Count is the COUNT hint of each SCAN, so also the usual batch size;
+	// defaults to 500.
+	Count int64
+	// Type keeps only keys of a Redis type, e.g. "hash".
+	Type string
+	// Concurrency is the number of batches handled at once; SCAN itself is
+	// sequential. Defaults to 1.
+	Concurrency int
+	// DryRun makes DeleteByPattern count matching keys without deleting them.
+	DryRun bool
+	// OnProgress is called after every batch with the running totals.
+	OnProgress func(ScanProgress)
+}
+
+type ScanProgress struct {
+	Scanned   int64
+	Processed int64
+}
+
+// IterateKeys walks the keys matching pattern with SCAN and calls fn with
+// each batch. Unlike KEYS it never blocks the server for long, but like SCAN
+// it may return a key more than once and misses or includes keys written
+// during the walk. The first error from fn stops the walk and is returned.
+func (c *clientEntity) IterateKeys(ctx context.Context, pattern string, fn func(context.Context, []string) error, opts *ScanOptions) (int64, error) {
+	if ctx == nil {
+		return 0, ErrContextRequired
+	}
+	if fn == nil {
+		return 0, ErrScanFuncRequired
+	}
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return 0, ErrPatternRequired
+	}
+	progress, err := c.scan(ctx, scanOperationIterate, pattern, opts, func(ctx context.Context, keys []string) (int64, error) {
+		if err := fn(ctx, keys); err != nil {
+			return 0, err
+		}
+		return int64(len(keys)), nil
+	})
+	return progress.Scanned, err
+}
+
+// DeleteByPattern removes the keys matching pattern in SCAN batches with
+// UNLINK, which frees memory off the main thread. It returns the number of
+// keys deleted, or the number matched when DryRun is set.
+func (c *clientEntity) DeleteByPattern(ctx context.Context, pattern string, opts *ScanOptions) (int64, error) {
+	if ctx == nil {
+		return 0, ErrContextRequired
+	}
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return 0, ErrPatternRequired
+	}
+	dryRun := opts != nil && opts.DryRun
+	progress, err := c.scan(ctx, scanOperationDelete, pattern, opts, func(ctx context.Context, keys []string) (int64, error) {
+		if dryRun {
+			return int64(len(keys)), nil
+		}
+		deleted, err := c.client.Unlink(ctx, keys...).Result()
+		if err == nil && c.metrics != nil {
+			c.metrics.scanDeletedKeys.WithLabelValues(c.name).Add(float64(deleted))
+		}
+		return deleted, err
+	})
+	if dryRun {
+		return progress.Scanned, err
+	}
+	return progress.Processed, err
+}
+
+func (c *clientEntity) scan(ctx context.Context, operation, pattern string, opts *ScanOptions, handle func(context.Context, []string) (int64, error)) (ScanProgress, error) {
+	var options ScanOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.Count <= 0 {
+		options.Count = defaultScanCount
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultScanConcurrency
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		scanned   atomic.Int64
+		processed atomic.Int64
+		wg        sync.WaitGroup
+	)
+	batches := make(chan []string)
+	for range options.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for keys := range batches {
+				// Drain batches handed over before the walk stopped.
+				if ctx.Err() != nil {
+					continue
+				}
+				n, err := handle(ctx, keys)
+				if err != nil {
+					cancel(err)
+					continue
+				}
+				total := processed.Add(n)
+				if c.metrics != nil {
+					c.metrics.scanBatches.WithLabelValues(c.name, operation).Inc()
+				}
+				if options.OnProgress != nil {
+					options.OnProgress(ScanProgress{Scanned: scanned.Load(), Processed: total})
+				}
+			}
+		}()
+	}
+
+	var cursor uint64
+	for {
+		var (
+			keys []string
+			err  error
+		)
+		if options.Type != "" {
+			keys, cursor, err = c.client.ScanType(ctx, cursor, pattern, options.Count, options.Type).Result()
+		} else {
+			keys, cursor, err = c.client.Scan(ctx, cursor, pattern, options.Count).Result()
+		}
+		if err != nil {
+			cancel(err)
+			break
+		}
+		if len(keys) > 0 {
+			scanned.Add(int64(len(keys)))
+			if c.metrics != nil {
+				c.metrics.scanKeys.WithLabelValues(c.name, operation).Add(float64(len(keys)))
+			}
+			select {
+			case batches <- keys:
+			case <-ctx.Done():
+			}
+		}
+		if cursor == 0 || ctx.Err() != nil {
+			break
+		}
+	}
+	close(batches)
+	wg.Wait()
+
+	progress := ScanProgress{Scanned: scanned.Load(), Processed: processed.Load()}
+	if err := context.Cause(ctx); err != nil {
+		return progress, err
+	}
+	return progress, nil
+}
@@ -0,0 +1,131 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/bang-go/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func openScanClient(t *testing.T, server *fakeRedisServer, reg prometheus.Registerer) *clientEntity {
+	t.Helper()
+	for i := range 25 {
+		server.store[fmt.Sprintf("session:%02d", i)] = "v"
+	}
+	server.store["user:1"] = "v"
+
+	client, err := Open(context.Background(), &Config{
+		Name:              "cache",
+		Addr:              "pipe",
+		Protocol:          2,
+		DisableIdentity:   util.Ptr(true),
+		Dialer:            server.dialer,
+		MetricsRegisterer: reg,
+		DisableMetrics:    reg == nil,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client.(*clientEntity)
+}
+
+func TestIterateKeys(t *testing.T) {
+	server := newFakeRedisServer()
+	client := openScanClient(t, server, nil)
+
+	var (
+		mu   sync.Mutex
+		seen []string
+	)
+	var progress []ScanProgress
+	scanned, err := client.IterateKeys(context.Background(), "session:*", func(_ context.Context, keys []string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, keys...)
+		return nil
+	}, &ScanOptions{Count: 10, Concurrency: 3, OnProgress: func(p ScanProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress = append(progress, p)
+	}})
+	if err != nil {
+		t.Fatalf("IterateKeys() error = %v", err)
+	}
+	if scanned != 25 || len(seen) != 25 {
+		t.Fatalf("scanned = %d, seen = %d", scanned, len(seen))
+	}
+	sort.Strings(seen)
+	if seen[0] != "session:00" || seen[24] != "session:24" {
+		t.Fatalf("seen = %v", seen)
+	}
+	if len(progress) == 0 {
+		t.Fatal("OnProgress was not called")
+	}
+	for _, cmd := range server.commandLog() {
+		if cmd[0] == "KEYS" {
+			t.Fatal("IterateKeys used KEYS")
+		}
+	}
+}
+
+func TestIterateKeysStopsOnError(t *testing.T) {
+	server := newFakeRedisServer()
+	client := openScanClient(t, server, nil)
+
+	boom := errors.New("boom")
+	calls := 0
+	_, err := client.IterateKeys(context.Background(), "*", func(context.Context, []string) error {
+		calls++
+		return boom
+	}, &ScanOptions{Count: 5})
+	if !errors.Is(err, boom) {
+		t.Fatalf("IterateKeys() error = %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("fn calls = %d, want the walk to stop after the first error", calls)
+	}
+}
+
+func TestDeleteByPattern(t *testing.T) {
+	server := newFakeRedisServer()
+	reg := prometheus.NewRegistry()
+	client := openScanClient(t, server, reg)
+	ctx := context.Background()
+
+	matched, err := client.DeleteByPattern(ctx, "session:*", &ScanOptions{Count: 10, DryRun: true})
+	if err != nil || matched != 25 {
+		t.Fatalf("DeleteByPattern(dry run) = %d, %v", matched, err)
+	}
+	if len(server.store) != 26 {
+		t.Fatalf("dry run deleted keys: %d left", len(server.store))
+	}
+
+	deleted, err := client.DeleteByPattern(ctx, "session:*", &ScanOptions{Count: 10, Concurrency: 2})
+	if err != nil || deleted != 25 {
+		t.Fatalf("DeleteByPattern() = %d, %v", deleted, err)
+	}
+	if _, ok := server.store["user:1"]; !ok || len(server.store) != 1 {
+		t.Fatalf("store after delete = %v", server.store)
+	}
+
+	if got := testutil.ToFloat64(client.metrics.scanDeletedKeys.WithLabelValues("cache")); got != 25 {
+		t.Fatalf("deleted keys metric = %v", got)
+	}
+	if got := testutil.ToFloat64(client.metrics.scanKeys.WithLabelValues("cache", scanOperationDelete)); got != 50 {
+		t.Fatalf("scanned keys metric = %v", got)
+	}
+
+	if _, err := client.DeleteByPattern(ctx, " ", nil); !errors.Is(err, ErrPatternRequired) {
+		t.Fatalf("DeleteByPattern(empty) error = %v", err)
+	}
+	if _, err := client.IterateKeys(ctx, "*", nil, nil); !errors.Is(err, ErrScanFuncRequired) {
+		t.Fatalf("IterateKeys(nil fn) error = %v", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -33,11 +35,15 @@ type fakeRedisServer struct {
 	mu       sync.Mutex
 	store    map[string]string
 	commands [][]string
+	// deleted keeps removed keys in the SCAN order so cursors stay stable
+	// across deletes, as Redis guarantees.
+	deleted map[string]struct{}
 }
 
 func newFakeRedisServer() *fakeRedisServer {
 	return &fakeRedisServer{
-		store: make(map[string]string),
+		store:   make(map[string]string),
+		deleted: make(map[string]struct{}),
 	}
 }
 
@@ -91,12 +97,15 @@ func (s *fakeRedisServer) serve(conn net.Conn) {
 			} else {
 				_ = writeBulkString(writer, value)
 			}
-		case "DEL":
+		case "SCAN":
+			_ = s.writeScan(writer, cmd[1:])
+		case "DEL", "UNLINK":
 			deleted := 0
 			s.mu.Lock()
 			for _, key := range cmd[1:] {
 				if _, ok := s.store[key]; ok {
 					delete(s.store, key)
+					s.deleted[key] = struct{}{}
 					deleted++
 				}
 			}
@@ -111,6 +120,62 @@ func (s *fakeRedisServer) serve(conn net.Conn) {
 	}
 }
 
+// writeScan pages through the sorted keys, using the cursor as an offset.
+func (s *fakeRedisServer) writeScan(w *bufio.Writer, args []string) error {
+	offset, _ := strconv.Atoi(args[0])
+	match, count := "*", 10
+	for i := 1; i+1 < len(args); i += 2 {
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			match = args[i+1]
+		case "COUNT":
+			count, _ = strconv.Atoi(args[i+1])
+		}
+	}
+
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.store))
+	for key := range s.store {
+		keys = append(keys, key)
+	}
+	for key := range s.deleted {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	end := min(offset+count, len(keys))
+	var page []string
+	for _, key := range keys[min(offset, len(keys)):end] {
+		if _, ok := s.store[key]; !ok {
+			continue
+		}
+		if ok, _ := path.Match(match, key); ok {
+			page = append(page, key)
+		}
+	}
+	s.mu.Unlock()
+	next := end
+	if end >= len(keys) {
+		next = 0
+	}
+
+	if _, err := io.WriteString(w, "*2\r\n"); err != nil {
+		return err
+	}
+	if err := writeBulkString(w, strconv.Itoa(next)); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(page)); err != nil {
+		return err
+	}
+	for _, key := range page {
+		if err := writeBulkString(w, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *fakeRedisServer) commandLog() [][]string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,375 @@
+package redisx
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultWatchdogInterval          = time.Second
+	defaultWatchdogTimeout           = 500 * time.Millisecond
+	defaultWatchdogFailureThreshold  = 3
+	defaultWatchdogRecoveryThreshold = 3
+)
+
+type WatchdogState string
+
+const (
+	WatchdogStatePrimary  WatchdogState = "primary"
+	WatchdogStateFallback WatchdogState = "fallback"
+	WatchdogStateOpen     WatchdogState = "open"
+)
+
+type WatchdogConfig struct {
+	// FallbackAddr receives new connections while the primary is unhealthy.
+	// When empty the watchdog opens a breaker and rejects commands instead.
+	FallbackAddr string
+
+	Interval          time.Duration
+	Timeout           time.Duration
+	LatencyThreshold  time.Duration
+	FailureThreshold  int
+	RecoveryThreshold int
+
+	OnStateChange func(WatchdogEvent)
+}
+
+type WatchdogEvent struct {
+	Name   string
+	From   WatchdogState
+	To     WatchdogState
+	Addr   string
+	Reason error
+	At     time.Time
+}
+
+type watchdog struct {
+	name        string
+	primaryAddr string
+	config      WatchdogConfig
+	logger      *logger.Logger
+	metrics     *metrics
+
+	dial  func(context.Context, string, string) (net.Conn, error)
+	probe *redis.Client
+
+	state     atomic.Value
+	connMu    sync.Mutex
+	conns     map[*trackedConn]struct{}
+	failures  int
+	successes int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func prepareWatchdogConfig(conf *WatchdogConfig, primaryAddr string) (*WatchdogConfig, error) {
+	cloned := *conf
+	cloned.FallbackAddr = strings.TrimSpace(cloned.FallbackAddr)
+	if cloned.FallbackAddr == primaryAddr {
+		return nil, ErrInvalidFallbackAddr
+	}
+	if cloned.Interval <= 0 {
+		cloned.Interval = defaultWatchdogInterval
+	}
+	if cloned.Timeout <= 0 {
+		cloned.Timeout = defaultWatchdogTimeout
+	}
+	if cloned.FailureThreshold <= 0 {
+		cloned.FailureThreshold = defaultWatchdogFailureThreshold
+	}
+	if cloned.RecoveryThreshold <= 0 {
+		cloned.RecoveryThreshold = defaultWatchdogRecoveryThreshold
+	}
+	return &cloned, nil
+}
+
+// newWatchdog rewires opts.Dialer so new connections follow the active
+// endpoint. It must be called before the redis client is built.
+func newWatchdog(conf *Config, opts *redis.Options, metrics *metrics) *watchdog {
+	dial := opts.Dialer
+	if dial == nil {
+		dial = redis.NewDialer(cloneOptions(opts))
+	}
+
+	probeOpts := cloneOptions(opts)
+	probeOpts.Dialer = dial
+	probeOpts.PoolSize = 1
+	probeOpts.MinIdleConns = 0
+	probeOpts.MaxIdleConns = 1
+	probeOpts.MaxRetries = -1
+	probeOpts.DialTimeout = conf.Watchdog.Timeout
+	probeOpts.ReadTimeout = conf.Watchdog.Timeout
+	probeOpts.WriteTimeout = conf.Watchdog.Timeout
+
+	w := &watchdog{
+		name:        conf.Name,
+		primaryAddr: opts.Addr,
+		config:      *conf.Watchdog,
+		logger:      conf.Logger,
+		metrics:     metrics,
+		dial:        dial,
+		probe:       redis.NewClient(probeOpts),
+		conns:       make(map[*trackedConn]struct{}),
+		done:        make(chan struct{}),
+	}
+	w.state.Store(WatchdogStatePrimary)
+	w.observeState(WatchdogStatePrimary)
+
+	opts.Dialer = w.dialActive
+	return w
+}
+
+func (w *watchdog) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	go w.run(ctx)
+}
+
+func (w *watchdog) stop() error {
+	var err error
+	w.once.Do(func() {
+		if w.cancel != nil {
+			w.cancel()
+			<-w.done
+		}
+		err = w.probe.Close()
+	})
+	return err
+}
+
+func (w *watchdog) current() WatchdogState {
+	return w.state.Load().(WatchdogState)
+}
+
+func (w *watchdog) activeAddr() string {
+	if w.current() == WatchdogStateFallback {
+		return w.config.FallbackAddr
+	}
+	return w.primaryAddr
+}
+
+func (w *watchdog) dialActive(ctx context.Context, network, _ string) (net.Conn, error) {
+	addr := w.activeAddr()
+	conn, err := w.dial(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := &trackedConn{Conn: conn, addr: addr, owner: w}
+	w.connMu.Lock()
+	if addr == w.activeAddr() {
+		w.conns[tracked] = struct{}{}
+	} else {
+		tracked.retired.Store(true)
+	}
+	w.connMu.Unlock()
+
+	if sc, ok := conn.(syscall.Conn); ok {
+		return &trackedSyscallConn{trackedConn: tracked, sc: sc}, nil
+	}
+	return tracked, nil
+}
+
+// setState switches the active endpoint and marks pooled connections to any
+// other endpoint as dead so the pool drops them instead of sending commands
+// to the previous target.
+func (w *watchdog) setState(state WatchdogState) {
+	w.connMu.Lock()
+	defer w.connMu.Unlock()
+
+	w.state.Store(state)
+	active := w.activeAddr()
+	for conn := range w.conns {
+		if conn.addr != active {
+			conn.retired.Store(true)
+			delete(w.conns, conn)
+		}
+	}
+}
+
+func (w *watchdog) untrack(conn *trackedConn) {
+	w.connMu.Lock()
+	delete(w.conns, conn)
+	w.connMu.Unlock()
+}
+
+func (w *watchdog) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check(ctx)
+		}
+	}
+}
+
+func (w *watchdog) check(ctx context.Context) {
+	err := w.ping(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		w.successes = 0
+		w.failures++
+		if w.current() == WatchdogStatePrimary && w.failures >= w.config.FailureThreshold {
+			to := WatchdogStateOpen
+			if w.config.FallbackAddr != "" {
+				to = WatchdogStateFallback
+			}
+			w.transition(to, err)
+		}
+		return
+	}
+
+	w.failures = 0
+	w.successes++
+	if w.current() != WatchdogStatePrimary && w.successes >= w.config.RecoveryThreshold {
+		w.transition(WatchdogStatePrimary, nil)
+	}
+}
+
+func (w *watchdog) ping(ctx context.Context) error {
+	pingCtx, cancel := context.WithTimeout(ctx, w.config.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := w.probe.Ping(pingCtx).Err()
+	duration := time.Since(start)
+
+	result := "success"
+	switch {
+	case err != nil:
+		result = "error"
+	case w.config.LatencyThreshold > 0 && duration > w.config.LatencyThreshold:
+		result = "slow"
+		err = ErrWatchdogSlowPing
+	}
+	if w.metrics != nil {
+		w.metrics.watchdogProbes.WithLabelValues(w.name, result).Inc()
+	}
+	return err
+}
+
+func (w *watchdog) transition(to WatchdogState, reason error) {
+	from := w.current()
+	w.setState(to)
+	w.observeState(to)
+	if w.metrics != nil {
+		w.metrics.watchdogTransitions.WithLabelValues(w.name, string(from), string(to)).Inc()
+	}
+
+	event := WatchdogEvent{
+		Name:   w.name,
+		From:   from,
+		To:     to,
+		Addr:   w.activeAddr(),
+		Reason: reason,
+		At:     time.Now(),
+	}
+	fields := []any{"name", w.name, "from", from, "to", to, "addr", event.Addr}
+	if reason != nil {
+		w.logger.Warn(context.Background(), "redis watchdog state changed", append(fields, "error", reason)...)
+	} else {
+		w.logger.Info(context.Background(), "redis watchdog state changed", fields...)
+	}
+	if w.config.OnStateChange != nil {
+		w.config.OnStateChange(event)
+	}
+}
+
+func (w *watchdog) observeState(state WatchdogState) {
+	if w.metrics == nil {
+		return
+	}
+	for _, s := range []WatchdogState{WatchdogStatePrimary, WatchdogStateFallback, WatchdogStateOpen} {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		w.metrics.watchdogState.WithLabelValues(w.name, string(s)).Set(value)
+	}
+}
+
+func (w *watchdog) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (w *watchdog) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if w.current() == WatchdogStateOpen {
+			cmd.SetErr(ErrCircuitOpen)
+			return ErrCircuitOpen
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (w *watchdog) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if w.current() == WatchdogStateOpen {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrCircuitOpen)
+			}
+			return ErrCircuitOpen
+		}
+		return next(ctx, cmds)
+	}
+}
+
+type trackedConn struct {
+	net.Conn
+	addr    string
+	owner   *watchdog
+	retired atomic.Bool
+}
+
+func (c *trackedConn) Read(p []byte) (int, error) {
+	if c.retired.Load() {
+		return 0, io.EOF
+	}
+	return c.Conn.Read(p)
+}
+
+func (c *trackedConn) Write(p []byte) (int, error) {
+	if c.retired.Load() {
+		return 0, io.EOF
+	}
+	return c.Conn.Write(p)
+}
+
+func (c *trackedConn) Close() error {
+	c.owner.untrack(c)
+	return c.Conn.Close()
+}
+
+// trackedSyscallConn keeps the pool's socket liveness check working for
+// connections that expose their file descriptor.
+type trackedSyscallConn struct {
+	*trackedConn
+	sc syscall.Conn
+}
+
+func (c *trackedSyscallConn) SyscallConn() (syscall.RawConn, error) {
+	if c.retired.Load() {
+		return nil, net.ErrClosed
+	}
+	return c.sc.SyscallConn()
+}
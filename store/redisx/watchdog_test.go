@@ -0,0 +1,247 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bang-go/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type routedRedis struct {
+	mu      sync.Mutex
+	servers map[string]*fakeRedisServer
+	down    map[string]bool
+	conns   map[string][]net.Conn
+}
+
+func newRoutedRedis(addrs ...string) *routedRedis {
+	r := &routedRedis{
+		servers: make(map[string]*fakeRedisServer),
+		down:    make(map[string]bool),
+		conns:   make(map[string][]net.Conn),
+	}
+	for _, addr := range addrs {
+		r.servers[addr] = newFakeRedisServer()
+	}
+	return r
+}
+
+func (r *routedRedis) dialer(ctx context.Context, network, addr string) (net.Conn, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	server, ok := r.servers[addr]
+	if !ok || r.down[addr] {
+		return nil, errors.New("connection refused")
+	}
+	client, serverConn := net.Pipe()
+	go server.serve(serverConn)
+	r.conns[addr] = append(r.conns[addr], serverConn)
+	return &peerClosedConn{Conn: client}, nil
+}
+
+// peerClosedConn makes a pipe behave like a TCP socket whose peer went away:
+// deadlines still apply and I/O reports io.EOF instead of io.ErrClosedPipe.
+type peerClosedConn struct {
+	net.Conn
+}
+
+func (c *peerClosedConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	return n, peerClosedError(err)
+}
+
+func (c *peerClosedConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	return n, peerClosedError(err)
+}
+
+func (c *peerClosedConn) SetDeadline(t time.Time) error {
+	return peerClosedError(c.Conn.SetDeadline(t))
+}
+
+func (c *peerClosedConn) SetReadDeadline(t time.Time) error {
+	return peerClosedError(c.Conn.SetReadDeadline(t))
+}
+
+func (c *peerClosedConn) SetWriteDeadline(t time.Time) error {
+	return peerClosedError(c.Conn.SetWriteDeadline(t))
+}
+
+func peerClosedError(err error) error {
+	if errors.Is(err, io.ErrClosedPipe) {
+		return io.EOF
+	}
+	return err
+}
+
+func (r *routedRedis) setDown(addr string, down bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.down[addr] = down
+	if down {
+		for _, conn := range r.conns[addr] {
+			_ = conn.Close()
+		}
+		r.conns[addr] = nil
+	}
+}
+
+func waitForWatchdogState(t *testing.T, client Client, want WatchdogState) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for client.WatchdogState() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("WatchdogState() = %q, want %q", client.WatchdogState(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatchdogFailsOverAndRecovers(t *testing.T) {
+	routes := newRoutedRedis("primary:6379", "replica:6379")
+	reg := prometheus.NewRegistry()
+	events := make(chan WatchdogEvent, 4)
+
+	client, err := Open(context.Background(), &Config{
+		Addr:              "primary:6379",
+		Protocol:          2,
+		DisableIdentity:   util.Ptr(true),
+		Dialer:            routes.dialer,
+		MetricsRegisterer: reg,
+		Watchdog: &WatchdogConfig{
+			FallbackAddr:      "replica:6379",
+			Interval:          10 * time.Millisecond,
+			FailureThreshold:  2,
+			RecoveryThreshold: 2,
+			OnStateChange: func(event WatchdogEvent) {
+				events <- event
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer client.Close()
+
+	routes.setDown("primary:6379", true)
+	waitForWatchdogState(t, client, WatchdogStateFallback)
+
+	event := <-events
+	if event.From != WatchdogStatePrimary || event.To != WatchdogStateFallback || event.Addr != "replica:6379" || event.Reason == nil {
+		t.Fatalf("failover event = %+v", event)
+	}
+
+	if err := client.Redis().Set(context.Background(), "k", "v", 0).Err(); err != nil {
+		t.Fatalf("set during failover: %v", err)
+	}
+	if got := businessCommandNames(routes.servers["replica:6379"].commandLog()); len(got) == 0 || got[len(got)-1] != "SET" {
+		t.Fatalf("replica commands = %v, want SET routed to fallback", got)
+	}
+
+	routes.setDown("primary:6379", false)
+	waitForWatchdogState(t, client, WatchdogStatePrimary)
+
+	event = <-events
+	if event.From != WatchdogStateFallback || event.To != WatchdogStatePrimary || event.Addr != "primary:6379" {
+		t.Fatalf("recovery event = %+v", event)
+	}
+
+	replicaCommands := len(routes.servers["replica:6379"].commandLog())
+	if err := client.Redis().Set(context.Background(), "k", "v2", 0).Err(); err != nil {
+		t.Fatalf("set after recovery: %v", err)
+	}
+	if got := len(routes.servers["replica:6379"].commandLog()); got != replicaCommands {
+		t.Fatalf("replica received %d commands after recovery, want 0", got-replicaCommands)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	transitions := 0.0
+	for _, family := range families {
+		if family.GetName() != "redisx_watchdog_transitions_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			transitions += metric.GetCounter().GetValue()
+		}
+	}
+	if transitions != 2 {
+		t.Fatalf("watchdog transitions = %v, want 2", transitions)
+	}
+}
+
+func TestWatchdogOpensBreakerWithoutFallback(t *testing.T) {
+	routes := newRoutedRedis("primary:6379")
+
+	client, err := Open(context.Background(), &Config{
+		Addr:            "primary:6379",
+		Protocol:        2,
+		DisableIdentity: util.Ptr(true),
+		Dialer:          routes.dialer,
+		DisableMetrics:  true,
+		Watchdog: &WatchdogConfig{
+			Interval:          10 * time.Millisecond,
+			FailureThreshold:  1,
+			RecoveryThreshold: 1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer client.Close()
+
+	routes.setDown("primary:6379", true)
+	waitForWatchdogState(t, client, WatchdogStateOpen)
+
+	if err := client.Redis().Get(context.Background(), "k").Err(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("get while open error = %v, want %v", err, ErrCircuitOpen)
+	}
+	pipe := client.Redis().Pipeline()
+	pipe.Get(context.Background(), "k")
+	if _, err := pipe.Exec(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("pipeline while open error = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	routes.setDown("primary:6379", false)
+	waitForWatchdogState(t, client, WatchdogStatePrimary)
+	if err := client.Redis().Set(context.Background(), "k", "v", 0).Err(); err != nil {
+		t.Fatalf("set after recovery: %v", err)
+	}
+}
+
+func TestWatchdogRejectsFallbackEqualToAddr(t *testing.T) {
+	_, _, err := prepareConfig(&Config{
+		Addr:     "127.0.0.1:6379",
+		Watchdog: &WatchdogConfig{FallbackAddr: " 127.0.0.1:6379 "},
+	})
+	if !errors.Is(err, ErrInvalidFallbackAddr) {
+		t.Fatalf("prepareConfig error = %v, want %v", err, ErrInvalidFallbackAddr)
+	}
+}
+
+func TestClientWithoutWatchdogReportsPrimary(t *testing.T) {
+	server := newFakeRedisServer()
+	client, err := Open(context.Background(), &Config{
+		Addr:            "pipe",
+		Protocol:        2,
+		DisableIdentity: util.Ptr(true),
+		Dialer:          server.dialer,
+		DisableMetrics:  true,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer client.Close()
+
+	if got := client.WatchdogState(); got != WatchdogStatePrimary {
+		t.Fatalf("WatchdogState() = %q, want %q", got, WatchdogStatePrimary)
+	}
+}
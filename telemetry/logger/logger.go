@@ -2,12 +2,14 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -18,18 +20,32 @@ type Logger struct {
 	handler slog.Handler
 	level   *slog.LevelVar
 	enabled *atomic.Bool
+	closer  *closer
+}
+
+// closer releases file sinks and flushes async buffers; it is shared by
+// loggers derived with With and WithGroup.
+type closer struct {
+	once    sync.Once
+	closers []io.Closer
+	err     error
 }
 
 type contextHandler struct {
-	base    slog.Handler
-	enabled *atomic.Bool
+	base         slog.Handler
+	enabled      *atomic.Bool
+	sampledLevel *slog.Level
 }
 
 type options struct {
-	level     slog.Level
-	format    string
-	addSource bool
-	output    io.Writer
+	level        slog.Level
+	sampledLevel *slog.Level
+	format       string
+	addSource    bool
+	output       io.Writer
+	sinks        []io.Writer
+	closers      []io.Closer
+	async        *AsyncConfig
 }
 
 type Option func(*options)
@@ -40,6 +56,13 @@ func WithLevel(level string) Option {
 	}
 }
 
+func WithSampledLevel(level string) Option {
+	return func(o *options) {
+		sampledLevel := parseLevel(level)
+		o.sampledLevel = &sampledLevel
+	}
+}
+
 func WithFormat(format string) Option {
 	return func(o *options) {
 		o.format = strings.ToLower(strings.TrimSpace(format))
@@ -68,9 +91,7 @@ func New(opts ...Option) *Logger {
 	for _, opt := range opts {
 		opt(config)
 	}
-	if config.output == nil {
-		config.output = io.Discard
-	}
+	output := buildOutput(config)
 
 	levelVar := &slog.LevelVar{}
 	levelVar.Set(config.level)
@@ -96,25 +117,71 @@ func New(opts ...Option) *Logger {
 	var handler slog.Handler
 	switch config.format {
 	case "", "json":
-		handler = slog.NewJSONHandler(config.output, handlerOptions)
+		handler = slog.NewJSONHandler(output, handlerOptions)
 	default:
-		handler = slog.NewTextHandler(config.output, handlerOptions)
+		handler = slog.NewTextHandler(output, handlerOptions)
 	}
 
 	enabled := &atomic.Bool{}
 	enabled.Store(true)
 	handler = &contextHandler{
-		base:    handler,
-		enabled: enabled,
+		base:         handler,
+		enabled:      enabled,
+		sampledLevel: config.sampledLevel,
 	}
 
 	return &Logger{
 		handler: handler,
 		level:   levelVar,
 		enabled: enabled,
+		closer:  &closer{closers: config.closers},
 	}
 }
 
+func buildOutput(config *options) io.Writer {
+	var writers []io.Writer
+	if config.output != nil && config.output != io.Discard {
+		writers = append(writers, config.output)
+	}
+	writers = append(writers, config.sinks...)
+
+	var output io.Writer
+	switch len(writers) {
+	case 0:
+		return io.Discard
+	case 1:
+		output = writers[0]
+	default:
+		output = multiWriter(writers)
+	}
+
+	if config.async != nil {
+		async := NewAsyncWriter(output, config.async)
+		// Flush buffered entries before closing the files they go to.
+		config.closers = append([]io.Closer{async}, config.closers...)
+		output = async
+	}
+	return output
+}
+
+// Close flushes async buffers and closes file sinks. Loggers derived with
+// With and WithGroup share the same sinks, so Close once on shutdown.
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	l.closer.once.Do(func() {
+		var errs []error
+		for _, c := range l.closer.closers {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		l.closer.err = errors.Join(errs...)
+	})
+	return l.closer.err
+}
+
 func (l *Logger) Toggle(enable bool) {
 	l.enabled.Store(enable)
 }
@@ -144,6 +211,7 @@ func (l *Logger) With(args ...any) *Logger {
 		handler: l.handler.WithAttrs(argsToAttrs(args)),
 		level:   l.level,
 		enabled: l.enabled,
+		closer:  l.closer,
 	}
 }
 
@@ -152,6 +220,7 @@ func (l *Logger) WithGroup(name string) *Logger {
 		handler: l.handler.WithGroup(name),
 		level:   l.level,
 		enabled: l.enabled,
+		closer:  l.closer,
 	}
 }
 
@@ -221,7 +290,10 @@ func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	return h.base.Enabled(ctx, level)
+	if h.base.Enabled(ctx, level) {
+		return true
+	}
+	return h.sampledLevel != nil && level >= *h.sampledLevel && trace.SpanContextFromContext(ctx).IsSampled()
 }
 
 func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
@@ -248,15 +320,17 @@ func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
 
 func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &contextHandler{
-		base:    h.base.WithAttrs(attrs),
-		enabled: h.enabled,
+		base:         h.base.WithAttrs(attrs),
+		enabled:      h.enabled,
+		sampledLevel: h.sampledLevel,
 	}
 }
 
 func (h *contextHandler) WithGroup(name string) slog.Handler {
 	return &contextHandler{
-		base:    h.base.WithGroup(name),
-		enabled: h.enabled,
+		base:         h.base.WithGroup(name),
+		enabled:      h.enabled,
+		sampledLevel: h.sampledLevel,
 	}
 }
 
@@ -147,3 +147,39 @@ func TestGetSlogSharesToggleAndInjectsTraceContext(t *testing.T) {
 		t.Fatalf("expected trace context in payload, got %#v", payload)
 	}
 }
+
+func TestSampledLevelElevatesSampledTraces(t *testing.T) {
+	var output bytes.Buffer
+	log := New(
+		WithOutput(&output),
+		WithFormat("json"),
+		WithLevel("info"),
+		WithSampledLevel("debug"),
+		WithAddSource(false),
+	)
+
+	unsampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: [16]byte{1},
+		SpanID:  [8]byte{1},
+	}))
+	log.Debug(unsampled, "unsampled")
+	log.Debug(context.Background(), "no trace")
+	if output.Len() != 0 {
+		t.Fatalf("expected debug logs without sampled trace to be dropped, got %s", output.String())
+	}
+
+	sampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    [16]byte{2},
+		SpanID:     [8]byte{2},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	log.With("component", "worker").GetSlog().DebugContext(sampled, "sampled")
+
+	var payload map[string]any
+	if err := json.Unmarshal(output.Bytes(), &payload); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v, payload = %s", err, output.String())
+	}
+	if payload["msg"] != "sampled" || payload["level"] != "DEBUG" || payload["component"] != "worker" {
+		t.Fatalf("unexpected payload: %#v", payload)
+	}
+}
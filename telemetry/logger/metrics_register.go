@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	asyncDropped *prometheus.CounterVec
+}
+
+var (
+	defaultMetricsOnce sync.Once
+	defaultMetrics     *metrics
+)
+
+func defaultLoggerMetrics() *metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = newLoggerMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetrics
+}
+
+func newLoggerMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		asyncDropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "logger_async_dropped_total",
+				Help: "Total number of log entries dropped because the async buffer was full.",
+			},
+			[]string{"sink"},
+		),
+	}
+
+	mustRegisterCollector(registerer, &m.asyncDropped, m.asyncDropped)
+
+	return m
+}
+
+func mustRegisterCollector[T prometheus.Collector](registerer prometheus.Registerer, dst *T, collector T) {
+	if registerer == nil {
+		return
+	}
+	if err := registerer.Register(collector); err != nil {
+		if alreadyRegistered, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if registered, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				*dst = registered
+				return
+			}
+		}
+		panic(err)
+	}
+}
@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	defaultFileMaxSizeMB   = 100
+	defaultAsyncBufferSize = 4096
+)
+
+// FileConfig configures a size and age rotated log file.
+type FileConfig struct {
+	Path string
+	// MaxSizeMB rotates the file once it reaches this size; defaults to 100.
+	MaxSizeMB int
+	// MaxAge removes rotated files older than this, rounded up to whole days;
+	// zero keeps them.
+	MaxAge time.Duration
+	// MaxBackups caps the number of rotated files kept; zero keeps all.
+	MaxBackups int
+	Compress   bool
+	// LocalTime names rotated files with local time instead of UTC.
+	LocalTime bool
+}
+
+// AsyncConfig buffers log writes in memory and writes them from a background
+// goroutine. When the buffer is full, entries are dropped rather than
+// blocking the caller.
+type AsyncConfig struct {
+	// Name labels the drop metric; defaults to "default".
+	Name string
+	// BufferSize is the number of buffered entries; defaults to 4096.
+	BufferSize int
+
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+}
+
+// WithFile adds a rotated file sink next to the primary output.
+func WithFile(conf FileConfig) Option {
+	return func(o *options) {
+		file := NewFileWriter(conf)
+		o.sinks = append(o.sinks, file)
+		o.closers = append(o.closers, file)
+	}
+}
+
+// WithSink adds an extra sink, e.g. a remote collector connection. Sinks are
+// written independently: one failing sink does not stop the others.
+func WithSink(w io.Writer) Option {
+	return func(o *options) {
+		if w != nil {
+			o.sinks = append(o.sinks, w)
+		}
+	}
+}
+
+// WithAsync makes all outputs asynchronous. Call Logger.Close on shutdown to
+// flush buffered entries.
+func WithAsync(conf *AsyncConfig) Option {
+	return func(o *options) {
+		if conf == nil {
+			conf = &AsyncConfig{}
+		}
+		o.async = conf
+	}
+}
+
+// NewFileWriter returns a writer rotating files per conf. The file is opened
+// lazily on first write.
+func NewFileWriter(conf FileConfig) io.WriteCloser {
+	maxSize := conf.MaxSizeMB
+	if maxSize <= 0 {
+		maxSize = defaultFileMaxSizeMB
+	}
+	maxAgeDays := 0
+	if conf.MaxAge > 0 {
+		maxAgeDays = int((conf.MaxAge + 24*time.Hour - 1) / (24 * time.Hour))
+	}
+	return &lumberjack.Logger{
+		Filename:   strings.TrimSpace(conf.Path),
+		MaxSize:    maxSize,
+		MaxAge:     maxAgeDays,
+		MaxBackups: conf.MaxBackups,
+		Compress:   conf.Compress,
+		LocalTime:  conf.LocalTime,
+	}
+}
+
+type multiWriter []io.Writer
+
+func (m multiWriter) Write(p []byte) (int, error) {
+	var errs []error
+	for _, w := range m {
+		if _, err := w.Write(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(p), errors.Join(errs...)
+}
+
+// AsyncWriter decouples callers from a slow writer. Use it directly to make a
+// single sink asynchronous; WithAsync wraps every output.
+type AsyncWriter struct {
+	out     io.Writer
+	queue   chan []byte
+	done    chan struct{}
+	dropped prometheus.Counter
+
+	mu     sync.RWMutex
+	closed bool
+	once   sync.Once
+}
+
+func NewAsyncWriter(w io.Writer, conf *AsyncConfig) *AsyncWriter {
+	if conf == nil {
+		conf = &AsyncConfig{}
+	}
+	size := conf.BufferSize
+	if size <= 0 {
+		size = defaultAsyncBufferSize
+	}
+	name := strings.TrimSpace(conf.Name)
+	if name == "" {
+		name = "default"
+	}
+
+	a := &AsyncWriter{
+		out:   w,
+		queue: make(chan []byte, size),
+		done:  make(chan struct{}),
+	}
+	if !conf.DisableMetrics {
+		metrics := defaultLoggerMetrics()
+		if conf.MetricsRegisterer != nil {
+			metrics = newLoggerMetrics(conf.MetricsRegisterer)
+		}
+		a.dropped = metrics.asyncDropped.WithLabelValues(name)
+	}
+
+	go a.run()
+	return a
+}
+
+// Write copies p into the buffer and never blocks; when the buffer is full or
+// the writer is closed, the entry is dropped and counted.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.closed {
+		select {
+		case a.queue <- append([]byte(nil), p...):
+			return len(p), nil
+		default:
+		}
+	}
+	if a.dropped != nil {
+		a.dropped.Inc()
+	}
+	return len(p), nil
+}
+
+// Close flushes buffered entries and stops the background goroutine. It does
+// not close the wrapped writer.
+func (a *AsyncWriter) Close() error {
+	a.once.Do(func() {
+		a.mu.Lock()
+		a.closed = true
+		close(a.queue)
+		a.mu.Unlock()
+		<-a.done
+	})
+	return nil
+}
+
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+	for entry := range a.queue {
+		_, _ = a.out.Write(entry)
+	}
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("remote down") }
+
+func TestFileAndSinksReceiveEveryEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var stdout, remote bytes.Buffer
+	log := New(
+		WithOutput(&stdout),
+		WithFile(FileConfig{Path: path, MaxSizeMB: 1, MaxBackups: 2}),
+		WithSink(failingWriter{}),
+		WithSink(&remote),
+		WithAddSource(false),
+	)
+	log.Info(context.Background(), "hello", "n", 1)
+	child := log.With("component", "child")
+	child.Info(context.Background(), "world")
+
+	if err := child.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	for name, got := range map[string]string{"stdout": stdout.String(), "file": string(data), "remote": remote.String()} {
+		if strings.Count(got, "\n") != 2 || !strings.Contains(got, `"msg":"world"`) {
+			t.Fatalf("%s output = %q, want both entries despite a failing sink", name, got)
+		}
+	}
+}
+
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func TestAsyncDropsWhenFullAndFlushesOnClose(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	out := &blockingWriter{release: make(chan struct{})}
+	log := New(
+		WithOutput(out),
+		WithAddSource(false),
+		WithAsync(&AsyncConfig{Name: "test", BufferSize: 2, MetricsRegisterer: reg}),
+	)
+
+	// The first entry is taken by the writer goroutine and blocks; two fill
+	// the buffer and the rest are dropped without blocking the caller.
+	for range 10 {
+		log.Info(context.Background(), "entry")
+	}
+	close(out.release)
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	written := strings.Count(out.buf.String(), "\n")
+	dropped := testutil.ToFloat64(newLoggerMetrics(reg).asyncDropped.WithLabelValues("test"))
+	if written < 2 || written > 3 || float64(written)+dropped != 10 {
+		t.Fatalf("written = %d, dropped = %v; want 2-3 written and the rest dropped", written, dropped)
+	}
+
+	// Writes after Close are dropped instead of panicking on a closed channel.
+	log.Info(context.Background(), "late")
+	if got := testutil.ToFloat64(newLoggerMetrics(reg).asyncDropped.WithLabelValues("test")); got != dropped+1 {
+		t.Fatalf("dropped after close = %v, want %v", got, dropped+1)
+	}
+}
+
+func TestNewFileWriterDefaults(t *testing.T) {
+	w := NewFileWriter(FileConfig{Path: " " + filepath.Join(t.TempDir(), "x.log") + " ", MaxAge: 36 * time.Hour})
+	defer w.Close()
+
+	file := w.(*lumberjack.Logger)
+	if file.MaxSize != defaultFileMaxSizeMB || file.MaxAge != 2 || strings.HasPrefix(file.Filename, " ") {
+		t.Fatalf("NewFileWriter() = %+v", file)
+	}
+	if _, err := io.WriteString(w, "line\n"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
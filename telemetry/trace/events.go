@@ -0,0 +1,91 @@
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Event names and attribute keys recorded by the helpers below. Components
+// use them instead of ad hoc names so trace analysis can rely on one shape.
+const (
+	EventCacheHit     = "cache.hit"
+	EventCacheMiss    = "cache.miss"
+	EventQueueEnqueue = "queue.enqueue"
+	EventQueueDequeue = "queue.dequeue"
+
+	AttrCacheName            = attribute.Key("cache.name")
+	AttrCacheOperation       = attribute.Key("cache.operation")
+	AttrMessagingSystem      = attribute.Key("messaging.system")
+	AttrMessagingDestination = attribute.Key("messaging.destination.name")
+	AttrMessageID            = attribute.Key("messaging.message.id")
+	AttrDBRows               = attribute.Key("db.rows")
+)
+
+// CacheHit records a cache.hit event on the span in ctx. Keys are never
+// recorded; pass extra attributes only when they are safe to export.
+func CacheHit(ctx context.Context, cache, operation string, attrs ...attribute.KeyValue) {
+	cacheEvent(ctx, EventCacheHit, cache, operation, attrs)
+}
+
+// CacheMiss records a cache.miss event on the span in ctx.
+func CacheMiss(ctx context.Context, cache, operation string, attrs ...attribute.KeyValue) {
+	cacheEvent(ctx, EventCacheMiss, cache, operation, attrs)
+}
+
+// QueueEnqueue records a queue.enqueue event for a published message.
+func QueueEnqueue(ctx context.Context, system, destination, messageID string, attrs ...attribute.KeyValue) {
+	queueEvent(ctx, EventQueueEnqueue, system, destination, messageID, attrs)
+}
+
+// QueueDequeue records a queue.dequeue event for a received message.
+func QueueDequeue(ctx context.Context, system, destination, messageID string, attrs ...attribute.KeyValue) {
+	queueEvent(ctx, EventQueueDequeue, system, destination, messageID, attrs)
+}
+
+// DBRows sets db.rows on the span in ctx. Negative counts, which drivers use
+// for "unknown", are ignored.
+func DBRows(ctx context.Context, rows int64) {
+	span := recordingSpan(ctx)
+	if span == nil || rows < 0 {
+		return
+	}
+	span.SetAttributes(AttrDBRows.Int64(rows))
+}
+
+func cacheEvent(ctx context.Context, name, cache, operation string, attrs []attribute.KeyValue) {
+	span := recordingSpan(ctx)
+	if span == nil {
+		return
+	}
+	span.AddEvent(name, oteltrace.WithAttributes(append([]attribute.KeyValue{
+		AttrCacheName.String(cache),
+		AttrCacheOperation.String(operation),
+	}, attrs...)...))
+}
+
+func queueEvent(ctx context.Context, name, system, destination, messageID string, attrs []attribute.KeyValue) {
+	span := recordingSpan(ctx)
+	if span == nil {
+		return
+	}
+	span.AddEvent(name, oteltrace.WithAttributes(append([]attribute.KeyValue{
+		AttrMessagingSystem.String(system),
+		AttrMessagingDestination.String(destination),
+		AttrMessageID.String(messageID),
+	}, attrs...)...))
+}
+
+// recordingSpan returns nil when there is nothing to record on, so callers
+// skip building attributes on unsampled requests.
+func recordingSpan(ctx context.Context) oteltrace.Span {
+	if ctx == nil {
+		return nil
+	}
+	span := oteltrace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return nil
+	}
+	return span
+}
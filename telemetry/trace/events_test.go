@@ -0,0 +1,70 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEventHelpers(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+
+	CacheHit(ctx, "session", "get")
+	CacheMiss(ctx, "session", "hget", attribute.String("tier", "l2"))
+	QueueEnqueue(ctx, "rocketmq", "orders", "msg-1")
+	QueueDequeue(ctx, "rocketmq", "orders", "msg-2")
+	DBRows(ctx, 3)
+	DBRows(ctx, -1)
+	span.End()
+
+	// No span, no context: nothing to record and nothing to panic on.
+	CacheHit(context.Background(), "session", "get")
+	DBRows(nil, 1)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(spans))
+	}
+	events := spans[0].Events()
+	want := []struct {
+		name  string
+		attrs map[attribute.Key]string
+	}{
+		{EventCacheHit, map[attribute.Key]string{AttrCacheName: "session", AttrCacheOperation: "get"}},
+		{EventCacheMiss, map[attribute.Key]string{AttrCacheName: "session", AttrCacheOperation: "hget", "tier": "l2"}},
+		{EventQueueEnqueue, map[attribute.Key]string{AttrMessagingSystem: "rocketmq", AttrMessagingDestination: "orders", AttrMessageID: "msg-1"}},
+		{EventQueueDequeue, map[attribute.Key]string{AttrMessagingSystem: "rocketmq", AttrMessagingDestination: "orders", AttrMessageID: "msg-2"}},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events = %d, want %d", len(events), len(want))
+	}
+	for i, w := range want {
+		if events[i].Name != w.name {
+			t.Fatalf("event %d = %q, want %q", i, events[i].Name, w.name)
+		}
+		got := make(map[attribute.Key]string, len(events[i].Attributes))
+		for _, kv := range events[i].Attributes {
+			got[kv.Key] = kv.Value.AsString()
+		}
+		for key, value := range w.attrs {
+			if got[key] != value {
+				t.Fatalf("event %s %s = %q, want %q", w.name, key, got[key], value)
+			}
+		}
+	}
+
+	var rows []int64
+	for _, kv := range spans[0].Attributes() {
+		if kv.Key == AttrDBRows {
+			rows = append(rows, kv.Value.AsInt64())
+		}
+	}
+	if len(rows) != 1 || rows[0] != 3 {
+		t.Fatalf("db.rows = %v, want [3]", rows)
+	}
+}
@@ -0,0 +1,99 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	// PropagatorTraceContext is the W3C traceparent / tracestate format.
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+	// PropagatorB3 is the single b3 header; PropagatorB3Multi the X-B3-*
+	// headers. Both accept either encoding on extract.
+	PropagatorB3      = "b3"
+	PropagatorB3Multi = "b3multi"
+	// PropagatorJaeger is the uber-trace-id header.
+	PropagatorJaeger = "jaeger"
+)
+
+var ErrUnsupportedPropagator = errors.New("trace: unsupported propagator")
+
+var defaultPropagators = []string{PropagatorTraceContext, PropagatorBaggage}
+
+// NewPropagator returns a propagator that injects every listed format and
+// extracts the first one present on the carrier, so services can accept and
+// emit W3C, B3 and Jaeger headers side by side while migrating. No names
+// yields W3C trace context plus baggage.
+func NewPropagator(names ...string) (propagation.TextMapPropagator, error) {
+	names, err := normalizePropagators(names)
+	if err != nil {
+		return nil, err
+	}
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case PropagatorB3:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case PropagatorB3Multi:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case PropagatorJaeger:
+			propagators = append(propagators, jaeger.Jaeger{})
+		}
+	}
+	// The composite propagator lets later extractors overwrite earlier ones,
+	// so extraction runs in reverse to give the first listed format priority.
+	reversed := slices.Clone(propagators)
+	slices.Reverse(reversed)
+	return priorityPropagator{
+		inject:  propagation.NewCompositeTextMapPropagator(propagators...),
+		extract: propagation.NewCompositeTextMapPropagator(reversed...),
+	}, nil
+}
+
+type priorityPropagator struct {
+	inject  propagation.TextMapPropagator
+	extract propagation.TextMapPropagator
+}
+
+func (p priorityPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	p.inject.Inject(ctx, carrier)
+}
+
+func (p priorityPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return p.extract.Extract(ctx, carrier)
+}
+
+func (p priorityPropagator) Fields() []string {
+	return p.inject.Fields()
+}
+
+func normalizePropagators(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return defaultPropagators, nil
+	}
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case PropagatorTraceContext, PropagatorBaggage, PropagatorB3, PropagatorB3Multi, PropagatorJaeger:
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedPropagator, name)
+		}
+		if !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func testSpanContext(t *testing.T, traceID, spanID string) oteltrace.SpanContext {
+	t.Helper()
+
+	tid, err := oteltrace.TraceIDFromHex(traceID)
+	if err != nil {
+		t.Fatalf("trace id: %v", err)
+	}
+	sid, err := oteltrace.SpanIDFromHex(spanID)
+	if err != nil {
+		t.Fatalf("span id: %v", err)
+	}
+	return oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: tid, SpanID: sid, TraceFlags: oteltrace.FlagsSampled, Remote: true})
+}
+
+func TestNewPropagatorInjectsEveryFormat(t *testing.T) {
+	propagator, err := NewPropagator(PropagatorTraceContext, PropagatorB3, PropagatorB3Multi, PropagatorJaeger)
+	if err != nil {
+		t.Fatalf("NewPropagator() error = %v", err)
+	}
+	sc := testSpanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(oteltrace.ContextWithSpanContext(context.Background(), sc), carrier)
+
+	for _, key := range []string{"traceparent", "b3", "x-b3-traceid", "uber-trace-id"} {
+		if carrier.Get(key) == "" {
+			t.Fatalf("header %q not injected: %v", key, carrier)
+		}
+	}
+}
+
+func TestNewPropagatorExtractPriority(t *testing.T) {
+	propagator, err := NewPropagator(PropagatorTraceContext, PropagatorB3Multi)
+	if err != nil {
+		t.Fatalf("NewPropagator() error = %v", err)
+	}
+
+	b3Only := propagation.MapCarrier{
+		"x-b3-traceid": "80f198ee56343ba864fe8b2a57d3eff7",
+		"x-b3-spanid":  "e457b5a2e4d86bd1",
+		"x-b3-sampled": "1",
+	}
+	got := oteltrace.SpanContextFromContext(propagator.Extract(context.Background(), b3Only))
+	if got.TraceID().String() != "80f198ee56343ba864fe8b2a57d3eff7" {
+		t.Fatalf("b3 trace id = %s", got.TraceID())
+	}
+
+	both := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	for key, value := range b3Only {
+		both[key] = value
+	}
+	got = oteltrace.SpanContextFromContext(propagator.Extract(context.Background(), both))
+	if got.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace id = %s, want the first listed format to win", got.TraceID())
+	}
+}
+
+func TestNewPropagatorValidation(t *testing.T) {
+	if _, err := NewPropagator("zipkin"); !errors.Is(err, ErrUnsupportedPropagator) {
+		t.Fatalf("NewPropagator(zipkin) error = %v", err)
+	}
+	if _, err := InitTracer(context.Background(), &Config{Propagators: []string{"ot"}}); !errors.Is(err, ErrUnsupportedPropagator) {
+		t.Fatalf("InitTracer() error = %v", err)
+	}
+
+	propagator, err := NewPropagator()
+	if err != nil {
+		t.Fatalf("NewPropagator() error = %v", err)
+	}
+	fields := propagator.Fields()
+	slices.Sort(fields)
+	if !slices.Equal(fields, []string{"baggage", "traceparent", "tracestate"}) {
+		t.Fatalf("default fields = %v", fields)
+	}
+}
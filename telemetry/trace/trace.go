@@ -13,10 +13,10 @@ import (
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
-	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -46,6 +46,9 @@ type Config struct {
 	Timeout           time.Duration
 	Insecure          bool
 	PrettyPrint       bool
+	// Propagators installed by InitTracer, e.g. {"tracecontext", "b3multi"};
+	// defaults to tracecontext and baggage. See NewPropagator.
+	Propagators []string
 
 	newStdoutExporter func(*Config) (sdktrace.SpanExporter, error)
 	newHTTPExporter   func(context.Context, httpExporterSettings) (sdktrace.SpanExporter, error)
@@ -106,20 +109,32 @@ func Open(ctx context.Context, conf *Config) (*sdktrace.TracerProvider, error) {
 }
 
 func InitTracer(ctx context.Context, conf *Config) (func(context.Context) error, error) {
+	var propagatorNames []string
+	if conf != nil {
+		propagatorNames = conf.Propagators
+	}
+	propagator, err := NewPropagator(propagatorNames...)
+	if err != nil {
+		return nil, err
+	}
 	tp, err := Open(ctx, conf)
 	if err != nil {
 		return nil, err
 	}
 
 	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
+	otel.SetTextMapPropagator(propagator)
 
 	return tp.Shutdown, nil
 }
 
+func IsSampled(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	return oteltrace.SpanContextFromContext(ctx).IsSampled()
+}
+
 func prepareConfig(conf *Config) (*Config, error) {
 	if conf == nil {
 		conf = &Config{}
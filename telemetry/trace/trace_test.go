@@ -183,3 +183,28 @@ func (nopPropagator) Extract(ctx context.Context, _ propagation.TextMapCarrier)
 }
 
 func (nopPropagator) Fields() []string { return nil }
+
+func TestIsSampled(t *testing.T) {
+	if IsSampled(nil) {
+		t.Fatal("expected nil context to be unsampled")
+	}
+	if IsSampled(context.Background()) {
+		t.Fatal("expected context without span to be unsampled")
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	defer tp.Shutdown(context.Background())
+	ctx, span := tp.Tracer("test").Start(context.Background(), "sampled")
+	defer span.End()
+	if !IsSampled(ctx) {
+		t.Fatal("expected sampled span context")
+	}
+
+	tp = sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample()))
+	defer tp.Shutdown(context.Background())
+	ctx, span = tp.Tracer("test").Start(context.Background(), "dropped")
+	defer span.End()
+	if IsSampled(ctx) {
+		t.Fatal("expected unsampled span context")
+	}
+}
@@ -0,0 +1,188 @@
+package ginx_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bang-go/micro/store/cache"
+	"github.com/bang-go/micro/transport/ginx/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newCacheRouter(t *testing.T, conf *middleware.CacheConfig) (*gin.Engine, *middleware.ResponseCache, *prometheus.Registry) {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	conf.Store = cache.NewMemory(nil)
+	conf.MetricsRegisterer = reg
+	rc, err := middleware.NewResponseCache(conf)
+	if err != nil {
+		t.Fatalf("NewResponseCache() error = %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(rc.Middleware())
+	return router, rc, reg
+}
+
+func getPath(router http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestResponseCacheServesCachedResponses(t *testing.T) {
+	router, _, reg := newCacheRouter(t, &middleware.CacheConfig{})
+	var calls atomic.Int32
+	router.GET("/items/:id", func(c *gin.Context) {
+		n := calls.Add(1)
+		c.Header("Cache-Control", "public, max-age=60")
+		c.Header("X-Call", strconv.Itoa(int(n)))
+		c.String(http.StatusOK, "item "+c.Param("id")+" "+c.Query("v"))
+	})
+
+	first := getPath(router, "/items/1?v=a", nil)
+	second := getPath(router, "/items/1?v=a", nil)
+	if got, want := second.Body.String(), "item 1 a"; got != want {
+		t.Fatalf("cached body = %q, want %q", got, want)
+	}
+	if got, want := second.Header().Get("X-Call"), first.Header().Get("X-Call"); got != want {
+		t.Fatalf("cached X-Call = %q, want %q", got, want)
+	}
+	if second.Header().Get("Age") == "" {
+		t.Fatal("cached response has no Age header")
+	}
+
+	getPath(router, "/items/1?v=b", nil)
+	getPath(router, "/items/2?v=a", nil)
+	if got, want := calls.Load(), int32(3); got != want {
+		t.Fatalf("handler calls = %d, want %d", got, want)
+	}
+
+	if got := cacheResults(t, reg, "hit"); got != 1 {
+		t.Fatalf("hits = %v, want 1", got)
+	}
+	if got := cacheResults(t, reg, "miss"); got != 3 {
+		t.Fatalf("misses = %v, want 3", got)
+	}
+}
+
+func TestResponseCacheHonorsCacheControl(t *testing.T) {
+	router, _, _ := newCacheRouter(t, &middleware.CacheConfig{Vary: []string{"Accept-Language"}})
+	var calls atomic.Int32
+	router.GET("/data", func(c *gin.Context) {
+		calls.Add(1)
+		if value := c.Query("cc"); value != "" {
+			c.Header("Cache-Control", value)
+		}
+		if vary := c.Query("vary"); vary != "" {
+			c.Header("Vary", vary)
+		}
+		c.String(http.StatusOK, "data")
+	})
+
+	cases := []struct {
+		name   string
+		path   string
+		header http.Header
+		cached bool
+	}{
+		{name: "no directive and no default ttl", path: "/data"},
+		{name: "no-store", path: "/data?cc=no-store,max-age=60"},
+		{name: "private", path: "/data?cc=private,max-age=60"},
+		{name: "authorized without public", path: "/data?cc=max-age=60", header: http.Header{"Authorization": {"Bearer t"}}},
+		{name: "authorized with s-maxage", path: "/data?cc=s-maxage=60", header: http.Header{"Authorization": {"Bearer t"}}, cached: true},
+		{name: "vary on unconfigured header", path: "/data?cc=max-age=60&vary=Cookie"},
+		{name: "vary on configured header", path: "/data?cc=max-age=60&vary=accept-language", cached: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := calls.Load()
+			getPath(router, tc.path, tc.header)
+			getPath(router, tc.path, tc.header)
+			want := int32(2)
+			if tc.cached {
+				want = 1
+			}
+			if got := calls.Load() - before; got != want {
+				t.Fatalf("handler calls = %d, want %d", got, want)
+			}
+		})
+	}
+
+	before := calls.Load()
+	getPath(router, "/data?cc=max-age=60&vary=accept-language", http.Header{"Accept-Language": {"en"}})
+	if got := calls.Load() - before; got != 1 {
+		t.Fatalf("request with another Accept-Language was served from cache")
+	}
+}
+
+func TestResponseCacheInvalidation(t *testing.T) {
+	router, rc, _ := newCacheRouter(t, &middleware.CacheConfig{})
+	var version atomic.Int32
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.Header("Cache-Control", "max-age=60")
+		c.String(http.StatusOK, strconv.Itoa(int(version.Load())))
+	})
+	router.PUT("/users/:id", rc.InvalidateMiddleware(), func(c *gin.Context) {
+		version.Add(1)
+		c.Status(http.StatusNoContent)
+	})
+
+	if got := getPath(router, "/users/7", nil).Body.String(); got != "0" {
+		t.Fatalf("body = %q, want 0", got)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/users/7", nil))
+	if got := getPath(router, "/users/7", nil).Body.String(); got != "1" {
+		t.Fatalf("body after PUT = %q, want 1", got)
+	}
+
+	version.Add(1)
+	if got := getPath(router, "/users/7", nil).Body.String(); got != "1" {
+		t.Fatalf("body = %q, want cached 1", got)
+	}
+	if err := rc.Invalidate(context.Background(), "/users/:id"); err != nil {
+		t.Fatalf("Invalidate() error = %v", err)
+	}
+	if got := getPath(router, "/users/7", nil).Body.String(); got != "2" {
+		t.Fatalf("body after Invalidate = %q, want 2", got)
+	}
+}
+
+func TestNewResponseCacheRequiresStore(t *testing.T) {
+	if _, err := middleware.NewResponseCache(&middleware.CacheConfig{}); !errors.Is(err, middleware.ErrCacheStoreRequired) {
+		t.Fatalf("NewResponseCache() error = %v, want %v", err, middleware.ErrCacheStoreRequired)
+	}
+}
+
+func cacheResults(t *testing.T, reg *prometheus.Registry, result string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	var total float64
+	for _, family := range families {
+		if family.GetName() != "ginx_server_cache_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "result" && label.GetValue() == result {
+					total += metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return total
+}
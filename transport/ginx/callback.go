@@ -0,0 +1,221 @@
+package ginx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCallbackMaxBodyBytes = 1 << 20
+	defaultCallbackLease        = 30 * time.Second
+	callbackRawBodyKey          = "ginx.callback.raw_body"
+)
+
+type CallbackEvent struct {
+	Key     string
+	Payload any
+}
+
+// CallbackVerifyFunc checks the signature and extracts the idempotency key.
+// The request body has already been buffered and rewound, so SDK parsers can read it again.
+type CallbackVerifyFunc func(req *http.Request, rawBody []byte) (*CallbackEvent, error)
+
+type CallbackHandleFunc func(ctx context.Context, event *CallbackEvent) error
+
+type CallbackAck interface {
+	Success(*gin.Context)
+	Failure(*gin.Context, error)
+}
+
+type CallbackConfig struct {
+	Name         string
+	Verify       CallbackVerifyFunc
+	Handle       CallbackHandleFunc
+	Ack          CallbackAck
+	Store        IdempotencyStore
+	MaxBodyBytes int64
+	Lease        time.Duration
+	Logger       *logger.Logger
+}
+
+type callbackEntity struct {
+	config *CallbackConfig
+	logger *logger.Logger
+}
+
+func NewCallbackHandler(conf *CallbackConfig) (gin.HandlerFunc, error) {
+	if conf == nil {
+		conf = &CallbackConfig{}
+	}
+	config := *conf
+	config.Name = strings.TrimSpace(config.Name)
+	if config.Verify == nil {
+		return nil, ErrCallbackVerifyRequired
+	}
+	if config.Handle == nil {
+		return nil, ErrCallbackHandlerRequired
+	}
+	if config.Ack == nil {
+		return nil, ErrCallbackAckRequired
+	}
+	if config.Store == nil {
+		return nil, ErrCallbackStoreRequired
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaultCallbackMaxBodyBytes
+	}
+	if config.Lease <= 0 {
+		config.Lease = defaultCallbackLease
+	}
+
+	log := config.Logger
+	if log == nil {
+		log = logger.New(logger.WithLevel("info"))
+	}
+	entity := &callbackEntity{config: &config, logger: log}
+	return entity.serve, nil
+}
+
+func RawBody(c *gin.Context) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	value, ok := c.Get(callbackRawBodyKey)
+	if !ok {
+		return nil, false
+	}
+	raw, ok := value.([]byte)
+	return raw, ok
+}
+
+func (h *callbackEntity) serve(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	raw, err := h.readBody(c)
+	if err != nil {
+		h.logger.Warn(ctx, "callback_body_rejected", "callback", h.config.Name, "error", err)
+		h.config.Ack.Failure(c, err)
+		return
+	}
+
+	event, err := h.config.Verify(c.Request, raw)
+	if err == nil && (event == nil || strings.TrimSpace(event.Key) == "") {
+		err = ErrCallbackKeyRequired
+	}
+	if err != nil {
+		h.logger.Warn(ctx, "callback_verify_failed", "callback", h.config.Name, "error", err)
+		h.config.Ack.Failure(c, err)
+		return
+	}
+
+	key := h.storeKey(event.Key)
+	state, err := h.config.Store.Acquire(ctx, key, h.config.Lease)
+	if err != nil {
+		h.logger.Error(ctx, "callback_acquire_failed", "callback", h.config.Name, "key", event.Key, "error", err)
+		h.config.Ack.Failure(c, err)
+		return
+	}
+	switch state {
+	case IdempotencyCompleted:
+		h.logger.Info(ctx, "callback_duplicate", "callback", h.config.Name, "key", event.Key)
+		h.config.Ack.Success(c)
+		return
+	case IdempotencyInProgress:
+		h.logger.Warn(ctx, "callback_in_progress", "callback", h.config.Name, "key", event.Key)
+		h.config.Ack.Failure(c, ErrCallbackInProgress)
+		return
+	}
+
+	// A disconnecting provider must not abort half-applied business logic;
+	// the lease bounds how long the handler may run instead.
+	handleCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.config.Lease)
+	defer cancel()
+
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if err := h.config.Store.Release(context.WithoutCancel(ctx), key); err != nil {
+			h.logger.Error(ctx, "callback_release_failed", "callback", h.config.Name, "key", event.Key, "error", err)
+		}
+	}()
+
+	if err := h.config.Handle(handleCtx, event); err != nil {
+		h.logger.Error(ctx, "callback_handle_failed", "callback", h.config.Name, "key", event.Key, "error", err)
+		h.config.Ack.Failure(c, err)
+		return
+	}
+
+	completed = true
+	if err := h.config.Store.Complete(context.WithoutCancel(ctx), key); err != nil {
+		h.logger.Error(ctx, "callback_complete_failed", "callback", h.config.Name, "key", event.Key, "error", err)
+	}
+	h.config.Ack.Success(c)
+}
+
+func (h *callbackEntity) readBody(c *gin.Context) ([]byte, error) {
+	if c.Request.Body == nil {
+		c.Request.Body = http.NoBody
+	}
+	raw, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, h.config.MaxBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, ErrCallbackBodyTooLarge
+		}
+		return nil, err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+	c.Set(callbackRawBodyKey, raw)
+	return raw, nil
+}
+
+func (h *callbackEntity) storeKey(key string) string {
+	key = strings.TrimSpace(key)
+	if h.config.Name == "" {
+		return key
+	}
+	return h.config.Name + ":" + key
+}
+
+type wechatCallbackAck struct{}
+
+// WechatCallbackAck answers WeChat Pay v3 notifications: 200 without a body on
+// success, 500 with {"code":"FAIL"} otherwise so WeChat retries.
+func WechatCallbackAck() CallbackAck {
+	return wechatCallbackAck{}
+}
+
+func (wechatCallbackAck) Success(c *gin.Context) {
+	c.Status(http.StatusOK)
+}
+
+func (wechatCallbackAck) Failure(c *gin.Context, err error) {
+	c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"code": "FAIL", "message": "失败"})
+}
+
+type alipayCallbackAck struct{}
+
+// AlipayCallbackAck answers Alipay notifications with the plain-text "success"
+// or "fail" bodies Alipay expects; anything but "success" triggers a retry.
+func AlipayCallbackAck() CallbackAck {
+	return alipayCallbackAck{}
+}
+
+func (alipayCallbackAck) Success(c *gin.Context) {
+	c.String(http.StatusOK, "success")
+}
+
+func (alipayCallbackAck) Failure(c *gin.Context, err error) {
+	c.Abort()
+	c.String(http.StatusOK, "fail")
+}
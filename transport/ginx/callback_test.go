@@ -0,0 +1,268 @@
+package ginx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/ginx"
+	"github.com/gin-gonic/gin"
+)
+
+func newCallbackRouter(t *testing.T, conf *ginx.CallbackConfig) *gin.Engine {
+	t.Helper()
+
+	if conf.Store == nil {
+		conf.Store = ginx.NewMemoryIdempotencyStore(0)
+	}
+	handler, err := ginx.NewCallbackHandler(conf)
+	if err != nil {
+		t.Fatalf("NewCallbackHandler() error = %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	router.POST("/notify", handler)
+	return router
+}
+
+func postCallback(router http.Handler, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader(body))
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func keyFromBody(req *http.Request, raw []byte) (*ginx.CallbackEvent, error) {
+	reread, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if string(reread) != string(raw) {
+		return nil, errors.New("request body was not rewound")
+	}
+	if string(raw) == "bad-sign" {
+		return nil, errors.New("signature mismatch")
+	}
+	return &ginx.CallbackEvent{Key: string(raw), Payload: string(raw)}, nil
+}
+
+func TestCallbackHandlerAcknowledgesDuplicatesWithoutReprocessing(t *testing.T) {
+	var calls atomic.Int32
+	router := newCallbackRouter(t, &ginx.CallbackConfig{
+		Name:   "alipay",
+		Verify: keyFromBody,
+		Handle: func(ctx context.Context, event *ginx.CallbackEvent) error {
+			calls.Add(1)
+			return nil
+		},
+		Ack: ginx.AlipayCallbackAck(),
+	})
+
+	for i := 0; i < 3; i++ {
+		recorder := postCallback(router, "trade-1")
+		if recorder.Code != http.StatusOK || recorder.Body.String() != "success" {
+			t.Fatalf("attempt %d = %d %q, want 200 success", i, recorder.Code, recorder.Body.String())
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("handler calls = %d, want 1", got)
+	}
+
+	postCallback(router, "trade-2")
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("handler calls = %d, want 2", got)
+	}
+}
+
+func TestCallbackHandlerReleasesKeyOnFailure(t *testing.T) {
+	var calls atomic.Int32
+	router := newCallbackRouter(t, &ginx.CallbackConfig{
+		Verify: keyFromBody,
+		Handle: func(ctx context.Context, event *ginx.CallbackEvent) error {
+			if calls.Add(1) == 1 {
+				return errors.New("db unavailable")
+			}
+			return nil
+		},
+		Ack: ginx.WechatCallbackAck(),
+	})
+
+	recorder := postCallback(router, "notify-1")
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("first attempt status = %d, want 500", recorder.Code)
+	}
+	if !strings.Contains(recorder.Body.String(), `"code":"FAIL"`) {
+		t.Fatalf("first attempt body = %q, want FAIL ack", recorder.Body.String())
+	}
+
+	recorder = postCallback(router, "notify-1")
+	if recorder.Code != http.StatusOK || recorder.Body.Len() != 0 {
+		t.Fatalf("retry = %d %q, want empty 200", recorder.Code, recorder.Body.String())
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("handler calls = %d, want 2", got)
+	}
+}
+
+func TestCallbackHandlerReleasesKeyOnPanic(t *testing.T) {
+	var calls atomic.Int32
+	router := newCallbackRouter(t, &ginx.CallbackConfig{
+		Verify: keyFromBody,
+		Handle: func(ctx context.Context, event *ginx.CallbackEvent) error {
+			if calls.Add(1) == 1 {
+				panic("boom")
+			}
+			return nil
+		},
+		Ack: ginx.AlipayCallbackAck(),
+	})
+
+	if recorder := postCallback(router, "notify-1"); recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("panicking attempt status = %d, want 500", recorder.Code)
+	}
+	if recorder := postCallback(router, "notify-1"); recorder.Body.String() != "success" {
+		t.Fatalf("retry body = %q, want success", recorder.Body.String())
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("handler calls = %d, want 2", got)
+	}
+}
+
+func TestCallbackHandlerRejectsConcurrentDelivery(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	router := newCallbackRouter(t, &ginx.CallbackConfig{
+		Verify: keyFromBody,
+		Handle: func(ctx context.Context, event *ginx.CallbackEvent) error {
+			close(started)
+			<-release
+			return nil
+		},
+		Ack: ginx.AlipayCallbackAck(),
+	})
+
+	first := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		first <- postCallback(router, "notify-1")
+	}()
+	<-started
+
+	if recorder := postCallback(router, "notify-1"); recorder.Body.String() != "fail" {
+		t.Fatalf("concurrent delivery body = %q, want fail", recorder.Body.String())
+	}
+	close(release)
+
+	select {
+	case recorder := <-first:
+		if recorder.Body.String() != "success" {
+			t.Fatalf("first delivery body = %q, want success", recorder.Body.String())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("first delivery did not finish")
+	}
+}
+
+func TestCallbackHandlerRejectsInvalidRequests(t *testing.T) {
+	var calls atomic.Int32
+	router := newCallbackRouter(t, &ginx.CallbackConfig{
+		Verify: keyFromBody,
+		Handle: func(ctx context.Context, event *ginx.CallbackEvent) error {
+			calls.Add(1)
+			return nil
+		},
+		Ack:          ginx.AlipayCallbackAck(),
+		MaxBodyBytes: 16,
+	})
+
+	for _, body := range []string{"bad-sign", "", strings.Repeat("x", 32)} {
+		if recorder := postCallback(router, body); recorder.Body.String() != "fail" {
+			t.Fatalf("body %q ack = %q, want fail", body, recorder.Body.String())
+		}
+	}
+	if got := calls.Load(); got != 0 {
+		t.Fatalf("handler calls = %d, want 0", got)
+	}
+}
+
+func TestCallbackHandlerExposesRawBody(t *testing.T) {
+	var raw string
+	gin.SetMode(gin.TestMode)
+	handler, err := ginx.NewCallbackHandler(&ginx.CallbackConfig{
+		Verify: keyFromBody,
+		Handle: func(ctx context.Context, event *ginx.CallbackEvent) error { return nil },
+		Ack:    ginx.AlipayCallbackAck(),
+		Store:  ginx.NewMemoryIdempotencyStore(0),
+	})
+	if err != nil {
+		t.Fatalf("NewCallbackHandler() error = %v", err)
+	}
+	router := gin.New()
+	router.POST("/notify", handler, func(c *gin.Context) {
+		body, _ := ginx.RawBody(c)
+		raw = string(body)
+	})
+
+	postCallback(router, `{"id":"1"}`)
+	if raw != `{"id":"1"}` {
+		t.Fatalf("RawBody() = %q, want original payload", raw)
+	}
+}
+
+func TestNewCallbackHandlerValidation(t *testing.T) {
+	verify := func(*http.Request, []byte) (*ginx.CallbackEvent, error) { return nil, nil }
+	handle := func(context.Context, *ginx.CallbackEvent) error { return nil }
+
+	tests := []struct {
+		conf *ginx.CallbackConfig
+		want error
+	}{
+		{conf: nil, want: ginx.ErrCallbackVerifyRequired},
+		{conf: &ginx.CallbackConfig{Verify: verify}, want: ginx.ErrCallbackHandlerRequired},
+		{conf: &ginx.CallbackConfig{Verify: verify, Handle: handle}, want: ginx.ErrCallbackAckRequired},
+		{conf: &ginx.CallbackConfig{Verify: verify, Handle: handle, Ack: ginx.AlipayCallbackAck()}, want: ginx.ErrCallbackStoreRequired},
+	}
+	for _, tt := range tests {
+		if _, err := ginx.NewCallbackHandler(tt.conf); !errors.Is(err, tt.want) {
+			t.Fatalf("NewCallbackHandler() error = %v, want %v", err, tt.want)
+		}
+	}
+}
+
+func TestMemoryIdempotencyStoreLeaseExpires(t *testing.T) {
+	store := ginx.NewMemoryIdempotencyStore(time.Hour)
+	ctx := context.Background()
+
+	state, err := store.Acquire(ctx, "k", 20*time.Millisecond)
+	if err != nil || state != ginx.IdempotencyAcquired {
+		t.Fatalf("Acquire() = %v, %v, want acquired", state, err)
+	}
+	if state, _ = store.Acquire(ctx, "k", time.Second); state != ginx.IdempotencyInProgress {
+		t.Fatalf("Acquire() during lease = %v, want in_progress", state)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if state, _ = store.Acquire(ctx, "k", time.Second); state != ginx.IdempotencyAcquired {
+		t.Fatalf("Acquire() after lease = %v, want acquired", state)
+	}
+	if err := store.Complete(ctx, "k"); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if err := store.Release(ctx, "k"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if state, _ = store.Acquire(ctx, "k", time.Second); state != ginx.IdempotencyCompleted {
+		t.Fatalf("Acquire() after complete = %v, want completed", state)
+	}
+	if _, err := store.Acquire(nil, "k", time.Second); !errors.Is(err, ginx.ErrContextRequired) {
+		t.Fatalf("Acquire(nil) error = %v, want %v", err, ginx.ErrContextRequired)
+	}
+}
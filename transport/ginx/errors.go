@@ -7,4 +7,13 @@ var (
 	ErrNilListener          = errors.New("ginx: listener is required")
 	ErrServerAddrRequired   = errors.New("ginx: server addr or listener is required")
 	ErrServerAlreadyRunning = errors.New("ginx: server already running")
+
+	ErrCallbackVerifyRequired  = errors.New("ginx: callback verify func is required")
+	ErrCallbackHandlerRequired = errors.New("ginx: callback handler is required")
+	ErrCallbackAckRequired     = errors.New("ginx: callback ack is required")
+	ErrCallbackKeyRequired     = errors.New("ginx: callback idempotency key is required")
+	ErrCallbackStoreRequired   = errors.New("ginx: callback idempotency store is required")
+	ErrCallbackBodyTooLarge    = errors.New("ginx: callback body too large")
+	ErrCallbackInProgress      = errors.New("ginx: callback is already being processed")
+	ErrRedisClientRequired     = errors.New("ginx: redis client is required")
 )
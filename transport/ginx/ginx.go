@@ -60,6 +60,13 @@ type ServerConfig struct {
 	DisableHealthEndpoint bool
 	HealthPath            string
 	HealthHandler         http.Handler
+
+	// Limit rejects oversized or pathologically nested request bodies before
+	// they reach handlers. Nil disables the check.
+	Limit *middleware.LimitConfig
+	// I18n negotiates the response language from Accept-Language so
+	// WriteError and BindError answer in it. Nil disables it.
+	I18n *middleware.I18nConfig
 }
 
 type serverEntity struct {
@@ -135,6 +142,12 @@ func New(conf *ServerConfig) Server {
 		ginEngine.Use(middleware.LoggerMiddleware(conf.Logger, observabilitySkipPaths...))
 	}
 	ginEngine.Use(middleware.RecoveryMiddleware(conf.Logger))
+	if conf.Limit != nil {
+		ginEngine.Use(middleware.LimitMiddleware(limitConfig(conf)))
+	}
+	if conf.I18n != nil {
+		ginEngine.Use(middleware.I18nMiddleware(conf.I18n))
+	}
 
 	return &serverEntity{
 		config:    conf,
@@ -142,6 +155,15 @@ func New(conf *ServerConfig) Server {
 	}
 }
 
+func limitConfig(conf *ServerConfig) *middleware.LimitConfig {
+	cloned := *conf.Limit
+	if cloned.MetricsRegisterer == nil {
+		cloned.MetricsRegisterer = conf.MetricsRegisterer
+	}
+	cloned.DisableMetrics = cloned.DisableMetrics || conf.DisableMetrics
+	return &cloned
+}
+
 func (s *serverEntity) GinEngine() *gin.Engine {
 	return s.ginEngine
 }
@@ -0,0 +1,107 @@
+package ginx
+
+import (
+	"errors"
+	"net/http"
+
+	microerrors "github.com/bang-go/micro/pkg/errors"
+	"github.com/bang-go/micro/pkg/i18n"
+	"github.com/bang-go/micro/transport/ginx/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ErrorResponse is the JSON body written by WriteError.
+type ErrorResponse struct {
+	Code    microerrors.Code `json:"code"`
+	Reason  string           `json:"reason,omitempty"`
+	Message string           `json:"message"`
+	Fields  []FieldError     `json:"fields,omitempty"`
+}
+
+type FieldError struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// Localizer returns the localizer negotiated by middleware.I18nMiddleware,
+// or nil, whose methods return message keys unchanged.
+func Localizer(c *gin.Context) *i18n.Localizer {
+	if value, ok := c.Get(middleware.LocalizerKey); ok {
+		if localizer, ok := value.(*i18n.Localizer); ok {
+			return localizer
+		}
+	}
+	return i18n.FromContext(c.Request.Context())
+}
+
+// WriteError aborts the request with err as an ErrorResponse. The message is
+// the translation of the error's Reason, then of "error.<CODE>", with
+// Metadata filling placeholders; untranslated errors keep their Message or
+// fall back to the HTTP status text, so causes of unknown errors never leak.
+func WriteError(c *gin.Context, err error) {
+	coded := microerrors.FromError(err)
+	if coded == nil {
+		return
+	}
+	status := coded.Code.HTTPStatus()
+	localizer := Localizer(c)
+	args := make(map[string]any, len(coded.Metadata))
+	for key, value := range coded.Metadata {
+		args[key] = value
+	}
+
+	message, ok := "", false
+	if coded.Reason != "" {
+		message, ok = localizer.Lookup(coded.Reason, args)
+	}
+	if !ok {
+		message, ok = localizer.Lookup("error."+string(coded.Code), args)
+	}
+	if !ok {
+		message = coded.Message
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+
+	response := ErrorResponse{Code: coded.Code, Reason: coded.Reason, Message: message}
+	for _, field := range coded.Fields {
+		response.Fields = append(response.Fields, FieldError{Field: field.Field, Description: field.Description})
+	}
+	c.AbortWithStatusJSON(status, response)
+}
+
+// BindError converts an error from c.ShouldBind* into an INVALID_ARGUMENT
+// error. Validation failures become field violations translated with
+// "validation.<tag>", then "validation.default", where {field} is the
+// translation of "field.<Field>" and {param} the tag parameter.
+func BindError(c *gin.Context, err error) *microerrors.Error {
+	if err == nil {
+		return nil
+	}
+	coded := microerrors.Wrap(err, microerrors.CodeInvalidArgument, "")
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return coded
+	}
+
+	localizer := Localizer(c)
+	fields := make([]microerrors.FieldViolation, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		label, ok := localizer.Lookup("field."+fieldErr.Field(), nil)
+		if !ok {
+			label = fieldErr.Field()
+		}
+		args := map[string]any{"field": label, "param": fieldErr.Param()}
+		description, ok := localizer.Lookup("validation."+fieldErr.Tag(), args)
+		if !ok {
+			description, ok = localizer.Lookup("validation.default", args)
+		}
+		if !ok {
+			description = fieldErr.Error()
+		}
+		fields = append(fields, microerrors.FieldViolation{Field: fieldErr.Field(), Description: description})
+	}
+	return coded.WithFields(fields...)
+}
@@ -0,0 +1,148 @@
+package ginx_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	microerrors "github.com/bang-go/micro/pkg/errors"
+	"github.com/bang-go/micro/pkg/i18n"
+	"github.com/bang-go/micro/transport/ginx"
+	"github.com/bang-go/micro/transport/ginx/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func newI18nRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	bundle, err := i18n.NewBundle("en")
+	if err != nil {
+		t.Fatalf("NewBundle() error = %v", err)
+	}
+	if err := bundle.AddMessages("en", map[string]string{
+		"USER_BANNED":        "User {user} is banned",
+		"error.NOT_FOUND":    "Not found",
+		"validation.default": "{field} is invalid",
+	}); err != nil {
+		t.Fatalf("AddMessages(en) error = %v", err)
+	}
+	if err := bundle.AddMessages("zh", map[string]string{
+		"USER_BANNED":         "用户 {user} 已被封禁",
+		"validation.required": "{field}不能为空",
+		"validation.min":      "{field}至少 {param} 个字符",
+		"field.Name":          "名称",
+	}); err != nil {
+		t.Fatalf("AddMessages(zh) error = %v", err)
+	}
+
+	server := ginx.New(&ginx.ServerConfig{
+		Mode:           gin.TestMode,
+		DisableMetrics: true,
+		I18n:           &middleware.I18nConfig{Bundle: bundle, QueryParam: "lang"},
+	})
+	router := server.GinEngine()
+	router.GET("/banned", func(c *gin.Context) {
+		ginx.WriteError(c, microerrors.New(microerrors.CodePermissionDenied, "banned").
+			WithReason("USER_BANNED", "user", map[string]string{"user": "ann"}))
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		ginx.WriteError(c, microerrors.New(microerrors.CodeNotFound, ""))
+	})
+	router.GET("/internal", func(c *gin.Context) {
+		ginx.WriteError(c, errorString("dial tcp 10.0.0.1:3306: connection refused"))
+	})
+	router.POST("/items", func(c *gin.Context) {
+		var body struct {
+			Name  string `json:"name" binding:"required,min=3"`
+			Email string `json:"email" binding:"required,email"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			ginx.WriteError(c, ginx.BindError(c, err))
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+func doI18nRequest(t *testing.T, router http.Handler, method, target, lang, body string) (*httptest.ResponseRecorder, ginx.ErrorResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if lang != "" {
+		req.Header.Set("Accept-Language", lang)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var resp ginx.ErrorResponse
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode %s: %v", rec.Body.String(), err)
+		}
+	}
+	return rec, resp
+}
+
+func TestWriteErrorLocalizesMessages(t *testing.T) {
+	router := newI18nRouter(t)
+
+	rec, resp := doI18nRequest(t, router, http.MethodGet, "/banned", "zh-CN,zh;q=0.9", "")
+	if rec.Code != http.StatusForbidden || resp.Message != "用户 ann 已被封禁" || resp.Reason != "USER_BANNED" {
+		t.Fatalf("zh banned = %d %+v", rec.Code, resp)
+	}
+	if got := rec.Header().Get("Content-Language"); got != "zh" {
+		t.Fatalf("Content-Language = %q", got)
+	}
+	if got := rec.Header().Get("Vary"); got != "Accept-Language" {
+		t.Fatalf("Vary = %q", got)
+	}
+
+	_, resp = doI18nRequest(t, router, http.MethodGet, "/banned?lang=en", "zh", "")
+	if resp.Message != "User ann is banned" {
+		t.Fatalf("query override message = %q", resp.Message)
+	}
+
+	// zh has no error.NOT_FOUND, so the chain falls back to English.
+	rec, resp = doI18nRequest(t, router, http.MethodGet, "/missing", "zh", "")
+	if rec.Code != http.StatusNotFound || resp.Message != "Not found" || resp.Code != microerrors.CodeNotFound {
+		t.Fatalf("missing = %d %+v", rec.Code, resp)
+	}
+
+	rec, resp = doI18nRequest(t, router, http.MethodGet, "/internal", "", "")
+	if rec.Code != http.StatusInternalServerError || resp.Message != "Internal Server Error" {
+		t.Fatalf("internal = %d %+v", rec.Code, resp)
+	}
+}
+
+func TestBindErrorLocalizesFieldViolations(t *testing.T) {
+	router := newI18nRouter(t)
+
+	rec, resp := doI18nRequest(t, router, http.MethodPost, "/items", "zh", `{"name":"ab"}`)
+	if rec.Code != http.StatusBadRequest || resp.Code != microerrors.CodeInvalidArgument {
+		t.Fatalf("zh items = %d %+v", rec.Code, resp)
+	}
+	want := []ginx.FieldError{
+		{Field: "Name", Description: "名称至少 3 个字符"},
+		{Field: "Email", Description: "Email不能为空"},
+	}
+	if len(resp.Fields) != len(want) || resp.Fields[0] != want[0] || resp.Fields[1] != want[1] {
+		t.Fatalf("zh fields = %+v", resp.Fields)
+	}
+
+	_, resp = doI18nRequest(t, router, http.MethodPost, "/items", "en", `{"name":"abc","email":"nope"}`)
+	if len(resp.Fields) != 1 || resp.Fields[0].Description != "Email is invalid" {
+		t.Fatalf("en fields = %+v", resp.Fields)
+	}
+
+	rec, resp = doI18nRequest(t, router, http.MethodPost, "/items", "en", `{`)
+	if rec.Code != http.StatusBadRequest || len(resp.Fields) != 0 || resp.Message != "Bad Request" {
+		t.Fatalf("malformed = %d %+v", rec.Code, resp)
+	}
+}
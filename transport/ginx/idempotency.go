@@ -0,0 +1,131 @@
+package ginx
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultIdempotencyRetention     = 24 * time.Hour
+	defaultIdempotencySweepInterval = time.Minute
+)
+
+type IdempotencyState int
+
+const (
+	IdempotencyAcquired IdempotencyState = iota
+	IdempotencyInProgress
+	IdempotencyCompleted
+)
+
+func (s IdempotencyState) String() string {
+	switch s {
+	case IdempotencyAcquired:
+		return "acquired"
+	case IdempotencyInProgress:
+		return "in_progress"
+	case IdempotencyCompleted:
+		return "completed"
+	default:
+		return "unknown"
+	}
+}
+
+// IdempotencyStore deduplicates callbacks. Acquire leases an unseen key,
+// Complete marks it processed, and Release drops the lease so the next retry runs again.
+type IdempotencyStore interface {
+	Acquire(ctx context.Context, key string, lease time.Duration) (IdempotencyState, error)
+	Complete(ctx context.Context, key string) error
+	Release(ctx context.Context, key string) error
+}
+
+type memoryIdempotencyEntry struct {
+	completed bool
+	expiresAt time.Time
+}
+
+type memoryIdempotencyStore struct {
+	retention time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]memoryIdempotencyEntry
+	lastSweep time.Time
+}
+
+func NewMemoryIdempotencyStore(retention time.Duration) IdempotencyStore {
+	if retention <= 0 {
+		retention = defaultIdempotencyRetention
+	}
+	return &memoryIdempotencyStore{
+		retention: retention,
+		now:       time.Now,
+		entries:   make(map[string]memoryIdempotencyEntry),
+	}
+}
+
+func (s *memoryIdempotencyStore) Acquire(ctx context.Context, key string, lease time.Duration) (IdempotencyState, error) {
+	if err := validateContext(ctx); err != nil {
+		return 0, err
+	}
+	if key == "" {
+		return 0, ErrCallbackKeyRequired
+	}
+
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		if entry.completed {
+			return IdempotencyCompleted, nil
+		}
+		return IdempotencyInProgress, nil
+	}
+	s.entries[key] = memoryIdempotencyEntry{expiresAt: now.Add(lease)}
+	return IdempotencyAcquired, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, key string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if key == "" {
+		return ErrCallbackKeyRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryIdempotencyEntry{completed: true, expiresAt: s.now().Add(s.retention)}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if key == "" {
+		return ErrCallbackKeyRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[key]; ok && !entry.completed {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < defaultIdempotencySweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bang-go/micro/store/redisx"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisIdempotencyPrefix    = "ginx:callback:"
+	redisIdempotencyPending   = "pending"
+	redisIdempotencyCompleted = "completed"
+)
+
+// releaseScript drops a lease without touching a completed marker that was
+// written after the caller last looked.
+var releaseScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+type redisIdempotencyStore struct {
+	client    *redis.Client
+	retention time.Duration
+}
+
+// NewRedisIdempotencyStore returns a store shared by every replica using the
+// same Redis. Keys live under "ginx:callback:"; completed keys are kept for
+// retention, 24h by default. The client's lifecycle stays with the caller.
+func NewRedisIdempotencyStore(client redisx.Client, retention time.Duration) (IdempotencyStore, error) {
+	if client == nil || client.Redis() == nil {
+		return nil, ErrRedisClientRequired
+	}
+	if retention <= 0 {
+		retention = defaultIdempotencyRetention
+	}
+	return &redisIdempotencyStore{client: client.Redis(), retention: retention}, nil
+}
+
+func (s *redisIdempotencyStore) Acquire(ctx context.Context, key string, lease time.Duration) (IdempotencyState, error) {
+	if err := validateContext(ctx); err != nil {
+		return 0, err
+	}
+	if key == "" {
+		return 0, ErrCallbackKeyRequired
+	}
+
+	key = redisIdempotencyPrefix + key
+	acquired, err := s.client.SetNX(ctx, key, redisIdempotencyPending, lease).Result()
+	if err != nil {
+		return 0, err
+	}
+	if acquired {
+		return IdempotencyAcquired, nil
+	}
+	value, err := s.client.Get(ctx, key).Result()
+	switch {
+	case errors.Is(err, redis.Nil):
+		// The lease expired between the two calls; let the provider retry.
+		return IdempotencyInProgress, nil
+	case err != nil:
+		return 0, err
+	case value == redisIdempotencyCompleted:
+		return IdempotencyCompleted, nil
+	default:
+		return IdempotencyInProgress, nil
+	}
+}
+
+func (s *redisIdempotencyStore) Complete(ctx context.Context, key string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if key == "" {
+		return ErrCallbackKeyRequired
+	}
+	return s.client.Set(ctx, redisIdempotencyPrefix+key, redisIdempotencyCompleted, s.retention).Err()
+}
+
+func (s *redisIdempotencyStore) Release(ctx context.Context, key string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if key == "" {
+		return ErrCallbackKeyRequired
+	}
+	return releaseScript.Run(ctx, s.client, []string{redisIdempotencyPrefix + key}, redisIdempotencyPending).Err()
+}
@@ -0,0 +1,159 @@
+package ginx_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/store/redisx"
+	"github.com/bang-go/micro/transport/ginx"
+	"github.com/bang-go/util"
+)
+
+// fakeRedis understands just enough RESP2 for the idempotency store: SET with
+// NX, GET, and the compare-and-delete release script.
+type fakeRedis struct {
+	mu    sync.Mutex
+	store map[string]string
+}
+
+func (s *fakeRedis) dialer(context.Context, string, string) (net.Conn, error) {
+	client, server := net.Pipe()
+	go s.serve(server)
+	return client, nil
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		cmd, err := readRedisCommand(reader)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		var reply string
+		switch strings.ToUpper(cmd[0]) {
+		case "PING":
+			reply = "+PONG\r\n"
+		case "SET":
+			reply = "+OK\r\n"
+			if _, ok := s.store[cmd[1]]; ok && slices.ContainsFunc(cmd[3:], func(arg string) bool { return strings.EqualFold(arg, "nx") }) {
+				reply = "$-1\r\n"
+			} else {
+				s.store[cmd[1]] = cmd[2]
+			}
+		case "GET":
+			if value, ok := s.store[cmd[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case "EVALSHA":
+			reply = "-NOSCRIPT No matching script.\r\n"
+		case "EVAL":
+			reply = ":0\r\n"
+			if value, ok := s.store[cmd[3]]; ok && value == cmd[4] {
+				delete(s.store, cmd[3])
+				reply = ":1\r\n"
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		s.mu.Unlock()
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readRedisCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	cmd := make([]string, 0, count)
+	for range count {
+		sizeLine, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(sizeLine, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		cmd = append(cmd, string(buf[:size]))
+	}
+	return cmd, nil
+}
+
+func TestRedisIdempotencyStore(t *testing.T) {
+	server := &fakeRedis{store: map[string]string{}}
+	client, err := redisx.Open(context.Background(), &redisx.Config{
+		Addr:            "fake:6379",
+		Protocol:        2,
+		DisableIdentity: util.Ptr(true),
+		Dialer:          server.dialer,
+		DisableMetrics:  true,
+	})
+	if err != nil {
+		t.Fatalf("redisx.Open() error = %v", err)
+	}
+	defer client.Close()
+
+	if _, err := ginx.NewRedisIdempotencyStore(nil, 0); !errors.Is(err, ginx.ErrRedisClientRequired) {
+		t.Fatalf("NewRedisIdempotencyStore(nil) error = %v", err)
+	}
+	store, err := ginx.NewRedisIdempotencyStore(client, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRedisIdempotencyStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	acquire := func(key string, want ginx.IdempotencyState) {
+		t.Helper()
+		if state, err := store.Acquire(ctx, key, time.Minute); err != nil || state != want {
+			t.Fatalf("Acquire(%s) = %v, %v, want %v", key, state, err, want)
+		}
+	}
+
+	acquire("a", ginx.IdempotencyAcquired)
+	acquire("a", ginx.IdempotencyInProgress)
+	if err := store.Release(ctx, "a"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	acquire("a", ginx.IdempotencyAcquired)
+	if err := store.Complete(ctx, "a"); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	acquire("a", ginx.IdempotencyCompleted)
+
+	// A late Release must not reopen a completed key.
+	if err := store.Release(ctx, "a"); err != nil {
+		t.Fatalf("Release() after Complete error = %v", err)
+	}
+	acquire("a", ginx.IdempotencyCompleted)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if _, ok := server.store["ginx:callback:a"]; !ok {
+		t.Fatalf("keys = %v, want ginx:callback: prefix", server.store)
+	}
+}
@@ -0,0 +1,80 @@
+package ginx_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bang-go/micro/transport/ginx"
+	"github.com/bang-go/micro/transport/ginx/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestServerLimitRejectsAbusivePayloads(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	server := ginx.New(&ginx.ServerConfig{
+		Mode:              gin.TestMode,
+		MetricsRegisterer: reg,
+		Limit: &middleware.LimitConfig{
+			MaxBodyBytes:       256,
+			MaxJSONDepth:       3,
+			MaxJSONFields:      4,
+			MaxJSONArrayLength: 5,
+		},
+	})
+	router := server.GinEngine()
+	router.POST("/orders", func(c *gin.Context) {
+		var body map[string]any
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.String(http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+	router.POST("/upload", func(c *gin.Context) {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.String(http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		c.String(http.StatusOK, "%d", len(data))
+	})
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{name: "accepted", path: "/orders", contentType: "application/json", body: `{"id":1,"items":[1,2,3],"meta":{"a":"b"}}`, want: http.StatusOK},
+		{name: "too deep", path: "/orders", contentType: "application/json", body: `{"a":{"b":{"c":{}}}}`, want: http.StatusBadRequest},
+		{name: "too many fields", path: "/orders", contentType: "application/json; charset=utf-8", body: `{"a":1,"b":2,"c":{"d":3,"e":4}}`, want: http.StatusBadRequest},
+		{name: "long array", path: "/orders", contentType: "application/json", body: `{"items":[1,2,3,4,5,6]}`, want: http.StatusBadRequest},
+		{name: "string values are not fields", path: "/orders", contentType: "application/json", body: `{"a":"x","b":"y","c":"z","d":"w"}`, want: http.StatusOK},
+		{name: "too large", path: "/orders", contentType: "application/json", body: `{"a":"` + strings.Repeat("x", 300) + `"}`, want: http.StatusRequestEntityTooLarge},
+		{name: "non-json passes structure checks", path: "/upload", contentType: "text/plain", body: `{"a":{"b":{"c":{}}}}`, want: http.StatusOK},
+		{name: "non-json size", path: "/upload", contentType: "text/plain", body: strings.Repeat("x", 300), want: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.name == "non-json size" {
+				// Force the streaming path instead of the Content-Length precheck.
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	assertCounterValue(t, reg, "ginx_server_limit_rejections_total", map[string]string{"route": "/orders", "reason": "json_depth"}, 1)
+	assertCounterValue(t, reg, "ginx_server_limit_rejections_total", map[string]string{"route": "/orders", "reason": "body_size"}, 1)
+}
@@ -0,0 +1,376 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bang-go/micro/store/cache"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultCachePrefix       = "ginx:cache:"
+	defaultCacheMaxBodyBytes = 1 << 20
+)
+
+var ErrCacheStoreRequired = errors.New("ginx: cache store is required")
+
+var (
+	defaultCacheOnce     sync.Once
+	defaultCacheRequests *prometheus.CounterVec
+)
+
+// CacheConfig configures ResponseCache. Responses are stored for the
+// s-maxage or max-age the handler sets in Cache-Control, or for TTL when it
+// sets neither.
+type CacheConfig struct {
+	// Store holds the responses, e.g. cache.NewMemory or cache.NewRedis.
+	Store cache.Store
+	// Prefix is prepended to every key, default "ginx:cache:".
+	Prefix string
+	// TTL applies to responses without max-age or s-maxage; zero caches only
+	// responses that set one.
+	TTL time.Duration
+	// Vary lists the request headers that select different representations,
+	// e.g. Accept-Language. Responses whose Vary names any other header are
+	// not stored.
+	Vary []string
+	// MaxBodyBytes bounds a stored response body, default 1 MiB.
+	MaxBodyBytes int64
+	SkipPaths    []string
+
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+}
+
+// ResponseCache caches GET responses per route, path, query and Vary
+// headers. Each route has a version in the store, so Invalidate drops every
+// cached path of a route with a single write.
+type ResponseCache struct {
+	config   CacheConfig
+	vary     map[string]struct{}
+	skip     map[string]struct{}
+	requests *prometheus.CounterVec
+	now      func() time.Time
+}
+
+type cachedResponse struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+func newCacheRequests() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ginx_server_cache_requests_total",
+			Help: "Total number of cacheable Gin HTTP requests by cache result.",
+		},
+		[]string{"route", "result"},
+	)
+}
+
+func cacheRequests(conf *CacheConfig) *prometheus.CounterVec {
+	if conf.DisableMetrics {
+		return nil
+	}
+	if conf.MetricsRegisterer != nil {
+		counter := newCacheRequests()
+		mustRegisterCollector(conf.MetricsRegisterer, &counter, counter)
+		return counter
+	}
+	defaultCacheOnce.Do(func() {
+		defaultCacheRequests = newCacheRequests()
+		mustRegisterCollector(prometheus.DefaultRegisterer, &defaultCacheRequests, defaultCacheRequests)
+	})
+	return defaultCacheRequests
+}
+
+func NewResponseCache(conf *CacheConfig) (*ResponseCache, error) {
+	if conf == nil || conf.Store == nil {
+		return nil, ErrCacheStoreRequired
+	}
+	config := *conf
+	if config.Prefix == "" {
+		config.Prefix = defaultCachePrefix
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaultCacheMaxBodyBytes
+	}
+	vary := make(map[string]struct{}, len(config.Vary))
+	for _, name := range config.Vary {
+		if name = strings.TrimSpace(name); name != "" {
+			vary[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	}
+	return &ResponseCache{
+		config:   config,
+		vary:     vary,
+		skip:     newSkipPathSet(config.SkipPaths),
+		requests: cacheRequests(&config),
+		now:      time.Now,
+	}, nil
+}
+
+// Middleware serves cached GET responses and stores cacheable ones. Store
+// errors are added to the gin context and the request falls through to the
+// handler.
+func (rc *ResponseCache) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if c.Request.Method != http.MethodGet || route == "" || shouldSkip(rc.skip, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		ctx := c.Request.Context()
+
+		key, err := rc.entryKey(ctx, c, route)
+		if err != nil {
+			rc.observe(route, "error")
+			_ = c.Error(err)
+			c.Next()
+			return
+		}
+		if entry, ok := rc.lookup(ctx, c, key); ok {
+			rc.observe(route, "hit")
+			rc.serve(c, entry)
+			return
+		}
+		rc.observe(route, "miss")
+
+		writer := &cacheWriter{ResponseWriter: c.Writer, limit: rc.config.MaxBodyBytes}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.uncacheable || writer.Status() != http.StatusOK {
+			return
+		}
+		ttl, ok := rc.responseTTL(writer.Header(), c.Request.Header.Get("Authorization") != "")
+		if !ok {
+			return
+		}
+		entry, err := json.Marshal(cachedResponse{
+			Status:   writer.Status(),
+			Header:   storedHeader(writer.Header()),
+			Body:     writer.body,
+			StoredAt: rc.now().UTC(),
+		})
+		if err == nil {
+			err = rc.config.Store.Set(ctx, key, entry, ttl)
+		}
+		if err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// Invalidate drops every cached response of the given route templates, e.g.
+// "/users/:id".
+func (rc *ResponseCache) Invalidate(ctx context.Context, routes ...string) error {
+	version := []byte(strconv.FormatInt(rc.now().UnixNano(), 36))
+	var errs []error
+	for _, route := range routes {
+		if err := rc.config.Store.Set(ctx, rc.versionKey(route), version, 0); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// InvalidateMiddleware invalidates routes, or the request's own route when
+// none are given, after a write request (anything but GET, HEAD or OPTIONS)
+// completes with a status below 400.
+func (rc *ResponseCache) InvalidateMiddleware(routes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return
+		}
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		targets := routes
+		if len(targets) == 0 {
+			if route := c.FullPath(); route != "" {
+				targets = []string{route}
+			}
+		}
+		if err := rc.Invalidate(c.Request.Context(), targets...); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+func (rc *ResponseCache) entryKey(ctx context.Context, c *gin.Context, route string) (string, error) {
+	version, err := rc.config.Store.Get(ctx, rc.versionKey(route))
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, part := range []string{string(version), c.Request.URL.Path, c.Request.URL.Query().Encode()} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	for _, name := range rc.config.Vary {
+		h.Write([]byte(strings.Join(c.Request.Header.Values(name), ",")))
+		h.Write([]byte{0})
+	}
+	return rc.config.Prefix + "resp:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (rc *ResponseCache) versionKey(route string) string {
+	return rc.config.Prefix + "ver:" + route
+}
+
+func (rc *ResponseCache) lookup(ctx context.Context, c *gin.Context, key string) (*cachedResponse, bool) {
+	data, err := rc.config.Store.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFound) {
+			_ = c.Error(err)
+		}
+		return nil, false
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+func (rc *ResponseCache) serve(c *gin.Context, entry *cachedResponse) {
+	header := c.Writer.Header()
+	for key, values := range entry.Header {
+		header[key] = values
+	}
+	age := rc.now().Sub(entry.StoredAt) / time.Second
+	header.Set("Age", strconv.FormatInt(int64(max(age, 0)), 10))
+	c.Writer.WriteHeader(entry.Status)
+	_, _ = c.Writer.Write(entry.Body)
+	c.Abort()
+}
+
+// responseTTL applies the shared-cache rules of RFC 9111: no-store, private
+// and no-cache responses are not stored, and responses to authorized
+// requests only when marked public or given an s-maxage.
+func (rc *ResponseCache) responseTTL(header http.Header, authorized bool) (time.Duration, bool) {
+	if header.Get("Set-Cookie") != "" {
+		return 0, false
+	}
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := rc.vary[http.CanonicalHeaderKey(name)]; !ok {
+				return 0, false
+			}
+		}
+	}
+
+	directives := parseCacheControl(header.Values("Cache-Control"))
+	for _, name := range []string{"no-store", "private", "no-cache"} {
+		if _, ok := directives[name]; ok {
+			return 0, false
+		}
+	}
+	sMaxAge, hasSMaxAge := directives["s-maxage"]
+	if _, public := directives["public"]; authorized && !public && !hasSMaxAge {
+		return 0, false
+	}
+
+	ttl := rc.config.TTL
+	if maxAge, ok := directives["max-age"]; ok {
+		ttl = parseSeconds(maxAge)
+	}
+	if hasSMaxAge {
+		ttl = parseSeconds(sMaxAge)
+	}
+	return ttl, ttl > 0
+}
+
+func (rc *ResponseCache) observe(route, result string) {
+	if rc.requests != nil {
+		rc.requests.WithLabelValues(route, result).Inc()
+	}
+}
+
+func parseCacheControl(values []string) map[string]string {
+	directives := make(map[string]string)
+	for _, value := range values {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				directives[name] = strings.Trim(strings.TrimSpace(arg), `"`)
+			}
+		}
+	}
+	return directives
+}
+
+func parseSeconds(value string) time.Duration {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// storedHeader drops headers that describe the original connection or
+// exchange rather than the response.
+func storedHeader(header http.Header) http.Header {
+	stored := header.Clone()
+	for _, key := range []string{"Age", "Connection", "Content-Length", "Date", "Keep-Alive", "Transfer-Encoding"} {
+		stored.Del(key)
+	}
+	return stored
+}
+
+// cacheWriter copies the body as it is written. Flushed (streamed) and
+// oversized responses are marked uncacheable.
+type cacheWriter struct {
+	gin.ResponseWriter
+	body        []byte
+	limit       int64
+	uncacheable bool
+}
+
+func (w *cacheWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cacheWriter) Flush() {
+	w.uncacheable = true
+	w.ResponseWriter.Flush()
+}
+
+func (w *cacheWriter) capture(data []byte) {
+	if w.uncacheable {
+		return
+	}
+	if int64(len(w.body)+len(data)) > w.limit {
+		w.uncacheable = true
+		w.body = nil
+		return
+	}
+	w.body = append(w.body, data...)
+}
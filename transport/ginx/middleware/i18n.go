@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/bang-go/micro/pkg/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// LocalizerKey is the gin context key holding the request's *i18n.Localizer.
+const LocalizerKey = "ginx.localizer"
+
+// I18nConfig negotiates the response language of each request.
+type I18nConfig struct {
+	// Bundle holds the translations; without it requests pass through.
+	Bundle *i18n.Bundle
+	// QueryParam, e.g. "lang", lets a query parameter take precedence over
+	// Accept-Language. Empty only honours the header.
+	QueryParam string
+}
+
+// I18nMiddleware stores the negotiated localizer in the gin context and the
+// request context, and sets Content-Language and Vary on the response.
+func I18nMiddleware(conf *I18nConfig) gin.HandlerFunc {
+	if conf == nil || conf.Bundle == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	config := *conf
+
+	return func(c *gin.Context) {
+		preferred := make([]string, 0, 2)
+		if config.QueryParam != "" {
+			if lang := c.Query(config.QueryParam); lang != "" {
+				preferred = append(preferred, lang)
+			}
+		}
+		preferred = append(preferred, c.GetHeader("Accept-Language"))
+
+		localizer := config.Bundle.Localizer(preferred...)
+		c.Set(LocalizerKey, localizer)
+		c.Request = c.Request.WithContext(i18n.NewContext(c.Request.Context(), localizer))
+		c.Header("Content-Language", localizer.Language())
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LimitConfig bounds request bodies before they reach handlers. JSON bodies
+// are scanned token by token, so rejecting a pathological payload costs far
+// less than binding it. Zero disables the corresponding check.
+type LimitConfig struct {
+	MaxBodyBytes int64
+	// MaxJSONDepth bounds nested objects and arrays.
+	MaxJSONDepth int
+	// MaxJSONFields bounds the total number of object keys in the document.
+	MaxJSONFields int
+	// MaxJSONArrayLength bounds the elements of any single array.
+	MaxJSONArrayLength int
+	SkipPaths          []string
+
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+}
+
+var (
+	errJSONTooDeep        = errors.New("json nesting too deep")
+	errJSONTooManyFields  = errors.New("json has too many fields")
+	errJSONArrayTooLong   = errors.New("json array too long")
+	defaultLimitOnce      sync.Once
+	defaultLimitRejection *prometheus.CounterVec
+)
+
+func newLimitRejections() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ginx_server_limit_rejections_total",
+			Help: "Total number of Gin HTTP requests rejected by payload limits.",
+		},
+		[]string{"route", "reason"},
+	)
+}
+
+func limitRejections(conf *LimitConfig) *prometheus.CounterVec {
+	if conf.DisableMetrics {
+		return nil
+	}
+	if conf.MetricsRegisterer != nil {
+		counter := newLimitRejections()
+		mustRegisterCollector(conf.MetricsRegisterer, &counter, counter)
+		return counter
+	}
+	defaultLimitOnce.Do(func() {
+		defaultLimitRejection = newLimitRejections()
+		mustRegisterCollector(prometheus.DefaultRegisterer, &defaultLimitRejection, defaultLimitRejection)
+	})
+	return defaultLimitRejection
+}
+
+func LimitMiddleware(conf *LimitConfig) gin.HandlerFunc {
+	if conf == nil {
+		conf = &LimitConfig{}
+	}
+	config := *conf
+	skip := newSkipPathSet(config.SkipPaths)
+	rejections := limitRejections(&config)
+	checkJSON := config.MaxJSONDepth > 0 || config.MaxJSONFields > 0 || config.MaxJSONArrayLength > 0
+
+	reject := func(c *gin.Context, status int, reason string) {
+		if rejections != nil {
+			rejections.WithLabelValues(routeLabel(c), reason).Inc()
+		}
+		c.AbortWithStatus(status)
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody || shouldSkip(skip, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		if config.MaxBodyBytes > 0 {
+			if c.Request.ContentLength > config.MaxBodyBytes {
+				reject(c, http.StatusRequestEntityTooLarge, "body_size")
+				return
+			}
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxBodyBytes)
+		}
+		if !checkJSON || !isJSONRequest(c.Request) {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				reject(c, http.StatusRequestEntityTooLarge, "body_size")
+				return
+			}
+			_ = c.Error(err)
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		_ = c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		switch err := scanJSON(body, &config); {
+		case errors.Is(err, errJSONTooDeep):
+			reject(c, http.StatusBadRequest, "json_depth")
+			return
+		case errors.Is(err, errJSONTooManyFields):
+			reject(c, http.StatusBadRequest, "json_fields")
+			return
+		case errors.Is(err, errJSONArrayTooLong):
+			reject(c, http.StatusBadRequest, "json_array")
+			return
+		}
+		// Malformed JSON is left for the handler's binder to report.
+		c.Next()
+	}
+}
+
+func isJSONRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+type jsonFrame struct {
+	object   bool
+	elements int
+}
+
+func scanJSON(body []byte, conf *LimitConfig) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	var stack []jsonFrame
+	fields := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		if len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.object {
+				// Tokens inside an object alternate key, value; count keys only.
+				if _, isKey := token.(string); isKey && top.elements%2 == 0 {
+					fields++
+					if conf.MaxJSONFields > 0 && fields > conf.MaxJSONFields {
+						return errJSONTooManyFields
+					}
+				}
+			}
+			if delim, ok := token.(json.Delim); !ok || delim == '{' || delim == '[' {
+				top.elements++
+				if !top.object && conf.MaxJSONArrayLength > 0 && top.elements > conf.MaxJSONArrayLength {
+					return errJSONArrayTooLong
+				}
+			}
+		}
+
+		delim, ok := token.(json.Delim)
+		if !ok {
+			continue
+		}
+		switch delim {
+		case '{', '[':
+			stack = append(stack, jsonFrame{object: delim == '{'})
+			if conf.MaxJSONDepth > 0 && len(stack) > conf.MaxJSONDepth {
+				return errJSONTooDeep
+			}
+		case '}', ']':
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package ginx
+
+import (
+	"fmt"
+	"strconv"
+
+	microerrors "github.com/bang-go/micro/pkg/errors"
+	"github.com/bang-go/micro/pkg/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// BindPagination parses the page, page_size, page_token, order_by and filter
+// query parameters. Errors are INVALID_ARGUMENT and can go straight to
+// WriteError.
+func BindPagination(c *gin.Context, p *pagination.Paginator) (*pagination.Request, error) {
+	page, err := queryInt(c, pagination.FieldPage)
+	if err != nil {
+		return nil, err
+	}
+	size, err := queryInt(c, pagination.FieldPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return p.Parse(pagination.Query{
+		Page:      page,
+		PageSize:  size,
+		PageToken: c.Query(pagination.FieldPageToken),
+		OrderBy:   c.Query(pagination.FieldOrderBy),
+		Filter:    c.Query(pagination.FieldFilter),
+	})
+}
+
+func queryInt(c *gin.Context, key string) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		description := fmt.Sprintf("%s must be an integer", key)
+		return 0, microerrors.Wrap(err, microerrors.CodeInvalidArgument, description).
+			WithFields(microerrors.FieldViolation{Field: key, Description: description})
+	}
+	return value, nil
+}
@@ -0,0 +1,62 @@
+package ginx_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bang-go/micro/pkg/pagination"
+	"github.com/bang-go/micro/transport/ginx"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	p, err := pagination.New(&pagination.Config{
+		SortFields:   []string{"created_at", "id"},
+		FilterFields: map[string]pagination.FieldType{"status": pagination.TypeString},
+	})
+	if err != nil {
+		t.Fatalf("pagination.New() error = %v", err)
+	}
+	router := gin.New()
+	router.GET("/items", func(c *gin.Context) {
+		req, err := ginx.BindPagination(c, p)
+		if err != nil {
+			ginx.WriteError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"offset": req.Offset(), "limit": req.Limit(), "order_by": req.OrderBy(), "filters": len(req.Filter)})
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, `/items?page=3&page_size=5&order_by=-created_at&filter=status%3D%22active%22`, nil))
+	var body struct {
+		Offset  int    `json:"offset"`
+		Limit   int    `json:"limit"`
+		OrderBy string `json:"order_by"`
+		Filters int    `json:"filters"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("response = %d %s", rec.Code, rec.Body)
+	}
+	if body.Offset != 10 || body.Limit != 5 || body.OrderBy != "created_at DESC" || body.Filters != 1 {
+		t.Fatalf("request = %+v", body)
+	}
+
+	for target, field := range map[string]string{
+		"/items?page_size=ten":     pagination.FieldPageSize,
+		"/items?order_by=password": pagination.FieldOrderBy,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var resp ginx.ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: decode error: %v", target, err)
+		}
+		if rec.Code != http.StatusBadRequest || len(resp.Fields) != 1 || resp.Fields[0].Field != field {
+			t.Fatalf("%s: response = %d %+v", target, rec.Code, resp)
+		}
+	}
+}
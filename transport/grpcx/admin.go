@@ -0,0 +1,52 @@
+package grpcx
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/admin"
+	"google.golang.org/grpc/reflection"
+)
+
+var (
+	healthMethods = []string{
+		"/grpc.health.v1.Health/Check",
+		"/grpc.health.v1.Health/Watch",
+	}
+	reflectionMethods = []string{
+		"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+		"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+	}
+	channelzMethods = []string{
+		"/grpc.channelz.v1.Channelz/GetTopChannels",
+		"/grpc.channelz.v1.Channelz/GetServers",
+		"/grpc.channelz.v1.Channelz/GetServer",
+		"/grpc.channelz.v1.Channelz/GetServerSockets",
+		"/grpc.channelz.v1.Channelz/GetChannel",
+		"/grpc.channelz.v1.Channelz/GetSubchannel",
+		"/grpc.channelz.v1.Channelz/GetSocket",
+	}
+)
+
+// observabilitySkipMethods lists the built-in services kept out of metrics,
+// traces and logs, followed by the configured ones.
+func observabilitySkipMethods(conf *ServerConfig) []string {
+	skipMethods := append([]string(nil), healthMethods...)
+	if conf.EnableReflection {
+		skipMethods = append(skipMethods, reflectionMethods...)
+	}
+	if conf.EnableChannelz {
+		skipMethods = append(skipMethods, channelzMethods...)
+	}
+	return append(skipMethods, conf.ObservabilitySkipMethods...)
+}
+
+// registerDebugServices registers reflection and the admin services. The
+// returned cleanup releases admin handlers once the server has stopped.
+func registerDebugServices(grpcServer *grpc.Server, conf *ServerConfig) (func(), error) {
+	if conf.EnableReflection {
+		reflection.Register(grpcServer)
+	}
+	if !conf.EnableChannelz {
+		return func() {}, nil
+	}
+	return admin.Register(grpcServer)
+}
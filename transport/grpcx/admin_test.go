@@ -0,0 +1,115 @@
+package grpcx_test
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/grpcx"
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func startBufServer(t *testing.T, conf *grpcx.ServerConfig) *bufconn.Listener {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	conf.Listener = listener
+	conf.DisableMetrics = true
+	server := grpcx.NewServer(conf)
+	serveDone := make(chan error, 1)
+	go func() {
+		serveDone <- server.Start(context.Background(), func(*grpc.Server) {})
+	}()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+		if err := <-serveDone; err != nil {
+			t.Errorf("Start() error = %v", err)
+		}
+	})
+	return listener
+}
+
+func startDebugServer(t *testing.T, conf *grpcx.ServerConfig) *grpc.ClientConn {
+	t.Helper()
+
+	listener := startBufServer(t, conf)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func listServices(ctx context.Context, conn *grpc.ClientConn) ([]string, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	return services, nil
+}
+
+func TestServerReflectionAndChannelz(t *testing.T) {
+	conn := startDebugServer(t, &grpcx.ServerConfig{EnableReflection: true, EnableChannelz: true})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	services, err := listServices(ctx, conn)
+	if err != nil {
+		t.Fatalf("list services: %v", err)
+	}
+	for _, want := range []string{"grpc.health.v1.Health", "grpc.reflection.v1.ServerReflection", "grpc.channelz.v1.Channelz"} {
+		if !slices.Contains(services, want) {
+			t.Fatalf("services = %v, missing %s", services, want)
+		}
+	}
+
+	servers, err := channelzpb.NewChannelzClient(conn).GetServers(ctx, &channelzpb.GetServersRequest{})
+	if err != nil {
+		t.Fatalf("GetServers() error = %v", err)
+	}
+	if len(servers.GetServer()) == 0 {
+		t.Fatal("channelz reported no servers")
+	}
+}
+
+func TestServerDebugServicesDisabledByDefault(t *testing.T) {
+	conn := startDebugServer(t, &grpcx.ServerConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := listServices(ctx, conn); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("reflection error = %v, want Unimplemented", err)
+	}
+	if _, err := channelzpb.NewChannelzClient(conn).GetServers(ctx, &channelzpb.GetServersRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("channelz error = %v, want Unimplemented", err)
+	}
+}
@@ -0,0 +1,105 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bang-go/micro/contrib/auth/jwtx"
+	serverinterceptor "github.com/bang-go/micro/transport/grpcx/server_interceptor"
+	"google.golang.org/grpc/credentials"
+)
+
+var ErrCredentialsRequired = errors.New("grpcx: per-rpc credentials are empty")
+
+// TokenCredentials sends "authorization: Bearer <token>" with every call.
+type TokenCredentials struct {
+	// Token returns the token for a call, e.g. StaticToken or JWTToken.
+	Token func(ctx context.Context) (string, error)
+	// AllowInsecure permits sending the token without TLS, e.g. inside a mesh.
+	AllowInsecure bool
+}
+
+func (c TokenCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	if c.Token == nil {
+		return nil, ErrCredentialsRequired
+	}
+	token, err := c.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, ErrCredentialsRequired
+	}
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+func (c TokenCredentials) RequireTransportSecurity() bool {
+	return !c.AllowInsecure
+}
+
+// APIKeyCredentials sends Key in the Header metadata, "x-api-key" by default.
+type APIKeyCredentials struct {
+	Header        string
+	Key           string
+	AllowInsecure bool
+}
+
+func (c APIKeyCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	if c.Key == "" {
+		return nil, ErrCredentialsRequired
+	}
+	header := strings.ToLower(strings.TrimSpace(c.Header))
+	if header == "" {
+		header = serverinterceptor.DefaultAPIKeyHeader
+	}
+	return map[string]string{header: c.Key}, nil
+}
+
+func (c APIKeyCredentials) RequireTransportSecurity() bool {
+	return !c.AllowInsecure
+}
+
+var (
+	_ credentials.PerRPCCredentials = TokenCredentials{}
+	_ credentials.PerRPCCredentials = APIKeyCredentials{}
+)
+
+func StaticToken(token string) func(context.Context) (string, error) {
+	return func(context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// JWTToken issues a token for payload with j and reuses it until less than a
+// tenth of its lifetime is left.
+func JWTToken[T any](j *jwtx.JWT[T], payload T, options ...jwtx.IssueOption) func(context.Context) (string, error) {
+	var (
+		mu        sync.Mutex
+		token     string
+		refreshAt time.Time
+	)
+	return func(context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if token != "" && time.Now().Before(refreshAt) {
+			return token, nil
+		}
+		issued, err := j.Generate(payload, options...)
+		if err != nil {
+			return "", err
+		}
+		claims, err := j.Parse(issued)
+		if err != nil {
+			return "", err
+		}
+		token = issued
+		refreshAt = claims.ExpiresAt.Time
+		if claims.IssuedAt != nil {
+			refreshAt = refreshAt.Add(-claims.ExpiresAt.Sub(claims.IssuedAt.Time) / 10)
+		}
+		return token, nil
+	}
+}
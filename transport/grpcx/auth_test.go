@@ -0,0 +1,78 @@
+package grpcx_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/contrib/auth/jwtx"
+	"github.com/bang-go/micro/transport/grpcx"
+	serverinterceptor "github.com/bang-go/micro/transport/grpcx/server_interceptor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerAuthWithClientCredentials(t *testing.T) {
+	j := jwtx.MustNew[string](&jwtx.Config{SecretKey: "secret"})
+	listener := startBufServer(t, &grpcx.ServerConfig{
+		EnableReflection: true,
+		Auth:             &serverinterceptor.AuthConfig{Authenticate: serverinterceptor.JWTAuth(j)},
+	})
+	dial := func(creds *grpcx.TokenCredentials) (*grpc.ClientConn, error) {
+		conf := &grpcx.ClientConfig{Addr: "passthrough:///bufnet", DisableMetrics: true}
+		if creds != nil {
+			conf.PerRPCCredentials = creds
+		}
+		client := grpcx.NewClient(conf)
+		client.AddDialOptions(grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}))
+		t.Cleanup(client.Close)
+		return client.Dial()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	anonymous, err := dial(nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	if _, err := grpc_health_v1.NewHealthClient(anonymous).Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.WaitForReady(true)); err != nil {
+		t.Fatalf("health Check() without token error = %v", err)
+	}
+	if _, err := listServices(ctx, anonymous); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("listServices() without token error = %v", err)
+	}
+
+	authorized, err := dial(&grpcx.TokenCredentials{Token: grpcx.JWTToken(j, "svc-a"), AllowInsecure: true})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	if _, err := listServices(ctx, authorized); err != nil {
+		t.Fatalf("listServices() with token error = %v", err)
+	}
+
+	// Without AllowInsecure the token is never sent over plaintext.
+	if _, err := dial(&grpcx.TokenCredentials{Token: grpcx.StaticToken("x")}); err == nil {
+		t.Fatal("Dial() accepted credentials requiring TLS over plaintext")
+	}
+}
+
+func TestJWTTokenReusesUntilRefresh(t *testing.T) {
+	j := jwtx.MustNew[string](&jwtx.Config{SecretKey: "secret", Expire: time.Hour})
+	source := grpcx.JWTToken(j, "svc-a", jwtx.WithJWTID("fixed"))
+	first, err := source(context.Background())
+	if err != nil {
+		t.Fatalf("token error = %v", err)
+	}
+	second, _ := source(context.Background())
+	if first != second {
+		t.Fatal("JWTToken() reissued a fresh token")
+	}
+	if payload, err := j.ParsePayload(first); err != nil || payload != "svc-a" {
+		t.Fatalf("ParsePayload() = %q, %v", payload, err)
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"crypto/tls"
 	"errors"
 	"runtime/debug"
+	"slices"
 	"sync"
 
 	"github.com/bang-go/micro/telemetry/logger"
 	client_interceptor "github.com/bang-go/micro/transport/grpcx/client_interceptor"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
@@ -27,6 +29,7 @@ type Client interface {
 	Dial() (*grpc.ClientConn, error)
 	DialContext(context.Context) (*grpc.ClientConn, error)
 	DialWithCall(ClientCallFunc) (any, error)
+	DialPool() (*ConnPool, error)
 	Conn() *grpc.ClientConn
 	Close()
 }
@@ -43,6 +46,40 @@ type ClientConfig struct {
 	Logger               *logger.Logger
 	EnableLogger         bool
 	//TraceFilter grpctrace.Filter
+
+	// Propagators overrides the global propagator, e.g. to accept and emit B3
+	// next to W3C metadata; see trace.NewPropagator.
+	Propagators propagation.TextMapPropagator
+
+	// TLS 以证书文件或 PEM 声明 TLS/mTLS，设置后无需再开 Secure；不能与 TLSConfig 同时设置。
+	TLS *ClientTLSConfig
+
+	// Discovery 通过注册中心解析服务地址，设置后可不填 Addr。
+	Discovery *DiscoveryConfig
+	// LoadBalancing 负载均衡策略：pick_first（默认）、round_robin、least_conn。
+	LoadBalancing string
+	// MethodConfigs 按方法配置超时、重试与对冲，转换为 gRPC service config。
+	MethodConfigs []MethodConfig
+	// CircuitBreaker 按方法熔断，错误率超限时直接拒绝调用，默认关闭。
+	CircuitBreaker *client_interceptor.BreakerConfig
+	// Transport 以类型化字段设置报文大小与 keepalive，并带默认值和校验；不能与 KeepaliveParams 同时设置。
+	Transport *ClientTransportConfig
+
+	// PerRPCCredentials 为每次调用附加凭证，如 TokenCredentials、APIKeyCredentials。
+	PerRPCCredentials credentials.PerRPCCredentials
+
+	// Addrs 无注册中心时直接配置多个地址，连接失败的地址自动跳过；不能与 Addr、Discovery 同时设置。
+	Addrs []string
+	// HealthCheck 开启客户端健康检查（grpc.health.v1 Watch），只向 SERVING 的地址发请求；未设置 LoadBalancing 时使用 round_robin，不支持 pick_first。
+	HealthCheck bool
+	// HealthCheckServiceName 健康检查的服务名，默认 "" 表示整个服务端。
+	HealthCheckServiceName string
+
+	// Audit 开启请求/响应报文审计日志，按字段脱敏并限制报文大小，默认关闭。
+	Audit *client_interceptor.AuditConfig
+
+	// PoolSize DialPool 建立的连接数，调用按轮询分散到各连接，避免单条 HTTP/2 连接成为瓶颈；默认 1。
+	PoolSize int
 }
 
 type ClientCallFunc func(*grpc.ClientConn) (any, error)
@@ -50,6 +87,7 @@ type ClientCallFunc func(*grpc.ClientConn) (any, error)
 type ClientEntity struct {
 	*ClientConfig
 	conn               *grpc.ClientConn
+	pool               *ConnPool
 	dialOptions        []grpc.DialOption
 	streamInterceptors []grpc.StreamClientInterceptor
 	unaryInterceptors  []grpc.UnaryClientInterceptor
@@ -87,6 +125,17 @@ func NewClient(conf *ClientConfig) Client {
 	if conf.EnableLogger {
 		unaryInterceptors = append(unaryInterceptors, client_interceptor.UnaryClientLoggerInterceptor(conf.Logger))
 	}
+	// 4. Circuit Breaker
+	var breaker *client_interceptor.Breaker
+	if conf.CircuitBreaker != nil {
+		breaker = client_interceptor.NewBreaker(breakerConfig(conf))
+		unaryInterceptors = append(unaryInterceptors, client_interceptor.UnaryClientBreakerInterceptor(breaker))
+	}
+	// 5. Payload Audit
+	audit := clientAuditConfig(conf)
+	if audit != nil {
+		unaryInterceptors = append(unaryInterceptors, client_interceptor.UnaryClientAuditInterceptor(audit))
+	}
 
 	streamInterceptors := []grpc.StreamClientInterceptor{
 		// 1. Recovery
@@ -102,6 +151,14 @@ func NewClient(conf *ClientConfig) Client {
 	if conf.EnableLogger {
 		streamInterceptors = append(streamInterceptors, client_interceptor.StreamClientLoggerInterceptor(conf.Logger))
 	}
+	// 4. Circuit Breaker
+	if breaker != nil {
+		streamInterceptors = append(streamInterceptors, client_interceptor.StreamClientBreakerInterceptor(breaker))
+	}
+	// 5. Payload Audit
+	if audit != nil {
+		streamInterceptors = append(streamInterceptors, client_interceptor.StreamClientAuditInterceptor(audit))
+	}
 
 	return &ClientEntity{
 		ClientConfig:       conf,
@@ -111,6 +168,26 @@ func NewClient(conf *ClientConfig) Client {
 	}
 }
 
+func breakerConfig(conf *ClientConfig) *client_interceptor.BreakerConfig {
+	cloned := *conf.CircuitBreaker
+	if cloned.MetricsRegisterer == nil {
+		cloned.MetricsRegisterer = conf.MetricsRegisterer
+	}
+	cloned.DisableMetrics = cloned.DisableMetrics || conf.DisableMetrics
+	return &cloned
+}
+
+func clientAuditConfig(conf *ClientConfig) *client_interceptor.AuditConfig {
+	if conf.Audit == nil {
+		return nil
+	}
+	cloned := *conf.Audit
+	if cloned.Logger == nil {
+		cloned.Logger = conf.Logger
+	}
+	return &cloned
+}
+
 func (c *ClientEntity) Dial() (conn *grpc.ClientConn, err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -119,17 +196,65 @@ func (c *ClientEntity) Dial() (conn *grpc.ClientConn, err error) {
 	if c.conn != nil {
 		return c.conn, nil
 	}
-	if c.ClientConfig.Addr == "" {
+	c.conn, err = c.newConn()
+	return c.conn, err
+}
+
+// newConn builds a connection from the config; callers hold c.mu.
+func (c *ClientEntity) newConn() (*grpc.ClientConn, error) {
+	target := c.ClientConfig.Addr
+	var resolvers []grpc.DialOption
+	if len(c.Addrs) > 0 {
+		if target != "" || c.Discovery != nil {
+			return nil, ErrClientTargetConflict
+		}
+		builder, err := staticResolver(c.Addrs)
+		if err != nil {
+			return nil, err
+		}
+		target = builder.Scheme() + ":///static"
+		resolvers = append(resolvers, grpc.WithResolvers(builder))
+	}
+	if d := c.Discovery; d != nil {
+		if d.Registry == nil || d.Service == "" {
+			return nil, errors.New("grpcx: discovery registry and service are required")
+		}
+		target = discoveryScheme + ":///" + d.Service
+		resolvers = append(resolvers, grpc.WithResolvers(NewResolverBuilder(discoveryScheme, d.Registry)))
+	}
+	if target == "" {
 		return nil, errors.New("grpcx: client addr is required")
 	}
+	serviceConfig, err := serviceConfigJSON(c.ClientConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	baseClientOption := make([]grpc.DialOption, 0, len(c.dialOptions)+4)
-	if c.KeepaliveParams != nil {
+	if c.Transport != nil {
+		if c.KeepaliveParams != nil {
+			return nil, ErrKeepaliveConflict
+		}
+		transportOptions, err := c.Transport.options()
+		if err != nil {
+			return nil, err
+		}
+		baseClientOption = append(baseClientOption, transportOptions...)
+	} else if c.KeepaliveParams != nil {
 		baseClientOption = append(baseClientOption, grpc.WithKeepaliveParams(*c.KeepaliveParams))
 	}
 	switch {
 	case c.TransportCredentials != nil:
 		baseClientOption = append(baseClientOption, grpc.WithTransportCredentials(c.TransportCredentials))
+	case c.TLS != nil:
+		if c.TLSConfig != nil {
+			return nil, ErrTLSConfigConflict
+		}
+		tlsConfig, err := c.TLS.Build()
+		if err != nil {
+			return nil, err
+		}
+		baseClientOption = append(baseClientOption, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	case c.Secure:
 		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
 		if c.TLSConfig != nil {
@@ -142,14 +267,24 @@ func (c *ClientEntity) Dial() (conn *grpc.ClientConn, err error) {
 	default:
 		baseClientOption = append(baseClientOption, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
+	if c.PerRPCCredentials != nil {
+		baseClientOption = append(baseClientOption, grpc.WithPerRPCCredentials(c.PerRPCCredentials))
+	}
 	if c.Trace {
 		// Trace StatsHandler
-		baseClientOption = append(baseClientOption, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+		baseClientOption = append(baseClientOption, grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpcOptions(c.Propagators)...)))
+	}
+	baseClientOption = append(baseClientOption, resolvers...)
+	if serviceConfig != "" {
+		baseClientOption = append(baseClientOption, grpc.WithDefaultServiceConfig(serviceConfig))
 	}
 	options := append(baseClientOption, c.dialOptions...)
-	options = append(options, grpc.WithChainUnaryInterceptor(c.unaryInterceptors...), grpc.WithChainStreamInterceptor(c.streamInterceptors...))
-	c.conn, err = grpc.NewClient(c.ClientConfig.Addr, options...)
-	return c.conn, err
+	unaryInterceptors := c.unaryInterceptors
+	if policies := newHedgingPolicies(c.MethodConfigs); len(policies) > 0 {
+		unaryInterceptors = append(slices.Clip(unaryInterceptors), hedgingInterceptor(policies))
+	}
+	options = append(options, grpc.WithChainUnaryInterceptor(unaryInterceptors...), grpc.WithChainStreamInterceptor(c.streamInterceptors...))
+	return grpc.NewClient(target, options...)
 }
 
 func (c *ClientEntity) DialContext(ctx context.Context) (*grpc.ClientConn, error) {
@@ -190,6 +325,12 @@ func (c *ClientEntity) Conn() *grpc.ClientConn {
 func (c *ClientEntity) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.pool != nil {
+		for _, conn := range c.pool.conns[1:] {
+			_ = conn.Close()
+		}
+		c.pool = nil
+	}
 	if c.conn != nil {
 		_ = c.conn.Close()
 		c.conn = nil
@@ -0,0 +1,103 @@
+package grpcx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/grpcx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+func startHealthServer(t testing.TB) (*grpc.Server, *health.Server, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return server, healthServer, listener.Addr().String()
+}
+
+func TestClientAddrsHealthAwareFailover(t *testing.T) {
+	firstServer, firstHealth, firstAddr := startHealthServer(t)
+	_, secondHealth, secondAddr := startHealthServer(t)
+	firstHealth.SetServingStatus("orders", grpc_health_v1.HealthCheckResponse_SERVING)
+	secondHealth.SetServingStatus("orders", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	client := grpcx.NewClient(&grpcx.ClientConfig{
+		Addrs:                  []string{firstAddr, secondAddr},
+		HealthCheck:            true,
+		HealthCheckServiceName: "orders",
+		DisableMetrics:         true,
+	})
+	conn, err := client.Dial()
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(client.Close)
+	healthClient := grpc_health_v1.NewHealthClient(conn)
+
+	// waitForPeer polls until every call in a batch lands on want; calls
+	// caught on a connection that is going away fail and are retried.
+	waitForPeer := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for {
+			seen := map[string]int{}
+			for range 5 {
+				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				var p peer.Peer
+				_, err := healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.Peer(&p), grpc.WaitForReady(true))
+				cancel()
+				if err != nil {
+					seen[err.Error()]++
+					continue
+				}
+				seen[p.Addr.String()]++
+			}
+			if seen[want] == 5 {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("calls = %v, want all on %s", seen, want)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	waitForPeer(firstAddr)
+
+	secondHealth.SetServingStatus("orders", grpc_health_v1.HealthCheckResponse_SERVING)
+	firstServer.Stop()
+	waitForPeer(secondAddr)
+}
+
+func TestClientAddrsValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *grpcx.ClientConfig
+		want error
+	}{
+		{"with addr", &grpcx.ClientConfig{Addr: "127.0.0.1:1", Addrs: []string{"127.0.0.1:2"}}, grpcx.ErrClientTargetConflict},
+		{"empty entry", &grpcx.ClientConfig{Addrs: []string{"127.0.0.1:2", " "}}, grpcx.ErrEmptyClientAddr},
+		{"pick_first health", &grpcx.ClientConfig{Addrs: []string{"127.0.0.1:2"}, HealthCheck: true, LoadBalancing: grpcx.LoadBalancingPickFirst}, grpcx.ErrHealthCheckPickFirst},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.conf.DisableMetrics = true
+			if _, err := grpcx.NewClient(tt.conf).Dial(); !errors.Is(err, tt.want) {
+				t.Fatalf("Dial() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
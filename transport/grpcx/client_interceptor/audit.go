@@ -0,0 +1,63 @@
+package grpcx
+
+import (
+	"context"
+
+	"github.com/bang-go/micro/transport/grpcx/internal/audit"
+	"google.golang.org/grpc"
+)
+
+// AuditConfig controls client payload audit logging; it is the server-side
+// config, applied with the same redaction and sampling rules.
+type AuditConfig = audit.Config
+
+func UnaryClientAuditInterceptor(conf *AuditConfig) grpc.UnaryClientInterceptor {
+	a := audit.New("client", conf)
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !a.ShouldAudit(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		a.Log(ctx, method, "request", req, "", nil)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err != nil {
+			a.Log(ctx, method, "response", nil, streamStatusCode(err).String(), err)
+		} else {
+			a.Log(ctx, method, "response", reply, "", nil)
+		}
+		return err
+	}
+}
+
+func StreamClientAuditInterceptor(conf *AuditConfig) grpc.StreamClientInterceptor {
+	a := audit.New("client", conf)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		clientStream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil || !a.ShouldAudit(method) {
+			return clientStream, err
+		}
+		return &auditClientStream{ClientStream: clientStream, auditor: a, method: method}, nil
+	}
+}
+
+type auditClientStream struct {
+	grpc.ClientStream
+	auditor *audit.Auditor
+	method  string
+}
+
+func (s *auditClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	s.auditor.Log(s.Context(), s.method, "request", m, streamStatusCode(err).String(), err)
+	return err
+}
+
+func (s *auditClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.auditor.Log(s.Context(), s.method, "response", m, "", nil)
+	}
+	return err
+}
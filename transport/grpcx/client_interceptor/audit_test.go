@@ -0,0 +1,61 @@
+package grpcx_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	clientinterceptor "github.com/bang-go/micro/transport/grpcx/client_interceptor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestUnaryClientAuditInterceptorRedactsAndGatesMethods(t *testing.T) {
+	var logs bytes.Buffer
+	interceptor := clientinterceptor.UnaryClientAuditInterceptor(&clientinterceptor.AuditConfig{
+		Logger:          logger.New(logger.WithFormat("text"), logger.WithAddSource(false), logger.WithOutput(&logs)),
+		SampleRate:      1,
+		MaxPayloadBytes: 64,
+		RedactFields:    []string{"json_name"},
+		Methods:         []string{"/svc/Create", "/svc/Fail"},
+	})
+	invoke := func(method string, req, reply proto.Message, err error) {
+		t.Helper()
+		got := interceptor(context.Background(), method, req, reply, nil, func(_ context.Context, _ string, _, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			if err == nil {
+				reply.(*descriptorpb.FieldDescriptorProto).Name = proto.String("created")
+			}
+			return err
+		})
+		if got != err {
+			t.Fatalf("interceptor error = %v, want %v", got, err)
+		}
+	}
+
+	req := &descriptorpb.FieldDescriptorProto{Name: proto.String("password"), JsonName: proto.String("secretJson")}
+	invoke("/svc/Create", req, &descriptorpb.FieldDescriptorProto{}, nil)
+	invoke("/svc/Other", req, &descriptorpb.FieldDescriptorProto{}, nil)
+	invoke("/svc/Fail", &descriptorpb.FieldDescriptorProto{Name: proto.String(strings.Repeat("x", 100))}, &descriptorpb.FieldDescriptorProto{}, status.Error(codes.NotFound, "missing"))
+
+	output := logs.String()
+	if strings.Contains(output, "secretJson") || !strings.Contains(output, "[REDACTED]") {
+		t.Fatalf("redacted value leaked: %s", output)
+	}
+	if !strings.Contains(output, "kind=client") || !strings.Contains(output, "created") {
+		t.Fatalf("expected client request and response entries: %s", output)
+	}
+	if strings.Contains(output, "/svc/Other") {
+		t.Fatalf("method outside Methods was audited: %s", output)
+	}
+	if !strings.Contains(output, "payload_omitted=too_large") || !strings.Contains(output, "code=NotFound") {
+		t.Fatalf("expected size cap and error code: %s", output)
+	}
+	if req.GetJsonName() != "secretJson" {
+		t.Fatal("original request was modified")
+	}
+}
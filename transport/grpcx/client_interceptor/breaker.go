@@ -0,0 +1,261 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bang-go/micro/internal/breaker"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCircuitOpen is returned, as an Unavailable status, for calls rejected by
+// an open breaker.
+var ErrCircuitOpen = status.Error(codes.Unavailable, "grpcx: circuit breaker is open")
+
+type BreakerState = breaker.State
+
+const (
+	BreakerClosed   = breaker.Closed
+	BreakerHalfOpen = breaker.HalfOpen
+	BreakerOpen     = breaker.Open
+)
+
+// BreakerConfig trips a method's breaker when its error rate over the rolling
+// Window reaches ErrorRate with at least MinRequests calls. After OpenTimeout
+// the breaker lets HalfOpenRequests probes through; it closes when they all
+// succeed and opens again on the first failure.
+type BreakerConfig struct {
+	Window           time.Duration
+	Buckets          int
+	MinRequests      int
+	ErrorRate        float64
+	OpenTimeout      time.Duration
+	HalfOpenRequests int
+	// IsFailure decides which errors count against the method; by default
+	// Unavailable, DeadlineExceeded, ResourceExhausted, Internal, Unknown and
+	// DataLoss do, so caller mistakes and cancellations never trip it.
+	IsFailure   func(error) bool
+	SkipMethods []string
+	// OnStateChange is called outside the breaker lock on every transition.
+	OnStateChange func(method string, from, to BreakerState)
+	// Fallback is called with ErrCircuitOpen when a call is rejected and with
+	// the call error when it counts as a failure; its result replaces the
+	// error. Unary fallbacks may fill reply and return nil to serve a
+	// degraded response; reply is nil for streams.
+	Fallback func(ctx context.Context, method string, reply any, err error) error
+
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+}
+
+type breakerMetrics struct {
+	state      *prometheus.GaugeVec
+	rejections *prometheus.CounterVec
+}
+
+var (
+	defaultBreakerMetricsOnce sync.Once
+	defaultBreakerMetrics     *breakerMetrics
+)
+
+func newBreakerMetrics(registerer prometheus.Registerer) *breakerMetrics {
+	m := &breakerMetrics{
+		state: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "grpc_client_breaker_state",
+				Help: "gRPC client circuit breaker state: 0 closed, 1 half-open, 2 open",
+			},
+			[]string{"method"},
+		),
+		rejections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_client_breaker_rejections_total",
+				Help: "gRPC client calls rejected by an open circuit breaker",
+			},
+			[]string{"method"},
+		),
+	}
+	mustRegisterCollector(registerer, &m.state, m.state)
+	mustRegisterCollector(registerer, &m.rejections, m.rejections)
+	return m
+}
+
+// Breaker keeps one circuit per full method name. Share a Breaker between the
+// unary and stream interceptors of a client.
+type Breaker struct {
+	isFailure  func(error) bool
+	fallbackFn func(ctx context.Context, method string, reply any, err error) error
+	skip       map[string]struct{}
+	metrics    *breakerMetrics
+	circuits   *breaker.Group
+}
+
+func NewBreaker(conf *BreakerConfig) *Breaker {
+	if conf == nil {
+		conf = &BreakerConfig{}
+	}
+	b := &Breaker{isFailure: conf.IsFailure, fallbackFn: conf.Fallback, skip: make(map[string]struct{})}
+	if b.isFailure == nil {
+		b.isFailure = isBreakerFailure
+	}
+	for _, method := range conf.SkipMethods {
+		b.skip[method] = struct{}{}
+	}
+	switch {
+	case conf.DisableMetrics:
+	case conf.MetricsRegisterer != nil:
+		b.metrics = newBreakerMetrics(conf.MetricsRegisterer)
+	default:
+		defaultBreakerMetricsOnce.Do(func() {
+			defaultBreakerMetrics = newBreakerMetrics(prometheus.DefaultRegisterer)
+		})
+		b.metrics = defaultBreakerMetrics
+	}
+	onStateChange := conf.OnStateChange
+	b.circuits = breaker.NewGroup(breaker.Config{
+		Window:           conf.Window,
+		Buckets:          conf.Buckets,
+		MinRequests:      conf.MinRequests,
+		ErrorRate:        conf.ErrorRate,
+		OpenTimeout:      conf.OpenTimeout,
+		HalfOpenRequests: conf.HalfOpenRequests,
+	}, func(method string) {
+		if b.metrics != nil {
+			b.metrics.state.WithLabelValues(method).Set(float64(BreakerClosed))
+		}
+	}, func(method string, from, to BreakerState) {
+		if b.metrics != nil {
+			b.metrics.state.WithLabelValues(method).Set(float64(to))
+		}
+		if onStateChange != nil {
+			onStateChange(method, from, to)
+		}
+	})
+	return b
+}
+
+// State reports the current state of method's circuit.
+func (b *Breaker) State(method string) BreakerState {
+	return b.circuits.State(method)
+}
+
+// allow admits a call and returns the circuit generation to report its result
+// against, or false when the call is rejected.
+func (b *Breaker) allow(method string) (uint64, bool) {
+	generation, allowed := b.circuits.Allow(method)
+	if !allowed && b.metrics != nil {
+		b.metrics.rejections.WithLabelValues(method).Inc()
+	}
+	return generation, allowed
+}
+
+func (b *Breaker) done(method string, generation uint64, failed bool) {
+	b.circuits.Done(method, generation, failed)
+}
+
+func (b *Breaker) fallback(ctx context.Context, method string, reply any, err error) error {
+	if b.fallbackFn == nil {
+		return err
+	}
+	return b.fallbackFn(ctx, method, reply, err)
+}
+
+var breakerFailureCodes = []codes.Code{
+	codes.Unavailable,
+	codes.DeadlineExceeded,
+	codes.ResourceExhausted,
+	codes.Internal,
+	codes.Unknown,
+	codes.DataLoss,
+}
+
+func isBreakerFailure(err error) bool {
+	return slices.Contains(breakerFailureCodes, status.Code(err))
+}
+
+// UnaryClientBreakerInterceptor rejects calls to methods whose breaker is
+// open. A nil breaker uses the defaults.
+func UnaryClientBreakerInterceptor(b *Breaker) grpc.UnaryClientInterceptor {
+	if b == nil {
+		b = NewBreaker(nil)
+	}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := b.skip[method]; ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		generation, ok := b.allow(method)
+		if !ok {
+			return b.fallback(ctx, method, reply, ErrCircuitOpen)
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		failed := err != nil && b.isFailure(err)
+		b.done(method, generation, failed)
+		if failed {
+			return b.fallback(ctx, method, reply, err)
+		}
+		return err
+	}
+}
+
+// StreamClientBreakerInterceptor applies the breaker to stream creation and
+// counts the stream's final status seen by RecvMsg: io.EOF is a success.
+// Streams abandoned without reading to the end are not counted.
+func StreamClientBreakerInterceptor(b *Breaker) grpc.StreamClientInterceptor {
+	if b == nil {
+		b = NewBreaker(nil)
+	}
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if _, ok := b.skip[method]; ok {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		generation, ok := b.allow(method)
+		if !ok {
+			return nil, b.fallback(ctx, method, nil, ErrCircuitOpen)
+		}
+		clientStream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			failed := b.isFailure(err)
+			b.done(method, generation, failed)
+			if failed {
+				return nil, b.fallback(ctx, method, nil, err)
+			}
+			return nil, err
+		}
+		return &breakerClientStream{
+			ClientStream:  clientStream,
+			breaker:       b,
+			method:        method,
+			generation:    generation,
+			serverStreams: desc.ServerStreams,
+		}, nil
+	}
+}
+
+type breakerClientStream struct {
+	grpc.ClientStream
+	breaker    *Breaker
+	method     string
+	generation uint64
+	// serverStreams is false for client-streaming calls, which end with the
+	// single response.
+	serverStreams bool
+	once          sync.Once
+}
+
+func (s *breakerClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil && s.serverStreams {
+		return nil
+	}
+	s.once.Do(func() {
+		s.breaker.done(s.method, s.generation, err != nil && !errors.Is(err, io.EOF) && s.breaker.isFailure(err))
+	})
+	return err
+}
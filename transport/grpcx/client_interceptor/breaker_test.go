@@ -0,0 +1,201 @@
+package grpcx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	clientinterceptor "github.com/bang-go/micro/transport/grpcx/client_interceptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const breakerMethod = "/svc.Orders/Get"
+
+type transitionLog struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (l *transitionLog) record(_ string, from, to clientinterceptor.BreakerState) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.steps = append(l.steps, from.String()+"->"+to.String())
+}
+
+func (l *transitionLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.steps, ",")
+}
+
+func invokerReturning(err *error, calls *int) grpc.UnaryInvoker {
+	return func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		*calls++
+		return *err
+	}
+}
+
+func TestUnaryClientBreakerTripsAndRecovers(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	var log transitionLog
+	breaker := clientinterceptor.NewBreaker(&clientinterceptor.BreakerConfig{
+		MinRequests:       4,
+		ErrorRate:         0.5,
+		OpenTimeout:       50 * time.Millisecond,
+		OnStateChange:     log.record,
+		MetricsRegisterer: reg,
+	})
+	interceptor := clientinterceptor.UnaryClientBreakerInterceptor(breaker)
+	ctx := context.Background()
+
+	var (
+		callErr error
+		calls   int
+	)
+	invoker := invokerReturning(&callErr, &calls)
+	call := func() error { return interceptor(ctx, breakerMethod, nil, nil, nil, invoker) }
+
+	_ = call()
+	_ = call()
+	callErr = status.Error(codes.Unavailable, "down")
+	_ = call()
+	if breaker.State(breakerMethod) != clientinterceptor.BreakerClosed {
+		t.Fatal("breaker opened before MinRequests")
+	}
+	_ = call()
+	if breaker.State(breakerMethod) != clientinterceptor.BreakerOpen {
+		t.Fatalf("state = %v, want open at 50%% errors", breaker.State(breakerMethod))
+	}
+
+	if err := call(); !errors.Is(err, clientinterceptor.ErrCircuitOpen) || status.Code(err) != codes.Unavailable {
+		t.Fatalf("call() on open breaker error = %v", err)
+	}
+	if calls != 4 {
+		t.Fatalf("invoker calls = %d, want the rejected call not to reach it", calls)
+	}
+	if got := testutil.ToFloat64(breakerGauge(t, reg)); got != float64(clientinterceptor.BreakerOpen) {
+		t.Fatalf("state gauge = %v", got)
+	}
+
+	// A failed probe reopens the breaker.
+	time.Sleep(60 * time.Millisecond)
+	_ = call()
+	if breaker.State(breakerMethod) != clientinterceptor.BreakerOpen {
+		t.Fatalf("state after failed probe = %v", breaker.State(breakerMethod))
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	callErr = nil
+	if err := call(); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	if breaker.State(breakerMethod) != clientinterceptor.BreakerClosed {
+		t.Fatalf("state after successful probe = %v", breaker.State(breakerMethod))
+	}
+	if got, want := log.String(), "closed->open,open->half_open,half_open->open,open->half_open,half_open->closed"; got != want {
+		t.Fatalf("transitions = %s, want %s", got, want)
+	}
+}
+
+func TestUnaryClientBreakerIgnoresCallerErrors(t *testing.T) {
+	breaker := clientinterceptor.NewBreaker(&clientinterceptor.BreakerConfig{MinRequests: 2, DisableMetrics: true})
+	interceptor := clientinterceptor.UnaryClientBreakerInterceptor(breaker)
+
+	calls := 0
+	for _, code := range []codes.Code{codes.Canceled, codes.InvalidArgument, codes.NotFound} {
+		callErr := status.Error(code, "caller")
+		_ = interceptor(context.Background(), breakerMethod, nil, nil, nil, invokerReturning(&callErr, &calls))
+	}
+	if breaker.State(breakerMethod) != clientinterceptor.BreakerClosed {
+		t.Fatal("caller errors opened the breaker")
+	}
+}
+
+func TestUnaryClientBreakerFallback(t *testing.T) {
+	breaker := clientinterceptor.NewBreaker(&clientinterceptor.BreakerConfig{
+		MinRequests: 1,
+		OpenTimeout: time.Hour,
+		Fallback: func(_ context.Context, method string, reply any, err error) error {
+			*reply.(*string) = "cached:" + method
+			return nil
+		},
+		DisableMetrics: true,
+	})
+	interceptor := clientinterceptor.UnaryClientBreakerInterceptor(breaker)
+
+	calls := 0
+	callErr := status.Error(codes.Unavailable, "down")
+	for range 2 {
+		var reply string
+		if err := interceptor(context.Background(), breakerMethod, nil, &reply, nil, invokerReturning(&callErr, &calls)); err != nil {
+			t.Fatalf("call() error = %v", err)
+		}
+		if reply != "cached:"+breakerMethod {
+			t.Fatalf("reply = %q", reply)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("invoker calls = %d, want 1", calls)
+	}
+
+	// Circuits are per method.
+	var reply string
+	callErr = nil
+	if err := interceptor(context.Background(), "/svc.Orders/List", nil, &reply, nil, invokerReturning(&callErr, &calls)); err != nil || calls != 2 {
+		t.Fatalf("other method error = %v, calls = %d", err, calls)
+	}
+}
+
+func TestStreamClientBreakerCountsFinalStatus(t *testing.T) {
+	breaker := clientinterceptor.NewBreaker(&clientinterceptor.BreakerConfig{MinRequests: 3, ErrorRate: 0.6, OpenTimeout: time.Hour, DisableMetrics: true})
+	interceptor := clientinterceptor.StreamClientBreakerInterceptor(breaker)
+	desc := &grpc.StreamDesc{ServerStreams: true}
+
+	open := func(recvErr error) (grpc.ClientStream, error) {
+		return interceptor(context.Background(), desc, nil, breakerMethod, func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{ctx: context.Background(), recvMsgErr: recvErr}, nil
+		})
+	}
+
+	stream, err := open(io.EOF)
+	if err != nil {
+		t.Fatalf("open error = %v", err)
+	}
+	_ = stream.RecvMsg(nil)
+	for range 2 {
+		stream, err = open(status.Error(codes.Unavailable, "reset"))
+		if err != nil {
+			t.Fatalf("open error = %v", err)
+		}
+		_ = stream.RecvMsg(nil)
+		_ = stream.RecvMsg(nil)
+	}
+	if breaker.State(breakerMethod) != clientinterceptor.BreakerOpen {
+		t.Fatalf("state = %v, want open", breaker.State(breakerMethod))
+	}
+	if _, err := open(io.EOF); !errors.Is(err, clientinterceptor.ErrCircuitOpen) {
+		t.Fatalf("open on tripped breaker error = %v", err)
+	}
+}
+
+func breakerGauge(t *testing.T, reg *prometheus.Registry) prometheus.Collector {
+	t.Helper()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grpc_client_breaker_state",
+		Help: "gRPC client circuit breaker state: 0 closed, 1 half-open, 2 open",
+	}, []string{"method"})
+	err := reg.Register(gauge)
+	var already prometheus.AlreadyRegisteredError
+	if !errors.As(err, &already) {
+		t.Fatalf("breaker state gauge not registered: %v", err)
+	}
+	return already.ExistingCollector.(*prometheus.GaugeVec).WithLabelValues(breakerMethod)
+}
@@ -0,0 +1,131 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	microerrors "github.com/bang-go/micro/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var statusCodes = map[codes.Code]microerrors.Code{
+	codes.Unknown:            microerrors.CodeUnknown,
+	codes.Canceled:           microerrors.CodeCanceled,
+	codes.InvalidArgument:    microerrors.CodeInvalidArgument,
+	codes.DeadlineExceeded:   microerrors.CodeDeadlineExceeded,
+	codes.NotFound:           microerrors.CodeNotFound,
+	codes.AlreadyExists:      microerrors.CodeAlreadyExists,
+	codes.PermissionDenied:   microerrors.CodePermissionDenied,
+	codes.ResourceExhausted:  microerrors.CodeResourceExhausted,
+	codes.FailedPrecondition: microerrors.CodeFailedPrecondition,
+	codes.Aborted:            microerrors.CodeAborted,
+	codes.OutOfRange:         microerrors.CodeOutOfRange,
+	codes.Unimplemented:      microerrors.CodeUnimplemented,
+	codes.Internal:           microerrors.CodeInternal,
+	codes.Unavailable:        microerrors.CodeUnavailable,
+	codes.DataLoss:           microerrors.CodeDataLoss,
+	codes.Unauthenticated:    microerrors.CodeUnauthenticated,
+}
+
+// statusCause keeps the original status reachable through status.FromError and
+// status.Code, so outer interceptors still see the gRPC code.
+type statusCause struct {
+	st *status.Status
+}
+
+func (c statusCause) Error() string {
+	return "grpc status " + c.st.Code().String()
+}
+
+func (c statusCause) GRPCStatus() *status.Status {
+	return c.st
+}
+
+// FromStatus converts a gRPC status into a coded error. ErrorInfo details fill
+// Reason, Domain and Metadata; BadRequest details fill Fields. It returns nil
+// for an OK status.
+func FromStatus(st *status.Status) *microerrors.Error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	code, ok := statusCodes[st.Code()]
+	if !ok {
+		code = microerrors.CodeUnknown
+	}
+
+	coded := microerrors.Wrap(statusCause{st: st}, code, st.Message())
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			coded.Reason = detail.GetReason()
+			coded.Domain = detail.GetDomain()
+			coded.Metadata = detail.GetMetadata()
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				coded.Fields = append(coded.Fields, microerrors.FieldViolation{
+					Field:       violation.GetField(),
+					Description: violation.GetDescription(),
+				})
+			}
+		}
+	}
+	return coded
+}
+
+// convertError maps status errors to coded errors and leaves other errors,
+// including io.EOF from streams, untouched.
+func convertError(err error) error {
+	if err == nil || errors.Is(err, io.EOF) {
+		return err
+	}
+	var coded *microerrors.Error
+	if errors.As(err, &coded) {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return FromStatus(st)
+}
+
+// UnaryClientErrorInterceptor converts gRPC status errors into
+// *errors.Error from pkg/errors, so callers handle one error model across HTTP
+// and gRPC dependencies. status.Code still works on the converted error.
+func UnaryClientErrorInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return convertError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientErrorInterceptor converts status errors returned when opening a
+// stream and from SendMsg / RecvMsg.
+func StreamClientErrorInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		clientStream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, convertError(err)
+		}
+		return &errorClientStream{ClientStream: clientStream}, nil
+	}
+}
+
+type errorClientStream struct {
+	grpc.ClientStream
+}
+
+func (s *errorClientStream) SendMsg(m any) error {
+	return convertError(s.ClientStream.SendMsg(m))
+}
+
+func (s *errorClientStream) RecvMsg(m any) error {
+	return convertError(s.ClientStream.RecvMsg(m))
+}
+
+func (s *errorClientStream) CloseSend() error {
+	return convertError(s.ClientStream.CloseSend())
+}
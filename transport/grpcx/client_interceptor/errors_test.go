@@ -0,0 +1,87 @@
+package grpcx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	microerrors "github.com/bang-go/micro/pkg/errors"
+	clientinterceptor "github.com/bang-go/micro/transport/grpcx/client_interceptor"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryClientErrorInterceptor(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "bad user").WithDetails(
+		&errdetails.ErrorInfo{Reason: "USER_INVALID", Domain: "user.example.com", Metadata: map[string]string{"uid": "1"}},
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "email", Description: "required"}}},
+	)
+	if err != nil {
+		t.Fatalf("WithDetails() error = %v", err)
+	}
+
+	interceptor := clientinterceptor.UnaryClientErrorInterceptor()
+	err = interceptor(context.Background(), "/svc/method", nil, nil, nil, func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		return st.Err()
+	})
+
+	var coded *microerrors.Error
+	if !errors.As(err, &coded) {
+		t.Fatalf("error = %T %v, want *errors.Error", err, err)
+	}
+	if coded.Code != microerrors.CodeInvalidArgument || coded.Message != "bad user" || coded.Reason != "USER_INVALID" ||
+		coded.Domain != "user.example.com" || coded.Metadata["uid"] != "1" {
+		t.Fatalf("coded = %+v", coded)
+	}
+	if len(coded.Fields) != 1 || coded.Fields[0] != (microerrors.FieldViolation{Field: "email", Description: "required"}) {
+		t.Fatalf("fields = %+v", coded.Fields)
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("status.Code(err) = %v, want gRPC code preserved", status.Code(err))
+	}
+
+	plain := errors.New("not a status")
+	if got := interceptor(context.Background(), "/svc/method", nil, nil, nil, func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		return plain
+	}); got != plain {
+		t.Fatalf("plain error = %v, want untouched", got)
+	}
+}
+
+type recvStream struct {
+	grpc.ClientStream
+	err error
+}
+
+func (s recvStream) RecvMsg(any) error { return s.err }
+
+func TestStreamClientErrorInterceptor(t *testing.T) {
+	interceptor := clientinterceptor.StreamClientErrorInterceptor()
+	open := func(err error) grpc.ClientStream {
+		stream, openErr := interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/svc/stream", func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return recvStream{err: err}, nil
+		})
+		if openErr != nil {
+			t.Fatalf("open stream: %v", openErr)
+		}
+		return stream
+	}
+
+	if err := open(io.EOF).RecvMsg(nil); err != io.EOF {
+		t.Fatalf("RecvMsg() = %v, want io.EOF untouched", err)
+	}
+	err := open(status.Error(codes.Unavailable, "down")).RecvMsg(nil)
+	if microerrors.CodeOf(err) != microerrors.CodeUnavailable {
+		t.Fatalf("RecvMsg() = %v, want coded unavailable", err)
+	}
+
+	_, err = interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/svc/stream", func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return nil, status.Error(codes.PermissionDenied, "no")
+	})
+	if microerrors.CodeOf(err) != microerrors.CodePermissionDenied {
+		t.Fatalf("open error = %v, want coded permission denied", err)
+	}
+}
@@ -0,0 +1,117 @@
+package grpcx_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/pkg/registry"
+	"github.com/bang-go/micro/transport/grpcx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+func startRegisteredServer(t *testing.T, reg registry.Registry) (grpcx.Server, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	server := grpcx.NewServer(&grpcx.ServerConfig{
+		Listener:       listener,
+		Registry:       reg,
+		ServiceName:    "greeter",
+		Metadata:       map[string]string{"zone": "a"},
+		DisableMetrics: true,
+	})
+	serveDone := make(chan error, 1)
+	go func() {
+		serveDone <- server.Start(context.Background(), func(*grpc.Server) {})
+	}()
+	t.Cleanup(func() {
+		_ = server.Shutdown(context.Background())
+		if err := <-serveDone; err != nil {
+			t.Errorf("grpc server exited with error: %v", err)
+		}
+	})
+	return server, addr
+}
+
+func TestClientDiscoveryRoundRobin(t *testing.T) {
+	reg := registry.NewMemory()
+	first, firstAddr := startRegisteredServer(t, reg)
+	_, secondAddr := startRegisteredServer(t, reg)
+
+	client := grpcx.NewClient(&grpcx.ClientConfig{
+		Discovery:      &grpcx.DiscoveryConfig{Registry: reg, Service: "greeter"},
+		LoadBalancing:  grpcx.LoadBalancingRoundRobin,
+		DisableMetrics: true,
+	})
+	conn, err := client.Dial()
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(client.Close)
+	health := grpc_health_v1.NewHealthClient(conn)
+
+	peers := func(calls int) map[string]int {
+		t.Helper()
+		seen := map[string]int{}
+		for range calls {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			var p peer.Peer
+			_, err := health.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.Peer(&p), grpc.WaitForReady(true))
+			cancel()
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			seen[p.Addr.String()]++
+		}
+		return seen
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		seen := peers(10)
+		if seen[firstAddr] > 0 && seen[secondAddr] > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("calls were not spread across instances: %v", seen)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := first.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for {
+		seen := peers(5)
+		if seen[firstAddr] == 0 && seen[secondAddr] == 5 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("calls still reach the deregistered instance: %v", seen)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestClientRejectsUnknownLoadBalancing(t *testing.T) {
+	client := grpcx.NewClient(&grpcx.ClientConfig{
+		Addr:           "passthrough:///bufnet",
+		LoadBalancing:  "fastest",
+		DisableMetrics: true,
+	})
+	if _, err := client.Dial(); err == nil || !strings.Contains(err.Error(), "fastest") {
+		t.Fatalf("Dial() error = %v", err)
+	}
+}
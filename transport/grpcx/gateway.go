@@ -0,0 +1,208 @@
+package grpcx
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/bang-go/micro/transport/httpx"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var ErrGatewayRegisterRequired = errors.New("grpcx: gateway register func is required")
+
+// GatewayRegisterFunc matches the generated RegisterXxxHandler functions, so
+// pb.RegisterGreeterHandler can be passed directly.
+type GatewayRegisterFunc func(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error
+
+type GatewayConfig struct {
+	// Register 注册 grpc-gateway 生成的 handler，多个服务时在函数内依次调用。
+	Register GatewayRegisterFunc
+	// HTTP 网关 HTTP 服务配置（地址、超时、健康检查）；Logger、EnableLogger、Trace、Propagators 与指标配置为空时沿用 ServerConfig。
+	HTTP *httpx.ServerConfig
+	// MuxOptions 传给 runtime.NewServeMux，例如自定义 Marshaler、HeaderMatcher、ErrorHandler。
+	MuxOptions []runtime.ServeMuxOption
+	// DialOptions 追加到网关连接 gRPC 服务的进程内连接上；服务端要求客户端证书（mTLS）时需要在这里提供。
+	DialOptions []grpc.DialOption
+}
+
+// gateway serves REST requests by calling the gRPC server over an in-memory
+// connection, so the same interceptors (auth, limits, metrics, logging)
+// apply to both protocols.
+type gateway struct {
+	pipe *pipeListener
+	conn *grpc.ClientConn
+	http httpx.Server
+	done chan struct{}
+}
+
+func newGateway(ctx context.Context, conf *ServerConfig, grpcServer *grpc.Server, tlsConfig *tls.Config) (_ *gateway, err error) {
+	gw := conf.Gateway
+	if gw.Register == nil {
+		return nil, ErrGatewayRegisterRequired
+	}
+	httpConf := gatewayHTTPConfig(conf)
+
+	listener := httpConf.Listener
+	if listener == nil {
+		if httpConf.Addr == "" {
+			return nil, errors.New("grpcx: gateway addr or listener is required")
+		}
+		listener, err = net.Listen("tcp", httpConf.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("grpcx: gateway listen: %w", err)
+		}
+	}
+	defer func() {
+		if err != nil {
+			_ = listener.Close()
+		}
+	}()
+
+	pipe := newPipeListener()
+	go func() { _ = grpcServer.Serve(pipe) }()
+	defer func() {
+		if err != nil {
+			_ = pipe.Close()
+		}
+	}()
+
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		// The connection never leaves the process, so there is no peer
+		// certificate worth verifying.
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})
+	}
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return pipe.DialContext(ctx)
+		}),
+	}
+	if conf.Trace {
+		dialOptions = append(dialOptions, grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpcOptions(conf.Propagators)...)))
+	}
+	dialOptions = append(dialOptions, gw.DialOptions...)
+	conn, err := grpc.NewClient("passthrough:///grpcx-gateway", dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("grpcx: gateway dial: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = conn.Close()
+		}
+	}()
+
+	mux := runtime.NewServeMux(gw.MuxOptions...)
+	if err := gw.Register(ctx, mux, conn); err != nil {
+		return nil, fmt.Errorf("grpcx: gateway register: %w", err)
+	}
+
+	g := &gateway{
+		pipe: pipe,
+		conn: conn,
+		http: httpx.NewServer(httpConf),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(g.done)
+		if err := g.http.Serve(ctx, listener, mux); err != nil {
+			conf.Logger.Error(ctx, "grpc gateway serve failed", "error", err)
+		}
+	}()
+	return g, nil
+}
+
+func gatewayHTTPConfig(conf *ServerConfig) *httpx.ServerConfig {
+	var cloned httpx.ServerConfig
+	if conf.Gateway.HTTP != nil {
+		cloned = *conf.Gateway.HTTP
+	}
+	if cloned.Logger == nil {
+		cloned.Logger = conf.Logger
+	}
+	cloned.EnableLogger = cloned.EnableLogger || conf.EnableLogger
+	cloned.Trace = cloned.Trace || conf.Trace
+	if cloned.Propagators == nil {
+		cloned.Propagators = conf.Propagators
+	}
+	if cloned.MetricsRegisterer == nil {
+		cloned.MetricsRegisterer = conf.MetricsRegisterer
+	}
+	cloned.DisableMetrics = cloned.DisableMetrics || conf.DisableMetrics
+	return &cloned
+}
+
+// shutdown drains REST requests while the gRPC server is still serving them.
+func (g *gateway) shutdown(ctx context.Context) error {
+	err := g.http.Shutdown(ctx)
+	select {
+	case <-g.done:
+	case <-ctx.Done():
+	}
+	return err
+}
+
+func (g *gateway) close() {
+	_ = g.http.Close()
+	<-g.done
+	_ = g.conn.Close()
+	_ = g.pipe.Close()
+}
+
+// pipeListener is a net.Listener backed by net.Pipe for in-process clients.
+type pipeListener struct {
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{conns: make(chan net.Conn), done: make(chan struct{})}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) DialContext(ctx context.Context) (net.Conn, error) {
+	server, client := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.done:
+	case <-ctx.Done():
+	}
+	_ = server.Close()
+	_ = client.Close()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, net.ErrClosed
+}
+
+func (l *pipeListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "grpcx-gateway" }
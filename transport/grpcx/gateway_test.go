@@ -0,0 +1,116 @@
+package grpcx_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/grpcx"
+	"github.com/bang-go/micro/transport/httpx"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// registerHealthGateway mirrors what protoc-gen-grpc-gateway emits for
+// GET /v1/health -> grpc.health.v1.Health/Check.
+func registerHealthGateway(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error {
+	client := grpc_health_v1.NewHealthClient(conn)
+	return mux.HandlePath(http.MethodGet, "/v1/health", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		_, marshaler := runtime.MarshalerForRequest(mux, r)
+		annotated, err := runtime.AnnotateContext(r.Context(), mux, r, grpc_health_v1.Health_Check_FullMethodName)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, marshaler, w, r, err)
+			return
+		}
+		resp, err := client.Check(annotated, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			runtime.HTTPError(annotated, mux, marshaler, w, r, err)
+			return
+		}
+		runtime.ForwardResponseMessage(annotated, mux, marshaler, w, r, resp)
+	})
+}
+
+func TestServerGatewayTranscodesThroughInterceptors(t *testing.T) {
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	server := grpcx.NewServer(&grpcx.ServerConfig{
+		Listener:       bufconn.Listen(1024 * 1024),
+		DisableMetrics: true,
+		Gateway: &grpcx.GatewayConfig{
+			Register: registerHealthGateway,
+			HTTP:     &httpx.ServerConfig{Listener: httpListener},
+		},
+	})
+	authorization := make(chan []string, 1)
+	server.AddUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		authorization <- md.Get("authorization")
+		return handler(ctx, req)
+	})
+
+	serveDone := make(chan error, 1)
+	go func() {
+		serveDone <- server.Start(context.Background(), func(*grpc.Server) {})
+	}()
+
+	url := "http://" + httpListener.Addr().String() + "/v1/health"
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "Bearer token")
+	var resp *http.Response
+	for deadline := time.Now().Add(2 * time.Second); ; {
+		resp, err = http.DefaultClient.Do(req)
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET %s error = %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct{ Status string }
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Status != "SERVING" {
+		t.Fatalf("response = %d %+v, want 200 SERVING", resp.StatusCode, body)
+	}
+	if got := <-authorization; len(got) != 1 || got[0] != "Bearer token" {
+		t.Fatalf("authorization metadata = %v", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-serveDone; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Fatal("gateway still serving after Shutdown")
+	}
+}
+
+func TestServerGatewayRequiresRegister(t *testing.T) {
+	server := grpcx.NewServer(&grpcx.ServerConfig{
+		Listener:       bufconn.Listen(1024),
+		DisableMetrics: true,
+		Gateway:        &grpcx.GatewayConfig{HTTP: &httpx.ServerConfig{Addr: "127.0.0.1:0"}},
+	})
+	err := server.Start(context.Background(), func(*grpc.Server) {})
+	if !errors.Is(err, grpcx.ErrGatewayRegisterRequired) {
+		t.Fatalf("Start() error = %v, want ErrGatewayRegisterRequired", err)
+	}
+}
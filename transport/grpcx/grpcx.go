@@ -3,8 +3,18 @@ package grpcx
 import (
 	"math"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 const (
 	infinity = time.Duration(math.MaxInt64)
 )
+
+func otelgrpcOptions(propagator propagation.TextMapPropagator, opts ...otelgrpc.Option) []otelgrpc.Option {
+	if propagator != nil {
+		opts = append(opts, otelgrpc.WithPropagators(propagator))
+	}
+	return opts
+}
@@ -0,0 +1,44 @@
+package grpcx
+
+import (
+	"context"
+	"time"
+)
+
+const defaultHealthInterval = 5 * time.Second
+
+func (s *ServerEntity) watchHealth(ctx context.Context, done <-chan struct{}, healthy bool) {
+	interval := s.HealthInterval
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			healthy = s.syncHealth(ctx, healthy)
+		}
+	}
+}
+
+func (s *ServerEntity) syncHealth(ctx context.Context, healthy bool) bool {
+	err := s.Health.Ready(ctx)
+	if err != nil {
+		if healthy {
+			s.Logger.Warn(ctx, "grpc server not serving", "error", err)
+		}
+		s.setCheckerHealth(false)
+		return false
+	}
+	if !healthy {
+		s.info(ctx, "grpc server serving")
+	}
+	s.setCheckerHealth(true)
+	return true
+}
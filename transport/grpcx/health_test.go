@@ -0,0 +1,179 @@
+package grpcx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/pkg/healthcheck"
+	"github.com/bang-go/micro/transport/grpcx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerPerServiceHealth(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpcx.NewServer(&grpcx.ServerConfig{Listener: listener, DisableMetrics: true})
+	server.SetNotServing("orders.v1.Orders")
+	serveDone := make(chan error, 1)
+	go func() {
+		serveDone <- server.Start(context.Background(), func(*grpc.Server) {})
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer conn.Close()
+	health := grpc_health_v1.NewHealthClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	check := func(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := health.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service}, grpc.WaitForReady(true))
+		if err != nil {
+			t.Fatalf("Check(%q) error = %v", service, err)
+		}
+		return resp.GetStatus()
+	}
+
+	if got := check("orders.v1.Orders"); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status before SetServing = %v", got)
+	}
+	if got := check(""); got != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("server status = %v", got)
+	}
+	server.SetServing("orders.v1.Orders")
+	if got := check("orders.v1.Orders"); got != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("status after SetServing = %v", got)
+	}
+	server.SetNotServing("")
+	if got := check(""); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("server status after SetNotServing = %v", got)
+	}
+
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-serveDone; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
+
+func TestServerHealthFollowsChecker(t *testing.T) {
+	var down atomic.Bool
+	checker := healthcheck.New(healthcheck.WithTTL(-1))
+	checker.MustRegister("db", func(context.Context) error {
+		if down.Load() {
+			return errors.New("db down")
+		}
+		return nil
+	})
+	conn := startDebugServer(t, &grpcx.ServerConfig{Health: checker, HealthInterval: 10 * time.Millisecond})
+	health := grpc_health_v1.NewHealthClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	waitFor := func(want grpc_health_v1.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		for {
+			resp, err := health.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.WaitForReady(true))
+			if err == nil && resp.GetStatus() == want {
+				return
+			}
+			if ctx.Err() != nil {
+				t.Fatalf("status never became %v, last = %v, %v", want, resp.GetStatus(), err)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitFor(grpc_health_v1.HealthCheckResponse_SERVING)
+	down.Store(true)
+	waitFor(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	down.Store(false)
+	waitFor(grpc_health_v1.HealthCheckResponse_SERVING)
+}
+
+func TestServerManualNotServingOverridesChecker(t *testing.T) {
+	var down atomic.Bool
+	checker := healthcheck.New(healthcheck.WithTTL(-1))
+	checker.MustRegister("db", func(context.Context) error {
+		if down.Load() {
+			return errors.New("db down")
+		}
+		return nil
+	})
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpcx.NewServer(&grpcx.ServerConfig{
+		Listener:       listener,
+		DisableMetrics: true,
+		Health:         checker,
+		HealthInterval: 5 * time.Millisecond,
+	})
+	serveDone := make(chan error, 1)
+	go func() {
+		serveDone <- server.Start(context.Background(), func(*grpc.Server) {})
+	}()
+	defer func() {
+		_ = server.Shutdown(context.Background())
+		<-serveDone
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer conn.Close()
+	health := grpc_health_v1.NewHealthClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	check := func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := health.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.WaitForReady(true))
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		return resp.GetStatus()
+	}
+	waitFor := func(want grpc_health_v1.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		for check() != want {
+			if ctx.Err() != nil {
+				t.Fatalf("status never became %v", want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitFor(grpc_health_v1.HealthCheckResponse_SERVING)
+	server.SetNotServing("")
+	// Several passing evaluations must not lift the manual override.
+	time.Sleep(50 * time.Millisecond)
+	if got := check(); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status after SetNotServing with healthy checks = %v", got)
+	}
+
+	down.Store(true)
+	server.SetServing("")
+	time.Sleep(50 * time.Millisecond)
+	if got := check(); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status after SetServing with failing checks = %v", got)
+	}
+	down.Store(false)
+	waitFor(grpc_health_v1.HealthCheckResponse_SERVING)
+}
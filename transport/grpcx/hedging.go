@@ -0,0 +1,114 @@
+package grpcx
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// hedgingPolicies resolves the hedging policy of a full method name with the
+// service config precedence: method, then service, then default.
+type hedgingPolicies map[methodName]*HedgingPolicy
+
+func newHedgingPolicies(configs []MethodConfig) hedgingPolicies {
+	policies := hedgingPolicies{}
+	for _, mc := range configs {
+		if mc.Hedging == nil {
+			continue
+		}
+		names, err := parseMethodNames(mc.Methods)
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			policies[name] = mc.Hedging
+		}
+	}
+	return policies
+}
+
+func (p hedgingPolicies) lookup(fullMethod string) *HedgingPolicy {
+	names, err := parseMethodNames([]string{fullMethod})
+	if err != nil {
+		return p[methodName{}]
+	}
+	name := names[0]
+	if policy, ok := p[name]; ok {
+		return policy
+	}
+	if policy, ok := p[methodName{Service: name.Service}]; ok {
+		return policy
+	}
+	return p[methodName{}]
+}
+
+// hedgingInterceptor applies HedgingPolicy to unary calls. It sits innermost
+// so metrics and logs see one logical call.
+func hedgingInterceptor(policies hedgingPolicies) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		policy := policies.lookup(method)
+		replyMsg, ok := reply.(proto.Message)
+		if policy == nil || !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return hedge(ctx, policy, replyMsg, func(ctx context.Context, reply proto.Message) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+func hedge(ctx context.Context, policy *HedgingPolicy, reply proto.Message, call func(context.Context, proto.Message) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		reply proto.Message
+		err   error
+	}
+	results := make(chan result, policy.MaxAttempts)
+	started, pending := 0, 0
+	launch := func() {
+		started++
+		pending++
+		attempt := reply.ProtoReflect().New().Interface()
+		go func() {
+			results <- result{reply: attempt, err: call(ctx, attempt)}
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(policy.HedgingDelay)
+	defer timer.Stop()
+	var lastErr error
+	for pending > 0 || started < policy.MaxAttempts {
+		var next <-chan time.Time
+		if started < policy.MaxAttempts {
+			next = timer.C
+		}
+		select {
+		case <-next:
+			launch()
+			timer.Reset(policy.HedgingDelay)
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				proto.Reset(reply)
+				proto.Merge(reply, res.reply)
+				return nil
+			}
+			lastErr = res.err
+			if !slices.Contains(policy.NonFatalCodes, status.Code(res.err)) {
+				return res.err
+			}
+			if started < policy.MaxAttempts {
+				launch()
+				timer.Reset(policy.HedgingDelay)
+			}
+		}
+	}
+	return lastErr
+}
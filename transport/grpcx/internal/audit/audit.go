@@ -0,0 +1,189 @@
+// Package audit holds the payload audit logging shared by the grpcx server
+// and client interceptors.
+package audit
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	defaultMaxPayloadBytes = 4 << 10
+	redactedValue          = "[REDACTED]"
+)
+
+// Config controls payload audit logging. Fields marked with the debug_redact
+// option are always redacted; RedactFields adds more by field name
+// ("password") or full name ("user.v1.User.password").
+type Config struct {
+	Logger *logger.Logger
+	// SampleRate is the fraction of calls audited, from 0 to 1.
+	SampleRate float64
+	// MaxPayloadBytes omits payloads whose wire size exceeds the limit.
+	MaxPayloadBytes int
+	RedactFields    []string
+	// Methods restricts auditing to these full method names when non-empty.
+	Methods     []string
+	SkipMethods []string
+}
+
+type Auditor struct {
+	kind            string
+	logger          *logger.Logger
+	sampleRate      float64
+	maxPayloadBytes int
+	redact          map[string]struct{}
+	methods         map[string]struct{}
+	skip            map[string]struct{}
+	sample          func() float64
+}
+
+// New returns nil when conf disables auditing. kind is logged as "server" or
+// "client".
+func New(kind string, conf *Config) *Auditor {
+	if conf == nil || conf.SampleRate <= 0 {
+		return nil
+	}
+
+	a := &Auditor{
+		kind:            kind,
+		logger:          conf.Logger,
+		sampleRate:      min(conf.SampleRate, 1),
+		maxPayloadBytes: conf.MaxPayloadBytes,
+		redact:          toSet(conf.RedactFields),
+		methods:         toSet(conf.Methods),
+		skip:            toSet(conf.SkipMethods),
+		sample:          rand.Float64,
+	}
+	if a.logger == nil {
+		a.logger = logger.New(logger.WithLevel("info"))
+	}
+	if a.maxPayloadBytes <= 0 {
+		a.maxPayloadBytes = defaultMaxPayloadBytes
+	}
+	return a
+}
+
+// ShouldAudit applies the method filters and samples the call. It is safe
+// on a nil Auditor.
+func (a *Auditor) ShouldAudit(method string) bool {
+	if a == nil {
+		return false
+	}
+	if _, ok := a.skip[method]; ok {
+		return false
+	}
+	if len(a.methods) > 0 {
+		if _, ok := a.methods[method]; !ok {
+			return false
+		}
+	}
+	return a.sample() < a.sampleRate
+}
+
+// Log writes one grpc_payload_audit entry. A nil payload, e.g. the response
+// of a failed call, logs no payload; code is only logged with err.
+func (a *Auditor) Log(ctx context.Context, method, direction string, payload any, code string, err error) {
+	args := []any{
+		"kind", a.kind,
+		"method", method,
+		"direction", direction,
+	}
+	if err != nil {
+		args = append(args, "code", code, "error", err)
+	}
+
+	msg, ok := payload.(proto.Message)
+	switch {
+	case payload == nil:
+	case !ok || msg == nil:
+		args = append(args, "payload_omitted", "not_proto")
+	case proto.Size(msg) > a.maxPayloadBytes:
+		args = append(args, "payload_omitted", "too_large", "size", proto.Size(msg))
+	default:
+		body, marshalErr := protojson.Marshal(a.redacted(msg))
+		if marshalErr != nil {
+			args = append(args, "payload_omitted", "marshal_failed", "marshal_error", marshalErr)
+			break
+		}
+		args = append(args, "payload", string(body), "size", proto.Size(msg))
+	}
+
+	a.logger.Info(ctx, "grpc_payload_audit", args...)
+}
+
+// redacted returns a copy of msg with sensitive fields masked; the original is never modified.
+func (a *Auditor) redacted(msg proto.Message) proto.Message {
+	cloned := proto.Clone(msg)
+	a.redactMessage(cloned.ProtoReflect())
+	return cloned
+}
+
+func (a *Auditor) redactMessage(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if a.isRedacted(fd) {
+			redactField(m, fd)
+			return true
+		}
+
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				a.redactMessage(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+				a.redactMessage(value.Message())
+				return true
+			})
+		case fd.Message() != nil && !fd.IsList() && !fd.IsMap():
+			a.redactMessage(v.Message())
+		}
+		return true
+	})
+}
+
+func (a *Auditor) isRedacted(fd protoreflect.FieldDescriptor) bool {
+	if options, ok := fd.Options().(*descriptorpb.FieldOptions); ok && options.GetDebugRedact() {
+		return true
+	}
+	if _, ok := a.redact[string(fd.Name())]; ok {
+		return true
+	}
+	_, ok := a.redact[string(fd.FullName())]
+	return ok
+}
+
+func redactField(m protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	if fd.IsList() || fd.IsMap() {
+		m.Clear(fd)
+		return
+	}
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		m.Set(fd, protoreflect.ValueOfString(redactedValue))
+	case protoreflect.BytesKind:
+		m.Set(fd, protoreflect.ValueOfBytes([]byte(redactedValue)))
+	default:
+		m.Clear(fd)
+	}
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			set[value] = struct{}{}
+		}
+	}
+	return set
+}
@@ -0,0 +1,123 @@
+package grpcx
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"google.golang.org/grpc"
+)
+
+// Names of the built-in middlewares, in chain order. Only the ones enabled
+// by ServerConfig are present.
+const (
+	MiddlewareRecovery = "recovery"
+	MiddlewareMetrics  = "metrics"
+	MiddlewareLogger   = "logger"
+	MiddlewareSlowLog  = "slowlog"
+	MiddlewareTimeout  = "timeout"
+	MiddlewareBudget   = "budget"
+	MiddlewareAuth     = "auth"
+	MiddlewareLimit    = "limit"
+	MiddlewareValidate = "validate"
+	MiddlewareAudit    = "audit"
+)
+
+var (
+	ErrMiddlewareNotFound = errors.New("grpcx: middleware not found")
+	ErrMiddlewareExists   = errors.New("grpcx: middleware already registered")
+	ErrMiddlewareEmpty    = errors.New("grpcx: middleware has no interceptor")
+)
+
+// Middleware is a named pair of server interceptors; either may be nil for
+// middleware that only applies to unary or streaming calls. The name lets
+// later middleware be placed relative to it.
+type Middleware struct {
+	Name   string
+	Unary  grpc.UnaryServerInterceptor
+	Stream grpc.StreamServerInterceptor
+}
+
+// Use appends middleware to the end of the chain, like AddUnaryInterceptor.
+func (s *ServerEntity) Use(middleware ...Middleware) error {
+	return s.insertMiddleware("", false, middleware)
+}
+
+// UseBefore places middleware just before the named one, e.g. a rate
+// limiter before MiddlewareMetrics so rejected calls are not measured.
+// An empty name matches nothing; use Use to append.
+func (s *ServerEntity) UseBefore(name string, middleware ...Middleware) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty name", ErrMiddlewareNotFound)
+	}
+	return s.insertMiddleware(name, false, middleware)
+}
+
+// UseAfter places middleware just after the named one.
+func (s *ServerEntity) UseAfter(name string, middleware ...Middleware) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty name", ErrMiddlewareNotFound)
+	}
+	return s.insertMiddleware(name, true, middleware)
+}
+
+// Middlewares returns the chain in call order; unnamed entries added with
+// AddUnaryInterceptor or AddStreamInterceptor show as "".
+func (s *ServerEntity) Middlewares() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, len(s.middlewares))
+	for i, m := range s.middlewares {
+		names[i] = m.Name
+	}
+	return names
+}
+
+func (s *ServerEntity) insertMiddleware(anchor string, after bool, middleware []Middleware) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make(map[string]struct{}, len(s.middlewares)+len(middleware))
+	for _, m := range s.middlewares {
+		names[m.Name] = struct{}{}
+	}
+	for _, m := range middleware {
+		if m.Unary == nil && m.Stream == nil {
+			return ErrMiddlewareEmpty
+		}
+		if m.Name == "" {
+			continue
+		}
+		if _, ok := names[m.Name]; ok {
+			return fmt.Errorf("%w: %s", ErrMiddlewareExists, m.Name)
+		}
+		names[m.Name] = struct{}{}
+	}
+
+	at := len(s.middlewares)
+	if anchor != "" {
+		at = slices.IndexFunc(s.middlewares, func(m Middleware) bool { return m.Name == anchor })
+		if at < 0 {
+			return fmt.Errorf("%w: %s", ErrMiddlewareNotFound, anchor)
+		}
+		if after {
+			at++
+		}
+	}
+	s.middlewares = slices.Insert(s.middlewares, at, middleware...)
+	return nil
+}
+
+func chainMiddlewares(middlewares []Middleware) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+	for _, m := range middlewares {
+		if m.Unary != nil {
+			unary = append(unary, m.Unary)
+		}
+		if m.Stream != nil {
+			stream = append(stream, m.Stream)
+		}
+	}
+	return unary, stream
+}
@@ -0,0 +1,95 @@
+package grpcx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/grpcx"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerMiddlewareOrdering(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpcx.NewServer(&grpcx.ServerConfig{Listener: listener, MetricsRegisterer: prometheus.NewRegistry()})
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) grpcx.Middleware {
+		return grpcx.Middleware{Name: name, Unary: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			mu.Lock()
+			calls = append(calls, name)
+			mu.Unlock()
+			return handler(ctx, req)
+		}}
+	}
+	if err := server.Use(record("tail")); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+	if err := server.UseBefore(grpcx.MiddlewareMetrics, record("ratelimit")); err != nil {
+		t.Fatalf("UseBefore() error = %v", err)
+	}
+	if err := server.UseAfter(grpcx.MiddlewareRecovery, record("tenant")); err != nil {
+		t.Fatalf("UseAfter() error = %v", err)
+	}
+	if err := server.UseBefore(grpcx.MiddlewareAuth, record("x")); !errors.Is(err, grpcx.ErrMiddlewareNotFound) {
+		t.Fatalf("UseBefore(disabled auth) error = %v", err)
+	}
+	if err := server.UseBefore("", record("x")); !errors.Is(err, grpcx.ErrMiddlewareNotFound) {
+		t.Fatalf("UseBefore(\"\") error = %v", err)
+	}
+	if err := server.UseAfter("", record("x")); !errors.Is(err, grpcx.ErrMiddlewareNotFound) {
+		t.Fatalf("UseAfter(\"\") error = %v", err)
+	}
+	if err := server.Use(record("tail")); !errors.Is(err, grpcx.ErrMiddlewareExists) {
+		t.Fatalf("Use(duplicate) error = %v", err)
+	}
+	if err := server.Use(grpcx.Middleware{Name: "empty"}); !errors.Is(err, grpcx.ErrMiddlewareEmpty) {
+		t.Fatalf("Use(empty) error = %v", err)
+	}
+	want := []string{grpcx.MiddlewareRecovery, "tenant", "ratelimit", grpcx.MiddlewareMetrics, "tail"}
+	if got := server.Middlewares(); !slices.Equal(got, want) {
+		t.Fatalf("Middlewares() = %v, want %v", got, want)
+	}
+
+	serveDone := make(chan error, 1)
+	go func() {
+		serveDone <- server.Start(context.Background(), func(*grpc.Server) {})
+	}()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.WaitForReady(true)); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	mu.Lock()
+	got := slices.Clone(calls)
+	mu.Unlock()
+	if !slices.Equal(got, []string{"tenant", "ratelimit", "tail"}) {
+		t.Fatalf("call order = %v", got)
+	}
+
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-serveDone; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
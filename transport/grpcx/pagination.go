@@ -0,0 +1,97 @@
+package grpcx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	microerrors "github.com/bang-go/micro/pkg/errors"
+	"github.com/bang-go/micro/pkg/pagination"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// BindPagination reads the page, page_size, page_token, order_by and filter
+// fields of a list request message; absent fields are treated as empty, so
+// messages following AIP-158 (page_size / page_token only) work as is.
+// Failures are INVALID_ARGUMENT statuses with BadRequest details.
+func BindPagination(msg proto.Message, p *pagination.Paginator) (*pagination.Request, error) {
+	if msg == nil {
+		return nil, status.Error(codes.InvalidArgument, "grpcx: list request is nil")
+	}
+	m := msg.ProtoReflect()
+	page, err := intField(m, pagination.FieldPage)
+	if err != nil {
+		return nil, err
+	}
+	size, err := intField(m, pagination.FieldPageSize)
+	if err != nil {
+		return nil, err
+	}
+	req, err := p.Parse(pagination.Query{
+		Page:      page,
+		PageSize:  size,
+		PageToken: stringField(m, pagination.FieldPageToken),
+		OrderBy:   stringField(m, pagination.FieldOrderBy),
+		Filter:    stringField(m, pagination.FieldFilter),
+	})
+	if err != nil {
+		return nil, paginationStatus(err)
+	}
+	return req, nil
+}
+
+func intField(m protoreflect.Message, name protoreflect.Name) (int, error) {
+	field := m.Descriptor().Fields().ByName(name)
+	if field == nil || field.IsList() || field.IsMap() {
+		return 0, nil
+	}
+	var value int64
+	switch field.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		value = m.Get(field).Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		unsigned := m.Get(field).Uint()
+		value = int64(min(unsigned, math.MaxInt32))
+	default:
+		return 0, nil
+	}
+	if value > math.MaxInt32 || value < math.MinInt32 {
+		description := fmt.Sprintf("%s is out of range", name)
+		return 0, paginationStatus(microerrors.New(microerrors.CodeInvalidArgument, description).
+			WithFields(microerrors.FieldViolation{Field: string(name), Description: description}))
+	}
+	return int(value), nil
+}
+
+func stringField(m protoreflect.Message, name protoreflect.Name) string {
+	field := m.Descriptor().Fields().ByName(name)
+	if field == nil || field.Kind() != protoreflect.StringKind || field.IsList() || field.IsMap() {
+		return ""
+	}
+	return m.Get(field).String()
+}
+
+func paginationStatus(err error) error {
+	var coded *microerrors.Error
+	if !errors.As(err, &coded) {
+		return status.Error(codes.InvalidArgument, "grpcx: invalid list request: "+err.Error())
+	}
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(coded.Fields))
+	descriptions := make([]string, 0, len(coded.Fields))
+	for _, field := range coded.Fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Description})
+		descriptions = append(descriptions, field.Description)
+	}
+	st := status.New(codes.InvalidArgument, "grpcx: invalid list request: "+strings.Join(descriptions, "; "))
+	detailed, detailErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
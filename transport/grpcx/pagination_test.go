@@ -0,0 +1,95 @@
+package grpcx_test
+
+import (
+	"testing"
+
+	"github.com/bang-go/micro/pkg/pagination"
+	"github.com/bang-go/micro/transport/grpcx"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func newListRequest(t *testing.T) (*dynamicpb.Message, protoreflect.MessageDescriptor) {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Type:   typ.Enum(),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("pagination_test.proto"),
+		Package: proto.String("pagination.test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("ListItemsRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("page_size", 1, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				field("page_token", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("order_by", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("filter", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("build descriptor: %v", err)
+	}
+	desc := file.Messages().Get(0)
+	return dynamicpb.NewMessage(desc), desc
+}
+
+func TestBindPagination(t *testing.T) {
+	p, err := pagination.New(&pagination.Config{
+		SortFields:   []string{"created_at", "id"},
+		DefaultSort:  "-created_at,id",
+		FilterFields: map[string]pagination.FieldType{"age": pagination.TypeInt},
+		CursorSecret: []byte("secret"),
+	})
+	if err != nil {
+		t.Fatalf("pagination.New() error = %v", err)
+	}
+
+	msg, desc := newListRequest(t)
+	msg.Set(desc.Fields().ByName("page_size"), protoreflect.ValueOfInt32(7))
+	msg.Set(desc.Fields().ByName("filter"), protoreflect.ValueOfString("age >= 18"))
+	req, err := grpcx.BindPagination(msg, p)
+	if err != nil {
+		t.Fatalf("BindPagination() error = %v", err)
+	}
+	if req.Size != 7 || req.Page != 1 || len(req.Filter) != 1 || req.OrderBy() != "created_at DESC, id ASC" {
+		t.Fatalf("request = %+v", req)
+	}
+
+	token, err := p.NextPageToken(req, "2024-01-01T00:00:00Z", 9)
+	if err != nil {
+		t.Fatalf("NextPageToken() error = %v", err)
+	}
+	msg.Set(desc.Fields().ByName("page_token"), protoreflect.ValueOfString(token))
+	if req, err = grpcx.BindPagination(msg, p); err != nil || req.Cursor == nil {
+		t.Fatalf("BindPagination(token) = %+v, %v", req, err)
+	}
+
+	msg.Set(desc.Fields().ByName("order_by"), protoreflect.ValueOfString("secret_column"))
+	_, err = grpcx.BindPagination(msg, p)
+	st, _ := status.FromError(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %v, want InvalidArgument", st.Code())
+	}
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = badRequest.GetFieldViolations()
+		}
+	}
+	if len(violations) != 1 || violations[0].GetField() != pagination.FieldOrderBy {
+		t.Fatalf("violations = %v", violations)
+	}
+}
@@ -0,0 +1,66 @@
+package grpcx
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// ConnPool spreads calls round-robin over several connections to the same
+// target. Pass it to generated constructors in place of a *grpc.ClientConn.
+type ConnPool struct {
+	conns []*grpc.ClientConn
+	next  atomic.Uint64
+}
+
+var _ grpc.ClientConnInterface = (*ConnPool)(nil)
+
+func (p *ConnPool) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return p.pick().Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream pins the stream to one connection for its lifetime.
+func (p *ConnPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.pick().NewStream(ctx, desc, method, opts...)
+}
+
+// Conns returns the pooled connections; the first is the one Dial returns.
+func (p *ConnPool) Conns() []*grpc.ClientConn {
+	return append([]*grpc.ClientConn(nil), p.conns...)
+}
+
+func (p *ConnPool) pick() *grpc.ClientConn {
+	return p.conns[(p.next.Add(1)-1)%uint64(len(p.conns))]
+}
+
+// DialPool dials PoolSize connections, reusing the one from Dial as the
+// first. The client owns them; Close closes the whole pool.
+func (c *ClientEntity) DialPool() (*ConnPool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pool != nil {
+		return c.pool, nil
+	}
+	if c.conn == nil {
+		conn, err := c.newConn()
+		if err != nil {
+			return nil, err
+		}
+		c.conn = conn
+	}
+	conns := []*grpc.ClientConn{c.conn}
+	for len(conns) < c.PoolSize {
+		conn, err := c.newConn()
+		if err != nil {
+			for _, conn := range conns[1:] {
+				_ = conn.Close()
+			}
+			return nil, err
+		}
+		conns = append(conns, conn)
+	}
+	c.pool = &ConnPool{conns: conns}
+	return c.pool, nil
+}
@@ -0,0 +1,83 @@
+package grpcx_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/grpcx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+func TestClientPoolSpreadsCalls(t *testing.T) {
+	_, _, addr := startHealthServer(t)
+	client := grpcx.NewClient(&grpcx.ClientConfig{Addr: addr, PoolSize: 4, DisableMetrics: true})
+	t.Cleanup(client.Close)
+
+	pool, err := client.DialPool()
+	if err != nil {
+		t.Fatalf("DialPool() error = %v", err)
+	}
+	if again, _ := client.DialPool(); again != pool {
+		t.Fatal("DialPool() dialed a second pool")
+	}
+	if conns := pool.Conns(); len(conns) != 4 || conns[0] != client.Conn() {
+		t.Fatalf("Conns() = %d connections, first shared with Conn() = %v", len(conns), conns[0] == client.Conn())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	healthClient := grpc_health_v1.NewHealthClient(pool)
+	local := map[string]int{}
+	for range 8 {
+		var p peer.Peer
+		if _, err := healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.Peer(&p), grpc.WaitForReady(true)); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		local[p.LocalAddr.String()]++
+	}
+	if len(local) != 4 {
+		t.Fatalf("calls by local addr = %v, want 4 connections used twice", local)
+	}
+	for addr, n := range local {
+		if n != 2 {
+			t.Fatalf("connection %s got %d calls, want 2", addr, n)
+		}
+	}
+}
+
+func BenchmarkClientPool(b *testing.B) {
+	_, _, addr := startHealthServer(b)
+	for _, size := range []int{1, 4} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			client := grpcx.NewClient(&grpcx.ClientConfig{Addr: addr, PoolSize: size, DisableMetrics: true})
+			defer client.Close()
+			pool, err := client.DialPool()
+			if err != nil {
+				b.Fatalf("DialPool() error = %v", err)
+			}
+			healthClient := grpc_health_v1.NewHealthClient(pool)
+			req := &grpc_health_v1.HealthCheckRequest{}
+			ctx := context.Background()
+			for _, conn := range pool.Conns() {
+				if err := grpcx.WaitForReady(ctx, conn); err != nil {
+					b.Fatalf("WaitForReady() error = %v", err)
+				}
+			}
+
+			b.SetParallelism(64)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := healthClient.Check(ctx, req); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/bang-go/micro/pkg/registry"
+)
+
+const deregisterTimeout = 5 * time.Second
+
+func (s *ServerEntity) registerInstance(ctx context.Context, listenAddr net.Addr) error {
+	if s.Registry == nil {
+		return nil
+	}
+	if s.ServiceName == "" {
+		return errors.New("grpcx: service name is required for registry")
+	}
+	addr := s.AdvertiseAddr
+	if addr == "" {
+		var err error
+		if addr, err = advertiseAddr(listenAddr); err != nil {
+			return err
+		}
+	}
+
+	ins := &registry.Instance{Name: s.ServiceName, Addr: addr, Metadata: s.Metadata}
+	if err := s.Registry.Register(ctx, ins); err != nil {
+		return fmt.Errorf("grpcx: register %s: %w", s.ServiceName, err)
+	}
+	s.mu.Lock()
+	s.registered = ins
+	s.mu.Unlock()
+	s.info(ctx, "grpc server registered", "service", s.ServiceName, "addr", addr)
+	return nil
+}
+
+// deregisterInstance removes the registered instance once; later calls are
+// no-ops.
+func (s *ServerEntity) deregisterInstance(ctx context.Context) error {
+	s.mu.Lock()
+	ins := s.registered
+	s.registered = nil
+	s.mu.Unlock()
+	if ins == nil {
+		return nil
+	}
+	return s.Registry.Deregister(ctx, ins)
+}
+
+// advertiseAddr turns a listener address into one peers can dial, replacing a
+// wildcard host with the first non-loopback interface address.
+func advertiseAddr(addr net.Addr) (string, error) {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return "", err
+	}
+	if ip := net.ParseIP(host); ip != nil && !ip.IsUnspecified() {
+		return addr.String(), nil
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	var fallback net.IP
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return net.JoinHostPort(ipNet.IP.String(), port), nil
+		}
+		if fallback == nil {
+			fallback = ipNet.IP
+		}
+	}
+	if fallback == nil {
+		return "", errors.New("grpcx: no address to advertise; set AdvertiseAddr")
+	}
+	return net.JoinHostPort(fallback.String(), port), nil
+}
@@ -0,0 +1,145 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/bang-go/micro/pkg/registry"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+const (
+	staticScheme        = "grpcx-static"
+	discoveryScheme     = "registry"
+	discoveryRetryDelay = time.Second
+)
+
+var (
+	ErrClientTargetConflict = errors.New("grpcx: only one of Addr, Addrs and Discovery may be set")
+	ErrEmptyClientAddr      = errors.New("grpcx: client addrs must not be empty")
+)
+
+type metadataKey struct{}
+
+// instanceMetadata is comparable through Equal, as resolver attributes require.
+type instanceMetadata map[string]string
+
+func (m instanceMetadata) Equal(other any) bool {
+	o, ok := other.(instanceMetadata)
+	return ok && maps.Equal(m, o)
+}
+
+// DiscoveryConfig resolves the client target from a registry instead of Addr.
+type DiscoveryConfig struct {
+	Registry registry.Registry
+	// Service is the registered service name to dial.
+	Service string
+}
+
+// NewResolverBuilder returns a gRPC resolver for reg under scheme, so plain
+// grpc.NewClient("<scheme>:///<service>", grpc.WithResolvers(b)) can use it.
+// ClientConfig.Discovery wires one up automatically.
+func NewResolverBuilder(scheme string, reg registry.Registry) resolver.Builder {
+	if scheme == "" {
+		scheme = discoveryScheme
+	}
+	return &resolverBuilder{scheme: scheme, registry: reg}
+}
+
+// InstanceMetadata returns the registry metadata attached to a resolved
+// address, for custom balancers and pickers.
+func InstanceMetadata(addr resolver.Address) map[string]string {
+	metadata, _ := addr.BalancerAttributes.Value(metadataKey{}).(instanceMetadata)
+	return metadata
+}
+
+type resolverBuilder struct {
+	scheme   string
+	registry registry.Registry
+}
+
+func (b *resolverBuilder) Scheme() string {
+	return b.scheme
+}
+
+func (b *resolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	if b.registry == nil {
+		return nil, errors.New("grpcx: discovery registry is required")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher, err := b.registry.Watch(ctx, target.Endpoint())
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	r := &registryResolver{cc: cc, watcher: watcher, ctx: ctx, cancel: cancel, done: make(chan struct{})}
+	go r.watch()
+	return r, nil
+}
+
+type registryResolver struct {
+	cc      resolver.ClientConn
+	watcher registry.Watcher
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func (r *registryResolver) watch() {
+	defer close(r.done)
+	for {
+		instances, err := r.watcher.Next()
+		if err != nil {
+			if r.ctx.Err() != nil || errors.Is(err, registry.ErrWatcherStopped) {
+				return
+			}
+			r.cc.ReportError(err)
+			select {
+			case <-time.After(discoveryRetryDelay):
+				continue
+			case <-r.ctx.Done():
+				return
+			}
+		}
+
+		addrs := make([]resolver.Address, 0, len(instances))
+		for _, ins := range instances {
+			addr := resolver.Address{Addr: ins.Addr}
+			if len(ins.Metadata) > 0 {
+				addr.BalancerAttributes = attributes.New(metadataKey{}, instanceMetadata(ins.Metadata))
+			}
+			addrs = append(addrs, addr)
+		}
+		_ = r.cc.UpdateState(resolver.State{Addresses: addrs})
+	}
+}
+
+// ResolveNow is a no-op: the watcher pushes every change.
+func (r *registryResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *registryResolver) Close() {
+	r.cancel()
+	_ = r.watcher.Stop()
+	<-r.done
+}
+
+// staticResolver serves a fixed address list, so the balancer can fail over
+// between them like it does for discovered instances.
+func staticResolver(addrs []string) (resolver.Builder, error) {
+	state := resolver.State{Addresses: make([]resolver.Address, 0, len(addrs))}
+	for _, addr := range addrs {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			return nil, ErrEmptyClientAddr
+		}
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: addr})
+	}
+	builder := manual.NewBuilderWithScheme(staticScheme)
+	builder.InitialState(state)
+	return builder, nil
+}
@@ -2,18 +2,25 @@ package grpcx
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"runtime/debug"
 	"sync"
+	"time"
 
+	"github.com/bang-go/micro/pkg/budget"
+	"github.com/bang-go/micro/pkg/healthcheck"
+	"github.com/bang-go/micro/pkg/registry"
 	"github.com/bang-go/micro/telemetry/logger"
 	serverinterceptor "github.com/bang-go/micro/transport/grpcx/server_interceptor"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
@@ -25,21 +32,35 @@ type Server interface {
 	AddServerOptions(serverOption ...grpc.ServerOption)
 	AddUnaryInterceptor(interceptor ...grpc.UnaryServerInterceptor)
 	AddStreamInterceptor(interceptor ...grpc.StreamServerInterceptor)
+	Use(middleware ...Middleware) error
+	UseBefore(name string, middleware ...Middleware) error
+	UseAfter(name string, middleware ...Middleware) error
+	Middlewares() []string
 	Start(context.Context, ServerRegisterFunc) error
 	Engine() *grpc.Server
+	SetServing(service string)
+	SetNotServing(service string)
 	Shutdown(context.Context) error
 }
 
 type ServerEntity struct {
 	*ServerConfig
-	serverOptions      []grpc.ServerOption
-	streamInterceptors []grpc.StreamServerInterceptor
-	unaryInterceptors  []grpc.UnaryServerInterceptor
-	grpcServer         *grpc.Server
-	healthServer       *health.Server
-	listener           net.Listener
-	mu                 sync.RWMutex
-	running            bool
+	serverOptions []grpc.ServerOption
+	middlewares   []Middleware
+	grpcServer    *grpc.Server
+	healthServer  *health.Server
+	healthStatus  map[string]grpc_health_v1.HealthCheckResponse_ServingStatus
+	tracker       *connTracker
+	gateway       *gateway
+	listener      net.Listener
+	registered    *registry.Instance
+	mu            sync.RWMutex
+	running       bool
+
+	// A manual SetNotServing("") holds "" down until SetServing("") clears
+	// it; the Health checker alone cannot lift it.
+	manualDown  bool
+	checkerDown bool
 }
 
 type ServerRegisterFunc func(*grpc.Server)
@@ -55,9 +76,62 @@ type ServerConfig struct {
 	EnableLogger               bool
 	//TraceFilter grpctrace.Filter
 
+	// Propagators overrides the global propagator, e.g. to accept and emit B3
+	// next to W3C metadata; see trace.NewPropagator.
+	Propagators propagation.TextMapPropagator
+
+	// TLS 以证书文件或 PEM 声明 TLS/mTLS，支持证书热加载；需要完全控制时使用 TLSConfig，两者不能同时设置。
+	TLS       *ServerTLSConfig
+	TLSConfig *tls.Config
+
 	// ObservabilitySkipMethods 跳过可观测性记录（Metrics & Trace）的方法列表
-	// 默认为 /grpc.health.v1.Health/Check, /grpc.health.v1.Health/Watch。用户配置将与默认值合并。
+	// 默认为 /grpc.health.v1.Health/Check, /grpc.health.v1.Health/Watch，开启反射和 channelz 时还包括它们的方法。用户配置将与默认值合并。
 	ObservabilitySkipMethods []string
+
+	// Audit 开启请求/响应报文审计日志，默认关闭。
+	Audit *serverinterceptor.AuditConfig
+
+	// Limit 拒绝超大或结构异常（嵌套过深、字段过多、repeated 过长）的请求报文，默认关闭。
+	Limit *serverinterceptor.LimitConfig
+
+	// Registry 设置后，Start 开始监听时把服务以 ServiceName 注册，Shutdown 时先注销再停止。
+	Registry    registry.Registry
+	ServiceName string
+	// AdvertiseAddr 注册到注册中心的地址，默认取监听地址；监听在通配地址时使用本机首个非回环 IP。
+	AdvertiseAddr string
+	// Metadata 随实例注册的元数据，例如版本、机房。
+	Metadata map[string]string
+
+	// EnableReflection 注册 gRPC 反射服务，便于 grpcurl 等工具调试；会暴露全部接口定义，建议仅在非生产环境开启。
+	EnableReflection bool
+	// EnableChannelz 通过 grpc/admin 注册 channelz 等管理服务，用于排查连接与通道状态。
+	EnableChannelz bool
+
+	// ShutdownTimeout 在 Shutdown 的 ctx 没有 deadline 时限制优雅停止的时长，超时后强制关闭剩余连接，默认 10s。
+	ShutdownTimeout time.Duration
+
+	// Transport 以类型化字段设置报文大小、keepalive 与连接寿命，并带默认值和校验；不能与 KeepaliveParams / KeepaliveEnforcementPolicy 同时设置。
+	Transport *ServerTransportConfig
+
+	// Auth 校验调用方凭证（JWT、API Key 或自定义 AuthFunc），健康检查始终放行，默认关闭。
+	Auth *serverinterceptor.AuthConfig
+
+	// Validate 在进入 handler 前执行 protoc-gen-validate 生成的校验方法或自定义 Validator，失败返回 INVALID_ARGUMENT 与字段明细，默认关闭。
+	Validate *serverinterceptor.ValidateConfig
+
+	// Gateway 通过 grpc-gateway 在独立 HTTP 端口提供 REST 接口，请求经进程内连接进入本服务，共用全部拦截器，默认关闭。
+	Gateway *GatewayConfig
+
+	// Health 设置后在启动时及每隔 HealthInterval（默认 5s）评估组件检查，据此切换整个服务（""）的健康状态；关键检查失败时为 NOT_SERVING。
+	Health         *healthcheck.Checker
+	HealthInterval time.Duration
+
+	// DefaultTimeout 客户端未携带 deadline 时为一元调用设置的超时，客户端自带的 deadline 不受影响，默认不设置。
+	DefaultTimeout time.Duration
+	// SlowThreshold 一元调用耗时超过该值时记录 Warn 日志（含方法、对端与 trace ID）并计入 grpc_server_slow_requests_total，默认关闭。
+	SlowThreshold time.Duration
+	// DeadlineBudget 为携带 deadline 的调用附加超时预算，httpx、gormx、redisx 的下游调用超时会被限制在剩余时间的 Fraction（默认 0.8）以内、不低于 Min（默认 10ms），默认关闭。
+	DeadlineBudget *budget.Policy
 }
 
 func NewServer(conf *ServerConfig) Server {
@@ -69,8 +143,7 @@ func NewServer(conf *ServerConfig) Server {
 	}
 
 	// Prepare Skip Methods (Default + User Config)
-	skipMethods := []string{"/grpc.health.v1.Health/Check", "/grpc.health.v1.Health/Watch"}
-	skipMethods = append(skipMethods, conf.ObservabilitySkipMethods...)
+	skipMethods := observabilitySkipMethods(conf)
 
 	var metrics *serverinterceptor.Metrics
 	if !conf.DisableMetrics {
@@ -80,43 +153,168 @@ func NewServer(conf *ServerConfig) Server {
 		}
 	}
 
+	recoverPanic := func(ctx context.Context, p any) error {
+		conf.Logger.Error(ctx, "[Recovery from panic]", "error", p, "stack", string(debug.Stack()))
+		return status.Error(codes.Internal, "grpcx: recovered from panic")
+	}
+
 	// Default Interceptors for Enterprise Production
-	unaryInterceptors := []grpc.UnaryServerInterceptor{
+	middlewares := []Middleware{{
 		// 1. Recovery
-		serverinterceptor.UnaryServerRecoveryInterceptor(func(ctx context.Context, p any) error {
-			conf.Logger.Error(ctx, "[Recovery from panic]", "error", p, "stack", string(debug.Stack()))
-			return status.Error(codes.Internal, "grpcx: recovered from panic")
-		}),
-	}
+		Name:   MiddlewareRecovery,
+		Unary:  serverinterceptor.UnaryServerRecoveryInterceptor(recoverPanic),
+		Stream: serverinterceptor.StreamServerRecoveryInterceptor(recoverPanic),
+	}}
+	// 2. Metrics
 	if !conf.DisableMetrics {
-		unaryInterceptors = append(unaryInterceptors, serverinterceptor.UnaryServerMetricInterceptorWithMetrics(metrics, skipMethods...))
+		middlewares = append(middlewares, Middleware{
+			Name:   MiddlewareMetrics,
+			Unary:  serverinterceptor.UnaryServerMetricInterceptorWithMetrics(metrics, skipMethods...),
+			Stream: serverinterceptor.StreamServerMetricInterceptorWithMetrics(metrics, skipMethods...),
+		})
 	}
 	// 3. Access Logger
 	if conf.EnableLogger {
-		unaryInterceptors = append(unaryInterceptors, serverinterceptor.UnaryServerLoggerInterceptor(conf.Logger, skipMethods...))
+		middlewares = append(middlewares, Middleware{
+			Name:   MiddlewareLogger,
+			Unary:  serverinterceptor.UnaryServerLoggerInterceptor(conf.Logger, skipMethods...),
+			Stream: serverinterceptor.StreamServerLoggerInterceptor(conf.Logger, skipMethods...),
+		})
 	}
-
-	streamInterceptors := []grpc.StreamServerInterceptor{
-		// 1. Recovery
-		serverinterceptor.StreamServerRecoveryInterceptor(func(ctx context.Context, p any) error {
-			conf.Logger.Error(ctx, "[Recovery from panic]", "error", p, "stack", string(debug.Stack()))
-			return status.Error(codes.Internal, "grpcx: recovered from panic")
-		}),
+	// 4. Slow Request Log
+	if conf.SlowThreshold > 0 {
+		middlewares = append(middlewares, Middleware{
+			Name: MiddlewareSlowLog,
+			Unary: serverinterceptor.UnaryServerSlowLogInterceptor(&serverinterceptor.SlowLogConfig{
+				Threshold:         conf.SlowThreshold,
+				Logger:            conf.Logger,
+				SkipMethods:       skipMethods,
+				MetricsRegisterer: conf.MetricsRegisterer,
+				DisableMetrics:    conf.DisableMetrics,
+			}),
+		})
 	}
-	if !conf.DisableMetrics {
-		streamInterceptors = append(streamInterceptors, serverinterceptor.StreamServerMetricInterceptorWithMetrics(metrics, skipMethods...))
+	// 5. Default Deadline
+	if conf.DefaultTimeout > 0 {
+		middlewares = append(middlewares, Middleware{
+			Name:  MiddlewareTimeout,
+			Unary: serverinterceptor.UnaryServerDefaultTimeoutInterceptor(conf.DefaultTimeout),
+		})
 	}
-	// 3. Access Logger
-	if conf.EnableLogger {
-		streamInterceptors = append(streamInterceptors, serverinterceptor.StreamServerLoggerInterceptor(conf.Logger, skipMethods...))
+	// 6. Deadline Budget
+	if conf.DeadlineBudget != nil {
+		middlewares = append(middlewares, Middleware{
+			Name:   MiddlewareBudget,
+			Unary:  serverinterceptor.UnaryServerDeadlineBudgetInterceptor(*conf.DeadlineBudget),
+			Stream: serverinterceptor.StreamServerDeadlineBudgetInterceptor(*conf.DeadlineBudget),
+		})
+	}
+	// 7. Authentication
+	if auth := authConfig(conf); auth != nil {
+		middlewares = append(middlewares, Middleware{
+			Name:   MiddlewareAuth,
+			Unary:  serverinterceptor.UnaryServerAuthInterceptor(auth),
+			Stream: serverinterceptor.StreamServerAuthInterceptor(auth),
+		})
+	}
+	// 8. Payload Limits
+	if limit := limitConfig(conf); limit != nil {
+		middlewares = append(middlewares, Middleware{
+			Name:   MiddlewareLimit,
+			Unary:  serverinterceptor.UnaryServerLimitInterceptor(limit),
+			Stream: serverinterceptor.StreamServerLimitInterceptor(limit),
+		})
+	}
+	// 9. Request Validation
+	if validate := validateConfig(conf); validate != nil {
+		middlewares = append(middlewares, Middleware{
+			Name:   MiddlewareValidate,
+			Unary:  serverinterceptor.UnaryServerValidateInterceptor(validate),
+			Stream: serverinterceptor.StreamServerValidateInterceptor(validate),
+		})
+	}
+	// 10. Payload Audit
+	if audit := auditConfig(conf, skipMethods); audit != nil {
+		middlewares = append(middlewares, Middleware{
+			Name:   MiddlewareAudit,
+			Unary:  serverinterceptor.UnaryServerAuditInterceptor(audit),
+			Stream: serverinterceptor.StreamServerAuditInterceptor(audit),
+		})
 	}
 
 	return &ServerEntity{
-		ServerConfig:       conf,
-		serverOptions:      nil,
-		streamInterceptors: streamInterceptors,
-		unaryInterceptors:  unaryInterceptors,
+		ServerConfig: conf,
+		middlewares:  middlewares,
+	}
+}
+
+func auditConfig(conf *ServerConfig, skipMethods []string) *serverinterceptor.AuditConfig {
+	if conf.Audit == nil {
+		return nil
+	}
+	cloned := *conf.Audit
+	if cloned.Logger == nil {
+		cloned.Logger = conf.Logger
+	}
+	cloned.SkipMethods = append(append([]string(nil), skipMethods...), cloned.SkipMethods...)
+	return &cloned
+}
+
+func authConfig(conf *ServerConfig) *serverinterceptor.AuthConfig {
+	if conf.Auth == nil {
+		return nil
+	}
+	cloned := *conf.Auth
+	cloned.SkipMethods = append(append([]string(nil), healthMethods...), cloned.SkipMethods...)
+	return &cloned
+}
+
+func validateConfig(conf *ServerConfig) *serverinterceptor.ValidateConfig {
+	if conf.Validate == nil {
+		return nil
+	}
+	cloned := *conf.Validate
+	cloned.SkipMethods = append(append([]string(nil), healthMethods...), cloned.SkipMethods...)
+	return &cloned
+}
+
+func limitConfig(conf *ServerConfig) *serverinterceptor.LimitConfig {
+	if conf.Limit == nil {
+		return nil
+	}
+	cloned := *conf.Limit
+	if cloned.MetricsRegisterer == nil {
+		cloned.MetricsRegisterer = conf.MetricsRegisterer
+	}
+	cloned.DisableMetrics = cloned.DisableMetrics || conf.DisableMetrics
+	return &cloned
+}
+
+func resolveServerTLSConfig(conf *ServerConfig) (*tls.Config, error) {
+	if conf.TLSConfig != nil && conf.TLS != nil {
+		return nil, ErrTLSConfigConflict
+	}
+	if conf.TLSConfig != nil {
+		return conf.TLSConfig.Clone(), nil
+	}
+	if conf.TLS != nil {
+		return conf.TLS.Build()
+	}
+	return nil, nil
+}
+
+func serverTransportOptions(conf *ServerConfig) ([]grpc.ServerOption, error) {
+	if conf.Transport == nil {
+		return nil, nil
+	}
+	if conf.KeepaliveParams != nil || conf.KeepaliveEnforcementPolicy != nil {
+		return nil, ErrKeepaliveConflict
 	}
+	var limitBytes int
+	if conf.Limit != nil {
+		limitBytes = conf.Limit.MaxMessageBytes
+	}
+	return conf.Transport.options(limitBytes)
 }
 
 func (s *ServerEntity) AddServerOptions(serverOption ...grpc.ServerOption) {
@@ -128,13 +326,17 @@ func (s *ServerEntity) AddServerOptions(serverOption ...grpc.ServerOption) {
 func (s *ServerEntity) AddUnaryInterceptor(interceptor ...grpc.UnaryServerInterceptor) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.unaryInterceptors = append(s.unaryInterceptors, interceptor...)
+	for _, i := range interceptor {
+		s.middlewares = append(s.middlewares, Middleware{Unary: i})
+	}
 }
 
 func (s *ServerEntity) AddStreamInterceptor(interceptor ...grpc.StreamServerInterceptor) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.streamInterceptors = append(s.streamInterceptors, interceptor...)
+	for _, i := range interceptor {
+		s.middlewares = append(s.middlewares, Middleware{Stream: i})
+	}
 }
 
 func (s *ServerEntity) Start(ctx context.Context, register ServerRegisterFunc) (err error) {
@@ -147,6 +349,14 @@ func (s *ServerEntity) Start(ctx context.Context, register ServerRegisterFunc) (
 	if err := ctx.Err(); err != nil {
 		return err
 	}
+	tlsConfig, err := resolveServerTLSConfig(s.ServerConfig)
+	if err != nil {
+		return err
+	}
+	transportOptions, err := serverTransportOptions(s.ServerConfig)
+	if err != nil {
+		return err
+	}
 
 	s.mu.Lock()
 	if s.running {
@@ -172,8 +382,7 @@ func (s *ServerEntity) Start(ctx context.Context, register ServerRegisterFunc) (
 	s.listener = lis
 	s.running = true
 
-	unaryInterceptors := append([]grpc.UnaryServerInterceptor(nil), s.unaryInterceptors...)
-	streamInterceptors := append([]grpc.StreamServerInterceptor(nil), s.streamInterceptors...)
+	unaryInterceptors, streamInterceptors := chainMiddlewares(s.middlewares)
 	serverOptions := append([]grpc.ServerOption(nil), s.serverOptions...)
 	s.mu.Unlock()
 
@@ -184,6 +393,8 @@ func (s *ServerEntity) Start(ctx context.Context, register ServerRegisterFunc) (
 		s.listener = nil
 		s.grpcServer = nil
 		s.healthServer = nil
+		s.tracker = nil
+		s.gateway = nil
 		s.mu.Unlock()
 
 		if !serveFinished && lis != nil {
@@ -191,21 +402,30 @@ func (s *ServerEntity) Start(ctx context.Context, register ServerRegisterFunc) (
 		}
 	}()
 
-	baseOptions := make([]grpc.ServerOption, 0, len(serverOptions)+4)
+	tracker := &connTracker{}
+	baseOptions := make([]grpc.ServerOption, 0, len(serverOptions)+6)
+	baseOptions = append(baseOptions, grpc.StatsHandler(tracker))
+	if tlsConfig != nil {
+		baseOptions = append(baseOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
 	if s.KeepaliveEnforcementPolicy != nil {
 		baseOptions = append(baseOptions, grpc.KeepaliveEnforcementPolicy(*s.KeepaliveEnforcementPolicy))
 	}
 	if s.KeepaliveParams != nil {
 		baseOptions = append(baseOptions, grpc.KeepaliveParams(*s.KeepaliveParams))
 	}
+	if transportOptions != nil {
+		baseOptions = append(baseOptions, transportOptions...)
+	} else if s.Limit != nil && s.Limit.MaxMessageBytes > 0 {
+		baseOptions = append(baseOptions, grpc.MaxRecvMsgSize(s.Limit.MaxMessageBytes))
+	}
 
 	if s.Trace {
 		// Prepare Skip Methods (Default + User Config)
-		skipMethods := []string{"/grpc.health.v1.Health/Check", "/grpc.health.v1.Health/Watch"}
-		skipMethods = append(skipMethods, s.ObservabilitySkipMethods...)
+		skipMethods := observabilitySkipMethods(s.ServerConfig)
 
 		// Trace StatsHandler
-		baseOptions = append(baseOptions, grpc.StatsHandler(otelgrpc.NewServerHandler(
+		baseOptions = append(baseOptions, grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpcOptions(s.Propagators,
 			otelgrpc.WithFilter(func(info *stats.RPCTagInfo) bool {
 				for _, m := range skipMethods {
 					if info.FullMethodName == m {
@@ -214,7 +434,7 @@ func (s *ServerEntity) Start(ctx context.Context, register ServerRegisterFunc) (
 				}
 				return true
 			}),
-		)))
+		)...)))
 	}
 
 	serverOptions = append(baseOptions, serverOptions...)
@@ -229,17 +449,51 @@ func (s *ServerEntity) Start(ctx context.Context, register ServerRegisterFunc) (
 	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
 
+	cleanupDebug, err := registerDebugServices(grpcServer, s.ServerConfig)
+	if err != nil {
+		return fmt.Errorf("grpcx: register admin services: %w", err)
+	}
+	defer cleanupDebug()
+
 	s.mu.Lock()
 	s.grpcServer = grpcServer
 	s.healthServer = healthServer
+	for service, status := range s.healthStatus {
+		healthServer.SetServingStatus(service, status)
+	}
+	s.tracker = tracker
 	s.mu.Unlock()
 
 	register(grpcServer)
 
+	if s.Gateway != nil {
+		gw, err := newGateway(ctx, s.ServerConfig, grpcServer, tlsConfig)
+		if err != nil {
+			return err
+		}
+		defer gw.close()
+		s.mu.Lock()
+		s.gateway = gw
+		s.mu.Unlock()
+	}
+
+	if err := s.registerInstance(ctx, lis.Addr()); err != nil {
+		return err
+	}
+	defer func() {
+		deregisterCtx, cancel := context.WithTimeout(context.Background(), deregisterTimeout)
+		defer cancel()
+		_ = s.deregisterInstance(deregisterCtx)
+	}()
+
 	s.info(ctx, "grpc server starting", "addr", lis.Addr().String())
 
 	serverDone := make(chan struct{})
 	defer close(serverDone)
+	if s.Health != nil {
+		healthy := s.syncHealth(ctx, true)
+		go s.watchHealth(ctx, serverDone, healthy)
+	}
 	go func() {
 		select {
 		case <-ctx.Done():
@@ -262,18 +516,98 @@ func (s *ServerEntity) Engine() *grpc.Server {
 	return s.grpcServer
 }
 
+// SetServing marks service as SERVING on the health server; "" is the
+// server as a whole. Statuses set before Start are applied once it runs.
+// With Health set, "" stays NOT_SERVING while a critical check fails.
+func (s *ServerEntity) SetServing(service string) {
+	s.setManualHealth(service, false)
+}
+
+// SetNotServing marks service as NOT_SERVING so health-checking clients
+// stop sending it traffic. For "" it overrides Health until SetServing("").
+func (s *ServerEntity) SetNotServing(service string) {
+	s.setManualHealth(service, true)
+}
+
+func (s *ServerEntity) setManualHealth(service string, down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if service == "" {
+		s.manualDown = down
+		s.applyServerHealthLocked()
+		return
+	}
+	s.setHealthStatusLocked(service, servingStatus(!down))
+}
+
+// setCheckerHealth records the latest Health evaluation for "".
+func (s *ServerEntity) setCheckerHealth(healthy bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkerDown = !healthy
+	s.applyServerHealthLocked()
+}
+
+func (s *ServerEntity) applyServerHealthLocked() {
+	s.setHealthStatusLocked("", servingStatus(!s.manualDown && !s.checkerDown))
+}
+
+func (s *ServerEntity) setHealthStatusLocked(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	if s.healthStatus == nil {
+		s.healthStatus = make(map[string]grpc_health_v1.HealthCheckResponse_ServingStatus)
+	}
+	s.healthStatus[service] = status
+	if s.healthServer != nil {
+		s.healthServer.SetServingStatus(service, status)
+	}
+}
+
+func servingStatus(serving bool) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if serving {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+}
+
+// Shutdown marks every service NOT_SERVING, deregisters and drains in-flight
+// RPCs, force-stopping the rest when ctx expires. A ctx without a deadline is
+// bounded by ShutdownTimeout, 10s by default, rather than waiting forever on
+// long-lived streams; pass a ctx with a longer deadline to wait longer.
 func (s *ServerEntity) Shutdown(ctx context.Context) error {
 	if ctx == nil {
 		return errors.New("grpcx: context is required")
 	}
 
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := s.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = defaultShutdownTimeout
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	s.mu.RLock()
 	healthServer := s.healthServer
 	grpcServer := s.grpcServer
+	tracker := s.tracker
+	gw := s.gateway
 	s.mu.RUnlock()
 
 	if healthServer != nil {
-		healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		// Flips every service to NOT_SERVING and ignores later updates.
+		healthServer.Shutdown()
+	}
+	// Leave the registry first so clients stop picking this instance while
+	// in-flight calls drain.
+	if err := s.deregisterInstance(ctx); err != nil {
+		s.Logger.Warn(ctx, "grpc server deregister failed", "error", err)
+	}
+	if gw != nil {
+		if err := gw.shutdown(ctx); err != nil {
+			s.Logger.Warn(ctx, "grpc gateway shutdown failed", "error", err)
+		}
 	}
 	if grpcServer == nil {
 		return nil
@@ -289,7 +623,14 @@ func (s *ServerEntity) Shutdown(ctx context.Context) error {
 	select {
 	case <-done:
 	case <-ctx.Done():
+		// Streams that never finish would block GracefulStop forever.
+		var conns, rpcs int64
+		if tracker != nil {
+			conns, rpcs = tracker.conns.Load(), tracker.rpcs.Load()
+		}
 		grpcServer.Stop()
+		<-done
+		s.Logger.Warn(ctx, "grpc server forced stop", "connections", conns, "rpcs", rpcs, "error", ctx.Err())
 		return ctx.Err()
 	}
 
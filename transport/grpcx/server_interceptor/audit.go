@@ -0,0 +1,71 @@
+package grpcx
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bang-go/micro/transport/grpcx/internal/audit"
+	"google.golang.org/grpc"
+)
+
+// AuditConfig controls payload audit logging. Fields marked with the
+// debug_redact option are always redacted; RedactFields adds more by field
+// name ("password") or full name ("user.v1.User.password").
+type AuditConfig = audit.Config
+
+func UnaryServerAuditInterceptor(conf *AuditConfig) grpc.UnaryServerInterceptor {
+	a := audit.New("server", conf)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !a.ShouldAudit(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		a.Log(ctx, info.FullMethod, "request", req, "", nil)
+		resp, err := handler(ctx, req)
+		a.Log(ctx, info.FullMethod, "response", resp, rpcStatusCode(err).String(), err)
+		return resp, err
+	}
+}
+
+func StreamServerAuditInterceptor(conf *AuditConfig) grpc.StreamServerInterceptor {
+	a := audit.New("server", conf)
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !a.ShouldAudit(info.FullMethod) {
+			return handler(srv, stream)
+		}
+		return handler(srv, &auditServerStream{ServerStream: stream, auditor: a, method: info.FullMethod})
+	}
+}
+
+type auditServerStream struct {
+	grpc.ServerStream
+	auditor *audit.Auditor
+	method  string
+}
+
+func (s *auditServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.auditor.Log(s.Context(), s.method, "request", m, "", nil)
+	}
+	return err
+}
+
+func (s *auditServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	s.auditor.Log(s.Context(), s.method, "response", m, rpcStatusCode(err).String(), err)
+	return err
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			set[value] = struct{}{}
+		}
+	}
+	return set
+}
@@ -0,0 +1,134 @@
+package grpcx_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	serverinterceptor "github.com/bang-go/micro/transport/grpcx/server_interceptor"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func newAuditLogger(buf *bytes.Buffer) *logger.Logger {
+	return logger.New(
+		logger.WithFormat("text"),
+		logger.WithAddSource(false),
+		logger.WithOutput(buf),
+	)
+}
+
+func TestUnaryServerAuditInterceptorRedactsConfiguredFields(t *testing.T) {
+	var logs bytes.Buffer
+	interceptor := serverinterceptor.UnaryServerAuditInterceptor(&serverinterceptor.AuditConfig{
+		Logger:       newAuditLogger(&logs),
+		SampleRate:   1,
+		RedactFields: []string{"json_name", "google.protobuf.DescriptorProto.name"},
+	})
+
+	req := &descriptorpb.DescriptorProto{
+		Name: proto.String("User"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{Name: proto.String("password"), JsonName: proto.String("secretJson")},
+		},
+	}
+	_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/svc/Create"}, func(context.Context, any) (any, error) {
+		return &descriptorpb.FieldDescriptorProto{Name: proto.String("ok")}, nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor error = %v", err)
+	}
+
+	output := logs.String()
+	if strings.Contains(output, "secretJson") || strings.Contains(output, `\"User\"`) {
+		t.Fatalf("redacted values leaked: %s", output)
+	}
+	if !strings.Contains(output, "[REDACTED]") || !strings.Contains(output, "password") {
+		t.Fatalf("expected redacted payload with remaining fields: %s", output)
+	}
+	if !strings.Contains(output, "direction=request") || !strings.Contains(output, "direction=response") {
+		t.Fatalf("expected request and response audit entries: %s", output)
+	}
+	if req.GetName() != "User" || req.GetField()[0].GetJsonName() != "secretJson" {
+		t.Fatal("original request was modified")
+	}
+}
+
+func TestUnaryServerAuditInterceptorHonorsDebugRedact(t *testing.T) {
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("audit_test.proto"),
+		Package: proto.String("audit.test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Login"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("user"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				{
+					Name:    proto.String("token"),
+					Number:  proto.Int32(2),
+					Type:    descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					Label:   descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Options: &descriptorpb.FieldOptions{DebugRedact: proto.Bool(true)},
+				},
+			},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("build descriptor: %v", err)
+	}
+
+	desc := file.Messages().Get(0)
+	msg := dynamicpb.NewMessage(desc)
+	msg.Set(desc.Fields().ByName("user"), protoreflect.ValueOfString("alice"))
+	msg.Set(desc.Fields().ByName("token"), protoreflect.ValueOfString("s3cr3t"))
+
+	var logs bytes.Buffer
+	interceptor := serverinterceptor.UnaryServerAuditInterceptor(&serverinterceptor.AuditConfig{
+		Logger:     newAuditLogger(&logs),
+		SampleRate: 1,
+	})
+	_, _ = interceptor(context.Background(), msg, &grpc.UnaryServerInfo{FullMethod: "/svc/Login"}, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+
+	output := logs.String()
+	if strings.Contains(output, "s3cr3t") || !strings.Contains(output, "alice") {
+		t.Fatalf("debug_redact field not masked: %s", output)
+	}
+}
+
+func TestUnaryServerAuditInterceptorLimitsAndSampling(t *testing.T) {
+	var logs bytes.Buffer
+	interceptor := serverinterceptor.UnaryServerAuditInterceptor(&serverinterceptor.AuditConfig{
+		Logger:          newAuditLogger(&logs),
+		SampleRate:      1,
+		MaxPayloadBytes: 8,
+		SkipMethods:     []string{"/svc/Skip"},
+	})
+
+	req := &descriptorpb.FieldDescriptorProto{Name: proto.String(strings.Repeat("x", 64))}
+	handler := func(context.Context, any) (any, error) { return nil, nil }
+
+	_, _ = interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/svc/Big"}, handler)
+	if output := logs.String(); !strings.Contains(output, "payload_omitted=too_large") || strings.Contains(output, "xxxxxxxx") {
+		t.Fatalf("oversized payload not omitted: %s", output)
+	}
+
+	logs.Reset()
+	_, _ = interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/svc/Skip"}, handler)
+	if logs.Len() != 0 {
+		t.Fatalf("skipped method was audited: %s", logs.String())
+	}
+
+	disabled := serverinterceptor.UnaryServerAuditInterceptor(&serverinterceptor.AuditConfig{Logger: newAuditLogger(&logs)})
+	_, _ = disabled(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/svc/Big"}, handler)
+	if logs.Len() != 0 {
+		t.Fatalf("audit without sample rate should be disabled: %s", logs.String())
+	}
+}
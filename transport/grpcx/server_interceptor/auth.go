@@ -0,0 +1,156 @@
+package grpcx
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/bang-go/micro/contrib/auth/jwtx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const DefaultAPIKeyHeader = "x-api-key"
+
+// AuthFunc authenticates a call from its incoming metadata and returns the
+// context the handler runs with. Errors without a gRPC status are reported
+// as Unauthenticated.
+type AuthFunc func(ctx context.Context, fullMethod string) (context.Context, error)
+
+type AuthConfig struct {
+	Authenticate AuthFunc
+	SkipMethods  []string
+}
+
+func UnaryServerAuthInterceptor(conf *AuthConfig) grpc.UnaryServerInterceptor {
+	authenticate, skip := authSettings(conf)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if authenticate == nil {
+			return handler(ctx, req)
+		}
+		if _, ok := skip[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+		authCtx, err := authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, authError(err)
+		}
+		return handler(authCtx, req)
+	}
+}
+
+func StreamServerAuthInterceptor(conf *AuthConfig) grpc.StreamServerInterceptor {
+	authenticate, skip := authSettings(conf)
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if authenticate == nil {
+			return handler(srv, stream)
+		}
+		if _, ok := skip[info.FullMethod]; ok {
+			return handler(srv, stream)
+		}
+		authCtx, err := authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return authError(err)
+		}
+		return handler(srv, &authServerStream{ServerStream: stream, ctx: authCtx})
+	}
+}
+
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func authSettings(conf *AuthConfig) (AuthFunc, map[string]struct{}) {
+	if conf == nil {
+		return nil, nil
+	}
+	return conf.Authenticate, toSet(conf.SkipMethods)
+}
+
+func authError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Unauthenticated, err.Error())
+}
+
+type claimsKey[T any] struct{}
+
+// JWTAuth validates the bearer token in the authorization metadata with j
+// and stores the claims for ClaimsFromContext.
+func JWTAuth[T any](j *jwtx.JWT[T]) AuthFunc {
+	return func(ctx context.Context, _ string) (context.Context, error) {
+		token, ok := BearerToken(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "grpcx: missing bearer token")
+		}
+		claims, err := j.ParseContext(ctx, token)
+		switch {
+		case errors.Is(err, jwtx.ErrTokenExpired):
+			return nil, status.Error(codes.Unauthenticated, "grpcx: token expired")
+		case err != nil:
+			return nil, status.Error(codes.Unauthenticated, "grpcx: invalid token")
+		}
+		return context.WithValue(ctx, claimsKey[T]{}, claims), nil
+	}
+}
+
+// ClaimsFromContext returns the claims stored by JWTAuth for payload type T.
+func ClaimsFromContext[T any](ctx context.Context) (*jwtx.Claims[T], bool) {
+	claims, ok := ctx.Value(claimsKey[T]{}).(*jwtx.Claims[T])
+	return claims, ok
+}
+
+// APIKeyAuth accepts calls whose header metadata, DefaultAPIKeyHeader when
+// empty, equals one of keys.
+func APIKeyAuth(header string, keys ...string) AuthFunc {
+	header = strings.ToLower(strings.TrimSpace(header))
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+	accepted := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		if key != "" {
+			accepted = append(accepted, []byte(key))
+		}
+	}
+
+	return func(ctx context.Context, _ string) (context.Context, error) {
+		values := metadata.ValueFromIncomingContext(ctx, header)
+		if len(values) == 0 || values[0] == "" {
+			return nil, status.Error(codes.Unauthenticated, "grpcx: missing api key")
+		}
+		got := []byte(values[0])
+		for _, key := range accepted {
+			if subtle.ConstantTimeCompare(got, key) == 1 {
+				return ctx, nil
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "grpcx: invalid api key")
+	}
+}
+
+// BearerToken returns the token of a "Bearer <token>" authorization metadata
+// value.
+func BearerToken(ctx context.Context) (string, bool) {
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(values) == 0 {
+		return "", false
+	}
+	scheme, token, ok := strings.Cut(strings.TrimSpace(values[0]), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,113 @@
+package grpcx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/contrib/auth/jwtx"
+	serverinterceptor "github.com/bang-go/micro/transport/grpcx/server_interceptor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type authPayload struct {
+	UserID string `json:"user_id"`
+}
+
+func incoming(pairs ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+func TestUnaryServerAuthInterceptorJWT(t *testing.T) {
+	j := jwtx.MustNew[authPayload](&jwtx.Config{SecretKey: "secret"})
+	token, err := j.Generate(authPayload{UserID: "u1"}, jwtx.WithSubject("u1"))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	expired, err := j.Generate(authPayload{}, jwtx.WithIssuedAt(time.Now().Add(-2*time.Hour)), jwtx.WithExpiresAt(time.Now().Add(-time.Hour)))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	interceptor := serverinterceptor.UnaryServerAuthInterceptor(&serverinterceptor.AuthConfig{
+		Authenticate: serverinterceptor.JWTAuth(j),
+		SkipMethods:  []string{"/svc/Public"},
+	})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		claims, ok := serverinterceptor.ClaimsFromContext[authPayload](ctx)
+		if !ok {
+			return "anonymous", nil
+		}
+		return claims.Payload.UserID, nil
+	}
+	call := func(ctx context.Context, method string) (interface{}, error) {
+		return interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	}
+
+	resp, err := call(incoming("authorization", "Bearer "+token), "/svc/Get")
+	if err != nil || resp != "u1" {
+		t.Fatalf("call with token = %v, %v", resp, err)
+	}
+	resp, err = call(context.Background(), "/svc/Public")
+	if err != nil || resp != "anonymous" {
+		t.Fatalf("skipped method = %v, %v", resp, err)
+	}
+
+	for name, ctx := range map[string]context.Context{
+		"missing": context.Background(),
+		"scheme":  incoming("authorization", "Basic "+token),
+		"invalid": incoming("authorization", "Bearer nope"),
+		"expired": incoming("authorization", "Bearer "+expired),
+	} {
+		if _, err := call(ctx, "/svc/Get"); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("%s: error = %v", name, err)
+		}
+	}
+	if _, err := call(incoming("authorization", "Bearer "+expired), "/svc/Get"); status.Convert(err).Message() != "grpcx: token expired" {
+		t.Fatalf("expired message = %v", err)
+	}
+	if _, ok := serverinterceptor.ClaimsFromContext[string](context.Background()); ok {
+		t.Fatal("ClaimsFromContext() found claims in an empty context")
+	}
+}
+
+func TestStreamServerAuthInterceptorAPIKey(t *testing.T) {
+	interceptor := serverinterceptor.StreamServerAuthInterceptor(&serverinterceptor.AuthConfig{
+		Authenticate: serverinterceptor.APIKeyAuth("", "k1", "k2"),
+	})
+	handler := func(interface{}, grpc.ServerStream) error { return nil }
+	call := func(ctx context.Context) error {
+		return interceptor(nil, &testServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/svc/Watch"}, handler)
+	}
+
+	if err := call(incoming("x-api-key", "k2")); err != nil {
+		t.Fatalf("valid key error = %v", err)
+	}
+	if err := call(incoming("x-api-key", "k3")); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("invalid key error = %v", err)
+	}
+	if err := call(context.Background()); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("missing key error = %v", err)
+	}
+
+	denied := serverinterceptor.StreamServerAuthInterceptor(&serverinterceptor.AuthConfig{
+		Authenticate: func(context.Context, string) (context.Context, error) {
+			return nil, status.Error(codes.PermissionDenied, "no")
+		},
+	})
+	if err := denied(nil, &testServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/svc/Watch"}, handler); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("custom status error = %v", err)
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
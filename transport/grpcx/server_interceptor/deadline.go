@@ -0,0 +1,145 @@
+package grpcx
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bang-go/micro/pkg/budget"
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// UnaryServerDefaultTimeoutInterceptor bounds calls that arrive without a
+// deadline, so a client that forgot one cannot pin a handler forever.
+// Deadlines sent by the client are kept, even when longer. Streams are not
+// covered: their lifetime is the session, not a request.
+func UnaryServerDefaultTimeoutInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+		if _, ok := ctx.Deadline(); ok {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// UnaryServerDeadlineBudgetInterceptor attaches policy to calls that carry a
+// deadline, so httpx, gormx and redisx cap their timeouts to a share of the
+// time the caller has left.
+func UnaryServerDeadlineBudgetInterceptor(policy budget.Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return handler(ctx, req)
+		}
+		return handler(budget.WithPolicy(ctx, policy), req)
+	}
+}
+
+func StreamServerDeadlineBudgetInterceptor(policy budget.Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := stream.Context()
+		if _, ok := ctx.Deadline(); !ok {
+			return handler(srv, stream)
+		}
+		return handler(srv, &budgetServerStream{ServerStream: stream, ctx: budget.WithPolicy(ctx, policy)})
+	}
+}
+
+type budgetServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *budgetServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// SlowLogConfig logs unary calls slower than Threshold at warn level with
+// the method, peer and, when tracing is on, the trace ID.
+type SlowLogConfig struct {
+	Threshold   time.Duration
+	Logger      *logger.Logger
+	SkipMethods []string
+
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+}
+
+var (
+	defaultSlowOnce     sync.Once
+	defaultSlowRequests *prometheus.CounterVec
+)
+
+func newSlowRequests() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_server_slow_requests_total",
+			Help: "gRPC server requests slower than the slow log threshold",
+		},
+		[]string{"method"},
+	)
+}
+
+func UnaryServerSlowLogInterceptor(conf *SlowLogConfig) grpc.UnaryServerInterceptor {
+	if conf == nil || conf.Threshold <= 0 {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(ctx, req)
+		}
+	}
+	l := conf.Logger
+	if l == nil {
+		l = logger.New(logger.WithLevel("info"))
+	}
+	skip := toSet(conf.SkipMethods)
+
+	var slowRequests *prometheus.CounterVec
+	switch {
+	case conf.DisableMetrics:
+	case conf.MetricsRegisterer != nil:
+		slowRequests = newSlowRequests()
+		mustRegisterCollector(conf.MetricsRegisterer, &slowRequests, slowRequests)
+	default:
+		defaultSlowOnce.Do(func() {
+			defaultSlowRequests = newSlowRequests()
+			mustRegisterCollector(prometheus.DefaultRegisterer, &defaultSlowRequests, defaultSlowRequests)
+		})
+		slowRequests = defaultSlowRequests
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := skip[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		duration := time.Since(start)
+		if duration < conf.Threshold {
+			return resp, err
+		}
+
+		if slowRequests != nil {
+			slowRequests.WithLabelValues(info.FullMethod).Inc()
+		}
+		peerAddr := ""
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			peerAddr = p.Addr.String()
+		}
+		l.Warn(ctx, "grpc_slow_request",
+			"kind", "server",
+			"method", info.FullMethod,
+			"peer", peerAddr,
+			"code", rpcStatusCode(err).String(),
+			"cost", duration.Seconds(),
+			"threshold", conf.Threshold.Seconds(),
+		)
+		return resp, err
+	}
+}
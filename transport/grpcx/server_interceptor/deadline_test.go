@@ -0,0 +1,100 @@
+package grpcx_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/pkg/budget"
+	serverinterceptor "github.com/bang-go/micro/transport/grpcx/server_interceptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+func TestUnaryServerDefaultTimeoutInterceptor(t *testing.T) {
+	interceptor := serverinterceptor.UnaryServerDefaultTimeoutInterceptor(time.Second)
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Get"}
+	remaining := func(ctx context.Context) time.Duration {
+		var got time.Duration
+		_, _ = interceptor(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+			deadline, ok := ctx.Deadline()
+			if ok {
+				got = time.Until(deadline)
+			}
+			return nil, nil
+		})
+		return got
+	}
+
+	if got := remaining(context.Background()); got <= 0 || got > time.Second {
+		t.Fatalf("default deadline remaining = %v", got)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if got := remaining(ctx); got <= time.Second {
+		t.Fatalf("client deadline was replaced, remaining = %v", got)
+	}
+}
+
+func TestUnaryServerDeadlineBudgetInterceptor(t *testing.T) {
+	interceptor := serverinterceptor.UnaryServerDeadlineBudgetInterceptor(budget.Policy{Fraction: 0.5})
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Get"}
+	capped := func(ctx context.Context) time.Duration {
+		var got time.Duration
+		_, _ = interceptor(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+			got = budget.Timeout(ctx, time.Minute)
+			return nil, nil
+		})
+		return got
+	}
+
+	if got := capped(context.Background()); got != time.Minute {
+		t.Fatalf("timeout without caller deadline = %v, want configured 1m", got)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if got := capped(ctx); got > 500*time.Millisecond || got < 400*time.Millisecond {
+		t.Fatalf("timeout with 1s caller deadline = %v, want about 500ms", got)
+	}
+}
+
+func TestUnaryServerSlowLogInterceptor(t *testing.T) {
+	var logs bytes.Buffer
+	reg := prometheus.NewRegistry()
+	interceptor := serverinterceptor.UnaryServerSlowLogInterceptor(&serverinterceptor.SlowLogConfig{
+		Threshold:         10 * time.Millisecond,
+		Logger:            newAuditLogger(&logs),
+		MetricsRegisterer: reg,
+	})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 7), Port: 5000}})
+	call := func(method string, sleep time.Duration) {
+		_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, any) (any, error) {
+			time.Sleep(sleep)
+			return nil, nil
+		})
+	}
+
+	call("/svc/Fast", 0)
+	call("/svc/Slow", 20*time.Millisecond)
+
+	output := logs.String()
+	if strings.Contains(output, "/svc/Fast") {
+		t.Fatalf("fast call logged: %s", output)
+	}
+	if !strings.Contains(output, "grpc_slow_request") || !strings.Contains(output, "method=/svc/Slow") || !strings.Contains(output, "peer=10.0.0.7:5000") {
+		t.Fatalf("slow call not logged: %s", output)
+	}
+	expected := `
+# HELP grpc_server_slow_requests_total gRPC server requests slower than the slow log threshold
+# TYPE grpc_server_slow_requests_total counter
+grpc_server_slow_requests_total{method="/svc/Slow"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "grpc_server_slow_requests_total"); err != nil {
+		t.Fatal(err)
+	}
+}
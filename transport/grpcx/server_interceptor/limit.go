@@ -0,0 +1,184 @@
+package grpcx
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// LimitConfig rejects decoded request messages whose shape could amplify
+// handler CPU. Byte size is better enforced at decode time with
+// grpc.MaxRecvMsgSize; grpcx.NewServer sets it from MaxMessageBytes. Zero
+// disables the corresponding check.
+type LimitConfig struct {
+	MaxMessageBytes int
+	// MaxDepth bounds nested message depth; the request itself is depth 1.
+	MaxDepth int
+	// MaxFields bounds the total populated fields across all nested messages.
+	MaxFields int
+	// MaxRepeatedLength bounds the elements of any single repeated or map field.
+	MaxRepeatedLength int
+	SkipMethods       []string
+
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+}
+
+var (
+	defaultLimitOnce       sync.Once
+	defaultLimitRejections *prometheus.CounterVec
+)
+
+func newLimitRejections() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_server_limit_rejections_total",
+			Help: "gRPC server requests rejected by message limits",
+		},
+		[]string{"method", "reason"},
+	)
+}
+
+type limiter struct {
+	conf       LimitConfig
+	skip       map[string]struct{}
+	rejections *prometheus.CounterVec
+}
+
+func newLimiter(conf *LimitConfig) *limiter {
+	if conf == nil {
+		return nil
+	}
+	l := &limiter{conf: *conf, skip: toSet(conf.SkipMethods)}
+	switch {
+	case conf.DisableMetrics:
+	case conf.MetricsRegisterer != nil:
+		l.rejections = newLimitRejections()
+		mustRegisterCollector(conf.MetricsRegisterer, &l.rejections, l.rejections)
+	default:
+		defaultLimitOnce.Do(func() {
+			defaultLimitRejections = newLimitRejections()
+			mustRegisterCollector(prometheus.DefaultRegisterer, &defaultLimitRejections, defaultLimitRejections)
+		})
+		l.rejections = defaultLimitRejections
+	}
+	return l
+}
+
+func UnaryServerLimitInterceptor(conf *LimitConfig) grpc.UnaryServerInterceptor {
+	l := newLimiter(conf)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if l != nil {
+			if err := l.check(info.FullMethod, req); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerLimitInterceptor(conf *LimitConfig) grpc.StreamServerInterceptor {
+	l := newLimiter(conf)
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if l == nil {
+			return handler(srv, stream)
+		}
+		return handler(srv, &limitServerStream{ServerStream: stream, limiter: l, method: info.FullMethod})
+	}
+}
+
+type limitServerStream struct {
+	grpc.ServerStream
+	limiter *limiter
+	method  string
+}
+
+func (s *limitServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.limiter.check(s.method, m)
+}
+
+func (l *limiter) check(method string, req interface{}) error {
+	if _, ok := l.skip[method]; ok {
+		return nil
+	}
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	reason := ""
+	if l.conf.MaxMessageBytes > 0 && proto.Size(msg) > l.conf.MaxMessageBytes {
+		reason = "message_size"
+	} else if l.conf.MaxDepth > 0 || l.conf.MaxFields > 0 || l.conf.MaxRepeatedLength > 0 {
+		fields := 0
+		reason = l.walk(msg.ProtoReflect(), 1, &fields)
+	}
+	if reason == "" {
+		return nil
+	}
+
+	if l.rejections != nil {
+		l.rejections.WithLabelValues(method, reason).Inc()
+	}
+	if reason == "message_size" {
+		return status.Error(codes.ResourceExhausted, "grpcx: request message too large")
+	}
+	return status.Errorf(codes.InvalidArgument, "grpcx: request exceeds %s limit", reason)
+}
+
+// walk returns the first violated limit, stopping as soon as one is found.
+func (l *limiter) walk(msg protoreflect.Message, depth int, fields *int) string {
+	if l.conf.MaxDepth > 0 && depth > l.conf.MaxDepth {
+		return "depth"
+	}
+
+	reason := ""
+	msg.Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		*fields++
+		if l.conf.MaxFields > 0 && *fields > l.conf.MaxFields {
+			reason = "fields"
+			return false
+		}
+
+		switch {
+		case fd.IsList():
+			list := value.List()
+			if l.conf.MaxRepeatedLength > 0 && list.Len() > l.conf.MaxRepeatedLength {
+				reason = "repeated_length"
+				return false
+			}
+			if fd.Message() != nil {
+				for i := 0; i < list.Len() && reason == ""; i++ {
+					reason = l.walk(list.Get(i).Message(), depth+1, fields)
+				}
+			}
+		case fd.IsMap():
+			m := value.Map()
+			if l.conf.MaxRepeatedLength > 0 && m.Len() > l.conf.MaxRepeatedLength {
+				reason = "repeated_length"
+				return false
+			}
+			if fd.MapValue().Message() != nil {
+				m.Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+					reason = l.walk(v.Message(), depth+1, fields)
+					return reason == ""
+				})
+			}
+		case fd.Message() != nil:
+			reason = l.walk(value.Message(), depth+1, fields)
+		}
+		return reason == ""
+	})
+	return reason
+}
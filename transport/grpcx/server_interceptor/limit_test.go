@@ -0,0 +1,105 @@
+package grpcx_test
+
+import (
+	"context"
+	"testing"
+
+	serverinterceptor "github.com/bang-go/micro/transport/grpcx/server_interceptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func nestedDescriptor(depth int) *descriptorpb.DescriptorProto {
+	msg := &descriptorpb.DescriptorProto{Name: proto.String("leaf")}
+	for i := 1; i < depth; i++ {
+		msg = &descriptorpb.DescriptorProto{Name: proto.String("node"), NestedType: []*descriptorpb.DescriptorProto{msg}}
+	}
+	return msg
+}
+
+func TestUnaryServerLimitInterceptorRejectsAbusiveMessages(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	interceptor := serverinterceptor.UnaryServerLimitInterceptor(&serverinterceptor.LimitConfig{
+		MaxMessageBytes:   512,
+		MaxDepth:          3,
+		MaxFields:         10,
+		MaxRepeatedLength: 4,
+		SkipMethods:       []string{"/svc/Bulk"},
+		MetricsRegisterer: reg,
+	})
+
+	manyFields := make([]*descriptorpb.FieldDescriptorProto, 4)
+	for i := range manyFields {
+		manyFields[i] = &descriptorpb.FieldDescriptorProto{Name: proto.String("f"), JsonName: proto.String("f"), Number: proto.Int32(int32(i + 1))}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		req    proto.Message
+		code   codes.Code
+	}{
+		{name: "accepted", method: "/svc/Create", req: nestedDescriptor(3), code: codes.OK},
+		{name: "too deep", method: "/svc/Create", req: nestedDescriptor(4), code: codes.InvalidArgument},
+		{name: "long repeated", method: "/svc/Create", req: &descriptorpb.DescriptorProto{ReservedName: []string{"a", "b", "c", "d", "e"}}, code: codes.InvalidArgument},
+		{name: "too many fields", method: "/svc/Create", req: &descriptorpb.DescriptorProto{Name: proto.String("x"), Field: manyFields}, code: codes.InvalidArgument},
+		{name: "too large", method: "/svc/Create", req: &descriptorpb.DescriptorProto{Name: proto.String(string(make([]byte, 600)))}, code: codes.ResourceExhausted},
+		{name: "skipped", method: "/svc/Bulk", req: nestedDescriptor(8), code: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			_, err := interceptor(context.Background(), tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(context.Context, any) (any, error) {
+				called = true
+				return nil, nil
+			})
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("code = %v, want %v (err %v)", got, tt.code, err)
+			}
+			if called != (tt.code == codes.OK) {
+				t.Fatalf("handler called = %v, want %v", called, tt.code == codes.OK)
+			}
+		})
+	}
+
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "grpc_server_limit_rejections_total", Help: "gRPC server requests rejected by message limits"}, []string{"method", "reason"})
+	if err := reg.Register(counter); err == nil {
+		t.Fatal("expected limit rejection counter to be registered")
+	} else if existing, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		counter = existing.ExistingCollector.(*prometheus.CounterVec)
+	}
+	for reason, want := range map[string]float64{"depth": 1, "repeated_length": 1, "fields": 1, "message_size": 1} {
+		if got := testutil.ToFloat64(counter.WithLabelValues("/svc/Create", reason)); got != want {
+			t.Fatalf("grpc_server_limit_rejections_total{reason=%s} = %v, want %v", reason, got, want)
+		}
+	}
+}
+
+type recvStream struct {
+	grpc.ServerStream
+	msg proto.Message
+}
+
+func (s *recvStream) Context() context.Context { return context.Background() }
+
+func (s *recvStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), s.msg)
+	return nil
+}
+
+func TestStreamServerLimitInterceptorChecksEachMessage(t *testing.T) {
+	interceptor := serverinterceptor.StreamServerLimitInterceptor(&serverinterceptor.LimitConfig{MaxDepth: 2, DisableMetrics: true})
+	stream := &recvStream{msg: nestedDescriptor(3)}
+
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/svc/Stream"}, func(_ any, ss grpc.ServerStream) error {
+		return ss.RecvMsg(&descriptorpb.DescriptorProto{})
+	})
+	if got := status.Code(err); got != codes.InvalidArgument {
+		t.Fatalf("code = %v, want %v", got, codes.InvalidArgument)
+	}
+}
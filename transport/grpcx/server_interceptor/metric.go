@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -37,12 +38,28 @@ var (
 		},
 		[]string{"method"},
 	)
+
+	ServerStreamMessages = newStreamMessages()
 )
 
 type Metrics struct {
 	RequestDuration *prometheus.HistogramVec
 	RequestsTotal   *prometheus.CounterVec
 	InFlight        *prometheus.GaugeVec
+	// StreamMessages observes messages sent and received per stream; nil
+	// skips it.
+	StreamMessages *prometheus.HistogramVec
+}
+
+func newStreamMessages() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_server_stream_messages",
+			Help:    "gRPC server messages per stream by direction",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+		},
+		[]string{"method", "direction"},
+	)
 }
 
 var (
@@ -56,13 +73,16 @@ func DefaultMetrics() *Metrics {
 			RequestDuration: ServerRequestDuration,
 			RequestsTotal:   ServerRequestsTotal,
 			InFlight:        ServerInFlight,
+			StreamMessages:  ServerStreamMessages,
 		}
 		mustRegisterCollector(prometheus.DefaultRegisterer, &defaultMetrics.RequestDuration, defaultMetrics.RequestDuration)
 		mustRegisterCollector(prometheus.DefaultRegisterer, &defaultMetrics.RequestsTotal, defaultMetrics.RequestsTotal)
 		mustRegisterCollector(prometheus.DefaultRegisterer, &defaultMetrics.InFlight, defaultMetrics.InFlight)
+		mustRegisterCollector(prometheus.DefaultRegisterer, &defaultMetrics.StreamMessages, defaultMetrics.StreamMessages)
 		ServerRequestDuration = defaultMetrics.RequestDuration
 		ServerRequestsTotal = defaultMetrics.RequestsTotal
 		ServerInFlight = defaultMetrics.InFlight
+		ServerStreamMessages = defaultMetrics.StreamMessages
 	})
 
 	return defaultMetrics
@@ -92,10 +112,12 @@ func NewMetrics(registerer prometheus.Registerer) *Metrics {
 			},
 			[]string{"method"},
 		),
+		StreamMessages: newStreamMessages(),
 	}
 	mustRegisterCollector(registerer, &metrics.RequestDuration, metrics.RequestDuration)
 	mustRegisterCollector(registerer, &metrics.RequestsTotal, metrics.RequestsTotal)
 	mustRegisterCollector(registerer, &metrics.InFlight, metrics.InFlight)
+	mustRegisterCollector(registerer, &metrics.StreamMessages, metrics.StreamMessages)
 	return metrics
 }
 
@@ -156,17 +178,44 @@ func StreamServerMetricInterceptorWithMetrics(metrics *Metrics, skipMethods ...s
 		metrics.InFlight.WithLabelValues(info.FullMethod).Inc()
 		defer metrics.InFlight.WithLabelValues(info.FullMethod).Dec()
 
-		err := handler(srv, stream)
+		counted := &countingServerStream{ServerStream: stream}
+		err := handler(srv, counted)
 		duration := time.Since(start).Seconds()
 
 		code := rpcStatusCode(err).String()
 		metrics.RequestDuration.WithLabelValues(info.FullMethod, code).Observe(duration)
 		metrics.RequestsTotal.WithLabelValues(info.FullMethod, code).Inc()
+		if metrics.StreamMessages != nil {
+			metrics.StreamMessages.WithLabelValues(info.FullMethod, "sent").Observe(float64(counted.sent.Load()))
+			metrics.StreamMessages.WithLabelValues(info.FullMethod, "received").Observe(float64(counted.received.Load()))
+		}
 
 		return err
 	}
 }
 
+type countingServerStream struct {
+	grpc.ServerStream
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+func (s *countingServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+	}
+	return err
+}
+
+func (s *countingServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Add(1)
+	}
+	return err
+}
+
 func rpcStatusCode(err error) codes.Code {
 	if err == nil {
 		return codes.OK
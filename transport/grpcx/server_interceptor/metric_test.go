@@ -0,0 +1,50 @@
+package grpcx_test
+
+import (
+	"testing"
+
+	serverinterceptor "github.com/bang-go/micro/transport/grpcx/server_interceptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestStreamServerMetricInterceptorCountsMessages(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := serverinterceptor.NewMetrics(registry)
+	interceptor := serverinterceptor.StreamServerMetricInterceptorWithMetrics(metrics)
+
+	stream := &recvServerStream{ServerStream: &sendServerStream{}, msg: &descriptorpb.DescriptorProto{}}
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/svc/Chat"}, func(_ interface{}, s grpc.ServerStream) error {
+		for i := 0; i < 3; i++ {
+			if err := s.SendMsg(&descriptorpb.DescriptorProto{}); err != nil {
+				return err
+			}
+		}
+		return s.RecvMsg(&descriptorpb.DescriptorProto{})
+	})
+	if err != nil {
+		t.Fatalf("interceptor error = %v", err)
+	}
+
+	if got := testutil.CollectAndCount(metrics.StreamMessages, "grpc_server_stream_messages"); got != 2 {
+		t.Fatalf("stream message series = %d, want 2", got)
+	}
+	for direction, want := range map[string]float64{"sent": 3, "received": 1} {
+		metric := &dto.Metric{}
+		if err := metrics.StreamMessages.WithLabelValues("/svc/Chat", direction).(prometheus.Metric).Write(metric); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if got := metric.GetHistogram().GetSampleSum(); got != want {
+			t.Fatalf("%s messages = %v, want %v", direction, got, want)
+		}
+	}
+}
+
+type sendServerStream struct {
+	grpc.ServerStream
+}
+
+func (sendServerStream) SendMsg(interface{}) error { return nil }
@@ -0,0 +1,167 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ValidateConfig rejects invalid requests with InvalidArgument and a
+// BadRequest detail listing the violated fields. Messages generated by
+// protoc-gen-validate are checked through ValidateAll (or Validate).
+type ValidateConfig struct {
+	// Validator runs after the generated methods, e.g. protovalidate:
+	//   Validator: func(m proto.Message) error { return v.Validate(m) }
+	Validator func(proto.Message) error
+	// Violations extracts field violations from Validator errors, e.g. from
+	// *protovalidate.ValidationError; protoc-gen-validate errors need none.
+	Violations  func(error) []*errdetails.BadRequest_FieldViolation
+	SkipMethods []string
+}
+
+type validator struct {
+	conf ValidateConfig
+	skip map[string]struct{}
+}
+
+type validateAller interface {
+	ValidateAll() error
+}
+
+type validater interface {
+	Validate() error
+}
+
+// pgvFieldError and pgvMultiError match the error types protoc-gen-validate
+// generates for every message.
+type pgvFieldError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+type pgvMultiError interface {
+	AllErrors() []error
+}
+
+func newValidator(conf *ValidateConfig) *validator {
+	if conf == nil {
+		return nil
+	}
+	return &validator{conf: *conf, skip: toSet(conf.SkipMethods)}
+}
+
+func UnaryServerValidateInterceptor(conf *ValidateConfig) grpc.UnaryServerInterceptor {
+	v := newValidator(conf)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if v != nil {
+			if err := v.check(info.FullMethod, req); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerValidateInterceptor(conf *ValidateConfig) grpc.StreamServerInterceptor {
+	v := newValidator(conf)
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if v == nil {
+			return handler(srv, stream)
+		}
+		return handler(srv, &validateServerStream{ServerStream: stream, validator: v, method: info.FullMethod})
+	}
+}
+
+type validateServerStream struct {
+	grpc.ServerStream
+	validator *validator
+	method    string
+}
+
+func (s *validateServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.validator.check(s.method, m)
+}
+
+func (v *validator) check(method string, req interface{}) error {
+	if _, ok := v.skip[method]; ok {
+		return nil
+	}
+
+	var err error
+	switch m := req.(type) {
+	case validateAller:
+		err = m.ValidateAll()
+	case validater:
+		err = m.Validate()
+	}
+	violations := pgvViolations(err, "")
+	if err == nil && v.conf.Validator != nil {
+		if msg, ok := req.(proto.Message); ok {
+			err = v.conf.Validator(msg)
+			if err != nil && v.conf.Violations != nil {
+				violations = v.conf.Violations(err)
+			}
+		}
+	}
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return invalidArgument(err, violations)
+}
+
+func invalidArgument(err error, violations []*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(codes.InvalidArgument, "grpcx: invalid request: "+err.Error())
+	if len(violations) == 0 {
+		return st.Err()
+	}
+	detailed, detailErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// pgvViolations flattens protoc-gen-validate errors, joining nested message
+// fields with dots ("address.city").
+func pgvViolations(err error, prefix string) []*errdetails.BadRequest_FieldViolation {
+	if err == nil {
+		return nil
+	}
+	var multi pgvMultiError
+	if errors.As(err, &multi) {
+		var violations []*errdetails.BadRequest_FieldViolation
+		for _, item := range multi.AllErrors() {
+			violations = append(violations, pgvViolations(item, prefix)...)
+		}
+		return violations
+	}
+	var field pgvFieldError
+	if !errors.As(err, &field) {
+		return nil
+	}
+	path := field.Field()
+	if prefix != "" {
+		path = prefix + "." + path
+	}
+	if nested := pgvViolations(field.Cause(), path); len(nested) > 0 {
+		return nested
+	}
+	return []*errdetails.BadRequest_FieldViolation{{
+		Field:       path,
+		Description: field.Reason(),
+	}}
+}
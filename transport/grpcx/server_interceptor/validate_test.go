@@ -0,0 +1,130 @@
+package grpcx_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	serverinterceptor "github.com/bang-go/micro/transport/grpcx/server_interceptor"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// pgvError mirrors the field error type protoc-gen-validate generates.
+type pgvError struct {
+	field  string
+	reason string
+	cause  error
+}
+
+func (e pgvError) Field() string  { return e.field }
+func (e pgvError) Reason() string { return e.reason }
+func (e pgvError) Cause() error   { return e.cause }
+func (e pgvError) Error() string  { return "invalid " + e.field + ": " + e.reason }
+
+type pgvMultiError []error
+
+func (m pgvMultiError) Error() string      { return m[0].Error() }
+func (m pgvMultiError) AllErrors() []error { return m }
+
+type createUserRequest struct {
+	*descriptorpb.DescriptorProto
+	err error
+}
+
+func (r createUserRequest) ValidateAll() error { return r.err }
+
+func fieldViolations(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %v, want InvalidArgument", st.Code())
+	}
+	violations := map[string]string{}
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				violations[violation.GetField()] = violation.GetDescription()
+			}
+		}
+	}
+	return violations
+}
+
+func TestUnaryServerValidateInterceptorPGV(t *testing.T) {
+	interceptor := serverinterceptor.UnaryServerValidateInterceptor(&serverinterceptor.ValidateConfig{})
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/Create"}
+	called := false
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		called = true
+		return "ok", nil
+	}
+
+	if _, err := interceptor(context.Background(), createUserRequest{}, info, handler); err != nil || !called {
+		t.Fatalf("valid request error = %v, called = %v", err, called)
+	}
+
+	called = false
+	req := createUserRequest{err: pgvMultiError{
+		pgvError{field: "Email", reason: "value must be a valid email address"},
+		pgvError{field: "Address", reason: "embedded message failed validation", cause: pgvError{field: "City", reason: "value length must be at least 1 runes"}},
+	}}
+	_, err := interceptor(context.Background(), req, info, handler)
+	if called {
+		t.Fatal("handler ran for an invalid request")
+	}
+	violations := fieldViolations(t, err)
+	if violations["Email"] != "value must be a valid email address" || violations["Address.City"] != "value length must be at least 1 runes" || len(violations) != 2 {
+		t.Fatalf("violations = %v", violations)
+	}
+}
+
+func TestStreamServerValidateInterceptorCustomValidator(t *testing.T) {
+	interceptor := serverinterceptor.StreamServerValidateInterceptor(&serverinterceptor.ValidateConfig{
+		Validator: func(m proto.Message) error {
+			if m.(*descriptorpb.DescriptorProto).GetName() == "" {
+				return errors.New("name is required")
+			}
+			return nil
+		},
+		Violations: func(err error) []*errdetails.BadRequest_FieldViolation {
+			return []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: err.Error()}}
+		},
+	})
+
+	var recvErr error
+	stream := &recvServerStream{msg: &descriptorpb.DescriptorProto{}}
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/svc/Upload"}, func(_ interface{}, s grpc.ServerStream) error {
+		recvErr = s.RecvMsg(&descriptorpb.DescriptorProto{})
+		return recvErr
+	})
+	if err != recvErr || !strings.Contains(status.Convert(err).Message(), "name is required") {
+		t.Fatalf("stream error = %v", err)
+	}
+	if violations := fieldViolations(t, err); violations["name"] != "name is required" {
+		t.Fatalf("violations = %v", violations)
+	}
+
+	stream.msg = &descriptorpb.DescriptorProto{Name: proto.String("ok")}
+	if err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/svc/Upload"}, func(_ interface{}, s grpc.ServerStream) error {
+		return s.RecvMsg(&descriptorpb.DescriptorProto{})
+	}); err != nil {
+		t.Fatalf("valid stream error = %v", err)
+	}
+}
+
+type recvServerStream struct {
+	grpc.ServerStream
+	msg *descriptorpb.DescriptorProto
+}
+
+func (s *recvServerStream) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), s.msg)
+	return nil
+}
@@ -0,0 +1,227 @@
+package grpcx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/grpc/balancer/leastrequest"
+	"google.golang.org/grpc/balancer/pickfirst"
+	"google.golang.org/grpc/balancer/roundrobin"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	LoadBalancingPickFirst  = "pick_first"
+	LoadBalancingRoundRobin = "round_robin"
+	// LoadBalancingLeastConn sends each RPC to the backend with the fewest
+	// outstanding requests among two random choices.
+	LoadBalancingLeastConn = "least_conn"
+
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = time.Second
+	defaultRetryMultiplier     = 2
+)
+
+var ErrHealthCheckPickFirst = errors.New("grpcx: health check requires round_robin or least_conn load balancing")
+
+// MethodConfig applies call policies to a set of methods. As in the gRPC
+// service config, an exact method wins over a whole service, which wins over
+// the default entry without Methods.
+type MethodConfig struct {
+	// Methods holds "/pkg.Service/Method", or "/pkg.Service/" for every
+	// method of a service. Empty makes this the default for all methods.
+	Methods []string
+	// Timeout caps each call unless the caller's deadline is earlier.
+	Timeout time.Duration
+	// Retry and Hedging are mutually exclusive.
+	Retry   *RetryPolicy
+	Hedging *HedgingPolicy
+}
+
+// RetryPolicy retries failed calls with exponential backoff. It runs inside
+// gRPC, so only calls that have not received response headers are retried.
+type RetryPolicy struct {
+	// MaxAttempts counts the original call and must be at least 2; gRPC
+	// caps it at 5.
+	MaxAttempts int
+	// InitialBackoff defaults to 100ms, MaxBackoff to 1s, BackoffMultiplier
+	// to 2. Each delay is randomized between 0 and the current backoff.
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	// RetryableCodes defaults to Unavailable.
+	RetryableCodes []codes.Code
+}
+
+// HedgingPolicy sends the same unary call again every HedgingDelay until one
+// attempt succeeds, returning the first success and canceling the rest.
+// grpc-go does not implement hedgingPolicy, so grpcx applies it with a client
+// interceptor; only idempotent methods should be hedged.
+type HedgingPolicy struct {
+	// MaxAttempts counts the original call and must be at least 2.
+	MaxAttempts  int
+	HedgingDelay time.Duration
+	// NonFatalCodes start the next attempt immediately instead of failing
+	// the call; any other error is returned at once.
+	NonFatalCodes []codes.Code
+}
+
+type methodName struct {
+	Service string `json:"service,omitempty"`
+	Method  string `json:"method,omitempty"`
+}
+
+// serviceConfigJSON builds the default service config for the client; it is
+// empty when the config needs none.
+func serviceConfigJSON(conf *ClientConfig) (string, error) {
+	sc := map[string]any{}
+	loadBalancing := conf.LoadBalancing
+	if conf.HealthCheck {
+		// pick_first only honors health checks under round_robin and
+		// least_conn, so it cannot be the top-level policy.
+		switch loadBalancing {
+		case "":
+			loadBalancing = LoadBalancingRoundRobin
+		case LoadBalancingPickFirst:
+			return "", ErrHealthCheckPickFirst
+		}
+		sc["healthCheckConfig"] = map[string]any{"serviceName": conf.HealthCheckServiceName}
+	}
+	if loadBalancing != "" {
+		var policy string
+		switch loadBalancing {
+		case LoadBalancingPickFirst:
+			policy = pickfirst.Name
+		case LoadBalancingRoundRobin:
+			policy = roundrobin.Name
+		case LoadBalancingLeastConn:
+			policy = leastrequest.Name
+		default:
+			return "", fmt.Errorf("grpcx: unsupported load balancing policy %q", loadBalancing)
+		}
+		sc["loadBalancingConfig"] = []map[string]any{{policy: map[string]any{}}}
+	}
+	if len(conf.MethodConfigs) > 0 {
+		methodConfigs, err := methodConfigJSON(conf.MethodConfigs)
+		if err != nil {
+			return "", err
+		}
+		sc["methodConfig"] = methodConfigs
+	}
+	if len(sc) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func methodConfigJSON(configs []MethodConfig) ([]map[string]any, error) {
+	seen := map[methodName]bool{}
+	out := make([]map[string]any, 0, len(configs))
+	for _, mc := range configs {
+		names, err := parseMethodNames(mc.Methods)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if seen[name] {
+				return nil, fmt.Errorf("grpcx: method %q configured twice", "/"+name.Service+"/"+name.Method)
+			}
+			seen[name] = true
+		}
+		if mc.Retry != nil && mc.Hedging != nil {
+			return nil, fmt.Errorf("grpcx: retry and hedging are mutually exclusive for %v", mc.Methods)
+		}
+
+		entry := map[string]any{"name": names}
+		if mc.Timeout > 0 {
+			entry["timeout"] = durationJSON(mc.Timeout)
+		}
+		if retry := mc.Retry; retry != nil {
+			if retry.MaxAttempts < 2 {
+				return nil, fmt.Errorf("grpcx: retry max attempts must be at least 2 for %v", mc.Methods)
+			}
+			policy := normalizeRetryPolicy(*retry)
+			entry["retryPolicy"] = map[string]any{
+				"maxAttempts":          policy.MaxAttempts,
+				"initialBackoff":       durationJSON(policy.InitialBackoff),
+				"maxBackoff":           durationJSON(policy.MaxBackoff),
+				"backoffMultiplier":    policy.BackoffMultiplier,
+				"retryableStatusCodes": codeNames(policy.RetryableCodes),
+			}
+		}
+		if hedging := mc.Hedging; hedging != nil && hedging.MaxAttempts < 2 {
+			return nil, fmt.Errorf("grpcx: hedging max attempts must be at least 2 for %v", mc.Methods)
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+func normalizeRetryPolicy(policy RetryPolicy) RetryPolicy {
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+	if policy.BackoffMultiplier <= 0 {
+		policy.BackoffMultiplier = defaultRetryMultiplier
+	}
+	if len(policy.RetryableCodes) == 0 {
+		policy.RetryableCodes = []codes.Code{codes.Unavailable}
+	}
+	return policy
+}
+
+// parseMethodNames turns "/pkg.Service/Method" and "/pkg.Service/" into
+// service config names; no methods yields the default name.
+func parseMethodNames(methods []string) ([]methodName, error) {
+	if len(methods) == 0 {
+		return []methodName{{}}, nil
+	}
+	names := make([]methodName, 0, len(methods))
+	for _, method := range methods {
+		service, name, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(method), "/"), "/")
+		if service == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("grpcx: invalid method name %q", method)
+		}
+		names = append(names, methodName{Service: service, Method: name})
+	}
+	return names, nil
+}
+
+// durationJSON formats d as the protobuf JSON duration gRPC expects.
+func durationJSON(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// codeNames converts codes to the canonical names used in service configs,
+// e.g. DeadlineExceeded to DEADLINE_EXCEEDED.
+func codeNames(list []codes.Code) []string {
+	names := make([]string, 0, len(list))
+	for _, code := range list {
+		var b strings.Builder
+		prev := rune(0)
+		for _, r := range code.String() {
+			if unicode.IsUpper(r) && unicode.IsLower(prev) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToUpper(r))
+			prev = r
+		}
+		names = append(names, b.String())
+	}
+	return names
+}
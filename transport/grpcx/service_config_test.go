@@ -0,0 +1,168 @@
+package grpcx_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/grpcx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// scriptedHealth answers Check with the handler of each call's index.
+type scriptedHealth struct {
+	grpc_health_v1.UnimplementedHealthServer
+	calls  atomic.Int32
+	handle func(call int32) error
+}
+
+func (h *scriptedHealth) Check(ctx context.Context, _ *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if err := h.handle(h.calls.Add(1)); err != nil {
+		return nil, err
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func dialScripted(t *testing.T, handle func(call int32) error, configs ...grpcx.MethodConfig) (grpc_health_v1.HealthClient, *scriptedHealth) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	health := &scriptedHealth{handle: handle}
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, health)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	client := grpcx.NewClient(&grpcx.ClientConfig{
+		Addr:           listener.Addr().String(),
+		MethodConfigs:  configs,
+		DisableMetrics: true,
+	})
+	conn, err := client.Dial()
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(client.Close)
+	return grpc_health_v1.NewHealthClient(conn), health
+}
+
+func check(client grpc_health_v1.HealthClient) (*grpc_health_v1.HealthCheckResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+}
+
+func TestMethodConfigRetry(t *testing.T) {
+	client, health := dialScripted(t, func(call int32) error {
+		if call < 3 {
+			return status.Error(codes.Unavailable, "warming up")
+		}
+		return nil
+	}, grpcx.MethodConfig{
+		Methods: []string{"/grpc.health.v1.Health/Check"},
+		Retry: &grpcx.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: 10 * time.Millisecond,
+			RetryableCodes: []codes.Code{codes.ResourceExhausted, codes.Unavailable},
+		},
+	})
+
+	if _, err := check(client); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if got := health.calls.Load(); got != 3 {
+		t.Fatalf("server calls = %d, want 3", got)
+	}
+}
+
+func TestMethodConfigTimeout(t *testing.T) {
+	client, _ := dialScripted(t, func(int32) error {
+		time.Sleep(300 * time.Millisecond)
+		return nil
+	}, grpcx.MethodConfig{Methods: []string{"/grpc.health.v1.Health/"}, Timeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := check(client); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Fatalf("Check() took %v", elapsed)
+	}
+}
+
+func TestMethodConfigHedging(t *testing.T) {
+	client, health := dialScripted(t, func(call int32) error {
+		if call == 1 {
+			time.Sleep(time.Second)
+		}
+		return nil
+	}, grpcx.MethodConfig{Hedging: &grpcx.HedgingPolicy{MaxAttempts: 2, HedgingDelay: 20 * time.Millisecond}})
+
+	start := time.Now()
+	resp, err := check(client)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("status = %v", resp.GetStatus())
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("hedged call took %v", elapsed)
+	}
+	if got := health.calls.Load(); got != 2 {
+		t.Fatalf("server calls = %d, want 2", got)
+	}
+}
+
+func TestMethodConfigHedgingFailsFastOnFatalCode(t *testing.T) {
+	client, health := dialScripted(t, func(call int32) error {
+		if call == 1 {
+			return status.Error(codes.Unavailable, "down")
+		}
+		return status.Error(codes.InvalidArgument, "bad request")
+	}, grpcx.MethodConfig{Hedging: &grpcx.HedgingPolicy{
+		MaxAttempts:   3,
+		HedgingDelay:  time.Second,
+		NonFatalCodes: []codes.Code{codes.Unavailable},
+	}})
+
+	if _, err := check(client); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if got := health.calls.Load(); got != 2 {
+		t.Fatalf("server calls = %d, want 2", got)
+	}
+}
+
+func TestMethodConfigValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs []grpcx.MethodConfig
+		want    string
+	}{
+		{"retry and hedging", []grpcx.MethodConfig{{
+			Retry:   &grpcx.RetryPolicy{MaxAttempts: 2},
+			Hedging: &grpcx.HedgingPolicy{MaxAttempts: 2},
+		}}, "mutually exclusive"},
+		{"single attempt", []grpcx.MethodConfig{{Retry: &grpcx.RetryPolicy{MaxAttempts: 1}}}, "at least 2"},
+		{"invalid name", []grpcx.MethodConfig{{Methods: []string{"/a/b/c"}}}, "invalid method name"},
+		{"duplicate", []grpcx.MethodConfig{{Methods: []string{"/a.S/M"}}, {Methods: []string{"a.S/M"}}}, "configured twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := grpcx.NewClient(&grpcx.ClientConfig{Addr: "127.0.0.1:1", MethodConfigs: tt.configs, DisableMetrics: true})
+			if _, err := client.Dial(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Dial() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
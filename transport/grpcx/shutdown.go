@@ -0,0 +1,44 @@
+package grpcx
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/stats"
+)
+
+const defaultShutdownTimeout = 10 * time.Second
+
+// connTracker counts open connections and in-flight RPCs so a forced stop
+// can report what it cut off.
+type connTracker struct {
+	conns atomic.Int64
+	rpcs  atomic.Int64
+}
+
+func (t *connTracker) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (t *connTracker) HandleRPC(_ context.Context, s stats.RPCStats) {
+	switch s.(type) {
+	case *stats.Begin:
+		t.rpcs.Add(1)
+	case *stats.End:
+		t.rpcs.Add(-1)
+	}
+}
+
+func (t *connTracker) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (t *connTracker) HandleConn(_ context.Context, s stats.ConnStats) {
+	switch s.(type) {
+	case *stats.ConnBegin:
+		t.conns.Add(1)
+	case *stats.ConnEnd:
+		t.conns.Add(-1)
+	}
+}
@@ -0,0 +1,161 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StreamSender is the sending half of a generated stream, e.g.
+// grpc.ServerStreamingServer[T] with T a message pointer.
+type StreamSender[T any] interface {
+	Send(T) error
+	Context() context.Context
+}
+
+// StreamReceiver is the receiving half of a generated stream, e.g.
+// grpc.ClientStreamingServer[T, R] or grpc.ServerStreamingClient[T].
+type StreamReceiver[T any] interface {
+	Recv() (T, error)
+	Context() context.Context
+}
+
+type BidiStream[Req, Resp any] interface {
+	StreamReceiver[Req]
+	Send(Resp) error
+}
+
+type SendOption[T any] func(*sendOptions[T])
+
+type sendOptions[T any] struct {
+	heartbeat time.Duration
+	message   func() T
+}
+
+// WithHeartbeat sends message() whenever nothing was sent for interval, so
+// proxies and clients can tell an idle long-lived stream from a dead one.
+func WithHeartbeat[T any](interval time.Duration, message func() T) SendOption[T] {
+	return func(o *sendOptions[T]) {
+		o.heartbeat = interval
+		o.message = message
+	}
+}
+
+// StreamSend sends values until the channel is closed. It returns the
+// stream's context error once the call ends, leaving values undrained.
+func StreamSend[T any](stream StreamSender[T], values <-chan T, opts ...SendOption[T]) error {
+	return streamSend(stream.Context(), stream, values, opts)
+}
+
+func streamSend[T any](ctx context.Context, stream StreamSender[T], values <-chan T, opts []SendOption[T]) error {
+	settings := sendOptions[T]{}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	var heartbeat <-chan time.Time
+	if settings.heartbeat > 0 && settings.message != nil {
+		ticker := time.NewTicker(settings.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case value, ok := <-values:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(value); err != nil {
+				return err
+			}
+		case <-heartbeat:
+			message, err := recoverStream(func() (T, error) { return settings.message(), nil })
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamRecv calls handle for every message until the peer closes its
+// side. Panics in handle become Internal errors.
+func StreamRecv[T any](stream StreamReceiver[T], handle func(T) error) error {
+	for {
+		message, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := recoverStream(func() (struct{}, error) { return struct{}{}, handle(message) }); err != nil {
+			return err
+		}
+	}
+}
+
+// StreamCollect receives all messages of a client stream. A positive limit
+// fails the call with ResourceExhausted once more messages arrive.
+func StreamCollect[T any](stream StreamReceiver[T], limit int) ([]T, error) {
+	var messages []T
+	err := StreamRecv(stream, func(message T) error {
+		if limit > 0 && len(messages) >= limit {
+			return status.Errorf(codes.ResourceExhausted, "grpcx: stream exceeds %d messages", limit)
+		}
+		messages = append(messages, message)
+		return nil
+	})
+	return messages, err
+}
+
+// StreamBidi receives requests on its own goroutine and passes each to
+// handle, while the calling goroutine owns every Send. handle may call send
+// any number of times; send fails once the call is over. It returns after
+// the client closes its side and all responses are sent, or on the first
+// error.
+func StreamBidi[Req, Resp any](stream BidiStream[Req, Resp], handle func(ctx context.Context, req Req, send func(Resp) error) error, opts ...SendOption[Resp]) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	responses := make(chan Resp)
+	send := func(response Resp) error {
+		select {
+		case responses <- response:
+			return nil
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	recvDone := make(chan error, 1)
+	go func() {
+		defer close(responses)
+		recvDone <- StreamRecv(stream, func(req Req) error {
+			return handle(ctx, req, send)
+		})
+	}()
+
+	if err := streamSend(ctx, stream, responses, opts); err != nil {
+		// The receiving goroutine ends once the stream context is canceled
+		// when the handler returns.
+		return err
+	}
+	return <-recvDone
+}
+
+func recoverStream[T any](fn func() (T, error)) (value T, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = status.Errorf(codes.Internal, "grpcx: stream handler panic: %v", p)
+		}
+	}()
+	return fn()
+}
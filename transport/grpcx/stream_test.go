@@ -0,0 +1,131 @@
+package grpcx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/grpcx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeStream struct {
+	ctx context.Context
+
+	mu   sync.Mutex
+	in   []string
+	sent []string
+}
+
+func newFakeStream(ctx context.Context, in ...string) *fakeStream {
+	return &fakeStream{ctx: ctx, in: in}
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func (s *fakeStream) Send(message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, message)
+	return nil
+}
+
+func (s *fakeStream) Recv() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.in) == 0 {
+		return "", io.EOF
+	}
+	message := s.in[0]
+	s.in = s.in[1:]
+	return message, nil
+}
+
+func (s *fakeStream) Sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.sent...)
+}
+
+func TestStreamSendWithHeartbeat(t *testing.T) {
+	stream := newFakeStream(context.Background())
+	values := make(chan string)
+	done := make(chan error, 1)
+	go func() {
+		done <- grpcx.StreamSend[string](stream, values, grpcx.WithHeartbeat(10*time.Millisecond, func() string { return "ping" }))
+	}()
+
+	values <- "a"
+	time.Sleep(35 * time.Millisecond)
+	values <- "b"
+	close(values)
+	if err := <-done; err != nil {
+		t.Fatalf("StreamSend() error = %v", err)
+	}
+	sent := strings.Join(stream.Sent(), ",")
+	if !strings.HasPrefix(sent, "a,ping") || !strings.HasSuffix(sent, ",b") {
+		t.Fatalf("sent = %s", sent)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := grpcx.StreamSend[string](newFakeStream(ctx), make(chan string)); status.Code(err) != codes.Canceled {
+		t.Fatalf("StreamSend() after cancel error = %v", err)
+	}
+}
+
+func TestStreamCollectAndRecv(t *testing.T) {
+	got, err := grpcx.StreamCollect[string](newFakeStream(context.Background(), "a", "b"), 0)
+	if err != nil || strings.Join(got, ",") != "a,b" {
+		t.Fatalf("StreamCollect() = %v, %v", got, err)
+	}
+	if _, err := grpcx.StreamCollect[string](newFakeStream(context.Background(), "a", "b", "c"), 2); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("StreamCollect() over limit error = %v", err)
+	}
+
+	err = grpcx.StreamRecv[string](newFakeStream(context.Background(), "a"), func(string) error {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("StreamRecv() panic error = %v", err)
+	}
+}
+
+func TestStreamBidi(t *testing.T) {
+	stream := newFakeStream(context.Background(), "a", "b")
+	err := grpcx.StreamBidi[string, string](stream, func(ctx context.Context, req string, send func(string) error) error {
+		if err := send(req + "1"); err != nil {
+			return err
+		}
+		return send(req + "2")
+	})
+	if err != nil {
+		t.Fatalf("StreamBidi() error = %v", err)
+	}
+	if sent := strings.Join(stream.Sent(), ","); sent != "a1,a2,b1,b2" {
+		t.Fatalf("sent = %s", sent)
+	}
+
+	failure := errors.New("bad request")
+	err = grpcx.StreamBidi[string, string](newFakeStream(context.Background(), "a", "b"), func(ctx context.Context, req string, send func(string) error) error {
+		if req == "b" {
+			return failure
+		}
+		return send(req)
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("StreamBidi() handler error = %v", err)
+	}
+
+	err = grpcx.StreamBidi[string, string](newFakeStream(context.Background(), "a"), func(context.Context, string, func(string) error) error {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("StreamBidi() panic error = %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package grpcx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bang-go/micro/internal/tlsutil"
+)
+
+var (
+	ErrTLSCertificateRequired = errors.New("grpcx: tls certificate is required")
+	ErrTLSKeyPairIncomplete   = errors.New("grpcx: tls certificate and key must be set together")
+	ErrInvalidTLSCA           = errors.New("grpcx: tls ca contains no certificates")
+	ErrTLSConfigConflict      = errors.New("grpcx: TLS and TLSConfig cannot both be set")
+)
+
+// ServerTLSConfig builds server credentials from files or PEM. Setting a
+// client CA turns on mTLS with RequireAndVerifyClientCert unless ClientAuth
+// says otherwise.
+type ServerTLSConfig struct {
+	CertFile     string
+	KeyFile      string
+	CertPEM      []byte
+	KeyPEM       []byte
+	Certificates []tls.Certificate
+	ClientCAFile string
+	ClientCAPEM  []byte
+	ClientCAs    *x509.CertPool
+	ClientAuth   tls.ClientAuthType
+	// MinVersion defaults to TLS 1.2.
+	MinVersion uint16
+	// ReloadInterval re-reads the files when their size or modification time
+	// changed, checked at most once per interval during handshakes; zero
+	// disables reloading. A failed reload keeps the previous credentials.
+	ReloadInterval time.Duration
+}
+
+// ClientTLSConfig builds client credentials. CA settings replace the system
+// roots; a certificate and key enable mTLS.
+type ClientTLSConfig struct {
+	CAFile   string
+	CAPEM    []byte
+	RootCAs  *x509.CertPool
+	CertFile string
+	KeyFile  string
+	CertPEM  []byte
+	KeyPEM   []byte
+	// ServerName overrides the name verified against the server certificate,
+	// which defaults to the host in Addr.
+	ServerName         string
+	InsecureSkipVerify bool
+	MinVersion         uint16
+	ReloadInterval     time.Duration
+}
+
+var tlsLoader = tlsutil.Loader{
+	Prefix:                 "grpcx",
+	ErrCertificateRequired: ErrTLSCertificateRequired,
+	ErrKeyPairIncomplete:   ErrTLSKeyPairIncomplete,
+	ErrInvalidCA:           ErrInvalidTLSCA,
+}
+
+// Build returns a tls.Config for the server; with ReloadInterval set it
+// serves the latest credentials through GetConfigForClient. ALPN is always
+// set to h2, which grpc-go requires.
+func (c *ServerTLSConfig) Build() (*tls.Config, error) {
+	return tlsLoader.Server((*tlsutil.Server)(c), []string{"h2"})
+}
+
+// Build returns a tls.Config for the client. With ReloadInterval set, the
+// client certificate and CA are re-read as they change; the server chain is
+// then verified in VerifyConnection against the current roots.
+func (c *ClientTLSConfig) Build() (*tls.Config, error) {
+	if c == nil {
+		return &tls.Config{MinVersion: tls.VersionTLS12}, nil
+	}
+	build := func() (*tls.Config, error) {
+		rootCAs, err := tlsLoader.CertPool(c.RootCAs, c.CAFile, c.CAPEM)
+		if err != nil {
+			return nil, err
+		}
+		config := &tls.Config{
+			RootCAs:            rootCAs,
+			ServerName:         strings.TrimSpace(c.ServerName),
+			InsecureSkipVerify: c.InsecureSkipVerify,
+			MinVersion:         tlsutil.MinVersion(c.MinVersion),
+		}
+		certificate, ok, err := tlsLoader.KeyPair(c.CertFile, c.KeyFile, c.CertPEM, c.KeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			config.Certificates = []tls.Certificate{certificate}
+		}
+		return config, nil
+	}
+
+	config, err := build()
+	if err != nil {
+		return nil, err
+	}
+	if c.ReloadInterval <= 0 {
+		return config, nil
+	}
+	r := tlsutil.NewReloader(c.ReloadInterval, build, config, c.CertFile, c.KeyFile, c.CAFile)
+	return &tls.Config{
+		ServerName: config.ServerName,
+		MinVersion: config.MinVersion,
+		// Verification moves to VerifyConnection so it sees reloaded roots.
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			current := r.Get()
+			if len(current.Certificates) == 0 {
+				return &tls.Certificate{}, nil
+			}
+			return &current.Certificates[0], nil
+		},
+		VerifyConnection: func(state tls.ConnectionState) error {
+			current := r.Get()
+			if current.InsecureSkipVerify {
+				return nil
+			}
+			return verifyServerChain(state, current.RootCAs)
+		},
+	}, nil
+}
+
+func verifyServerChain(state tls.ConnectionState, roots *x509.CertPool) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("grpcx: server presented no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       state.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
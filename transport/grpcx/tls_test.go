@@ -0,0 +1,254 @@
+package grpcx_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/grpcx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create ca: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for localhost, usable by both
+// servers and clients.
+func (ca *testCA) issue(t *testing.T, serial int64) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func startTLSServer(t *testing.T, conf *grpcx.ServerConfig) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	conf.Listener = listener
+	conf.DisableMetrics = true
+	server := grpcx.NewServer(conf)
+
+	serveDone := make(chan error, 1)
+	go func() {
+		serveDone <- server.Start(context.Background(), func(*grpc.Server) {})
+	}()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+		if err := <-serveDone; err != nil {
+			t.Errorf("server exited with error: %v", err)
+		}
+	})
+	return listener.Addr().String()
+}
+
+func tlsHealthCheck(t *testing.T, addr string, conf *grpcx.ClientTLSConfig) error {
+	t.Helper()
+	client := grpcx.NewClient(&grpcx.ClientConfig{Addr: addr, TLS: conf, DisableMetrics: true})
+	defer client.Close()
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	return err
+}
+
+func TestMutualTLSFromFiles(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	dir := t.TempDir()
+	serverCert, serverKey := ca.issue(t, 2)
+	writeFile(t, filepath.Join(dir, "server.crt"), serverCert)
+	writeFile(t, filepath.Join(dir, "server.key"), serverKey)
+	writeFile(t, filepath.Join(dir, "ca.crt"), ca.pem)
+
+	addr := startTLSServer(t, &grpcx.ServerConfig{TLS: &grpcx.ServerTLSConfig{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	}})
+
+	clientCert, clientKey := ca.issue(t, 3)
+	err := tlsHealthCheck(t, addr, &grpcx.ClientTLSConfig{
+		CAFile:     filepath.Join(dir, "ca.crt"),
+		CertPEM:    clientCert,
+		KeyPEM:     clientKey,
+		ServerName: "localhost",
+	})
+	if err != nil {
+		t.Fatalf("mTLS health check error = %v", err)
+	}
+
+	if err := tlsHealthCheck(t, addr, &grpcx.ClientTLSConfig{CAPEM: ca.pem, ServerName: "localhost"}); err == nil {
+		t.Fatal("server accepted a client without a certificate")
+	}
+	if err := tlsHealthCheck(t, addr, &grpcx.ClientTLSConfig{CAPEM: newTestCA(t, "other").pem, CertPEM: clientCert, KeyPEM: clientKey}); err == nil {
+		t.Fatal("client trusted a server signed by an unknown ca")
+	}
+}
+
+func TestServerTLSReloadsRotatedCertificate(t *testing.T) {
+	oldCA, newCA := newTestCA(t, "old"), newTestCA(t, "new")
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	cert, key := oldCA.issue(t, 2)
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+
+	addr := startTLSServer(t, &grpcx.ServerConfig{TLS: &grpcx.ServerTLSConfig{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ReloadInterval: 10 * time.Millisecond,
+	}})
+	trustNew := &grpcx.ClientTLSConfig{CAPEM: newCA.pem, ServerName: "localhost"}
+	if err := tlsHealthCheck(t, addr, trustNew); err == nil {
+		t.Fatal("client trusted the old certificate")
+	}
+
+	cert, key = newCA.issue(t, 3)
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+	// Keep the modification time moving on filesystems with coarse mtimes.
+	future := time.Now().Add(time.Second)
+	_ = os.Chtimes(certFile, future, future)
+	_ = os.Chtimes(keyFile, future, future)
+	time.Sleep(20 * time.Millisecond)
+
+	if err := tlsHealthCheck(t, addr, trustNew); err != nil {
+		t.Fatalf("health check after rotation error = %v", err)
+	}
+}
+
+func TestClientTLSReloadsCA(t *testing.T) {
+	oldCA, newCA := newTestCA(t, "old"), newTestCA(t, "new")
+	cert, key := newCA.issue(t, 2)
+	certificate, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		t.Fatalf("key pair: %v", err)
+	}
+	addr := startTLSServer(t, &grpcx.ServerConfig{TLS: &grpcx.ServerTLSConfig{Certificates: []tls.Certificate{certificate}}})
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	writeFile(t, caFile, oldCA.pem)
+	conf := &grpcx.ClientTLSConfig{CAFile: caFile, ServerName: "localhost", ReloadInterval: 10 * time.Millisecond}
+	tlsConfig, err := conf.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	dial := func() error {
+		conn, err := tls.Dial("tcp", addr, tlsConfig)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	if err := dial(); err == nil {
+		t.Fatal("client trusted a server signed by the old ca")
+	}
+
+	writeFile(t, caFile, newCA.pem)
+	future := time.Now().Add(time.Second)
+	_ = os.Chtimes(caFile, future, future)
+	time.Sleep(20 * time.Millisecond)
+	if err := dial(); err != nil {
+		t.Fatalf("dial after ca rotation error = %v", err)
+	}
+}
+
+func TestTLSConfigValidation(t *testing.T) {
+	if _, err := (&grpcx.ServerTLSConfig{}).Build(); !errors.Is(err, grpcx.ErrTLSCertificateRequired) {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if _, err := (&grpcx.ServerTLSConfig{CertFile: "tls.crt"}).Build(); !errors.Is(err, grpcx.ErrTLSKeyPairIncomplete) {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if _, err := (&grpcx.ClientTLSConfig{CAPEM: []byte("not pem")}).Build(); !errors.Is(err, grpcx.ErrInvalidTLSCA) {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	server := grpcx.NewServer(&grpcx.ServerConfig{
+		Addr:      "127.0.0.1:0",
+		TLS:       &grpcx.ServerTLSConfig{},
+		TLSConfig: &tls.Config{},
+	})
+	if err := server.Start(context.Background(), func(*grpc.Server) {}); !errors.Is(err, grpcx.ErrTLSConfigConflict) {
+		t.Fatalf("Start() error = %v", err)
+	}
+	client := grpcx.NewClient(&grpcx.ClientConfig{Addr: "127.0.0.1:1", TLS: &grpcx.ClientTLSConfig{}, TLSConfig: &tls.Config{}})
+	if _, err := client.Dial(); !errors.Is(err, grpcx.ErrTLSConfigConflict) {
+		t.Fatalf("Dial() error = %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package grpcx
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	defaultServerKeepaliveTime    = time.Minute
+	defaultServerKeepaliveTimeout = 20 * time.Second
+	defaultMinPingInterval        = 10 * time.Second
+	defaultClientKeepaliveTime    = 30 * time.Second
+	defaultClientKeepaliveTimeout = 10 * time.Second
+
+	// minClientKeepaliveTime is the floor grpc-go silently raises shorter
+	// client ping intervals to.
+	minClientKeepaliveTime = 10 * time.Second
+)
+
+var (
+	ErrInvalidTransportConfig = errors.New("grpcx: invalid transport config")
+	ErrKeepaliveConflict      = errors.New("grpcx: Transport and KeepaliveParams/KeepaliveEnforcementPolicy cannot both be set")
+)
+
+// ServerTransportConfig tunes message sizes, keepalive and connection age.
+// Zero fields take the defaults noted on them.
+type ServerTransportConfig struct {
+	// MaxRecvMsgSize defaults to gRPC's 4MiB. Limit.MaxMessageBytes, when
+	// also set, applies if it is smaller.
+	MaxRecvMsgSize int
+	// MaxSendMsgSize defaults to unlimited.
+	MaxSendMsgSize int
+	// MaxConcurrentStreams per connection; zero leaves it unlimited.
+	MaxConcurrentStreams uint32
+
+	// KeepaliveTime pings idle clients after this long; defaults to 1m.
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout closes the connection when a ping is not answered in
+	// time; defaults to 20s.
+	KeepaliveTimeout time.Duration
+	// MaxConnectionIdle closes connections without RPCs for this long.
+	MaxConnectionIdle time.Duration
+	// MaxConnectionAge sends GOAWAY after this long so clients rebalance
+	// across new instances; MaxConnectionAgeGrace bounds the drain after it.
+	MaxConnectionAge      time.Duration
+	MaxConnectionAgeGrace time.Duration
+
+	// MinPingInterval is the shortest client ping interval tolerated before
+	// the connection is closed with too_many_pings; defaults to 10s, which
+	// admits any client grpc-go allows.
+	MinPingInterval time.Duration
+	// RejectPingWithoutStream closes connections pinging without active
+	// RPCs; by default such pings are allowed.
+	RejectPingWithoutStream bool
+}
+
+// ClientTransportConfig tunes message sizes and keepalive of a client.
+type ClientTransportConfig struct {
+	// MaxRecvMsgSize defaults to gRPC's 4MiB.
+	MaxRecvMsgSize int
+	// MaxSendMsgSize defaults to unlimited.
+	MaxSendMsgSize int
+
+	// KeepaliveTime pings the server after this long without activity;
+	// defaults to 30s and must be at least 10s. It has to stay above the
+	// server's MinPingInterval.
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout defaults to 10s.
+	KeepaliveTimeout time.Duration
+	// PermitWithoutStream keeps pinging idle connections, detecting dead
+	// peers before the next call at the cost of extra traffic.
+	PermitWithoutStream bool
+}
+
+func (c *ServerTransportConfig) options(limitBytes int) ([]grpc.ServerOption, error) {
+	if c.MaxRecvMsgSize < 0 || c.MaxSendMsgSize < 0 {
+		return nil, fmt.Errorf("%w: message sizes must not be negative", ErrInvalidTransportConfig)
+	}
+	if err := nonNegativeDurations(c.KeepaliveTime, c.KeepaliveTimeout, c.MaxConnectionIdle,
+		c.MaxConnectionAge, c.MaxConnectionAgeGrace, c.MinPingInterval); err != nil {
+		return nil, err
+	}
+	if c.MaxConnectionAgeGrace > 0 && c.MaxConnectionAge == 0 {
+		return nil, fmt.Errorf("%w: MaxConnectionAgeGrace requires MaxConnectionAge", ErrInvalidTransportConfig)
+	}
+
+	params := keepalive.ServerParameters{
+		Time:                  durationOr(c.KeepaliveTime, defaultServerKeepaliveTime),
+		Timeout:               durationOr(c.KeepaliveTimeout, defaultServerKeepaliveTimeout),
+		MaxConnectionIdle:     c.MaxConnectionIdle,
+		MaxConnectionAge:      c.MaxConnectionAge,
+		MaxConnectionAgeGrace: c.MaxConnectionAgeGrace,
+	}
+	policy := keepalive.EnforcementPolicy{
+		MinTime:             durationOr(c.MinPingInterval, defaultMinPingInterval),
+		PermitWithoutStream: !c.RejectPingWithoutStream,
+	}
+	options := []grpc.ServerOption{grpc.KeepaliveParams(params), grpc.KeepaliveEnforcementPolicy(policy)}
+
+	recv := c.MaxRecvMsgSize
+	if limitBytes > 0 && (recv == 0 || limitBytes < recv) {
+		recv = limitBytes
+	}
+	if recv > 0 {
+		options = append(options, grpc.MaxRecvMsgSize(recv))
+	}
+	if c.MaxSendMsgSize > 0 {
+		options = append(options, grpc.MaxSendMsgSize(c.MaxSendMsgSize))
+	}
+	if c.MaxConcurrentStreams > 0 {
+		options = append(options, grpc.MaxConcurrentStreams(c.MaxConcurrentStreams))
+	}
+	return options, nil
+}
+
+func (c *ClientTransportConfig) options() ([]grpc.DialOption, error) {
+	if c.MaxRecvMsgSize < 0 || c.MaxSendMsgSize < 0 {
+		return nil, fmt.Errorf("%w: message sizes must not be negative", ErrInvalidTransportConfig)
+	}
+	if err := nonNegativeDurations(c.KeepaliveTime, c.KeepaliveTimeout); err != nil {
+		return nil, err
+	}
+	if c.KeepaliveTime > 0 && c.KeepaliveTime < minClientKeepaliveTime {
+		return nil, fmt.Errorf("%w: KeepaliveTime must be at least %s", ErrInvalidTransportConfig, minClientKeepaliveTime)
+	}
+
+	options := []grpc.DialOption{grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                durationOr(c.KeepaliveTime, defaultClientKeepaliveTime),
+		Timeout:             durationOr(c.KeepaliveTimeout, defaultClientKeepaliveTimeout),
+		PermitWithoutStream: c.PermitWithoutStream,
+	})}
+	var callOptions []grpc.CallOption
+	if c.MaxRecvMsgSize > 0 {
+		callOptions = append(callOptions, grpc.MaxCallRecvMsgSize(c.MaxRecvMsgSize))
+	}
+	if c.MaxSendMsgSize > 0 {
+		callOptions = append(callOptions, grpc.MaxCallSendMsgSize(c.MaxSendMsgSize))
+	}
+	if len(callOptions) > 0 {
+		options = append(options, grpc.WithDefaultCallOptions(callOptions...))
+	}
+	return options, nil
+}
+
+func nonNegativeDurations(durations ...time.Duration) error {
+	for _, d := range durations {
+		if d < 0 {
+			return fmt.Errorf("%w: durations must not be negative", ErrInvalidTransportConfig)
+		}
+	}
+	return nil
+}
+
+func durationOr(d, fallback time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return fallback
+}
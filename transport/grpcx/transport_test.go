@@ -0,0 +1,96 @@
+package grpcx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/grpcx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+func TestTransportMessageSizes(t *testing.T) {
+	listener := startBufServer(t, &grpcx.ServerConfig{
+		Transport: &grpcx.ServerTransportConfig{MaxRecvMsgSize: 1024, MaxConnectionAge: time.Minute},
+	})
+	dial := func(transport *grpcx.ClientTransportConfig) grpc_health_v1.HealthClient {
+		client := grpcx.NewClient(&grpcx.ClientConfig{
+			Addr:           "passthrough:///bufnet",
+			Transport:      transport,
+			DisableMetrics: true,
+		})
+		client.AddDialOptions(grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}))
+		conn, err := client.Dial()
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		t.Cleanup(client.Close)
+		return grpc_health_v1.NewHealthClient(conn)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	large := &grpc_health_v1.HealthCheckRequest{Service: strings.Repeat("x", 2048)}
+	client := dial(&grpcx.ClientTransportConfig{KeepaliveTime: time.Minute})
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.WaitForReady(true)); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if _, err := client.Check(ctx, large); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Check(large) error = %v, want server-side ResourceExhausted", err)
+	}
+
+	limited := dial(&grpcx.ClientTransportConfig{MaxSendMsgSize: 512})
+	if _, err := limited.Check(ctx, large); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Check(large) error = %v, want client-side ResourceExhausted", err)
+	}
+}
+
+func TestTransportValidation(t *testing.T) {
+	handler := func(*grpc.Server) {}
+	for name, conf := range map[string]*grpcx.ServerConfig{
+		"negative size": {Transport: &grpcx.ServerTransportConfig{MaxRecvMsgSize: -1}},
+		"grace no age":  {Transport: &grpcx.ServerTransportConfig{MaxConnectionAgeGrace: time.Second}},
+	} {
+		conf.Addr = "127.0.0.1:0"
+		conf.DisableMetrics = true
+		if err := grpcx.NewServer(conf).Start(context.Background(), handler); !errors.Is(err, grpcx.ErrInvalidTransportConfig) {
+			t.Fatalf("%s: Start() error = %v", name, err)
+		}
+	}
+	server := grpcx.NewServer(&grpcx.ServerConfig{
+		Addr:            "127.0.0.1:0",
+		Transport:       &grpcx.ServerTransportConfig{},
+		KeepaliveParams: &keepalive.ServerParameters{},
+		DisableMetrics:  true,
+	})
+	if err := server.Start(context.Background(), handler); !errors.Is(err, grpcx.ErrKeepaliveConflict) {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	client := grpcx.NewClient(&grpcx.ClientConfig{
+		Addr:           "127.0.0.1:1",
+		Transport:      &grpcx.ClientTransportConfig{KeepaliveTime: time.Second},
+		DisableMetrics: true,
+	})
+	if _, err := client.Dial(); !errors.Is(err, grpcx.ErrInvalidTransportConfig) {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	client = grpcx.NewClient(&grpcx.ClientConfig{
+		Addr:            "127.0.0.1:1",
+		Transport:       &grpcx.ClientTransportConfig{},
+		KeepaliveParams: &keepalive.ClientParameters{},
+		DisableMetrics:  true,
+	})
+	if _, err := client.Dial(); !errors.Is(err, grpcx.ErrKeepaliveConflict) {
+		t.Fatalf("Dial() error = %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bang-go/micro/internal/breaker"
+)
+
+const defaultBreakerConsecutiveFailures = 5
+
+type BreakerState = breaker.State
+
+const (
+	BreakerClosed   = breaker.Closed
+	BreakerHalfOpen = breaker.HalfOpen
+	BreakerOpen     = breaker.Open
+)
+
+// BreakerConfig trips a host's breaker when its error rate over the rolling
+// Window reaches ErrorRate with at least MinRequests requests, or after
+// ConsecutiveFailures failures in a row. After OpenTimeout the breaker lets
+// HalfOpenRequests probes through; it closes when they all succeed and opens
+// again on the first failure.
+type BreakerConfig struct {
+	Window      time.Duration
+	Buckets     int
+	MinRequests int
+	ErrorRate   float64
+	// ConsecutiveFailures defaults to 5; negative disables the check.
+	ConsecutiveFailures int
+	OpenTimeout         time.Duration
+	HalfOpenRequests    int
+	// IsFailure decides which outcomes count against the host; by default
+	// transport errors and 5xx responses do. Requests canceled by the caller
+	// are never counted.
+	IsFailure func(*http.Response, error) bool
+	// OnStateChange is called outside the breaker lock on every transition.
+	OnStateChange func(host string, from, to BreakerState)
+}
+
+// breakerTransport keeps one circuit per request host, so a failing
+// downstream fails fast instead of holding callers for the full timeout.
+type breakerTransport struct {
+	next      http.RoundTripper
+	isFailure func(*http.Response, error) bool
+	circuits  *breaker.Group
+	metrics   *metrics
+}
+
+func newBreakerTransport(conf *BreakerConfig, next http.RoundTripper, metrics *metrics) *breakerTransport {
+	t := &breakerTransport{next: next, isFailure: conf.IsFailure, metrics: metrics}
+	if t.isFailure == nil {
+		t.isFailure = isBreakerFailure
+	}
+	consecutive := conf.ConsecutiveFailures
+	if consecutive == 0 {
+		consecutive = defaultBreakerConsecutiveFailures
+	}
+	t.circuits = breaker.NewGroup(breaker.Config{
+		Window:              conf.Window,
+		Buckets:             conf.Buckets,
+		MinRequests:         conf.MinRequests,
+		ErrorRate:           conf.ErrorRate,
+		ConsecutiveFailures: consecutive,
+		OpenTimeout:         conf.OpenTimeout,
+		HalfOpenRequests:    conf.HalfOpenRequests,
+	}, func(host string) {
+		if metrics != nil {
+			metrics.clientBreakerState.WithLabelValues(host).Set(float64(BreakerClosed))
+		}
+	}, func(host string, from, to BreakerState) {
+		if metrics != nil {
+			metrics.clientBreakerState.WithLabelValues(host).Set(float64(to))
+		}
+		if conf.OnStateChange != nil {
+			conf.OnStateChange(host, from, to)
+		}
+	})
+	return t
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	generation, ok := t.circuits.Allow(host)
+	if !ok {
+		if t.metrics != nil {
+			t.metrics.clientBreakerRejectionsTotal.WithLabelValues(host).Inc()
+		}
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+	}
+	resp, err := t.next.RoundTrip(req)
+	if errors.Is(err, context.Canceled) {
+		// The caller gave up; that says nothing about the host.
+		t.circuits.Release(host, generation)
+		return resp, err
+	}
+	t.circuits.Done(host, generation, t.isFailure(resp, err))
+	return resp, err
+}
+
+func (t *breakerTransport) CloseIdleConnections() {
+	if closer, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func isBreakerFailure(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
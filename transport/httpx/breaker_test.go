@@ -0,0 +1,139 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/httpx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestClientBreakerOpensPerHost(t *testing.T) {
+	t.Parallel()
+
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		status := http.StatusOK
+		if r.URL.Host == "down.example" && !healthy.Load() {
+			status = http.StatusBadGateway
+		}
+		return &http.Response{StatusCode: status, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+	var transitions []string
+	reg := prometheus.NewRegistry()
+	client := httpx.NewClient(&httpx.ClientConfig{
+		HTTPClient:        &http.Client{Transport: transport},
+		MetricsRegisterer: reg,
+		CircuitBreaker: &httpx.BreakerConfig{
+			ConsecutiveFailures: 3,
+			OpenTimeout:         50 * time.Millisecond,
+			OnStateChange: func(host string, from, to httpx.BreakerState) {
+				transitions = append(transitions, host+":"+from.String()+"->"+to.String())
+			},
+		},
+	})
+	get := func(host string) error {
+		_, err := client.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: "http://" + host + "/"})
+		return err
+	}
+
+	for range 3 {
+		if err := get("down.example"); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+	}
+	before := calls.Load()
+	if err := get("down.example"); !errors.Is(err, httpx.ErrCircuitOpen) {
+		t.Fatalf("Do() error = %v, want ErrCircuitOpen", err)
+	}
+	if calls.Load() != before {
+		t.Fatal("open breaker let the request through")
+	}
+	if err := get("up.example"); err != nil {
+		t.Fatalf("other host rejected: %v", err)
+	}
+
+	expected := `
+# HELP httpx_client_breaker_state HTTP client circuit breaker state by host: 0 closed, 1 half-open, 2 open.
+# TYPE httpx_client_breaker_state gauge
+httpx_client_breaker_state{host="down.example"} 2
+httpx_client_breaker_state{host="up.example"} 0
+# HELP httpx_client_breaker_rejections_total Total number of HTTP client requests rejected by an open circuit breaker.
+# TYPE httpx_client_breaker_rejections_total counter
+httpx_client_breaker_rejections_total{host="down.example"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "httpx_client_breaker_state", "httpx_client_breaker_rejections_total"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	healthy.Store(true)
+	if err := get("down.example"); err != nil {
+		t.Fatalf("half-open probe error = %v", err)
+	}
+	if err := get("down.example"); err != nil {
+		t.Fatalf("Do() after recovery error = %v", err)
+	}
+	want := []string{
+		"down.example:closed->open",
+		"down.example:open->half_open",
+		"down.example:half_open->closed",
+	}
+	if strings.Join(transitions, ",") != strings.Join(want, ",") {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+}
+
+func TestClientBreakerErrorRateIgnoresCancellation(t *testing.T) {
+	t.Parallel()
+
+	var fail atomic.Bool
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if err := r.Context().Err(); err != nil {
+			return nil, err
+		}
+		if fail.Load() {
+			return nil, errors.New("connection refused")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+	client := httpx.NewClient(&httpx.ClientConfig{
+		HTTPClient:     &http.Client{Transport: transport},
+		DisableMetrics: true,
+		CircuitBreaker: &httpx.BreakerConfig{
+			MinRequests:         4,
+			ErrorRate:           0.5,
+			ConsecutiveFailures: -1,
+		},
+	})
+	do := func(ctx context.Context) error {
+		_, err := client.Do(ctx, &httpx.Request{Method: httpx.MethodGet, URL: "http://api.example/"})
+		return err
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 5 {
+		_ = do(canceled)
+	}
+	if err := do(context.Background()); err != nil {
+		t.Fatalf("cancellations tripped the breaker: %v", err)
+	}
+
+	_ = do(context.Background())
+	fail.Store(true)
+	_ = do(context.Background())
+	_ = do(context.Background())
+	if err := do(context.Background()); !errors.Is(err, httpx.ErrCircuitOpen) {
+		t.Fatalf("Do() error = %v, want ErrCircuitOpen", err)
+	}
+}
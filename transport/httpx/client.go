@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/bang-go/micro/pkg/budget"
 	"github.com/bang-go/micro/telemetry/logger"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
@@ -41,6 +42,7 @@ const (
 
 type Client interface {
 	Do(context.Context, *Request) (*Response, error)
+	SendStream(context.Context, *Request) (*StreamResponse, error)
 	HTTPClient() *http.Client
 	CloseIdleConnections()
 }
@@ -48,9 +50,11 @@ type Client interface {
 type clientEntity struct {
 	config                 *ClientConfig
 	httpClient             *http.Client
+	streamClient           *http.Client
 	closeIdleConnectionsFn func()
 	skipPaths              map[string]struct{}
 	metrics                *metrics
+	mirror                 *mirror
 }
 
 func NewClient(conf *ClientConfig) Client {
@@ -72,20 +76,37 @@ func NewClient(conf *ClientConfig) Client {
 	httpClient := newBaseHTTPClient(conf)
 	skipPaths := newSkipPathSet(nil, conf.ObservabilitySkipPaths)
 	transport, closeIdleConnectionsFn := buildClientTransport(conf, httpClient.Transport)
+	if conf.Signer != nil {
+		transport = &signingTransport{next: transport, signer: conf.Signer}
+	}
+	if conf.CircuitBreaker != nil {
+		transport = newBreakerTransport(conf.CircuitBreaker, transport, metrics)
+	}
+	// Outside the breaker, so requests the limiter rejects do not count as
+	// host failures.
+	if conf.RateLimit != nil {
+		transport = newLimitTransport(conf.RateLimit, transport, metrics)
+	}
 	if conf.Trace {
-		httpClient.Transport = otelhttp.NewTransport(transport, otelhttp.WithFilter(func(r *http.Request) bool {
+		httpClient.Transport = otelhttp.NewTransport(transport, traceOptions(conf.Propagators, otelhttp.WithFilter(func(r *http.Request) bool {
 			return !matchesPath(skipPaths, r.URL.Path)
-		}))
+		}))...)
 	} else {
 		httpClient.Transport = transport
 	}
 
+	// SendStream enforces Timeout itself, up to the response headers only.
+	streamClient := *httpClient
+	streamClient.Timeout = 0
+
 	return &clientEntity{
 		config:                 conf,
 		httpClient:             httpClient,
+		streamClient:           &streamClient,
 		closeIdleConnectionsFn: closeIdleConnectionsFn,
 		skipPaths:              skipPaths,
 		metrics:                metrics,
+		mirror:                 newMirror(conf.Mirror, httpClient, metrics, conf.Logger),
 	}
 }
 
@@ -93,10 +114,17 @@ func (c *clientEntity) Do(ctx context.Context, req *Request) (*Response, error)
 	if err := validateContext(ctx); err != nil {
 		return nil, err
 	}
+	// Within a server call that carries a deadline budget, leave the caller
+	// time to handle the outcome.
+	ctx, cancel := budget.Context(ctx, c.httpClient.Timeout)
+	defer cancel()
 	httpReq, err := req.Build(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if c.mirror != nil {
+		c.mirror.send(httpReq)
+	}
 
 	start := time.Now()
 	httpResp, err := c.httpClient.Do(httpReq)
@@ -252,6 +280,7 @@ func buildClientTransport(conf *ClientConfig, existing http.RoundTripper) (http.
 }
 
 func applyClientTransportConfig(conf *ClientConfig, transport *http.Transport) {
+	transport.Proxy = clientProxy(conf, transport.Proxy)
 	if conf.MaxIdleConns > 0 {
 		transport.MaxIdleConns = conf.MaxIdleConns
 	}
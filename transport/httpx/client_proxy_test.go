@@ -0,0 +1,224 @@
+package httpx_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bang-go/micro/transport/httpx"
+)
+
+func TestClientProxyURL(t *testing.T) {
+	t.Parallel()
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "direct")
+	}))
+	defer target.Close()
+
+	newProxy := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			want := "Basic " + base64.StdEncoding.EncodeToString([]byte("alice:s3cret"))
+			if got := r.Header.Get("Proxy-Authorization"); got != want {
+				http.Error(w, "proxy auth required", http.StatusProxyAuthRequired)
+				return
+			}
+			_, _ = io.WriteString(w, name+" "+r.URL.String())
+		}))
+	}
+	primary := newProxy("primary")
+	defer primary.Close()
+	secondary := newProxy("secondary")
+	defer secondary.Close()
+
+	client := httpx.NewClient(&httpx.ClientConfig{
+		ProxyURL:       "http://alice:s3cret@" + primary.Listener.Addr().String(),
+		DisableMetrics: true,
+	})
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name     string
+		proxyURL string
+		want     string
+	}{
+		{name: "client", want: "primary " + target.URL + "/items"},
+		{name: "request", proxyURL: "http://alice:s3cret@" + secondary.Listener.Addr().String(), want: "secondary " + target.URL + "/items"},
+		{name: "direct", proxyURL: httpx.ProxyDirect, want: "direct"},
+	} {
+		resp, err := client.Do(ctx, &httpx.Request{Method: httpx.MethodGet, URL: target.URL + "/items", ProxyURL: tc.proxyURL})
+		if err != nil {
+			t.Fatalf("%s: Do() error = %v", tc.name, err)
+		}
+		if got := resp.Text(); got != tc.want {
+			t.Fatalf("%s: body = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestClientSOCKS5Proxy(t *testing.T) {
+	t.Parallel()
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer target.Close()
+
+	proxy := startSOCKS5(t, "alice", "s3cret")
+	client := httpx.NewClient(&httpx.ClientConfig{
+		ProxyURL:       "socks5://alice:s3cret@" + proxy.addr,
+		DisableMetrics: true,
+	})
+
+	resp, err := client.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: target.URL})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.Text() != "ok" || proxy.connects.Load() != 1 {
+		t.Fatalf("body = %q, proxied connects = %d", resp.Text(), proxy.connects.Load())
+	}
+
+	bad := httpx.NewClient(&httpx.ClientConfig{
+		ProxyURL:       "socks5://alice:wrong@" + proxy.addr,
+		DisableMetrics: true,
+	})
+	if _, err := bad.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: target.URL}); err == nil {
+		t.Fatal("expected wrong SOCKS5 credentials to fail")
+	}
+}
+
+func TestClientProxyURLValidation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	for _, raw := range []string{"ftp://proxy:21", "socks5://", "http://[::1"} {
+		req := &httpx.Request{Method: httpx.MethodGet, URL: "http://example.com", ProxyURL: raw}
+		if _, err := req.Build(ctx); !errors.Is(err, httpx.ErrProxyURLInvalid) {
+			t.Fatalf("Build() with proxy %q error = %v", raw, err)
+		}
+	}
+
+	client := httpx.NewClient(&httpx.ClientConfig{ProxyURL: "gopher://proxy:70", DisableMetrics: true})
+	if _, err := client.Do(ctx, &httpx.Request{Method: httpx.MethodGet, URL: "http://example.com"}); !errors.Is(err, httpx.ErrProxyURLInvalid) {
+		t.Fatalf("Do() with invalid client proxy error = %v", err)
+	}
+}
+
+type socks5Server struct {
+	addr     string
+	connects atomic.Int32
+}
+
+// startSOCKS5 serves the RFC 1928 CONNECT command with RFC 1929
+// username/password authentication.
+func startSOCKS5(t *testing.T, username, password string) *socks5Server {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	server := &socks5Server{addr: ln.Addr().String()}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go server.handle(conn, username, password)
+		}
+	}()
+	return server
+}
+
+func (s *socks5Server) handle(conn net.Conn, username, password string) {
+	defer conn.Close()
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil || header[0] != 5 {
+		return
+	}
+	if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+		return
+	}
+	if _, err := conn.Write([]byte{5, 2}); err != nil {
+		return
+	}
+
+	readField := func() (string, error) {
+		size := make([]byte, 1)
+		if _, err := io.ReadFull(conn, size); err != nil {
+			return "", err
+		}
+		field := make([]byte, size[0])
+		_, err := io.ReadFull(conn, field)
+		return string(field), err
+	}
+	if _, err := io.ReadFull(conn, make([]byte, 1)); err != nil {
+		return
+	}
+	user, err := readField()
+	if err != nil {
+		return
+	}
+	pass, err := readField()
+	if err != nil {
+		return
+	}
+	if user != username || pass != password {
+		_, _ = conn.Write([]byte{1, 1})
+		return
+	}
+	if _, err := conn.Write([]byte{1, 0}); err != nil {
+		return
+	}
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil || request[1] != 1 {
+		return
+	}
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, net.IPv4len)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return
+		}
+		host = net.IP(ip).String()
+	case 3:
+		if host, err = readField(); err != nil {
+			return
+		}
+	default:
+		return
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return
+	}
+
+	upstream, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+	s.connects.Add(1)
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return
+	}
+	go func() {
+		_, _ = io.Copy(upstream, conn)
+		_ = upstream.Close()
+	}()
+	_, _ = io.Copy(conn, upstream)
+}
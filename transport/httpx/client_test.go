@@ -12,8 +12,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bang-go/micro/pkg/budget"
+	"github.com/bang-go/micro/telemetry/trace"
 	"github.com/bang-go/micro/transport/httpx"
 	"github.com/prometheus/client_golang/prometheus"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 func TestClientDoJSONAndSnapshot(t *testing.T) {
@@ -267,6 +270,31 @@ func TestRequestValidation(t *testing.T) {
 	}
 }
 
+func TestClientDoCapsTimeoutToDeadlineBudget(t *testing.T) {
+	t.Parallel()
+
+	remaining := make(chan time.Duration, 1)
+	client := httpx.NewClient(&httpx.ClientConfig{
+		Timeout: time.Minute,
+		HTTPClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			deadline, _ := r.Context().Deadline()
+			remaining <- time.Until(deadline)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+		})},
+		DisableMetrics: true,
+	})
+
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ctx := budget.WithPolicy(parent, budget.Policy{Fraction: 0.5})
+	if _, err := client.Do(ctx, &httpx.Request{Method: httpx.MethodGet, URL: "http://example.com"}); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got := <-remaining; got > 500*time.Millisecond || got <= 0 {
+		t.Fatalf("request deadline in %v, want within half of the 1s budget", got)
+	}
+}
+
 func TestClientDoRequiresContext(t *testing.T) {
 	t.Parallel()
 
@@ -348,3 +376,43 @@ type roundTripFunc func(*http.Request) (*http.Response, error)
 func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
 	return f(r)
 }
+
+func TestClientTracePropagators(t *testing.T) {
+	t.Parallel()
+
+	propagator, err := trace.NewPropagator(trace.PropagatorB3Multi)
+	if err != nil {
+		t.Fatalf("NewPropagator() error = %v", err)
+	}
+	headers := make(chan http.Header, 1)
+	client := httpx.NewClient(&httpx.ClientConfig{
+		Trace:          true,
+		Propagators:    propagator,
+		DisableMetrics: true,
+		HTTPClient: &http.Client{
+			Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				headers <- r.Header.Clone()
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Header: http.Header{}}, nil
+			}),
+		},
+	})
+
+	traceID, _ := oteltrace.TraceIDFromHex("80f198ee56343ba864fe8b2a57d3eff7")
+	spanID, _ := oteltrace.SpanIDFromHex("e457b5a2e4d86bd1")
+	ctx := oteltrace.ContextWithSpanContext(context.Background(), oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: oteltrace.FlagsSampled,
+	}))
+	if _, err := client.Do(ctx, &httpx.Request{Method: httpx.MethodGet, URL: "http://example.com"}); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	got := <-headers
+	if got.Get("X-B3-TraceId") != traceID.String() {
+		t.Fatalf("X-B3-TraceId = %q, headers = %v", got.Get("X-B3-TraceId"), got)
+	}
+	if got.Get("Traceparent") != "" {
+		t.Fatalf("traceparent injected by the global propagator: %v", got)
+	}
+}
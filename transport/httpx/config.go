@@ -1,18 +1,23 @@
 package httpx
 
 import (
+	"crypto/tls"
 	"net"
 	"net/http"
 	"time"
 
 	"github.com/bang-go/micro/telemetry/logger"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 type ClientConfig struct {
 	Logger       *logger.Logger
 	EnableLogger bool
 	Trace        bool
+	// Propagators overrides the global propagator, e.g. to accept and emit B3
+	// next to W3C headers; see trace.NewPropagator.
+	Propagators propagation.TextMapPropagator
 
 	Timeout time.Duration
 
@@ -22,6 +27,12 @@ type ClientConfig struct {
 	CheckRedirect func(*http.Request, []*http.Request) error
 	Jar           http.CookieJar
 
+	// ProxyURL routes requests through an http, https, socks5 or socks5h
+	// proxy, with credentials in the userinfo. Empty honors HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY; ProxyDirect disables proxying. Request.ProxyURL
+	// overrides it per request. Both need an *http.Transport.
+	ProxyURL string
+
 	MaxIdleConns          int
 	MaxIdleConnsPerHost   int
 	MaxConnsPerHost       int
@@ -30,6 +41,18 @@ type ClientConfig struct {
 	ResponseHeaderTimeout time.Duration
 	ExpectContinueTimeout time.Duration
 
+	Mirror *MirrorConfig
+	// CircuitBreaker fails requests to a failing host fast with ErrCircuitOpen
+	// instead of waiting for the timeout. Disabled by default.
+	CircuitBreaker *BreakerConfig
+	// RateLimit throttles requests per host by QPS and in-flight count,
+	// waiting or failing with ErrRateLimited per its Policy. Disabled by
+	// default.
+	RateLimit *RateLimitConfig
+	// Signer signs every outgoing request, e.g. &HMACSigner{...} or
+	// &AliyunSigner{...}. Nil sends requests unsigned.
+	Signer RequestSigner
+
 	// ObservabilitySkipPaths skips metrics, tracing, and access logging for
 	// matching request paths. Client side defaults to none.
 	ObservabilitySkipPaths []string
@@ -40,16 +63,36 @@ type ClientConfig struct {
 type ServerConfig struct {
 	Addr     string
 	Listener net.Listener
+	// ListenConfig opens the listener for Addr, e.g. to set socket options
+	// through Control. Ignored when Listener is set.
+	ListenConfig *net.ListenConfig
+	// ReusePort listens on Addr with SO_REUSEPORT, so several processes can
+	// share the port during a rolling restart. Ignored when Listener is set.
+	ReusePort bool
+
+	// TLS serves HTTPS from certificate files or PEM, with optional mTLS and
+	// hot reload; TLSConfig gives full control. They cannot both be set.
+	TLS       *ServerTLSConfig
+	TLSConfig *tls.Config
+	// H2C serves HTTP/2 without TLS to clients with prior knowledge, e.g.
+	// behind a proxy or mesh sidecar that speaks h2c upstream. HTTP/1.1 keeps
+	// working on the same port.
+	H2C bool
 
 	Logger       *logger.Logger
 	EnableLogger bool
 	Trace        bool
+	// Propagators overrides the global propagator, e.g. to accept and emit B3
+	// next to W3C headers; see trace.NewPropagator.
+	Propagators propagation.TextMapPropagator
 
 	ReadTimeout       time.Duration
 	ReadHeaderTimeout time.Duration
 	WriteTimeout      time.Duration
 	IdleTimeout       time.Duration
 	ShutdownTimeout   time.Duration
+	// MaxHeaderBytes caps request header size; zero uses net/http's 1 MiB.
+	MaxHeaderBytes int
 
 	// ObservabilitySkipPaths skips metrics, tracing, and access logging for
 	// matching request paths. /metrics is always skipped; the default health
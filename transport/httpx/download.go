@@ -0,0 +1,190 @@
+package httpx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const partialSuffix = ".part"
+
+// DownloadOptions controls DownloadToFile. The zero value downloads from
+// scratch without verification.
+type DownloadOptions struct {
+	// Resume continues an interrupted download from path+".part" with a Range
+	// request. Servers that ignore Range restart the download. The remote file
+	// is assumed unchanged; set Checksum to catch the case where it did.
+	Resume bool
+	// Checksum is the expected hex digest of the whole file. On mismatch the
+	// partial file is removed and ErrChecksumMismatch returned.
+	Checksum string
+	// Hash builds the digest for Checksum, default SHA-256.
+	Hash func() hash.Hash
+	// Progress is called after each write with the bytes on disk and the
+	// expected total, or -1 when the server did not say.
+	Progress func(written, total int64)
+}
+
+type DownloadResult struct {
+	Path     string
+	Size     int64
+	Resumed  bool
+	Checksum string
+}
+
+// DownloadToFile streams req's response body to path. Data goes to
+// path+".part" first and is renamed into place only once complete and
+// verified, so path never holds a truncated file.
+func DownloadToFile(ctx context.Context, client Client, req *Request, path string, opts *DownloadOptions) (*DownloadResult, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, ErrNilClient
+	}
+	if req == nil {
+		return nil, ErrNilRequest
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, ErrDownloadPathRequired
+	}
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	newHash := opts.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+
+	partial := path + partialSuffix
+	var offset int64
+	if opts.Resume {
+		if info, err := os.Stat(partial); err == nil {
+			offset = info.Size()
+		}
+	} else {
+		_ = os.Remove(partial)
+	}
+
+	ranged := *req
+	if ranged.Method == "" {
+		ranged.Method = MethodGet
+	}
+	ranged.Header = cloneHeader(req.Header)
+	if offset > 0 {
+		ranged.SetHeader("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	resp, err := client.SendStream(ctx, &ranged)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+
+	total := int64(-1)
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		if size, ok := contentRangeTotal(resp.Header.Get("Content-Range")); ok {
+			total = size
+		} else if resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+	case offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file already holds the whole body.
+		if size, ok := contentRangeTotal(resp.Header.Get("Content-Range")); !ok || size != offset {
+			return nil, fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+		}
+		return finishDownload(partial, path, offset, true, newHash, opts.Checksum)
+	case resp.Success():
+		offset = 0
+		total = resp.ContentLength
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+
+	file, err := os.OpenFile(partial, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: open download file: %w", err)
+	}
+	written, copyErr := io.Copy(&progressWriter{w: file, written: offset, total: total, progress: opts.Progress}, resp)
+	closeErr := file.Close()
+	if err := errors.Join(copyErr, closeErr); err != nil {
+		return nil, fmt.Errorf("httpx: download: %w", err)
+	}
+	size := offset + written
+	if total >= 0 && size != total {
+		return nil, fmt.Errorf("httpx: download: %w: got %d of %d bytes", io.ErrUnexpectedEOF, size, total)
+	}
+	return finishDownload(partial, path, size, offset > 0, newHash, opts.Checksum)
+}
+
+func finishDownload(partial, path string, size int64, resumed bool, newHash func() hash.Hash, expected string) (*DownloadResult, error) {
+	result := &DownloadResult{Path: path, Size: size, Resumed: resumed}
+	if expected != "" {
+		// Hash the file on disk rather than the stream, so resumed downloads
+		// are verified as a whole.
+		sum, err := fileDigest(partial, newHash)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(sum, strings.TrimSpace(expected)) {
+			_ = os.Remove(partial)
+			return nil, fmt.Errorf("%w: got %s, want %s", ErrChecksumMismatch, sum, expected)
+		}
+		result.Checksum = sum
+	}
+	if err := os.Rename(partial, path); err != nil {
+		return nil, fmt.Errorf("httpx: move download into place: %w", err)
+	}
+	return result, nil
+}
+
+func fileDigest(path string, newHash func() hash.Hash) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("httpx: open download file: %w", err)
+	}
+	defer file.Close()
+	h := newHash()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("httpx: hash download file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// contentRangeTotal returns the complete length from "bytes 0-99/1000" or
+// "bytes */1000".
+func contentRangeTotal(header string) (int64, bool) {
+	_, size, ok := strings.Cut(header, "/")
+	if !ok || size == "*" {
+		return 0, false
+	}
+	total, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+	return total, err == nil
+}
+
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if p.progress != nil && n > 0 {
+		p.progress(p.written, p.total)
+	}
+	return n, err
+}
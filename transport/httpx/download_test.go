@@ -0,0 +1,135 @@
+package httpx_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/httpx"
+)
+
+func newDownloadServer(t *testing.T, content []byte, honorRange bool) (*httptest.Server, *atomic.Value) {
+	t.Helper()
+	var lastRange atomic.Value
+	lastRange.Store("")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRange.Store(r.Header.Get("Range"))
+		if !honorRange {
+			r.Header.Del("Range")
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, &lastRange
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloadToFileVerifiesAndReportsProgress(t *testing.T) {
+	t.Parallel()
+
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	server, _ := newDownloadServer(t, content, true)
+	client := httpx.NewClient(&httpx.ClientConfig{DisableMetrics: true})
+	path := filepath.Join(t.TempDir(), "file.bin")
+
+	var last, total int64
+	result, err := httpx.DownloadToFile(context.Background(), client, &httpx.Request{URL: server.URL}, path, &httpx.DownloadOptions{
+		Checksum: sha256Hex(content),
+		Progress: func(written, size int64) { last, total = written, size },
+	})
+	if err != nil {
+		t.Fatalf("DownloadToFile() error = %v", err)
+	}
+	if result.Size != int64(len(content)) || result.Resumed || result.Checksum != sha256Hex(content) {
+		t.Fatalf("result = %+v", result)
+	}
+	if last != int64(len(content)) || total != int64(len(content)) {
+		t.Fatalf("progress = %d/%d, want %d/%d", last, total, len(content), len(content))
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, content) {
+		t.Fatal("downloaded file differs")
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Fatalf("partial file left behind: %v", err)
+	}
+}
+
+func TestDownloadToFileResumes(t *testing.T) {
+	t.Parallel()
+
+	content := bytes.Repeat([]byte("abcdefghij"), 1000)
+	for _, tt := range []struct {
+		name        string
+		honorRange  bool
+		wantResumed bool
+	}{
+		{"ranged", true, true},
+		{"server ignores range", false, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server, lastRange := newDownloadServer(t, content, tt.honorRange)
+			client := httpx.NewClient(&httpx.ClientConfig{DisableMetrics: true})
+			path := filepath.Join(t.TempDir(), "file.bin")
+			if err := os.WriteFile(path+".part", content[:4000], 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := httpx.DownloadToFile(context.Background(), client, &httpx.Request{URL: server.URL}, path, &httpx.DownloadOptions{
+				Resume:   true,
+				Checksum: sha256Hex(content),
+			})
+			if err != nil {
+				t.Fatalf("DownloadToFile() error = %v", err)
+			}
+			if got := lastRange.Load().(string); got != "bytes=4000-" {
+				t.Fatalf("Range = %q, want bytes=4000-", got)
+			}
+			if result.Resumed != tt.wantResumed || result.Size != int64(len(content)) {
+				t.Fatalf("result = %+v", result)
+			}
+			if got, _ := os.ReadFile(path); !bytes.Equal(got, content) {
+				t.Fatal("downloaded file differs")
+			}
+		})
+	}
+}
+
+func TestDownloadToFileRejectsBadChecksumAndStatus(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newDownloadServer(t, []byte("payload"), true)
+	client := httpx.NewClient(&httpx.ClientConfig{DisableMetrics: true})
+	path := filepath.Join(t.TempDir(), "file.bin")
+
+	_, err := httpx.DownloadToFile(context.Background(), client, &httpx.Request{URL: server.URL}, path, &httpx.DownloadOptions{Checksum: sha256Hex([]byte("other"))})
+	if !errors.Is(err, httpx.ErrChecksumMismatch) {
+		t.Fatalf("DownloadToFile() error = %v, want ErrChecksumMismatch", err)
+	}
+	for _, p := range []string{path, path + ".part"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("%s exists after checksum mismatch", p)
+		}
+	}
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	if _, err := httpx.DownloadToFile(context.Background(), client, &httpx.Request{URL: missing.URL}, path, nil); !errors.Is(err, httpx.ErrUnexpectedStatus) {
+		t.Fatalf("DownloadToFile() error = %v, want ErrUnexpectedStatus", err)
+	}
+	if _, err := httpx.DownloadToFile(context.Background(), client, &httpx.Request{URL: server.URL}, " ", nil); !errors.Is(err, httpx.ErrDownloadPathRequired) {
+		t.Fatalf("DownloadToFile() error = %v, want ErrDownloadPathRequired", err)
+	}
+}
@@ -13,4 +13,25 @@ var (
 	ErrNilListener                = errors.New("httpx: listener is required")
 	ErrServerAddrRequired         = errors.New("httpx: server addr or listener is required")
 	ErrServerAlreadyRunning       = errors.New("httpx: server already running")
+	ErrTLSCertificateRequired     = errors.New("httpx: tls certificate is required")
+	ErrTLSKeyPairIncomplete       = errors.New("httpx: tls certificate and key must be set together")
+	ErrInvalidTLSCA               = errors.New("httpx: tls ca contains no certificates")
+	ErrTLSConfigConflict          = errors.New("httpx: TLS and TLSConfig cannot both be set")
+	ErrReusePortUnsupported       = errors.New("httpx: SO_REUSEPORT is not supported on this platform")
+	ErrProxyURLInvalid            = errors.New("httpx: proxy url must be an http, https, socks5 or socks5h url")
+	ErrProxyUpstreamRequired      = errors.New("httpx: proxy upstream is required")
+	ErrProxyUpstreamInvalid       = errors.New("httpx: proxy upstream must be an absolute url")
+	ErrCircuitOpen                = errors.New("httpx: circuit breaker is open")
+	ErrRateLimited                = errors.New("httpx: client rate limit exceeded")
+	ErrNilClient                  = errors.New("httpx: client is required")
+	ErrDownloadPathRequired       = errors.New("httpx: download path is required")
+	ErrUnexpectedStatus           = errors.New("httpx: unexpected response status")
+	ErrChecksumMismatch           = errors.New("httpx: checksum mismatch")
+	ErrNilMultipartBuilder        = errors.New("httpx: multipart builder is required")
+	ErrMultipartFieldRequired     = errors.New("httpx: multipart field name is required")
+	ErrMultipartReaderRequired    = errors.New("httpx: multipart file reader is required")
+	ErrInvalidMultipartBoundary   = errors.New("httpx: invalid multipart boundary")
+	ErrSignerKeyRequired          = errors.New("httpx: signer key and secret are required")
+	ErrSignatureInvalid           = errors.New("httpx: signature invalid")
+	ErrSignatureExpired           = errors.New("httpx: signature timestamp out of range")
 )
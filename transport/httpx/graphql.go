@@ -0,0 +1,203 @@
+package httpx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const persistedQueryNotFound = "PERSISTED_QUERY_NOT_FOUND"
+
+var (
+	ErrGraphQLEndpointRequired = errors.New("httpx: graphql endpoint is required")
+	ErrGraphQLQueryRequired    = errors.New("httpx: graphql query is required")
+	ErrGraphQLStatus           = errors.New("httpx: graphql unexpected status")
+)
+
+type GraphQLConfig struct {
+	// Endpoint is the absolute URL of the GraphQL server.
+	Endpoint string
+	// Client sends the requests; nil uses NewClient(nil).
+	Client Client
+	// Header is added to every request, e.g. vendor API keys.
+	Header http.Header
+	// PersistedQueries sends only the query's SHA-256 hash first and falls
+	// back to the full query when the server has not seen it (Apollo APQ).
+	PersistedQueries bool
+}
+
+type GraphQLRequest struct {
+	Query         string
+	OperationName string
+	Variables     map[string]any
+	Header        http.Header
+}
+
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Path       []any             `json:"path,omitempty"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+// Code returns extensions.code, the conventional machine-readable error code.
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// GraphQLErrors is returned when the response carries an errors list. Data
+// may still be partially populated.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return "httpx: graphql: " + strings.Join(messages, "; ")
+}
+
+type GraphQLResponse struct {
+	Data       json.RawMessage `json:"data"`
+	Errors     GraphQLErrors   `json:"errors,omitempty"`
+	Extensions map[string]any  `json:"extensions,omitempty"`
+	// HTTP is the underlying response, for status and headers.
+	HTTP *Response `json:"-"`
+}
+
+type GraphQLClient struct {
+	config *GraphQLConfig
+	client Client
+}
+
+func NewGraphQLClient(conf *GraphQLConfig) (*GraphQLClient, error) {
+	if conf == nil || strings.TrimSpace(conf.Endpoint) == "" {
+		return nil, ErrGraphQLEndpointRequired
+	}
+	client := conf.Client
+	if client == nil {
+		client = NewClient(nil)
+	}
+	return &GraphQLClient{config: conf, client: client}, nil
+}
+
+// Do sends a query or mutation. The response is returned together with a
+// GraphQLErrors error when the server reported errors.
+func (c *GraphQLClient) Do(ctx context.Context, req *GraphQLRequest) (*GraphQLResponse, error) {
+	if req == nil {
+		return nil, ErrNilRequest
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, ErrGraphQLQueryRequired
+	}
+	if !c.config.PersistedQueries {
+		return c.send(ctx, req, true)
+	}
+
+	resp, err := c.send(ctx, req, false)
+	if err != nil && resp != nil && resp.persistedQueryNotFound() {
+		return c.send(ctx, req, true)
+	}
+	return resp, err
+}
+
+func (c *GraphQLClient) send(ctx context.Context, req *GraphQLRequest, withQuery bool) (*GraphQLResponse, error) {
+	payload := map[string]any{}
+	if withQuery {
+		payload["query"] = req.Query
+	}
+	if req.OperationName != "" {
+		payload["operationName"] = req.OperationName
+	}
+	if len(req.Variables) > 0 {
+		payload["variables"] = req.Variables
+	}
+	if c.config.PersistedQueries {
+		hash := sha256.Sum256([]byte(req.Query))
+		payload["extensions"] = map[string]any{
+			"persistedQuery": map[string]any{"version": 1, "sha256Hash": hex.EncodeToString(hash[:])},
+		}
+	}
+
+	httpReq := &Request{Method: MethodPost, URL: c.config.Endpoint, Header: cloneHeader(c.config.Header)}
+	for key, values := range req.Header {
+		for _, value := range values {
+			httpReq.AddHeader(key, value)
+		}
+	}
+	httpReq.SetHeader("Accept", ContentTypeJSON)
+	if err := httpReq.SetJSONBody(payload); err != nil {
+		return nil, err
+	}
+
+	httpResp, err := c.client.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	resp := &GraphQLResponse{HTTP: httpResp}
+	// Servers may answer errors with a 4xx/5xx status and a GraphQL body,
+	// which is more useful than the status alone.
+	if decodeErr := json.Unmarshal(httpResp.Body, resp); decodeErr != nil || (resp.Data == nil && len(resp.Errors) == 0) {
+		if !httpResp.Success() {
+			return resp, fmt.Errorf("%w: %s", ErrGraphQLStatus, httpResp.Status)
+		}
+		if decodeErr != nil {
+			return resp, fmt.Errorf("httpx: decode graphql response: %w", decodeErr)
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return resp, resp.Errors
+	}
+	if !httpResp.Success() {
+		return resp, fmt.Errorf("%w: %s", ErrGraphQLStatus, httpResp.Status)
+	}
+	return resp, nil
+}
+
+func (r *GraphQLResponse) persistedQueryNotFound() bool {
+	for _, err := range r.Errors {
+		if err.Code() == persistedQueryNotFound || err.Message == "PersistedQueryNotFound" {
+			return true
+		}
+	}
+	return false
+}
+
+// Decode unmarshals data into dst; null data leaves dst unchanged.
+func (r *GraphQLResponse) Decode(dst any) error {
+	if r == nil {
+		return ErrNilResponse
+	}
+	if len(r.Data) == 0 || string(r.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(r.Data, dst); err != nil {
+		return fmt.Errorf("httpx: decode graphql data: %w", err)
+	}
+	return nil
+}
+
+// GraphQLQuery sends req and decodes data into T. With partial results both
+// the decoded data and the GraphQLErrors are returned.
+func GraphQLQuery[T any](ctx context.Context, c *GraphQLClient, req *GraphQLRequest) (T, error) {
+	var data T
+	resp, err := c.Do(ctx, req)
+	if resp == nil {
+		return data, err
+	}
+	if decodeErr := resp.Decode(&data); decodeErr != nil {
+		return data, errors.Join(err, decodeErr)
+	}
+	return data, err
+}
@@ -0,0 +1,141 @@
+package httpx_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bang-go/micro/transport/httpx"
+)
+
+type graphqlPayload struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+	Extensions    map[string]any `json:"extensions"`
+}
+
+func newGraphQLClient(t *testing.T, persisted bool, handler func(http.ResponseWriter, graphqlPayload, *http.Request)) *httpx.GraphQLClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload graphqlPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		w.Header().Set("Content-Type", httpx.ContentTypeJSON)
+		handler(w, payload, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := httpx.NewGraphQLClient(&httpx.GraphQLConfig{
+		Endpoint:         server.URL,
+		Client:           httpx.NewClient(&httpx.ClientConfig{DisableMetrics: true}),
+		Header:           http.Header{"X-Api-Key": {"key"}},
+		PersistedQueries: persisted,
+	})
+	if err != nil {
+		t.Fatalf("NewGraphQLClient() error = %v", err)
+	}
+	return client
+}
+
+func TestGraphQLQueryDecodesTypedData(t *testing.T) {
+	t.Parallel()
+
+	client := newGraphQLClient(t, false, func(w http.ResponseWriter, payload graphqlPayload, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" || payload.OperationName != "User" || payload.Variables["id"] != "42" {
+			t.Errorf("unexpected request: %+v %v", payload, r.Header)
+		}
+		_, _ = w.Write([]byte(`{"data":{"user":{"id":"42","name":"ann"}}}`))
+	})
+
+	type userData struct {
+		User struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"user"`
+	}
+	data, err := httpx.GraphQLQuery[userData](context.Background(), client, &httpx.GraphQLRequest{
+		Query:         `query User($id: ID!) { user(id: $id) { id name } }`,
+		OperationName: "User",
+		Variables:     map[string]any{"id": "42"},
+	})
+	if err != nil {
+		t.Fatalf("GraphQLQuery() error = %v", err)
+	}
+	if data.User.Name != "ann" {
+		t.Fatalf("user = %+v", data.User)
+	}
+}
+
+func TestGraphQLErrorsKeepPartialData(t *testing.T) {
+	t.Parallel()
+
+	client := newGraphQLClient(t, false, func(w http.ResponseWriter, _ graphqlPayload, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"a":1,"b":null},"errors":[{"message":"forbidden","path":["b"],"extensions":{"code":"FORBIDDEN"}}]}`))
+	})
+
+	data, err := httpx.GraphQLQuery[map[string]*int](context.Background(), client, &httpx.GraphQLRequest{Query: "{ a b }"})
+	var gqlErrs httpx.GraphQLErrors
+	if !errors.As(err, &gqlErrs) || len(gqlErrs) != 1 || gqlErrs[0].Code() != "FORBIDDEN" {
+		t.Fatalf("error = %v, want GraphQLErrors with FORBIDDEN", err)
+	}
+	if data["a"] == nil || *data["a"] != 1 {
+		t.Fatalf("partial data = %v", data)
+	}
+}
+
+func TestGraphQLStatusWithoutBody(t *testing.T) {
+	t.Parallel()
+
+	client := newGraphQLClient(t, false, func(w http.ResponseWriter, _ graphqlPayload, _ *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	})
+	resp, err := client.Do(context.Background(), &httpx.GraphQLRequest{Query: "{ a }"})
+	if !errors.Is(err, httpx.ErrGraphQLStatus) {
+		t.Fatalf("Do() error = %v, want ErrGraphQLStatus", err)
+	}
+	if resp == nil || resp.HTTP.StatusCode != http.StatusBadGateway {
+		t.Fatalf("response = %+v", resp)
+	}
+	if _, err := client.Do(context.Background(), &httpx.GraphQLRequest{}); !errors.Is(err, httpx.ErrGraphQLQueryRequired) {
+		t.Fatalf("Do() error = %v, want ErrGraphQLQueryRequired", err)
+	}
+}
+
+func TestGraphQLPersistedQueryFallback(t *testing.T) {
+	t.Parallel()
+
+	var registered atomic.Bool
+	var calls atomic.Int32
+	client := newGraphQLClient(t, true, func(w http.ResponseWriter, payload graphqlPayload, _ *http.Request) {
+		calls.Add(1)
+		if _, ok := payload.Extensions["persistedQuery"]; !ok {
+			t.Error("persistedQuery extension missing")
+		}
+		switch {
+		case payload.Query != "":
+			registered.Store(true)
+		case !registered.Load():
+			_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"ok":true}}`))
+	})
+
+	req := &httpx.GraphQLRequest{Query: "{ ok }"}
+	for range 2 {
+		data, err := httpx.GraphQLQuery[struct{ OK bool }](context.Background(), client, req)
+		if err != nil || !data.OK {
+			t.Fatalf("GraphQLQuery() = %+v, %v", data, err)
+		}
+	}
+	// miss + register, then a hash-only hit
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("requests = %d, want 3", got)
+	}
+}
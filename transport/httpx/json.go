@@ -0,0 +1,140 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// requestIDHeaders are checked in order for the ID a server assigned to the
+// call, first on the response and then on the request.
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id", "X-Correlation-Id", "X-Amzn-Requestid"}
+
+// APIError is returned by SendJSON and SendJSONWithError for non-2xx
+// responses. It matches ErrUnexpectedStatus with errors.Is.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	URL        string
+	RequestID  string
+	// Body is the raw response body.
+	Body []byte
+	// Detail is the body decoded into the error type of SendJSONWithError, or
+	// nil when it was not requested or did not decode.
+	Detail any
+	// Response is the full response, for headers and cookies.
+	Response *Response
+}
+
+func (e *APIError) Error() string {
+	if e == nil {
+		return ""
+	}
+	msg := fmt.Sprintf("%v: %s %s: %s", ErrUnexpectedStatus, e.Method, e.URL, e.Status)
+	if e.RequestID != "" {
+		msg += " request_id=" + e.RequestID
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error {
+	return ErrUnexpectedStatus
+}
+
+// APIErrorDetail returns the decoded error body of type E carried by err.
+func APIErrorDetail[E any](err error) (E, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if detail, ok := apiErr.Detail.(E); ok {
+			return detail, true
+		}
+	}
+	var zero E
+	return zero, false
+}
+
+// SendJSON sends req with body encoded as JSON, unless body is nil, and
+// decodes a 2xx response into T. An empty 2xx body leaves T zero. Other
+// statuses return an *APIError. req is not modified.
+func SendJSON[T any](ctx context.Context, c Client, req *Request, body any) (T, error) {
+	var out T
+	resp, err := sendJSON(ctx, c, req, body)
+	if err != nil {
+		return out, err
+	}
+	if !resp.Success() {
+		return out, newAPIError(resp)
+	}
+	if len(strings.TrimSpace(string(resp.Body))) == 0 {
+		return out, nil
+	}
+	if err := json.Unmarshal(resp.Body, &out); err != nil {
+		return out, fmt.Errorf("httpx: decode json response: %w", err)
+	}
+	return out, nil
+}
+
+// SendJSONWithError is SendJSON that also decodes non-2xx bodies into E and
+// stores the result in APIError.Detail; read it back with APIErrorDetail[E].
+func SendJSONWithError[T, E any](ctx context.Context, c Client, req *Request, body any) (T, error) {
+	out, err := SendJSON[T](ctx, c, req, body)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && len(apiErr.Body) > 0 {
+		var detail E
+		if json.Unmarshal(apiErr.Body, &detail) == nil {
+			apiErr.Detail = detail
+		}
+	}
+	return out, err
+}
+
+func sendJSON(ctx context.Context, c Client, req *Request, body any) (*Response, error) {
+	if c == nil {
+		return nil, ErrNilClient
+	}
+	if req == nil {
+		return nil, ErrNilRequest
+	}
+	jsonReq := *req
+	jsonReq.Header = cloneHeader(req.Header)
+	if body != nil {
+		if err := jsonReq.SetJSONBody(body); err != nil {
+			return nil, err
+		}
+	}
+	if jsonReq.Header.Get("Accept") == "" {
+		jsonReq.SetHeader("Accept", ContentTypeJSON)
+	}
+	return c.Do(ctx, &jsonReq)
+}
+
+func newAPIError(resp *Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RequestID:  requestID(resp.Header),
+		Body:       resp.Body,
+		Response:   resp,
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.URL = resp.Request.URL
+		if apiErr.RequestID == "" {
+			apiErr.RequestID = requestID(resp.Request.Header)
+		}
+	}
+	return apiErr
+}
+
+func requestID(header http.Header) string {
+	for _, key := range requestIDHeaders {
+		if id := strings.TrimSpace(header.Get(key)); id != "" {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+package httpx_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bang-go/micro/transport/httpx"
+)
+
+type jsonUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type jsonAPIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newJSONServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, httpx.Client) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server, httpx.NewClient(&httpx.ClientConfig{DisableMetrics: true})
+}
+
+func TestSendJSONEncodesBodyAndDecodesResponse(t *testing.T) {
+	t.Parallel()
+
+	server, client := newJSONServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Content-Type"), httpx.ContentTypeJSON; got != want {
+			t.Errorf("content-type = %q, want %q", got, want)
+		}
+		if got, want := r.Header.Get("Accept"), httpx.ContentTypeJSON; got != want {
+			t.Errorf("accept = %q, want %q", got, want)
+		}
+		var in jsonUser
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		_ = json.NewEncoder(w).Encode(jsonUser{ID: "42", Name: in.Name})
+	})
+
+	req := &httpx.Request{Method: httpx.MethodPost, URL: server.URL + "/users"}
+	user, err := httpx.SendJSON[jsonUser](context.Background(), client, req, jsonUser{Name: "ann"})
+	if err != nil {
+		t.Fatalf("SendJSON() error = %v", err)
+	}
+	if user.ID != "42" || user.Name != "ann" {
+		t.Fatalf("user = %+v", user)
+	}
+	if req.Body != nil || req.Header != nil {
+		t.Fatalf("SendJSON() modified the request: %+v", req)
+	}
+}
+
+func TestSendJSONEmptySuccessBody(t *testing.T) {
+	t.Parallel()
+
+	server, client := newJSONServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	user, err := httpx.SendJSON[*jsonUser](context.Background(), client, &httpx.Request{Method: httpx.MethodDelete, URL: server.URL}, nil)
+	if err != nil {
+		t.Fatalf("SendJSON() error = %v", err)
+	}
+	if user != nil {
+		t.Fatalf("user = %+v, want nil", user)
+	}
+}
+
+func TestSendJSONReturnsAPIError(t *testing.T) {
+	t.Parallel()
+
+	server, client := newJSONServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":"user_not_found","message":"no such user"}`))
+	})
+
+	_, err := httpx.SendJSONWithError[jsonUser, jsonAPIError](context.Background(), client, &httpx.Request{Method: httpx.MethodGet, URL: server.URL + "/users/7"}, nil)
+	if !errors.Is(err, httpx.ErrUnexpectedStatus) {
+		t.Fatalf("SendJSONWithError() error = %v, want %v", err, httpx.ErrUnexpectedStatus)
+	}
+	var apiErr *httpx.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error %T is not *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.RequestID != "req-1" || apiErr.Method != http.MethodGet || apiErr.URL != server.URL+"/users/7" {
+		t.Fatalf("apiErr = %+v", apiErr)
+	}
+	detail, ok := httpx.APIErrorDetail[jsonAPIError](err)
+	if !ok || detail.Code != "user_not_found" {
+		t.Fatalf("APIErrorDetail() = %+v, %v", detail, ok)
+	}
+}
+
+func TestSendJSONAPIErrorWithUndecodableBody(t *testing.T) {
+	t.Parallel()
+
+	server, client := newJSONServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("upstream down"))
+	})
+
+	req := &httpx.Request{Method: httpx.MethodGet, URL: server.URL, Header: http.Header{"X-Request-Id": {"caller-1"}}}
+	_, err := httpx.SendJSONWithError[jsonUser, jsonAPIError](context.Background(), client, req, nil)
+	var apiErr *httpx.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("SendJSONWithError() error = %v, want *APIError", err)
+	}
+	if got, want := string(apiErr.Body), "upstream down"; got != want {
+		t.Fatalf("apiErr.Body = %q, want %q", got, want)
+	}
+	if got, want := apiErr.RequestID, "caller-1"; got != want {
+		t.Fatalf("apiErr.RequestID = %q, want %q", got, want)
+	}
+	if _, ok := httpx.APIErrorDetail[jsonAPIError](err); ok {
+		t.Fatal("APIErrorDetail() ok = true for an undecodable body")
+	}
+}
+
+func TestSendJSONDecodeError(t *testing.T) {
+	t.Parallel()
+
+	server, client := newJSONServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not json"))
+	})
+
+	_, err := httpx.SendJSON[jsonUser](context.Background(), client, &httpx.Request{Method: httpx.MethodGet, URL: server.URL}, nil)
+	if err == nil || errors.Is(err, httpx.ErrUnexpectedStatus) {
+		t.Fatalf("SendJSON() error = %v, want decode error", err)
+	}
+}
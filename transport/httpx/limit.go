@@ -0,0 +1,188 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type LimitPolicy int
+
+const (
+	// LimitWait blocks until the request is admitted or its context ends.
+	LimitWait LimitPolicy = iota
+	// LimitReject fails the request with ErrRateLimited right away.
+	LimitReject
+)
+
+// RateLimitConfig caps what the client sends to each request host: QPS is a
+// token bucket rate and MaxInFlight bounds concurrent requests, counting a
+// request until its response body is closed. Zero disables either limit.
+type RateLimitConfig struct {
+	QPS float64
+	// Burst defaults to ceil(QPS), at least 1.
+	Burst       int
+	MaxInFlight int
+	Policy      LimitPolicy
+}
+
+// limitTransport throttles requests per host so bursty callers cannot
+// overload a downstream.
+type limitTransport struct {
+	next    http.RoundTripper
+	conf    RateLimitConfig
+	metrics *metrics
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimiter
+}
+
+type hostLimiter struct {
+	rate     *rate.Limiter
+	inFlight chan struct{}
+}
+
+func newLimitTransport(conf *RateLimitConfig, next http.RoundTripper, metrics *metrics) *limitTransport {
+	t := &limitTransport{next: next, conf: *conf, metrics: metrics, hosts: make(map[string]*hostLimiter)}
+	if t.conf.QPS > 0 && t.conf.Burst <= 0 {
+		t.conf.Burst = max(1, int(math.Ceil(t.conf.QPS)))
+	}
+	return t
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	limiter := t.limiter(host)
+	release, err := t.acquire(req.Context(), host, limiter)
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+func (t *limitTransport) CloseIdleConnections() {
+	if closer, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (t *limitTransport) limiter(host string) *hostLimiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	l, ok := t.hosts[host]
+	if !ok {
+		l = &hostLimiter{}
+		if t.conf.QPS > 0 {
+			l.rate = rate.NewLimiter(rate.Limit(t.conf.QPS), t.conf.Burst)
+		}
+		if t.conf.MaxInFlight > 0 {
+			l.inFlight = make(chan struct{}, t.conf.MaxInFlight)
+		}
+		t.hosts[host] = l
+	}
+	return l
+}
+
+// acquire takes an in-flight slot and then a token, so a request that waited
+// for a slot does not spend a token that went stale meanwhile.
+func (t *limitTransport) acquire(ctx context.Context, host string, l *hostLimiter) (func(), error) {
+	release := func() {}
+	if l.inFlight != nil {
+		if err := t.acquireSlot(ctx, host, l.inFlight); err != nil {
+			return nil, err
+		}
+		var once sync.Once
+		release = func() {
+			once.Do(func() { <-l.inFlight })
+		}
+	}
+	if l.rate != nil {
+		if err := t.acquireToken(ctx, host, l.rate); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+func (t *limitTransport) acquireSlot(ctx context.Context, host string, slots chan struct{}) error {
+	select {
+	case slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if t.conf.Policy == LimitReject {
+		t.throttled(host, "concurrency", "rejected")
+		return fmt.Errorf("%w: %s: %d requests in flight", ErrRateLimited, host, cap(slots))
+	}
+	t.throttled(host, "concurrency", "delayed")
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *limitTransport) acquireToken(ctx context.Context, host string, limiter *rate.Limiter) error {
+	if t.conf.Policy == LimitReject {
+		if limiter.Allow() {
+			return nil
+		}
+		t.throttled(host, "rate", "rejected")
+		return fmt.Errorf("%w: %s: over %g requests per second", ErrRateLimited, host, t.conf.QPS)
+	}
+
+	reservation := limiter.Reserve()
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+	t.throttled(host, "rate", "delayed")
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		reservation.Cancel()
+		return fmt.Errorf("%w: %s: wait of %s exceeds the context deadline", ErrRateLimited, host, delay)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	}
+}
+
+func (t *limitTransport) throttled(host, limit, action string) {
+	if t.metrics != nil {
+		t.metrics.clientThrottledTotal.WithLabelValues(host, limit, action).Inc()
+	}
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,148 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/httpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func okTransport(calls *atomic.Int32) roundTripFunc {
+	return func(*http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	}
+}
+
+func TestClientRateLimitRejectsPerHost(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	reg := prometheus.NewRegistry()
+	client := httpx.NewClient(&httpx.ClientConfig{
+		HTTPClient:        &http.Client{Transport: okTransport(&calls)},
+		MetricsRegisterer: reg,
+		RateLimit:         &httpx.RateLimitConfig{QPS: 1, Burst: 2, Policy: httpx.LimitReject},
+	})
+	get := func(host string) error {
+		_, err := client.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: "http://" + host + "/"})
+		return err
+	}
+
+	for range 2 {
+		if err := get("a.example"); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+	}
+	if err := get("a.example"); !errors.Is(err, httpx.ErrRateLimited) {
+		t.Fatalf("Do() error = %v, want ErrRateLimited", err)
+	}
+	if err := get("b.example"); err != nil {
+		t.Fatalf("Do(other host) error = %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("calls = %d, want 3", got)
+	}
+	assertCounterValue(t, reg, "httpx_client_throttled_requests_total", map[string]string{"host": "a.example", "limit": "rate", "action": "rejected"}, 1)
+}
+
+func TestClientRateLimitWaits(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	client := httpx.NewClient(&httpx.ClientConfig{
+		DisableMetrics: true,
+		HTTPClient:     &http.Client{Transport: okTransport(&calls)},
+		RateLimit:      &httpx.RateLimitConfig{QPS: 20, Burst: 1},
+	})
+	start := time.Now()
+	for range 3 {
+		if _, err := client.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: "http://a.example/"}); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("3 requests at 20 QPS took %s, want about 100ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	client = httpx.NewClient(&httpx.ClientConfig{
+		DisableMetrics: true,
+		HTTPClient:     &http.Client{Transport: okTransport(&calls)},
+		RateLimit:      &httpx.RateLimitConfig{QPS: 0.1, Burst: 1},
+	})
+	_, _ = client.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: "http://a.example/"})
+	if _, err := client.Do(ctx, &httpx.Request{Method: httpx.MethodGet, URL: "http://a.example/"}); !errors.Is(err, httpx.ErrRateLimited) {
+		t.Fatalf("Do() error = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestClientMaxInFlight(t *testing.T) {
+	t.Parallel()
+
+	var active, peak atomic.Int32
+	release := make(chan struct{})
+	transport := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		n := active.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		active.Add(-1)
+		return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	})
+	client := httpx.NewClient(&httpx.ClientConfig{
+		DisableMetrics: true,
+		HTTPClient:     &http.Client{Transport: transport},
+		RateLimit:      &httpx.RateLimitConfig{MaxInFlight: 2},
+	})
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			if _, err := client.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: "http://a.example/"}); err != nil {
+				t.Errorf("Do() error = %v", err)
+			}
+		})
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := peak.Load(); got != 2 {
+		t.Fatalf("peak in flight = %d, want 2", got)
+	}
+}
+
+func TestClientMaxInFlightHoldsUntilBodyClosed(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	client := httpx.NewClient(&httpx.ClientConfig{
+		DisableMetrics: true,
+		HTTPClient:     &http.Client{Transport: okTransport(&calls)},
+		RateLimit:      &httpx.RateLimitConfig{MaxInFlight: 1, Policy: httpx.LimitReject},
+	})
+	stream, err := client.SendStream(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: "http://a.example/"})
+	if err != nil {
+		t.Fatalf("SendStream() error = %v", err)
+	}
+	if _, err := client.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: "http://a.example/"}); !errors.Is(err, httpx.ErrRateLimited) {
+		t.Fatalf("Do() with open stream error = %v, want ErrRateLimited", err)
+	}
+	_ = stream.Close()
+	if _, err := client.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: "http://a.example/"}); err != nil {
+		t.Fatalf("Do() after close error = %v", err)
+	}
+}
@@ -7,10 +7,19 @@ import (
 )
 
 type metrics struct {
-	clientRequestDuration *prometheus.HistogramVec
-	clientRequestsTotal   *prometheus.CounterVec
-	serverRequestDuration *prometheus.HistogramVec
-	serverRequestsTotal   *prometheus.CounterVec
+	clientRequestDuration        *prometheus.HistogramVec
+	clientRequestsTotal          *prometheus.CounterVec
+	clientMirrorRequestsTotal    *prometheus.CounterVec
+	clientBreakerState           *prometheus.GaugeVec
+	clientBreakerRejectionsTotal *prometheus.CounterVec
+	clientThrottledTotal         *prometheus.CounterVec
+	serverRequestDuration        *prometheus.HistogramVec
+	serverRequestsTotal          *prometheus.CounterVec
+	serverRequestsInFlight       *prometheus.GaugeVec
+	proxyRequestDuration         *prometheus.HistogramVec
+	proxyRequestsTotal           *prometheus.CounterVec
+	proxyRetriesTotal            *prometheus.CounterVec
+	proxyUpstreamHealthy         *prometheus.GaugeVec
 }
 
 var (
@@ -42,27 +51,100 @@ func newHTTPMetrics(registerer prometheus.Registerer) *metrics {
 			},
 			[]string{"method", "code"},
 		),
+		clientMirrorRequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "httpx_client_mirror_requests_total",
+				Help: "Total number of mirrored HTTP client requests by result.",
+			},
+			[]string{"result"},
+		),
+		clientBreakerState: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "httpx_client_breaker_state",
+				Help: "HTTP client circuit breaker state by host: 0 closed, 1 half-open, 2 open.",
+			},
+			[]string{"host"},
+		),
+		clientBreakerRejectionsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "httpx_client_breaker_rejections_total",
+				Help: "Total number of HTTP client requests rejected by an open circuit breaker.",
+			},
+			[]string{"host"},
+		),
+		clientThrottledTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "httpx_client_throttled_requests_total",
+				Help: "Total number of HTTP client requests delayed or rejected by the client rate limiter, by host, limit and action.",
+			},
+			[]string{"host", "limit", "action"},
+		),
 		serverRequestDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "httpx_server_request_duration_seconds",
 				Help:    "HTTP server request duration in seconds.",
-				Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+				Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
 			},
-			[]string{"method", "code"},
+			[]string{"method", "route", "status"},
 		),
 		serverRequestsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "httpx_server_requests_total",
 				Help: "Total number of HTTP server requests.",
 			},
-			[]string{"method", "code"},
+			[]string{"method", "route", "status"},
+		),
+		serverRequestsInFlight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "httpx_server_requests_in_flight",
+				Help: "Current number of in-flight HTTP server requests.",
+			},
+			[]string{"method"},
+		),
+		proxyRequestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "httpx_proxy_request_duration_seconds",
+				Help:    "HTTP reverse proxy upstream request duration in seconds.",
+				Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+			},
+			[]string{"upstream", "code"},
+		),
+		proxyRequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "httpx_proxy_requests_total",
+				Help: "Total number of HTTP reverse proxy upstream requests.",
+			},
+			[]string{"upstream", "code"},
+		),
+		proxyRetriesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "httpx_proxy_retries_total",
+				Help: "Total number of HTTP reverse proxy retries after connection failures, by retried upstream.",
+			},
+			[]string{"upstream"},
+		),
+		proxyUpstreamHealthy: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "httpx_proxy_upstream_healthy",
+				Help: "Whether an HTTP reverse proxy upstream is currently eligible for traffic.",
+			},
+			[]string{"upstream"},
 		),
 	}
 
 	mustRegisterCollector(registerer, &m.clientRequestDuration, m.clientRequestDuration)
 	mustRegisterCollector(registerer, &m.clientRequestsTotal, m.clientRequestsTotal)
+	mustRegisterCollector(registerer, &m.clientMirrorRequestsTotal, m.clientMirrorRequestsTotal)
+	mustRegisterCollector(registerer, &m.clientBreakerState, m.clientBreakerState)
+	mustRegisterCollector(registerer, &m.clientBreakerRejectionsTotal, m.clientBreakerRejectionsTotal)
+	mustRegisterCollector(registerer, &m.clientThrottledTotal, m.clientThrottledTotal)
 	mustRegisterCollector(registerer, &m.serverRequestDuration, m.serverRequestDuration)
 	mustRegisterCollector(registerer, &m.serverRequestsTotal, m.serverRequestsTotal)
+	mustRegisterCollector(registerer, &m.serverRequestsInFlight, m.serverRequestsInFlight)
+	mustRegisterCollector(registerer, &m.proxyRequestDuration, m.proxyRequestDuration)
+	mustRegisterCollector(registerer, &m.proxyRequestsTotal, m.proxyRequestsTotal)
+	mustRegisterCollector(registerer, &m.proxyRetriesTotal, m.proxyRetriesTotal)
+	mustRegisterCollector(registerer, &m.proxyUpstreamHealthy, m.proxyUpstreamHealthy)
 
 	return m
 }
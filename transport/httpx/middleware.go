@@ -0,0 +1,74 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const unmatchedRoute = "unmatched"
+
+// Middleware wraps an http.Handler, e.g. for authentication or CORS.
+type Middleware func(http.Handler) http.Handler
+
+// Chain composes middlewares into one; the first runs outermost.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			if middlewares[i] != nil {
+				next = middlewares[i](next)
+			}
+		}
+		return next
+	}
+}
+
+type routeContextKey struct{}
+
+type routeHolder struct {
+	route string
+}
+
+// SetRoute sets the route label the server records for r, such as
+// "/users/{id}". Patterns matched by http.ServeMux are picked up without it;
+// other routers call it from their handlers to keep the label bounded.
+func SetRoute(r *http.Request, route string) {
+	if holder, ok := r.Context().Value(routeContextKey{}).(*routeHolder); ok {
+		holder.route = route
+	}
+}
+
+func withRouteHolder(r *http.Request) (*http.Request, *routeHolder) {
+	holder := &routeHolder{}
+	return r.WithContext(context.WithValue(r.Context(), routeContextKey{}, holder)), holder
+}
+
+// captureRoute wraps the application handler: http.ServeMux writes the
+// matched pattern into the request it was given, which is read back here.
+func captureRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if r.Pattern == "" {
+			return
+		}
+		if holder, ok := r.Context().Value(routeContextKey{}).(*routeHolder); ok && holder.route == "" {
+			holder.route = patternRoute(r.Pattern)
+		}
+	})
+}
+
+// patternRoute drops the method from a ServeMux pattern, so "GET /users/{id}"
+// is labelled like ginx labels "/users/:id": by path only.
+func patternRoute(pattern string) string {
+	if method, rest, ok := strings.Cut(pattern, " "); ok && method != "" {
+		return strings.TrimLeft(rest, " \t")
+	}
+	return pattern
+}
+
+func routeLabel(holder *routeHolder) string {
+	if holder == nil || holder.route == "" {
+		return unmatchedRoute
+	}
+	return holder.route
+}
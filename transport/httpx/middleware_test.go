@@ -0,0 +1,113 @@
+package httpx_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/bang-go/micro/transport/httpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestServerMiddlewareChainAndRouteMetrics(t *testing.T) {
+	listener := newPipeListener()
+	reg := prometheus.NewRegistry()
+	server := httpx.NewServer(&httpx.ServerConfig{
+		Listener:          listener,
+		Logger:            logger.New(logger.WithOutput(io.Discard)),
+		MetricsRegisterer: reg,
+	})
+
+	var order []string
+	tag := func(name string) httpx.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				w.Header().Add("X-Chain", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	server.Use(tag("outer"), nil)
+	server.Use(httpx.Chain(tag("inner")))
+	server.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/panic" {
+				panic("middleware")
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.PathValue("id")))
+	})
+	mux.HandleFunc("/custom/", func(w http.ResponseWriter, r *http.Request) {
+		httpx.SetRoute(r, "/custom/:name")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Start(context.Background(), mux) }()
+	waitForServer(t, server)
+
+	status, body, header, err := doPipeRequest(listener, http.MethodGet, "/users/7")
+	if err != nil || status != http.StatusOK || body != "7" {
+		t.Fatalf("GET /users/7 = %d %q %v", status, body, err)
+	}
+	if got := strings.Join(header.Values("X-Chain"), ","); got != "outer,inner" {
+		t.Fatalf("X-Chain = %q, want outer,inner", got)
+	}
+	if _, _, _, err := doPipeRequest(listener, http.MethodGet, "/users/8"); err != nil {
+		t.Fatal(err)
+	}
+	if status, _, _, _ := doPipeRequest(listener, http.MethodGet, "/custom/a"); status != http.StatusAccepted {
+		t.Fatalf("GET /custom/a status = %d", status)
+	}
+	if status, _, _, _ := doPipeRequest(listener, http.MethodGet, "/missing"); status != http.StatusNotFound {
+		t.Fatalf("GET /missing status = %d", status)
+	}
+	if status, _, _, _ := doPipeRequest(listener, http.MethodGet, "/panic"); status != http.StatusInternalServerError {
+		t.Fatalf("GET /panic status = %d, want recovered 500", status)
+	}
+	order = nil
+	if status, _, header, _ := doPipeRequest(listener, http.MethodGet, "/healthz"); status != http.StatusOK || header.Get("X-Chain") != "" {
+		t.Fatalf("health check = %d %v, want 200 without middlewares", status, header)
+	}
+	if len(order) != 0 {
+		t.Fatalf("middlewares ran for the health endpoint: %v", order)
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("start returned error: %v", err)
+	}
+
+	assertCounterValue(t, reg, "httpx_server_requests_total", map[string]string{"method": "GET", "route": "/users/{id}", "status": "200"}, 2)
+	assertCounterValue(t, reg, "httpx_server_requests_total", map[string]string{"method": "GET", "route": "/custom/:name", "status": "202"}, 1)
+	assertCounterValue(t, reg, "httpx_server_requests_total", map[string]string{"method": "GET", "route": "unmatched", "status": "404"}, 1)
+	assertCounterValue(t, reg, "httpx_server_requests_total", map[string]string{"method": "GET", "route": "unmatched", "status": "500"}, 1)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "httpx_server_requests_in_flight" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if got := metric.GetGauge().GetValue(); got != 0 {
+				t.Fatalf("in-flight = %v after all requests finished", got)
+			}
+		}
+		return
+	}
+	t.Fatal("httpx_server_requests_in_flight not found")
+}
@@ -0,0 +1,161 @@
+package httpx
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+)
+
+const (
+	defaultMirrorTimeout     = 5 * time.Second
+	defaultMirrorMaxBodySize = 1 << 20
+	defaultMirrorMaxInFlight = 64
+)
+
+// MirrorConfig mirrors a sample of client requests to a shadow target.
+// Mirrored responses are discarded; outcomes are only counted in metrics.
+type MirrorConfig struct {
+	// Target is an absolute base URL. Its scheme and host replace the original
+	// request's, and its path, if any, is prefixed to the original path.
+	Target string
+	// Percent of requests to mirror, from 0 to 100.
+	Percent float64
+	// Timeout bounds each mirrored request independently of the caller's context.
+	Timeout time.Duration
+	// MaxBodySize skips mirroring for requests with larger bodies.
+	MaxBodySize int64
+	// MaxInFlight caps concurrent mirrored requests; excess samples are dropped.
+	MaxInFlight int
+}
+
+type mirror struct {
+	target      *url.URL
+	percent     float64
+	timeout     time.Duration
+	maxBodySize int64
+	slots       chan struct{}
+	client      *http.Client
+	metrics     *metrics
+	sample      func() float64
+}
+
+func newMirror(conf *MirrorConfig, client *http.Client, metrics *metrics, log *logger.Logger) *mirror {
+	if conf == nil || conf.Percent <= 0 {
+		return nil
+	}
+
+	target, err := url.Parse(strings.TrimSpace(conf.Target))
+	if err != nil || !target.IsAbs() || target.Host == "" {
+		if log == nil {
+			log = logger.New(logger.WithLevel("info"))
+		}
+		log.Warn(context.Background(), "http_client_mirror_disabled", "target", conf.Target, "error", err)
+		return nil
+	}
+
+	m := &mirror{
+		target:      target,
+		percent:     min(conf.Percent, 100),
+		timeout:     conf.Timeout,
+		maxBodySize: conf.MaxBodySize,
+		client:      client,
+		metrics:     metrics,
+		sample:      func() float64 { return rand.Float64() * 100 },
+	}
+	if m.timeout <= 0 {
+		m.timeout = defaultMirrorTimeout
+	}
+	if m.maxBodySize <= 0 {
+		m.maxBodySize = defaultMirrorMaxBodySize
+	}
+	maxInFlight := conf.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = defaultMirrorMaxInFlight
+	}
+	m.slots = make(chan struct{}, maxInFlight)
+	return m
+}
+
+// send must be called before the original request is sent, so that the
+// body snapshot is taken from GetBody rather than the consumed reader.
+func (m *mirror) send(req *http.Request) {
+	if m.sample() >= m.percent {
+		return
+	}
+
+	var body io.ReadCloser
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil || req.ContentLength < 0 || req.ContentLength > m.maxBodySize {
+			m.observe("skipped")
+			return
+		}
+		snapshot, err := req.GetBody()
+		if err != nil {
+			m.observe("skipped")
+			return
+		}
+		body = snapshot
+	}
+
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		if body != nil {
+			_ = body.Close()
+		}
+		m.observe("dropped")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), m.timeout)
+	shadow := req.Clone(ctx)
+	shadow.URL = m.targetURL(req.URL)
+	shadow.Host = ""
+	shadow.RequestURI = ""
+	shadow.Body = body
+	if body == nil {
+		shadow.Body = http.NoBody
+	}
+
+	go func() {
+		defer func() { <-m.slots }()
+		defer cancel()
+
+		resp, err := m.client.Do(shadow)
+		if err != nil {
+			m.observe("error")
+			return
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			m.observe("error")
+			return
+		}
+		m.observe("ok")
+	}()
+}
+
+func (m *mirror) targetURL(original *url.URL) *url.URL {
+	u := *original
+	u.Scheme = m.target.Scheme
+	u.Host = m.target.Host
+	u.User = m.target.User
+	if prefix := strings.TrimSuffix(m.target.Path, "/"); prefix != "" {
+		u.Path = prefix + original.Path
+		u.RawPath = ""
+	}
+	return &u
+}
+
+func (m *mirror) observe(result string) {
+	if m.metrics != nil {
+		m.metrics.clientMirrorRequestsTotal.WithLabelValues(result).Inc()
+	}
+}
@@ -0,0 +1,153 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/httpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type mirroredRequest struct {
+	host string
+	path string
+	body string
+}
+
+func newMirrorTestClient(t *testing.T, reg *prometheus.Registry, mirror *httpx.MirrorConfig, shadowErr error) (httpx.Client, <-chan mirroredRequest) {
+	t.Helper()
+
+	seen := make(chan mirroredRequest, 8)
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body := ""
+		if r.Body != nil {
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				return nil, err
+			}
+			body = string(data)
+		}
+		seen <- mirroredRequest{host: r.URL.Host, path: r.URL.Path, body: body}
+		if r.URL.Host == "shadow.example" && shadowErr != nil {
+			return nil, shadowErr
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader("primary")),
+		}, nil
+	})
+
+	client := httpx.NewClient(&httpx.ClientConfig{
+		HTTPClient:        &http.Client{Transport: transport},
+		MetricsRegisterer: reg,
+		Mirror:            mirror,
+	})
+	return client, seen
+}
+
+func TestClientMirrorsRequestsToShadowTarget(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+	client, seen := newMirrorTestClient(t, reg, &httpx.MirrorConfig{
+		Target:  "http://shadow.example/v2",
+		Percent: 100,
+	}, nil)
+
+	req := &httpx.Request{Method: httpx.MethodPost, URL: "http://primary.example/orders"}
+	req.SetBody(httpx.ContentTypeJSON, []byte(`{"id":1}`))
+	resp, err := client.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got := string(resp.Body); got != "primary" {
+		t.Fatalf("body = %q, want primary response", got)
+	}
+
+	requests := map[string]mirroredRequest{}
+	for len(requests) < 2 {
+		select {
+		case r := <-seen:
+			requests[r.host] = r
+		case <-time.After(time.Second):
+			t.Fatalf("requests seen = %+v, want primary and shadow", requests)
+		}
+	}
+
+	if got := requests["primary.example"]; got.path != "/orders" || got.body != `{"id":1}` {
+		t.Fatalf("primary request = %+v", got)
+	}
+	if got := requests["shadow.example"]; got.path != "/v2/orders" || got.body != `{"id":1}` {
+		t.Fatalf("shadow request = %+v", got)
+	}
+	waitForMirrorCount(t, reg, "ok", 1)
+}
+
+func TestClientMirrorErrorsDoNotAffectPrimary(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+	client, _ := newMirrorTestClient(t, reg, &httpx.MirrorConfig{
+		Target:  "http://shadow.example",
+		Percent: 100,
+	}, errors.New("shadow down"))
+
+	if _, err := client.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: "http://primary.example/"}); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	waitForMirrorCount(t, reg, "error", 1)
+}
+
+func TestClientMirrorDisabled(t *testing.T) {
+	t.Parallel()
+
+	for _, mirror := range []*httpx.MirrorConfig{
+		{Target: "http://shadow.example", Percent: 0},
+		{Target: "shadow.example", Percent: 100},
+	} {
+		client, seen := newMirrorTestClient(t, prometheus.NewRegistry(), mirror, nil)
+		if _, err := client.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: "http://primary.example/"}); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		<-seen
+		select {
+		case r := <-seen:
+			t.Fatalf("unexpected mirrored request: %+v", r)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func waitForMirrorCount(t *testing.T, reg *prometheus.Registry, result string, want float64) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		families, err := reg.Gather()
+		if err != nil {
+			t.Fatalf("Gather() error = %v", err)
+		}
+		for _, family := range families {
+			if family.GetName() != "httpx_client_mirror_requests_total" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "result" && label.GetValue() == result && metric.GetCounter().GetValue() == want {
+						return
+					}
+				}
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("mirror %s count did not reach %v", result, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
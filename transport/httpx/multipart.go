@@ -0,0 +1,285 @@
+package httpx
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// MultipartBuilder assembles a multipart/form-data body. The body is encoded
+// while the request is sent, so files are streamed from their readers rather
+// than copied into memory. Do not add parts after SetMultipartBody.
+type MultipartBuilder struct {
+	boundary string
+	parts    []multipartPart
+	err      error
+}
+
+type multipartPart struct {
+	header textproto.MIMEHeader
+	// size is -1 when it cannot be known before sending.
+	size int64
+
+	value  string
+	reader io.Reader
+	path   string
+}
+
+func NewMultipartBuilder() *MultipartBuilder {
+	return &MultipartBuilder{boundary: multipart.NewWriter(io.Discard).Boundary()}
+}
+
+// SetBoundary replaces the random boundary, e.g. for golden tests. It must
+// be 1-70 characters allowed by RFC 2046.
+func (b *MultipartBuilder) SetBoundary(boundary string) error {
+	if err := multipart.NewWriter(io.Discard).SetBoundary(boundary); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMultipartBoundary, err)
+	}
+	b.boundary = boundary
+	return nil
+}
+
+func (b *MultipartBuilder) Boundary() string {
+	return b.boundary
+}
+
+// ContentType returns the Content-Type header value, with the boundary
+// quoted when it needs to be.
+func (b *MultipartBuilder) ContentType() string {
+	return mime.FormatMediaType("multipart/form-data", map[string]string{"boundary": b.boundary})
+}
+
+func (b *MultipartBuilder) AddField(name, value string) *MultipartBuilder {
+	if !b.checkField(name) {
+		return b
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", formDisposition(name, ""))
+	b.parts = append(b.parts, multipartPart{header: header, size: int64(len(value)), value: value})
+	return b
+}
+
+// AddFile adds a file part read from r when the request is sent. The part's
+// Content-Type comes from the filename extension. r is not closed, and a body
+// with a reader part cannot be replayed on redirects.
+func (b *MultipartBuilder) AddFile(field, filename string, r io.Reader) *MultipartBuilder {
+	if !b.checkField(field) {
+		return b
+	}
+	if r == nil {
+		b.err = fmt.Errorf("%w: %s", ErrMultipartReaderRequired, field)
+		return b
+	}
+	b.parts = append(b.parts, multipartPart{header: fileHeader(field, filename), size: readerSize(r), reader: r})
+	return b
+}
+
+// AddFileFromPath adds the file at path, opened only when the request is
+// sent and again for each replay.
+func (b *MultipartBuilder) AddFileFromPath(field, path string) *MultipartBuilder {
+	if !b.checkField(field) {
+		return b
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		b.err = fmt.Errorf("httpx: multipart file: %w", err)
+		return b
+	}
+	if !info.Mode().IsRegular() {
+		b.err = fmt.Errorf("httpx: multipart file: %s is not a regular file", path)
+		return b
+	}
+	b.parts = append(b.parts, multipartPart{header: fileHeader(field, filepath.Base(path)), size: info.Size(), path: path})
+	return b
+}
+
+// Err returns the first error from adding parts.
+func (b *MultipartBuilder) Err() error {
+	return b.err
+}
+
+func (b *MultipartBuilder) checkField(name string) bool {
+	if b.err != nil {
+		return false
+	}
+	if strings.TrimSpace(name) == "" {
+		b.err = ErrMultipartFieldRequired
+		return false
+	}
+	return true
+}
+
+// SetMultipartBody sets b as the request body and Content-Type. Build then
+// sets Content-Length when every part's size is known, and allows replays
+// when no part is a one-shot reader.
+func (r *Request) SetMultipartBody(b *MultipartBuilder) error {
+	if r == nil {
+		return ErrNilRequest
+	}
+	if b == nil {
+		return ErrNilMultipartBuilder
+	}
+	if b.err != nil {
+		return b.err
+	}
+	r.Body = b.newBody()
+	r.SetHeader("Content-Type", b.ContentType())
+	return nil
+}
+
+func setMultipartBody(req *http.Request, b *MultipartBuilder) {
+	req.ContentLength = b.contentLength()
+	if b.replayable() {
+		req.GetBody = func() (io.ReadCloser, error) {
+			return b.newBody(), nil
+		}
+	}
+}
+
+// contentLength returns the encoded size, or -1 when a part's size is
+// unknown and the body is sent chunked.
+func (b *MultipartBuilder) contentLength() int64 {
+	var counter countingWriter
+	w := multipart.NewWriter(&counter)
+	_ = w.SetBoundary(b.boundary)
+	var content int64
+	for _, part := range b.parts {
+		if part.size < 0 {
+			return -1
+		}
+		_, _ = w.CreatePart(part.header)
+		content += part.size
+	}
+	_ = w.Close()
+	return counter.n + content
+}
+
+func (b *MultipartBuilder) replayable() bool {
+	for _, part := range b.parts {
+		if part.reader != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *MultipartBuilder) writeTo(dst io.Writer) error {
+	w := multipart.NewWriter(dst)
+	_ = w.SetBoundary(b.boundary)
+	for _, part := range b.parts {
+		pw, err := w.CreatePart(part.header)
+		if err != nil {
+			return err
+		}
+		if err := part.writeTo(pw); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+func (p multipartPart) writeTo(w io.Writer) error {
+	switch {
+	case p.reader != nil:
+		_, err := io.Copy(w, p.reader)
+		return err
+	case p.path != "":
+		file, err := os.Open(p.path)
+		if err != nil {
+			return fmt.Errorf("httpx: multipart file: %w", err)
+		}
+		defer file.Close()
+		_, err = io.Copy(w, file)
+		return err
+	default:
+		_, err := io.WriteString(w, p.value)
+		return err
+	}
+}
+
+func (b *MultipartBuilder) newBody() *multipartBody {
+	pr, pw := io.Pipe()
+	return &multipartBody{builder: b, pr: pr, pw: pw}
+}
+
+// multipartBody encodes its builder through a pipe. The encoding goroutine
+// starts on the first Read, so a request that is never sent leaks nothing.
+type multipartBody struct {
+	builder *MultipartBuilder
+	pr      *io.PipeReader
+	pw      *io.PipeWriter
+	once    sync.Once
+}
+
+func (m *multipartBody) Read(p []byte) (int, error) {
+	m.once.Do(func() {
+		go func() {
+			m.pw.CloseWithError(m.builder.writeTo(m.pw))
+		}()
+	})
+	return m.pr.Read(p)
+}
+
+// Close stops the encoder; the transport calls it when the request ends.
+func (m *multipartBody) Close() error {
+	m.once.Do(func() {})
+	return m.pr.Close()
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// formDisposition quotes names like multipart.Writer.CreateFormFile and
+// browsers do, rather than the RFC 2231 form many servers do not parse.
+func formDisposition(field, filename string) string {
+	disposition := `form-data; name="` + quoteEscaper.Replace(field) + `"`
+	if filename != "" {
+		disposition += `; filename="` + quoteEscaper.Replace(filename) + `"`
+	}
+	return disposition
+}
+
+func fileHeader(field, filename string) textproto.MIMEHeader {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", formDisposition(field, filename))
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = ContentTypeOctetStream
+	}
+	header.Set("Content-Type", contentType)
+	return header
+}
+
+func readerSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	default:
+		return -1
+	}
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
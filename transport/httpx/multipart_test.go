@@ -0,0 +1,176 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/bang-go/micro/transport/httpx"
+)
+
+type multipartReceived struct {
+	contentLength int64
+	chunked       bool
+	fields        map[string]string
+	files         map[string]string
+	fileTypes     map[string]string
+	fileNames     map[string]string
+}
+
+func newMultipartServer(t *testing.T) (*httptest.Server, <-chan multipartReceived) {
+	t.Helper()
+	received := make(chan multipartReceived, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/upload", http.StatusTemporaryRedirect)
+			return
+		}
+		got := multipartReceived{
+			contentLength: r.ContentLength,
+			chunked:       len(r.TransferEncoding) > 0,
+			fields:        map[string]string{},
+			files:         map[string]string{},
+			fileTypes:     map[string]string{},
+			fileNames:     map[string]string{},
+		}
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(part)
+			if part.FileName() == "" {
+				got.fields[part.FormName()] = string(data)
+				continue
+			}
+			got.files[part.FormName()] = string(data)
+			got.fileTypes[part.FormName()] = part.Header.Get("Content-Type")
+			got.fileNames[part.FormName()] = part.FileName()
+		}
+		received <- got
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestMultipartBuilderStreamsFieldsAndFiles(t *testing.T) {
+	t.Parallel()
+
+	server, received := newMultipartServer(t)
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(path, []byte(`{"ok":true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	builder := httpx.NewMultipartBuilder().
+		AddField("title", "Q3 \"final\"").
+		AddFile("avatar", "me.png", strings.NewReader("png-bytes")).
+		AddFileFromPath("report", path)
+	req := &httpx.Request{Method: httpx.MethodPost, URL: server.URL + "/upload"}
+	if err := req.SetMultipartBody(builder); err != nil {
+		t.Fatalf("SetMultipartBody() error = %v", err)
+	}
+	if got := req.Header.Get("Content-Type"); got != "multipart/form-data; boundary="+builder.Boundary() {
+		t.Fatalf("Content-Type = %q", got)
+	}
+
+	client := httpx.NewClient(&httpx.ClientConfig{DisableMetrics: true})
+	resp, err := client.Do(context.Background(), req)
+	if err != nil || !resp.Success() {
+		t.Fatalf("Do() = %v, %v", resp, err)
+	}
+
+	got := <-received
+	if got.chunked || got.contentLength <= 0 {
+		t.Fatalf("content length = %d, chunked = %v; want a known length", got.contentLength, got.chunked)
+	}
+	if got.fields["title"] != `Q3 "final"` {
+		t.Fatalf("fields = %v", got.fields)
+	}
+	if got.files["avatar"] != "png-bytes" || got.fileTypes["avatar"] != "image/png" || got.fileNames["avatar"] != "me.png" {
+		t.Fatalf("avatar = %q %q %q", got.files["avatar"], got.fileTypes["avatar"], got.fileNames["avatar"])
+	}
+	if got.files["report"] != `{"ok":true}` || got.fileNames["report"] != "report.json" {
+		t.Fatalf("report = %q %q", got.files["report"], got.fileNames["report"])
+	}
+}
+
+func TestMultipartBuilderChunksUnknownSizesAndReplaysPaths(t *testing.T) {
+	t.Parallel()
+
+	server, received := newMultipartServer(t)
+	client := httpx.NewClient(&httpx.ClientConfig{DisableMetrics: true})
+
+	unsized := httpx.NewMultipartBuilder().AddFile("blob", "blob.bin", iotest.OneByteReader(strings.NewReader("stream")))
+	req := &httpx.Request{Method: httpx.MethodPost, URL: server.URL + "/upload"}
+	if err := req.SetMultipartBody(unsized); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(context.Background(), req); err != nil {
+		t.Fatalf("Do(unsized) error = %v", err)
+	}
+	if got := <-received; !got.chunked || got.files["blob"] != "stream" || got.fileTypes["blob"] != httpx.ContentTypeOctetStream {
+		t.Fatalf("unsized upload = %+v", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(path, []byte("replayed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	req = &httpx.Request{Method: httpx.MethodPost, URL: server.URL + "/redirect"}
+	if err := req.SetMultipartBody(httpx.NewMultipartBuilder().AddFileFromPath("data", path)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(context.Background(), req); err != nil {
+		t.Fatalf("Do(redirect) error = %v", err)
+	}
+	if got := <-received; got.files["data"] != "replayed" {
+		t.Fatalf("replayed upload = %+v", got)
+	}
+}
+
+func TestMultipartBuilderValidation(t *testing.T) {
+	t.Parallel()
+
+	if err := httpx.NewMultipartBuilder().SetBoundary("bad\nboundary"); !errors.Is(err, httpx.ErrInvalidMultipartBoundary) {
+		t.Fatalf("SetBoundary() error = %v", err)
+	}
+	builder := httpx.NewMultipartBuilder()
+	if err := builder.SetBoundary("fixed boundary"); err != nil {
+		t.Fatal(err)
+	}
+	if got := builder.ContentType(); got != `multipart/form-data; boundary="fixed boundary"` {
+		t.Fatalf("ContentType() = %q, want quoted boundary", got)
+	}
+
+	req := &httpx.Request{}
+	if err := req.SetMultipartBody(nil); !errors.Is(err, httpx.ErrNilMultipartBuilder) {
+		t.Fatalf("SetMultipartBody(nil) error = %v", err)
+	}
+	if err := req.SetMultipartBody(httpx.NewMultipartBuilder().AddField(" ", "v")); !errors.Is(err, httpx.ErrMultipartFieldRequired) {
+		t.Fatalf("empty field error = %v", err)
+	}
+	if err := req.SetMultipartBody(httpx.NewMultipartBuilder().AddFile("f", "f.txt", nil)); !errors.Is(err, httpx.ErrMultipartReaderRequired) {
+		t.Fatalf("nil reader error = %v", err)
+	}
+	missing := httpx.NewMultipartBuilder().AddFileFromPath("f", filepath.Join(t.TempDir(), "missing")).AddField("later", "ignored")
+	if err := missing.Err(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("missing file error = %v", err)
+	}
+}
@@ -0,0 +1,474 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	defaultProxyFailTimeout     = 10 * time.Second
+	defaultProxyHealthInterval  = 10 * time.Second
+	defaultProxyHealthTimeout   = 2 * time.Second
+	defaultProxyHealthThreshold = 2
+)
+
+type ProxyConfig struct {
+	// Upstreams are absolute base URLs. A base path, if any, is prefixed to
+	// the proxied path.
+	Upstreams []string
+	// Retries is the number of other upstreams tried after a connection
+	// failure. Only dial errors are retried, so the request never reached
+	// the failed upstream.
+	Retries int
+	// FailTimeout ejects an upstream for this long after a connection failure.
+	FailTimeout time.Duration
+	HealthCheck *ProxyHealthCheck
+
+	// PathRewrites are tried in order; the first matching prefix wins.
+	PathRewrites    []PathRewrite
+	RequestHeaders  HeaderRewrite
+	ResponseHeaders HeaderRewrite
+	// Rewrite runs after the built-in rules for custom adjustments.
+	Rewrite func(*httputil.ProxyRequest)
+
+	Transport     http.RoundTripper
+	FlushInterval time.Duration
+
+	Logger            *logger.Logger
+	EnableLogger      bool
+	Trace             bool
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+
+	// Propagators overrides the global propagator, e.g. to accept and emit B3
+	// next to W3C headers; see trace.NewPropagator.
+	Propagators propagation.TextMapPropagator
+}
+
+// PathRewrite replaces a leading Prefix of the request path, e.g.
+// {Prefix: "/api/v1/orders", Replacement: "/orders"}.
+type PathRewrite struct {
+	Prefix      string
+	Replacement string
+}
+
+type HeaderRewrite struct {
+	Set    map[string]string
+	Add    map[string]string
+	Remove []string
+}
+
+// ProxyHealthCheck actively probes Path on every upstream. An upstream is
+// marked down after Threshold consecutive failures and up again after
+// Threshold consecutive 2xx/3xx responses.
+type ProxyHealthCheck struct {
+	Path      string
+	Interval  time.Duration
+	Timeout   time.Duration
+	Threshold int
+}
+
+type UpstreamStatus struct {
+	URL     string
+	Healthy bool
+}
+
+type Proxy interface {
+	http.Handler
+	Upstreams() []UpstreamStatus
+	// Close stops health checks.
+	Close() error
+}
+
+type upstream struct {
+	target *url.URL
+	label  string
+	// healthy reflects active health checks; downUntil passive ejection.
+	healthy   atomic.Bool
+	downUntil atomic.Int64
+	checks    int
+}
+
+type proxyEntity struct {
+	config    *ProxyConfig
+	upstreams []*upstream
+	next      atomic.Uint64
+	transport http.RoundTripper
+	handler   *httputil.ReverseProxy
+	metrics   *metrics
+	now       func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// NewProxy returns a reverse proxy that balances requests round-robin across
+// healthy upstreams. When every upstream is unhealthy it falls back to all of
+// them rather than failing closed.
+func NewProxy(conf *ProxyConfig) (Proxy, error) {
+	if conf == nil || len(conf.Upstreams) == 0 {
+		return nil, ErrProxyUpstreamRequired
+	}
+
+	config := *conf
+	if config.Logger == nil {
+		config.Logger = logger.New(logger.WithLevel("info"))
+	}
+	if config.FailTimeout <= 0 {
+		config.FailTimeout = defaultProxyFailTimeout
+	}
+	if config.Retries < 0 {
+		config.Retries = 0
+	}
+
+	p := &proxyEntity{config: &config, now: time.Now}
+	for _, raw := range conf.Upstreams {
+		target, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || !target.IsAbs() || target.Host == "" {
+			return nil, ErrProxyUpstreamInvalid
+		}
+		u := &upstream{target: target, label: target.Scheme + "://" + target.Host}
+		u.healthy.Store(true)
+		p.upstreams = append(p.upstreams, u)
+	}
+
+	if !config.DisableMetrics {
+		p.metrics = defaultHTTPMetrics()
+		if config.MetricsRegisterer != nil {
+			p.metrics = newHTTPMetrics(config.MetricsRegisterer)
+		}
+	}
+
+	p.transport = config.Transport
+	if p.transport == nil {
+		p.transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	if config.Trace {
+		p.transport = otelhttp.NewTransport(p.transport, traceOptions(config.Propagators)...)
+	}
+
+	p.handler = &httputil.ReverseProxy{
+		Rewrite:        p.rewrite,
+		Transport:      roundTripFunc(p.roundTrip),
+		FlushInterval:  config.FlushInterval,
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler:   p.errorHandler,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	if config.HealthCheck != nil {
+		p.startHealthChecks(ctx, *config.HealthCheck)
+	}
+	p.setHealthGauges()
+	return p, nil
+}
+
+func (p *proxyEntity) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.handler.ServeHTTP(w, r)
+}
+
+func (p *proxyEntity) Upstreams() []UpstreamStatus {
+	now := p.now().UnixNano()
+	statuses := make([]UpstreamStatus, len(p.upstreams))
+	for i, u := range p.upstreams {
+		statuses[i] = UpstreamStatus{URL: u.target.String(), Healthy: u.available(now)}
+	}
+	return statuses
+}
+
+func (p *proxyEntity) Close() error {
+	p.once.Do(func() {
+		p.cancel()
+		p.wg.Wait()
+	})
+	return nil
+}
+
+func (p *proxyEntity) rewrite(pr *httputil.ProxyRequest) {
+	out := pr.Out
+	for _, rule := range p.config.PathRewrites {
+		if rule.Prefix != "" && strings.HasPrefix(out.URL.Path, rule.Prefix) {
+			out.URL.Path = rule.Replacement + strings.TrimPrefix(out.URL.Path, rule.Prefix)
+			if out.URL.Path == "" {
+				out.URL.Path = "/"
+			}
+			out.URL.RawPath = ""
+			break
+		}
+	}
+	// The upstream host is chosen per attempt; Rewrite may restore In.Host.
+	out.Host = ""
+	pr.SetXForwarded()
+	applyHeaderRewrite(out.Header, p.config.RequestHeaders)
+	if p.config.Rewrite != nil {
+		p.config.Rewrite(pr)
+	}
+}
+
+func (p *proxyEntity) modifyResponse(resp *http.Response) error {
+	applyHeaderRewrite(resp.Header, p.config.ResponseHeaders)
+	return nil
+}
+
+func (p *proxyEntity) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusBadGateway
+	if errors.Is(err, context.DeadlineExceeded) {
+		status = http.StatusGatewayTimeout
+	}
+	if !errors.Is(err, context.Canceled) {
+		p.config.Logger.Warn(r.Context(), "http_proxy_error",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"error", err,
+		)
+	}
+	w.WriteHeader(status)
+}
+
+func (p *proxyEntity) roundTrip(req *http.Request) (*http.Response, error) {
+	var body *retryBody
+	if req.Body != nil && req.Body != http.NoBody {
+		body = &retryBody{ReadCloser: req.Body}
+	}
+
+	tried := make([]bool, len(p.upstreams))
+	var lastErr error
+	for attempt := 0; attempt <= p.config.Retries; attempt++ {
+		u := p.pick(tried)
+		if u == nil {
+			break
+		}
+		if attempt > 0 && p.metrics != nil {
+			p.metrics.proxyRetriesTotal.WithLabelValues(u.label).Inc()
+		}
+
+		out := req.Clone(req.Context())
+		out.URL = u.targetURL(req.URL)
+		if body != nil {
+			out.Body = body
+		}
+
+		start := p.now()
+		resp, err := p.transport.RoundTrip(out)
+		if err == nil {
+			p.record(out, u, resp.StatusCode, p.now().Sub(start), nil)
+			return resp, nil
+		}
+		p.record(out, u, 0, p.now().Sub(start), err)
+
+		lastErr = err
+		if !isDialError(err) || (body != nil && body.read.Load()) {
+			break
+		}
+		u.downUntil.Store(p.now().Add(p.config.FailTimeout).UnixNano())
+		p.setHealthGauges()
+	}
+	return nil, lastErr
+}
+
+// pick returns the next available upstream not tried yet, falling back to
+// any untried upstream when none is available.
+func (p *proxyEntity) pick(tried []bool) *upstream {
+	now := p.now().UnixNano()
+	start := int(p.next.Add(1) - 1)
+	fallback := -1
+	for i := range p.upstreams {
+		idx := (start + i) % len(p.upstreams)
+		if tried[idx] {
+			continue
+		}
+		if p.upstreams[idx].available(now) {
+			tried[idx] = true
+			return p.upstreams[idx]
+		}
+		if fallback < 0 {
+			fallback = idx
+		}
+	}
+	if fallback < 0 {
+		return nil
+	}
+	tried[fallback] = true
+	return p.upstreams[fallback]
+}
+
+func (p *proxyEntity) record(req *http.Request, u *upstream, statusCode int, duration time.Duration, err error) {
+	code := statusLabel(statusCode)
+	if p.metrics != nil {
+		p.metrics.proxyRequestsTotal.WithLabelValues(u.label, code).Inc()
+		p.metrics.proxyRequestDuration.WithLabelValues(u.label, code).Observe(duration.Seconds())
+	}
+	if !p.config.EnableLogger {
+		return
+	}
+
+	fields := []any{
+		"method", req.Method,
+		"upstream", u.label,
+		"path", req.URL.Path,
+		"status", statusCode,
+		"duration", duration.Seconds(),
+	}
+	if err != nil {
+		p.config.Logger.Error(req.Context(), "http_proxy_request_failed", append(fields, "error", err)...)
+		return
+	}
+	if statusCode >= http.StatusInternalServerError {
+		p.config.Logger.Error(req.Context(), "http_proxy_request", fields...)
+		return
+	}
+	p.config.Logger.Info(req.Context(), "http_proxy_request", fields...)
+}
+
+func (p *proxyEntity) startHealthChecks(ctx context.Context, check ProxyHealthCheck) {
+	if check.Interval <= 0 {
+		check.Interval = defaultProxyHealthInterval
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = defaultProxyHealthTimeout
+	}
+	if check.Threshold <= 0 {
+		check.Threshold = defaultProxyHealthThreshold
+	}
+	if check.Path == "" {
+		check.Path = defaultServerHealthPath
+	}
+
+	client := &http.Client{Transport: p.transport, Timeout: check.Timeout}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(check.Interval)
+		defer ticker.Stop()
+		for {
+			p.checkUpstreams(ctx, client, check)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (p *proxyEntity) checkUpstreams(ctx context.Context, client *http.Client, check ProxyHealthCheck) {
+	for _, u := range p.upstreams {
+		target := u.targetURL(&url.URL{Path: check.Path})
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+		if err != nil {
+			continue
+		}
+		ok := false
+		if resp, err := client.Do(req); err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			ok = resp.StatusCode < http.StatusBadRequest
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		// checks counts consecutive results that disagree with the current state.
+		if ok == u.healthy.Load() {
+			u.checks = 0
+			continue
+		}
+		u.checks++
+		if u.checks >= check.Threshold {
+			u.checks = 0
+			u.healthy.Store(ok)
+			if ok {
+				u.downUntil.Store(0)
+			}
+			p.config.Logger.Warn(ctx, "http_proxy_upstream_health_changed", "upstream", u.label, "healthy", ok)
+		}
+	}
+	p.setHealthGauges()
+}
+
+func (p *proxyEntity) setHealthGauges() {
+	if p.metrics == nil {
+		return
+	}
+	now := p.now().UnixNano()
+	for _, u := range p.upstreams {
+		value := 0.0
+		if u.available(now) {
+			value = 1
+		}
+		p.metrics.proxyUpstreamHealthy.WithLabelValues(u.label).Set(value)
+	}
+}
+
+func (u *upstream) available(now int64) bool {
+	return u.healthy.Load() && now >= u.downUntil.Load()
+}
+
+func (u *upstream) targetURL(original *url.URL) *url.URL {
+	target := *original
+	target.Scheme = u.target.Scheme
+	target.Host = u.target.Host
+	target.User = u.target.User
+	if prefix := strings.TrimSuffix(u.target.Path, "/"); prefix != "" {
+		target.Path = prefix + original.Path
+		target.RawPath = ""
+	}
+	return &target
+}
+
+func applyHeaderRewrite(header http.Header, rewrite HeaderRewrite) {
+	for _, key := range rewrite.Remove {
+		header.Del(key)
+	}
+	for key, value := range rewrite.Set {
+		header.Set(key, value)
+	}
+	for key, value := range rewrite.Add {
+		header.Add(key, value)
+	}
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryBody lets a request body be offered to another upstream after a dial
+// failure. Close is a no-op: the server closes the inbound body once the
+// handler returns.
+type retryBody struct {
+	io.ReadCloser
+	read atomic.Bool
+}
+
+func (b *retryBody) Read(p []byte) (int, error) {
+	b.read.Store(true)
+	return b.ReadCloser.Read(p)
+}
+
+func (b *retryBody) Close() error {
+	return nil
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
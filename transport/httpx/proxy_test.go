@@ -0,0 +1,169 @@
+package httpx_test
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/httpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func deadUpstream(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+	return "http://" + addr
+}
+
+func TestProxyRetriesConnectFailuresAndRewrites(t *testing.T) {
+	t.Parallel()
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Server", "backend")
+		w.Header().Set("X-Upstream-Path", r.URL.Path)
+		w.Header().Set("X-Tenant", r.Header.Get("X-Tenant"))
+		w.Header().Set("X-Had-Cookie", r.Header.Get("Cookie"))
+		w.Header().Set("X-Forwarded-Host", r.Header.Get("X-Forwarded-Host"))
+		_, _ = w.Write(body)
+	}))
+	defer backend.Close()
+
+	reg := prometheus.NewRegistry()
+	dead := deadUpstream(t)
+	proxy, err := httpx.NewProxy(&httpx.ProxyConfig{
+		Upstreams: []string{dead, backend.URL + "/internal"},
+		Retries:   1,
+		PathRewrites: []httpx.PathRewrite{
+			{Prefix: "/api/v1/orders", Replacement: "/orders"},
+		},
+		RequestHeaders:    httpx.HeaderRewrite{Set: map[string]string{"X-Tenant": "t1"}, Remove: []string{"Cookie"}},
+		ResponseHeaders:   httpx.HeaderRewrite{Remove: []string{"Server"}},
+		MetricsRegisterer: reg,
+	})
+	if err != nil {
+		t.Fatalf("NewProxy() error = %v", err)
+	}
+	defer proxy.Close()
+
+	for i := range 2 {
+		req := httptest.NewRequest(http.MethodPost, "http://gateway.example/api/v1/orders/42", strings.NewReader("payload"))
+		req.Header.Set("Cookie", "session=secret")
+		rec := httptest.NewRecorder()
+		proxy.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK || rec.Body.String() != "payload" {
+			t.Fatalf("request %d: status = %d body = %q", i, rec.Code, rec.Body.String())
+		}
+		header := rec.Header()
+		if got := header.Get("X-Upstream-Path"); got != "/internal/orders/42" {
+			t.Fatalf("upstream path = %q", got)
+		}
+		if header.Get("X-Tenant") != "t1" || header.Get("X-Had-Cookie") != "" || header.Get("Server") != "" {
+			t.Fatalf("header rewrite not applied: %v", header)
+		}
+		if got := header.Get("X-Forwarded-Host"); got != "gateway.example" {
+			t.Fatalf("X-Forwarded-Host = %q", got)
+		}
+	}
+
+	statuses := proxy.Upstreams()
+	if statuses[0].Healthy || !statuses[1].Healthy {
+		t.Fatalf("Upstreams() = %+v, want dead upstream ejected", statuses)
+	}
+	assertCounterValue(t, reg, "httpx_proxy_requests_total", map[string]string{"upstream": strings.TrimSuffix(backend.URL, "/"), "code": "200"}, 2)
+}
+
+func TestProxyReturnsBadGatewayWithoutHealthyUpstream(t *testing.T) {
+	t.Parallel()
+
+	proxy, err := httpx.NewProxy(&httpx.ProxyConfig{
+		Upstreams:      []string{deadUpstream(t)},
+		Retries:        3,
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("NewProxy() error = %v", err)
+	}
+	defer proxy.Close()
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("status = %d, want 502", rec.Code)
+	}
+}
+
+func TestProxyHealthCheckEjectsUpstream(t *testing.T) {
+	t.Parallel()
+
+	var unhealthy atomic.Bool
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" && unhealthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	proxy, err := httpx.NewProxy(&httpx.ProxyConfig{
+		Upstreams: []string{backend.URL},
+		HealthCheck: &httpx.ProxyHealthCheck{
+			Path:      "/healthz",
+			Interval:  10 * time.Millisecond,
+			Threshold: 2,
+		},
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("NewProxy() error = %v", err)
+	}
+	defer proxy.Close()
+
+	waitHealthy := func(want bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for proxy.Upstreams()[0].Healthy != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("upstream healthy never became %v", want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	unhealthy.Store(true)
+	waitHealthy(false)
+
+	// A lone unhealthy upstream still receives traffic rather than failing closed.
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want fallback to unhealthy upstream", rec.Code)
+	}
+
+	unhealthy.Store(false)
+	waitHealthy(true)
+}
+
+func TestNewProxyValidation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := httpx.NewProxy(nil); !errors.Is(err, httpx.ErrProxyUpstreamRequired) {
+		t.Fatalf("NewProxy(nil) error = %v", err)
+	}
+	if _, err := httpx.NewProxy(&httpx.ProxyConfig{Upstreams: []string{"/relative"}}); !errors.Is(err, httpx.ErrProxyUpstreamInvalid) {
+		t.Fatalf("NewProxy(relative) error = %v", err)
+	}
+}
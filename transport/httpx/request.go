@@ -26,6 +26,9 @@ type Request struct {
 	Cookies   []*http.Cookie
 	BasicAuth *BasicAuth
 	Host      string
+	// ProxyURL overrides ClientConfig.ProxyURL for this request; ProxyDirect
+	// skips the proxy.
+	ProxyURL string
 }
 
 func (r *Request) Build(ctx context.Context) (*http.Request, error) {
@@ -61,10 +64,19 @@ func (r *Request) Build(ctx context.Context) (*http.Request, error) {
 	}
 	parsedURL.RawQuery = query.Encode()
 
+	if r.ProxyURL != "" {
+		if ctx, err = withProxy(ctx, r.ProxyURL); err != nil {
+			return nil, err
+		}
+	}
+
 	httpReq, err := http.NewRequestWithContext(ctx, method, parsedURL.String(), r.Body)
 	if err != nil {
 		return nil, fmt.Errorf("httpx: build request: %w", err)
 	}
+	if body, ok := r.Body.(*multipartBody); ok {
+		setMultipartBody(httpReq, body.builder)
+	}
 
 	httpReq.Header = cloneHeader(r.Header)
 	if r.Host != "" {
@@ -56,11 +56,15 @@ func newResponse(req *http.Request, resp *http.Response, body []byte, duration t
 		Cookies:    cloneCookies(resp.Cookies()),
 		Body:       append([]byte(nil), body...),
 		Duration:   duration,
-		Request: &RequestSnapshot{
-			Method: req.Method,
-			URL:    redactedURLString(req.URL),
-			Header: redactHeader(req.Header),
-			Host:   req.Host,
-		},
+		Request:    newRequestSnapshot(req),
+	}
+}
+
+func newRequestSnapshot(req *http.Request) *RequestSnapshot {
+	return &RequestSnapshot{
+		Method: req.Method,
+		URL:    redactedURLString(req.URL),
+		Header: redactHeader(req.Header),
+		Host:   req.Host,
 	}
 }
@@ -0,0 +1,9 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package httpx
+
+import "syscall"
+
+func reusePortControl(_, _ string, _ syscall.RawConn) error {
+	return ErrReusePortUnsupported
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"runtime/debug"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/bang-go/micro/telemetry/logger"
@@ -32,6 +33,10 @@ type Server interface {
 	Shutdown(context.Context) error
 	Close() error
 	HTTPServer() *http.Server
+	// Use appends middlewares run around the handler, after recovery and
+	// observability and not for the health endpoint. It affects the next
+	// Start or Serve.
+	Use(...Middleware)
 }
 
 type serverEntity struct {
@@ -41,6 +46,8 @@ type serverEntity struct {
 	mu        sync.RWMutex
 	running   bool
 	metrics   *metrics
+
+	middlewares []Middleware
 }
 
 func NewServer(conf *ServerConfig) Server {
@@ -105,7 +112,7 @@ func (s *serverEntity) Start(ctx context.Context, handler http.Handler) error {
 		return ErrServerAddrRequired
 	}
 
-	listener, err := net.Listen("tcp", s.config.Addr)
+	listener, err := s.listen(ctx)
 	if err != nil {
 		return fmt.Errorf("httpx: listen: %w", err)
 	}
@@ -131,6 +138,10 @@ func (s *serverEntity) Serve(ctx context.Context, listener net.Listener, handler
 	if handler == nil {
 		return ErrNilHandler
 	}
+	tlsConfig, err := resolveServerTLSConfig(s.config)
+	if err != nil {
+		return err
+	}
 
 	s.mu.Lock()
 	if s.running {
@@ -140,15 +151,24 @@ func (s *serverEntity) Serve(ctx context.Context, listener net.Listener, handler
 
 	server := &http.Server{
 		Addr:              listener.Addr().String(),
-		Handler:           s.wrapHandler(handler),
+		Handler:           s.wrapHandler(Chain(s.middlewares...)(captureRoute(handler))),
 		ReadTimeout:       s.config.ReadTimeout,
 		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
 		WriteTimeout:      s.config.WriteTimeout,
 		IdleTimeout:       s.config.IdleTimeout,
+		MaxHeaderBytes:    s.config.MaxHeaderBytes,
+		TLSConfig:         tlsConfig,
 		BaseContext: func(net.Listener) context.Context {
 			return ctx
 		},
 	}
+	if s.config.H2C {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		server.Protocols = protocols
+	}
 	s.server = server
 	s.running = true
 	s.mu.Unlock()
@@ -164,9 +184,13 @@ func (s *serverEntity) Serve(ctx context.Context, listener net.Listener, handler
 	defer close(done)
 	go s.watchContext(ctx, done)
 
-	s.info(ctx, "http server starting", "addr", listener.Addr().String())
+	s.info(ctx, "http server starting", "addr", listener.Addr().String(), "tls", tlsConfig != nil, "h2c", s.config.H2C)
 
-	err := server.Serve(listener)
+	if tlsConfig != nil {
+		err = server.ServeTLS(listener, "", "")
+	} else {
+		err = server.Serve(listener)
+	}
 	if errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
@@ -241,14 +265,20 @@ func (s *serverEntity) HTTPServer() *http.Server {
 	return s.server
 }
 
+func (s *serverEntity) Use(middlewares ...Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middlewares = append(s.middlewares, middlewares...)
+}
+
 func (s *serverEntity) wrapHandler(handler http.Handler) http.Handler {
 	base := s.withHealthEndpoint(handler)
 	base = s.recoveryMiddleware(base)
 
 	if s.config.Trace {
-		base = otelhttp.NewHandler(base, "httpx.server", otelhttp.WithFilter(func(r *http.Request) bool {
+		base = otelhttp.NewHandler(base, "httpx.server", traceOptions(s.config.Propagators, otelhttp.WithFilter(func(r *http.Request) bool {
 			return !matchesPath(s.skipPaths, r.URL.Path)
-		}))
+		}))...)
 	}
 
 	return s.instrumentationMiddleware(base)
@@ -270,6 +300,7 @@ func (s *serverEntity) withHealthEndpoint(next http.Handler) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == s.config.HealthPath {
+			SetRoute(r, s.config.HealthPath)
 			healthHandler.ServeHTTP(w, r)
 			return
 		}
@@ -288,22 +319,29 @@ func (s *serverEntity) instrumentationMiddleware(next http.Handler) http.Handler
 			return
 		}
 
+		r, holder := withRouteHolder(r)
 		recorder := newResponseRecorder(w)
 		start := time.Now()
+		if s.metrics != nil {
+			s.metrics.serverRequestsInFlight.WithLabelValues(r.Method).Inc()
+			defer s.metrics.serverRequestsInFlight.WithLabelValues(r.Method).Dec()
+		}
 		next.ServeHTTP(recorder, r)
 		duration := time.Since(start)
 		code := recorder.StatusCode()
-		status := statusLabel(code)
+		route := routeLabel(holder)
 
 		if s.metrics != nil {
-			s.metrics.serverRequestDuration.WithLabelValues(r.Method, status).Observe(duration.Seconds())
-			s.metrics.serverRequestsTotal.WithLabelValues(r.Method, status).Inc()
+			status := statusLabel(code)
+			s.metrics.serverRequestDuration.WithLabelValues(r.Method, route, status).Observe(duration.Seconds())
+			s.metrics.serverRequestsTotal.WithLabelValues(r.Method, route, status).Inc()
 		}
 
 		if s.config.EnableLogger && s.config.Logger != nil {
 			fields := []any{
 				"method", r.Method,
 				"path", r.URL.Path,
+				"route", route,
 				"status", code,
 				"bytes", recorder.BytesWritten(),
 				"remote_addr", remoteAddrHost(r.RemoteAddr),
@@ -344,6 +382,25 @@ func (s *serverEntity) recoveryMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+func (s *serverEntity) listen(ctx context.Context) (net.Listener, error) {
+	var config net.ListenConfig
+	if s.config.ListenConfig != nil {
+		config = *s.config.ListenConfig
+	}
+	if s.config.ReusePort {
+		control := config.Control
+		config.Control = func(network, address string, conn syscall.RawConn) error {
+			if control != nil {
+				if err := control(network, address, conn); err != nil {
+					return err
+				}
+			}
+			return reusePortControl(network, address, conn)
+		}
+	}
+	return config.Listen(ctx, "tcp", s.config.Addr)
+}
+
 func (s *serverEntity) watchContext(ctx context.Context, done <-chan struct{}) {
 	select {
 	case <-done:
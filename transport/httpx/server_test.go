@@ -286,14 +286,14 @@ func TestServerMetricsRegistererAndDisableMetrics(t *testing.T) {
 			Name: "httpx_server_request_duration_seconds",
 			Help: "HTTP server request duration in seconds.",
 		},
-		[]string{"method", "code"},
+		[]string{"method", "route", "status"},
 	))
 	assertCollectorRegistered(t, reg, prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "httpx_server_requests_total",
 			Help: "Total number of HTTP server requests.",
 		},
-		[]string{"method", "code"},
+		[]string{"method", "route", "status"},
 	))
 
 	disabledReg := prometheus.NewRegistry()
@@ -307,7 +307,7 @@ func TestServerMetricsRegistererAndDisableMetrics(t *testing.T) {
 			Name: "httpx_server_request_duration_seconds",
 			Help: "HTTP server request duration in seconds.",
 		},
-		[]string{"method", "code"},
+		[]string{"method", "route", "status"},
 	))
 }
 
@@ -351,7 +351,8 @@ func TestDisableHealthEndpointHealthRouteRecordedInMetrics(t *testing.T) {
 
 	assertCounterValue(t, reg, "httpx_server_requests_total", map[string]string{
 		"method": "GET",
-		"code":   "204",
+		"route":  "unmatched",
+		"status": "204",
 	}, 1)
 }
 
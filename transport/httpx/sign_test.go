@@ -0,0 +1,144 @@
+package httpx_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/httpx"
+)
+
+func TestHMACSignerRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	signer := &httpx.HMACSigner{KeyID: "svc-a", Secret: "s3cret", SignedHeaders: []string{"Content-Type"}}
+	var verifyErr error
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyErr = signer.Verify(r)
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	t.Cleanup(server.Close)
+
+	client := httpx.NewClient(&httpx.ClientConfig{DisableMetrics: true, Signer: signer})
+	req := &httpx.Request{Method: httpx.MethodPost, URL: server.URL + "/orders?b=2&a=1"}
+	if err := req.SetJSONBody(map[string]int{"amount": 10}); err != nil {
+		t.Fatalf("SetJSONBody() error = %v", err)
+	}
+	if _, err := client.Do(context.Background(), req); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if verifyErr != nil {
+		t.Fatalf("Verify() error = %v", verifyErr)
+	}
+	if got, want := body, `{"amount":10}`; got != want {
+		t.Fatalf("body after Verify = %q, want %q", got, want)
+	}
+	if req.Header.Get("Authorization") != "" {
+		t.Fatal("signing modified the caller's request")
+	}
+}
+
+func TestHMACSignerVerifyRejectsTampering(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	signer := &httpx.HMACSigner{KeyID: "svc-a", Secret: "s3cret", Now: func() time.Time { return now }}
+	sign := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "http://api.internal/items/1?x=1", strings.NewReader("payload"))
+		if err := signer.Sign(req); err != nil {
+			t.Fatalf("Sign() error = %v", err)
+		}
+		return req
+	}
+
+	if err := signer.Verify(sign()); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	req := sign()
+	req.URL.RawQuery = "x=2"
+	if err := signer.Verify(req); !errors.Is(err, httpx.ErrSignatureInvalid) {
+		t.Fatalf("Verify(changed query) error = %v, want %v", err, httpx.ErrSignatureInvalid)
+	}
+
+	req = sign()
+	req.Body = io.NopCloser(strings.NewReader("other"))
+	if err := signer.Verify(req); !errors.Is(err, httpx.ErrSignatureInvalid) {
+		t.Fatalf("Verify(changed body) error = %v, want %v", err, httpx.ErrSignatureInvalid)
+	}
+
+	req = sign()
+	other := &httpx.HMACSigner{KeyID: "svc-a", Secret: "other", Now: signer.Now}
+	if err := other.Verify(req); !errors.Is(err, httpx.ErrSignatureInvalid) {
+		t.Fatalf("Verify(other secret) error = %v, want %v", err, httpx.ErrSignatureInvalid)
+	}
+
+	req = sign()
+	late := &httpx.HMACSigner{KeyID: "svc-a", Secret: "s3cret", Now: func() time.Time { return now.Add(10 * time.Minute) }}
+	if err := late.Verify(req); !errors.Is(err, httpx.ErrSignatureExpired) {
+		t.Fatalf("Verify(late) error = %v, want %v", err, httpx.ErrSignatureExpired)
+	}
+}
+
+func TestAliyunSigner(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 23, 12, 0, 0, 0, time.UTC)
+	signer := &httpx.AliyunSigner{AccessKeyID: "ak", AccessKeySecret: "sk", SecurityToken: "sts", Now: func() time.Time { return now }}
+	req := httptest.NewRequest(http.MethodPost, "https://cs.aliyuncs.com/clusters?name=a%20b&page=1", strings.NewReader(`{"k":1}`))
+	req.Header.Set("Content-Type", httpx.ContentTypeJSON)
+	if err := signer.Sign(req); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	if got, want := req.Header.Get("Date"), "Mon, 23 Mar 2026 12:00:00 GMT"; got != want {
+		t.Fatalf("Date = %q, want %q", got, want)
+	}
+	sum := md5.Sum([]byte(`{"k":1}`))
+	if got, want := req.Header.Get("Content-MD5"), base64.StdEncoding.EncodeToString(sum[:]); got != want {
+		t.Fatalf("Content-MD5 = %q, want %q", got, want)
+	}
+	stringToSign := "POST\n" +
+		"application/json\n" +
+		req.Header.Get("Content-MD5") + "\n" +
+		"application/json\n" +
+		"Mon, 23 Mar 2026 12:00:00 GMT\n" +
+		"x-acs-security-token:sts\n" +
+		"x-acs-signature-method:HMAC-SHA1\n" +
+		"x-acs-signature-nonce:" + req.Header.Get("X-Acs-Signature-Nonce") + "\n" +
+		"x-acs-signature-version:1.0\n" +
+		"/clusters?name=a b&page=1"
+	mac := hmac.New(sha1.New, []byte("sk"))
+	mac.Write([]byte(stringToSign))
+	if got, want := req.Header.Get("Authorization"), "acs ak:"+base64.StdEncoding.EncodeToString(mac.Sum(nil)); got != want {
+		t.Fatalf("Authorization = %q, want %q", got, want)
+	}
+}
+
+func TestSignerRequiresKey(t *testing.T) {
+	t.Parallel()
+
+	client := httpx.NewClient(&httpx.ClientConfig{
+		DisableMetrics: true,
+		Signer:         &httpx.HMACSigner{},
+		HTTPClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			t.Fatal("unsigned request was sent")
+			return nil, nil
+		})},
+	})
+	_, err := client.Do(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: "http://example.com"})
+	if !errors.Is(err, httpx.ErrSignerKeyRequired) {
+		t.Fatalf("Do() error = %v, want %v", err, httpx.ErrSignerKeyRequired)
+	}
+}
@@ -0,0 +1,109 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bang-go/micro/pkg/budget"
+)
+
+// StreamResponse is a response whose body is read from the connection as the
+// caller consumes it. Close it to release the connection.
+type StreamResponse struct {
+	StatusCode    int
+	Status        string
+	Header        http.Header
+	Cookies       []*http.Cookie
+	ContentLength int64
+	Request       *RequestSnapshot
+
+	body   io.ReadCloser
+	finish func(readErr error) error
+	read   int64
+	err    error
+	once   sync.Once
+}
+
+func (r *StreamResponse) Success() bool {
+	return r != nil && r.StatusCode >= http.StatusOK && r.StatusCode < http.StatusMultipleChoices
+}
+
+func (r *StreamResponse) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.read += int64(n)
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.err = err
+	}
+	return n, err
+}
+
+// Close closes the body and records the request, with the duration covering
+// the body transfer.
+func (r *StreamResponse) Close() error {
+	var err error
+	r.once.Do(func() {
+		err = r.finish(r.err)
+	})
+	return err
+}
+
+// SendStream is Do without buffering the body. Timeout, capped by a deadline
+// budget on ctx, bounds the wait for the response headers only; reading the
+// body is bounded by ctx, so large downloads are not cut off.
+func (c *clientEntity) SendStream(ctx context.Context, req *Request) (*StreamResponse, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	httpReq, err := req.Build(streamCtx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if c.mirror != nil {
+		c.mirror.send(httpReq)
+	}
+
+	start := time.Now()
+	var timer *time.Timer
+	if timeout := budget.Timeout(ctx, c.httpClient.Timeout); timeout > 0 {
+		timer = time.AfterFunc(timeout, cancel)
+	}
+	httpResp, err := c.streamClient.Do(httpReq)
+	if timer != nil && !timer.Stop() {
+		// The timer canceled the request, possibly just as the headers
+		// arrived; either way the body is unusable.
+		if err == nil {
+			_ = httpResp.Body.Close()
+		}
+		err = fmt.Errorf("httpx: awaiting response headers: %w", context.DeadlineExceeded)
+	}
+	if err != nil {
+		cancel()
+		c.record(httpReq, 0, time.Since(start), err)
+		return nil, err
+	}
+
+	effectiveReq := effectiveRequest(httpReq, httpResp)
+	resp := &StreamResponse{
+		StatusCode:    httpResp.StatusCode,
+		Status:        httpResp.Status,
+		Header:        cloneHeader(httpResp.Header),
+		Cookies:       cloneCookies(httpResp.Cookies()),
+		ContentLength: httpResp.ContentLength,
+		Request:       newRequestSnapshot(effectiveReq),
+		body:          httpResp.Body,
+	}
+	resp.finish = func(readErr error) error {
+		closeErr := httpResp.Body.Close()
+		cancel()
+		c.record(effectiveReq, httpResp.StatusCode, time.Since(start), errors.Join(readErr, closeErr))
+		return closeErr
+	}
+	return resp, nil
+}
@@ -0,0 +1,52 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/httpx"
+)
+
+func TestClientSendStreamOutlivesTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-headers" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		flusher := w.(http.Flusher)
+		for _, chunk := range []string{"a", "b", "c"} {
+			_, _ = io.WriteString(w, chunk)
+			flusher.Flush()
+			time.Sleep(40 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	client := httpx.NewClient(&httpx.ClientConfig{Timeout: 50 * time.Millisecond, DisableMetrics: true})
+
+	resp, err := client.SendStream(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: server.URL + "/stream"})
+	if err != nil {
+		t.Fatalf("SendStream() error = %v", err)
+	}
+	body, err := io.ReadAll(resp)
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	if err := resp.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if string(body) != "abc" || !resp.Success() {
+		t.Fatalf("stream = %q, status %d", body, resp.StatusCode)
+	}
+
+	_, err = client.SendStream(context.Background(), &httpx.Request{Method: httpx.MethodGet, URL: server.URL + "/slow-headers"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("SendStream() slow headers error = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,57 @@
+package httpx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	"github.com/bang-go/micro/internal/tlsutil"
+)
+
+// ServerTLSConfig builds server credentials from files or PEM. Setting a
+// client CA turns on mTLS with RequireAndVerifyClientCert unless ClientAuth
+// says otherwise.
+type ServerTLSConfig struct {
+	CertFile     string
+	KeyFile      string
+	CertPEM      []byte
+	KeyPEM       []byte
+	Certificates []tls.Certificate
+	ClientCAFile string
+	ClientCAPEM  []byte
+	ClientCAs    *x509.CertPool
+	ClientAuth   tls.ClientAuthType
+	// MinVersion defaults to TLS 1.2.
+	MinVersion uint16
+	// ReloadInterval re-reads the files when their size or modification time
+	// changed, checked at most once per interval during handshakes; zero
+	// disables reloading. A failed reload keeps the previous credentials.
+	ReloadInterval time.Duration
+}
+
+var tlsLoader = tlsutil.Loader{
+	Prefix:                 "httpx",
+	ErrCertificateRequired: ErrTLSCertificateRequired,
+	ErrKeyPairIncomplete:   ErrTLSKeyPairIncomplete,
+	ErrInvalidCA:           ErrInvalidTLSCA,
+}
+
+// Build returns a tls.Config for the server offering HTTP/2 and HTTP/1.1;
+// with ReloadInterval set it serves the latest credentials through
+// GetConfigForClient.
+func (c *ServerTLSConfig) Build() (*tls.Config, error) {
+	return tlsLoader.Server((*tlsutil.Server)(c), []string{"h2", "http/1.1"})
+}
+
+func resolveServerTLSConfig(conf *ServerConfig) (*tls.Config, error) {
+	if conf.TLSConfig != nil && conf.TLS != nil {
+		return nil, ErrTLSConfigConflict
+	}
+	if conf.TLSConfig != nil {
+		return conf.TLSConfig.Clone(), nil
+	}
+	if conf.TLS != nil {
+		return conf.TLS.Build()
+	}
+	return nil, nil
+}
@@ -0,0 +1,253 @@
+package httpx_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/httpx"
+)
+
+// newTestCertificate returns a self-signed PEM certificate and key for
+// localhost that also acts as its own CA.
+func newTestCertificate(t *testing.T, serial int64) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func startServer(t *testing.T, conf *httpx.ServerConfig) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	conf.DisableMetrics = true
+	server := httpx.NewServer(conf)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ctx, listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, r.Proto)
+		}))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	})
+	return listener.Addr().String()
+}
+
+func TestServerTLSServesHTTP2(t *testing.T) {
+	certPEM, keyPEM := newTestCertificate(t, 1)
+	addr := startServer(t, &httpx.ServerConfig{TLS: &httpx.ServerTLSConfig{CertPEM: certPEM, KeyPEM: keyPEM}})
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(certPEM)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}, ForceAttemptHTTP2: true}}
+	resp, err := client.Get("https://" + addr + "/")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "HTTP/2.0" {
+		t.Fatalf("proto = %q, want HTTP/2.0", body)
+	}
+}
+
+func TestServerTLSReloadsRotatedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCertificate := func(serial int64) {
+		certPEM, keyPEM := newTestCertificate(t, serial)
+		if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeCertificate(1)
+	addr := startServer(t, &httpx.ServerConfig{TLS: &httpx.ServerTLSConfig{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ReloadInterval: 10 * time.Millisecond,
+	}})
+
+	serial := func() int64 {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+	}
+	if got := serial(); got != 1 {
+		t.Fatalf("serial = %d, want 1", got)
+	}
+
+	// Make sure the rewrite changes the modification time.
+	time.Sleep(20 * time.Millisecond)
+	writeCertificate(2)
+	deadline := time.Now().Add(2 * time.Second)
+	for serial() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("rotated certificate was not served")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestServerH2C(t *testing.T) {
+	addr := startServer(t, &httpx.ServerConfig{H2C: true})
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	resp, err := client.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("h2c GET error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HTTP/2.0" {
+		t.Fatalf("proto = %q, want HTTP/2.0", body)
+	}
+
+	resp, err = http.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("HTTP/1.1 GET error = %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HTTP/1.1" {
+		t.Fatalf("proto = %q, want HTTP/1.1", body)
+	}
+}
+
+func TestServerMaxHeaderBytes(t *testing.T) {
+	addr := startServer(t, &httpx.ServerConfig{MaxHeaderBytes: 1024})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/", nil)
+	req.Header.Set("X-Large", strings.Repeat("a", 8192))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestHeaderFieldsTooLarge {
+		t.Fatalf("status = %d, want 431", resp.StatusCode)
+	}
+}
+
+func TestServerReusePort(t *testing.T) {
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := probe.Addr().String()
+	_ = probe.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	errs := make(chan error, 2)
+	servers := make([]httpx.Server, 2)
+	for i := range servers {
+		servers[i] = httpx.NewServer(&httpx.ServerConfig{Addr: addr, ReusePort: true, DisableMetrics: true})
+		go func() { errs <- servers[i].Start(ctx, handler) }()
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for servers[0].HTTPServer() == nil || servers[1].HTTPServer() == nil {
+		select {
+		case err := <-errs:
+			if errors.Is(err, httpx.ErrReusePortUnsupported) {
+				t.Skip(err)
+			}
+			t.Fatalf("Start() error = %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("servers did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	resp, err := http.Get("http://" + addr + "/healthz")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+
+	cancel()
+	for range servers {
+		if err := <-errs; err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+	}
+}
+
+func TestServerTLSValidation(t *testing.T) {
+	server := httpx.NewServer(&httpx.ServerConfig{
+		TLS:            &httpx.ServerTLSConfig{},
+		TLSConfig:      &tls.Config{},
+		DisableMetrics: true,
+	})
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	if err := server.Serve(context.Background(), newPipeListener(), handler); !errors.Is(err, httpx.ErrTLSConfigConflict) {
+		t.Fatalf("Serve() error = %v, want ErrTLSConfigConflict", err)
+	}
+
+	server = httpx.NewServer(&httpx.ServerConfig{TLS: &httpx.ServerTLSConfig{}, DisableMetrics: true})
+	if err := server.Serve(context.Background(), newPipeListener(), handler); !errors.Is(err, httpx.ErrTLSCertificateRequired) {
+		t.Fatalf("Serve() error = %v, want ErrTLSCertificateRequired", err)
+	}
+
+	certPEM, _ := newTestCertificate(t, 1)
+	server = httpx.NewServer(&httpx.ServerConfig{TLS: &httpx.ServerTLSConfig{CertPEM: certPEM}, DisableMetrics: true})
+	if err := server.Serve(context.Background(), newPipeListener(), handler); !errors.Is(err, httpx.ErrTLSKeyPairIncomplete) {
+		t.Fatalf("Serve() error = %v, want ErrTLSKeyPairIncomplete", err)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 var sensitiveHeaders = map[string]struct{}{
@@ -132,3 +135,10 @@ func remoteAddrHost(remoteAddr string) string {
 	}
 	return host
 }
+
+func traceOptions(propagator propagation.TextMapPropagator, opts ...otelhttp.Option) []otelhttp.Option {
+	if propagator != nil {
+		opts = append(opts, otelhttp.WithPropagators(propagator))
+	}
+	return opts
+}
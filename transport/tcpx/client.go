@@ -20,6 +20,7 @@ const (
 	defaultClientKeepAlive    = 30 * time.Second
 	defaultServerKeepAlive    = 30 * time.Second
 	defaultServerShutdownTime = 10 * time.Second
+	defaultServerHandshake    = 10 * time.Second
 )
 
 type Client interface {
@@ -33,7 +34,9 @@ type ClientConfig struct {
 	ReadTimeout       time.Duration
 	WriteTimeout      time.Duration
 	KeepAlivePeriod   time.Duration
+	Socket            SocketOptions
 	TLSConfig         *tls.Config
+	Codec             Codec
 	Dialer            *net.Dialer
 	ContextDialer     func(context.Context, string, string) (net.Conn, error)
 	Logger            *logger.Logger
@@ -125,6 +128,8 @@ func (c *clientEntity) DialContext(ctx context.Context) (Connect, error) {
 	conn, err := NewConnect(rawConn,
 		WithReadTimeout(c.config.ReadTimeout),
 		WithWriteTimeout(c.config.WriteTimeout),
+		WithCodec(c.config.Codec),
+		WithSocketOptions(c.config.Socket),
 	)
 	if err != nil {
 		if rawConn != nil {
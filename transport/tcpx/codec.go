@@ -0,0 +1,199 @@
+package tcpx
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+const defaultMaxFrameSize = 4 << 20
+
+type Codec interface {
+	Decode(*bufio.Reader) ([]byte, error)
+	Encode([]byte) ([]byte, error)
+}
+
+// appendEncoder is implemented by the built-in codecs so WriteFrame can encode
+// into a pooled buffer instead of allocating a frame per write.
+type appendEncoder interface {
+	encodedSize(frame []byte) int
+	appendEncode(dst, frame []byte) ([]byte, error)
+}
+
+type LengthFieldCodec struct {
+	HeaderSize   int
+	LittleEndian bool
+	MaxFrameSize int
+}
+
+func (c LengthFieldCodec) Decode(r *bufio.Reader) ([]byte, error) {
+	headerSize, err := c.headerSize()
+	if err != nil {
+		return nil, err
+	}
+
+	// Peek keeps the header off the heap; ReadFull through io.Reader would not.
+	header, err := r.Peek(headerSize)
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	size := c.headerValue(header)
+	_, _ = r.Discard(headerSize)
+
+	if size > uint64(c.maxFrameSize()) {
+		return nil, ErrFrameTooLarge
+	}
+
+	frame := make([]byte, int(size))
+	if _, err := io.ReadFull(r, frame); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return frame, nil
+}
+
+func (c LengthFieldCodec) Encode(frame []byte) ([]byte, error) {
+	return c.appendEncode(make([]byte, 0, c.encodedSize(frame)), frame)
+}
+
+func (c LengthFieldCodec) encodedSize(frame []byte) int {
+	headerSize, _ := c.headerSize()
+	return headerSize + len(frame)
+}
+
+func (c LengthFieldCodec) appendEncode(dst, frame []byte) ([]byte, error) {
+	headerSize, err := c.headerSize()
+	if err != nil {
+		return nil, err
+	}
+	if len(frame) > c.maxFrameSize() || uint64(len(frame)) > maxHeaderValue(headerSize) {
+		return nil, ErrFrameTooLarge
+	}
+
+	dst = c.appendHeader(dst, headerSize, uint64(len(frame)))
+	return append(dst, frame...), nil
+}
+
+func (c LengthFieldCodec) headerSize() (int, error) {
+	switch c.HeaderSize {
+	case 0:
+		return 4, nil
+	case 1, 2, 4, 8:
+		return c.HeaderSize, nil
+	default:
+		return 0, ErrInvalidHeaderSize
+	}
+}
+
+func (c LengthFieldCodec) maxFrameSize() int {
+	if c.MaxFrameSize > 0 {
+		return c.MaxFrameSize
+	}
+	return defaultMaxFrameSize
+}
+
+func (c LengthFieldCodec) appendHeader(dst []byte, headerSize int, size uint64) []byte {
+	for i := range headerSize {
+		shift := 8 * (headerSize - 1 - i)
+		if c.LittleEndian {
+			shift = 8 * i
+		}
+		dst = append(dst, byte(size>>shift))
+	}
+	return dst
+}
+
+func (c LengthFieldCodec) headerValue(header []byte) uint64 {
+	var size uint64
+	for i, b := range header {
+		shift := 8 * (len(header) - 1 - i)
+		if c.LittleEndian {
+			shift = 8 * i
+		}
+		size |= uint64(b) << shift
+	}
+	return size
+}
+
+type LineCodec struct {
+	Delimiter    byte
+	MaxFrameSize int
+}
+
+func (c LineCodec) Decode(r *bufio.Reader) ([]byte, error) {
+	delimiter := c.delimiter()
+	maxFrameSize := c.maxFrameSize()
+
+	var frame []byte
+	for {
+		chunk, err := r.ReadSlice(delimiter)
+		if len(frame)+len(chunk) > maxFrameSize+1 {
+			return nil, ErrFrameTooLarge
+		}
+		frame = append(frame, chunk...)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if errors.Is(err, io.EOF) && len(frame) > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	frame = frame[:len(frame)-1]
+	if delimiter == '\n' {
+		frame = bytes.TrimSuffix(frame, []byte{'\r'})
+	}
+	return frame, nil
+}
+
+func (c LineCodec) Encode(frame []byte) ([]byte, error) {
+	return c.appendEncode(make([]byte, 0, c.encodedSize(frame)), frame)
+}
+
+func (c LineCodec) encodedSize(frame []byte) int {
+	return len(frame) + 1
+}
+
+func (c LineCodec) appendEncode(dst, frame []byte) ([]byte, error) {
+	delimiter := c.delimiter()
+	if len(frame) > c.maxFrameSize() {
+		return nil, ErrFrameTooLarge
+	}
+	if bytes.IndexByte(frame, delimiter) >= 0 {
+		return nil, ErrInvalidFrame
+	}
+
+	dst = append(dst, frame...)
+	return append(dst, delimiter), nil
+}
+
+func (c LineCodec) delimiter() byte {
+	if c.Delimiter == 0 {
+		return '\n'
+	}
+	return c.Delimiter
+}
+
+func (c LineCodec) maxFrameSize() int {
+	if c.MaxFrameSize > 0 {
+		return c.MaxFrameSize
+	}
+	return defaultMaxFrameSize
+}
+
+func maxHeaderValue(headerSize int) uint64 {
+	if headerSize >= 8 {
+		return ^uint64(0)
+	}
+	return 1<<(uint(headerSize)*8) - 1
+}
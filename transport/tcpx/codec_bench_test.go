@@ -0,0 +1,50 @@
+package tcpx_test
+
+import (
+	"testing"
+
+	"github.com/bang-go/micro/transport/tcpx"
+)
+
+// BenchmarkWriteFrame compares WriteFrame, which encodes the built-in codecs
+// into a pooled buffer, with encoding a fresh frame per write.
+func BenchmarkWriteFrame(b *testing.B) {
+	payload := make([]byte, 1024)
+	for _, tt := range []struct {
+		name  string
+		codec tcpx.Codec
+	}{
+		{"length", tcpx.LengthFieldCodec{}},
+		{"line", tcpx.LineCodec{}},
+	} {
+		stub := &stubConn{}
+		conn, err := tcpx.NewConnect(stub, tcpx.WithCodec(tt.codec))
+		if err != nil {
+			b.Fatalf("NewConnect: %v", err)
+		}
+		b.Run(tt.name+"/pooled", func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(payload)))
+			for b.Loop() {
+				stub.writes.Reset()
+				if err := conn.WriteFrame(payload); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(tt.name+"/encode", func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(payload)))
+			for b.Loop() {
+				stub.writes.Reset()
+				frame, err := tt.codec.Encode(payload)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := conn.WriteFull(frame); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
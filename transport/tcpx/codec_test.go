@@ -0,0 +1,149 @@
+package tcpx_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/bang-go/micro/transport/tcpx"
+)
+
+func TestLengthFieldCodecRoundTrip(t *testing.T) {
+	cases := []tcpx.LengthFieldCodec{
+		{},
+		{HeaderSize: 1},
+		{HeaderSize: 2, LittleEndian: true},
+		{HeaderSize: 8},
+	}
+	for _, codec := range cases {
+		encoded, err := codec.Encode([]byte("hello"))
+		if err != nil {
+			t.Fatalf("Encode(%+v): %v", codec, err)
+		}
+		frame, err := codec.Decode(bufio.NewReader(bytes.NewReader(encoded)))
+		if err != nil {
+			t.Fatalf("Decode(%+v): %v", codec, err)
+		}
+		if got, want := string(frame), "hello"; got != want {
+			t.Fatalf("frame = %q, want %q", got, want)
+		}
+	}
+
+	encoded, err := tcpx.LengthFieldCodec{HeaderSize: 2}.Encode([]byte("ab"))
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !bytes.Equal(encoded, []byte{0, 2, 'a', 'b'}) {
+		t.Fatalf("big endian header = %v", encoded)
+	}
+	encoded, _ = tcpx.LengthFieldCodec{HeaderSize: 2, LittleEndian: true}.Encode([]byte("ab"))
+	if !bytes.Equal(encoded, []byte{2, 0, 'a', 'b'}) {
+		t.Fatalf("little endian header = %v", encoded)
+	}
+}
+
+func TestLengthFieldCodecLimits(t *testing.T) {
+	codec := tcpx.LengthFieldCodec{HeaderSize: 2, MaxFrameSize: 4}
+	if _, err := codec.Encode([]byte("hello")); !errors.Is(err, tcpx.ErrFrameTooLarge) {
+		t.Fatalf("Encode oversized error = %v", err)
+	}
+	if _, err := codec.Decode(bufio.NewReader(bytes.NewReader([]byte{0, 5, 'h', 'e', 'l', 'l', 'o'}))); !errors.Is(err, tcpx.ErrFrameTooLarge) {
+		t.Fatalf("Decode oversized error = %v", err)
+	}
+	if _, err := (tcpx.LengthFieldCodec{HeaderSize: 1}).Encode(make([]byte, 256)); !errors.Is(err, tcpx.ErrFrameTooLarge) {
+		t.Fatalf("Encode header overflow error = %v", err)
+	}
+	if _, err := (tcpx.LengthFieldCodec{HeaderSize: 3}).Encode(nil); !errors.Is(err, tcpx.ErrInvalidHeaderSize) {
+		t.Fatalf("Encode invalid header error = %v", err)
+	}
+	if _, err := codec.Decode(bufio.NewReader(bytes.NewReader([]byte{0, 3, 'a'}))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Decode truncated error = %v", err)
+	}
+	if _, err := codec.Decode(bufio.NewReader(bytes.NewReader(nil))); !errors.Is(err, io.EOF) {
+		t.Fatalf("Decode empty error = %v", err)
+	}
+}
+
+func TestLineCodec(t *testing.T) {
+	codec := tcpx.LineCodec{MaxFrameSize: 8}
+	reader := bufio.NewReader(strings.NewReader("ping\r\npong\npartial"))
+
+	for _, want := range []string{"ping", "pong"} {
+		frame, err := codec.Decode(reader)
+		if err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		if string(frame) != want {
+			t.Fatalf("frame = %q, want %q", frame, want)
+		}
+	}
+	if _, err := codec.Decode(reader); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Decode partial error = %v", err)
+	}
+
+	if _, err := codec.Decode(bufio.NewReader(strings.NewReader("toolongline\n"))); !errors.Is(err, tcpx.ErrFrameTooLarge) {
+		t.Fatalf("Decode oversized error = %v", err)
+	}
+	if _, err := codec.Encode([]byte("a\nb")); !errors.Is(err, tcpx.ErrInvalidFrame) {
+		t.Fatalf("Encode delimiter error = %v", err)
+	}
+
+	encoded, err := tcpx.LineCodec{Delimiter: 0}.Encode([]byte("x"))
+	if err != nil || string(encoded) != "x\n" {
+		t.Fatalf("Encode = %q, %v", encoded, err)
+	}
+	encoded, err = tcpx.LineCodec{Delimiter: '|'}.Encode([]byte("x"))
+	if err != nil || string(encoded) != "x|" {
+		t.Fatalf("Encode custom delimiter = %q, %v", encoded, err)
+	}
+}
+
+func TestConnectReadWriteFrame(t *testing.T) {
+	left, right := net.Pipe()
+	defer left.Close()
+	defer right.Close()
+
+	codec := tcpx.LengthFieldCodec{HeaderSize: 2}
+	client, err := tcpx.NewConnect(left, tcpx.WithCodec(codec))
+	if err != nil {
+		t.Fatalf("NewConnect: %v", err)
+	}
+	server, err := tcpx.NewConnect(right, tcpx.WithCodec(codec))
+	if err != nil {
+		t.Fatalf("NewConnect: %v", err)
+	}
+
+	go func() {
+		_ = client.WriteFrame([]byte("first"))
+		_ = client.WriteFrame([]byte("second"))
+		_ = client.WriteFull([]byte("raw"))
+	}()
+
+	frame, err := server.ReadFrame()
+	if err != nil || string(frame) != "first" {
+		t.Fatalf("ReadFrame = %q, %v", frame, err)
+	}
+	frame, err = server.ReadFrame()
+	if err != nil || string(frame) != "second" {
+		t.Fatalf("ReadFrame = %q, %v", frame, err)
+	}
+	raw := make([]byte, 3)
+	if err := server.ReadFull(raw); err != nil || string(raw) != "raw" {
+		t.Fatalf("ReadFull after frames = %q, %v", raw, err)
+	}
+
+	plain, err := tcpx.NewConnect(&stubConn{})
+	if err != nil {
+		t.Fatalf("NewConnect: %v", err)
+	}
+	if _, err := plain.ReadFrame(); !errors.Is(err, tcpx.ErrCodecRequired) {
+		t.Fatalf("ReadFrame without codec error = %v", err)
+	}
+	if err := plain.WriteFrame([]byte("x")); !errors.Is(err, tcpx.ErrCodecRequired) {
+		t.Fatalf("WriteFrame without codec error = %v", err)
+	}
+}
@@ -1,12 +1,14 @@
 package tcpx
 
 import (
+	"bufio"
 	"io"
 	"net"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/bang-go/micro/pkg/bytesx"
 	"github.com/bang-go/opt"
 )
 
@@ -15,6 +17,8 @@ type Connect interface {
 	ReadFull([]byte) error
 	Write([]byte) (int, error)
 	WriteFull([]byte) error
+	ReadFrame() ([]byte, error)
+	WriteFrame([]byte) error
 	SetReadTimeout(time.Duration)
 	SetWriteTimeout(time.Duration)
 	LocalAddr() net.Addr
@@ -24,7 +28,9 @@ type Connect interface {
 }
 
 type connectEntity struct {
-	conn net.Conn
+	conn   net.Conn
+	codec  Codec
+	reader *bufio.Reader
 
 	readTimeout  atomic.Int64
 	writeTimeout atomic.Int64
@@ -46,12 +52,21 @@ func NewConnect(conn net.Conn, opts ...opt.Option[connectOptions]) (Connect, err
 
 	options := &connectOptions{}
 	opt.Each(options, opts...)
+	if options.socket != nil {
+		if err := applySocketOptions(conn, *options.socket); err != nil {
+			return nil, err
+		}
+	}
 
 	c := &connectEntity{
 		conn:    conn,
+		codec:   options.codec,
 		onRead:  options.onRead,
 		onWrite: options.onWrite,
 	}
+	if c.codec != nil {
+		c.reader = bufio.NewReader(readerFunc(c.readRaw))
+	}
 	c.readTimeout.Store(int64(options.readTimeout))
 	c.writeTimeout.Store(int64(options.writeTimeout))
 	return c, nil
@@ -65,9 +80,10 @@ func (c *connectEntity) Read(data []byte) (int, error) {
 		return 0, err
 	}
 
-	n, err := c.conn.Read(data)
-	c.observeRead(n)
-	return n, err
+	if c.reader != nil {
+		return c.reader.Read(data)
+	}
+	return c.readRaw(data)
 }
 
 func (c *connectEntity) ReadFull(data []byte) error {
@@ -78,10 +94,14 @@ func (c *connectEntity) ReadFull(data []byte) error {
 		return err
 	}
 
+	if c.reader != nil {
+		_, err := io.ReadFull(c.reader, data)
+		return err
+	}
+
 	remaining := data
 	for len(remaining) > 0 {
-		n, err := c.conn.Read(remaining)
-		c.observeRead(n)
+		n, err := c.readRaw(remaining)
 		remaining = remaining[n:]
 		if n == 0 && err == nil {
 			return io.ErrNoProgress
@@ -129,6 +149,43 @@ func (c *connectEntity) WriteFull(data []byte) error {
 	return nil
 }
 
+func (c *connectEntity) ReadFrame() ([]byte, error) {
+	if c.codec == nil {
+		return nil, ErrCodecRequired
+	}
+
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	if err := c.conn.SetReadDeadline(c.deadline(c.readTimeout.Load())); err != nil {
+		return nil, err
+	}
+	return c.codec.Decode(c.reader)
+}
+
+func (c *connectEntity) WriteFrame(frame []byte) error {
+	if c.codec == nil {
+		return ErrCodecRequired
+	}
+
+	encoder, ok := c.codec.(appendEncoder)
+	if !ok {
+		data, err := c.codec.Encode(frame)
+		if err != nil {
+			return err
+		}
+		return c.WriteFull(data)
+	}
+
+	buffer := bytesx.Get(encoder.encodedSize(frame))
+	defer bytesx.Put(buffer)
+	data, err := encoder.appendEncode((*buffer)[:0], frame)
+	if err != nil {
+		return err
+	}
+	return c.WriteFull(data)
+}
+
 func (c *connectEntity) SetReadTimeout(timeout time.Duration) {
 	c.readTimeout.Store(int64(timeout))
 }
@@ -167,6 +224,12 @@ func (c *connectEntity) deadline(raw int64) time.Time {
 	return time.Now().Add(timeout)
 }
 
+func (c *connectEntity) readRaw(data []byte) (int, error) {
+	n, err := c.conn.Read(data)
+	c.observeRead(n)
+	return n, err
+}
+
 func (c *connectEntity) observeRead(n int) {
 	if n > 0 && c.onRead != nil {
 		c.onRead(n)
@@ -178,3 +241,9 @@ func (c *connectEntity) observeWrite(n int) {
 		c.onWrite(n)
 	}
 }
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(data []byte) (int, error) {
+	return f(data)
+}
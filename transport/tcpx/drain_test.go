@@ -0,0 +1,161 @@
+package tcpx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/tcpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func startDrainServer(t *testing.T, conf *tcpx.ServerConfig, handler tcpx.HandlerFunc) (tcpx.Server, *pipeListener, <-chan error) {
+	t.Helper()
+
+	listener := newPipeListener()
+	conf.Listener = listener
+	server := tcpx.NewServer(conf)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Start(context.Background(), handler)
+	}()
+	waitForServer(t, server)
+	return server, listener, errCh
+}
+
+func TestServerDrainWaitsForInFlightConnections(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	finished := make(chan error, 1)
+
+	server, listener, errCh := startDrainServer(t, &tcpx.ServerConfig{
+		DrainConnections:  true,
+		MetricsRegisterer: reg,
+	}, func(ctx context.Context, conn tcpx.Connect) error {
+		close(started)
+		<-release
+		err := ctx.Err()
+		finished <- err
+		return err
+	})
+
+	conn, err := listener.Dial()
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- server.Shutdown(context.Background())
+	}()
+
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("Shutdown() returned before handler finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-finished; err != nil {
+		t.Fatalf("handler context error = %v, want nil without DrainNotify", err)
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got := gatheredHistogramCount(t, reg, "tcpx_server_drain_duration_seconds"); got != 1 {
+		t.Fatalf("drain observations = %d, want 1", got)
+	}
+}
+
+func TestServerDrainNotifyCancelsHandlers(t *testing.T) {
+	started := make(chan struct{})
+	server, listener, errCh := startDrainServer(t, &tcpx.ServerConfig{
+		DrainConnections: true,
+		DrainNotify:      true,
+		DisableMetrics:   true,
+	}, func(ctx context.Context, conn tcpx.Connect) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	})
+
+	conn, err := listener.Dial()
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
+
+func TestServerDrainForceClosesAfterDeadline(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	started := make(chan struct{})
+	server, listener, errCh := startDrainServer(t, &tcpx.ServerConfig{
+		DrainConnections:  true,
+		MetricsRegisterer: reg,
+	}, func(ctx context.Context, conn tcpx.Connect) error {
+		close(started)
+		_, err := conn.Read(make([]byte, 1))
+		return err
+	})
+
+	conn, err := listener.Dial()
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop after forced close")
+	}
+	if got := gatheredCounter(t, reg, "tcpx_server_drain_forced_connections_total"); got != 1 {
+		t.Fatalf("forced connections = %v, want 1", got)
+	}
+}
+
+func gatheredHistogramCount(t *testing.T, reg *prometheus.Registry, name string) uint64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	var total uint64
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			total += metric.GetHistogram().GetSampleCount()
+		}
+	}
+	return total
+}
@@ -10,6 +10,21 @@ var (
 	ErrClientAddrRequired   = errors.New("tcpx: client addr is required")
 	ErrServerAddrRequired   = errors.New("tcpx: server addr or listener is required")
 	ErrServerAlreadyRunning = errors.New("tcpx: server already running")
+	ErrCodecRequired        = errors.New("tcpx: codec is required")
+	ErrFrameTooLarge        = errors.New("tcpx: frame exceeds max frame size")
+	ErrInvalidFrame         = errors.New("tcpx: frame contains delimiter")
+	ErrInvalidHeaderSize    = errors.New("tcpx: length header size must be 1, 2, 4 or 8")
+
+	ErrTLSCertificateRequired  = errors.New("tcpx: tls certificate is required")
+	ErrInvalidClientCA         = errors.New("tcpx: client ca contains no valid certificates")
+	ErrTLSConfigConflict       = errors.New("tcpx: TLSConfig and TLS cannot both be set")
+	ErrPoolClosed              = errors.New("tcpx: pool closed")
+	ErrReusePortUnsupported    = errors.New("tcpx: SO_REUSEPORT is not supported on this platform")
+	ErrSessionClosed           = errors.New("tcpx: session closed")
+	ErrInvalidCorrelationFrame = errors.New("tcpx: frame is too short for a correlation id")
+	ErrServerNotRunning        = errors.New("tcpx: server not running")
+	ErrServerDraining          = errors.New("tcpx: server draining")
+	ErrHealthAddrRequired      = errors.New("tcpx: health addr or listener is required")
 
 	errServerClosed          = errors.New("tcpx: server closed")
 	errMaxConnectionsReached = errors.New("tcpx: max connections reached")
@@ -0,0 +1,130 @@
+package tcpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+const defaultHealthTimeout = time.Second
+
+var healthResponse = []byte("OK\n")
+
+// HealthConfig opens a side listener for load balancer TCP checks. A ready
+// server answers each connection with "OK\n" and closes it; a failing check
+// resets the connection without a payload. The listener is closed as soon as
+// Shutdown starts, so plain connect checks fail during a drain.
+type HealthConfig struct {
+	Addr     string
+	Listener net.Listener
+	// Check adds an application readiness check on top of the server state.
+	Check   func(context.Context) error
+	Timeout time.Duration
+}
+
+func (s *serverEntity) Ready(ctx context.Context) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	running, draining := s.running, s.draining
+	s.mu.RUnlock()
+
+	if !running {
+		return ErrServerNotRunning
+	}
+	if draining {
+		return ErrServerDraining
+	}
+	if s.config.Health != nil && s.config.Health.Check != nil {
+		return s.config.Health.Check(ctx)
+	}
+	return nil
+}
+
+// HealthHandler adapts Server.Ready to the /healthz convention of httpx and
+// ginx, e.g. ginx.ServerConfig{HealthHandler: tcpx.HealthHandler(server)}.
+func HealthHandler(server Server) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if server == nil || server.Ready(r.Context()) != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("UNAVAILABLE"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("OK"))
+	})
+}
+
+func (s *serverEntity) listenHealth() (net.Listener, error) {
+	health := s.config.Health
+	if health == nil {
+		return nil, nil
+	}
+	if health.Listener != nil {
+		return health.Listener, nil
+	}
+	if health.Addr == "" {
+		return nil, ErrHealthAddrRequired
+	}
+	listener, err := net.Listen("tcp", health.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("tcpx: health listen: %w", err)
+	}
+	return listener, nil
+}
+
+func (s *serverEntity) serveHealth(ctx context.Context, listener net.Listener) {
+	timeout := s.config.Health.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if isListenerClosedError(err) || ctx.Err() != nil {
+				return
+			}
+			if isTemporaryNetError(err) {
+				time.Sleep(5 * time.Millisecond)
+				continue
+			}
+			s.config.Logger.Warn(ctx, "tcp health listener stopped", "error", err)
+			return
+		}
+		go s.answerHealth(ctx, conn, timeout)
+	}
+}
+
+func (s *serverEntity) answerHealth(ctx context.Context, conn net.Conn, timeout time.Duration) {
+	defer conn.Close()
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := s.Ready(checkCtx); err != nil {
+		s.observeHealth("unavailable")
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			_ = tcpConn.SetLinger(0)
+		}
+		return
+	}
+
+	s.observeHealth("ok")
+	_ = conn.SetWriteDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write(healthResponse); err != nil && !errors.Is(err, net.ErrClosed) {
+		s.config.Logger.Warn(ctx, "tcp health response failed", "error", err)
+	}
+}
+
+func (s *serverEntity) observeHealth(result string) {
+	if s.metrics != nil {
+		s.metrics.healthChecks.WithLabelValues(result).Inc()
+	}
+}
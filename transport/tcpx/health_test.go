@@ -0,0 +1,186 @@
+package tcpx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/tcpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func readHealth(t *testing.T, dial func() (net.Conn, error)) (string, error) {
+	t.Helper()
+
+	conn, err := dial()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	data, err := io.ReadAll(conn)
+	return string(data), err
+}
+
+func assertListenerClosed(t *testing.T, listener *pipeListener) {
+	t.Helper()
+
+	select {
+	case <-listener.closed:
+	default:
+		t.Fatal("health listener still open")
+	}
+}
+
+func healthStatus(server tcpx.Server) int {
+	recorder := httptest.NewRecorder()
+	tcpx.HealthHandler(server).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	return recorder.Code
+}
+
+func TestServerHealthListenerReportsReadiness(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	healthListener := newPipeListener()
+	checkErr := make(chan error, 1)
+	checkErr <- nil
+
+	server, _, errCh := startDrainServer(t, &tcpx.ServerConfig{
+		MetricsRegisterer: reg,
+		Health: &tcpx.HealthConfig{
+			Listener: healthListener,
+			Check: func(context.Context) error {
+				err := <-checkErr
+				checkErr <- err
+				return err
+			},
+		},
+	}, func(ctx context.Context, conn tcpx.Connect) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	if got, err := readHealth(t, healthListener.Dial); err != nil || got != "OK\n" {
+		t.Fatalf("health response = %q, %v, want OK", got, err)
+	}
+	if err := server.Ready(context.Background()); err != nil {
+		t.Fatalf("Ready() error = %v", err)
+	}
+	if got := healthStatus(server); got != http.StatusOK {
+		t.Fatalf("HealthHandler status = %d, want 200", got)
+	}
+
+	<-checkErr
+	checkErr <- errors.New("db down")
+	if got, _ := readHealth(t, healthListener.Dial); got != "" {
+		t.Fatalf("unhealthy response = %q, want no payload", got)
+	}
+	if got := healthStatus(server); got != http.StatusServiceUnavailable {
+		t.Fatalf("HealthHandler status = %d, want 503", got)
+	}
+	if got := gatheredCounter(t, reg, "tcpx_health_checks_total"); got != 2 {
+		t.Fatalf("tcpx_health_checks_total = %v, want 2", got)
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	assertListenerClosed(t, healthListener)
+	if err := server.Ready(context.Background()); !errors.Is(err, tcpx.ErrServerNotRunning) {
+		t.Fatalf("Ready() after shutdown error = %v, want %v", err, tcpx.ErrServerNotRunning)
+	}
+}
+
+func TestServerNotReadyWhileDraining(t *testing.T) {
+	healthListener := newPipeListener()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	server, listener, errCh := startDrainServer(t, &tcpx.ServerConfig{
+		DrainConnections: true,
+		DisableMetrics:   true,
+		Health:           &tcpx.HealthConfig{Listener: healthListener},
+	}, func(ctx context.Context, conn tcpx.Connect) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	conn, err := listener.Dial()
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- server.Shutdown(context.Background())
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for !errors.Is(server.Ready(context.Background()), tcpx.ErrServerDraining) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Ready() error = %v, want %v", server.Ready(context.Background()), tcpx.ErrServerDraining)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	assertListenerClosed(t, healthListener)
+
+	close(release)
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
+
+func TestServerHealthListenerOnAddr(t *testing.T) {
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("loopback unavailable: %v", err)
+	}
+	addr := probe.Addr().String()
+	_ = probe.Close()
+
+	server, _, errCh := startDrainServer(t, &tcpx.ServerConfig{
+		DisableMetrics: true,
+		Health:         &tcpx.HealthConfig{Addr: addr},
+	}, func(ctx context.Context, conn tcpx.Connect) error {
+		return nil
+	})
+	defer func() {
+		_ = server.Shutdown(context.Background())
+		<-errCh
+	}()
+
+	got, err := readHealth(t, func() (net.Conn, error) {
+		return net.DialTimeout("tcp", addr, time.Second)
+	})
+	if err != nil || got != "OK\n" {
+		t.Fatalf("health response = %q, %v, want OK", got, err)
+	}
+}
+
+func TestServerHealthRequiresAddr(t *testing.T) {
+	server := tcpx.NewServer(&tcpx.ServerConfig{
+		Listener:       newPipeListener(),
+		DisableMetrics: true,
+		Health:         &tcpx.HealthConfig{},
+	})
+	err := server.Start(context.Background(), tcpx.HandlerFunc(func(context.Context, tcpx.Connect) error { return nil }))
+	if !errors.Is(err, tcpx.ErrHealthAddrRequired) {
+		t.Fatalf("Start() error = %v, want %v", err, tcpx.ErrHealthAddrRequired)
+	}
+	if err := server.Ready(context.Background()); !errors.Is(err, tcpx.ErrServerNotRunning) {
+		t.Fatalf("Ready() error = %v, want %v", err, tcpx.ErrServerNotRunning)
+	}
+}
@@ -0,0 +1,75 @@
+package tcpx
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const minIdleReapInterval = 10 * time.Millisecond
+
+type connectionState struct {
+	lastActive   atomic.Int64
+	bytesRead    atomic.Int64
+	bytesWritten atomic.Int64
+}
+
+func newConnectionState() *connectionState {
+	state := &connectionState{}
+	state.touch()
+	return state
+}
+
+func (s *connectionState) touch() {
+	s.lastActive.Store(time.Now().UnixNano())
+}
+
+func (s *connectionState) addRead(n int) {
+	s.bytesRead.Add(int64(n))
+	s.touch()
+}
+
+func (s *connectionState) addWritten(n int) {
+	s.bytesWritten.Add(int64(n))
+	s.touch()
+}
+
+func (s *connectionState) idleSince(now time.Time) time.Duration {
+	return now.Sub(time.Unix(0, s.lastActive.Load()))
+}
+
+// reapIdleConnections closes connections without I/O for longer than
+// IdleTimeout. It runs until the serve context is canceled.
+func (s *serverEntity) reapIdleConnections(ctx context.Context, addrLabel string) {
+	interval := max(s.config.IdleTimeout/2, minIdleReapInterval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, conn := range s.idleConnections(now) {
+				if s.metrics != nil {
+					s.metrics.serverConnectionsReaped.WithLabelValues(addrLabel).Inc()
+				}
+				_ = conn.Close()
+				s.info(ctx, "tcp idle connection reaped", "remote_addr", conn.RemoteAddr().String(), "idle_timeout", s.config.IdleTimeout)
+			}
+		}
+	}
+}
+
+func (s *serverEntity) idleConnections(now time.Time) []Connect {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var idle []Connect
+	for conn, state := range s.connections {
+		if state != nil && state.idleSince(now) >= s.config.IdleTimeout {
+			idle = append(idle, conn)
+		}
+	}
+	return idle
+}
@@ -0,0 +1,96 @@
+package tcpx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/tcpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestServerReapsIdleConnections(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	readErr := make(chan error, 1)
+
+	server, listener, errCh := startDrainServer(t, &tcpx.ServerConfig{
+		IdleTimeout:       40 * time.Millisecond,
+		MetricsRegisterer: reg,
+	}, func(ctx context.Context, conn tcpx.Connect) error {
+		buf := make([]byte, 1)
+		_, err := conn.Read(buf)
+		readErr <- err
+		return nil
+	})
+
+	conn, err := listener.Dial()
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	select {
+	case err := <-readErr:
+		if err == nil {
+			t.Fatal("Read() error = nil, want closed connection")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("idle connection was not reaped")
+	}
+	if got := gatheredCounter(t, reg, "tcpx_server_connections_reaped_total"); got != 1 {
+		t.Fatalf("reaped connections = %v, want 1", got)
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
+
+func TestServerKeepsActiveConnections(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	done := make(chan error, 1)
+
+	server, listener, errCh := startDrainServer(t, &tcpx.ServerConfig{
+		IdleTimeout:       60 * time.Millisecond,
+		MetricsRegisterer: reg,
+	}, func(ctx context.Context, conn tcpx.Connect) error {
+		buf := make([]byte, 1)
+		for range 5 {
+			if err := conn.ReadFull(buf); err != nil {
+				done <- err
+				return err
+			}
+		}
+		done <- nil
+		return nil
+	})
+
+	conn, err := listener.Dial()
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	for range 5 {
+		time.Sleep(20 * time.Millisecond)
+		if _, err := conn.Write([]byte{1}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if got := gatheredCounter(t, reg, "tcpx_server_connections_reaped_total"); got != 0 {
+		t.Fatalf("reaped connections = %v, want 0", got)
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+package tcpx
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultAcceptLimiterIdleTTL = 10 * time.Minute
+	minBandwidthBurst           = 32 << 10
+)
+
+type AcceptRateLimitConfig struct {
+	// Rate is the number of connections per second allowed from one source IP.
+	Rate float64
+	// Burst defaults to ceil(Rate), at least 1.
+	Burst int
+	// IdleTTL evicts per-IP buckets that have not been used for this long.
+	IdleTTL           time.Duration
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+}
+
+type BandwidthLimitConfig struct {
+	ReadBytesPerSecond  int
+	WriteBytesPerSecond int
+	// Burst defaults to the per-direction rate, at least 32KiB.
+	Burst             int
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+}
+
+type acceptLimiter struct {
+	rate    rate.Limit
+	burst   int
+	idleTTL time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*acceptBucket
+	lastSweep time.Time
+}
+
+type acceptBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// AcceptRateLimit closes connections from source IPs that exceed a token
+// bucket rate. Rejected connections are counted, not logged as errors.
+func AcceptRateLimit(conf *AcceptRateLimitConfig) Interceptor {
+	if conf == nil || conf.Rate <= 0 {
+		return nil
+	}
+
+	burst := conf.Burst
+	if burst <= 0 {
+		burst = max(1, int(math.Ceil(conf.Rate)))
+	}
+	idleTTL := conf.IdleTTL
+	if idleTTL <= 0 {
+		idleTTL = defaultAcceptLimiterIdleTTL
+	}
+	limiter := &acceptLimiter{
+		rate:    rate.Limit(conf.Rate),
+		burst:   burst,
+		idleTTL: idleTTL,
+		now:     time.Now,
+		buckets: make(map[string]*acceptBucket),
+	}
+	metrics := limiterMetrics(conf.MetricsRegisterer, conf.DisableMetrics)
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, conn Connect) error {
+			host, _ := splitAddr(conn.RemoteAddr())
+			if !limiter.allow(host) {
+				if metrics != nil {
+					metrics.serverAcceptRateLimited.Inc()
+				}
+				return conn.Close()
+			}
+			return next.Handle(ctx, conn)
+		})
+	}
+}
+
+func (l *acceptLimiter) allow(host string) bool {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= l.idleTTL {
+		for key, bucket := range l.buckets {
+			if now.Sub(bucket.lastSeen) >= l.idleTTL {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, ok := l.buckets[host]
+	if !ok {
+		bucket = &acceptBucket{limiter: rate.NewLimiter(l.rate, l.burst)}
+		l.buckets[host] = bucket
+	}
+	bucket.lastSeen = now
+	return bucket.limiter.AllowN(now, 1)
+}
+
+// BandwidthLimit throttles reads and writes on each connection independently.
+// Waiting is bound to the connection context, so shutdown interrupts throttled I/O.
+func BandwidthLimit(conf *BandwidthLimitConfig) Interceptor {
+	if conf == nil || (conf.ReadBytesPerSecond <= 0 && conf.WriteBytesPerSecond <= 0) {
+		return nil
+	}
+
+	cloned := *conf
+	metrics := limiterMetrics(conf.MetricsRegisterer, conf.DisableMetrics)
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, conn Connect) error {
+			throttled := &throttledConnect{
+				Connect: conn,
+				ctx:     ctx,
+				read:    newBandwidthLimiter(cloned.ReadBytesPerSecond, cloned.Burst),
+				write:   newBandwidthLimiter(cloned.WriteBytesPerSecond, cloned.Burst),
+				metrics: metrics,
+			}
+			return next.Handle(ctx, throttled)
+		})
+	}
+}
+
+func newBandwidthLimiter(bytesPerSecond int, burst int) *rate.Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = max(bytesPerSecond, minBandwidthBurst)
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+}
+
+type throttledConnect struct {
+	Connect
+	ctx     context.Context
+	read    *rate.Limiter
+	write   *rate.Limiter
+	metrics *metrics
+}
+
+func (c *throttledConnect) Read(data []byte) (int, error) {
+	if c.read == nil {
+		return c.Connect.Read(data)
+	}
+	if len(data) > c.read.Burst() {
+		data = data[:c.read.Burst()]
+	}
+	n, err := c.Connect.Read(data)
+	if waitErr := c.wait(c.read, n, "read"); waitErr != nil && err == nil {
+		err = waitErr
+	}
+	return n, err
+}
+
+func (c *throttledConnect) ReadFull(data []byte) error {
+	if c.read == nil {
+		return c.Connect.ReadFull(data)
+	}
+	for len(data) > 0 {
+		chunk := data[:min(len(data), c.read.Burst())]
+		if err := c.Connect.ReadFull(chunk); err != nil {
+			return err
+		}
+		if err := c.wait(c.read, len(chunk), "read"); err != nil {
+			return err
+		}
+		data = data[len(chunk):]
+	}
+	return nil
+}
+
+func (c *throttledConnect) ReadFrame() ([]byte, error) {
+	frame, err := c.Connect.ReadFrame()
+	if err != nil || c.read == nil {
+		return frame, err
+	}
+	if err := c.wait(c.read, len(frame), "read"); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+func (c *throttledConnect) Write(data []byte) (int, error) {
+	if c.write == nil {
+		return c.Connect.Write(data)
+	}
+	written := 0
+	for len(data) > 0 {
+		chunk := data[:min(len(data), c.write.Burst())]
+		if err := c.wait(c.write, len(chunk), "write"); err != nil {
+			return written, err
+		}
+		n, err := c.Connect.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		data = data[n:]
+	}
+	return written, nil
+}
+
+func (c *throttledConnect) WriteFull(data []byte) error {
+	if c.write == nil {
+		return c.Connect.WriteFull(data)
+	}
+	for len(data) > 0 {
+		chunk := data[:min(len(data), c.write.Burst())]
+		if err := c.wait(c.write, len(chunk), "write"); err != nil {
+			return err
+		}
+		if err := c.Connect.WriteFull(chunk); err != nil {
+			return err
+		}
+		data = data[len(chunk):]
+	}
+	return nil
+}
+
+func (c *throttledConnect) WriteFrame(frame []byte) error {
+	if c.write != nil {
+		if err := c.wait(c.write, len(frame), "write"); err != nil {
+			return err
+		}
+	}
+	return c.Connect.WriteFrame(frame)
+}
+
+func (c *throttledConnect) wait(limiter *rate.Limiter, n int, direction string) error {
+	throttled := false
+	for n > 0 {
+		chunk := min(n, limiter.Burst())
+		reservation := limiter.ReserveN(time.Now(), chunk)
+		if delay := reservation.Delay(); delay > 0 {
+			throttled = true
+			timer := time.NewTimer(delay)
+			select {
+			case <-c.ctx.Done():
+				timer.Stop()
+				reservation.Cancel()
+				return context.Cause(c.ctx)
+			case <-timer.C:
+			}
+		}
+		n -= chunk
+	}
+	if throttled && c.metrics != nil {
+		c.metrics.serverThrottled.WithLabelValues(direction).Inc()
+	}
+	return nil
+}
+
+func limiterMetrics(registerer prometheus.Registerer, disabled bool) *metrics {
+	if disabled {
+		return nil
+	}
+	if registerer != nil {
+		return newTCPMetrics(registerer)
+	}
+	return defaultTCPMetrics()
+}
@@ -0,0 +1,140 @@
+package tcpx_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/tcpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestAcceptRateLimitClosesExcessConnections(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	interceptor := tcpx.AcceptRateLimit(&tcpx.AcceptRateLimitConfig{
+		Rate:              0.001,
+		Burst:             2,
+		MetricsRegisterer: reg,
+	})
+
+	handled := 0
+	handler := interceptor(tcpx.HandlerFunc(func(context.Context, tcpx.Connect) error {
+		handled++
+		return nil
+	}))
+
+	for i := 0; i < 3; i++ {
+		client, server := net.Pipe()
+		conn, err := tcpx.NewConnect(server)
+		if err != nil {
+			t.Fatalf("NewConnect() error = %v", err)
+		}
+		if err := handler.Handle(context.Background(), conn); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		_ = client.Close()
+		_ = conn.Close()
+	}
+
+	if handled != 2 {
+		t.Fatalf("handled = %d, want 2", handled)
+	}
+	if got := gatheredCounter(t, reg, "tcpx_server_accept_rate_limited_total"); got != 1 {
+		t.Fatalf("rate limited = %v, want 1", got)
+	}
+}
+
+func TestBandwidthLimitThrottlesWrites(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	interceptor := tcpx.BandwidthLimit(&tcpx.BandwidthLimitConfig{
+		WriteBytesPerSecond: 1000,
+		Burst:               100,
+		MetricsRegisterer:   reg,
+	})
+
+	client, server := net.Pipe()
+	defer client.Close()
+	conn, err := tcpx.NewConnect(server)
+	if err != nil {
+		t.Fatalf("NewConnect() error = %v", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		_, _ = io.Copy(io.Discard, client)
+	}()
+
+	start := time.Now()
+	err = interceptor(tcpx.HandlerFunc(func(ctx context.Context, conn tcpx.Connect) error {
+		return conn.WriteFull(make([]byte, 300))
+	})).Handle(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("elapsed = %v, want throttled write", elapsed)
+	}
+
+	if got := gatheredCounter(t, reg, "tcpx_server_throttled_total"); got < 1 {
+		t.Fatalf("throttled = %v, want at least 1", got)
+	}
+}
+
+func TestBandwidthLimitStopsOnContextCancel(t *testing.T) {
+	interceptor := tcpx.BandwidthLimit(&tcpx.BandwidthLimitConfig{
+		ReadBytesPerSecond: 10,
+		Burst:              10,
+		DisableMetrics:     true,
+	})
+
+	client, server := net.Pipe()
+	defer client.Close()
+	conn, err := tcpx.NewConnect(server)
+	if err != nil {
+		t.Fatalf("NewConnect() error = %v", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		_, _ = client.Write(make([]byte, 40))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = interceptor(tcpx.HandlerFunc(func(ctx context.Context, conn tcpx.Connect) error {
+		return conn.ReadFull(make([]byte, 40))
+	})).Handle(ctx, conn)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Handle() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestLimitInterceptorsDisabledWithoutRate(t *testing.T) {
+	if tcpx.AcceptRateLimit(nil) != nil || tcpx.AcceptRateLimit(&tcpx.AcceptRateLimitConfig{}) != nil {
+		t.Fatal("AcceptRateLimit without rate should be nil")
+	}
+	if tcpx.BandwidthLimit(&tcpx.BandwidthLimitConfig{}) != nil {
+		t.Fatal("BandwidthLimit without rate should be nil")
+	}
+}
+
+func gatheredCounter(t *testing.T, reg *prometheus.Registry, name string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	total := 0.0
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			total += metric.GetCounter().GetValue()
+		}
+	}
+	return total
+}
@@ -16,6 +16,22 @@ type metrics struct {
 	serverConnectionDuration  *prometheus.HistogramVec
 	serverBytesRead           *prometheus.CounterVec
 	serverBytesWritten        *prometheus.CounterVec
+	poolConnections           *prometheus.GaugeVec
+	poolBorrowDuration        *prometheus.HistogramVec
+	poolHealthChecks          *prometheus.CounterVec
+	serverAcceptRateLimited   prometheus.Counter
+	serverThrottled           *prometheus.CounterVec
+	serverDrainDuration       *prometheus.HistogramVec
+	serverDrainForced         *prometheus.CounterVec
+	serverConnectionsReaped   *prometheus.CounterVec
+	sessionCallDuration       *prometheus.HistogramVec
+	sessionInFlight           *prometheus.GaugeVec
+	sessionOrphans            *prometheus.CounterVec
+	healthChecks              *prometheus.CounterVec
+	serverAcceptErrors        *prometheus.CounterVec
+	serverConnectionsLimit    *prometheus.GaugeVec
+	serverHandlerQueueWait    *prometheus.HistogramVec
+	serverHandlerDuration     *prometheus.HistogramVec
 }
 
 var (
@@ -97,6 +113,122 @@ func newTCPMetrics(registerer prometheus.Registerer) *metrics {
 			},
 			[]string{"addr"},
 		),
+		poolConnections: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "tcpx_pool_connections",
+				Help: "Current number of TCP pool connections by state.",
+			},
+			[]string{"addr", "state"},
+		),
+		poolBorrowDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "tcpx_pool_borrow_duration_seconds",
+				Help:    "Time spent borrowing a TCP pool connection in seconds.",
+				Buckets: []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+			},
+			[]string{"addr", "result"},
+		),
+		poolHealthChecks: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcpx_pool_health_checks_total",
+				Help: "Total number of TCP pool connection health checks.",
+			},
+			[]string{"addr", "result"},
+		),
+		serverAcceptRateLimited: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "tcpx_server_accept_rate_limited_total",
+				Help: "Total number of TCP connections closed by the per-IP accept rate limit.",
+			},
+		),
+		serverThrottled: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcpx_server_throttled_total",
+				Help: "Total number of TCP I/O operations delayed by bandwidth throttling.",
+			},
+			[]string{"direction"},
+		),
+		serverDrainDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "tcpx_server_drain_duration_seconds",
+				Help:    "Time spent waiting for TCP connections during shutdown in seconds.",
+				Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60},
+			},
+			[]string{"addr", "result"},
+		),
+		serverDrainForced: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcpx_server_drain_forced_connections_total",
+				Help: "Total number of TCP connections force-closed after the drain deadline.",
+			},
+			[]string{"addr"},
+		),
+		serverConnectionsReaped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcpx_server_connections_reaped_total",
+				Help: "Total number of idle TCP server connections closed by the reaper.",
+			},
+			[]string{"addr"},
+		),
+		sessionCallDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "tcpx_session_call_duration_seconds",
+				Help:    "TCP session call duration in seconds.",
+				Buckets: []float64{0.0005, 0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+			},
+			[]string{"addr", "result"},
+		),
+		sessionInFlight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "tcpx_session_in_flight",
+				Help: "Current number of in-flight TCP session calls.",
+			},
+			[]string{"addr"},
+		),
+		sessionOrphans: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcpx_session_orphaned_responses_total",
+				Help: "Total number of TCP session responses that matched no pending call.",
+			},
+			[]string{"addr"},
+		),
+		healthChecks: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcpx_health_checks_total",
+				Help: "Total number of TCP health listener checks by result.",
+			},
+			[]string{"result"},
+		),
+		serverAcceptErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcpx_server_accept_errors_total",
+				Help: "Total number of TCP accept loop failures that did not stop the server.",
+			},
+			[]string{"addr", "reason"},
+		),
+		serverConnectionsLimit: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "tcpx_server_connections_limit",
+				Help: "Configured maximum concurrent TCP server connections; 0 means unlimited.",
+			},
+			[]string{"addr"},
+		),
+		serverHandlerQueueWait: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "tcpx_server_handler_queue_wait_seconds",
+				Help:    "Time from accept until the TCP handler starts, including the TLS handshake, in seconds.",
+				Buckets: []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+			},
+			[]string{"addr"},
+		),
+		serverHandlerDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "tcpx_server_handler_duration_seconds",
+				Help:    "TCP handler execution duration in seconds.",
+				Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+			},
+			[]string{"addr", "result"},
+		),
 	}
 
 	mustRegisterCollector(registerer, &m.clientDialDuration, m.clientDialDuration)
@@ -108,6 +240,22 @@ func newTCPMetrics(registerer prometheus.Registerer) *metrics {
 	mustRegisterCollector(registerer, &m.serverConnectionDuration, m.serverConnectionDuration)
 	mustRegisterCollector(registerer, &m.serverBytesRead, m.serverBytesRead)
 	mustRegisterCollector(registerer, &m.serverBytesWritten, m.serverBytesWritten)
+	mustRegisterCollector(registerer, &m.poolConnections, m.poolConnections)
+	mustRegisterCollector(registerer, &m.poolBorrowDuration, m.poolBorrowDuration)
+	mustRegisterCollector(registerer, &m.poolHealthChecks, m.poolHealthChecks)
+	mustRegisterCollector(registerer, &m.serverAcceptRateLimited, m.serverAcceptRateLimited)
+	mustRegisterCollector(registerer, &m.serverThrottled, m.serverThrottled)
+	mustRegisterCollector(registerer, &m.serverDrainDuration, m.serverDrainDuration)
+	mustRegisterCollector(registerer, &m.serverDrainForced, m.serverDrainForced)
+	mustRegisterCollector(registerer, &m.serverConnectionsReaped, m.serverConnectionsReaped)
+	mustRegisterCollector(registerer, &m.sessionCallDuration, m.sessionCallDuration)
+	mustRegisterCollector(registerer, &m.sessionInFlight, m.sessionInFlight)
+	mustRegisterCollector(registerer, &m.sessionOrphans, m.sessionOrphans)
+	mustRegisterCollector(registerer, &m.healthChecks, m.healthChecks)
+	mustRegisterCollector(registerer, &m.serverAcceptErrors, m.serverAcceptErrors)
+	mustRegisterCollector(registerer, &m.serverConnectionsLimit, m.serverConnectionsLimit)
+	mustRegisterCollector(registerer, &m.serverHandlerQueueWait, m.serverHandlerQueueWait)
+	mustRegisterCollector(registerer, &m.serverHandlerDuration, m.serverHandlerDuration)
 
 	return m
 }
@@ -9,6 +9,8 @@ import (
 type connectOptions struct {
 	readTimeout  time.Duration
 	writeTimeout time.Duration
+	codec        Codec
+	socket       *SocketOptions
 	onRead       func(int)
 	onWrite      func(int)
 }
@@ -25,6 +27,12 @@ func WithWriteTimeout(timeout time.Duration) opt.Option[connectOptions] {
 	})
 }
 
+func WithCodec(codec Codec) opt.Option[connectOptions] {
+	return opt.OptionFunc[connectOptions](func(o *connectOptions) {
+		o.codec = codec
+	})
+}
+
 func withReadObserver(observer func(int)) opt.Option[connectOptions] {
 	return opt.OptionFunc[connectOptions](func(o *connectOptions) {
 		o.onRead = observer
@@ -0,0 +1,433 @@
+package tcpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultPoolSize           = 8
+	defaultPoolDialBackoff    = 100 * time.Millisecond
+	defaultPoolMaxDialBackoff = 2 * time.Second
+	defaultPoolProbeTimeout   = time.Second
+)
+
+type Pool interface {
+	Borrow(context.Context) (Connect, error)
+	Return(Connect, error)
+	Execute(context.Context, func(context.Context, Connect) error) error
+	Stats() PoolStats
+	Close() error
+}
+
+type PoolConfig struct {
+	Addr                string
+	Client              Client
+	Size                int
+	MinIdle             int
+	DialRetries         int
+	DialBackoff         time.Duration
+	MaxDialBackoff      time.Duration
+	HealthCheck         func(context.Context, Connect) error
+	HealthCheckInterval time.Duration
+	HealthCheckTimeout  time.Duration
+	Logger              *logger.Logger
+	MetricsRegisterer   prometheus.Registerer
+	DisableMetrics      bool
+}
+
+type PoolStats struct {
+	Idle  int
+	InUse int
+	Total int
+}
+
+type poolEntity struct {
+	config  *PoolConfig
+	client  Client
+	metrics *metrics
+	label   string
+
+	slots chan struct{}
+	idle  chan *pooledConnect
+	inUse atomic.Int64
+
+	mu        sync.Mutex
+	closed    bool
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+type pooledConnect struct {
+	Connect
+	pool     *poolEntity
+	returned atomic.Bool
+}
+
+func NewPool(conf *PoolConfig) (Pool, error) {
+	if conf == nil {
+		return nil, ErrClientAddrRequired
+	}
+
+	cloned := *conf
+	if cloned.Client == nil {
+		if cloned.Addr == "" {
+			return nil, ErrClientAddrRequired
+		}
+		cloned.Client = NewClient(&ClientConfig{
+			Addr:              cloned.Addr,
+			Logger:            cloned.Logger,
+			MetricsRegisterer: cloned.MetricsRegisterer,
+			DisableMetrics:    cloned.DisableMetrics,
+		})
+	}
+	if cloned.Logger == nil {
+		cloned.Logger = logger.New(logger.WithLevel("info"))
+	}
+	if cloned.Size <= 0 {
+		cloned.Size = defaultPoolSize
+	}
+	if cloned.MinIdle < 0 {
+		cloned.MinIdle = 0
+	}
+	if cloned.MinIdle > cloned.Size {
+		cloned.MinIdle = cloned.Size
+	}
+	if cloned.DialRetries < 0 {
+		cloned.DialRetries = 0
+	}
+	if cloned.DialBackoff <= 0 {
+		cloned.DialBackoff = defaultPoolDialBackoff
+	}
+	if cloned.MaxDialBackoff <= 0 {
+		cloned.MaxDialBackoff = defaultPoolMaxDialBackoff
+	}
+	if cloned.HealthCheckTimeout <= 0 {
+		cloned.HealthCheckTimeout = defaultPoolProbeTimeout
+	}
+
+	var metrics *metrics
+	if !cloned.DisableMetrics {
+		metrics = defaultTCPMetrics()
+		if cloned.MetricsRegisterer != nil {
+			metrics = newTCPMetrics(cloned.MetricsRegisterer)
+		}
+	}
+
+	label := cloned.Addr
+	if label == "" {
+		label = "default"
+	}
+
+	p := &poolEntity{
+		config:  &cloned,
+		client:  cloned.Client,
+		metrics: metrics,
+		label:   label,
+		slots:   make(chan struct{}, cloned.Size),
+		idle:    make(chan *pooledConnect, cloned.Size),
+		closeCh: make(chan struct{}),
+	}
+
+	if cloned.HealthCheckInterval > 0 || cloned.MinIdle > 0 {
+		p.wg.Add(1)
+		go p.maintain()
+	}
+	return p, nil
+}
+
+func (p *poolEntity) Borrow(ctx context.Context) (Connect, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	conn, err := p.borrow(ctx)
+	if p.metrics != nil {
+		p.metrics.poolBorrowDuration.WithLabelValues(p.label, borrowResult(err)).Observe(time.Since(start).Seconds())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conn.returned.Store(false)
+	p.inUse.Add(1)
+	p.observeConnections()
+	return conn, nil
+}
+
+func (p *poolEntity) Return(conn Connect, err error) {
+	pooled, ok := conn.(*pooledConnect)
+	if !ok || pooled.pool != p || !pooled.returned.CompareAndSwap(false, true) {
+		return
+	}
+	p.inUse.Add(-1)
+
+	// Any error, a cancelled ctx included, may leave an unread reply or a
+	// partial frame on the connection, so it is never reused.
+	if err != nil {
+		p.discard(pooled)
+		return
+	}
+	if !p.putIdle(pooled) {
+		p.discard(pooled)
+	}
+}
+
+func (p *poolEntity) Execute(ctx context.Context, fn func(context.Context, Connect) error) (err error) {
+	if fn == nil {
+		return ErrNilHandler
+	}
+
+	conn, err := p.Borrow(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			p.Return(conn, fmt.Errorf("panic: %v", recovered))
+			panic(recovered)
+		}
+		p.Return(conn, err)
+	}()
+	return fn(ctx, conn)
+}
+
+func (p *poolEntity) Stats() PoolStats {
+	idle := len(p.idle)
+	inUse := int(p.inUse.Load())
+	return PoolStats{
+		Idle:  idle,
+		InUse: inUse,
+		Total: len(p.slots),
+	}
+}
+
+func (p *poolEntity) Close() error {
+	p.closeOnce.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		close(p.closeCh)
+		p.mu.Unlock()
+	})
+	p.wg.Wait()
+
+	var errs []error
+	for {
+		select {
+		case conn := <-p.idle:
+			if err := conn.Connect.Close(); err != nil {
+				errs = append(errs, err)
+			}
+			<-p.slots
+		default:
+			p.observeConnections()
+			return errors.Join(errs...)
+		}
+	}
+}
+
+func (p *poolEntity) borrow(ctx context.Context) (*pooledConnect, error) {
+	if p.isClosed() {
+		return nil, ErrPoolClosed
+	}
+
+	select {
+	case conn := <-p.idle:
+		return conn, nil
+	default:
+	}
+
+	select {
+	case conn := <-p.idle:
+		return conn, nil
+	case p.slots <- struct{}{}:
+		conn, err := p.dial(ctx)
+		if err != nil {
+			<-p.slots
+			return nil, err
+		}
+		return conn, nil
+	case <-p.closeCh:
+		return nil, ErrPoolClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *poolEntity) dial(ctx context.Context) (*pooledConnect, error) {
+	backoff := p.config.DialBackoff
+	var lastErr error
+	for attempt := 0; attempt <= p.config.DialRetries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, errors.Join(ctx.Err(), lastErr)
+			case <-p.closeCh:
+				timer.Stop()
+				return nil, ErrPoolClosed
+			case <-timer.C:
+			}
+			backoff *= 2
+			if backoff > p.config.MaxDialBackoff {
+				backoff = p.config.MaxDialBackoff
+			}
+		}
+
+		conn, err := p.client.DialContext(ctx)
+		if err == nil {
+			return &pooledConnect{Connect: conn, pool: p}, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("tcpx: pool dial: %w", lastErr)
+}
+
+func (p *poolEntity) putIdle(conn *pooledConnect) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false
+	}
+	select {
+	case p.idle <- conn:
+		p.observeConnections()
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *poolEntity) discard(conn *pooledConnect) {
+	_ = conn.Connect.Close()
+	<-p.slots
+	p.observeConnections()
+}
+
+func (p *poolEntity) maintain() {
+	defer p.wg.Done()
+
+	interval := p.config.HealthCheckInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	p.replenish()
+	for {
+		select {
+		case <-p.closeCh:
+			return
+		case <-ticker.C:
+			p.probeIdle()
+			p.replenish()
+		}
+	}
+}
+
+func (p *poolEntity) probeIdle() {
+	if p.config.HealthCheck == nil {
+		return
+	}
+
+	for i, n := 0, len(p.idle); i < n; i++ {
+		var conn *pooledConnect
+		select {
+		case conn = <-p.idle:
+		default:
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), p.config.HealthCheckTimeout)
+		err := p.config.HealthCheck(ctx, conn)
+		cancel()
+
+		result := "healthy"
+		if err != nil {
+			result = "unhealthy"
+			p.config.Logger.Warn(context.Background(), "tcp pool connection unhealthy", "addr", p.label, "error", err)
+			p.discard(conn)
+		} else if !p.putIdle(conn) {
+			p.discard(conn)
+		}
+		if p.metrics != nil {
+			p.metrics.poolHealthChecks.WithLabelValues(p.label, result).Inc()
+		}
+	}
+}
+
+func (p *poolEntity) replenish() {
+	for len(p.idle) < p.config.MinIdle && !p.isClosed() {
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), p.config.MaxDialBackoff)
+		conn, err := p.dial(ctx)
+		cancel()
+		if err != nil {
+			<-p.slots
+			p.config.Logger.Warn(context.Background(), "tcp pool replenish failed", "addr", p.label, "error", err)
+			return
+		}
+		if !p.putIdle(conn) {
+			p.discard(conn)
+			return
+		}
+	}
+}
+
+func (p *poolEntity) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+func (p *poolEntity) observeConnections() {
+	if p.metrics == nil {
+		return
+	}
+	stats := p.Stats()
+	p.metrics.poolConnections.WithLabelValues(p.label, "idle").Set(float64(stats.Idle))
+	p.metrics.poolConnections.WithLabelValues(p.label, "in_use").Set(float64(stats.InUse))
+}
+
+func (c *pooledConnect) Close() error {
+	if c.returned.CompareAndSwap(false, true) {
+		c.pool.inUse.Add(-1)
+		c.pool.discard(c)
+		return nil
+	}
+	return nil
+}
+
+func borrowResult(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrPoolClosed):
+		return "closed"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,284 @@
+package tcpx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/tcpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type pipeClient struct {
+	dials    atomic.Int32
+	failures atomic.Int32
+
+	mu    sync.Mutex
+	peers []net.Conn
+}
+
+func (c *pipeClient) Dial() (tcpx.Connect, error) {
+	return c.DialContext(context.Background())
+}
+
+func (c *pipeClient) DialContext(ctx context.Context) (tcpx.Connect, error) {
+	c.dials.Add(1)
+	if c.failures.Load() > 0 {
+		c.failures.Add(-1)
+		return nil, errors.New("dial refused")
+	}
+	clientConn, serverConn := net.Pipe()
+	c.mu.Lock()
+	c.peers = append(c.peers, serverConn)
+	c.mu.Unlock()
+	return tcpx.NewConnect(clientConn)
+}
+
+func (c *pipeClient) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, peer := range c.peers {
+		_ = peer.Close()
+	}
+}
+
+func newTestPool(t *testing.T, client *pipeClient, conf tcpx.PoolConfig) tcpx.Pool {
+	t.Helper()
+
+	conf.Client = client
+	conf.Addr = "pipe"
+	conf.MetricsRegisterer = prometheus.NewRegistry()
+	pool, err := tcpx.NewPool(&conf)
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = pool.Close()
+		client.close()
+	})
+	return pool
+}
+
+func TestPoolBorrowReusesReturnedConnection(t *testing.T) {
+	client := &pipeClient{}
+	pool := newTestPool(t, client, tcpx.PoolConfig{Size: 2})
+
+	first, err := pool.Borrow(context.Background())
+	if err != nil {
+		t.Fatalf("Borrow() error = %v", err)
+	}
+	if stats := pool.Stats(); stats.InUse != 1 || stats.Total != 1 {
+		t.Fatalf("stats after borrow = %+v", stats)
+	}
+	pool.Return(first, nil)
+
+	second, err := pool.Borrow(context.Background())
+	if err != nil {
+		t.Fatalf("Borrow() error = %v", err)
+	}
+	defer pool.Return(second, nil)
+
+	if got := client.dials.Load(); got != 1 {
+		t.Fatalf("dials = %d, want 1", got)
+	}
+}
+
+func TestPoolBorrowBlocksWhenExhausted(t *testing.T) {
+	client := &pipeClient{}
+	pool := newTestPool(t, client, tcpx.PoolConfig{Size: 1})
+
+	conn, err := pool.Borrow(context.Background())
+	if err != nil {
+		t.Fatalf("Borrow() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Borrow(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Borrow() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		conn, err := pool.Borrow(context.Background())
+		if err == nil {
+			pool.Return(conn, nil)
+		}
+		done <- err
+	}()
+	pool.Return(conn, nil)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("waiting Borrow() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting Borrow() did not receive returned connection")
+	}
+}
+
+func TestPoolExecuteDiscardsFailedConnection(t *testing.T) {
+	client := &pipeClient{}
+	pool := newTestPool(t, client, tcpx.PoolConfig{Size: 1})
+
+	wantErr := errors.New("broken pipe")
+	err := pool.Execute(context.Background(), func(context.Context, tcpx.Connect) error {
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Execute() error = %v, want %v", err, wantErr)
+	}
+	if stats := pool.Stats(); stats.Total != 0 || stats.Idle != 0 {
+		t.Fatalf("stats after failed execute = %+v", stats)
+	}
+
+	if err := pool.Execute(context.Background(), func(context.Context, tcpx.Connect) error { return nil }); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := client.dials.Load(); got != 2 {
+		t.Fatalf("dials = %d, want 2", got)
+	}
+	if stats := pool.Stats(); stats.Idle != 1 {
+		t.Fatalf("stats after successful execute = %+v", stats)
+	}
+}
+
+func TestPoolDiscardsConnectionReturnedAfterCancel(t *testing.T) {
+	client := &pipeClient{}
+	pool := newTestPool(t, client, tcpx.PoolConfig{Size: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := pool.Execute(context.Background(), func(context.Context, tcpx.Connect) error {
+		// The request went out but the caller gave up before the reply.
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Execute() error = %v, want %v", err, context.Canceled)
+	}
+	if stats := pool.Stats(); stats.Total != 0 || stats.Idle != 0 {
+		t.Fatalf("stats after cancelled execute = %+v", stats)
+	}
+
+	conn, err := pool.Borrow(context.Background())
+	if err != nil {
+		t.Fatalf("Borrow() error = %v", err)
+	}
+	pool.Return(conn, context.DeadlineExceeded)
+	if stats := pool.Stats(); stats.Total != 0 || stats.Idle != 0 {
+		t.Fatalf("stats after timed out return = %+v", stats)
+	}
+	if got := client.dials.Load(); got != 2 {
+		t.Fatalf("dials = %d, want 2", got)
+	}
+}
+
+func TestPoolRetriesDialWithBackoff(t *testing.T) {
+	client := &pipeClient{}
+	client.failures.Store(2)
+	pool := newTestPool(t, client, tcpx.PoolConfig{
+		Size:        1,
+		DialRetries: 2,
+		DialBackoff: time.Millisecond,
+	})
+
+	conn, err := pool.Borrow(context.Background())
+	if err != nil {
+		t.Fatalf("Borrow() error = %v", err)
+	}
+	pool.Return(conn, nil)
+	if got := client.dials.Load(); got != 3 {
+		t.Fatalf("dials = %d, want 3", got)
+	}
+
+	client.failures.Store(5)
+	exhausted := newTestPool(t, client, tcpx.PoolConfig{Size: 1, DialRetries: 1, DialBackoff: time.Millisecond})
+	if _, err := exhausted.Borrow(context.Background()); err == nil {
+		t.Fatal("Borrow() error = nil, want dial failure")
+	}
+	if stats := exhausted.Stats(); stats.Total != 0 {
+		t.Fatalf("stats after dial failure = %+v", stats)
+	}
+}
+
+func TestPoolHealthCheckReplacesUnhealthyConnections(t *testing.T) {
+	client := &pipeClient{}
+	var healthy atomic.Bool
+	var probes atomic.Int32
+	pool := newTestPool(t, client, tcpx.PoolConfig{
+		Size:                2,
+		MinIdle:             2,
+		HealthCheckInterval: 10 * time.Millisecond,
+		HealthCheck: func(context.Context, tcpx.Connect) error {
+			probes.Add(1)
+			if healthy.Load() {
+				return nil
+			}
+			return errors.New("unhealthy")
+		},
+	})
+
+	waitFor(t, func() bool { return probes.Load() >= 2 })
+	healthy.Store(true)
+	if dials := client.dials.Load(); dials < 3 {
+		t.Fatalf("dials = %d, want replacement dials after failed probes", dials)
+	}
+
+	waitFor(t, func() bool { return pool.Stats().Idle == 2 })
+}
+
+func TestPoolCloseRejectsBorrowAndClosesReturned(t *testing.T) {
+	client := &pipeClient{}
+	pool := newTestPool(t, client, tcpx.PoolConfig{Size: 1})
+
+	conn, err := pool.Borrow(context.Background())
+	if err != nil {
+		t.Fatalf("Borrow() error = %v", err)
+	}
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := pool.Borrow(context.Background()); !errors.Is(err, tcpx.ErrPoolClosed) {
+		t.Fatalf("Borrow() error = %v, want %v", err, tcpx.ErrPoolClosed)
+	}
+
+	pool.Return(conn, nil)
+	if stats := pool.Stats(); stats.Total != 0 || stats.InUse != 0 {
+		t.Fatalf("stats after close = %+v", stats)
+	}
+}
+
+func TestPoolValidation(t *testing.T) {
+	if _, err := tcpx.NewPool(nil); !errors.Is(err, tcpx.ErrClientAddrRequired) {
+		t.Fatalf("NewPool(nil) error = %v, want %v", err, tcpx.ErrClientAddrRequired)
+	}
+
+	client := &pipeClient{}
+	pool := newTestPool(t, client, tcpx.PoolConfig{})
+
+	var ctx context.Context
+	if _, err := pool.Borrow(ctx); !errors.Is(err, tcpx.ErrContextRequired) {
+		t.Fatalf("Borrow(nil) error = %v, want %v", err, tcpx.ErrContextRequired)
+	}
+	if err := pool.Execute(context.Background(), nil); !errors.Is(err, tcpx.ErrNilHandler) {
+		t.Fatalf("Execute(nil) error = %v, want %v", err, tcpx.ErrNilHandler)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("condition not met before deadline")
+}
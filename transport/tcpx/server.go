@@ -37,17 +37,46 @@ type Server interface {
 	Close() error
 	Listener() net.Listener
 	Use(...Interceptor) error
+	// Ready reports nil while the server is serving, not draining, and the
+	// configured health check passes.
+	Ready(context.Context) error
+	Stats() ServerStats
+}
+
+type ServerStats struct {
+	Active         int
+	MaxConnections int
+	Draining       bool
 }
 
 type ServerConfig struct {
-	Addr              string
-	Listener          net.Listener
-	ReadTimeout       time.Duration
-	WriteTimeout      time.Duration
-	KeepAlivePeriod   time.Duration
-	ShutdownTimeout   time.Duration
+	Addr         string
+	Listener     net.Listener
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// IdleTimeout closes connections with no reads or writes for this long.
+	IdleTimeout     time.Duration
+	KeepAlivePeriod time.Duration
+	// Socket tunes accepted connections; keepalive idle/interval default to
+	// KeepAlivePeriod.
+	Socket SocketOptions
+	// ReusePort listens with SO_REUSEPORT. Listeners > 1 opens that many
+	// sockets on Addr and accepts from all of them in parallel.
+	ReusePort       bool
+	Listeners       int
+	ShutdownTimeout time.Duration
+	// DrainConnections makes Shutdown wait for in-flight connections until its
+	// deadline instead of closing them immediately; the rest are force-closed.
+	DrainConnections bool
+	// DrainNotify cancels handler contexts when a drain starts, so handlers can
+	// finish their current exchange and return early.
+	DrainNotify       bool
 	MaxConnections    int
+	Health            *HealthConfig
 	TLSConfig         *tls.Config
+	TLS               *ServerTLSConfig
+	HandshakeTimeout  time.Duration
+	Codec             Codec
 	Logger            *logger.Logger
 	EnableLogger      bool
 	Trace             bool
@@ -58,14 +87,16 @@ type ServerConfig struct {
 type serverEntity struct {
 	config *ServerConfig
 
-	mu           sync.RWMutex
-	listener     net.Listener
-	running      bool
-	serveCancel  context.CancelCauseFunc
-	connections  map[Connect]struct{}
-	interceptors []Interceptor
-	wg           sync.WaitGroup
-	metrics      *metrics
+	mu             sync.RWMutex
+	listener       net.Listener
+	healthListener net.Listener
+	running        bool
+	draining       bool
+	serveCancel    context.CancelCauseFunc
+	connections    map[Connect]*connectionState
+	interceptors   []Interceptor
+	wg             sync.WaitGroup
+	metrics        *metrics
 }
 
 func NewServer(conf *ServerConfig) Server {
@@ -81,6 +112,9 @@ func NewServer(conf *ServerConfig) Server {
 	if conf.ShutdownTimeout == 0 {
 		conf.ShutdownTimeout = defaultServerShutdownTime
 	}
+	if conf.HandshakeTimeout == 0 {
+		conf.HandshakeTimeout = defaultServerHandshake
+	}
 	if conf.MaxConnections < 0 {
 		conf.MaxConnections = 0
 	}
@@ -95,7 +129,7 @@ func NewServer(conf *ServerConfig) Server {
 
 	return &serverEntity{
 		config:      conf,
-		connections: make(map[Connect]struct{}),
+		connections: make(map[Connect]*connectionState),
 		metrics:     metrics,
 	}
 }
@@ -133,7 +167,7 @@ func (s *serverEntity) Start(ctx context.Context, handler Handler) error {
 		return ErrServerAddrRequired
 	}
 
-	listener, err := net.Listen("tcp", s.config.Addr)
+	listener, err := s.listen(ctx)
 	if err != nil {
 		return fmt.Errorf("tcpx: listen: %w", err)
 	}
@@ -157,33 +191,52 @@ func (s *serverEntity) Serve(ctx context.Context, listener net.Listener, handler
 	if handler == nil {
 		return ErrNilHandler
 	}
+	tlsConfig, err := resolveServerTLSConfig(s.config)
+	if err != nil {
+		return err
+	}
+
+	healthListener, err := s.listenHealth()
+	if err != nil {
+		return err
+	}
 
 	s.mu.Lock()
 	if s.running {
 		s.mu.Unlock()
+		if healthListener != nil && healthListener != s.config.Health.Listener {
+			_ = healthListener.Close()
+		}
 		return ErrServerAlreadyRunning
 	}
 
 	serveCtx, serveCancel := context.WithCancelCause(ctx)
 	activeListener := listener
-	if s.config.TLSConfig != nil {
-		activeListener = tls.NewListener(listener, s.config.TLSConfig.Clone())
+	if tlsConfig != nil {
+		activeListener = tls.NewListener(listener, tlsConfig)
 	}
 
 	s.listener = activeListener
+	s.healthListener = healthListener
 	s.serveCancel = serveCancel
 	s.running = true
-	s.connections = make(map[Connect]struct{})
+	s.draining = false
+	s.connections = make(map[Connect]*connectionState)
 	interceptors := append([]Interceptor(nil), s.interceptors...)
 	s.mu.Unlock()
 
 	defer func() {
 		serveCancel(errServerClosed)
 		s.mu.Lock()
+		if s.healthListener != nil {
+			_ = s.healthListener.Close()
+		}
 		s.listener = nil
+		s.healthListener = nil
 		s.serveCancel = nil
 		s.running = false
-		s.connections = make(map[Connect]struct{})
+		s.draining = false
+		s.connections = make(map[Connect]*connectionState)
 		s.mu.Unlock()
 	}()
 
@@ -195,6 +248,16 @@ func (s *serverEntity) Serve(ctx context.Context, listener net.Listener, handler
 	s.info(ctx, "tcp server starting", "addr", activeListener.Addr().String())
 
 	addrLabel := activeListener.Addr().String()
+	if s.metrics != nil {
+		s.metrics.serverConnectionsLimit.WithLabelValues(addrLabel).Set(float64(s.config.MaxConnections))
+	}
+	if s.config.IdleTimeout > 0 {
+		go s.reapIdleConnections(serveCtx, addrLabel)
+	}
+	if healthListener != nil {
+		s.info(ctx, "tcp health listener starting", "addr", healthListener.Addr().String())
+		go s.serveHealth(serveCtx, healthListener)
+	}
 	tracer := otel.Tracer("micro/tcpx")
 	var tempDelay time.Duration
 
@@ -215,6 +278,7 @@ func (s *serverEntity) Serve(ctx context.Context, listener net.Listener, handler
 				if tempDelay > time.Second {
 					tempDelay = time.Second
 				}
+				s.observeAcceptError(addrLabel, "temporary")
 				s.config.Logger.Warn(ctx, "tcp accept temporary failure", "error", err, "retry_in", tempDelay)
 				time.Sleep(tempDelay)
 				continue
@@ -226,22 +290,28 @@ func (s *serverEntity) Serve(ctx context.Context, listener net.Listener, handler
 			return acceptErr
 		}
 		tempDelay = 0
+		acceptedAt := time.Now()
 
 		if err := s.configureSocket(rawConn); err != nil {
+			s.observeAcceptError(addrLabel, "socket")
 			s.config.Logger.Warn(ctx, "tcp socket configuration failed", "error", err, "remote_addr", rawConn.RemoteAddr().String())
 			_ = rawConn.Close()
 			continue
 		}
 
+		state := newConnectionState()
 		conn, err := NewConnect(rawConn,
 			WithReadTimeout(s.config.ReadTimeout),
 			WithWriteTimeout(s.config.WriteTimeout),
+			WithCodec(s.config.Codec),
 			withReadObserver(func(n int) {
+				state.addRead(n)
 				if s.metrics != nil {
 					s.metrics.serverBytesRead.WithLabelValues(addrLabel).Add(float64(n))
 				}
 			}),
 			withWriteObserver(func(n int) {
+				state.addWritten(n)
 				if s.metrics != nil {
 					s.metrics.serverBytesWritten.WithLabelValues(addrLabel).Add(float64(n))
 				}
@@ -252,7 +322,7 @@ func (s *serverEntity) Serve(ctx context.Context, listener net.Listener, handler
 			return err
 		}
 
-		if err := s.registerConnection(serveCtx, conn, addrLabel); err != nil {
+		if err := s.registerConnection(serveCtx, conn, state, addrLabel); err != nil {
 			_ = conn.Close()
 			reason := "server_closed"
 			if errors.Is(err, errMaxConnectionsReached) {
@@ -268,7 +338,7 @@ func (s *serverEntity) Serve(ctx context.Context, listener net.Listener, handler
 		}
 
 		s.wg.Add(1)
-		go s.handleConnection(serveCtx, tracer, addrLabel, finalHandler, conn)
+		go s.handleConnection(serveCtx, tracer, addrLabel, finalHandler, conn, state, acceptedAt)
 	}
 }
 
@@ -288,19 +358,25 @@ func (s *serverEntity) Shutdown(ctx context.Context) error {
 	if listener == nil && cancel == nil {
 		return nil
 	}
+	s.beginDrain()
 
-	s.info(ctx, "tcp server shutting down")
+	s.info(ctx, "tcp server shutting down", "drain", s.config.DrainConnections, "connections", len(connections))
 
-	if cancel != nil {
-		cancel(errServerClosed)
-	}
+	addrLabel := ""
 	if listener != nil {
+		addrLabel = listener.Addr().String()
 		_ = listener.Close()
 	}
-	for _, conn := range connections {
-		_ = conn.Close()
+	if cancel != nil && (!s.config.DrainConnections || s.config.DrainNotify) {
+		cancel(errServerClosed)
+	}
+	if !s.config.DrainConnections {
+		for _, conn := range connections {
+			_ = conn.Close()
+		}
 	}
 
+	start := time.Now()
 	done := make(chan struct{})
 	go func() {
 		s.wg.Wait()
@@ -309,8 +385,14 @@ func (s *serverEntity) Shutdown(ctx context.Context) error {
 
 	select {
 	case <-done:
+		s.observeDrain(addrLabel, "completed", time.Since(start), 0)
 		return nil
 	case <-ctx.Done():
+		_, _, remaining := s.snapshotState()
+		s.observeDrain(addrLabel, "forced", time.Since(start), len(remaining))
+		if s.config.DrainConnections {
+			s.config.Logger.Warn(ctx, "tcp server drain deadline exceeded", "remaining", len(remaining))
+		}
 		closeErr := s.Close()
 		if closeErr != nil {
 			return errors.Join(ctx.Err(), closeErr)
@@ -319,12 +401,33 @@ func (s *serverEntity) Shutdown(ctx context.Context) error {
 	}
 }
 
+func (s *serverEntity) beginDrain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.draining = true
+	if s.healthListener != nil {
+		_ = s.healthListener.Close()
+	}
+}
+
+func (s *serverEntity) observeDrain(addrLabel string, result string, duration time.Duration, forced int) {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.serverDrainDuration.WithLabelValues(addrLabel, result).Observe(duration.Seconds())
+	if forced > 0 {
+		s.metrics.serverDrainForced.WithLabelValues(addrLabel).Add(float64(forced))
+	}
+}
+
 func (s *serverEntity) Close() error {
 	listener, cancel, connections := s.snapshotState()
 
 	if cancel != nil {
 		cancel(errServerClosed)
 	}
+	s.beginDrain()
 
 	var errs []error
 	if listener != nil {
@@ -346,7 +449,24 @@ func (s *serverEntity) Listener() net.Listener {
 	return s.listener
 }
 
-func (s *serverEntity) registerConnection(ctx context.Context, conn Connect, addrLabel string) error {
+func (s *serverEntity) Stats() ServerStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return ServerStats{
+		Active:         len(s.connections),
+		MaxConnections: s.config.MaxConnections,
+		Draining:       s.draining,
+	}
+}
+
+func (s *serverEntity) observeAcceptError(addrLabel string, reason string) {
+	if s.metrics != nil {
+		s.metrics.serverAcceptErrors.WithLabelValues(addrLabel, reason).Inc()
+	}
+}
+
+func (s *serverEntity) registerConnection(ctx context.Context, conn Connect, state *connectionState, addrLabel string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -357,7 +477,7 @@ func (s *serverEntity) registerConnection(ctx context.Context, conn Connect, add
 		return errMaxConnectionsReached
 	}
 
-	s.connections[conn] = struct{}{}
+	s.connections[conn] = state
 	if s.metrics != nil {
 		s.metrics.serverConnectionsActive.WithLabelValues(addrLabel).Inc()
 		s.metrics.serverConnectionsAccepted.WithLabelValues(addrLabel).Inc()
@@ -404,6 +524,8 @@ func (s *serverEntity) handleConnection(
 	addrLabel string,
 	handler Handler,
 	conn Connect,
+	state *connectionState,
+	acceptedAt time.Time,
 ) {
 	defer s.wg.Done()
 	defer s.unregisterConnection(conn, addrLabel)
@@ -435,8 +557,9 @@ func (s *serverEntity) handleConnection(
 
 	start := time.Now()
 	var (
-		handleErr error
-		panicked  bool
+		handleErr    error
+		panicked     bool
+		handlerStart time.Time
 	)
 
 	defer func() {
@@ -459,9 +582,19 @@ func (s *serverEntity) handleConnection(
 
 		result := classifyConnectionResult(ctx, handleErr, panicked)
 		duration := time.Since(start)
+		bytesRead, bytesWritten := state.bytesRead.Load(), state.bytesWritten.Load()
+		if span != nil {
+			span.SetAttributes(
+				attribute.Int64("tcpx.bytes_read", bytesRead),
+				attribute.Int64("tcpx.bytes_written", bytesWritten),
+			)
+		}
 		if s.metrics != nil {
 			s.metrics.serverConnectionsClosed.WithLabelValues(addrLabel, result).Inc()
 			s.metrics.serverConnectionDuration.WithLabelValues(addrLabel, result).Observe(duration.Seconds())
+			if !handlerStart.IsZero() {
+				s.metrics.serverHandlerDuration.WithLabelValues(addrLabel, result).Observe(time.Since(handlerStart).Seconds())
+			}
 		}
 
 		switch result {
@@ -474,33 +607,42 @@ func (s *serverEntity) handleConnection(
 				"local_addr", localAddr,
 				"remote_addr", remoteAddr,
 				"duration", duration,
+				"bytes_read", bytesRead,
+				"bytes_written", bytesWritten,
 				"error", handleErr,
 			)
 		case "panic":
-			s.info(connCtx, "tcp connection closed", "local_addr", localAddr, "remote_addr", remoteAddr, "duration", duration, "result", result)
+			s.info(connCtx, "tcp connection closed", "local_addr", localAddr, "remote_addr", remoteAddr, "duration", duration, "bytes_read", bytesRead, "bytes_written", bytesWritten, "result", result)
 		default:
-			s.info(connCtx, "tcp connection closed", "local_addr", localAddr, "remote_addr", remoteAddr, "duration", duration, "result", result)
+			s.info(connCtx, "tcp connection closed", "local_addr", localAddr, "remote_addr", remoteAddr, "duration", duration, "bytes_read", bytesRead, "bytes_written", bytesWritten, "result", result)
 		}
 	}()
 
-	handleErr = handler.Handle(connCtx, conn)
-}
-
-func (s *serverEntity) configureSocket(conn net.Conn) error {
-	tcpConn, ok := conn.(*net.TCPConn)
-	if !ok {
-		return nil
+	connCtx, handleErr = s.handshake(connCtx, conn)
+	if handleErr != nil {
+		return
 	}
-	if s.config.KeepAlivePeriod <= 0 {
-		return nil
+	if span != nil {
+		if peer, ok := PeerCertificate(connCtx); ok {
+			span.SetAttributes(attribute.String("tls.client.subject", peer.Subject.String()))
+		}
 	}
-	if err := tcpConn.SetKeepAlive(true); err != nil {
-		return err
+	handlerStart = time.Now()
+	if s.metrics != nil {
+		s.metrics.serverHandlerQueueWait.WithLabelValues(addrLabel).Observe(handlerStart.Sub(acceptedAt).Seconds())
 	}
-	if err := tcpConn.SetKeepAlivePeriod(s.config.KeepAlivePeriod); err != nil {
-		return err
+	handleErr = handler.Handle(connCtx, conn)
+}
+
+func (s *serverEntity) listen(ctx context.Context) (net.Listener, error) {
+	if !s.config.ReusePort {
+		return net.Listen("tcp", s.config.Addr)
 	}
-	return nil
+	return listenReusePort(ctx, s.config.Addr, s.config.Listeners)
+}
+
+func (s *serverEntity) configureSocket(conn net.Conn) error {
+	return applySocketOptions(conn, s.config.Socket.withKeepAlivePeriod(s.config.KeepAlivePeriod))
 }
 
 func (s *serverEntity) watchContext(ctx context.Context, done <-chan struct{}) {
@@ -384,6 +384,128 @@ func TestServerMetricsRegistererAndDisableMetrics(t *testing.T) {
 	))
 }
 
+func TestServerAcceptLoopMetricsAndStats(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	server, listener, errCh := startDrainServer(t, &tcpx.ServerConfig{
+		MaxConnections:    4,
+		MetricsRegisterer: reg,
+	}, func(ctx context.Context, conn tcpx.Connect) error {
+		entered <- struct{}{}
+		<-release
+		return nil
+	})
+
+	conn, err := listener.Dial()
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	<-entered
+
+	if stats := server.Stats(); stats.Active != 1 || stats.MaxConnections != 4 || stats.Draining {
+		t.Fatalf("Stats() = %+v, want 1 active of 4", stats)
+	}
+	if got := gatheredGauge(t, reg, "tcpx_server_connections_limit"); got != 4 {
+		t.Fatalf("tcpx_server_connections_limit = %v, want 4", got)
+	}
+	if got := gatheredHistogramCount(t, reg, "tcpx_server_handler_queue_wait_seconds"); got != 1 {
+		t.Fatalf("tcpx_server_handler_queue_wait_seconds count = %d, want 1", got)
+	}
+
+	close(release)
+	waitFor(t, func() bool {
+		return server.Stats().Active == 0
+	})
+	if got := gatheredHistogramCount(t, reg, "tcpx_server_handler_duration_seconds"); got != 1 {
+		t.Fatalf("tcpx_server_handler_duration_seconds count = %d, want 1", got)
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
+
+func TestServerCountsBytesPerAddrAndConnection(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	var logs bytes.Buffer
+
+	server, listener, errCh := startDrainServer(t, &tcpx.ServerConfig{
+		MetricsRegisterer: reg,
+		EnableLogger:      true,
+		Logger: logger.New(
+			logger.WithFormat("text"),
+			logger.WithAddSource(false),
+			logger.WithOutput(&syncWriter{w: &logs}),
+		),
+	}, func(ctx context.Context, conn tcpx.Connect) error {
+		buf := make([]byte, 4)
+		if err := conn.ReadFull(buf); err != nil {
+			return err
+		}
+		return conn.WriteFull([]byte("pong!!"))
+	})
+
+	if _, err := doPipeExchange(listener, []byte("ping"), 6); err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	waitFor(t, func() bool {
+		return server.Stats().Active == 0
+	})
+
+	if got := gatheredCounter(t, reg, "tcpx_server_bytes_read_total"); got != 4 {
+		t.Fatalf("tcpx_server_bytes_read_total = %v, want 4", got)
+	}
+	if got := gatheredCounter(t, reg, "tcpx_server_bytes_written_total"); got != 6 {
+		t.Fatalf("tcpx_server_bytes_written_total = %v, want 6", got)
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if out := logs.String(); !strings.Contains(out, "bytes_read=4") || !strings.Contains(out, "bytes_written=6") {
+		t.Fatalf("connection close log missing byte totals: %s", out)
+	}
+}
+
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+func gatheredGauge(t *testing.T, reg *prometheus.Registry, name string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	total := 0.0
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			total += metric.GetGauge().GetValue()
+		}
+	}
+	return total
+}
+
 func waitForServer(t *testing.T, server tcpx.Server) {
 	t.Helper()
 
@@ -0,0 +1,329 @@
+package tcpx
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const correlationIDSize = 8
+
+// Correlator attaches a correlation ID to outgoing payloads and extracts it
+// from incoming frames. Peers must echo the ID in their responses.
+type Correlator interface {
+	Encode(id uint64, payload []byte) ([]byte, error)
+	Decode(frame []byte) (id uint64, payload []byte, err error)
+}
+
+// PrefixCorrelator carries the ID as an 8-byte big-endian prefix of every
+// frame.
+type PrefixCorrelator struct{}
+
+func (PrefixCorrelator) Encode(id uint64, payload []byte) ([]byte, error) {
+	frame := make([]byte, correlationIDSize+len(payload))
+	binary.BigEndian.PutUint64(frame, id)
+	copy(frame[correlationIDSize:], payload)
+	return frame, nil
+}
+
+func (PrefixCorrelator) Decode(frame []byte) (uint64, []byte, error) {
+	if len(frame) < correlationIDSize {
+		return 0, nil, ErrInvalidCorrelationFrame
+	}
+	return binary.BigEndian.Uint64(frame), frame[correlationIDSize:], nil
+}
+
+// Session multiplexes concurrent request/response calls over one codec
+// connection, matching responses to callers by correlation ID.
+type Session interface {
+	Call(context.Context, []byte) ([]byte, error)
+	InFlight() int
+	Done() <-chan struct{}
+	Err() error
+	Close() error
+}
+
+type SessionConfig struct {
+	Correlator Correlator
+	// CallTimeout bounds calls whose context has no deadline. Zero disables it.
+	CallTimeout time.Duration
+	// MaxInFlight caps concurrent calls; Call waits for a slot. Zero is
+	// unlimited.
+	MaxInFlight int
+	// OnOrphan receives frames whose ID matches no pending call, typically
+	// late responses to calls that already timed out.
+	OnOrphan          func(id uint64, payload []byte)
+	Logger            *logger.Logger
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+}
+
+type sessionEntity struct {
+	conn    Connect
+	config  *SessionConfig
+	metrics *metrics
+	label   string
+
+	nextID  atomic.Uint64
+	slots   chan struct{}
+	mu      sync.Mutex
+	pending map[uint64]chan []byte
+
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// NewSession starts reading frames from conn, which must have a Codec. The
+// session owns conn and closes it on Close or on the first read error.
+func NewSession(conn Connect, conf *SessionConfig) (Session, error) {
+	if conn == nil {
+		return nil, ErrNilConn
+	}
+	if conf == nil {
+		conf = &SessionConfig{}
+	}
+
+	cloned := *conf
+	if cloned.Correlator == nil {
+		cloned.Correlator = PrefixCorrelator{}
+	}
+	if cloned.Logger == nil {
+		cloned.Logger = logger.New(logger.WithLevel("info"))
+	}
+	if cloned.MaxInFlight < 0 {
+		cloned.MaxInFlight = 0
+	}
+
+	var metrics *metrics
+	if !cloned.DisableMetrics {
+		metrics = defaultTCPMetrics()
+		if cloned.MetricsRegisterer != nil {
+			metrics = newTCPMetrics(cloned.MetricsRegisterer)
+		}
+	}
+
+	label := "unknown"
+	if addr := conn.RemoteAddr(); addr != nil {
+		label = addr.String()
+	}
+
+	s := &sessionEntity{
+		conn:    conn,
+		config:  &cloned,
+		metrics: metrics,
+		label:   label,
+		pending: make(map[uint64]chan []byte),
+		done:    make(chan struct{}),
+	}
+	if cloned.MaxInFlight > 0 {
+		s.slots = make(chan struct{}, cloned.MaxInFlight)
+	}
+	go s.readLoop()
+	return s, nil
+}
+
+func (s *sessionEntity) Call(ctx context.Context, payload []byte) (resp []byte, err error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if s.config.CallTimeout > 0 {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, s.config.CallTimeout)
+			defer cancel()
+		}
+	}
+
+	start := time.Now()
+	defer func() {
+		s.observeCall(callResult(err), time.Since(start))
+	}()
+
+	if err := s.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer s.release()
+
+	id := s.nextID.Add(1)
+	reply := make(chan []byte, 1)
+	if err := s.register(id, reply); err != nil {
+		return nil, err
+	}
+	defer s.unregister(id)
+
+	frame, err := s.config.Correlator.Encode(id, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.conn.WriteFrame(frame); err != nil {
+		s.fail(err)
+		return nil, err
+	}
+
+	select {
+	case resp := <-reply:
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.done:
+		return nil, s.Err()
+	}
+}
+
+func (s *sessionEntity) InFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+func (s *sessionEntity) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *sessionEntity) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+func (s *sessionEntity) Close() error {
+	s.fail(ErrSessionClosed)
+	return nil
+}
+
+func (s *sessionEntity) acquire(ctx context.Context) error {
+	if s.slots == nil {
+		return nil
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.done:
+		return s.Err()
+	}
+}
+
+func (s *sessionEntity) release() {
+	if s.slots != nil {
+		<-s.slots
+	}
+}
+
+func (s *sessionEntity) register(id uint64, reply chan []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return s.err
+	default:
+	}
+	s.pending[id] = reply
+	s.setInFlight(len(s.pending))
+	return nil
+}
+
+func (s *sessionEntity) unregister(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.pending, id)
+	s.setInFlight(len(s.pending))
+}
+
+func (s *sessionEntity) readLoop() {
+	for {
+		frame, err := s.conn.ReadFrame()
+		if err != nil {
+			s.fail(err)
+			return
+		}
+
+		id, payload, err := s.config.Correlator.Decode(frame)
+		if err != nil {
+			s.fail(err)
+			return
+		}
+
+		s.mu.Lock()
+		reply, ok := s.pending[id]
+		if ok {
+			delete(s.pending, id)
+			s.setInFlight(len(s.pending))
+		}
+		s.mu.Unlock()
+
+		if ok {
+			reply <- payload
+			continue
+		}
+		s.orphan(id, payload)
+	}
+}
+
+func (s *sessionEntity) orphan(id uint64, payload []byte) {
+	if s.metrics != nil {
+		s.metrics.sessionOrphans.WithLabelValues(s.label).Inc()
+	}
+	if s.config.OnOrphan != nil {
+		s.config.OnOrphan(id, payload)
+		return
+	}
+	s.config.Logger.Warn(context.Background(), "tcp session orphaned response", "remote_addr", s.label, "id", id)
+}
+
+// fail closes the session with err; the first error wins. The stored error
+// always wraps ErrSessionClosed so callers can test for it.
+func (s *sessionEntity) fail(err error) {
+	s.closeOnce.Do(func() {
+		if !errors.Is(err, ErrSessionClosed) {
+			err = fmt.Errorf("%w: %w", ErrSessionClosed, err)
+		}
+		s.mu.Lock()
+		s.err = err
+		close(s.done)
+		s.mu.Unlock()
+		_ = s.conn.Close()
+	})
+}
+
+func (s *sessionEntity) setInFlight(n int) {
+	if s.metrics != nil {
+		s.metrics.sessionInFlight.WithLabelValues(s.label).Set(float64(n))
+	}
+}
+
+func (s *sessionEntity) observeCall(result string, duration time.Duration) {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.sessionCallDuration.WithLabelValues(s.label, result).Observe(duration.Seconds())
+}
+
+func callResult(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, ErrSessionClosed):
+		return "closed"
+	default:
+		return "error"
+	}
+}
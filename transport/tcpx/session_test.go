@@ -0,0 +1,211 @@
+package tcpx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/tcpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newSessionPair(t *testing.T, conf *tcpx.SessionConfig) (tcpx.Session, tcpx.Connect) {
+	t.Helper()
+
+	clientRaw, serverRaw := net.Pipe()
+	codec := tcpx.LengthFieldCodec{}
+	clientConn, err := tcpx.NewConnect(clientRaw, tcpx.WithCodec(codec))
+	if err != nil {
+		t.Fatalf("NewConnect(client) error = %v", err)
+	}
+	serverConn, err := tcpx.NewConnect(serverRaw, tcpx.WithCodec(codec))
+	if err != nil {
+		t.Fatalf("NewConnect(server) error = %v", err)
+	}
+	t.Cleanup(func() { _ = serverConn.Close() })
+
+	session, err := tcpx.NewSession(clientConn, conf)
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session, serverConn
+}
+
+func readRequest(t *testing.T, conn tcpx.Connect) (uint64, []byte) {
+	t.Helper()
+
+	frame, err := conn.ReadFrame()
+	if err != nil {
+		t.Errorf("server ReadFrame() error = %v", err)
+		return 0, nil
+	}
+	id, payload, err := tcpx.PrefixCorrelator{}.Decode(frame)
+	if err != nil {
+		t.Errorf("Decode() error = %v", err)
+	}
+	return id, payload
+}
+
+func writeResponse(t *testing.T, conn tcpx.Connect, id uint64, payload []byte) {
+	t.Helper()
+
+	frame, _ := tcpx.PrefixCorrelator{}.Encode(id, payload)
+	if err := conn.WriteFrame(frame); err != nil {
+		t.Errorf("server WriteFrame() error = %v", err)
+	}
+}
+
+func TestSessionMatchesOutOfOrderResponses(t *testing.T) {
+	session, server := newSessionPair(t, &tcpx.SessionConfig{DisableMetrics: true})
+
+	const calls = 8
+	go func() {
+		type request struct {
+			id      uint64
+			payload []byte
+		}
+		requests := make([]request, 0, calls)
+		for range calls {
+			id, payload := readRequest(t, server)
+			requests = append(requests, request{id, payload})
+		}
+		for i := len(requests) - 1; i >= 0; i-- {
+			writeResponse(t, server, requests[i].id, append([]byte("re:"), requests[i].payload...))
+		}
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+	for i := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			payload := fmt.Sprintf("call-%d", i)
+			resp, err := session.Call(context.Background(), []byte(payload))
+			if err != nil {
+				errs <- err
+				return
+			}
+			if string(resp) != "re:"+payload {
+				errs <- fmt.Errorf("response = %q, want %q", resp, "re:"+payload)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if got := session.InFlight(); got != 0 {
+		t.Fatalf("InFlight() = %d, want 0", got)
+	}
+}
+
+func TestSessionCallTimeoutAndOrphanedResponse(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	orphans := make(chan uint64, 1)
+	session, server := newSessionPair(t, &tcpx.SessionConfig{
+		CallTimeout:       30 * time.Millisecond,
+		MetricsRegisterer: reg,
+		OnOrphan: func(id uint64, payload []byte) {
+			orphans <- id
+		},
+	})
+
+	ids := make(chan uint64, 1)
+	go func() {
+		id, _ := readRequest(t, server)
+		ids <- id
+	}()
+
+	_, err := session.Call(context.Background(), []byte("slow"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Call() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	id := <-ids
+	writeResponse(t, server, id, []byte("late"))
+	select {
+	case got := <-orphans:
+		if got != id {
+			t.Fatalf("orphan id = %d, want %d", got, id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("late response was not reported as orphan")
+	}
+	if got := gatheredCounter(t, reg, "tcpx_session_orphaned_responses_total"); got != 1 {
+		t.Fatalf("orphaned responses = %v, want 1", got)
+	}
+	if err := session.Err(); err != nil {
+		t.Fatalf("session Err() = %v, want nil after orphan", err)
+	}
+}
+
+func TestSessionMaxInFlightWaitsForSlot(t *testing.T) {
+	session, server := newSessionPair(t, &tcpx.SessionConfig{MaxInFlight: 1, DisableMetrics: true})
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := session.Call(context.Background(), []byte("first"))
+		first <- err
+	}()
+	id, _ := readRequest(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := session.Call(ctx, []byte("second")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second Call() error = %v, want %v while slot is held", err, context.DeadlineExceeded)
+	}
+
+	writeResponse(t, server, id, []byte("ok"))
+	if err := <-first; err != nil {
+		t.Fatalf("first Call() error = %v", err)
+	}
+}
+
+func TestSessionCloseFailsPendingCalls(t *testing.T) {
+	session, server := newSessionPair(t, &tcpx.SessionConfig{DisableMetrics: true})
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := session.Call(context.Background(), []byte("pending"))
+		result <- err
+	}()
+	readRequest(t, server)
+
+	if err := session.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := <-result; !errors.Is(err, tcpx.ErrSessionClosed) {
+		t.Fatalf("pending Call() error = %v, want %v", err, tcpx.ErrSessionClosed)
+	}
+	<-session.Done()
+	if _, err := session.Call(context.Background(), []byte("after")); !errors.Is(err, tcpx.ErrSessionClosed) {
+		t.Fatalf("Call() after Close error = %v, want %v", err, tcpx.ErrSessionClosed)
+	}
+}
+
+func TestSessionPeerCloseEndsSession(t *testing.T) {
+	session, server := newSessionPair(t, &tcpx.SessionConfig{DisableMetrics: true})
+
+	_ = server.Close()
+	select {
+	case <-session.Done():
+	case <-time.After(time.Second):
+		t.Fatal("session did not end after peer closed")
+	}
+	if err := session.Err(); !errors.Is(err, tcpx.ErrSessionClosed) {
+		t.Fatalf("Err() = %v, want %v", err, tcpx.ErrSessionClosed)
+	}
+}
+
+func TestPrefixCorrelatorRejectsShortFrame(t *testing.T) {
+	if _, _, err := (tcpx.PrefixCorrelator{}).Decode([]byte{1, 2, 3}); !errors.Is(err, tcpx.ErrInvalidCorrelationFrame) {
+		t.Fatalf("Decode() error = %v, want %v", err, tcpx.ErrInvalidCorrelationFrame)
+	}
+}
@@ -0,0 +1,189 @@
+package tcpx
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bang-go/opt"
+)
+
+// SocketOptions tunes the underlying TCP socket. Zero values keep the
+// operating system (or Go runtime) defaults.
+type SocketOptions struct {
+	// KeepAliveIdle and KeepAliveInterval default to the config's
+	// KeepAlivePeriod; KeepAliveCount defaults to the OS value.
+	KeepAliveIdle     time.Duration
+	KeepAliveInterval time.Duration
+	KeepAliveCount    int
+	// NoDelay sets TCP_NODELAY. Go enables it by default; set false to
+	// allow Nagle batching for small writes.
+	NoDelay         *bool
+	ReadBufferSize  int
+	WriteBufferSize int
+}
+
+func WithSocketOptions(options SocketOptions) opt.Option[connectOptions] {
+	return opt.OptionFunc[connectOptions](func(o *connectOptions) {
+		o.socket = &options
+	})
+}
+
+func (o SocketOptions) withKeepAlivePeriod(period time.Duration) SocketOptions {
+	if period <= 0 {
+		return o
+	}
+	if o.KeepAliveIdle == 0 {
+		o.KeepAliveIdle = period
+	}
+	if o.KeepAliveInterval == 0 {
+		o.KeepAliveInterval = period
+	}
+	return o
+}
+
+func (o SocketOptions) keepAliveConfig() (net.KeepAliveConfig, bool) {
+	if o.KeepAliveIdle <= 0 && o.KeepAliveInterval <= 0 && o.KeepAliveCount <= 0 {
+		return net.KeepAliveConfig{}, false
+	}
+	config := net.KeepAliveConfig{Enable: true, Idle: -1, Interval: -1, Count: -1}
+	if o.KeepAliveIdle > 0 {
+		config.Idle = o.KeepAliveIdle
+	}
+	if o.KeepAliveInterval > 0 {
+		config.Interval = o.KeepAliveInterval
+	}
+	if o.KeepAliveCount > 0 {
+		config.Count = o.KeepAliveCount
+	}
+	return config, true
+}
+
+func applySocketOptions(conn net.Conn, options SocketOptions) error {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	if config, ok := options.keepAliveConfig(); ok {
+		if err := tcpConn.SetKeepAliveConfig(config); err != nil {
+			return err
+		}
+	}
+	if options.NoDelay != nil {
+		if err := tcpConn.SetNoDelay(*options.NoDelay); err != nil {
+			return err
+		}
+	}
+	if options.ReadBufferSize > 0 {
+		if err := tcpConn.SetReadBuffer(options.ReadBufferSize); err != nil {
+			return err
+		}
+	}
+	if options.WriteBufferSize > 0 {
+		if err := tcpConn.SetWriteBuffer(options.WriteBufferSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listenReusePort opens count SO_REUSEPORT sockets on addr so the kernel can
+// spread incoming connections across parallel accept loops.
+func listenReusePort(ctx context.Context, addr string, count int) (net.Listener, error) {
+	config := net.ListenConfig{Control: reusePortControl}
+
+	first, err := config.Listen(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if count <= 1 {
+		return first, nil
+	}
+
+	// Later sockets bind the resolved address so ":0" shares one port.
+	listeners := []net.Listener{first}
+	for len(listeners) < count {
+		listener, err := config.Listen(ctx, "tcp", first.Addr().String())
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+	return newMultiListener(listeners), nil
+}
+
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+type multiListener struct {
+	listeners []net.Listener
+	accepted  chan acceptResult
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func newMultiListener(listeners []net.Listener) *multiListener {
+	l := &multiListener{
+		listeners: listeners,
+		accepted:  make(chan acceptResult),
+		done:      make(chan struct{}),
+	}
+	for _, listener := range listeners {
+		go l.acceptLoop(listener)
+	}
+	return l
+}
+
+func (l *multiListener) acceptLoop(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		select {
+		case l.accepted <- acceptResult{conn: conn, err: err}:
+		case <-l.done:
+			if conn != nil {
+				_ = conn.Close()
+			}
+			return
+		}
+		if err != nil && !isTemporaryNetError(err) {
+			return
+		}
+	}
+}
+
+func (l *multiListener) Accept() (net.Conn, error) {
+	select {
+	case result := <-l.accepted:
+		return result.conn, result.err
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *multiListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		for _, listener := range l.listeners {
+			if err := listener.Close(); err != nil && l.closeErr == nil && !isListenerClosedError(err) {
+				l.closeErr = err
+			}
+		}
+	})
+	return l.closeErr
+}
+
+func (l *multiListener) Addr() net.Addr {
+	return l.listeners[0].Addr()
+}
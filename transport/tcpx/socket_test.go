@@ -0,0 +1,101 @@
+package tcpx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/tcpx"
+)
+
+func TestServerReusePortAcceptsOnAllListeners(t *testing.T) {
+	noDelay := false
+	server := tcpx.NewServer(&tcpx.ServerConfig{
+		Addr:      "127.0.0.1:0",
+		ReusePort: true,
+		Listeners: 4,
+		Socket: tcpx.SocketOptions{
+			KeepAliveIdle:     time.Minute,
+			KeepAliveInterval: 10 * time.Second,
+			KeepAliveCount:    3,
+			NoDelay:           &noDelay,
+			ReadBufferSize:    64 << 10,
+			WriteBufferSize:   64 << 10,
+		},
+		DisableMetrics: true,
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Start(context.Background(), tcpx.HandlerFunc(func(ctx context.Context, conn tcpx.Connect) error {
+			buf := make([]byte, 4)
+			if err := conn.ReadFull(buf); err != nil {
+				return err
+			}
+			return conn.WriteFull(buf)
+		}))
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for server.Listener() == nil {
+		select {
+		case err := <-errCh:
+			if errors.Is(err, tcpx.ErrReusePortUnsupported) {
+				t.Skip(err)
+			}
+			t.Fatalf("Start() error = %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server did not become ready")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	client := tcpx.NewClient(&tcpx.ClientConfig{
+		Addr:           server.Listener().Addr().String(),
+		Socket:         tcpx.SocketOptions{NoDelay: &noDelay, ReadBufferSize: 32 << 10},
+		DisableMetrics: true,
+	})
+	for i := range 16 {
+		conn, err := client.DialContext(context.Background())
+		if err != nil {
+			t.Fatalf("dial %d: %v", i, err)
+		}
+		if err := conn.WriteFull([]byte("ping")); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+		reply := make([]byte, 4)
+		if err := conn.ReadFull(reply); err != nil {
+			t.Fatalf("read %d: %v", i, err)
+		}
+		if string(reply) != "ping" {
+			t.Fatalf("reply %d = %q, want ping", i, reply)
+		}
+		_ = conn.Close()
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
+
+func TestWithSocketOptionsIgnoresNonTCPConn(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	noDelay := true
+	conn, err := tcpx.NewConnect(client, tcpx.WithSocketOptions(tcpx.SocketOptions{
+		NoDelay:        &noDelay,
+		ReadBufferSize: 1024,
+	}))
+	if err != nil {
+		t.Fatalf("NewConnect() error = %v", err)
+	}
+	_ = conn.Close()
+}
@@ -0,0 +1,131 @@
+package tcpx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type ServerTLSConfig struct {
+	CertFile     string
+	KeyFile      string
+	Certificates []tls.Certificate
+	ClientCAFile string
+	ClientCAs    *x509.CertPool
+	ClientAuth   tls.ClientAuthType
+	MinVersion   uint16
+	NextProtos   []string
+}
+
+type tlsStateContextKey struct{}
+
+func (c *ServerTLSConfig) Build() (*tls.Config, error) {
+	if c == nil {
+		return nil, ErrTLSCertificateRequired
+	}
+
+	certificates := append([]tls.Certificate(nil), c.Certificates...)
+	certFile := strings.TrimSpace(c.CertFile)
+	keyFile := strings.TrimSpace(c.KeyFile)
+	if certFile != "" || keyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tcpx: load tls key pair: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, ErrTLSCertificateRequired
+	}
+
+	clientCAs := c.ClientCAs
+	if caFile := strings.TrimSpace(c.ClientCAFile); caFile != "" {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("tcpx: read client ca: %w", err)
+		}
+		if clientCAs == nil {
+			clientCAs = x509.NewCertPool()
+		} else {
+			clientCAs = clientCAs.Clone()
+		}
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return nil, ErrInvalidClientCA
+		}
+	}
+
+	clientAuth := c.ClientAuth
+	if clientAuth == tls.NoClientCert && clientCAs != nil {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	minVersion := c.MinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+
+	return &tls.Config{
+		Certificates: certificates,
+		ClientCAs:    clientCAs,
+		ClientAuth:   clientAuth,
+		MinVersion:   minVersion,
+		NextProtos:   append([]string(nil), c.NextProtos...),
+	}, nil
+}
+
+func TLSConnectionState(ctx context.Context) (tls.ConnectionState, bool) {
+	if ctx == nil {
+		return tls.ConnectionState{}, false
+	}
+	state, ok := ctx.Value(tlsStateContextKey{}).(*tls.ConnectionState)
+	if !ok || state == nil {
+		return tls.ConnectionState{}, false
+	}
+	return *state, true
+}
+
+func PeerCertificate(ctx context.Context) (*x509.Certificate, bool) {
+	state, ok := TLSConnectionState(ctx)
+	if !ok || len(state.PeerCertificates) == 0 {
+		return nil, false
+	}
+	return state.PeerCertificates[0], true
+}
+
+func withTLSConnectionState(ctx context.Context, state tls.ConnectionState) context.Context {
+	return context.WithValue(ctx, tlsStateContextKey{}, &state)
+}
+
+func resolveServerTLSConfig(conf *ServerConfig) (*tls.Config, error) {
+	if conf.TLSConfig != nil && conf.TLS != nil {
+		return nil, ErrTLSConfigConflict
+	}
+	if conf.TLSConfig != nil {
+		return conf.TLSConfig.Clone(), nil
+	}
+	if conf.TLS != nil {
+		return conf.TLS.Build()
+	}
+	return nil, nil
+}
+
+func (s *serverEntity) handshake(ctx context.Context, conn Connect) (context.Context, error) {
+	tlsConn, ok := conn.Conn().(*tls.Conn)
+	if !ok {
+		return ctx, nil
+	}
+
+	handshakeCtx := ctx
+	if s.config.HandshakeTimeout > 0 {
+		var cancel context.CancelFunc
+		handshakeCtx, cancel = context.WithTimeout(ctx, s.config.HandshakeTimeout)
+		defer cancel()
+	}
+	if err := tlsConn.HandshakeContext(handshakeCtx); err != nil {
+		return ctx, fmt.Errorf("tcpx: tls handshake: %w", err)
+	}
+	return withTLSConnectionState(ctx, tlsConn.ConnectionState()), nil
+}
@@ -0,0 +1,207 @@
+package tcpx_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/tcpx"
+)
+
+func TestServerTLSConfigBuild(t *testing.T) {
+	if _, err := (&tcpx.ServerTLSConfig{}).Build(); !errors.Is(err, tcpx.ErrTLSCertificateRequired) {
+		t.Fatalf("Build() without certificate error = %v", err)
+	}
+
+	ca := newTestCA(t)
+	serverCert := ca.issue(t, "localhost", x509.ExtKeyUsageServerAuth)
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, ca.certPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	cfg, err := (&tcpx.ServerTLSConfig{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAFile: caFile,
+		NextProtos:   []string{"bang/1"},
+	}).Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if cfg.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Fatalf("ClientAuth = %v, want RequireAndVerifyClientCert", cfg.ClientAuth)
+	}
+	if cfg.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("MinVersion = %x, want TLS 1.2", cfg.MinVersion)
+	}
+
+	badCA := filepath.Join(dir, "bad.pem")
+	if err := os.WriteFile(badCA, []byte("not a cert"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := (&tcpx.ServerTLSConfig{Certificates: []tls.Certificate{serverCert}, ClientCAFile: badCA}).Build(); !errors.Is(err, tcpx.ErrInvalidClientCA) {
+		t.Fatalf("Build() with bad CA error = %v", err)
+	}
+
+	server := tcpx.NewServer(&tcpx.ServerConfig{
+		Listener:       newPipeListener(),
+		TLSConfig:      &tls.Config{},
+		TLS:            &tcpx.ServerTLSConfig{Certificates: []tls.Certificate{serverCert}},
+		DisableMetrics: true,
+	})
+	err = server.Start(context.Background(), tcpx.HandlerFunc(func(context.Context, tcpx.Connect) error { return nil }))
+	if !errors.Is(err, tcpx.ErrTLSConfigConflict) {
+		t.Fatalf("Start() with conflicting TLS error = %v", err)
+	}
+}
+
+func TestServerMutualTLSExposesPeerCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert := ca.issue(t, "localhost", x509.ExtKeyUsageServerAuth)
+	clientCert := ca.issue(t, "orders-client", x509.ExtKeyUsageClientAuth)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+
+	server := tcpx.NewServer(&tcpx.ServerConfig{
+		Listener: listener,
+		TLS: &tcpx.ServerTLSConfig{
+			Certificates: []tls.Certificate{serverCert},
+			ClientCAs:    ca.pool,
+			NextProtos:   []string{"bang/1"},
+		},
+		DisableMetrics: true,
+	})
+
+	subjects := make(chan string, 1)
+	go func() {
+		_ = server.Start(context.Background(), tcpx.HandlerFunc(func(ctx context.Context, conn tcpx.Connect) error {
+			peer, ok := tcpx.PeerCertificate(ctx)
+			state, _ := tcpx.TLSConnectionState(ctx)
+			if ok {
+				subjects <- peer.Subject.CommonName + "|" + state.NegotiatedProtocol
+			} else {
+				subjects <- ""
+			}
+			return conn.WriteFull([]byte("ok"))
+		}))
+	}()
+	waitForServer(t, server)
+	defer server.Shutdown(context.Background())
+
+	client := tcpx.NewClient(&tcpx.ClientConfig{
+		Addr: listener.Addr().String(),
+		TLSConfig: &tls.Config{
+			ServerName:   "localhost",
+			RootCAs:      ca.pool,
+			Certificates: []tls.Certificate{clientCert},
+			NextProtos:   []string{"bang/1"},
+		},
+		DisableMetrics: true,
+	})
+	conn, err := client.DialContext(context.Background())
+	if err != nil {
+		t.Fatalf("DialContext: %v", err)
+	}
+	defer conn.Close()
+
+	reply := make([]byte, 2)
+	if err := conn.ReadFull(reply); err != nil {
+		t.Fatalf("ReadFull: %v", err)
+	}
+	if got, want := <-subjects, "orders-client|bang/1"; got != want {
+		t.Fatalf("peer = %q, want %q", got, want)
+	}
+
+	anonymous := tcpx.NewClient(&tcpx.ClientConfig{
+		Addr:           listener.Addr().String(),
+		TLSConfig:      &tls.Config{ServerName: "localhost", RootCAs: ca.pool},
+		DisableMetrics: true,
+	})
+	anonConn, err := anonymous.DialContext(context.Background())
+	if err == nil {
+		defer anonConn.Close()
+		if err := anonConn.ReadFull(make([]byte, 1)); err == nil {
+			t.Fatal("expected server to reject client without certificate")
+		}
+	}
+}
+
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	pool    *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "tcpx test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pool:    pool,
+	}
+}
+
+func (ca *testCA) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{commonName},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
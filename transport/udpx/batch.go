@@ -0,0 +1,285 @@
+package udpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/bang-go/micro/pkg/bytesx"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+type readLoop struct {
+	server    *serverEntity
+	ctx       context.Context
+	logCtx    context.Context
+	handler   Handler
+	tracer    trace.Tracer
+	addrLabel string
+}
+
+type datagram struct {
+	n         int
+	addr      net.Addr
+	truncated bool
+}
+
+// packetReader fills buffers with up to len(buffers) datagrams.
+type packetReader interface {
+	read(buffers [][]byte, out []datagram) (int, error)
+}
+
+// run reads until the socket is closed. It returns nil on shutdown and the
+// read error when the socket fails.
+func (l *readLoop) run(packetConn net.PacketConn) error {
+	s := l.server
+	reader, batch := newPacketReader(packetConn, s.config.BatchSize)
+	buffers := make([][]byte, batch)
+	var pooled []*[]byte
+	if s.buffers != nil {
+		pooled = make([]*[]byte, batch)
+		for i := range pooled {
+			pooled[i] = s.buffers.get()
+			buffers[i] = *pooled[i]
+		}
+		defer func() {
+			for _, buffer := range pooled {
+				s.buffers.put(buffer)
+			}
+		}()
+	} else {
+		for i := range buffers {
+			buffers[i] = make([]byte, s.config.MaxPacketSize)
+		}
+	}
+	datagrams := make([]datagram, batch)
+
+	for {
+		count, err := reader.read(buffers, datagrams)
+		if err != nil {
+			if isClosedConnectionError(err) || errors.Is(context.Cause(l.ctx), errServerClosed) {
+				return nil
+			}
+			if isTemporaryNetError(err) {
+				s.config.Logger.Warn(l.logCtx, "udp read temporary failure", "error", err)
+				continue
+			}
+			return fmt.Errorf("udpx: read packet: %w", err)
+		}
+
+		if !s.beginDispatch() {
+			l.discard(count)
+			continue
+		}
+		for i := range count {
+			var buffer *[]byte
+			if pooled != nil {
+				buffer = pooled[i]
+			}
+			handedOff, ok := l.dispatch(packetConn, buffers[i], buffer, datagrams[i])
+			if handedOff {
+				// The handler owns the buffer until it is released.
+				pooled[i] = s.buffers.get()
+				buffers[i] = *pooled[i]
+			}
+			if !ok {
+				l.discard(count - i)
+				s.endDispatch()
+				return nil
+			}
+		}
+		s.endDispatch()
+	}
+}
+
+// dispatch starts the handler for one datagram. handedOff reports whether the
+// pooled buffer now belongs to the packet; ok is false once the server stops.
+func (l *readLoop) dispatch(packetConn net.PacketConn, buffer []byte, pooled *[]byte, packet datagram) (handedOff, ok bool) {
+	s := l.server
+	if s.metrics != nil {
+		s.metrics.serverPacketsReceived.WithLabelValues(l.addrLabel).Inc()
+		s.metrics.serverBytesRead.WithLabelValues(l.addrLabel).Add(float64(packet.n))
+	}
+
+	if packet.truncated {
+		if s.metrics != nil {
+			s.metrics.serverPacketsDropped.WithLabelValues(l.addrLabel, "truncated").Inc()
+		}
+		s.config.Logger.Warn(l.logCtx, "udp packet dropped", "reason", "truncated", "remote_addr", packet.addr.String(), "max_packet_size", s.config.MaxPacketSize)
+		if s.config.OversizeReply != nil {
+			l.replyOversize(packetConn, packet.addr)
+		}
+		return false, true
+	}
+
+	payload := buffer[:packet.n]
+	if s.config.Codec != nil {
+		decoded, err := s.config.Codec.Decode(payload)
+		if err != nil {
+			if s.metrics != nil {
+				s.metrics.serverPacketsDropped.WithLabelValues(l.addrLabel, "malformed").Inc()
+			}
+			if s.config.EnableLogger {
+				s.config.Logger.Debug(l.logCtx, "udp packet dropped", "reason", "malformed", "remote_addr", packet.addr.String(), "error", err)
+			}
+			return false, true
+		}
+		payload = decoded
+	}
+
+	if err := s.acquireSlot(l.ctx); err != nil {
+		return false, false
+	}
+
+	var onWrite func(int, error)
+	if s.metrics != nil {
+		onWrite = func(written int, err error) {
+			s.observeWrite(l.addrLabel, written, err)
+		}
+	}
+
+	var p *packetEntity
+	if pooled != nil {
+		p = newPacket(payload, packet.addr, packetConn.LocalAddr(), packetConn, onWrite)
+		p.buffer = pooled
+		p.pool = s.buffers
+	} else {
+		p = newPacket(append([]byte(nil), payload...), packet.addr, packetConn.LocalAddr(), packetConn, onWrite)
+	}
+	p.codec = s.config.Codec
+
+	s.wg.Add(1)
+	go s.handlePacket(l.ctx, l.tracer, l.addrLabel, l.handler, p)
+	return pooled != nil, true
+}
+
+// replyOversize answers from the read loop; the reply is small and a slow
+// write only delays this reader.
+func (l *readLoop) replyOversize(packetConn net.PacketConn, addr net.Addr) {
+	s := l.server
+	reply := s.config.OversizeReply
+	if s.config.Codec != nil {
+		encoded, err := s.config.Codec.Encode(reply)
+		if err != nil {
+			return
+		}
+		reply = encoded
+	}
+	n, err := packetConn.WriteTo(reply, addr)
+	if s.metrics != nil {
+		s.observeWrite(l.addrLabel, n, err)
+	}
+	if err != nil && s.config.EnableLogger {
+		s.config.Logger.Debug(l.logCtx, "udp oversize reply failed", "remote_addr", addr.String(), "error", err)
+	}
+}
+
+func newPacketReader(packetConn net.PacketConn, batchSize int) (packetReader, int) {
+	if udpConn, ok := packetConn.(*net.UDPConn); ok && batchSize > 1 {
+		return newBatchReader(udpConn, batchSize), batchSize
+	}
+	return singleReader{packetConn: packetConn}, 1
+}
+
+type singleReader struct {
+	packetConn net.PacketConn
+}
+
+func (r singleReader) read(buffers [][]byte, out []datagram) (int, error) {
+	type messageReader interface {
+		ReadMsgUDP([]byte, []byte) (int, int, int, *net.UDPAddr, error)
+	}
+	if reader, ok := r.packetConn.(messageReader); ok {
+		n, _, flags, addr, err := reader.ReadMsgUDP(buffers[0], nil)
+		if err != nil {
+			return 0, err
+		}
+		out[0] = datagram{n: n, addr: addr, truncated: flags&syscall.MSG_TRUNC != 0}
+		return 1, nil
+	}
+
+	n, addr, err := r.packetConn.ReadFrom(buffers[0])
+	if err != nil {
+		return 0, err
+	}
+	out[0] = datagram{n: n, addr: addr}
+	return 1, nil
+}
+
+// batchConn is implemented by both ipv4.PacketConn and ipv6.PacketConn, whose
+// Message types alias the same struct.
+type batchConn interface {
+	ReadBatch([]ipv4.Message, int) (int, error)
+}
+
+type batchReader struct {
+	conn     batchConn
+	messages []ipv4.Message
+}
+
+func newBatchReader(udpConn *net.UDPConn, batchSize int) *batchReader {
+	var conn batchConn = ipv4.NewPacketConn(udpConn)
+	if addr, ok := udpConn.LocalAddr().(*net.UDPAddr); ok && addr.IP.To4() == nil && len(addr.IP) > 0 {
+		conn = ipv6.NewPacketConn(udpConn)
+	}
+	messages := make([]ipv4.Message, batchSize)
+	for i := range messages {
+		messages[i].Buffers = make([][]byte, 1)
+	}
+	return &batchReader{conn: conn, messages: messages}
+}
+
+func (r *batchReader) read(buffers [][]byte, out []datagram) (int, error) {
+	for i := range r.messages {
+		r.messages[i].Buffers[0] = buffers[i]
+	}
+	count, err := r.conn.ReadBatch(r.messages, 0)
+	if err != nil {
+		return 0, err
+	}
+	for i := range count {
+		msg := &r.messages[i]
+		out[i] = datagram{n: msg.N, addr: msg.Addr, truncated: msg.Flags&syscall.MSG_TRUNC != 0}
+	}
+	return count, nil
+}
+
+// bufferPool is a bounded free list of read buffers. Unlike sync.Pool it is
+// not emptied by GC, so a steady packet rate does not keep reallocating
+// MaxPacketSize buffers. Bursts beyond it fall back to the shared bytesx pool.
+type bufferPool struct {
+	size int
+	free chan *[]byte
+}
+
+func newBufferPool(conf *ServerConfig) *bufferPool {
+	// Enough for every reader slot plus the handlers that may hold a buffer.
+	capacity := 2 * conf.Readers * conf.BatchSize
+	if conf.MaxConcurrency > 0 {
+		capacity += conf.MaxConcurrency
+	} else {
+		capacity += defaultServerMaxConcurrency
+	}
+	return &bufferPool{size: conf.MaxPacketSize, free: make(chan *[]byte, capacity)}
+}
+
+func (p *bufferPool) get() *[]byte {
+	select {
+	case buffer := <-p.free:
+		return buffer
+	default:
+		return bytesx.Get(p.size)
+	}
+}
+
+func (p *bufferPool) put(buffer *[]byte) {
+	select {
+	case p.free <- buffer:
+	default:
+		bytesx.Put(buffer)
+	}
+}
@@ -0,0 +1,270 @@
+package udpx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/udpx"
+)
+
+func startRealServer(tb testing.TB, conf *udpx.ServerConfig, handler udpx.HandlerFunc) udpx.Server {
+	tb.Helper()
+
+	server := udpx.NewServer(conf)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Start(context.Background(), handler)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for server.PacketConn() == nil {
+		select {
+		case err := <-errCh:
+			if errors.Is(err, udpx.ErrReusePortUnsupported) {
+				tb.Skip(err)
+			}
+			tb.Fatalf("Start() error = %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			tb.Fatal("server did not become ready")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	tb.Cleanup(func() {
+		if err := server.Shutdown(context.Background()); err != nil {
+			tb.Errorf("Shutdown() error = %v", err)
+		}
+		if err := <-errCh; err != nil {
+			tb.Errorf("Start() error = %v", err)
+		}
+	})
+	return server
+}
+
+func TestServerReusePortBatchReaders(t *testing.T) {
+	server := startRealServer(t, &udpx.ServerConfig{
+		Addr:           "127.0.0.1:0",
+		ReusePort:      true,
+		Readers:        4,
+		BatchSize:      16,
+		MaxPacketSize:  64,
+		DisableMetrics: true,
+	}, func(ctx context.Context, packet udpx.Packet) error {
+		_, err := packet.Reply(ctx, packet.Payload())
+		return err
+	})
+
+	const clients = 8
+	for i := range clients {
+		conn, err := net.Dial("udp", server.PacketConn().LocalAddr().String())
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		defer conn.Close()
+
+		want := fmt.Sprintf("client-%d", i)
+		if _, err := conn.Write([]byte(want)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		buf := make([]byte, 64)
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("read reply %d: %v", i, err)
+		}
+		if got := string(buf[:n]); got != want {
+			t.Fatalf("reply = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestServerBatchReaderDropsTruncatedPackets(t *testing.T) {
+	var handled atomic.Int32
+	server := startRealServer(t, &udpx.ServerConfig{
+		Addr:           "127.0.0.1:0",
+		BatchSize:      8,
+		MaxPacketSize:  8,
+		DisableMetrics: true,
+	}, func(ctx context.Context, packet udpx.Packet) error {
+		handled.Add(1)
+		_, err := packet.Reply(ctx, packet.Payload())
+		return err
+	})
+
+	conn, err := net.Dial("udp", server.PacketConn().LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	_, _ = conn.Write([]byte("this payload is too long"))
+	_, _ = conn.Write([]byte("ok"))
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if got := string(buf[:n]); got != "ok" {
+		t.Fatalf("reply = %q, want ok", got)
+	}
+	if got := handled.Load(); got != 1 {
+		t.Fatalf("handled = %d, want truncated packet dropped", got)
+	}
+}
+
+func TestServerPooledBuffersRetain(t *testing.T) {
+	retained := make(chan []byte, 1)
+	var release func()
+	server := startRealServer(t, &udpx.ServerConfig{
+		Addr:           "127.0.0.1:0",
+		PooledBuffers:  true,
+		BatchSize:      4,
+		MaxConcurrency: 1,
+		DisableMetrics: true,
+	}, func(ctx context.Context, packet udpx.Packet) error {
+		if string(packet.Payload()) == "keep" {
+			release = udpx.Retain(packet)
+			retained <- packet.Payload()
+		}
+		_, err := packet.Reply(ctx, packet.Payload())
+		return err
+	})
+
+	conn, err := net.Dial("udp", server.PacketConn().LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 64)
+	for _, payload := range []string{"keep", "overwrite-1", "overwrite-2", "overwrite-3"} {
+		if _, err := conn.Write([]byte(payload)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("read reply: %v", err)
+		}
+		if got := string(buf[:n]); got != payload {
+			t.Fatalf("reply = %q, want %q", got, payload)
+		}
+	}
+
+	if got := string(<-retained); got != "keep" {
+		t.Fatalf("retained payload = %q, want keep", got)
+	}
+	release()
+	release()
+}
+
+func TestRetainIsNoopForCopiedPackets(t *testing.T) {
+	server := startRealServer(t, &udpx.ServerConfig{
+		Addr:           "127.0.0.1:0",
+		DisableMetrics: true,
+	}, func(ctx context.Context, packet udpx.Packet) error {
+		udpx.Retain(packet)()
+		_, err := packet.Reply(ctx, packet.Payload())
+		return err
+	})
+
+	conn, err := net.Dial("udp", server.PacketConn().LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte("copy"))
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "copy" {
+		t.Fatalf("reply = %q, %v", buf[:n], err)
+	}
+}
+
+// BenchmarkServerThroughput compares the single reader loop with batched,
+// multi-socket readers and pooled buffers. Run with -benchtime=1000000x; "pps"
+// is the delivered packet rate, "loss" the fraction the kernel dropped, and
+// B/op is per sent packet.
+func BenchmarkServerThroughput(b *testing.B) {
+	cases := []struct {
+		name      string
+		readers   int
+		batch     int
+		reusePort bool
+		pooled    bool
+	}{
+		{name: "readers=1/batch=1", readers: 1, batch: 1},
+		{name: "readers=1/batch=32", readers: 1, batch: 32},
+		{name: "readers=4/batch=32/reuseport", readers: 4, batch: 32, reusePort: true},
+		{name: "readers=4/batch=32/reuseport/pooled", readers: 4, batch: 32, reusePort: true, pooled: true},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			var received atomic.Int64
+			server := startRealServer(b, &udpx.ServerConfig{
+				Addr:           "127.0.0.1:0",
+				Readers:        tc.readers,
+				BatchSize:      tc.batch,
+				ReusePort:      tc.reusePort,
+				PooledBuffers:  tc.pooled,
+				ReadBuffer:     8 << 20,
+				MaxPacketSize:  2048,
+				MaxConcurrency: 1024,
+				DisableMetrics: true,
+			}, func(context.Context, udpx.Packet) error {
+				received.Add(1)
+				return nil
+			})
+			addr := server.PacketConn().LocalAddr().String()
+
+			// Several source ports let SO_REUSEPORT hash flows to different sockets.
+			const senders = 8
+			conns := make([]net.Conn, senders)
+			for i := range conns {
+				conn, err := net.Dial("udp", addr)
+				if err != nil {
+					b.Fatalf("dial: %v", err)
+				}
+				defer conn.Close()
+				conns[i] = conn
+			}
+			payload := make([]byte, 128)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			start := time.Now()
+			done := make(chan struct{})
+			for i, conn := range conns {
+				go func() {
+					defer func() { done <- struct{}{} }()
+					for j := i; j < b.N; j += senders {
+						_, _ = conn.Write(payload)
+					}
+				}()
+			}
+			for range senders {
+				<-done
+			}
+
+			// Wait for the readers to drain the socket buffers.
+			last := int64(-1)
+			for received.Load() != last {
+				last = received.Load()
+				time.Sleep(20 * time.Millisecond)
+			}
+			elapsed := time.Since(start)
+			b.StopTimer()
+
+			b.ReportMetric(float64(last)/elapsed.Seconds(), "pps")
+			b.ReportMetric(1-float64(last)/float64(b.N), "loss")
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/pion/dtls/v3"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -26,13 +27,17 @@ type Client interface {
 }
 
 type ClientConfig struct {
-	Addr              string
-	LocalAddr         net.Addr
-	DialTimeout       time.Duration
-	ReadTimeout       time.Duration
-	WriteTimeout      time.Duration
-	Dialer            *net.Dialer
-	ContextDialer     func(context.Context, string, string) (net.Conn, error)
+	Addr          string
+	LocalAddr     net.Addr
+	DialTimeout   time.Duration
+	ReadTimeout   time.Duration
+	WriteTimeout  time.Duration
+	Dialer        *net.Dialer
+	ContextDialer func(context.Context, string, string) (net.Conn, error)
+	// DTLSConfig or DTLS run a DTLS handshake after dialing; the Connect then
+	// reads and writes decrypted payloads.
+	DTLSConfig        *dtls.Config
+	DTLS              *DTLSConfig
 	Logger            *logger.Logger
 	EnableLogger      bool
 	Trace             bool
@@ -145,10 +150,26 @@ func (c *clientEntity) DialContext(ctx context.Context) (Connect, error) {
 }
 
 func (c *clientEntity) dialContext(ctx context.Context) (net.Conn, error) {
+	dtlsConfig, err := resolveDTLSConfig(c.config.DTLSConfig, c.config.DTLS, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawConn net.Conn
 	if c.config.ContextDialer != nil {
-		return c.config.ContextDialer(ctx, "udp", c.config.Addr)
+		rawConn, err = c.config.ContextDialer(ctx, "udp", c.config.Addr)
+	} else {
+		rawConn, err = c.newDialer().DialContext(ctx, "udp", c.config.Addr)
+	}
+	if err != nil || dtlsConfig == nil {
+		return rawConn, err
+	}
+	if dtlsConfig.ServerName == "" && !dtlsConfig.InsecureSkipVerify {
+		if host, _, splitErr := net.SplitHostPort(c.config.Addr); splitErr == nil {
+			dtlsConfig.ServerName = host
+		}
 	}
-	return c.newDialer().DialContext(ctx, "udp", c.config.Addr)
+	return dialDTLS(ctx, rawConn, dtlsConfig, c.config.DTLS.handshakeTimeout())
 }
 
 func (c *clientEntity) newDialer() *net.Dialer {
@@ -0,0 +1,58 @@
+package udpx
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Codec frames datagrams. A server with a Codec decodes every datagram before
+// the handler sees it and encodes payloads written through Packet.
+type Codec interface {
+	Encode(payload []byte) ([]byte, error)
+	// Decode returns the payload carried by a datagram, or an error wrapping
+	// ErrMalformedPacket. The result may alias datagram.
+	Decode(datagram []byte) ([]byte, error)
+}
+
+// appendEncoder is implemented by the built-in codec so writes can encode
+// into a pooled buffer instead of allocating a frame per datagram.
+type appendEncoder interface {
+	encodedSize(payload []byte) int
+	appendEncode(dst, payload []byte) []byte
+}
+
+type headerCodec struct {
+	magic   []byte
+	version byte
+}
+
+// NewHeaderCodec returns a Codec framing payloads as magic | version | payload.
+// Decode rejects datagrams with a different magic or version.
+func NewHeaderCodec(magic []byte, version byte) Codec {
+	return &headerCodec{magic: append([]byte(nil), magic...), version: version}
+}
+
+func (c *headerCodec) Encode(payload []byte) ([]byte, error) {
+	return c.appendEncode(make([]byte, 0, c.encodedSize(payload)), payload), nil
+}
+
+func (c *headerCodec) encodedSize(payload []byte) int {
+	return len(c.magic) + 1 + len(payload)
+}
+
+func (c *headerCodec) appendEncode(dst, payload []byte) []byte {
+	dst = append(dst, c.magic...)
+	dst = append(dst, c.version)
+	return append(dst, payload...)
+}
+
+func (c *headerCodec) Decode(datagram []byte) ([]byte, error) {
+	headerLen := len(c.magic) + 1
+	if len(datagram) < headerLen || !bytes.Equal(datagram[:len(c.magic)], c.magic) {
+		return nil, fmt.Errorf("%w: bad magic", ErrMalformedPacket)
+	}
+	if version := datagram[len(c.magic)]; version != c.version {
+		return nil, fmt.Errorf("%w: version %d, want %d", ErrUnsupportedVersion, version, c.version)
+	}
+	return datagram[headerLen:], nil
+}
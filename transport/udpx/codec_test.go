@@ -0,0 +1,104 @@
+package udpx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/udpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestHeaderCodec(t *testing.T) {
+	codec := udpx.NewHeaderCodec([]byte("BG"), 2)
+
+	frame, err := codec.Encode([]byte("hello"))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if string(frame) != "BG\x02hello" {
+		t.Fatalf("Encode() = %q", frame)
+	}
+	payload, err := codec.Decode(frame)
+	if err != nil || string(payload) != "hello" {
+		t.Fatalf("Decode() = %q, %v", payload, err)
+	}
+
+	if _, err := codec.Decode([]byte("XX\x02hello")); !errors.Is(err, udpx.ErrMalformedPacket) {
+		t.Fatalf("Decode(bad magic) error = %v", err)
+	}
+	if _, err := codec.Decode([]byte("BG\x01hello")); !errors.Is(err, udpx.ErrUnsupportedVersion) || !errors.Is(err, udpx.ErrMalformedPacket) {
+		t.Fatalf("Decode(bad version) error = %v", err)
+	}
+	if _, err := codec.Decode([]byte("B")); !errors.Is(err, udpx.ErrMalformedPacket) {
+		t.Fatalf("Decode(short) error = %v", err)
+	}
+}
+
+func TestServerCodecDecodesAndDropsMalformedPackets(t *testing.T) {
+	packetConn := newFakePacketConn()
+	reg := prometheus.NewRegistry()
+	server := udpx.NewServer(&udpx.ServerConfig{
+		PacketConn:        packetConn,
+		MaxPacketSize:     16,
+		Codec:             udpx.NewHeaderCodec([]byte("BG"), 1),
+		OversizeReply:     []byte("too big"),
+		MetricsRegisterer: reg,
+	})
+
+	handled := make(chan string, 4)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Start(context.Background(), udpx.HandlerFunc(func(ctx context.Context, packet udpx.Packet) error {
+			handled <- string(packet.Payload())
+			n, err := packet.Reply(ctx, []byte("pong"))
+			if err == nil && n != len("pong") {
+				t.Errorf("Reply() n = %d, want payload length", n)
+			}
+			return err
+		}))
+	}()
+	waitForServer(t, server)
+
+	remote := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 30005}
+	packetConn.enqueuePacket([]byte("junk"), remote)
+	packetConn.enqueuePacket([]byte("BG\x01ping"), remote)
+
+	select {
+	case got := <-handled:
+		if got != "ping" {
+			t.Fatalf("handler payload = %q, want decoded ping", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler was not called")
+	}
+	reply, err := packetConn.nextWrite(2 * time.Second)
+	if err != nil || string(reply.data) != "BG\x01pong" {
+		t.Fatalf("reply = %q, %v; want encoded pong", reply.data, err)
+	}
+
+	packetConn.enqueueTruncatedPacket([]byte("BG\x01this payload is too long"), remote)
+	reply, err = packetConn.nextWrite(2 * time.Second)
+	if err != nil || string(reply.data) != "BG\x01too big" {
+		t.Fatalf("oversize reply = %q, %v", reply.data, err)
+	}
+	if len(handled) != 0 {
+		t.Fatalf("malformed or oversize packet reached handler: %q", <-handled)
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("start returned error: %v", err)
+	}
+
+	if got := droppedPackets(t, reg, "malformed"); got != 1 {
+		t.Fatalf("malformed drops = %v, want 1", got)
+	}
+	if got := droppedPackets(t, reg, "truncated"); got != 1 {
+		t.Fatalf("truncated drops = %v, want 1", got)
+	}
+}
@@ -0,0 +1,56 @@
+package udpx
+
+import "context"
+
+// beginDispatch reports whether packets just read may still be dispatched.
+// Once Shutdown seals the server, reads are discarded instead.
+func (s *serverEntity) beginDispatch() bool {
+	s.dispatchMu.Lock()
+	defer s.dispatchMu.Unlock()
+
+	if s.sealed {
+		return false
+	}
+	s.dispatching.Add(1)
+	return true
+}
+
+func (s *serverEntity) endDispatch() {
+	s.dispatching.Done()
+}
+
+// seal stops dispatching newly read packets. Batches already being dispatched
+// finish; no dispatch starts afterwards, so waiting on dispatching is safe.
+func (s *serverEntity) seal() {
+	s.dispatchMu.Lock()
+	s.sealed = true
+	s.dispatchMu.Unlock()
+}
+
+// drain waits for in-progress dispatches and running handlers, or for ctx.
+func (s *serverEntity) drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.dispatching.Wait()
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *readLoop) discard(count int) {
+	if count <= 0 {
+		return
+	}
+	s := l.server
+	s.discarded.Add(int64(count))
+	if s.metrics != nil {
+		s.metrics.serverPacketsDropped.WithLabelValues(l.addrLabel, "shutdown").Add(float64(count))
+	}
+}
@@ -0,0 +1,428 @@
+package udpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bang-go/micro/pkg/bytesx"
+	"github.com/pion/dtls/v3"
+)
+
+const (
+	defaultDTLSHandshakeTimeout = 10 * time.Second
+	defaultDTLSIdleTimeout      = 5 * time.Minute
+	// dtlsMaxRecordSize is the largest plaintext a DTLS record carries.
+	dtlsMaxRecordSize = 1 << 14
+)
+
+// DTLSConfig builds a pion/dtls configuration from certificate files or a
+// pre-shared key. Set ServerConfig.DTLSConfig / ClientConfig.DTLSConfig
+// instead for full control.
+type DTLSConfig struct {
+	CertFile     string
+	KeyFile      string
+	Certificates []tls.Certificate
+	// CAFile and CAs verify the peer: client certificates on the server,
+	// the server certificate on the client.
+	CAFile string
+	CAs    *x509.CertPool
+	// ClientAuth defaults to RequireAndVerifyClientCert when CAs are set on
+	// the server.
+	ClientAuth         dtls.ClientAuthType
+	ServerName         string
+	InsecureSkipVerify bool
+
+	// PSK switches to pre-shared key cipher suites; certificates are then
+	// optional. PSKIdentity is sent by the client and, when set on the server,
+	// must match.
+	PSK         []byte
+	PSKIdentity string
+
+	// HandshakeTimeout defaults to 10s.
+	HandshakeTimeout time.Duration
+	// IdleTimeout closes server sessions without traffic; defaults to 5m.
+	IdleTimeout time.Duration
+}
+
+func (c *DTLSConfig) BuildServer() (*dtls.Config, error) {
+	config, err := c.build()
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Certificates) == 0 && config.PSK == nil {
+		return nil, ErrDTLSCredentialsRequired
+	}
+	config.ClientCAs = config.RootCAs
+	config.RootCAs = nil
+	config.ClientAuth = c.ClientAuth
+	if config.ClientAuth == dtls.NoClientCert && config.ClientCAs != nil {
+		config.ClientAuth = dtls.RequireAndVerifyClientCert
+	}
+	if identity := c.PSKIdentity; config.PSK != nil && identity != "" {
+		key := config.PSK
+		config.PSK = func(hint []byte) ([]byte, error) {
+			if string(hint) != identity {
+				return nil, fmt.Errorf("udpx: unknown psk identity %q", hint)
+			}
+			return key(hint)
+		}
+	}
+	return config, nil
+}
+
+func (c *DTLSConfig) BuildClient() (*dtls.Config, error) {
+	config, err := c.build()
+	if err != nil {
+		return nil, err
+	}
+	config.ServerName = c.ServerName
+	config.InsecureSkipVerify = c.InsecureSkipVerify
+	if config.PSK != nil {
+		config.PSKIdentityHint = []byte(c.PSKIdentity)
+	}
+	return config, nil
+}
+
+func (c *DTLSConfig) build() (*dtls.Config, error) {
+	if c == nil {
+		return nil, ErrDTLSCredentialsRequired
+	}
+
+	certificates := append([]tls.Certificate(nil), c.Certificates...)
+	certFile := strings.TrimSpace(c.CertFile)
+	keyFile := strings.TrimSpace(c.KeyFile)
+	if certFile != "" || keyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("udpx: load dtls key pair: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	cas := c.CAs
+	if caFile := strings.TrimSpace(c.CAFile); caFile != "" {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("udpx: read dtls ca: %w", err)
+		}
+		if cas == nil {
+			cas = x509.NewCertPool()
+		} else {
+			cas = cas.Clone()
+		}
+		if !cas.AppendCertsFromPEM(data) {
+			return nil, ErrInvalidDTLSCA
+		}
+	}
+
+	config := &dtls.Config{
+		Certificates:         certificates,
+		RootCAs:              cas,
+		ExtendedMasterSecret: dtls.RequireExtendedMasterSecret,
+	}
+	if len(c.PSK) > 0 {
+		key := bytes.Clone(c.PSK)
+		config.PSK = func([]byte) ([]byte, error) {
+			return key, nil
+		}
+		// pion's default suites are certificate based only.
+		config.CipherSuites = []dtls.CipherSuiteID{
+			dtls.TLS_ECDHE_PSK_WITH_AES_128_CBC_SHA256,
+			dtls.TLS_PSK_WITH_AES_128_GCM_SHA256,
+			dtls.TLS_PSK_WITH_AES_128_CCM,
+		}
+	}
+	return config, nil
+}
+
+func (c *DTLSConfig) handshakeTimeout() time.Duration {
+	if c != nil && c.HandshakeTimeout > 0 {
+		return c.HandshakeTimeout
+	}
+	return defaultDTLSHandshakeTimeout
+}
+
+func (c *DTLSConfig) idleTimeout() time.Duration {
+	if c != nil && c.IdleTimeout > 0 {
+		return c.IdleTimeout
+	}
+	return defaultDTLSIdleTimeout
+}
+
+func resolveDTLSConfig(raw *dtls.Config, conf *DTLSConfig, server bool) (*dtls.Config, error) {
+	switch {
+	case raw != nil && conf != nil:
+		return nil, ErrDTLSConfigConflict
+	case raw != nil:
+		cloned := *raw
+		return &cloned, nil
+	case conf == nil:
+		return nil, nil
+	case server:
+		return conf.BuildServer()
+	default:
+		return conf.BuildClient()
+	}
+}
+
+// dtlsDatagram carries a pooled copy of a record; the reader returns it to
+// the pool once copied into the server's buffer.
+type dtlsDatagram struct {
+	data *[]byte
+	addr *net.UDPAddr
+}
+
+// dtlsPacketConn presents the sessions of a DTLS listener as one PacketConn so
+// the server's read loop, codec, metrics and interceptors work unchanged.
+// Each datagram read is one decrypted record; writes go to the session of the
+// destination address.
+type dtlsPacketConn struct {
+	listener         net.Listener
+	handshakeTimeout time.Duration
+	idleTimeout      time.Duration
+	onError          func(remote net.Addr, err error)
+
+	incoming chan dtlsDatagram
+	closed   chan struct{}
+
+	mu sync.Mutex
+	// conns holds every accepted conn; sessions only those past the handshake,
+	// by remote address.
+	conns     map[net.Conn]struct{}
+	sessions  map[string]net.Conn
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func listenDTLS(addr string, config *dtls.Config, conf *DTLSConfig, onError func(net.Addr, error)) (*dtlsPacketConn, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := dtls.Listen("udp", udpAddr, config)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &dtlsPacketConn{
+		listener:         listener,
+		handshakeTimeout: conf.handshakeTimeout(),
+		idleTimeout:      conf.idleTimeout(),
+		onError:          onError,
+		incoming:         make(chan dtlsDatagram, 64),
+		closed:           make(chan struct{}),
+		conns:            make(map[net.Conn]struct{}),
+		sessions:         make(map[string]net.Conn),
+	}
+	c.wg.Add(1)
+	go c.accept()
+	return c, nil
+}
+
+func (c *dtlsPacketConn) accept() {
+	defer c.wg.Done()
+	for {
+		conn, err := c.listener.Accept()
+		if err != nil {
+			if isClosedConnectionError(err) || c.isClosed() {
+				return
+			}
+			// Server() fails for a rejected connection attempt; keep accepting.
+			c.onError(nil, err)
+			continue
+		}
+		c.wg.Add(1)
+		go c.serveSession(conn)
+	}
+}
+
+func (c *dtlsPacketConn) serveSession(conn net.Conn) {
+	defer c.wg.Done()
+	defer conn.Close()
+
+	// Track the conn before the handshake so Close can interrupt it.
+	c.mu.Lock()
+	if c.conns == nil {
+		c.mu.Unlock()
+		return
+	}
+	c.conns[conn] = struct{}{}
+	c.mu.Unlock()
+
+	remote := conn.RemoteAddr()
+	key := remote.String()
+	defer func() {
+		c.mu.Lock()
+		delete(c.conns, conn)
+		if c.sessions[key] == conn {
+			delete(c.sessions, key)
+		}
+		c.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.handshakeTimeout)
+	err := conn.(*dtls.Conn).HandshakeContext(ctx)
+	cancel()
+	if err != nil {
+		if !c.isClosed() {
+			c.onError(remote, err)
+		}
+		return
+	}
+
+	c.mu.Lock()
+	if previous, ok := c.sessions[key]; ok {
+		_ = previous.Close()
+	}
+	if c.sessions != nil {
+		c.sessions[key] = conn
+	}
+	c.mu.Unlock()
+
+	udpAddr, _ := remote.(*net.UDPAddr)
+	buffer := make([]byte, dtlsMaxRecordSize)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(c.idleTimeout))
+		n, err := conn.Read(buffer)
+		if err != nil {
+			return
+		}
+		data := bytesx.Get(n)
+		copy(*data, buffer[:n])
+		select {
+		case c.incoming <- dtlsDatagram{data: data, addr: udpAddr}:
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// ReadMsgUDP lets the server's reader detect records larger than its buffer.
+func (c *dtlsPacketConn) ReadMsgUDP(p, _ []byte) (int, int, int, *net.UDPAddr, error) {
+	select {
+	case datagram := <-c.incoming:
+		n := copy(p, *datagram.data)
+		flags := 0
+		if n < len(*datagram.data) {
+			flags = syscall.MSG_TRUNC
+		}
+		bytesx.Put(datagram.data)
+		return n, 0, flags, datagram.addr, nil
+	case <-c.closed:
+		return 0, 0, 0, nil, net.ErrClosed
+	}
+}
+
+func (c *dtlsPacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	n, _, _, addr, err := c.ReadMsgUDP(p, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	return n, addr, nil
+}
+
+func (c *dtlsPacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	if addr == nil {
+		return 0, ErrDTLSSessionNotFound
+	}
+	c.mu.Lock()
+	conn, ok := c.sessions[addr.String()]
+	c.mu.Unlock()
+	if !ok {
+		if c.isClosed() {
+			return 0, net.ErrClosed
+		}
+		return 0, fmt.Errorf("%w: %s", ErrDTLSSessionNotFound, addr)
+	}
+	return conn.Write(p)
+}
+
+func (c *dtlsPacketConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.listener.Close()
+		c.mu.Lock()
+		conns := c.conns
+		c.conns, c.sessions = nil, nil
+		c.mu.Unlock()
+		for conn := range conns {
+			_ = conn.Close()
+		}
+		c.wg.Wait()
+	})
+	return err
+}
+
+func (c *dtlsPacketConn) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *dtlsPacketConn) LocalAddr() net.Addr {
+	return c.listener.Addr()
+}
+
+// Deadlines are not supported; the server stops reads by closing the conn.
+func (c *dtlsPacketConn) SetDeadline(time.Time) error      { return nil }
+func (c *dtlsPacketConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *dtlsPacketConn) SetWriteDeadline(time.Time) error { return nil }
+
+// packetConnOf adapts a connected conn to the PacketConn pion/dtls writes to.
+type packetConnOf struct {
+	net.Conn
+}
+
+func (c packetConnOf) ReadFrom(p []byte) (int, net.Addr, error) {
+	n, err := c.Conn.Read(p)
+	return n, c.Conn.RemoteAddr(), err
+}
+
+func (c packetConnOf) WriteTo(p []byte, _ net.Addr) (int, error) {
+	return c.Conn.Write(p)
+}
+
+// dtlsClientConn gives a client DTLS session the datagram methods Connect
+// requires. A session has a single peer, so WriteTo ignores addr.
+type dtlsClientConn struct {
+	*dtls.Conn
+}
+
+func (c dtlsClientConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	n, err := c.Conn.Read(p)
+	return n, c.Conn.RemoteAddr(), err
+}
+
+func (c dtlsClientConn) WriteTo(p []byte, _ net.Addr) (int, error) {
+	return c.Conn.Write(p)
+}
+
+func dialDTLS(ctx context.Context, rawConn net.Conn, config *dtls.Config, handshakeTimeout time.Duration) (net.Conn, error) {
+	conn, err := dtls.Client(packetConnOf{Conn: rawConn}, rawConn.RemoteAddr(), config)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, handshakeTimeout)
+		defer cancel()
+	}
+	if err := conn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("udpx: dtls handshake: %w", err)
+	}
+	return dtlsClientConn{Conn: conn}, nil
+}
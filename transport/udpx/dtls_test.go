@@ -0,0 +1,136 @@
+package udpx_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/udpx"
+	"github.com/pion/dtls/v3"
+	"github.com/pion/dtls/v3/pkg/crypto/selfsign"
+)
+
+func startDTLSEchoServer(t *testing.T, conf *udpx.ServerConfig) string {
+	t.Helper()
+
+	conf.Addr = "127.0.0.1:0"
+	conf.DisableMetrics = true
+	server := startRealServer(t, conf, func(ctx context.Context, packet udpx.Packet) error {
+		_, err := packet.Reply(ctx, append([]byte("echo:"), packet.Payload()...))
+		return err
+	})
+	return server.PacketConn().LocalAddr().String()
+}
+
+func dtlsRoundTrip(t *testing.T, client udpx.Client, payload string) string {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := client.DialContext(ctx)
+	if err != nil {
+		t.Fatalf("DialContext() error = %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadTimeout(2 * time.Second)
+	if _, err := conn.Write([]byte(payload)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestDTLSWithPSK(t *testing.T) {
+	addr := startDTLSEchoServer(t, &udpx.ServerConfig{
+		DTLS: &udpx.DTLSConfig{PSK: []byte("secret"), PSKIdentity: "device-1"},
+	})
+
+	client := udpx.NewClient(&udpx.ClientConfig{
+		Addr:           addr,
+		DTLS:           &udpx.DTLSConfig{PSK: []byte("secret"), PSKIdentity: "device-1"},
+		DisableMetrics: true,
+	})
+	for _, payload := range []string{"first", "second"} {
+		if got := dtlsRoundTrip(t, client, payload); got != "echo:"+payload {
+			t.Fatalf("reply = %q", got)
+		}
+	}
+
+	wrong := udpx.NewClient(&udpx.ClientConfig{
+		Addr:           addr,
+		DTLS:           &udpx.DTLSConfig{PSK: []byte("secret"), PSKIdentity: "device-2", HandshakeTimeout: time.Second},
+		DisableMetrics: true,
+	})
+	if _, err := wrong.Dial(); err == nil {
+		t.Fatal("Dial() with an unknown psk identity succeeded")
+	}
+}
+
+func TestDTLSWithCertificate(t *testing.T) {
+	certificate, err := selfsign.GenerateSelfSignedWithDNS("localhost")
+	if err != nil {
+		t.Fatalf("generate certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+
+	addr := startDTLSEchoServer(t, &udpx.ServerConfig{
+		DTLS: &udpx.DTLSConfig{Certificates: []tls.Certificate{certificate}},
+	})
+
+	client := udpx.NewClient(&udpx.ClientConfig{
+		Addr:           addr,
+		DTLS:           &udpx.DTLSConfig{CAs: roots, ServerName: "localhost"},
+		DisableMetrics: true,
+	})
+	if got := dtlsRoundTrip(t, client, "ping"); got != "echo:ping" {
+		t.Fatalf("reply = %q", got)
+	}
+
+	untrusted := udpx.NewClient(&udpx.ClientConfig{
+		Addr:           addr,
+		DTLS:           &udpx.DTLSConfig{CAs: x509.NewCertPool(), ServerName: "localhost", HandshakeTimeout: time.Second},
+		DisableMetrics: true,
+	})
+	if _, err := untrusted.Dial(); err == nil {
+		t.Fatal("Dial() trusted an unknown certificate")
+	}
+}
+
+func TestDTLSConfigValidation(t *testing.T) {
+	if _, err := (&udpx.DTLSConfig{}).BuildServer(); !errors.Is(err, udpx.ErrDTLSCredentialsRequired) {
+		t.Fatalf("BuildServer() error = %v", err)
+	}
+
+	server := udpx.NewServer(&udpx.ServerConfig{
+		Addr:           "127.0.0.1:0",
+		DTLSConfig:     &dtls.Config{},
+		DTLS:           &udpx.DTLSConfig{PSK: []byte("k")},
+		DisableMetrics: true,
+	})
+	handler := udpx.HandlerFunc(func(context.Context, udpx.Packet) error { return nil })
+	if err := server.Start(context.Background(), handler); !errors.Is(err, udpx.ErrDTLSConfigConflict) {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	server = udpx.NewServer(&udpx.ServerConfig{
+		PacketConn:     newFakePacketConn(),
+		DTLS:           &udpx.DTLSConfig{PSK: []byte("k")},
+		DisableMetrics: true,
+	})
+	if err := server.Start(context.Background(), handler); !errors.Is(err, udpx.ErrDTLSAddrRequired) {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
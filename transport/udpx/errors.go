@@ -1,6 +1,9 @@
 package udpx
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrContextRequired      = errors.New("udpx: context is required")
@@ -11,6 +14,24 @@ var (
 	ErrClientAddrRequired   = errors.New("udpx: client addr is required")
 	ErrServerAddrRequired   = errors.New("udpx: server addr or packet conn is required")
 	ErrServerAlreadyRunning = errors.New("udpx: server already running")
+	ErrServerNotRunning     = errors.New("udpx: server not running")
+	ErrReusePortUnsupported = errors.New("udpx: SO_REUSEPORT is not supported on this platform")
+	ErrMalformedPacket      = errors.New("udpx: malformed packet")
+	ErrUnsupportedVersion   = fmt.Errorf("%w: unsupported version", ErrMalformedPacket)
+	ErrInvalidSource        = errors.New("udpx: invalid source ip or cidr")
+	ErrInvalidGroup         = errors.New("udpx: invalid multicast group")
+	ErrDownstream           = errors.New("udpx: downstream failure")
+
+	ErrDTLSConfigConflict      = errors.New("udpx: DTLSConfig and DTLS cannot both be set")
+	ErrDTLSCredentialsRequired = errors.New("udpx: dtls certificate or psk is required")
+	ErrInvalidDTLSCA           = errors.New("udpx: invalid dtls ca certificate")
+	ErrDTLSAddrRequired        = errors.New("udpx: dtls server requires addr instead of packet conn")
+	ErrDTLSSessionNotFound     = errors.New("udpx: no dtls session for address")
+
+	ErrRequestTimeout        = errors.New("udpx: request timed out")
+	ErrRequestInFlight       = errors.New("udpx: request with the same correlation key is in flight")
+	ErrRequesterClosed       = errors.New("udpx: requester closed")
+	ErrCorrelationKeyMissing = errors.New("udpx: payload has no correlation key")
 
 	errServerClosed = errors.New("udpx: server closed")
 )
@@ -0,0 +1,254 @@
+package udpx
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+const defaultSourceGuardMaxSources = 65536
+
+type SourceGuardConfig struct {
+	// Rate is the number of packets per second allowed from one source IP;
+	// zero disables rate limiting and leaves only the denylist.
+	Rate float64
+	// Burst defaults to ceil(Rate), at least 1.
+	Burst int
+	// MaxSources caps the number of tracked source buckets; the least recently
+	// seen source is evicted first. Defaults to 65536.
+	MaxSources int
+	// Denylist holds IPs or CIDRs dropped before rate limiting.
+	Denylist          []string
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+}
+
+// SourceGuard drops packets from denied or flooding source IPs. Dropped packets
+// are counted under udpx_server_packets_dropped_total with reason "denied" or
+// "rate_limited" and never reach the handler.
+type SourceGuard struct {
+	rate       rate.Limit
+	burst      int
+	maxSources int
+	now        func() time.Time
+	metrics    *metrics
+
+	mu      sync.Mutex
+	buckets map[netip.Addr]*list.Element
+	lru     *list.List
+
+	denyMu   sync.RWMutex
+	denyIPs  map[netip.Addr]struct{}
+	denyNets []netip.Prefix
+}
+
+type sourceBucket struct {
+	addr    netip.Addr
+	limiter *rate.Limiter
+}
+
+func NewSourceGuard(conf *SourceGuardConfig) (*SourceGuard, error) {
+	if conf == nil {
+		conf = &SourceGuardConfig{}
+	}
+
+	burst := conf.Burst
+	if burst <= 0 {
+		burst = max(1, int(math.Ceil(conf.Rate)))
+	}
+	maxSources := conf.MaxSources
+	if maxSources <= 0 {
+		maxSources = defaultSourceGuardMaxSources
+	}
+	g := &SourceGuard{
+		rate:       rate.Limit(conf.Rate),
+		burst:      burst,
+		maxSources: maxSources,
+		now:        time.Now,
+		buckets:    make(map[netip.Addr]*list.Element),
+		lru:        list.New(),
+		denyIPs:    make(map[netip.Addr]struct{}),
+	}
+	if !conf.DisableMetrics {
+		g.metrics = defaultUDPMetrics()
+		if conf.MetricsRegisterer != nil {
+			g.metrics = newUDPMetrics(conf.MetricsRegisterer)
+		}
+	}
+	if err := g.Deny(conf.Denylist...); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Interceptor returns the interceptor to install with Server.Use.
+func (g *SourceGuard) Interceptor() Interceptor {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, packet Packet) error {
+			addr, ok := sourceIP(packet.RemoteAddr())
+			if ok {
+				if g.denied(addr) {
+					g.drop(packet, "denied")
+					return nil
+				}
+				if g.rate > 0 && !g.allow(addr) {
+					g.drop(packet, "rate_limited")
+					return nil
+				}
+			}
+			return next.Handle(ctx, packet)
+		})
+	}
+}
+
+// Deny adds IPs or CIDRs to the denylist. Either all entries are added or, on
+// a parse error, none are.
+func (g *SourceGuard) Deny(entries ...string) error {
+	ips, nets, err := parseSources(entries)
+	if err != nil {
+		return err
+	}
+
+	g.denyMu.Lock()
+	defer g.denyMu.Unlock()
+
+	for _, ip := range ips {
+		g.denyIPs[ip] = struct{}{}
+	}
+	for _, prefix := range nets {
+		if !slices.Contains(g.denyNets, prefix) {
+			g.denyNets = append(g.denyNets, prefix)
+		}
+	}
+	return nil
+}
+
+// Undeny removes entries previously added with Deny. Entries must match
+// exactly; removing 10.0.0.1 does not carve it out of a denied 10.0.0.0/8.
+func (g *SourceGuard) Undeny(entries ...string) error {
+	ips, nets, err := parseSources(entries)
+	if err != nil {
+		return err
+	}
+
+	g.denyMu.Lock()
+	defer g.denyMu.Unlock()
+
+	for _, ip := range ips {
+		delete(g.denyIPs, ip)
+	}
+	g.denyNets = slices.DeleteFunc(g.denyNets, func(prefix netip.Prefix) bool {
+		return slices.Contains(nets, prefix)
+	})
+	return nil
+}
+
+// Denylist returns the current denylist entries.
+func (g *SourceGuard) Denylist() []string {
+	g.denyMu.RLock()
+	defer g.denyMu.RUnlock()
+
+	entries := make([]string, 0, len(g.denyIPs)+len(g.denyNets))
+	for ip := range g.denyIPs {
+		entries = append(entries, ip.String())
+	}
+	for _, prefix := range g.denyNets {
+		entries = append(entries, prefix.String())
+	}
+	slices.Sort(entries)
+	return entries
+}
+
+func (g *SourceGuard) denied(addr netip.Addr) bool {
+	g.denyMu.RLock()
+	defer g.denyMu.RUnlock()
+
+	if _, ok := g.denyIPs[addr]; ok {
+		return true
+	}
+	for _, prefix := range g.denyNets {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *SourceGuard) allow(addr netip.Addr) bool {
+	now := g.now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if elem, ok := g.buckets[addr]; ok {
+		g.lru.MoveToFront(elem)
+		return elem.Value.(*sourceBucket).limiter.AllowN(now, 1)
+	}
+
+	if g.lru.Len() >= g.maxSources {
+		oldest := g.lru.Back()
+		delete(g.buckets, oldest.Value.(*sourceBucket).addr)
+		g.lru.Remove(oldest)
+	}
+	bucket := &sourceBucket{addr: addr, limiter: rate.NewLimiter(g.rate, g.burst)}
+	g.buckets[addr] = g.lru.PushFront(bucket)
+	return bucket.limiter.AllowN(now, 1)
+}
+
+func (g *SourceGuard) drop(packet Packet, reason string) {
+	if g.metrics == nil {
+		return
+	}
+	addrLabel := ""
+	if local := packet.LocalAddr(); local != nil {
+		addrLabel = local.String()
+	}
+	g.metrics.serverPacketsDropped.WithLabelValues(addrLabel, reason).Inc()
+}
+
+func sourceIP(addr net.Addr) (netip.Addr, bool) {
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		ip, ok := netip.AddrFromSlice(udpAddr.IP)
+		return ip.Unmap(), ok
+	}
+	host, _ := splitAddr(addr)
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+func parseSources(entries []string) ([]netip.Addr, []netip.Prefix, error) {
+	var (
+		ips  []netip.Addr
+		nets []netip.Prefix
+	)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: %q", ErrInvalidSource, entry)
+			}
+			nets = append(nets, prefix.Masked())
+			continue
+		}
+		ip, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %q", ErrInvalidSource, entry)
+		}
+		ips = append(ips, ip.Unmap())
+	}
+	return ips, nets, nil
+}
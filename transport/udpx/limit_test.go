@@ -0,0 +1,124 @@
+package udpx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/bang-go/micro/transport/udpx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type stubPacket struct {
+	udpx.Packet
+	remote net.Addr
+}
+
+func (p stubPacket) RemoteAddr() net.Addr { return p.remote }
+func (p stubPacket) LocalAddr() net.Addr  { return testAddr("127.0.0.1:9999") }
+
+func TestSourceGuardRateLimitsPerSourceAndEvictsLRU(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	guard, err := udpx.NewSourceGuard(&udpx.SourceGuardConfig{
+		Rate:              0.001,
+		Burst:             2,
+		MaxSources:        2,
+		MetricsRegisterer: reg,
+	})
+	if err != nil {
+		t.Fatalf("NewSourceGuard() error = %v", err)
+	}
+
+	handled := map[string]int{}
+	handler := guard.Interceptor()(udpx.HandlerFunc(func(_ context.Context, packet udpx.Packet) error {
+		handled[packet.RemoteAddr().String()]++
+		return nil
+	}))
+	send := func(ip string) {
+		addr := &net.UDPAddr{IP: net.ParseIP(ip), Port: 5000}
+		if err := handler.Handle(context.Background(), stubPacket{remote: addr}); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+	}
+
+	for range 3 {
+		send("10.0.0.1")
+	}
+	send("10.0.0.2")
+	// A third source evicts 10.0.0.1, the least recently seen, so it starts
+	// again with a full bucket.
+	send("10.0.0.3")
+	send("10.0.0.1")
+
+	if handled["10.0.0.1:5000"] != 3 || handled["10.0.0.2:5000"] != 1 || handled["10.0.0.3:5000"] != 1 {
+		t.Fatalf("handled = %v", handled)
+	}
+	if got := droppedPackets(t, reg, "rate_limited"); got != 1 {
+		t.Fatalf("rate limited drops = %v, want 1", got)
+	}
+}
+
+func TestSourceGuardDenylist(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	guard, err := udpx.NewSourceGuard(&udpx.SourceGuardConfig{
+		Denylist:          []string{"192.168.0.0/16"},
+		MetricsRegisterer: reg,
+	})
+	if err != nil {
+		t.Fatalf("NewSourceGuard() error = %v", err)
+	}
+
+	handled := 0
+	handler := guard.Interceptor()(udpx.HandlerFunc(func(context.Context, udpx.Packet) error {
+		handled++
+		return nil
+	}))
+	send := func(ip string) {
+		addr := &net.UDPAddr{IP: net.ParseIP(ip), Port: 5000}
+		if err := handler.Handle(context.Background(), stubPacket{remote: addr}); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+	}
+
+	send("192.168.1.1")
+	send("10.0.0.1")
+	if err := guard.Deny("10.0.0.1", "::ffff:10.0.0.2"); err != nil {
+		t.Fatalf("Deny() error = %v", err)
+	}
+	send("10.0.0.1")
+	send("10.0.0.2")
+	if got := guard.Denylist(); !slices.Equal(got, []string{"10.0.0.1", "10.0.0.2", "192.168.0.0/16"}) {
+		t.Fatalf("Denylist() = %v", got)
+	}
+	if err := guard.Undeny("10.0.0.1", "192.168.0.0/16"); err != nil {
+		t.Fatalf("Undeny() error = %v", err)
+	}
+	send("10.0.0.1")
+	send("192.168.1.1")
+
+	if handled != 3 {
+		t.Fatalf("handled = %d, want 3", handled)
+	}
+	if got := droppedPackets(t, reg, "denied"); got != 3 {
+		t.Fatalf("denied drops = %v, want 3", got)
+	}
+
+	if err := guard.Deny("10.0.0.9", "not-an-ip"); !errors.Is(err, udpx.ErrInvalidSource) {
+		t.Fatalf("Deny(invalid) error = %v", err)
+	}
+	if slices.Contains(guard.Denylist(), "10.0.0.9") {
+		t.Fatal("Deny() applied entries despite an invalid one")
+	}
+	if _, err := udpx.NewSourceGuard(&udpx.SourceGuardConfig{Denylist: []string{"10.0.0.0/33"}}); !errors.Is(err, udpx.ErrInvalidSource) {
+		t.Fatalf("NewSourceGuard(invalid) error = %v", err)
+	}
+}
+
+func droppedPackets(t *testing.T, reg *prometheus.Registry, reason string) float64 {
+	t.Helper()
+	dropped := registeredCounterVec(t, reg, "udpx_server_packets_dropped_total", "Total number of UDP packets dropped by the server.", "addr", "reason")
+	return testutil.ToFloat64(dropped.WithLabelValues("127.0.0.1:9999", reason))
+}
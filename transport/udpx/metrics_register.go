@@ -9,12 +9,18 @@ import (
 type metrics struct {
 	clientDialDuration    *prometheus.HistogramVec
 	clientDialsTotal      *prometheus.CounterVec
+	clientRequestsTotal   *prometheus.CounterVec
+	clientRequestDuration *prometheus.HistogramVec
+	clientRetransmits     *prometheus.CounterVec
+	clientUnmatched       *prometheus.CounterVec
 	serverPacketsReceived *prometheus.CounterVec
 	serverPacketsHandled  *prometheus.CounterVec
 	serverPacketsDropped  *prometheus.CounterVec
 	serverPacketDuration  *prometheus.HistogramVec
 	serverBytesRead       *prometheus.CounterVec
 	serverBytesWritten    *prometheus.CounterVec
+	serverPacketsSent     *prometheus.CounterVec
+	serverHandlerErrors   *prometheus.CounterVec
 }
 
 var (
@@ -46,6 +52,35 @@ func newUDPMetrics(registerer prometheus.Registerer) *metrics {
 			},
 			[]string{"result"},
 		),
+		clientRequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "udpx_client_requests_total",
+				Help: "Total number of UDP requests completed by requesters.",
+			},
+			[]string{"addr", "result"},
+		),
+		clientRequestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "udpx_client_request_duration_seconds",
+				Help:    "UDP request round-trip duration in seconds, including retransmissions.",
+				Buckets: []float64{0.0005, 0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+			},
+			[]string{"addr", "result"},
+		),
+		clientRetransmits: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "udpx_client_retransmissions_total",
+				Help: "Total number of UDP request retransmissions.",
+			},
+			[]string{"addr"},
+		),
+		clientUnmatched: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "udpx_client_unmatched_responses_total",
+				Help: "Total number of UDP responses that matched no in-flight request.",
+			},
+			[]string{"addr"},
+		),
 		serverPacketsReceived: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "udpx_server_packets_received_total",
@@ -89,16 +124,36 @@ func newUDPMetrics(registerer prometheus.Registerer) *metrics {
 			},
 			[]string{"addr"},
 		),
+		serverPacketsSent: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "udpx_server_packets_sent_total",
+				Help: "Total number of UDP packets written by the server.",
+			},
+			[]string{"addr", "result"},
+		),
+		serverHandlerErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "udpx_server_handler_errors_total",
+				Help: "Total number of UDP handler errors by kind.",
+			},
+			[]string{"addr", "kind"},
+		),
 	}
 
 	mustRegisterCollector(registerer, &m.clientDialDuration, m.clientDialDuration)
 	mustRegisterCollector(registerer, &m.clientDialsTotal, m.clientDialsTotal)
+	mustRegisterCollector(registerer, &m.clientRequestsTotal, m.clientRequestsTotal)
+	mustRegisterCollector(registerer, &m.clientRequestDuration, m.clientRequestDuration)
+	mustRegisterCollector(registerer, &m.clientRetransmits, m.clientRetransmits)
+	mustRegisterCollector(registerer, &m.clientUnmatched, m.clientUnmatched)
 	mustRegisterCollector(registerer, &m.serverPacketsReceived, m.serverPacketsReceived)
 	mustRegisterCollector(registerer, &m.serverPacketsHandled, m.serverPacketsHandled)
 	mustRegisterCollector(registerer, &m.serverPacketsDropped, m.serverPacketsDropped)
 	mustRegisterCollector(registerer, &m.serverPacketDuration, m.serverPacketDuration)
 	mustRegisterCollector(registerer, &m.serverBytesRead, m.serverBytesRead)
 	mustRegisterCollector(registerer, &m.serverBytesWritten, m.serverBytesWritten)
+	mustRegisterCollector(registerer, &m.serverPacketsSent, m.serverPacketsSent)
+	mustRegisterCollector(registerer, &m.serverHandlerErrors, m.serverHandlerErrors)
 
 	return m
 }
@@ -0,0 +1,220 @@
+package udpx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const defaultMulticastTTL = 1
+
+type MulticastConfig struct {
+	// Groups are joined when the socket opens, e.g. "239.255.0.1" or "ff02::fb".
+	Groups []string
+	// Interface names the interface to join on and send from; empty uses the
+	// system default.
+	Interface string
+	// TTL is the hop limit of outgoing multicast; defaults to 1, the local
+	// network.
+	TTL int
+	// Loopback delivers outgoing multicast to listeners on the same host.
+	Loopback bool
+}
+
+// ListenMulticast opens a UDP socket on addr, typically ":port", and joins
+// conf.Groups. The socket sets SO_REUSEADDR (and SO_REUSEPORT where
+// available) so several processes on one host can listen to the same group.
+// Serve it with Server.Serve, or set ServerConfig.Multicast to let Start do it.
+func ListenMulticast(ctx context.Context, addr string, conf *MulticastConfig) (*net.UDPConn, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if conf == nil {
+		conf = &MulticastConfig{}
+	}
+	groups, err := parseGroups(conf.Groups)
+	if err != nil {
+		return nil, err
+	}
+	ifi, err := multicastInterface(conf.Interface)
+	if err != nil {
+		return nil, err
+	}
+
+	// Multicast options are per address family, so the socket is bound to the
+	// groups' family, or to addr's when no group is joined up front.
+	network := "udp4"
+	if len(groups) > 0 {
+		if groups[0].Is6() {
+			network = "udp6"
+		}
+	} else if host, _, err := net.SplitHostPort(addr); err == nil && strings.Contains(host, ":") {
+		network = "udp6"
+	}
+	config := net.ListenConfig{Control: multicastControl}
+	packetConn, err := config.ListenPacket(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	conn := packetConn.(*net.UDPConn)
+
+	if err := setMulticastOptions(conn, network != "udp6", ifi, conf); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	for _, group := range groups {
+		if err := joinGroup(conn, group, ifi); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// JoinGroup joins group on conn, on the named interface or the system default.
+func JoinGroup(conn *net.UDPConn, group string, iface string) error {
+	addr, ifi, err := resolveGroup(conn, group, iface)
+	if err != nil {
+		return err
+	}
+	return joinGroup(conn, addr, ifi)
+}
+
+// LeaveGroup leaves a group previously joined with the same interface.
+func LeaveGroup(conn *net.UDPConn, group string, iface string) error {
+	addr, ifi, err := resolveGroup(conn, group, iface)
+	if err != nil {
+		return err
+	}
+	udpAddr := &net.UDPAddr{IP: addr.AsSlice()}
+	if addr.Is4() {
+		return ipv4.NewPacketConn(conn).LeaveGroup(ifi, udpAddr)
+	}
+	return ipv6.NewPacketConn(conn).LeaveGroup(ifi, udpAddr)
+}
+
+// SendMulticast sends payload to a multicast or broadcast addr from an
+// ephemeral socket, applying conf's interface, TTL and loopback settings.
+// Replies, if any, go to that socket and are discarded; protocols expecting
+// answers should send from the serving socket with Packet.WriteTo instead.
+func SendMulticast(ctx context.Context, addr string, payload []byte, conf *MulticastConfig) (int, error) {
+	if err := validateContext(ctx); err != nil {
+		return 0, err
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if conf == nil {
+		conf = &MulticastConfig{}
+	}
+	dst, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return 0, err
+	}
+	ifi, err := multicastInterface(conf.Interface)
+	if err != nil {
+		return 0, err
+	}
+
+	is4 := dst.IP.To4() != nil
+	network := "udp6"
+	if is4 {
+		network = "udp4"
+	}
+	var config net.ListenConfig
+	packetConn, err := config.ListenPacket(ctx, network, ":0")
+	if err != nil {
+		return 0, err
+	}
+	conn := packetConn.(*net.UDPConn)
+	defer conn.Close()
+
+	if err := setMulticastOptions(conn, is4, ifi, conf); err != nil {
+		return 0, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetWriteDeadline(deadline)
+	}
+	return conn.WriteToUDP(payload, dst)
+}
+
+func setMulticastOptions(conn *net.UDPConn, is4 bool, ifi *net.Interface, conf *MulticastConfig) error {
+	ttl := conf.TTL
+	if ttl <= 0 {
+		ttl = defaultMulticastTTL
+	}
+	if is4 {
+		p := ipv4.NewPacketConn(conn)
+		if ifi != nil {
+			if err := p.SetMulticastInterface(ifi); err != nil {
+				return err
+			}
+		}
+		if err := p.SetMulticastTTL(ttl); err != nil {
+			return err
+		}
+		return p.SetMulticastLoopback(conf.Loopback)
+	}
+	p := ipv6.NewPacketConn(conn)
+	if ifi != nil {
+		if err := p.SetMulticastInterface(ifi); err != nil {
+			return err
+		}
+	}
+	if err := p.SetMulticastHopLimit(ttl); err != nil {
+		return err
+	}
+	return p.SetMulticastLoopback(conf.Loopback)
+}
+
+func joinGroup(conn *net.UDPConn, group netip.Addr, ifi *net.Interface) error {
+	udpAddr := &net.UDPAddr{IP: group.AsSlice()}
+	if group.Is4() {
+		return ipv4.NewPacketConn(conn).JoinGroup(ifi, udpAddr)
+	}
+	return ipv6.NewPacketConn(conn).JoinGroup(ifi, udpAddr)
+}
+
+func resolveGroup(conn *net.UDPConn, group string, iface string) (netip.Addr, *net.Interface, error) {
+	if conn == nil {
+		return netip.Addr{}, nil, ErrNilPacketConn
+	}
+	groups, err := parseGroups([]string{group})
+	if err != nil {
+		return netip.Addr{}, nil, err
+	}
+	ifi, err := multicastInterface(iface)
+	if err != nil {
+		return netip.Addr{}, nil, err
+	}
+	return groups[0], ifi, nil
+}
+
+func parseGroups(groups []string) ([]netip.Addr, error) {
+	addrs := make([]netip.Addr, 0, len(groups))
+	for _, group := range groups {
+		addr, err := netip.ParseAddr(strings.TrimSpace(group))
+		if err != nil || !addr.Unmap().IsMulticast() {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidGroup, group)
+		}
+		addr = addr.Unmap()
+		if len(addrs) > 0 && addr.Is4() != addrs[0].Is4() {
+			return nil, fmt.Errorf("%w: %q mixes address families", ErrInvalidGroup, group)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+func multicastInterface(name string) (*net.Interface, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+	return net.InterfaceByName(name)
+}
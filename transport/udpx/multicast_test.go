@@ -0,0 +1,112 @@
+package udpx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/udpx"
+)
+
+const testGroup = "239.255.77.1"
+
+// loopbackMulticast returns a config sending and receiving multicast on the
+// loopback interface, skipping the test where the host does not support it.
+func loopbackMulticast(t *testing.T) *udpx.MulticastConfig {
+	t.Helper()
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		t.Skipf("list interfaces: %v", err)
+	}
+	for _, ifi := range ifaces {
+		if ifi.Flags&net.FlagLoopback == 0 || ifi.Flags&net.FlagUp == 0 {
+			continue
+		}
+		conf := &udpx.MulticastConfig{Groups: []string{testGroup}, Interface: ifi.Name, Loopback: true}
+		conn, err := udpx.ListenMulticast(context.Background(), ":0", conf)
+		if err != nil {
+			t.Skipf("multicast unsupported on %s: %v", ifi.Name, err)
+		}
+		_ = conn.Close()
+		return conf
+	}
+	t.Skip("no loopback interface")
+	return nil
+}
+
+func TestServerMulticastJoinAndLeave(t *testing.T) {
+	conf := loopbackMulticast(t)
+
+	received := make(chan string, 4)
+	server := startRealServer(t, &udpx.ServerConfig{
+		Addr:           ":0",
+		Multicast:      conf,
+		DisableMetrics: true,
+	}, func(_ context.Context, packet udpx.Packet) error {
+		received <- string(packet.Payload())
+		return nil
+	})
+	port := strconv.Itoa(server.PacketConn().LocalAddr().(*net.UDPAddr).Port)
+	send := func(payload string) {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if _, err := udpx.SendMulticast(ctx, net.JoinHostPort(testGroup, port), []byte(payload), conf); err != nil {
+			t.Fatalf("SendMulticast() error = %v", err)
+		}
+	}
+
+	send("hello")
+	select {
+	case got := <-received:
+		if got != "hello" {
+			t.Fatalf("received %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("multicast datagram was not delivered")
+	}
+
+	if err := server.LeaveGroup(testGroup); err != nil {
+		t.Fatalf("LeaveGroup() error = %v", err)
+	}
+	send("after leave")
+	select {
+	case got := <-received:
+		t.Fatalf("received %q after leaving the group", got)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := server.JoinGroup(testGroup); err != nil {
+		t.Fatalf("JoinGroup() error = %v", err)
+	}
+	send("rejoined")
+	select {
+	case got := <-received:
+		if got != "rejoined" {
+			t.Fatalf("received %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("multicast datagram was not delivered after rejoining")
+	}
+
+	if err := server.JoinGroup("10.0.0.1"); !errors.Is(err, udpx.ErrInvalidGroup) {
+		t.Fatalf("JoinGroup(unicast) error = %v", err)
+	}
+}
+
+func TestMulticastValidation(t *testing.T) {
+	server := udpx.NewServer(&udpx.ServerConfig{Addr: ":0", DisableMetrics: true})
+	if err := server.JoinGroup(testGroup); !errors.Is(err, udpx.ErrServerNotRunning) {
+		t.Fatalf("JoinGroup() before start error = %v", err)
+	}
+
+	for _, groups := range [][]string{{"192.168.1.1"}, {"bogus"}, {testGroup, "ff02::fb"}} {
+		if _, err := udpx.ListenMulticast(context.Background(), ":0", &udpx.MulticastConfig{Groups: groups}); !errors.Is(err, udpx.ErrInvalidGroup) {
+			t.Fatalf("ListenMulticast(%v) error = %v", groups, err)
+		}
+	}
+}
@@ -3,6 +3,10 @@ package udpx
 import (
 	"context"
 	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bang-go/micro/pkg/bytesx"
 )
 
 type Packet interface {
@@ -19,10 +23,16 @@ type packetEntity struct {
 	remote     net.Addr
 	local      net.Addr
 	packetConn net.PacketConn
-	onWrite    func(int)
+	onWrite    func(int, error)
+	codec      Codec
+
+	// buffer is set when payload aliases a pooled read buffer.
+	buffer   *[]byte
+	pool     *bufferPool
+	retained atomic.Bool
 }
 
-func newPacket(payload []byte, remote, local net.Addr, packetConn net.PacketConn, onWrite func(int)) Packet {
+func newPacket(payload []byte, remote, local net.Addr, packetConn net.PacketConn, onWrite func(int, error)) *packetEntity {
 	return &packetEntity{
 		payload:    payload,
 		remote:     remote,
@@ -55,9 +65,25 @@ func (p *packetEntity) WriteTo(ctx context.Context, data []byte, addr net.Addr)
 	if err := ctx.Err(); err != nil {
 		return 0, err
 	}
-	n, err := p.packetConn.WriteTo(data, addr)
-	if n > 0 && p.onWrite != nil {
-		p.onWrite(n)
+	frame := data
+	if encoder, ok := p.codec.(appendEncoder); ok {
+		buffer := bytesx.Get(encoder.encodedSize(data))
+		defer bytesx.Put(buffer)
+		frame = encoder.appendEncode((*buffer)[:0], data)
+	} else if p.codec != nil {
+		encoded, err := p.codec.Encode(data)
+		if err != nil {
+			return 0, err
+		}
+		frame = encoded
+	}
+	n, err := p.packetConn.WriteTo(frame, addr)
+	if p.onWrite != nil {
+		p.onWrite(n, err)
+	}
+	// Report payload bytes like an unframed write; a datagram is all or nothing.
+	if err == nil && p.codec != nil {
+		n = len(data)
 	}
 	return n, err
 }
@@ -65,3 +91,28 @@ func (p *packetEntity) WriteTo(ctx context.Context, data []byte, addr net.Addr)
 func (p *packetEntity) PacketConn() net.PacketConn {
 	return p.packetConn
 }
+
+// Retain keeps the payload of a packet from a PooledBuffers server valid after
+// the handler returns. It must be called before the handler returns; the
+// returned release func hands the buffer back to the pool and is safe to call
+// more than once. For other packets it returns a no-op.
+func Retain(packet Packet) (release func()) {
+	p, ok := packet.(*packetEntity)
+	if !ok || p.buffer == nil || !p.retained.CompareAndSwap(false, true) {
+		return func() {}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(p.putBuffer)
+	}
+}
+
+func releasePacket(packet Packet) {
+	if p, ok := packet.(*packetEntity); ok && p.buffer != nil && !p.retained.Load() {
+		p.putBuffer()
+	}
+}
+
+func (p *packetEntity) putBuffer() {
+	p.pool.put(p.buffer)
+}
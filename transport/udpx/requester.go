@@ -0,0 +1,295 @@
+package udpx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const defaultRequestTimeout = time.Second
+
+// CorrelateFunc extracts the key that pairs a request with its response, e.g.
+// the transaction ID of a DNS message. It is applied to both directions.
+type CorrelateFunc func(payload []byte) (key string, ok bool)
+
+type Requester interface {
+	Request(context.Context, []byte) ([]byte, error)
+	LocalAddr() net.Addr
+	RemoteAddr() net.Addr
+	Close() error
+}
+
+type RequesterConfig struct {
+	Addr   string
+	Client Client
+	// Correlate enables concurrent requests. Without it requests are
+	// serialized and the next datagram from the peer is the response.
+	Correlate CorrelateFunc
+	// Timeout bounds each attempt; defaults to 1s.
+	Timeout time.Duration
+	// Retries is the number of retransmissions after the first attempt.
+	Retries           int
+	MaxPacketSize     int
+	Logger            *logger.Logger
+	EnableLogger      bool
+	MetricsRegisterer prometheus.Registerer
+	DisableMetrics    bool
+}
+
+type requesterEntity struct {
+	config  *RequesterConfig
+	conn    Connect
+	metrics *metrics
+	addr    string
+
+	serial sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan []byte
+	closed  bool
+	done    chan struct{}
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewRequester dials the peer and starts a read loop that dispatches
+// responses to in-flight requests.
+func NewRequester(ctx context.Context, conf *RequesterConfig) (Requester, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if conf == nil {
+		return nil, ErrClientAddrRequired
+	}
+
+	cloned := *conf
+	if cloned.Client == nil {
+		if cloned.Addr == "" {
+			return nil, ErrClientAddrRequired
+		}
+		cloned.Client = NewClient(&ClientConfig{
+			Addr:              cloned.Addr,
+			Logger:            cloned.Logger,
+			MetricsRegisterer: cloned.MetricsRegisterer,
+			DisableMetrics:    cloned.DisableMetrics,
+		})
+	}
+	if cloned.Logger == nil {
+		cloned.Logger = logger.New(logger.WithLevel("info"))
+	}
+	if cloned.Timeout <= 0 {
+		cloned.Timeout = defaultRequestTimeout
+	}
+	if cloned.Retries < 0 {
+		cloned.Retries = 0
+	}
+	if cloned.MaxPacketSize <= 0 {
+		cloned.MaxPacketSize = defaultServerMaxPacketSize
+	}
+
+	var metrics *metrics
+	if !cloned.DisableMetrics {
+		metrics = defaultUDPMetrics()
+		if cloned.MetricsRegisterer != nil {
+			metrics = newUDPMetrics(cloned.MetricsRegisterer)
+		}
+	}
+
+	conn, err := cloned.Client.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The read loop blocks until Close; per-attempt timeouts are enforced by Request.
+	conn.SetReadTimeout(0)
+
+	r := &requesterEntity{
+		config:  &cloned,
+		conn:    conn,
+		metrics: metrics,
+		addr:    conn.RemoteAddr().String(),
+		pending: make(map[string]chan []byte),
+		done:    make(chan struct{}),
+	}
+	go r.readLoop()
+	return r, nil
+}
+
+func (r *requesterEntity) Request(ctx context.Context, payload []byte) ([]byte, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	response, err := r.request(ctx, payload)
+	if r.metrics != nil {
+		result := requestResult(err)
+		r.metrics.clientRequestsTotal.WithLabelValues(r.addr, result).Inc()
+		r.metrics.clientRequestDuration.WithLabelValues(r.addr, result).Observe(time.Since(start).Seconds())
+	}
+	return response, err
+}
+
+func (r *requesterEntity) request(ctx context.Context, payload []byte) ([]byte, error) {
+	key := ""
+	if r.config.Correlate != nil {
+		var ok bool
+		if key, ok = r.config.Correlate(payload); !ok {
+			return nil, ErrCorrelationKeyMissing
+		}
+	} else {
+		r.serial.Lock()
+		defer r.serial.Unlock()
+	}
+
+	respCh, err := r.register(key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.unregister(key)
+
+	for attempt := 0; attempt <= r.config.Retries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if attempt > 0 && r.metrics != nil {
+			r.metrics.clientRetransmits.WithLabelValues(r.addr).Inc()
+		}
+		if _, err := r.conn.Write(payload); err != nil {
+			if r.isClosed() {
+				return nil, ErrRequesterClosed
+			}
+			return nil, err
+		}
+
+		timer := time.NewTimer(r.config.Timeout)
+		select {
+		case response := <-respCh:
+			timer.Stop()
+			return response, nil
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-r.done:
+			timer.Stop()
+			return nil, ErrRequesterClosed
+		}
+	}
+	return nil, ErrRequestTimeout
+}
+
+func (r *requesterEntity) register(key string) (chan []byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, ErrRequesterClosed
+	}
+	if _, ok := r.pending[key]; ok {
+		return nil, ErrRequestInFlight
+	}
+	ch := make(chan []byte, 1)
+	r.pending[key] = ch
+	return ch, nil
+}
+
+func (r *requesterEntity) unregister(key string) {
+	r.mu.Lock()
+	delete(r.pending, key)
+	r.mu.Unlock()
+}
+
+func (r *requesterEntity) readLoop() {
+	buf := make([]byte, r.config.MaxPacketSize)
+	for {
+		n, err := r.conn.Read(buf)
+		if err != nil {
+			if r.isClosed() || isClosedConnectionError(err) {
+				return
+			}
+			if isTemporaryNetError(err) {
+				continue
+			}
+			if r.config.EnableLogger {
+				r.config.Logger.Warn(context.Background(), "udp requester read failed", "addr", r.addr, "error", err)
+			}
+			continue
+		}
+		r.dispatch(append([]byte(nil), buf[:n]...))
+	}
+}
+
+func (r *requesterEntity) dispatch(response []byte) {
+	key := ""
+	if r.config.Correlate != nil {
+		var ok bool
+		if key, ok = r.config.Correlate(response); !ok {
+			r.unmatched()
+			return
+		}
+	}
+
+	r.mu.Lock()
+	ch, ok := r.pending[key]
+	r.mu.Unlock()
+	if !ok {
+		r.unmatched()
+		return
+	}
+	select {
+	case ch <- response:
+	default:
+		// A retransmission produced a duplicate response.
+		r.unmatched()
+	}
+}
+
+func (r *requesterEntity) unmatched() {
+	if r.metrics != nil {
+		r.metrics.clientUnmatched.WithLabelValues(r.addr).Inc()
+	}
+}
+
+func (r *requesterEntity) LocalAddr() net.Addr {
+	return r.conn.LocalAddr()
+}
+
+func (r *requesterEntity) RemoteAddr() net.Addr {
+	return r.conn.RemoteAddr()
+}
+
+func (r *requesterEntity) Close() error {
+	r.closeOnce.Do(func() {
+		r.mu.Lock()
+		r.closed = true
+		r.mu.Unlock()
+		close(r.done)
+		r.closeErr = r.conn.Close()
+	})
+	return r.closeErr
+}
+
+func (r *requesterEntity) isClosed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed
+}
+
+func requestResult(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, ErrRequestTimeout):
+		return "timeout"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
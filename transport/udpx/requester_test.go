@@ -0,0 +1,247 @@
+package udpx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bang-go/micro/transport/udpx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// startUDPPeer answers "key:body" datagrams with "key:BODY" after handle
+// decides whether and when to respond.
+func startUDPPeer(t *testing.T, handle func(payload string, seen int) (reply bool, delay time.Duration)) net.Addr {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		var mu sync.Mutex
+		seen := map[string]int{}
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			payload := string(buf[:n])
+			mu.Lock()
+			seen[payload]++
+			count := seen[payload]
+			mu.Unlock()
+
+			reply, delay := handle(payload, count)
+			if !reply {
+				continue
+			}
+			key, body, _ := strings.Cut(payload, ":")
+			go func() {
+				time.Sleep(delay)
+				_, _ = conn.WriteTo([]byte(key+":"+strings.ToUpper(body)), addr)
+			}()
+		}
+	}()
+	return conn.LocalAddr()
+}
+
+func correlateByPrefix(payload []byte) (string, bool) {
+	key, _, ok := strings.Cut(string(payload), ":")
+	return key, ok && key != ""
+}
+
+func gatheredUDPCounter(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metric:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if want, ok := labels[label.GetName()]; ok && want != label.GetValue() {
+					continue metric
+				}
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestRequesterMatchesConcurrentResponses(t *testing.T) {
+	addr := startUDPPeer(t, func(payload string, _ int) (bool, time.Duration) {
+		// Answer the first request last so responses arrive out of order.
+		if strings.HasPrefix(payload, "1:") {
+			return true, 50 * time.Millisecond
+		}
+		return true, 0
+	})
+	reg := prometheus.NewRegistry()
+	requester, err := udpx.NewRequester(context.Background(), &udpx.RequesterConfig{
+		Addr:              addr.String(),
+		Correlate:         correlateByPrefix,
+		Timeout:           time.Second,
+		MetricsRegisterer: reg,
+	})
+	if err != nil {
+		t.Fatalf("NewRequester() error = %v", err)
+	}
+	defer requester.Close()
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"1", "2", "3"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := requester.Request(context.Background(), []byte(key+":ping"))
+			if err != nil {
+				t.Errorf("Request(%s) error = %v", key, err)
+				return
+			}
+			if got, want := string(response), key+":PING"; got != want {
+				t.Errorf("Request(%s) = %q, want %q", key, got, want)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := gatheredUDPCounter(t, reg, "udpx_client_requests_total", map[string]string{"result": "ok"}); got != 3 {
+		t.Fatalf("udpx_client_requests_total{result=ok} = %v, want 3", got)
+	}
+}
+
+func TestRequesterRetransmitsAndTimesOut(t *testing.T) {
+	addr := startUDPPeer(t, func(payload string, seen int) (bool, time.Duration) {
+		switch {
+		case strings.HasPrefix(payload, "lossy:"):
+			return seen > 1, 0
+		default:
+			return false, 0
+		}
+	})
+	reg := prometheus.NewRegistry()
+	requester, err := udpx.NewRequester(context.Background(), &udpx.RequesterConfig{
+		Addr:              addr.String(),
+		Correlate:         correlateByPrefix,
+		Timeout:           50 * time.Millisecond,
+		Retries:           2,
+		MetricsRegisterer: reg,
+	})
+	if err != nil {
+		t.Fatalf("NewRequester() error = %v", err)
+	}
+	defer requester.Close()
+
+	response, err := requester.Request(context.Background(), []byte("lossy:ping"))
+	if err != nil || string(response) != "lossy:PING" {
+		t.Fatalf("Request(lossy) = %q, %v", response, err)
+	}
+
+	start := time.Now()
+	if _, err := requester.Request(context.Background(), []byte("dead:ping")); !errors.Is(err, udpx.ErrRequestTimeout) {
+		t.Fatalf("Request(dead) error = %v, want %v", err, udpx.ErrRequestTimeout)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("Request(dead) returned after %v, want all 3 attempts", elapsed)
+	}
+
+	if got := gatheredUDPCounter(t, reg, "udpx_client_retransmissions_total", nil); got != 3 {
+		t.Fatalf("udpx_client_retransmissions_total = %v, want 3", got)
+	}
+	if got := gatheredUDPCounter(t, reg, "udpx_client_requests_total", map[string]string{"result": "timeout"}); got != 1 {
+		t.Fatalf("udpx_client_requests_total{result=timeout} = %v, want 1", got)
+	}
+}
+
+func TestRequesterSerializesWithoutCorrelation(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	addr := startUDPPeer(t, func(string, int) (bool, time.Duration) {
+		if n := inFlight.Add(1); n > maxInFlight.Load() {
+			maxInFlight.Store(n)
+		}
+		time.AfterFunc(5*time.Millisecond, func() { inFlight.Add(-1) })
+		return true, 10 * time.Millisecond
+	})
+	requester, err := udpx.NewRequester(context.Background(), &udpx.RequesterConfig{
+		Addr:           addr.String(),
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("NewRequester() error = %v", err)
+	}
+	defer requester.Close()
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := requester.Request(context.Background(), []byte("x:ping")); err != nil {
+				t.Errorf("Request() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := maxInFlight.Load(); got != 1 {
+		t.Fatalf("max in-flight requests = %d, want 1", got)
+	}
+}
+
+func TestRequesterRejectsInvalidRequests(t *testing.T) {
+	addr := startUDPPeer(t, func(string, int) (bool, time.Duration) { return false, 0 })
+	requester, err := udpx.NewRequester(context.Background(), &udpx.RequesterConfig{
+		Addr:           addr.String(),
+		Correlate:      correlateByPrefix,
+		Timeout:        time.Second,
+		DisableMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("NewRequester() error = %v", err)
+	}
+
+	if _, err := requester.Request(context.Background(), []byte("no-key")); !errors.Is(err, udpx.ErrCorrelationKeyMissing) {
+		t.Fatalf("Request(no key) error = %v, want %v", err, udpx.ErrCorrelationKeyMissing)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := requester.Request(context.Background(), []byte("k:first"))
+		errCh <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	if _, err := requester.Request(context.Background(), []byte("k:second")); !errors.Is(err, udpx.ErrRequestInFlight) {
+		t.Fatalf("Request(duplicate key) error = %v, want %v", err, udpx.ErrRequestInFlight)
+	}
+
+	if err := requester.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := <-errCh; !errors.Is(err, udpx.ErrRequesterClosed) {
+		t.Fatalf("pending Request() error = %v, want %v", err, udpx.ErrRequesterClosed)
+	}
+	if _, err := requester.Request(context.Background(), []byte("k:after")); !errors.Is(err, udpx.ErrRequesterClosed) {
+		t.Fatalf("Request() after Close error = %v, want %v", err, udpx.ErrRequesterClosed)
+	}
+
+	if _, err := udpx.NewRequester(nil, &udpx.RequesterConfig{Addr: addr.String()}); !errors.Is(err, udpx.ErrContextRequired) {
+		t.Fatalf("NewRequester(nil ctx) error = %v", err)
+	}
+	if _, err := udpx.NewRequester(context.Background(), &udpx.RequesterConfig{}); !errors.Is(err, udpx.ErrClientAddrRequired) {
+		t.Fatalf("NewRequester(no addr) error = %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package udpx
+
+import "syscall"
+
+func reusePortControl(_, _ string, _ syscall.RawConn) error {
+	return ErrReusePortUnsupported
+}
+
+// multicastControl leaves socket options untouched; only one process per host
+// can then listen on a multicast port.
+func multicastControl(_, _ string, _ syscall.RawConn) error {
+	return nil
+}
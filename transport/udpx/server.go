@@ -7,10 +7,11 @@ import (
 	"net"
 	"runtime/debug"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	"time"
 
 	"github.com/bang-go/micro/telemetry/logger"
+	"github.com/pion/dtls/v3"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -37,34 +38,81 @@ type Server interface {
 	Close() error
 	PacketConn() net.PacketConn
 	Use(...Interceptor) error
+	// JoinGroup and LeaveGroup change multicast membership of a running
+	// server on MulticastConfig.Interface.
+	JoinGroup(group string) error
+	LeaveGroup(group string) error
 }
 
 type ServerConfig struct {
-	Addr              string
-	PacketConn        net.PacketConn
-	ReadBuffer        int
-	WriteBuffer       int
-	MaxPacketSize     int
-	MaxConcurrency    int
+	Addr           string
+	PacketConn     net.PacketConn
+	ReadBuffer     int
+	WriteBuffer    int
+	MaxPacketSize  int
+	MaxConcurrency int
+	// Readers is the number of goroutines reading packets. With ReusePort,
+	// Start opens that many SO_REUSEPORT sockets on Addr so the kernel spreads
+	// flows across them; otherwise the readers share one socket.
+	Readers   int
+	ReusePort bool
+	// BatchSize > 1 reads up to that many packets per syscall (recvmmsg on
+	// Linux) from *net.UDPConn sockets.
+	BatchSize int
+	// PooledBuffers hands handlers the pooled read buffer instead of a copy.
+	// Packet.Payload is then only valid until the handler returns; call
+	// Retain to keep it longer.
+	PooledBuffers bool
+	// Codec decodes datagrams before the handler runs and encodes payloads
+	// written through Packet. Datagrams failing to decode are dropped.
+	Codec Codec
+	// OversizeReply, when set, is sent back to the source of a datagram
+	// larger than MaxPacketSize instead of dropping it silently. Keep it
+	// small: it is sent to unauthenticated, possibly spoofed addresses.
+	OversizeReply []byte
+	// ClassifyError names the kind of a handler error for
+	// udpx_server_handler_errors_total. Returning "" falls back to the
+	// built-in kinds: decode, timeout, downstream and internal.
+	ClassifyError func(error) string
+	// Multicast makes Start listen with ListenMulticast and join its groups.
+	// Every SO_REUSEPORT socket would receive its own copy of each datagram,
+	// so multicast servers open one socket and ignore ReusePort.
+	Multicast *MulticastConfig
+	// DTLSConfig or DTLS make Start accept DTLS sessions on Addr. Handlers
+	// see decrypted payloads and replies are encrypted; ReusePort, BatchSize
+	// and Multicast do not apply.
+	DTLSConfig        *dtls.Config
+	DTLS              *DTLSConfig
 	ShutdownTimeout   time.Duration
 	Logger            *logger.Logger
 	EnableLogger      bool
 	Trace             bool
 	MetricsRegisterer prometheus.Registerer
 	DisableMetrics    bool
+	// Drain makes Shutdown stop reading, dispatch packets already read and
+	// let running handlers finish before it cancels their contexts, bounded
+	// by the Shutdown context. Canceling the Start context drains as well.
+	// Without it Shutdown cancels handler contexts immediately.
+	Drain bool
 }
 
 type serverEntity struct {
 	config *ServerConfig
 
 	mu           sync.RWMutex
-	packetConn   net.PacketConn
+	packetConns  []net.PacketConn
 	running      bool
 	serveCancel  context.CancelCauseFunc
 	interceptors []Interceptor
 	wg           sync.WaitGroup
 	sem          chan struct{}
 	metrics      *metrics
+	buffers      *bufferPool
+
+	dispatchMu  sync.Mutex
+	sealed      bool
+	dispatching sync.WaitGroup
+	discarded   atomic.Int64
 }
 
 func NewServer(conf *ServerConfig) Server {
@@ -86,6 +134,12 @@ func NewServer(conf *ServerConfig) Server {
 	if conf.ShutdownTimeout == 0 {
 		conf.ShutdownTimeout = defaultServerShutdownTime
 	}
+	if conf.Readers <= 0 {
+		conf.Readers = 1
+	}
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = 1
+	}
 
 	var metrics *metrics
 	if !conf.DisableMetrics {
@@ -95,10 +149,14 @@ func NewServer(conf *ServerConfig) Server {
 		}
 	}
 
-	return &serverEntity{
+	s := &serverEntity{
 		config:  conf,
 		metrics: metrics,
 	}
+	if conf.PooledBuffers {
+		s.buffers = newBufferPool(conf)
+	}
+	return s
 }
 
 func (s *serverEntity) Use(interceptors ...Interceptor) error {
@@ -127,6 +185,13 @@ func (s *serverEntity) Start(ctx context.Context, handler Handler) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
+	dtlsConfig, err := resolveDTLSConfig(s.config.DTLSConfig, s.config.DTLS, true)
+	if err != nil {
+		return err
+	}
+	if dtlsConfig != nil && s.config.PacketConn != nil {
+		return ErrDTLSAddrRequired
+	}
 	if s.config.PacketConn != nil {
 		return s.Serve(ctx, s.config.PacketConn, handler)
 	}
@@ -134,17 +199,77 @@ func (s *serverEntity) Start(ctx context.Context, handler Handler) error {
 		return ErrServerAddrRequired
 	}
 
-	packetConn, err := net.ListenPacket("udp", s.config.Addr)
+	packetConns, err := s.listen(ctx, dtlsConfig)
 	if err != nil {
 		return fmt.Errorf("udpx: listen: %w", err)
 	}
-	err = s.Serve(ctx, packetConn, handler)
+	err = s.serve(ctx, packetConns, handler)
 	if err != nil {
-		_ = packetConn.Close()
+		for _, packetConn := range packetConns {
+			_ = packetConn.Close()
+		}
 	}
 	return err
 }
 
+func (s *serverEntity) listen(ctx context.Context, dtlsConfig *dtls.Config) ([]net.PacketConn, error) {
+	if dtlsConfig != nil {
+		conn, err := listenDTLS(s.config.Addr, dtlsConfig, s.config.DTLS, func(remote net.Addr, err error) {
+			if s.config.EnableLogger {
+				s.config.Logger.Debug(ctx, "udp dtls handshake failed", "remote_addr", fmt.Sprint(remote), "error", err)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []net.PacketConn{conn}, nil
+	}
+	if s.config.Multicast != nil {
+		conn, err := ListenMulticast(ctx, s.config.Addr, s.config.Multicast)
+		if err != nil {
+			return nil, err
+		}
+		return []net.PacketConn{conn}, nil
+	}
+	if !s.config.ReusePort {
+		packetConn, err := net.ListenPacket("udp", s.config.Addr)
+		if err != nil {
+			return nil, err
+		}
+		return []net.PacketConn{packetConn}, nil
+	}
+	return listenReusePort(ctx, s.config.Addr, s.config.Readers)
+}
+
+func (s *serverEntity) JoinGroup(group string) error {
+	return s.changeGroup(group, JoinGroup)
+}
+
+func (s *serverEntity) LeaveGroup(group string) error {
+	return s.changeGroup(group, LeaveGroup)
+}
+
+func (s *serverEntity) changeGroup(group string, change func(*net.UDPConn, string, string) error) error {
+	packetConns, _ := s.snapshotState()
+	if len(packetConns) == 0 {
+		return ErrServerNotRunning
+	}
+	iface := ""
+	if s.config.Multicast != nil {
+		iface = s.config.Multicast.Interface
+	}
+	for _, packetConn := range packetConns {
+		udpConn, ok := packetConn.(*net.UDPConn)
+		if !ok {
+			return ErrDatagramConnRequired
+		}
+		if err := change(udpConn, group, iface); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *serverEntity) Serve(ctx context.Context, packetConn net.PacketConn, handler Handler) error {
 	if err := validateContext(ctx); err != nil {
 		return err
@@ -155,6 +280,16 @@ func (s *serverEntity) Serve(ctx context.Context, packetConn net.PacketConn, han
 	if packetConn == nil {
 		return ErrNilPacketConn
 	}
+	return s.serve(ctx, []net.PacketConn{packetConn}, handler)
+}
+
+func (s *serverEntity) serve(ctx context.Context, packetConns []net.PacketConn, handler Handler) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if handler == nil {
 		return ErrNilHandler
 	}
@@ -165,8 +300,17 @@ func (s *serverEntity) Serve(ctx context.Context, packetConn net.PacketConn, han
 		return ErrServerAlreadyRunning
 	}
 
-	serveCtx, serveCancel := context.WithCancelCause(ctx)
-	s.packetConn = packetConn
+	baseCtx := ctx
+	if s.config.Drain {
+		// Handlers keep running while Shutdown drains after ctx is canceled.
+		baseCtx = context.WithoutCancel(ctx)
+	}
+	serveCtx, serveCancel := context.WithCancelCause(baseCtx)
+	s.dispatchMu.Lock()
+	s.sealed = false
+	s.dispatchMu.Unlock()
+	s.discarded.Store(0)
+	s.packetConns = packetConns
 	s.serveCancel = serveCancel
 	s.running = true
 	if s.config.MaxConcurrency > 0 {
@@ -180,88 +324,65 @@ func (s *serverEntity) Serve(ctx context.Context, packetConn net.PacketConn, han
 	defer func() {
 		serveCancel(errServerClosed)
 		s.mu.Lock()
-		s.packetConn = nil
+		s.packetConns = nil
 		s.serveCancel = nil
 		s.running = false
 		s.sem = nil
 		s.mu.Unlock()
 	}()
 
-	if err := s.configurePacketConn(packetConn); err != nil {
-		return err
+	for _, packetConn := range packetConns {
+		if err := s.configurePacketConn(packetConn); err != nil {
+			return err
+		}
 	}
 
 	done := make(chan struct{})
 	defer close(done)
 	go s.watchContext(ctx, done)
 
-	finalHandler := chainInterceptors(handler, interceptors)
 	addrLabel := ""
-	if localAddr := packetConn.LocalAddr(); localAddr != nil {
+	if localAddr := packetConns[0].LocalAddr(); localAddr != nil {
 		addrLabel = localAddr.String()
 	}
-	tracer := otel.Tracer("micro/udpx")
-	var bufPool = sync.Pool{
-		New: func() any {
-			return make([]byte, s.config.MaxPacketSize)
-		},
+	loop := &readLoop{
+		server:    s,
+		ctx:       serveCtx,
+		logCtx:    ctx,
+		handler:   chainInterceptors(handler, interceptors),
+		tracer:    otel.Tracer("micro/udpx"),
+		addrLabel: addrLabel,
 	}
 
-	s.info(ctx, "udp server starting", "addr", addrLabel)
-
-	for {
-		buffer := bufPool.Get().([]byte)
-		n, remoteAddr, truncated, err := s.readPacket(packetConn, buffer)
-		if err != nil {
-			bufPool.Put(buffer)
-			if isClosedConnectionError(err) || errors.Is(context.Cause(serveCtx), errServerClosed) {
-				s.stopActivePackets(errServerClosed)
-				s.wg.Wait()
-				return nil
-			}
-			if isTemporaryNetError(err) {
-				s.config.Logger.Warn(ctx, "udp read temporary failure", "error", err)
-				continue
-			}
-
-			readErr := fmt.Errorf("udpx: read packet: %w", err)
-			s.stopActivePackets(readErr)
-			s.wg.Wait()
-			return readErr
-		}
-
-		if s.metrics != nil {
-			s.metrics.serverPacketsReceived.WithLabelValues(addrLabel).Inc()
-			s.metrics.serverBytesRead.WithLabelValues(addrLabel).Add(float64(n))
-		}
-
-		if truncated {
-			if s.metrics != nil {
-				s.metrics.serverPacketsDropped.WithLabelValues(addrLabel, "truncated").Inc()
-			}
-			bufPool.Put(buffer)
-			s.config.Logger.Warn(ctx, "udp packet dropped", "reason", "truncated", "remote_addr", remoteAddr.String(), "max_packet_size", s.config.MaxPacketSize)
-			continue
-		}
+	readersPerConn := 1
+	if len(packetConns) == 1 {
+		readersPerConn = s.config.Readers
+	}
 
-		payload := append([]byte(nil), buffer[:n]...)
-		bufPool.Put(buffer)
+	s.info(ctx, "udp server starting", "addr", addrLabel, "sockets", len(packetConns), "readers", len(packetConns)*readersPerConn)
 
-		if err := s.acquireSlot(serveCtx); err != nil {
-			return nil
+	errCh := make(chan error, len(packetConns)*readersPerConn)
+	for _, packetConn := range packetConns {
+		for range readersPerConn {
+			go func() {
+				errCh <- loop.run(packetConn)
+			}()
 		}
+	}
 
-		var onWrite func(int)
-		if s.metrics != nil {
-			onWrite = func(written int) {
-				s.metrics.serverBytesWritten.WithLabelValues(addrLabel).Add(float64(written))
-			}
+	var serveErr error
+	for range cap(errCh) {
+		if err := <-errCh; err != nil && serveErr == nil {
+			serveErr = err
+			s.stopActivePackets(err)
 		}
-		packet := newPacket(payload, remoteAddr, packetConn.LocalAddr(), packetConn, onWrite)
-
-		s.wg.Add(1)
-		go s.handlePacket(serveCtx, tracer, addrLabel, finalHandler, packet)
 	}
+	if serveErr == nil {
+		s.stopActivePackets(errServerClosed)
+	}
+	s.wg.Wait()
+	s.info(ctx, "udp server stopped", "addr", addrLabel, "discarded", s.discarded.Load())
+	return serveErr
 }
 
 func (s *serverEntity) Shutdown(ctx context.Context) error {
@@ -276,19 +397,27 @@ func (s *serverEntity) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	packetConn, cancel := s.snapshotState()
-	if packetConn == nil && cancel == nil {
+	packetConns, cancel := s.snapshotState()
+	if packetConns == nil && cancel == nil {
 		return nil
 	}
 
-	s.info(ctx, "udp server shutting down")
+	s.info(ctx, "udp server shutting down", "drain", s.config.Drain)
 
+	s.seal()
+	var drainErr error
+	if s.config.Drain {
+		drainErr = s.drain(ctx)
+	}
 	if cancel != nil {
 		cancel(errServerClosed)
 	}
-	if packetConn != nil {
+	for _, packetConn := range packetConns {
 		_ = packetConn.Close()
 	}
+	if drainErr != nil {
+		return drainErr
+	}
 
 	done := make(chan struct{})
 	go func() {
@@ -309,38 +438,41 @@ func (s *serverEntity) Shutdown(ctx context.Context) error {
 }
 
 func (s *serverEntity) Close() error {
-	packetConn, cancel := s.snapshotState()
+	packetConns, cancel := s.snapshotState()
 	if cancel != nil {
 		cancel(errServerClosed)
 	}
-	if packetConn == nil {
-		return nil
-	}
-	err := packetConn.Close()
-	if isClosedConnectionError(err) {
-		return nil
+	var closeErr error
+	for _, packetConn := range packetConns {
+		if err := packetConn.Close(); err != nil && !isClosedConnectionError(err) && closeErr == nil {
+			closeErr = err
+		}
 	}
-	return err
+	return closeErr
 }
 
+// PacketConn returns the first socket when the server listens on several.
 func (s *serverEntity) PacketConn() net.PacketConn {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.packetConn
+	if len(s.packetConns) == 0 {
+		return nil
+	}
+	return s.packetConns[0]
 }
 
-func (s *serverEntity) snapshotState() (net.PacketConn, context.CancelCauseFunc) {
+func (s *serverEntity) snapshotState() ([]net.PacketConn, context.CancelCauseFunc) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.packetConn, s.serveCancel
+	return s.packetConns, s.serveCancel
 }
 
 func (s *serverEntity) stopActivePackets(cause error) {
-	packetConn, cancel := s.snapshotState()
+	packetConns, cancel := s.snapshotState()
 	if cancel != nil {
 		cancel(cause)
 	}
-	if packetConn != nil {
+	for _, packetConn := range packetConns {
 		_ = packetConn.Close()
 	}
 }
@@ -382,6 +514,7 @@ func (s *serverEntity) handlePacket(
 ) {
 	defer s.wg.Done()
 	defer s.releaseSlot()
+	defer releasePacket(packet)
 
 	packetCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -425,9 +558,16 @@ func (s *serverEntity) handlePacket(
 
 		result := classifyPacketResult(packetCtx, handleErr, panicked)
 		duration := time.Since(start)
+		var kind string
+		if result == "error" {
+			kind = s.classifyError(handleErr)
+		}
 		if s.metrics != nil {
 			s.metrics.serverPacketsHandled.WithLabelValues(addrLabel, result).Inc()
 			s.metrics.serverPacketDuration.WithLabelValues(addrLabel, result).Observe(duration.Seconds())
+			if kind != "" {
+				s.metrics.serverHandlerErrors.WithLabelValues(addrLabel, kind).Inc()
+			}
 		}
 
 		switch result {
@@ -439,6 +579,7 @@ func (s *serverEntity) handlePacket(
 			s.config.Logger.Error(packetCtx, "udp packet handling failed",
 				"remote_addr", packet.RemoteAddr().String(),
 				"duration", duration,
+				"kind", kind,
 				"error", handleErr,
 			)
 		case "panic":
@@ -474,22 +615,6 @@ func (s *serverEntity) configurePacketConn(packetConn net.PacketConn) error {
 	return nil
 }
 
-func (s *serverEntity) readPacket(packetConn net.PacketConn, buffer []byte) (int, net.Addr, bool, error) {
-	type messageReader interface {
-		ReadMsgUDP([]byte, []byte) (int, int, int, *net.UDPAddr, error)
-	}
-	if reader, ok := packetConn.(messageReader); ok {
-		n, _, flags, addr, err := reader.ReadMsgUDP(buffer, nil)
-		if err != nil {
-			return 0, nil, false, err
-		}
-		return n, addr, flags&syscall.MSG_TRUNC != 0, nil
-	}
-
-	n, addr, err := packetConn.ReadFrom(buffer)
-	return n, addr, false, err
-}
-
 func (s *serverEntity) watchContext(ctx context.Context, done <-chan struct{}) {
 	select {
 	case <-done:
@@ -538,3 +663,23 @@ func connectionAttributes(local, remote net.Addr) []attribute.KeyValue {
 
 	return attrs
 }
+
+func (s *serverEntity) classifyError(err error) string {
+	if s.config.ClassifyError != nil {
+		if kind := s.config.ClassifyError(err); kind != "" {
+			return kind
+		}
+	}
+	return classifyHandlerError(err)
+}
+
+func (s *serverEntity) observeWrite(addrLabel string, written int, err error) {
+	if written > 0 {
+		s.metrics.serverBytesWritten.WithLabelValues(addrLabel).Add(float64(written))
+	}
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	s.metrics.serverPacketsSent.WithLabelValues(addrLabel, result).Inc()
+}
@@ -3,6 +3,7 @@ package udpx_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"strings"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/bang-go/micro/telemetry/logger"
 	"github.com/bang-go/micro/transport/udpx"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestServerServeShutdownAndRecovery(t *testing.T) {
@@ -465,3 +467,195 @@ func waitForLog(t *testing.T, logs *safeBuffer, pattern string) {
 	}
 	t.Fatalf("log %q not found in %q", pattern, logs.String())
 }
+
+func TestServerShutdownDrainsInFlightPackets(t *testing.T) {
+	packetConn := newFakePacketConn()
+	reg := prometheus.NewRegistry()
+	server := udpx.NewServer(&udpx.ServerConfig{
+		PacketConn:        packetConn,
+		Drain:             true,
+		MetricsRegisterer: reg,
+	})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Start(context.Background(), udpx.HandlerFunc(func(ctx context.Context, packet udpx.Packet) error {
+			close(started)
+			<-release
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			_, err := packet.Reply(ctx, []byte("done"))
+			return err
+		}))
+	}()
+
+	waitForServer(t, server)
+	packetConn.enqueuePacket([]byte("slow"), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 30004})
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("handler did not start")
+	}
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- server.Shutdown(context.Background())
+	}()
+	time.Sleep(50 * time.Millisecond)
+	packetConn.enqueuePacket([]byte("late"), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 30004})
+
+	deadline := time.Now().Add(time.Second)
+	for droppedPackets(t, reg, "shutdown") != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("shutdown drops = %v, want 1", droppedPackets(t, reg, "shutdown"))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("Shutdown() returned %v before the handler finished", err)
+	default:
+	}
+
+	close(release)
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	write, err := packetConn.nextWrite(time.Second)
+	if err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	if string(write.data) != "done" {
+		t.Fatalf("reply = %q", write.data)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
+
+func TestServerShutdownDrainHonorsContext(t *testing.T) {
+	packetConn := newFakePacketConn()
+	server := udpx.NewServer(&udpx.ServerConfig{
+		PacketConn:     packetConn,
+		Drain:          true,
+		DisableMetrics: true,
+	})
+
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Start(context.Background(), udpx.HandlerFunc(func(ctx context.Context, packet udpx.Packet) error {
+			close(started)
+			<-ctx.Done()
+			close(canceled)
+			return ctx.Err()
+		}))
+	}()
+
+	waitForServer(t, server)
+	packetConn.enqueuePacket([]byte("stuck"), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 30005})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("handler context was not canceled after the drain deadline")
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
+
+func TestServerWriteMetricsAndErrorKinds(t *testing.T) {
+	packetConn := newFakePacketConn()
+	reg := prometheus.NewRegistry()
+	errQuota := errors.New("quota exceeded")
+	server := udpx.NewServer(&udpx.ServerConfig{
+		PacketConn:        packetConn,
+		MetricsRegisterer: reg,
+		ClassifyError: func(err error) string {
+			if errors.Is(err, errQuota) {
+				return "quota"
+			}
+			return ""
+		},
+	})
+
+	handled := make(chan struct{}, 8)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Start(context.Background(), udpx.HandlerFunc(func(ctx context.Context, packet udpx.Packet) error {
+			defer func() { handled <- struct{}{} }()
+			switch string(packet.Payload()) {
+			case "ok":
+				_, err := packet.Reply(ctx, []byte("pong"))
+				return err
+			case "decode":
+				return fmt.Errorf("parse header: %w", udpx.ErrMalformedPacket)
+			case "timeout":
+				return fmt.Errorf("lookup: %w", context.DeadlineExceeded)
+			case "downstream":
+				return fmt.Errorf("%w: redis unavailable", udpx.ErrDownstream)
+			case "quota":
+				return errQuota
+			default:
+				return errors.New("boom")
+			}
+		}))
+	}()
+	waitForServer(t, server)
+
+	payloads := []string{"ok", "decode", "timeout", "downstream", "quota", "other"}
+	for _, payload := range payloads {
+		packetConn.enqueuePacket([]byte(payload), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 30006})
+	}
+	for range payloads {
+		select {
+		case <-handled:
+		case <-time.After(time.Second):
+			t.Fatal("handler did not run")
+		}
+	}
+	if _, err := packetConn.nextWrite(time.Second); err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	sent := registeredCounterVec(t, reg, "udpx_server_packets_sent_total", "Total number of UDP packets written by the server.", "addr", "result")
+	if got := testutil.ToFloat64(sent.WithLabelValues("127.0.0.1:9999", "ok")); got != 1 {
+		t.Fatalf("packets sent = %v", got)
+	}
+	errorsByKind := registeredCounterVec(t, reg, "udpx_server_handler_errors_total", "Total number of UDP handler errors by kind.", "addr", "kind")
+	for _, kind := range []string{"decode", "timeout", "downstream", "quota", "internal"} {
+		if got := testutil.ToFloat64(errorsByKind.WithLabelValues("127.0.0.1:9999", kind)); got != 1 {
+			t.Fatalf("handler errors{kind=%q} = %v", kind, got)
+		}
+	}
+}
+
+func registeredCounterVec(t *testing.T, reg *prometheus.Registry, name, help string, labels ...string) *prometheus.CounterVec {
+	t.Helper()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+	if err := reg.Register(counter); err != nil {
+		existing, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			t.Fatalf("register %s: %v", name, err)
+		}
+		counter = existing.ExistingCollector.(*prometheus.CounterVec)
+	}
+	return counter
+}
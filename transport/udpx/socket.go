@@ -0,0 +1,31 @@
+package udpx
+
+import (
+	"context"
+	"net"
+)
+
+// listenReusePort opens count SO_REUSEPORT sockets on addr so the kernel can
+// spread incoming flows across parallel readers.
+func listenReusePort(ctx context.Context, addr string, count int) ([]net.PacketConn, error) {
+	config := net.ListenConfig{Control: reusePortControl}
+
+	first, err := config.ListenPacket(ctx, "udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Later sockets bind the resolved address so ":0" shares one port.
+	packetConns := []net.PacketConn{first}
+	for len(packetConns) < count {
+		packetConn, err := config.ListenPacket(ctx, "udp", first.LocalAddr().String())
+		if err != nil {
+			for _, conn := range packetConns {
+				_ = conn.Close()
+			}
+			return nil, err
+		}
+		packetConns = append(packetConns, packetConn)
+	}
+	return packetConns, nil
+}
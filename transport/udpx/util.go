@@ -40,6 +40,26 @@ func classifyPacketResult(ctx context.Context, err error, panicked bool) string
 	}
 }
 
+// classifyHandlerError maps a handler error to a metric kind. Handlers opt
+// into decode and downstream by wrapping ErrMalformedPacket or ErrDownstream.
+func classifyHandlerError(err error) string {
+	switch {
+	case errors.Is(err, ErrMalformedPacket):
+		return "decode"
+	case errors.Is(err, context.DeadlineExceeded) || isTimeoutError(err):
+		return "timeout"
+	case errors.Is(err, ErrDownstream):
+		return "downstream"
+	default:
+		return "internal"
+	}
+}
+
+func isTimeoutError(err error) bool {
+	var timeoutErr interface{ Timeout() bool }
+	return errors.As(err, &timeoutErr) && timeoutErr.Timeout()
+}
+
 func isClosedConnectionError(err error) bool {
 	if err == nil {
 		return false
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
 	"sync"
 	"time"
 
@@ -105,7 +106,8 @@ func (c *clientEntity) loop(ctx context.Context) {
 			dialCtx, cancel = context.WithTimeout(ctx, c.options.dialTimeout)
 		}
 		dialOpts := &websocket.DialOptions{
-			HTTPHeader: c.options.httpHeader,
+			HTTPHeader:   c.options.httpHeader,
+			Subprotocols: c.options.protocols.names,
 		}
 		conn, _, err := websocket.Dial(dialCtx, c.addr, dialOpts)
 		cancel()
@@ -128,7 +130,11 @@ func (c *clientEntity) loop(ctx context.Context) {
 		reconnectAttempts = 0
 
 		// Wrap connection
-		wsConn := NewConnect(conn, c.addr, c.options.connectOpts...)
+		connOpts := c.options.connectOpts
+		if codecOpt := c.options.protocols.connectOption(conn.Subprotocol()); codecOpt != nil {
+			connOpts = append(slices.Clip(connOpts), codecOpt)
+		}
+		wsConn := NewConnect(conn, c.addr, connOpts...)
 		c.setConn(wsConn)
 
 		c.signalFirstConnect(nil)
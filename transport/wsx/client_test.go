@@ -3,6 +3,7 @@ package wsx
 import (
 	"context"
 	"errors"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -80,3 +81,33 @@ func TestClientMessageHookPanicTriggersDisconnectWithoutCrashing(t *testing.T) {
 		t.Fatal("expected disconnect after message hook panic")
 	}
 }
+
+func TestClientSubprotocolSelectsCodec(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&ServerConfig{}, WithServerSubprotocol("v1.json", nil), WithServerSubprotocol("v2.proto", ProtoCodec))
+	httpServer := httptest.NewServer(server.Handler(func(ctx context.Context, conn Connect) {
+		_, _, _ = conn.ReadMessage(ctx)
+	}))
+	t.Cleanup(httpServer.Close)
+
+	connected := make(chan Connect, 1)
+	client := NewClient("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws",
+		WithClientSubprotocol("v2.proto", ProtoCodec),
+		WithClientReconnectInterval(20*time.Millisecond),
+	)
+	client.OnConnect(func(_ context.Context, conn Connect) {
+		connected <- conn
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	defer client.Close()
+
+	conn := <-connected
+	if conn.Subprotocol() != "v2.proto" || conn.Codec() != ProtoCodec {
+		t.Fatalf("Subprotocol() = %q, Codec() = %T", conn.Subprotocol(), conn.Codec())
+	}
+}
@@ -0,0 +1,54 @@
+package wsx
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/coder/websocket"
+	"google.golang.org/protobuf/proto"
+)
+
+var errCodecNotProto = errors.New("wsx: proto codec requires a proto.Message")
+
+// Codec encodes the values passed to Connect.Send and decodes received
+// frames for one subprotocol, e.g. JSON for v1 clients and protobuf for v2.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	// MessageType is the frame type Send writes.
+	MessageType() websocket.MessageType
+}
+
+var (
+	// JSONCodec writes text frames; it is the default when no subprotocol
+	// with a codec was negotiated.
+	JSONCodec Codec = jsonCodec{}
+	// ProtoCodec writes binary frames and only accepts proto.Message values.
+	ProtoCodec Codec = protoCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) MessageType() websocket.MessageType { return websocket.MessageText }
+
+type protoCodec struct{}
+
+func (protoCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, errCodecNotProto
+	}
+	return proto.Marshal(m)
+}
+
+func (protoCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return errCodecNotProto
+	}
+	return proto.Unmarshal(data, m)
+}
+
+func (protoCodec) MessageType() websocket.MessageType { return websocket.MessageBinary }
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/bang-go/micro/pkg/bytesx"
 	"github.com/bang-go/opt"
 	"github.com/coder/websocket"
 	"github.com/google/uuid"
@@ -15,6 +17,8 @@ import (
 type message struct {
 	typ  websocket.MessageType
 	data []byte
+	// pooled backs data when it is a bytesx copy; writeLoop returns it.
+	pooled *[]byte
 }
 
 type Connect interface {
@@ -28,6 +32,10 @@ type Connect interface {
 	// SendJSON queues a JSON message.
 	SendJSON(context.Context, interface{}) error
 
+	// Send encodes v with the codec of the negotiated subprotocol (JSON by
+	// default) and queues it.
+	Send(context.Context, any) error
+
 	// ReadMessage blocks until a message is received or context done.
 	ReadMessage(context.Context) (websocket.MessageType, []byte, error)
 
@@ -42,6 +50,12 @@ type Connect interface {
 
 	// SessionID returns the unique physical session identifier
 	SessionID() string
+
+	// Subprotocol returns the negotiated Sec-WebSocket-Protocol, or "".
+	Subprotocol() string
+
+	// Codec returns the codec Send uses, for decoding received frames.
+	Codec() Codec
 }
 
 type roomMember interface {
@@ -65,6 +79,9 @@ type connectEntity struct {
 	readTimeout       time.Duration
 	writeTimeout      time.Duration
 
+	appHeartbeat  *AppHeartbeatConfig
+	lastHeartbeat atomic.Int64
+
 	// Outbound channel
 	sendChan chan message
 
@@ -74,6 +91,7 @@ type connectEntity struct {
 	once        sync.Once
 
 	skipObservability bool
+	codec             Codec
 }
 
 func NewConnect(conn *websocket.Conn, remoteAddr string, opts ...opt.Option[connectOptions]) Connect {
@@ -102,6 +120,14 @@ func NewConnect(conn *websocket.Conn, remoteAddr string, opts ...opt.Option[conn
 		closed:            make(chan struct{}),
 		roomSet:           make(map[string]struct{}),
 		skipObservability: options.skipObservability,
+		codec:             options.codec,
+	}
+	if c.codec == nil {
+		c.codec = JSONCodec
+	}
+	if options.appHeartbeat != nil {
+		c.appHeartbeat = normalizeAppHeartbeat(*options.appHeartbeat)
+		c.lastHeartbeat.Store(time.Now().UnixNano())
 	}
 	c.closeCtx, c.closeCancel = context.WithCancel(context.Background())
 
@@ -142,6 +168,20 @@ func (c *connectEntity) writeLoop() {
 		tickerC = ticker.C
 	}
 
+	var appTickerC, staleTickerC <-chan time.Time
+	if hb := c.appHeartbeat; hb != nil {
+		if hb.Interval > 0 {
+			appTicker := time.NewTicker(hb.Interval)
+			defer appTicker.Stop()
+			appTickerC = appTicker.C
+		}
+		if hb.Timeout > 0 {
+			staleTicker := time.NewTicker(hb.staleCheckInterval())
+			defer staleTicker.Stop()
+			staleTickerC = staleTicker.C
+		}
+	}
+
 	defer func() {
 		// Metrics: Decrement active connections
 		if !c.skipObservability {
@@ -162,6 +202,7 @@ func (c *connectEntity) writeLoop() {
 			wCtx, wCancel := c.newWriteContext()
 			err := c.conn.Write(wCtx, msg.typ, msg.data)
 			wCancel()
+			bytesx.Put(msg.pooled)
 			if err != nil {
 				// Log? Close?
 				if !c.skipObservability {
@@ -183,12 +224,34 @@ func (c *connectEntity) writeLoop() {
 				c.Close()
 				return
 			}
+
+		case <-appTickerC:
+			wCtx, wCancel := c.newWriteContext()
+			err := c.conn.Write(wCtx, c.appHeartbeat.MessageType, c.appHeartbeat.Payload)
+			wCancel()
+			if err != nil {
+				c.Close()
+				return
+			}
+			if !c.skipObservability {
+				appHeartbeats.WithLabelValues("sent").Inc()
+			}
+
+		case now := <-staleTickerC:
+			if c.heartbeatStale(now) {
+				if !c.skipObservability {
+					appHeartbeats.WithLabelValues("timeout").Inc()
+				}
+				c.closeWith(websocket.StatusPolicyViolation, "heartbeat timeout")
+				return
+			}
 		}
 	}
 }
 
 func (c *connectEntity) SendText(ctx context.Context, text string) error {
-	return c.send(ctx, message{typ: websocket.MessageText, data: []byte(text)})
+	// send copies, so the string's bytes can be used directly.
+	return c.send(ctx, message{typ: websocket.MessageText, data: bytesx.Bytes(text)})
 }
 
 func (c *connectEntity) SendBinary(ctx context.Context, data []byte) error {
@@ -203,23 +266,46 @@ func (c *connectEntity) SendJSON(ctx context.Context, v interface{}) error {
 	return c.send(ctx, message{typ: websocket.MessageText, data: data})
 }
 
-func (c *connectEntity) send(ctx context.Context, msg message) (err error) {
+func (c *connectEntity) Send(ctx context.Context, v any) error {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.enqueue(ctx, message{typ: c.codec.MessageType(), data: data})
+}
+
+// send queues a pooled copy of msg.data, so callers may reuse their slice
+// once it returns.
+func (c *connectEntity) send(ctx context.Context, msg message) error {
+	if msg.data != nil {
+		buffer := bytesx.Get(len(msg.data))
+		copy(*buffer, msg.data)
+		msg.data, msg.pooled = *buffer, buffer
+	}
+	return c.enqueue(ctx, msg)
+}
+
+// sendShared queues data without copying. The hub uses it to fan one
+// message out to many connections; data must not be modified afterwards.
+func (c *connectEntity) sendShared(ctx context.Context, data []byte) error {
+	return c.enqueue(ctx, message{typ: websocket.MessageBinary, data: data})
+}
+
+func (c *connectEntity) enqueue(ctx context.Context, msg message) (err error) {
 	ctx = normalizeContext(ctx)
 	defer func() {
 		if recover() != nil {
 			err = errConnectionClosed
 		}
+		if err != nil {
+			bytesx.Put(msg.pooled)
+		}
 	}()
 
-	queued := msg
-	if msg.data != nil {
-		queued.data = append([]byte(nil), msg.data...)
-	}
-
 	select {
 	case <-c.closeCtx.Done():
 		return errConnectionClosed
-	case c.sendChan <- queued:
+	case c.sendChan <- msg:
 		return nil
 	case <-ctx.Done():
 		if !c.skipObservability {
@@ -252,30 +338,47 @@ func (c *connectEntity) ReadMessage(ctx context.Context) (websocket.MessageType,
 	// Instead, we pass the original context. The connection will be closed
 	// if the context is cancelled or the Ping loop detects a failure.
 
-	mt, data, err := c.conn.Read(readCtx)
-	if err != nil {
-		return 0, nil, err
-	}
-	if !c.skipObservability {
-		msgReceived.Inc()
+	for {
+		mt, data, err := c.conn.Read(readCtx)
+		if err != nil {
+			return 0, nil, err
+		}
+		if c.handleAppHeartbeat(mt, data) {
+			continue
+		}
+		if !c.skipObservability {
+			msgReceived.Inc()
+		}
+		return mt, data, nil
 	}
-	return mt, data, nil
 }
 
 func (c *connectEntity) Close() error {
+	c.closeWith(websocket.StatusNormalClosure, "closed")
+	return nil
+}
+
+func (c *connectEntity) closeWith(code websocket.StatusCode, reason string) {
 	c.once.Do(func() {
 		c.closeCancel()
 		close(c.closed)
 		close(c.sendChan)
-		_ = c.conn.Close(websocket.StatusNormalClosure, "closed")
+		_ = c.conn.Close(code, reason)
 	})
-	return nil
 }
 
 func (c *connectEntity) UserID() string {
 	return c.userID
 }
 
+func (c *connectEntity) Subprotocol() string {
+	return c.conn.Subprotocol()
+}
+
+func (c *connectEntity) Codec() Codec {
+	return c.codec
+}
+
 func (c *connectEntity) rooms() []string {
 	c.roomMu.RLock()
 	defer c.roomMu.RUnlock()
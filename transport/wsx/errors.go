@@ -17,4 +17,6 @@ var (
 	errServerAddrMissing            = errors.New("wsx: server addr or listener is required")
 	errBrokerClosed                 = errors.New("wsx: broker closed")
 	errBrokerHandlerMissing         = errors.New("wsx: subscribe handler is required")
+	errSequencePayloadInvalid       = errors.New("wsx: sequenced message payload must be JSON")
+	errSequenceMissing              = errors.New("wsx: message has no sequence number")
 )
@@ -0,0 +1,103 @@
+package wsx
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+var (
+	defaultAppHeartbeatPayload = []byte("ping")
+	defaultAppHeartbeatPong    = []byte("pong")
+)
+
+// AppHeartbeatConfig configures heartbeats carried in ordinary data frames, for
+// clients such as mini-programs that cannot answer protocol-level pings. It
+// runs alongside WithHeartbeatInterval; either can be disabled on its own.
+type AppHeartbeatConfig struct {
+	// Interval sends Payload on this period. 0 only listens for the peer's
+	// heartbeats.
+	Interval time.Duration
+	// Payload is the outgoing heartbeat frame; defaults to "ping".
+	Payload []byte
+	// MessageType of outgoing heartbeat and reply frames; defaults to text.
+	MessageType websocket.MessageType
+	// Match reports whether an inbound frame is a heartbeat. Matched frames
+	// refresh the liveness clock and are not returned by ReadMessage. Defaults
+	// to text frames equal to "ping" or "pong".
+	Match func(websocket.MessageType, []byte) bool
+	// Reply, when set, is sent back for every matched heartbeat, except a
+	// frame equal to Reply itself so two peers never echo each other forever.
+	Reply []byte
+	// Timeout closes the connection with StatusPolicyViolation when no
+	// heartbeat has been received for this long. 0 disables staleness checks.
+	Timeout time.Duration
+}
+
+func normalizeAppHeartbeat(conf AppHeartbeatConfig) *AppHeartbeatConfig {
+	if len(conf.Payload) == 0 {
+		conf.Payload = defaultAppHeartbeatPayload
+	}
+	conf.Payload = append([]byte(nil), conf.Payload...)
+	conf.Reply = append([]byte(nil), conf.Reply...)
+	if conf.MessageType == 0 {
+		conf.MessageType = websocket.MessageText
+	}
+	if conf.Match == nil {
+		conf.Match = defaultAppHeartbeatMatch
+	}
+	return &conf
+}
+
+func defaultAppHeartbeatMatch(typ websocket.MessageType, data []byte) bool {
+	return typ == websocket.MessageText &&
+		(bytes.Equal(data, defaultAppHeartbeatPayload) || bytes.Equal(data, defaultAppHeartbeatPong))
+}
+
+// staleCheckInterval is how often the write loop checks for a missed heartbeat.
+func (c *AppHeartbeatConfig) staleCheckInterval() time.Duration {
+	interval := c.Timeout / 4
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	return interval
+}
+
+// handleAppHeartbeat consumes an inbound heartbeat frame. It reports false for
+// frames that belong to the caller.
+func (c *connectEntity) handleAppHeartbeat(typ websocket.MessageType, data []byte) bool {
+	hb := c.appHeartbeat
+	if hb == nil || !hb.Match(typ, data) {
+		return false
+	}
+	c.lastHeartbeat.Store(time.Now().UnixNano())
+	if !c.skipObservability {
+		appHeartbeats.WithLabelValues("received").Inc()
+	}
+	if len(hb.Reply) > 0 && !bytes.Equal(data, hb.Reply) {
+		// The reply bypasses the caller's context; a full queue or closed
+		// connection just drops it and the peer's own staleness check decides.
+		c.trySend(message{typ: hb.MessageType, data: hb.Reply})
+	}
+	return true
+}
+
+func (c *connectEntity) trySend(msg message) {
+	defer func() {
+		_ = recover() // sendChan closed concurrently
+	}()
+	select {
+	case <-c.closed:
+	case c.sendChan <- msg:
+	default:
+	}
+}
+
+func (c *connectEntity) heartbeatStale(now time.Time) bool {
+	hb := c.appHeartbeat
+	if hb == nil || hb.Timeout <= 0 {
+		return false
+	}
+	return now.Sub(time.Unix(0, c.lastHeartbeat.Load())) > hb.Timeout
+}
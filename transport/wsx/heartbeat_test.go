@@ -0,0 +1,136 @@
+package wsx
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+func startAppHeartbeatServer(t *testing.T, conf AppHeartbeatConfig, handler func(context.Context, Connect)) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	server := NewServer(&ServerConfig{
+		Listener:        listener,
+		ShutdownTimeout: time.Second,
+	}, WithServerConnectOption(WithHeartbeatInterval(0), WithAppHeartbeat(conf)))
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Start(context.Background(), handler)
+	}()
+	t.Cleanup(func() {
+		_ = server.Shutdown(context.Background())
+		if err := <-errCh; err != nil {
+			t.Errorf("server returned unexpected error: %v", err)
+		}
+	})
+	return "ws://" + listener.Addr().String() + "/ws"
+}
+
+func TestAppHeartbeatRepliesAndHidesHeartbeatFrames(t *testing.T) {
+	received := make(chan string, 1)
+	wsURL := startAppHeartbeatServer(t, AppHeartbeatConfig{Reply: []byte("pong")}, func(ctx context.Context, conn Connect) {
+		_, payload, err := conn.ReadMessage(ctx)
+		if err == nil {
+			received <- string(payload)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	client, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer client.CloseNow()
+
+	if err := client.Write(ctx, websocket.MessageText, []byte("ping")); err != nil {
+		t.Fatalf("write ping: %v", err)
+	}
+	typ, reply, err := client.Read(ctx)
+	if err != nil {
+		t.Fatalf("read reply: %v", err)
+	}
+	if typ != websocket.MessageText || string(reply) != "pong" {
+		t.Fatalf("reply = %v %q", typ, reply)
+	}
+
+	if err := client.Write(ctx, websocket.MessageText, []byte("hello")); err != nil {
+		t.Fatalf("write message: %v", err)
+	}
+	select {
+	case got := <-received:
+		if got != "hello" {
+			t.Fatalf("handler read %q, want the heartbeat to be consumed", got)
+		}
+	case <-ctx.Done():
+		t.Fatal("handler did not receive the message")
+	}
+}
+
+func TestAppHeartbeatSendsPayloadAndClosesStaleConnection(t *testing.T) {
+	wsURL := startAppHeartbeatServer(t, AppHeartbeatConfig{
+		Interval: 20 * time.Millisecond,
+		Payload:  []byte(`{"type":"hb"}`),
+		Timeout:  150 * time.Millisecond,
+	}, func(ctx context.Context, conn Connect) {
+		_, _, _ = conn.ReadMessage(ctx)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	client, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer client.CloseNow()
+
+	start := time.Now()
+	heartbeats := 0
+	for {
+		_, payload, err := client.Read(ctx)
+		if err != nil {
+			if status := websocket.CloseStatus(err); status != websocket.StatusPolicyViolation {
+				t.Fatalf("close status = %v (%v)", status, err)
+			}
+			break
+		}
+		if string(payload) != `{"type":"hb"}` {
+			t.Fatalf("heartbeat payload = %q", payload)
+		}
+		heartbeats++
+	}
+	if heartbeats == 0 {
+		t.Fatal("no heartbeat frames were sent")
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("connection closed after %v, before the heartbeat timeout", elapsed)
+	}
+}
+
+func TestAppHeartbeatDoesNotEchoItsOwnReply(t *testing.T) {
+	conn := &connectEntity{
+		sendChan:     make(chan message, 1),
+		closed:       make(chan struct{}),
+		appHeartbeat: normalizeAppHeartbeat(AppHeartbeatConfig{Reply: []byte("pong")}),
+	}
+
+	if !conn.handleAppHeartbeat(websocket.MessageText, []byte("pong")) {
+		t.Fatal("pong was not treated as a heartbeat")
+	}
+	select {
+	case msg := <-conn.sendChan:
+		t.Fatalf("replied %q to its own reply", msg.data)
+	default:
+	}
+	if conn.handleAppHeartbeat(websocket.MessageBinary, []byte("ping")) {
+		t.Fatal("binary frame matched the default text heartbeat")
+	}
+}
@@ -1,6 +1,7 @@
 package wsx
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -102,6 +103,7 @@ type hubEntity struct {
 	maxRoomsPerConnect int
 	sendTimeout        time.Duration
 	maxConcurrentSends int
+	sequence           SequenceStore
 	subscribeCancel    context.CancelFunc
 	closeOnce          sync.Once
 	closed             bool
@@ -144,6 +146,7 @@ func NewHub(opts ...opt.Option[hubOptions]) (Hub, error) {
 		maxRoomsPerConnect: options.maxRoomsPerConnect,
 		sendTimeout:        options.sendTimeout,
 		maxConcurrentSends: options.maxConcurrentSends,
+		sequence:           options.sequence,
 		pending:            make(map[string]*pendingCommand),
 	}
 
@@ -171,6 +174,7 @@ type hubOptions struct {
 	maxRoomsPerConnect int
 	sendTimeout        time.Duration
 	maxConcurrentSends int
+	sequence           SequenceStore
 }
 
 func WithHubBroker(broker MessageBroker) opt.Option[hubOptions] {
@@ -209,6 +213,16 @@ func WithHubMaxConcurrentSends(max int) opt.Option[hubOptions] {
 	})
 }
 
+// WithHubSequence makes SendTo and SendJSONTo wrap each JSON payload in a
+// SequencedMessage numbered per user by store, so a user's devices can restore
+// the send order with a Reorderer. Use a shared store such as RedisSequence
+// when the hub has a broker.
+func WithHubSequence(store SequenceStore) opt.Option[hubOptions] {
+	return opt.OptionFunc[hubOptions](func(o *hubOptions) {
+		o.sequence = store
+	})
+}
+
 func (h *hubEntity) Register(c Connect) error {
 	var (
 		stale        []Connect
@@ -462,6 +476,13 @@ func (h *hubEntity) SendToWithOrigin(ctx context.Context, userID string, msg []b
 	if err := requireUserID(userID); err != nil {
 		return err
 	}
+	if h.sequence != nil {
+		stamped, err := h.stampSequence(ctx, userID, msg)
+		if err != nil {
+			return err
+		}
+		msg = stamped
+	}
 	// Wrap in protocol
 	hm := hubMessage{
 		Type:     "unicast",
@@ -826,6 +847,8 @@ func (h *hubEntity) leaveLocal(ctx context.Context, sessionID string, room strin
 
 func (h *hubEntity) batchSend(ctx context.Context, conns []Connect, msg []byte) error {
 	ctx = normalizeContext(ctx)
+	// One copy shared by every connection instead of one per connection.
+	msg = bytes.Clone(msg)
 
 	// For small number of connections, send sequentially to avoid goroutine overhead
 	if len(conns) <= 10 {
@@ -928,15 +951,25 @@ func (h *hubEntity) Close() {
 	})
 }
 
+// sendWithTimeout queues msg without copying it when conn supports that;
+// batchSend owns msg, so connections can share it.
 func (h *hubEntity) sendWithTimeout(ctx context.Context, conn Connect, msg []byte) error {
+	send := conn.SendBinary
+	if shared, ok := conn.(sharedSender); ok {
+		send = shared.sendShared
+	}
 	if h.sendTimeout <= 0 {
-		return conn.SendBinary(ctx, msg)
+		return send(ctx, msg)
 	}
 
 	sendCtx, cancel := context.WithTimeout(ctx, h.sendTimeout)
 	defer cancel()
 
-	return conn.SendBinary(sendCtx, msg)
+	return send(sendCtx, msg)
+}
+
+type sharedSender interface {
+	sendShared(context.Context, []byte) error
 }
 
 func (h *hubEntity) ensureOpen() error {
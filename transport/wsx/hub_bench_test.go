@@ -0,0 +1,60 @@
+package wsx
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/bang-go/micro/pkg/bytesx"
+)
+
+// copyingConnect reproduces a fresh copy per connection, as every send made
+// before messages were pooled and shared.
+type copyingConnect struct {
+	Connect
+	entity *connectEntity
+}
+
+func (c copyingConnect) SendBinary(ctx context.Context, data []byte) error {
+	return c.entity.sendShared(ctx, bytes.Clone(data))
+}
+
+// BenchmarkHubBatchSend fans a 1 KiB message out to 100 connections. The
+// shared variant queues one copy for all of them; most remaining allocations
+// are the per-send timeout contexts and goroutines.
+func BenchmarkHubBatchSend(b *testing.B) {
+	const conns = 100
+	msg := make([]byte, 1024)
+	for _, tt := range []struct {
+		name string
+		wrap func(*connectEntity) Connect
+	}{
+		{"shared", func(c *connectEntity) Connect { return c }},
+		{"copy", func(c *connectEntity) Connect { return copyingConnect{Connect: c, entity: c} }},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			hub := &hubEntity{maxConcurrentSends: 50}
+			entities := make([]*connectEntity, conns)
+			targets := make([]Connect, conns)
+			for i := range entities {
+				entities[i] = &connectEntity{
+					sendChan:          make(chan message, 1),
+					closeCtx:          context.Background(),
+					skipObservability: true,
+				}
+				targets[i] = tt.wrap(entities[i])
+			}
+
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := hub.batchSend(context.Background(), targets, msg); err != nil {
+					b.Fatal(err)
+				}
+				for _, c := range entities {
+					// Stand in for writeLoop.
+					bytesx.Put((<-c.sendChan).pooled)
+				}
+			}
+		})
+	}
+}
@@ -384,6 +384,18 @@ func (c *stubConnect) SessionID() string {
 	return c.sessionID
 }
 
+func (c *stubConnect) Send(context.Context, any) error {
+	return errors.New("not implemented")
+}
+
+func (c *stubConnect) Subprotocol() string {
+	return ""
+}
+
+func (c *stubConnect) Codec() Codec {
+	return JSONCodec
+}
+
 func (c *stubConnect) rooms() []string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
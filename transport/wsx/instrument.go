@@ -49,4 +49,11 @@ var (
 		Name: "ws_limit_exceeded_total",
 		Help: "Total number of limit exceeded events",
 	}, []string{"type"})
+
+	// Application-level heartbeat frames
+	// Label: event = "sent" | "received" | "timeout"
+	appHeartbeats = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ws_app_heartbeats_total",
+		Help: "Total number of application-level heartbeat events",
+	}, []string{"event"})
 )
@@ -0,0 +1,107 @@
+package wsx
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const upgradeRateWindow = time.Minute
+
+type upgradeRateLimiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*upgradeBucket
+	lastSweep time.Time
+}
+
+type upgradeBucket struct {
+	start time.Time
+	count int
+}
+
+func newUpgradeRateLimiter(limit int) *upgradeRateLimiter {
+	return &upgradeRateLimiter{
+		limit:   limit,
+		window:  upgradeRateWindow,
+		now:     time.Now,
+		buckets: make(map[string]*upgradeBucket),
+	}
+}
+
+// allow reports whether ip may upgrade now, and otherwise how long until its window resets.
+func (l *upgradeRateLimiter) allow(ip string) (bool, time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= l.window {
+		for key, bucket := range l.buckets {
+			if now.Sub(bucket.start) >= l.window {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, ok := l.buckets[ip]
+	if !ok || now.Sub(bucket.start) >= l.window {
+		l.buckets[ip] = &upgradeBucket{start: now, count: 1}
+		return true, 0
+	}
+	if bucket.count >= l.limit {
+		return false, l.window - now.Sub(bucket.start)
+	}
+	bucket.count++
+	return true, 0
+}
+
+func (s *serverEntity) admit(w http.ResponseWriter, r *http.Request) bool {
+	if s.upgradeLimiter != nil {
+		if ok, retryAfter := s.upgradeLimiter.allow(remoteIP(r)); !ok {
+			limitExceeded.WithLabelValues("upgrade_rate").Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return false
+		}
+	}
+
+	if s.config.MaxConnections > 0 {
+		s.mu.Lock()
+		full := s.admitted >= s.config.MaxConnections
+		if !full {
+			s.admitted++
+		}
+		s.mu.Unlock()
+
+		if full {
+			limitExceeded.WithLabelValues("max_connections").Inc()
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return false
+		}
+	}
+	return true
+}
+
+func (s *serverEntity) release() {
+	if s.config.MaxConnections <= 0 {
+		return
+	}
+	s.mu.Lock()
+	s.admitted--
+	s.mu.Unlock()
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -17,6 +17,7 @@ func registerWSMetrics() {
 		mustRegisterCollector(&hubKick, hubKick)
 		mustRegisterCollector(&hubRoomOps, hubRoomOps)
 		mustRegisterCollector(&limitExceeded, limitExceeded)
+		mustRegisterCollector(&appHeartbeats, appHeartbeats)
 	})
 }
 
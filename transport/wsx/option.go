@@ -16,7 +16,9 @@ type connectOptions struct {
 	writeTimeout      time.Duration
 	sendBufferSize    int
 	userID            string
+	appHeartbeat      *AppHeartbeatConfig
 	skipObservability bool
+	codec             Codec
 }
 
 func WithHeartbeatInterval(d time.Duration) opt.Option[connectOptions] {
@@ -49,12 +51,50 @@ func WithConnectUserID(userID string) opt.Option[connectOptions] {
 	})
 }
 
+// WithAppHeartbeat enables application-level heartbeat frames on the
+// connection; see AppHeartbeatConfig.
+func WithAppHeartbeat(conf AppHeartbeatConfig) opt.Option[connectOptions] {
+	return opt.OptionFunc[connectOptions](func(o *connectOptions) {
+		o.appHeartbeat = &conf
+	})
+}
+
 func withSkipObservability(skip bool) opt.Option[connectOptions] {
 	return opt.OptionFunc[connectOptions](func(o *connectOptions) {
 		o.skipObservability = skip
 	})
 }
 
+func withCodec(codec Codec) opt.Option[connectOptions] {
+	return opt.OptionFunc[connectOptions](func(o *connectOptions) {
+		o.codec = codec
+	})
+}
+
+// subprotocols keeps Sec-WebSocket-Protocol values in preference order with
+// the codec each one selects.
+type subprotocols struct {
+	names  []string
+	codecs map[string]Codec
+}
+
+func (p *subprotocols) add(protocol string, codec Codec) {
+	if p.codecs == nil {
+		p.codecs = make(map[string]Codec)
+	}
+	if _, ok := p.codecs[protocol]; !ok {
+		p.names = append(p.names, protocol)
+	}
+	p.codecs[protocol] = codec
+}
+
+func (p *subprotocols) connectOption(protocol string) opt.Option[connectOptions] {
+	if codec := p.codecs[protocol]; codec != nil {
+		return withCodec(codec)
+	}
+	return nil
+}
+
 // ------------------- Server Options -------------------
 
 type serverOptions struct {
@@ -68,6 +108,7 @@ type serverOptions struct {
 	path        string
 	connectOpts []opt.Option[connectOptions]
 	hub         Hub
+	protocols   subprotocols
 }
 
 func WithServerCheckOrigin(f func(r *http.Request) bool) opt.Option[serverOptions] {
@@ -106,6 +147,16 @@ func WithServerConnectOption(opts ...opt.Option[connectOptions]) opt.Option[serv
 	})
 }
 
+// WithServerSubprotocol negotiates protocol through Sec-WebSocket-Protocol;
+// earlier calls are preferred. When it is selected, Connect.Send encodes with
+// codec, or JSON if codec is nil. Clients offering no known protocol still
+// connect with an empty Subprotocol.
+func WithServerSubprotocol(protocol string, codec Codec) opt.Option[serverOptions] {
+	return opt.OptionFunc[serverOptions](func(o *serverOptions) {
+		o.protocols.add(protocol, codec)
+	})
+}
+
 func WithServerHub(h Hub) opt.Option[serverOptions] {
 	return opt.OptionFunc[serverOptions](func(o *serverOptions) {
 		o.hub = h
@@ -120,6 +171,7 @@ type clientOptions struct {
 	maxReconnectAttempts int
 	httpHeader           http.Header
 	connectOpts          []opt.Option[connectOptions]
+	protocols            subprotocols
 }
 
 func WithClientDialTimeout(d time.Duration) opt.Option[clientOptions] {
@@ -151,3 +203,11 @@ func WithClientConnectOption(opts ...opt.Option[connectOptions]) opt.Option[clie
 		o.connectOpts = append(o.connectOpts, opts...)
 	})
 }
+
+// WithClientSubprotocol offers protocol in Sec-WebSocket-Protocol, in call
+// order, and encodes Connect.Send with codec when the server selects it.
+func WithClientSubprotocol(protocol string, codec Codec) opt.Option[clientOptions] {
+	return opt.OptionFunc[clientOptions](func(o *clientOptions) {
+		o.protocols.add(protocol, codec)
+	})
+}
@@ -0,0 +1,226 @@
+package wsx
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultSequenceKeyPrefix = "ws:seq:"
+	defaultReorderMaxWait    = 2 * time.Second
+	defaultReorderMaxPending = 256
+)
+
+// SequenceStore hands out increasing per-user sequence numbers starting at 1.
+type SequenceStore interface {
+	Next(ctx context.Context, userID string) (uint64, error)
+}
+
+// SequencedMessage is the frame SendTo writes when the hub has a sequence
+// store: Data is the original JSON payload.
+type SequencedMessage struct {
+	Seq  uint64          `json:"seq"`
+	Data json.RawMessage `json:"data"`
+}
+
+// RedisSequence counts with INCR so every node shares one counter per user.
+// With a TTL the counter restarts at 1 after the user has been idle that
+// long; Reorderer treats a 1 after any later sequence as a restart.
+type RedisSequence struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+func NewRedisSequence(client redis.UniversalClient, prefix string, ttl time.Duration) *RedisSequence {
+	if prefix == "" {
+		prefix = defaultSequenceKeyPrefix
+	}
+	return &RedisSequence{client: client, prefix: prefix, ttl: ttl}
+}
+
+func (s *RedisSequence) Next(ctx context.Context, userID string) (uint64, error) {
+	key := s.prefix + userID
+	if s.ttl <= 0 {
+		return s.client.Incr(ctx, key).Uint64()
+	}
+	var incr *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, s.ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Uint64()
+}
+
+// MemorySequence is a SequenceStore for a single node.
+type MemorySequence struct {
+	mu   sync.Mutex
+	next map[string]uint64
+}
+
+func NewMemorySequence() *MemorySequence {
+	return &MemorySequence{next: make(map[string]uint64)}
+}
+
+func (s *MemorySequence) Next(_ context.Context, userID string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next[userID]++
+	return s.next[userID], nil
+}
+
+func (h *hubEntity) stampSequence(ctx context.Context, userID string, msg []byte) ([]byte, error) {
+	if !json.Valid(msg) {
+		return nil, errSequencePayloadInvalid
+	}
+	seq, err := h.sequence.Next(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(SequencedMessage{Seq: seq, Data: msg})
+}
+
+type ReorderOptions struct {
+	// LastSeq is the last sequence the client has already handled, e.g. from
+	// before a reconnect. Zero accepts whatever arrives first as the start.
+	LastSeq uint64
+	// MaxWait is how long a gap may hold back later messages before they are
+	// released without it; defaults to 2s.
+	MaxWait time.Duration
+	// MaxPending caps the held-back messages; defaults to 256.
+	MaxPending int
+}
+
+// Reorderer puts SequencedMessage frames back in order on the client and drops
+// duplicates. Messages fanned out by different nodes can overtake each other;
+// a message behind a gap is held until the gap fills or MaxWait passes. It is
+// not safe for concurrent use.
+type Reorderer struct {
+	last       uint64
+	started    bool
+	maxWait    time.Duration
+	maxPending int
+	pending    map[uint64]json.RawMessage
+	gapSince   time.Time
+	now        func() time.Time
+}
+
+func NewReorderer(opts *ReorderOptions) *Reorderer {
+	var options ReorderOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.MaxWait <= 0 {
+		options.MaxWait = defaultReorderMaxWait
+	}
+	if options.MaxPending <= 0 {
+		options.MaxPending = defaultReorderMaxPending
+	}
+	return &Reorderer{
+		last:       options.LastSeq,
+		started:    options.LastSeq > 0,
+		maxWait:    options.MaxWait,
+		maxPending: options.MaxPending,
+		pending:    make(map[uint64]json.RawMessage),
+		now:        time.Now,
+	}
+}
+
+// Push decodes a SequencedMessage frame and returns the payloads that are now
+// ready, in sequence order.
+func (r *Reorderer) Push(frame []byte) ([]json.RawMessage, error) {
+	var msg SequencedMessage
+	if err := json.Unmarshal(frame, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Seq == 0 {
+		return nil, errSequenceMissing
+	}
+	return r.Add(msg.Seq, msg.Data), nil
+}
+
+// Add is Push for an already decoded message.
+func (r *Reorderer) Add(seq uint64, data json.RawMessage) []json.RawMessage {
+	switch {
+	case !r.started:
+		r.started = true
+		r.last = seq - 1
+	case seq == 1 && r.last > 1:
+		// The counter restarted, e.g. after its TTL. A late duplicate of 1
+		// would also land here; replaying it beats dropping a whole new run.
+		ready := r.Flush()
+		r.last = 0
+		r.pending[seq] = data
+		return append(ready, r.drain()...)
+	case seq <= r.last:
+		return nil
+	}
+	if _, ok := r.pending[seq]; !ok {
+		r.pending[seq] = data
+	}
+	ready := r.drain()
+	if len(r.pending) > 0 && (len(r.pending) > r.maxPending || r.now().Sub(r.gapSince) >= r.maxWait) {
+		ready = append(ready, r.skipGap()...)
+	}
+	return ready
+}
+
+// Expired releases held-back messages whose gap has been open longer than
+// MaxWait. Call it from a timer when no new frames arrive.
+func (r *Reorderer) Expired() []json.RawMessage {
+	if len(r.pending) == 0 || r.now().Sub(r.gapSince) < r.maxWait {
+		return nil
+	}
+	return r.skipGap()
+}
+
+// Flush releases everything held back, in order, skipping all gaps.
+func (r *Reorderer) Flush() []json.RawMessage {
+	var ready []json.RawMessage
+	for len(r.pending) > 0 {
+		ready = append(ready, r.skipGap()...)
+	}
+	return ready
+}
+
+// LastSeq is the last sequence handed out, to resume from after a reconnect.
+func (r *Reorderer) LastSeq() uint64 {
+	return r.last
+}
+
+func (r *Reorderer) drain() []json.RawMessage {
+	var ready []json.RawMessage
+	for {
+		data, ok := r.pending[r.last+1]
+		if !ok {
+			break
+		}
+		delete(r.pending, r.last+1)
+		r.last++
+		ready = append(ready, data)
+	}
+	switch {
+	case len(r.pending) == 0:
+		r.gapSince = time.Time{}
+	case r.gapSince.IsZero() || len(ready) > 0:
+		r.gapSince = r.now()
+	}
+	return ready
+}
+
+// skipGap gives up on the missing sequences before the lowest held one.
+func (r *Reorderer) skipGap() []json.RawMessage {
+	r.last = slices.Min(slices.Collect(maps.Keys(r.pending))) - 1
+	r.gapSince = time.Time{}
+	return r.drain()
+}
@@ -0,0 +1,167 @@
+package wsx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestHubSequenceStampsPerUser(t *testing.T) {
+	t.Parallel()
+
+	hub, err := NewHub(WithHubSequence(NewMemorySequence()))
+	if err != nil {
+		t.Fatalf("new hub failed: %v", err)
+	}
+	defer hub.Close()
+
+	phone := newStubConnect("user-1", "phone")
+	laptop := newStubConnect("user-1", "laptop")
+	other := newStubConnect("user-2", "other")
+	for _, conn := range []*stubConnect{phone, laptop, other} {
+		if err := hub.Register(conn); err != nil {
+			t.Fatalf("register failed: %v", err)
+		}
+	}
+
+	for i := range 3 {
+		if err := hub.SendJSONTo(context.Background(), "user-1", map[string]int{"n": i}); err != nil {
+			t.Fatalf("send json failed: %v", err)
+		}
+	}
+	if err := hub.SendTo(context.Background(), "user-2", []byte(`"hi"`)); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	for _, conn := range []*stubConnect{phone, laptop} {
+		messages := conn.Messages()
+		if len(messages) != 3 {
+			t.Fatalf("%s got %d messages, want 3", conn.SessionID(), len(messages))
+		}
+		for i, raw := range messages {
+			var msg SequencedMessage
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				t.Fatalf("unmarshal failed: %v", err)
+			}
+			if msg.Seq != uint64(i+1) {
+				t.Fatalf("%s message %d seq = %d", conn.SessionID(), i, msg.Seq)
+			}
+		}
+	}
+	if got := string(other.Messages()[0]); got != `{"seq":1,"data":"hi"}` {
+		t.Fatalf("user-2 frame = %s", got)
+	}
+
+	if err := hub.SendTo(context.Background(), "user-1", []byte("plain text")); !errors.Is(err, errSequencePayloadInvalid) {
+		t.Fatalf("send non-json error = %v, want %v", err, errSequencePayloadInvalid)
+	}
+}
+
+func TestReordererRestoresOrder(t *testing.T) {
+	t.Parallel()
+
+	r := NewReorderer(&ReorderOptions{LastSeq: 4})
+	push := func(seq uint64) []string {
+		t.Helper()
+		frame, _ := json.Marshal(SequencedMessage{Seq: seq, Data: json.RawMessage(`"m` + strconv.FormatUint(seq, 10) + `"`)})
+		ready, err := r.Push(frame)
+		if err != nil {
+			t.Fatalf("push failed: %v", err)
+		}
+		out := make([]string, len(ready))
+		for i, data := range ready {
+			out[i] = string(data)
+		}
+		return out
+	}
+
+	if got := push(6); len(got) != 0 {
+		t.Fatalf("push(6) = %v, want held back", got)
+	}
+	if got := push(4); len(got) != 0 {
+		t.Fatalf("push(4) = %v, want duplicate dropped", got)
+	}
+	if got := push(5); len(got) != 2 || got[0] != `"m5"` || got[1] != `"m6"` {
+		t.Fatalf("push(5) = %v, want [m5 m6]", got)
+	}
+	if got := push(6); len(got) != 0 {
+		t.Fatalf("push(6) again = %v, want duplicate dropped", got)
+	}
+	if got := r.LastSeq(); got != 6 {
+		t.Fatalf("LastSeq() = %d, want 6", got)
+	}
+
+	if _, err := r.Push([]byte(`{"data":1}`)); !errors.Is(err, errSequenceMissing) {
+		t.Fatalf("push without seq error = %v, want %v", err, errSequenceMissing)
+	}
+}
+
+func TestReordererSkipsExpiredGap(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	r := NewReorderer(&ReorderOptions{MaxWait: time.Second})
+	r.now = func() time.Time { return now }
+
+	if got := r.Add(10, json.RawMessage(`10`)); len(got) != 1 {
+		t.Fatalf("first message = %v, want delivered", got)
+	}
+	if got := r.Add(12, json.RawMessage(`12`)); len(got) != 0 {
+		t.Fatalf("Add(12) = %v, want held back", got)
+	}
+	if got := r.Expired(); len(got) != 0 {
+		t.Fatalf("Expired() before MaxWait = %v", got)
+	}
+
+	now = now.Add(time.Second)
+	if got := r.Expired(); len(got) != 1 || string(got[0]) != "12" {
+		t.Fatalf("Expired() = %v, want [12]", got)
+	}
+	if got := r.Add(11, json.RawMessage(`11`)); len(got) != 0 {
+		t.Fatalf("late Add(11) = %v, want dropped", got)
+	}
+}
+
+func TestReordererMaxPendingAndRestart(t *testing.T) {
+	t.Parallel()
+
+	r := NewReorderer(&ReorderOptions{MaxPending: 2})
+	r.Add(1, json.RawMessage(`1`))
+	r.Add(3, json.RawMessage(`3`))
+	r.Add(4, json.RawMessage(`4`))
+	if got := r.Add(5, json.RawMessage(`5`)); len(got) != 3 {
+		t.Fatalf("Add over MaxPending = %v, want [3 4 5]", got)
+	}
+
+	if got := r.Add(1, json.RawMessage(`"again"`)); len(got) != 1 || string(got[0]) != `"again"` {
+		t.Fatalf("Add(1) after restart = %v, want delivered", got)
+	}
+	if got := r.LastSeq(); got != 1 {
+		t.Fatalf("LastSeq() after restart = %d, want 1", got)
+	}
+}
+
+func TestReordererRestartWithSmallLastSeq(t *testing.T) {
+	t.Parallel()
+
+	r := NewReorderer(nil)
+	for seq := uint64(1); seq <= 3; seq++ {
+		r.Add(seq, json.RawMessage(strconv.FormatUint(seq, 10)))
+	}
+
+	if got := r.Add(1, json.RawMessage(`"r1"`)); len(got) != 1 || string(got[0]) != `"r1"` {
+		t.Fatalf("Add(1) after restart = %v, want delivered", got)
+	}
+	if got := r.Add(2, json.RawMessage(`"r2"`)); len(got) != 1 || string(got[0]) != `"r2"` {
+		t.Fatalf("Add(2) after restart = %v, want delivered", got)
+	}
+	if got := r.Add(2, json.RawMessage(`"r2"`)); len(got) != 0 {
+		t.Fatalf("duplicate Add(2) = %v, want dropped", got)
+	}
+	if got := r.LastSeq(); got != 2 {
+		t.Fatalf("LastSeq() = %d, want 2", got)
+	}
+}
@@ -17,10 +17,17 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+type HandlerFunc func(context.Context, Connect)
+
+// Interceptor wraps the per-connection handler after the upgrade. It may
+// enrich ctx, skip next to reject the connection, or run code around it.
+type Interceptor func(next HandlerFunc) HandlerFunc
+
 type Server interface {
 	Start(context.Context, func(context.Context, Connect)) error
 	Shutdown(context.Context) error
 	Handler(func(context.Context, Connect)) http.HandlerFunc
+	Use(...Interceptor) error
 }
 
 type ServerConfig struct {
@@ -38,6 +45,12 @@ type ServerConfig struct {
 	// ObservabilitySkipPaths 跳过可观测性记录（Metrics & Trace）的路径列表
 	// 默认为 /healthz, /metrics。用户配置将与默认值合并。
 	ObservabilitySkipPaths []string
+	// MaxConnections caps concurrent connections, including in-flight upgrades; excess requests get 503.
+	MaxConnections int
+	// MaxUpgradesPerIPPerMinute caps upgrade attempts per source IP; excess requests get 429.
+	MaxUpgradesPerIPPerMinute int
+	// HealthHandler serves /healthz, e.g. a healthcheck.Checker handler; defaults to a static "OK".
+	HealthHandler http.Handler
 }
 
 type serverEntity struct {
@@ -47,7 +60,11 @@ type serverEntity struct {
 	listener    net.Listener
 	mu          sync.Mutex
 	running     bool
-	connections map[Connect]struct{}
+	connections  map[Connect]struct{}
+	admitted     int
+	interceptors []Interceptor
+
+	upgradeLimiter *upgradeRateLimiter
 }
 
 func NewServer(conf *ServerConfig, opts ...opt.Option[serverOptions]) Server {
@@ -83,9 +100,31 @@ func NewServer(conf *ServerConfig, opts ...opt.Option[serverOptions]) Server {
 		options:     options,
 		connections: make(map[Connect]struct{}),
 	}
+	if conf.MaxUpgradesPerIPPerMinute > 0 {
+		s.upgradeLimiter = newUpgradeRateLimiter(conf.MaxUpgradesPerIPPerMinute)
+	}
+	if conf.MaxConnections > 0 || conf.MaxUpgradesPerIPPerMinute > 0 {
+		registerWSMetrics()
+	}
 	return s
 }
 
+func (s *serverEntity) Use(interceptors ...Interceptor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return errServerAlreadyRunning
+	}
+	for _, interceptor := range interceptors {
+		if interceptor == nil {
+			continue
+		}
+		s.interceptors = append(s.interceptors, interceptor)
+	}
+	return nil
+}
+
 func (s *serverEntity) Start(ctx context.Context, handler func(context.Context, Connect)) error {
 	ctx = normalizeContext(ctx)
 	if err := ctx.Err(); err != nil {
@@ -135,10 +174,14 @@ func (s *serverEntity) Start(ctx context.Context, handler func(context.Context,
 	// WebSocket Route
 	mux.HandleFunc(s.options.path, s.Handler(handler))
 	// Health Check Route
-	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	if s.config.HealthHandler != nil {
+		mux.Handle("/healthz", s.config.HealthHandler)
+	} else {
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+		})
+	}
 
 	finalHandler := http.Handler(mux)
 	if s.config.Trace {
@@ -233,6 +276,11 @@ func (s *serverEntity) info(ctx context.Context, msg string, args ...any) {
 }
 
 func (s *serverEntity) Handler(handler func(context.Context, Connect)) http.HandlerFunc {
+	s.mu.Lock()
+	interceptors := append([]Interceptor(nil), s.interceptors...)
+	s.mu.Unlock()
+	handler = chainInterceptors(handler, interceptors)
+
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
 			rawConn *websocket.Conn
@@ -261,7 +309,13 @@ func (s *serverEntity) Handler(handler func(context.Context, Connect)) http.Hand
 			}
 		}()
 
-		// 1. Auth Hook
+		// 1. Admission limits
+		if !s.admit(w, r) {
+			return
+		}
+		defer s.release()
+
+		// 2. Auth Hook
 		if s.options.beforeUpgrade != nil {
 			if err := s.options.beforeUpgrade(r); err != nil {
 				http.Error(w, err.Error(), http.StatusUnauthorized)
@@ -289,6 +343,7 @@ func (s *serverEntity) Handler(handler func(context.Context, Connect)) http.Hand
 		// Origin verification is handled above so callers can provide arbitrary policies.
 		rawConn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
 			InsecureSkipVerify: true,
+			Subprotocols:       s.options.protocols.names,
 		})
 		if err != nil {
 			return
@@ -298,14 +353,18 @@ func (s *serverEntity) Handler(handler func(context.Context, Connect)) http.Hand
 			"method", r.Method,
 			"path", r.URL.Path,
 			"ip", r.RemoteAddr,
+			"subprotocol", rawConn.Subprotocol(),
 		)
 
-		// 2. Post-Handshake / OnConnect Hook
+		// 3. Post-Handshake / OnConnect Hook
 
 		connOpts := append([]opt.Option[connectOptions](nil), s.options.connectOpts...)
 		if userID != "" {
 			connOpts = append(connOpts, WithConnectUserID(userID))
 		}
+		if codecOpt := s.options.protocols.connectOption(rawConn.Subprotocol()); codecOpt != nil {
+			connOpts = append(connOpts, codecOpt)
+		}
 		if s.shouldSkipObservability(r.URL.Path) {
 			connOpts = append(connOpts, withSkipObservability(true))
 		}
@@ -321,7 +380,7 @@ func (s *serverEntity) Handler(handler func(context.Context, Connect)) http.Hand
 			}
 		}
 
-		// 3. Register to Hub if present
+		// 4. Register to Hub if present
 		if s.options.hub != nil {
 			if err := s.options.hub.Register(conn); err != nil {
 				_ = conn.Close()
@@ -337,6 +396,16 @@ func (s *serverEntity) Handler(handler func(context.Context, Connect)) http.Hand
 	}
 }
 
+func chainInterceptors(handler HandlerFunc, interceptors []Interceptor) HandlerFunc {
+	if handler == nil {
+		return nil
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		handler = interceptors[i](handler)
+	}
+	return handler
+}
+
 func (s *serverEntity) shouldSkipObservability(path string) bool {
 	if path == "/healthz" || path == "/metrics" {
 		return true
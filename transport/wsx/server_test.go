@@ -4,11 +4,14 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/coder/websocket"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestServerDefaultOriginPolicy(t *testing.T) {
@@ -156,3 +159,276 @@ func TestServerStartStopsWhenContextCanceled(t *testing.T) {
 		t.Fatal("server did not stop after context cancel")
 	}
 }
+
+type interceptorCtxKey struct{}
+
+func TestServerInterceptorsWrapHandlerInOrder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(step string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, step)
+	}
+
+	server := NewServer(&ServerConfig{})
+	if err := server.Use(
+		func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context, conn Connect) {
+				record("outer:before")
+				next(context.WithValue(ctx, interceptorCtxKey{}, "tenant-a"), conn)
+				record("outer:after")
+			}
+		},
+		nil,
+		func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context, conn Connect) {
+				if ctx.Value(interceptorCtxKey{}) != "tenant-a" {
+					record("inner:rejected")
+					return
+				}
+				record("inner")
+				next(ctx, conn)
+			}
+		},
+	); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+
+	done := make(chan struct{})
+	httpServer := httptest.NewServer(server.Handler(func(ctx context.Context, conn Connect) {
+		record("handler:" + ctx.Value(interceptorCtxKey{}).(string))
+		_ = conn.SendText(ctx, "hello")
+		_, _, _ = conn.ReadMessage(ctx)
+		close(done)
+	}))
+	defer httpServer.Close()
+
+	conn, _, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	if _, data, err := conn.Read(context.Background()); err != nil || string(data) != "hello" {
+		t.Fatalf("read = %q, %v, want hello", data, err)
+	}
+	_ = conn.Close(websocket.StatusNormalClosure, "done")
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler did not return")
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		got := strings.Join(order, ",")
+		mu.Unlock()
+		if got == "outer:before,inner,handler:tenant-a,outer:after" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("interceptor order = %s", got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServerInterceptorCanRejectConnection(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&ServerConfig{})
+	_ = server.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, conn Connect) {}
+	})
+
+	called := make(chan struct{}, 1)
+	httpServer := httptest.NewServer(server.Handler(func(ctx context.Context, conn Connect) {
+		called <- struct{}{}
+	}))
+	defer httpServer.Close()
+
+	conn, _, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "done")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, _, err := conn.Read(ctx); err == nil {
+		t.Fatal("expected rejected connection to be closed")
+	}
+	select {
+	case <-called:
+		t.Fatal("handler ran despite interceptor rejection")
+	default:
+	}
+}
+
+func TestServerUseRejectedWhileRunning(t *testing.T) {
+	t.Parallel()
+
+	server, wsURL, _, _ := startTestWSServer(t, func(ctx context.Context, conn Connect) {})
+	deadline := time.Now().Add(time.Second)
+	for {
+		conn, _, err := websocket.Dial(context.Background(), wsURL, nil)
+		if err == nil {
+			_ = conn.Close(websocket.StatusNormalClosure, "done")
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := server.Use(func(next HandlerFunc) HandlerFunc { return next }); err != errServerAlreadyRunning {
+		t.Fatalf("Use() error = %v, want %v", err, errServerAlreadyRunning)
+	}
+}
+
+func TestServerMaxConnections(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&ServerConfig{MaxConnections: 1})
+	httpServer := httptest.NewServer(server.Handler(func(ctx context.Context, conn Connect) {
+		_, _, _ = conn.ReadMessage(ctx)
+	}))
+	defer httpServer.Close()
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	first, _, err := websocket.Dial(context.Background(), wsURL, nil)
+	if err != nil {
+		t.Fatalf("first dial failed: %v", err)
+	}
+
+	second, resp, err := websocket.Dial(context.Background(), wsURL, nil)
+	if err == nil {
+		_ = second.Close(websocket.StatusNormalClosure, "unexpected success")
+		t.Fatal("expected second dial to be rejected")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	_ = first.Close(websocket.StatusNormalClosure, "done")
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		conn, _, err := websocket.Dial(context.Background(), wsURL, nil)
+		if err == nil {
+			_ = conn.Close(websocket.StatusNormalClosure, "done")
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("slot was not released after close: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServerUpgradeRateLimitPerIP(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&ServerConfig{MaxUpgradesPerIPPerMinute: 2})
+	httpServer := httptest.NewServer(server.Handler(func(ctx context.Context, conn Connect) {}))
+	defer httpServer.Close()
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	for i := 0; i < 2; i++ {
+		conn, _, err := websocket.Dial(context.Background(), wsURL, nil)
+		if err != nil {
+			t.Fatalf("dial %d failed: %v", i, err)
+		}
+		_ = conn.Close(websocket.StatusNormalClosure, "done")
+	}
+
+	conn, resp, err := websocket.Dial(context.Background(), wsURL, nil)
+	if err == nil {
+		_ = conn.Close(websocket.StatusNormalClosure, "unexpected success")
+		t.Fatal("expected third dial to be rate limited")
+	}
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Fatal("expected Retry-After header")
+	}
+}
+
+func TestUpgradeRateLimiterWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	limiter := newUpgradeRateLimiter(1)
+	limiter.now = func() time.Time { return now }
+
+	if ok, _ := limiter.allow("10.0.0.1"); !ok {
+		t.Fatal("first upgrade should be allowed")
+	}
+	if ok, retryAfter := limiter.allow("10.0.0.1"); ok || retryAfter != time.Minute {
+		t.Fatalf("second upgrade = %v retry after %v, want rejected with 1m", ok, retryAfter)
+	}
+	if ok, _ := limiter.allow("10.0.0.2"); !ok {
+		t.Fatal("other ip should be allowed")
+	}
+
+	now = now.Add(time.Minute)
+	if ok, _ := limiter.allow("10.0.0.1"); !ok {
+		t.Fatal("upgrade should be allowed after window reset")
+	}
+	if got := len(limiter.buckets); got != 1 {
+		t.Fatalf("buckets = %d, want stale entries swept", got)
+	}
+}
+
+func TestServerSubprotocolNegotiation(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&ServerConfig{},
+		WithServerSubprotocol("v2.proto", ProtoCodec),
+		WithServerSubprotocol("v1.json", nil),
+	)
+	httpServer := httptest.NewServer(server.Handler(func(ctx context.Context, conn Connect) {
+		_ = conn.Send(ctx, wrapperspb.String("hello "+conn.Subprotocol()))
+		_, _, _ = conn.ReadMessage(ctx)
+	}))
+	t.Cleanup(httpServer.Close)
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"
+
+	tests := []struct {
+		offer    []string
+		protocol string
+		typ      websocket.MessageType
+		codec    Codec
+	}{
+		{offer: []string{"v1.json", "v2.proto"}, protocol: "v2.proto", typ: websocket.MessageBinary, codec: ProtoCodec},
+		{offer: []string{"v1.json"}, protocol: "v1.json", typ: websocket.MessageText, codec: JSONCodec},
+		{offer: nil, protocol: "", typ: websocket.MessageText, codec: JSONCodec},
+	}
+	for _, tt := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		conn, _, err := websocket.Dial(ctx, wsURL, &websocket.DialOptions{Subprotocols: tt.offer})
+		if err != nil {
+			cancel()
+			t.Fatalf("dial %v: %v", tt.offer, err)
+		}
+		if got := conn.Subprotocol(); got != tt.protocol {
+			t.Fatalf("offer %v selected %q, want %q", tt.offer, got, tt.protocol)
+		}
+		typ, data, err := conn.Read(ctx)
+		_ = conn.Close(websocket.StatusNormalClosure, "")
+		cancel()
+		if err != nil || typ != tt.typ {
+			t.Fatalf("offer %v read type = %v, err = %v", tt.offer, typ, err)
+		}
+		var got wrapperspb.StringValue
+		if err := tt.codec.Unmarshal(data, &got); err != nil || got.GetValue() != "hello "+tt.protocol {
+			t.Fatalf("offer %v payload = %q, err = %v", tt.offer, data, err)
+		}
+	}
+}